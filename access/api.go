@@ -14,6 +14,8 @@ import (
 type API interface {
 	Ping(ctx context.Context) error
 	GetNetworkParameters(ctx context.Context) NetworkParameters
+	GetNodeVersionInfo(ctx context.Context) (*NodeVersionInfo, error)
+	GetLatestEpochInfo(ctx context.Context) (*EpochInfo, error)
 
 	GetLatestBlockHeader(ctx context.Context, isSealed bool) (*flow.Header, error)
 	GetBlockHeaderByHeight(ctx context.Context, height uint64) (*flow.Header, error)
@@ -26,15 +28,20 @@ type API interface {
 	GetCollectionByID(ctx context.Context, id flow.Identifier) (*flow.LightCollection, error)
 
 	SendTransaction(ctx context.Context, tx *flow.TransactionBody) error
+	SimulateTransaction(ctx context.Context, tx *flow.TransactionBody) (*TransactionSimulationResult, error)
 	GetTransaction(ctx context.Context, id flow.Identifier) (*flow.TransactionBody, error)
 	GetTransactionsByBlockID(ctx context.Context, blockID flow.Identifier) ([]*flow.TransactionBody, error)
 	GetTransactionResult(ctx context.Context, id flow.Identifier) (*TransactionResult, error)
 	GetTransactionResultByIndex(ctx context.Context, blockID flow.Identifier, index uint32) (*TransactionResult, error)
 	GetTransactionResultsByBlockID(ctx context.Context, blockID flow.Identifier) ([]*TransactionResult, error)
+	GetTransactionProfile(ctx context.Context, id flow.Identifier) (*TransactionProfile, error)
+	SubscribeTransactionStatus(ctx context.Context, id flow.Identifier) (TransactionStatusSubscription, error)
 
 	GetAccount(ctx context.Context, address flow.Address) (*flow.Account, error)
 	GetAccountAtLatestBlock(ctx context.Context, address flow.Address) (*flow.Account, error)
 	GetAccountAtBlockHeight(ctx context.Context, address flow.Address, height uint64) (*flow.Account, error)
+	GetAccountsAtLatestBlock(ctx context.Context, addresses []flow.Address) ([]AccountResult, error)
+	GetAccountsAtBlockHeight(ctx context.Context, addresses []flow.Address, height uint64) ([]AccountResult, error)
 
 	ExecuteScriptAtLatestBlock(ctx context.Context, script []byte, arguments [][]byte) ([]byte, error)
 	ExecuteScriptAtBlockHeight(ctx context.Context, blockHeight uint64, script []byte, arguments [][]byte) ([]byte, error)
@@ -42,6 +49,9 @@ type API interface {
 
 	GetEventsForHeightRange(ctx context.Context, eventType string, startHeight, endHeight uint64) ([]flow.BlockEvents, error)
 	GetEventsForBlockIDs(ctx context.Context, eventType string, blockIDs []flow.Identifier) ([]flow.BlockEvents, error)
+	QueryEventsForHeightRange(ctx context.Context, query EventQuery, startHeight, endHeight uint64) ([]flow.BlockEvents, error)
+	QueryEventsForBlockIDs(ctx context.Context, query EventQuery, blockIDs []flow.Identifier) ([]flow.BlockEvents, error)
+	SubscribeEvents(ctx context.Context, startHeight uint64, isSealed bool, filter EventFilter) (EventsSubscription, error)
 
 	GetLatestProtocolStateSnapshot(ctx context.Context) ([]byte, error)
 
@@ -96,7 +106,67 @@ func MessageToTransactionResult(message *access.TransactionResultResponse) *Tran
 	}
 }
 
+// TransactionSimulationResult is the outcome of running a transaction against the latest sealed execution state
+// without committing it.
+type TransactionSimulationResult struct {
+	BlockID         flow.Identifier // the sealed block whose execution state the transaction was run against
+	BlockHeight     uint64
+	ComputationUsed uint64
+	MemoryUsed      uint64
+	Events          []flow.Event
+	RegisterUpdates []flow.RegisterEntry // storage delta the transaction would commit
+	Fee             uint64               // fee that would be charged, in UFix64 units, 0 if fees are disabled
+	ErrorCode       uint                 // fvm error code, 0 if the transaction succeeded
+	ErrorMessage    string
+}
+
+// TransactionProfile is the breakdown of the resources used to execute a transaction, as recorded by the
+// execution node which executed it.
+type TransactionProfile struct {
+	BlockID              flow.Identifier
+	TransactionID        flow.Identifier
+	ComputationUsed      uint64
+	MemoryUsed           uint64
+	ComputationKinds     []ProfiledKind // kinds which used the most computation, in descending order
+	MemoryKinds          []ProfiledKind // kinds which used the most memory, in descending order
+	RegisterBytesRead    uint64
+	RegisterBytesWritten uint64
+}
+
+// ProfiledKind is the usage of a single computation or memory kind by a transaction.
+type ProfiledKind struct {
+	Kind      uint32
+	Name      string
+	Intensity uint64 // total intensity metered for the kind
+	Used      uint64 // computation or memory charged for the kind
+}
+
+// MaxAccountsPerRequest is the maximum number of accounts that can be requested in a single batch.
+const MaxAccountsPerRequest = 50
+
+// AccountResult is the outcome of getting a single account of a batch. Either Account or Err is set.
+type AccountResult struct {
+	Address flow.Address
+	Account *flow.Account
+	Err     error
+}
+
 // NetworkParameters contains the network-wide parameters for the Flow blockchain.
 type NetworkParameters struct {
 	ChainID flow.ChainID
 }
+
+// NodeVersionInfo contains the version of the node software and of the protocol state it serves.
+type NodeVersionInfo struct {
+	Semver               string
+	Commit               string
+	SporkID              flow.Identifier
+	ProtocolVersion      uint
+	SporkRootBlockHeight uint64
+}
+
+// EpochInfo contains the counter and phase of the current epoch as of the latest finalized block.
+type EpochInfo struct {
+	Counter uint64
+	Phase   flow.EpochPhase
+}
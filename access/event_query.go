@@ -0,0 +1,104 @@
+package access
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// MaxEventTypesPerQuery is the maximum number of event types of an event query, the events of each type are
+// retrieved from the execution nodes separately
+const MaxEventTypesPerQuery = 10
+
+// EventFieldFilter matches the events whose decoded Cadence payload has a field with the given name and value.
+//
+// Values are compared to the string representation of the field value, except for strings which are compared
+// without quotes and addresses which are compared regardless of the 0x prefix and leading zeros. Optional fields are
+// compared by their inner value, or to "nil" if they are not set.
+type EventFieldFilter struct {
+	Field string
+	Value string
+}
+
+// EventQuery selects the events of any of the event types which match all field filters.
+type EventQuery struct {
+	EventTypes   []flow.EventType
+	FieldFilters []EventFieldFilter
+}
+
+// FilterFields returns the events which match all field filters of the query. Events whose payload can not be
+// decoded do not match any field filter.
+func (q EventQuery) FilterFields(events []flow.Event) []flow.Event {
+	if len(q.FieldFilters) == 0 {
+		return events
+	}
+
+	filtered := make([]flow.Event, 0, len(events))
+	for _, event := range events {
+		if q.matchFields(event) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+func (q EventQuery) matchFields(event flow.Event) bool {
+	value, err := jsoncdc.Decode(nil, event.Payload)
+	if err != nil {
+		return false
+	}
+
+	cadenceEvent, ok := value.(cadence.Event)
+	if !ok {
+		return false
+	}
+
+	for _, filter := range q.FieldFilters {
+		if !filter.Match(cadenceEvent) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match returns true if the event has the field of the filter with the filtered value.
+func (f EventFieldFilter) Match(event cadence.Event) bool {
+	if event.EventType == nil {
+		return false
+	}
+
+	for i, field := range event.EventType.Fields {
+		if field.Identifier == f.Field && i < len(event.Fields) {
+			return matchValue(event.Fields[i], f.Value)
+		}
+	}
+	return false
+}
+
+func matchValue(value cadence.Value, expected string) bool {
+	switch v := value.(type) {
+	case cadence.Optional:
+		if v.Value == nil {
+			return expected == "nil"
+		}
+		return matchValue(v.Value, expected)
+	case cadence.String:
+		return string(v) == expected
+	case cadence.Address:
+		trimmed := strings.TrimPrefix(expected, "0x")
+		if len(trimmed)%2 == 1 {
+			trimmed = "0" + trimmed
+		}
+		b, err := hex.DecodeString(trimmed)
+		if err != nil || len(b) == 0 || len(b) > flow.AddressLength {
+			return false
+		}
+		return flow.BytesToAddress(b) == flow.Address(v)
+	default:
+		return value.String() == expected
+	}
+}
@@ -0,0 +1,84 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+func TestEventQueryFilterFields(t *testing.T) {
+	eventType := &cadence.EventType{
+		Location: common.AddressLocation{
+			Address: common.Address(flow.HexToAddress("1654653399040a61")),
+			Name:    "FlowToken",
+		},
+		QualifiedIdentifier: "FlowToken.TokensDeposited",
+		Fields: []cadence.Field{
+			{Identifier: "amount", Type: cadence.UFix64Type{}},
+			{Identifier: "to", Type: cadence.OptionalType{Type: cadence.AddressType{}}},
+			{Identifier: "memo", Type: cadence.StringType{}},
+		},
+	}
+
+	deposit := func(amount string, to *flow.Address, memo string) flow.Event {
+		ufix, err := cadence.NewUFix64(amount)
+		require.NoError(t, err)
+
+		var toValue cadence.Optional
+		if to != nil {
+			toValue = cadence.NewOptional(cadence.Address(*to))
+		}
+
+		payload, err := jsoncdc.Encode(cadence.NewEvent([]cadence.Value{ufix, toValue, cadence.String(memo)}).
+			WithType(eventType))
+		require.NoError(t, err)
+
+		return flow.Event{Type: "A.1654653399040a61.FlowToken.TokensDeposited", Payload: payload}
+	}
+
+	alice := flow.HexToAddress("01cf0e2f2f715450")
+	bob := flow.HexToAddress("179b6b1cb6755e31")
+	events := []flow.Event{
+		deposit("10.0", &alice, "rent"),
+		deposit("10.0", &bob, "rent"),
+		deposit("2.5", nil, "tip"),
+		{Type: "A.1654653399040a61.FlowToken.TokensDeposited", Payload: []byte("invalid")},
+	}
+
+	t.Run("no field filters", func(t *testing.T) {
+		assert.Equal(t, events, EventQuery{}.FilterFields(events))
+	})
+
+	t.Run("address field", func(t *testing.T) {
+		query := EventQuery{FieldFilters: []EventFieldFilter{{Field: "to", Value: "0x01cf0e2f2f715450"}}}
+		assert.Equal(t, events[:1], query.FilterFields(events))
+
+		query = EventQuery{FieldFilters: []EventFieldFilter{{Field: "to", Value: "179b6b1cb6755e31"}}}
+		assert.Equal(t, events[1:2], query.FilterFields(events))
+	})
+
+	t.Run("nil optional field", func(t *testing.T) {
+		query := EventQuery{FieldFilters: []EventFieldFilter{{Field: "to", Value: "nil"}}}
+		assert.Equal(t, events[2:3], query.FilterFields(events))
+	})
+
+	t.Run("filters are combined with and", func(t *testing.T) {
+		query := EventQuery{FieldFilters: []EventFieldFilter{
+			{Field: "amount", Value: "10.00000000"},
+			{Field: "memo", Value: "rent"},
+			{Field: "to", Value: "0x179b6b1cb6755e31"},
+		}}
+		assert.Equal(t, events[1:2], query.FilterFields(events))
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		query := EventQuery{FieldFilters: []EventFieldFilter{{Field: "from", Value: "rent"}}}
+		assert.Empty(t, query.FilterFields(events))
+	})
+}
@@ -0,0 +1,360 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package extended
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ExtendedAccessAPIClient is the client API for ExtendedAccessAPI service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ExtendedAccessAPIClient interface {
+	// SubscribeEvents streams the events matching the filter block by block,
+	// starting at the given height, as blocks become sealed or finalized.
+	SubscribeEvents(ctx context.Context, in *SubscribeEventsRequest, opts ...grpc.CallOption) (ExtendedAccessAPI_SubscribeEventsClient, error)
+	// SubscribeTransactionStatus streams each status transition of a transaction,
+	// until the transaction is sealed or expired.
+	SubscribeTransactionStatus(ctx context.Context, in *SubscribeTransactionStatusRequest, opts ...grpc.CallOption) (ExtendedAccessAPI_SubscribeTransactionStatusClient, error)
+	// GetAccountsAtLatestBlock gets a batch of accounts at the latest sealed block.
+	GetAccountsAtLatestBlock(ctx context.Context, in *GetAccountsAtLatestBlockRequest, opts ...grpc.CallOption) (*GetAccountsResponse, error)
+	// GetAccountsAtBlockHeight gets a batch of accounts at the given block height.
+	GetAccountsAtBlockHeight(ctx context.Context, in *GetAccountsAtBlockHeightRequest, opts ...grpc.CallOption) (*GetAccountsResponse, error)
+	// SimulateTransaction runs a transaction against the latest sealed execution
+	// state without committing it, and reports its resource usage and fee.
+	// It is only available on access nodes which index the execution state and
+	// have transaction simulation enabled.
+	SimulateTransaction(ctx context.Context, in *SimulateTransactionRequest, opts ...grpc.CallOption) (*SimulateTransactionResponse, error)
+	// GetTransactionProfile gets the breakdown of the resources used to execute
+	// a transaction, as recorded by an execution node.
+	GetTransactionProfile(ctx context.Context, in *GetTransactionProfileRequest, opts ...grpc.CallOption) (*GetTransactionProfileResponse, error)
+}
+
+type extendedAccessAPIClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewExtendedAccessAPIClient(cc grpc.ClientConnInterface) ExtendedAccessAPIClient {
+	return &extendedAccessAPIClient{cc}
+}
+
+func (c *extendedAccessAPIClient) SubscribeEvents(ctx context.Context, in *SubscribeEventsRequest, opts ...grpc.CallOption) (ExtendedAccessAPI_SubscribeEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ExtendedAccessAPI_ServiceDesc.Streams[0], "/extended.ExtendedAccessAPI/SubscribeEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &extendedAccessAPISubscribeEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ExtendedAccessAPI_SubscribeEventsClient interface {
+	Recv() (*SubscribeEventsResponse, error)
+	grpc.ClientStream
+}
+
+type extendedAccessAPISubscribeEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *extendedAccessAPISubscribeEventsClient) Recv() (*SubscribeEventsResponse, error) {
+	m := new(SubscribeEventsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *extendedAccessAPIClient) SubscribeTransactionStatus(ctx context.Context, in *SubscribeTransactionStatusRequest, opts ...grpc.CallOption) (ExtendedAccessAPI_SubscribeTransactionStatusClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ExtendedAccessAPI_ServiceDesc.Streams[1], "/extended.ExtendedAccessAPI/SubscribeTransactionStatus", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &extendedAccessAPISubscribeTransactionStatusClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ExtendedAccessAPI_SubscribeTransactionStatusClient interface {
+	Recv() (*SubscribeTransactionStatusResponse, error)
+	grpc.ClientStream
+}
+
+type extendedAccessAPISubscribeTransactionStatusClient struct {
+	grpc.ClientStream
+}
+
+func (x *extendedAccessAPISubscribeTransactionStatusClient) Recv() (*SubscribeTransactionStatusResponse, error) {
+	m := new(SubscribeTransactionStatusResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *extendedAccessAPIClient) GetAccountsAtLatestBlock(ctx context.Context, in *GetAccountsAtLatestBlockRequest, opts ...grpc.CallOption) (*GetAccountsResponse, error) {
+	out := new(GetAccountsResponse)
+	err := c.cc.Invoke(ctx, "/extended.ExtendedAccessAPI/GetAccountsAtLatestBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *extendedAccessAPIClient) GetAccountsAtBlockHeight(ctx context.Context, in *GetAccountsAtBlockHeightRequest, opts ...grpc.CallOption) (*GetAccountsResponse, error) {
+	out := new(GetAccountsResponse)
+	err := c.cc.Invoke(ctx, "/extended.ExtendedAccessAPI/GetAccountsAtBlockHeight", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *extendedAccessAPIClient) SimulateTransaction(ctx context.Context, in *SimulateTransactionRequest, opts ...grpc.CallOption) (*SimulateTransactionResponse, error) {
+	out := new(SimulateTransactionResponse)
+	err := c.cc.Invoke(ctx, "/extended.ExtendedAccessAPI/SimulateTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *extendedAccessAPIClient) GetTransactionProfile(ctx context.Context, in *GetTransactionProfileRequest, opts ...grpc.CallOption) (*GetTransactionProfileResponse, error) {
+	out := new(GetTransactionProfileResponse)
+	err := c.cc.Invoke(ctx, "/extended.ExtendedAccessAPI/GetTransactionProfile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExtendedAccessAPIServer is the server API for ExtendedAccessAPI service.
+// All implementations must embed UnimplementedExtendedAccessAPIServer
+// for forward compatibility
+type ExtendedAccessAPIServer interface {
+	// SubscribeEvents streams the events matching the filter block by block,
+	// starting at the given height, as blocks become sealed or finalized.
+	SubscribeEvents(*SubscribeEventsRequest, ExtendedAccessAPI_SubscribeEventsServer) error
+	// SubscribeTransactionStatus streams each status transition of a transaction,
+	// until the transaction is sealed or expired.
+	SubscribeTransactionStatus(*SubscribeTransactionStatusRequest, ExtendedAccessAPI_SubscribeTransactionStatusServer) error
+	// GetAccountsAtLatestBlock gets a batch of accounts at the latest sealed block.
+	GetAccountsAtLatestBlock(context.Context, *GetAccountsAtLatestBlockRequest) (*GetAccountsResponse, error)
+	// GetAccountsAtBlockHeight gets a batch of accounts at the given block height.
+	GetAccountsAtBlockHeight(context.Context, *GetAccountsAtBlockHeightRequest) (*GetAccountsResponse, error)
+	// SimulateTransaction runs a transaction against the latest sealed execution
+	// state without committing it, and reports its resource usage and fee.
+	// It is only available on access nodes which index the execution state and
+	// have transaction simulation enabled.
+	SimulateTransaction(context.Context, *SimulateTransactionRequest) (*SimulateTransactionResponse, error)
+	// GetTransactionProfile gets the breakdown of the resources used to execute
+	// a transaction, as recorded by an execution node.
+	GetTransactionProfile(context.Context, *GetTransactionProfileRequest) (*GetTransactionProfileResponse, error)
+	mustEmbedUnimplementedExtendedAccessAPIServer()
+}
+
+// UnimplementedExtendedAccessAPIServer must be embedded to have forward compatible implementations.
+type UnimplementedExtendedAccessAPIServer struct {
+}
+
+func (UnimplementedExtendedAccessAPIServer) SubscribeEvents(*SubscribeEventsRequest, ExtendedAccessAPI_SubscribeEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeEvents not implemented")
+}
+func (UnimplementedExtendedAccessAPIServer) SubscribeTransactionStatus(*SubscribeTransactionStatusRequest, ExtendedAccessAPI_SubscribeTransactionStatusServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeTransactionStatus not implemented")
+}
+func (UnimplementedExtendedAccessAPIServer) GetAccountsAtLatestBlock(context.Context, *GetAccountsAtLatestBlockRequest) (*GetAccountsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountsAtLatestBlock not implemented")
+}
+func (UnimplementedExtendedAccessAPIServer) GetAccountsAtBlockHeight(context.Context, *GetAccountsAtBlockHeightRequest) (*GetAccountsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountsAtBlockHeight not implemented")
+}
+func (UnimplementedExtendedAccessAPIServer) SimulateTransaction(context.Context, *SimulateTransactionRequest) (*SimulateTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SimulateTransaction not implemented")
+}
+func (UnimplementedExtendedAccessAPIServer) GetTransactionProfile(context.Context, *GetTransactionProfileRequest) (*GetTransactionProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionProfile not implemented")
+}
+func (UnimplementedExtendedAccessAPIServer) mustEmbedUnimplementedExtendedAccessAPIServer() {}
+
+// UnsafeExtendedAccessAPIServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ExtendedAccessAPIServer will
+// result in compilation errors.
+type UnsafeExtendedAccessAPIServer interface {
+	mustEmbedUnimplementedExtendedAccessAPIServer()
+}
+
+func RegisterExtendedAccessAPIServer(s grpc.ServiceRegistrar, srv ExtendedAccessAPIServer) {
+	s.RegisterService(&ExtendedAccessAPI_ServiceDesc, srv)
+}
+
+func _ExtendedAccessAPI_SubscribeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExtendedAccessAPIServer).SubscribeEvents(m, &extendedAccessAPISubscribeEventsServer{stream})
+}
+
+type ExtendedAccessAPI_SubscribeEventsServer interface {
+	Send(*SubscribeEventsResponse) error
+	grpc.ServerStream
+}
+
+type extendedAccessAPISubscribeEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *extendedAccessAPISubscribeEventsServer) Send(m *SubscribeEventsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ExtendedAccessAPI_SubscribeTransactionStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeTransactionStatusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExtendedAccessAPIServer).SubscribeTransactionStatus(m, &extendedAccessAPISubscribeTransactionStatusServer{stream})
+}
+
+type ExtendedAccessAPI_SubscribeTransactionStatusServer interface {
+	Send(*SubscribeTransactionStatusResponse) error
+	grpc.ServerStream
+}
+
+type extendedAccessAPISubscribeTransactionStatusServer struct {
+	grpc.ServerStream
+}
+
+func (x *extendedAccessAPISubscribeTransactionStatusServer) Send(m *SubscribeTransactionStatusResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ExtendedAccessAPI_GetAccountsAtLatestBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountsAtLatestBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExtendedAccessAPIServer).GetAccountsAtLatestBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/extended.ExtendedAccessAPI/GetAccountsAtLatestBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExtendedAccessAPIServer).GetAccountsAtLatestBlock(ctx, req.(*GetAccountsAtLatestBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExtendedAccessAPI_GetAccountsAtBlockHeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountsAtBlockHeightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExtendedAccessAPIServer).GetAccountsAtBlockHeight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/extended.ExtendedAccessAPI/GetAccountsAtBlockHeight",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExtendedAccessAPIServer).GetAccountsAtBlockHeight(ctx, req.(*GetAccountsAtBlockHeightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExtendedAccessAPI_SimulateTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SimulateTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExtendedAccessAPIServer).SimulateTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/extended.ExtendedAccessAPI/SimulateTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExtendedAccessAPIServer).SimulateTransaction(ctx, req.(*SimulateTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExtendedAccessAPI_GetTransactionProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExtendedAccessAPIServer).GetTransactionProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/extended.ExtendedAccessAPI/GetTransactionProfile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExtendedAccessAPIServer).GetTransactionProfile(ctx, req.(*GetTransactionProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ExtendedAccessAPI_ServiceDesc is the grpc.ServiceDesc for ExtendedAccessAPI service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ExtendedAccessAPI_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "extended.ExtendedAccessAPI",
+	HandlerType: (*ExtendedAccessAPIServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAccountsAtLatestBlock",
+			Handler:    _ExtendedAccessAPI_GetAccountsAtLatestBlock_Handler,
+		},
+		{
+			MethodName: "GetAccountsAtBlockHeight",
+			Handler:    _ExtendedAccessAPI_GetAccountsAtBlockHeight_Handler,
+		},
+		{
+			MethodName: "SimulateTransaction",
+			Handler:    _ExtendedAccessAPI_SimulateTransaction_Handler,
+		},
+		{
+			MethodName: "GetTransactionProfile",
+			Handler:    _ExtendedAccessAPI_GetTransactionProfile_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeEvents",
+			Handler:       _ExtendedAccessAPI_SubscribeEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeTransactionStatus",
+			Handler:       _ExtendedAccessAPI_SubscribeTransactionStatus_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "extended/extended.proto",
+}
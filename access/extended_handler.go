@@ -0,0 +1,356 @@
+package access
+
+import (
+	"context"
+
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/onflow/flow-go/access/extended"
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// ExtendedHandler serves the ExtendedAccessAPI, which complements the Flow Access API
+// served by Handler.
+type ExtendedHandler struct {
+	extended.UnimplementedExtendedAccessAPIServer
+	api   API
+	chain flow.Chain
+}
+
+func NewExtendedHandler(api API, chain flow.Chain) *ExtendedHandler {
+	return &ExtendedHandler{
+		api:   api,
+		chain: chain,
+	}
+}
+
+// SubscribeEvents streams the events matching the requested filter block by block.
+func (h *ExtendedHandler) SubscribeEvents(
+	req *extended.SubscribeEventsRequest,
+	stream extended.ExtendedAccessAPI_SubscribeEventsServer,
+) error {
+	filter, err := MessageToEventFilter(req.GetFilter(), h.chain)
+	if err != nil {
+		return err
+	}
+
+	sub, err := h.api.SubscribeEvents(stream.Context(), req.GetStartHeight(), req.GetIsSealed(), filter)
+	if err != nil {
+		return err
+	}
+
+	for blockEvents := range sub.Channel() {
+		err := stream.Send(BlockEventsToSubscribeEventsResponse(blockEvents))
+		if err != nil {
+			return err
+		}
+	}
+
+	return sub.Err()
+}
+
+// SubscribeTransactionStatus streams each status transition of a transaction.
+func (h *ExtendedHandler) SubscribeTransactionStatus(
+	req *extended.SubscribeTransactionStatusRequest,
+	stream extended.ExtendedAccessAPI_SubscribeTransactionStatusServer,
+) error {
+	id, err := convert.TransactionID(req.GetId())
+	if err != nil {
+		return err
+	}
+
+	sub, err := h.api.SubscribeTransactionStatus(stream.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	for result := range sub.Channel() {
+		err := stream.Send(TransactionResultToSubscribeTransactionStatusResponse(result))
+		if err != nil {
+			return err
+		}
+	}
+
+	return sub.Err()
+}
+
+// GetAccountsAtLatestBlock gets a batch of accounts at the latest sealed block.
+func (h *ExtendedHandler) GetAccountsAtLatestBlock(
+	ctx context.Context,
+	req *extended.GetAccountsAtLatestBlockRequest,
+) (*extended.GetAccountsResponse, error) {
+	addresses, err := h.addresses(req.GetAddresses())
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := h.api.GetAccountsAtLatestBlock(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	return AccountResultsToGetAccountsResponse(results), nil
+}
+
+// GetAccountsAtBlockHeight gets a batch of accounts at the given block height.
+func (h *ExtendedHandler) GetAccountsAtBlockHeight(
+	ctx context.Context,
+	req *extended.GetAccountsAtBlockHeightRequest,
+) (*extended.GetAccountsResponse, error) {
+	addresses, err := h.addresses(req.GetAddresses())
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := h.api.GetAccountsAtBlockHeight(ctx, addresses, req.GetBlockHeight())
+	if err != nil {
+		return nil, err
+	}
+
+	return AccountResultsToGetAccountsResponse(results), nil
+}
+
+// SimulateTransaction runs a transaction against the latest sealed execution state without committing it.
+func (h *ExtendedHandler) SimulateTransaction(
+	ctx context.Context,
+	req *extended.SimulateTransactionRequest,
+) (*extended.SimulateTransactionResponse, error) {
+	tx, err := convert.MessageToTransaction(ExtendedMessageToTransactionMessage(req.GetTransaction()), h.chain)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	result, err := h.api.SimulateTransaction(ctx, &tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return TransactionSimulationResultToMessage(result), nil
+}
+
+// GetTransactionProfile returns the breakdown of the resources used to execute a transaction.
+func (h *ExtendedHandler) GetTransactionProfile(
+	ctx context.Context,
+	req *extended.GetTransactionProfileRequest,
+) (*extended.GetTransactionProfileResponse, error) {
+	id, err := convert.TransactionID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := h.api.GetTransactionProfile(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return TransactionProfileToMessage(profile), nil
+}
+
+func (h *ExtendedHandler) addresses(rawAddresses [][]byte) ([]flow.Address, error) {
+	addresses := make([]flow.Address, len(rawAddresses))
+	for i, rawAddress := range rawAddresses {
+		address, err := convert.Address(rawAddress, h.chain)
+		if err != nil {
+			return nil, err
+		}
+		addresses[i] = address
+	}
+	return addresses, nil
+}
+
+// MessageToEventFilter converts and validates an event filter received over gRPC.
+func MessageToEventFilter(m *extended.EventFilter, chain flow.Chain) (EventFilter, error) {
+	var filter EventFilter
+
+	for _, eventType := range m.GetEventTypes() {
+		t, err := convert.EventType(eventType)
+		if err != nil {
+			return EventFilter{}, err
+		}
+		filter.EventTypes = append(filter.EventTypes, flow.EventType(t))
+	}
+
+	for _, rawAddress := range m.GetAddresses() {
+		address, err := convert.Address(rawAddress, chain)
+		if err != nil {
+			return EventFilter{}, err
+		}
+		filter.Addresses = append(filter.Addresses, address)
+	}
+
+	filter.NamePrefixes = m.GetNamePrefixes()
+
+	return filter, nil
+}
+
+func BlockEventsToSubscribeEventsResponse(blockEvents flow.BlockEvents) *extended.SubscribeEventsResponse {
+	return &extended.SubscribeEventsResponse{
+		BlockId:        blockEvents.BlockID[:],
+		BlockHeight:    blockEvents.BlockHeight,
+		BlockTimestamp: timestamppb.New(blockEvents.BlockTimestamp),
+		Events:         EventsToExtendedMessages(blockEvents.Events),
+	}
+}
+
+func EventsToExtendedMessages(events []flow.Event) []*extended.Event {
+	messages := make([]*extended.Event, len(events))
+	for i, e := range events {
+		messages[i] = &extended.Event{
+			Type:             string(e.Type),
+			TransactionId:    e.TransactionID[:],
+			TransactionIndex: e.TransactionIndex,
+			EventIndex:       e.EventIndex,
+			Payload:          e.Payload,
+		}
+	}
+	return messages
+}
+
+func TransactionResultToSubscribeTransactionStatusResponse(result *TransactionResult) *extended.SubscribeTransactionStatusResponse {
+	return &extended.SubscribeTransactionStatusResponse{
+		TransactionId: result.TransactionID[:],
+		Status:        extended.TransactionStatus(result.Status),
+		BlockId:       result.BlockID[:],
+		StatusCode:    uint32(result.StatusCode),
+		ErrorMessage:  result.ErrorMessage,
+		Events:        EventsToExtendedMessages(result.Events),
+	}
+}
+
+func AccountResultsToGetAccountsResponse(results []AccountResult) *extended.GetAccountsResponse {
+	messages := make([]*extended.AccountResult, len(results))
+	for i, result := range results {
+		message := &extended.AccountResult{
+			Address: result.Address.Bytes(),
+		}
+		if result.Err != nil {
+			errStatus := status.Convert(result.Err)
+			message.ErrorCode = uint32(errStatus.Code())
+			message.ErrorMessage = errStatus.Message()
+		} else {
+			message.Account = AccountToExtendedMessage(result.Account)
+		}
+		messages[i] = message
+	}
+
+	return &extended.GetAccountsResponse{
+		Results: messages,
+	}
+}
+
+func AccountToExtendedMessage(a *flow.Account) *extended.Account {
+	keys := make([]*extended.AccountKey, len(a.Keys))
+	for i, k := range a.Keys {
+		keys[i] = &extended.AccountKey{
+			Index:          uint32(k.Index),
+			PublicKey:      k.PublicKey.Encode(),
+			SignAlgo:       uint32(k.SignAlgo),
+			HashAlgo:       uint32(k.HashAlgo),
+			Weight:         uint32(k.Weight),
+			SequenceNumber: uint32(k.SeqNumber),
+			Revoked:        k.Revoked,
+		}
+	}
+
+	return &extended.Account{
+		Address:   a.Address.Bytes(),
+		Balance:   a.Balance,
+		Keys:      keys,
+		Contracts: a.Contracts,
+	}
+}
+
+// ExtendedMessageToTransactionMessage converts the mirrored transaction message to the transaction message of the
+// Flow Access API, so it is converted and validated the same way.
+func ExtendedMessageToTransactionMessage(m *extended.Transaction) *entities.Transaction {
+	if m == nil {
+		return nil
+	}
+
+	signatures := func(sigs []*extended.Transaction_Signature) []*entities.Transaction_Signature {
+		messages := make([]*entities.Transaction_Signature, len(sigs))
+		for i, sig := range sigs {
+			messages[i] = &entities.Transaction_Signature{
+				Address:   sig.GetAddress(),
+				KeyId:     sig.GetKeyId(),
+				Signature: sig.GetSignature(),
+			}
+		}
+		return messages
+	}
+
+	var proposalKey *entities.Transaction_ProposalKey
+	if m.GetProposalKey() != nil {
+		proposalKey = &entities.Transaction_ProposalKey{
+			Address:        m.GetProposalKey().GetAddress(),
+			KeyId:          m.GetProposalKey().GetKeyId(),
+			SequenceNumber: m.GetProposalKey().GetSequenceNumber(),
+		}
+	}
+
+	return &entities.Transaction{
+		Script:             m.GetScript(),
+		Arguments:          m.GetArguments(),
+		ReferenceBlockId:   m.GetReferenceBlockId(),
+		GasLimit:           m.GetGasLimit(),
+		ProposalKey:        proposalKey,
+		Payer:              m.GetPayer(),
+		Authorizers:        m.GetAuthorizers(),
+		PayloadSignatures:  signatures(m.GetPayloadSignatures()),
+		EnvelopeSignatures: signatures(m.GetEnvelopeSignatures()),
+	}
+}
+
+func TransactionSimulationResultToMessage(result *TransactionSimulationResult) *extended.SimulateTransactionResponse {
+	updates := make([]*extended.RegisterEntry, len(result.RegisterUpdates))
+	for i, update := range result.RegisterUpdates {
+		updates[i] = &extended.RegisterEntry{
+			Owner:      []byte(update.Key.Owner),
+			Controller: []byte(update.Key.Controller),
+			Key:        []byte(update.Key.Key),
+			Value:      update.Value,
+		}
+	}
+
+	return &extended.SimulateTransactionResponse{
+		BlockId:         result.BlockID[:],
+		BlockHeight:     result.BlockHeight,
+		ComputationUsed: result.ComputationUsed,
+		MemoryUsed:      result.MemoryUsed,
+		Events:          EventsToExtendedMessages(result.Events),
+		RegisterUpdates: updates,
+		Fee:             result.Fee,
+		ErrorCode:       uint32(result.ErrorCode),
+		ErrorMessage:    result.ErrorMessage,
+	}
+}
+
+func TransactionProfileToMessage(profile *TransactionProfile) *extended.GetTransactionProfileResponse {
+	profiledKinds := func(kinds []ProfiledKind) []*extended.ProfiledKind {
+		messages := make([]*extended.ProfiledKind, len(kinds))
+		for i, kind := range kinds {
+			messages[i] = &extended.ProfiledKind{
+				Kind:      kind.Kind,
+				Name:      kind.Name,
+				Intensity: kind.Intensity,
+				Used:      kind.Used,
+			}
+		}
+		return messages
+	}
+
+	return &extended.GetTransactionProfileResponse{
+		BlockId:              profile.BlockID[:],
+		TransactionId:        profile.TransactionID[:],
+		ComputationUsed:      profile.ComputationUsed,
+		MemoryUsed:           profile.MemoryUsed,
+		ComputationKinds:     profiledKinds(profile.ComputationKinds),
+		MemoryKinds:          profiledKinds(profile.MemoryKinds),
+		RegisterBytesRead:    profile.RegisterBytesRead,
+		RegisterBytesWritten: profile.RegisterBytesWritten,
+	}
+}
@@ -4,14 +4,11 @@ package mock
 
 import (
 	context "context"
+	testing "testing"
 
 	access "github.com/onflow/flow-go/access"
-
 	flow "github.com/onflow/flow-go/model/flow"
-
 	mock "github.com/stretchr/testify/mock"
-
-	testing "testing"
 )
 
 // API is an autogenerated mock type for the API type
@@ -157,6 +154,52 @@ func (_m *API) GetAccountAtLatestBlock(ctx context.Context, address flow.Address
 	return r0, r1
 }
 
+// GetAccountsAtBlockHeight provides a mock function with given fields: ctx, addresses, height
+func (_m *API) GetAccountsAtBlockHeight(ctx context.Context, addresses []flow.Address, height uint64) ([]access.AccountResult, error) {
+	ret := _m.Called(ctx, addresses, height)
+
+	var r0 []access.AccountResult
+	if rf, ok := ret.Get(0).(func(context.Context, []flow.Address, uint64) []access.AccountResult); ok {
+		r0 = rf(ctx, addresses, height)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]access.AccountResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []flow.Address, uint64) error); ok {
+		r1 = rf(ctx, addresses, height)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAccountsAtLatestBlock provides a mock function with given fields: ctx, addresses
+func (_m *API) GetAccountsAtLatestBlock(ctx context.Context, addresses []flow.Address) ([]access.AccountResult, error) {
+	ret := _m.Called(ctx, addresses)
+
+	var r0 []access.AccountResult
+	if rf, ok := ret.Get(0).(func(context.Context, []flow.Address) []access.AccountResult); ok {
+		r0 = rf(ctx, addresses)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]access.AccountResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []flow.Address) error); ok {
+		r1 = rf(ctx, addresses)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBlockByHeight provides a mock function with given fields: ctx, height
 func (_m *API) GetBlockByHeight(ctx context.Context, height uint64) (*flow.Block, error) {
 	ret := _m.Called(ctx, height)
@@ -410,6 +453,29 @@ func (_m *API) GetLatestBlockHeader(ctx context.Context, isSealed bool) (*flow.H
 	return r0, r1
 }
 
+// GetLatestEpochInfo provides a mock function with given fields: ctx
+func (_m *API) GetLatestEpochInfo(ctx context.Context) (*access.EpochInfo, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *access.EpochInfo
+	if rf, ok := ret.Get(0).(func(context.Context) *access.EpochInfo); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*access.EpochInfo)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLatestProtocolStateSnapshot provides a mock function with given fields: ctx
 func (_m *API) GetLatestProtocolStateSnapshot(ctx context.Context) ([]byte, error) {
 	ret := _m.Called(ctx)
@@ -447,6 +513,29 @@ func (_m *API) GetNetworkParameters(ctx context.Context) access.NetworkParameter
 	return r0
 }
 
+// GetNodeVersionInfo provides a mock function with given fields: ctx
+func (_m *API) GetNodeVersionInfo(ctx context.Context) (*access.NodeVersionInfo, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *access.NodeVersionInfo
+	if rf, ok := ret.Get(0).(func(context.Context) *access.NodeVersionInfo); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*access.NodeVersionInfo)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTransaction provides a mock function with given fields: ctx, id
 func (_m *API) GetTransaction(ctx context.Context, id flow.Identifier) (*flow.TransactionBody, error) {
 	ret := _m.Called(ctx, id)
@@ -470,6 +559,29 @@ func (_m *API) GetTransaction(ctx context.Context, id flow.Identifier) (*flow.Tr
 	return r0, r1
 }
 
+// GetTransactionProfile provides a mock function with given fields: ctx, id
+func (_m *API) GetTransactionProfile(ctx context.Context, id flow.Identifier) (*access.TransactionProfile, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *access.TransactionProfile
+	if rf, ok := ret.Get(0).(func(context.Context, flow.Identifier) *access.TransactionProfile); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*access.TransactionProfile)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, flow.Identifier) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTransactionResult provides a mock function with given fields: ctx, id
 func (_m *API) GetTransactionResult(ctx context.Context, id flow.Identifier) (*access.TransactionResult, error) {
 	ret := _m.Called(ctx, id)
@@ -576,6 +688,52 @@ func (_m *API) Ping(ctx context.Context) error {
 	return r0
 }
 
+// QueryEventsForBlockIDs provides a mock function with given fields: ctx, query, blockIDs
+func (_m *API) QueryEventsForBlockIDs(ctx context.Context, query access.EventQuery, blockIDs []flow.Identifier) ([]flow.BlockEvents, error) {
+	ret := _m.Called(ctx, query, blockIDs)
+
+	var r0 []flow.BlockEvents
+	if rf, ok := ret.Get(0).(func(context.Context, access.EventQuery, []flow.Identifier) []flow.BlockEvents); ok {
+		r0 = rf(ctx, query, blockIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]flow.BlockEvents)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, access.EventQuery, []flow.Identifier) error); ok {
+		r1 = rf(ctx, query, blockIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueryEventsForHeightRange provides a mock function with given fields: ctx, query, startHeight, endHeight
+func (_m *API) QueryEventsForHeightRange(ctx context.Context, query access.EventQuery, startHeight uint64, endHeight uint64) ([]flow.BlockEvents, error) {
+	ret := _m.Called(ctx, query, startHeight, endHeight)
+
+	var r0 []flow.BlockEvents
+	if rf, ok := ret.Get(0).(func(context.Context, access.EventQuery, uint64, uint64) []flow.BlockEvents); ok {
+		r0 = rf(ctx, query, startHeight, endHeight)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]flow.BlockEvents)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, access.EventQuery, uint64, uint64) error); ok {
+		r1 = rf(ctx, query, startHeight, endHeight)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SendTransaction provides a mock function with given fields: ctx, tx
 func (_m *API) SendTransaction(ctx context.Context, tx *flow.TransactionBody) error {
 	ret := _m.Called(ctx, tx)
@@ -590,6 +748,75 @@ func (_m *API) SendTransaction(ctx context.Context, tx *flow.TransactionBody) er
 	return r0
 }
 
+// SimulateTransaction provides a mock function with given fields: ctx, tx
+func (_m *API) SimulateTransaction(ctx context.Context, tx *flow.TransactionBody) (*access.TransactionSimulationResult, error) {
+	ret := _m.Called(ctx, tx)
+
+	var r0 *access.TransactionSimulationResult
+	if rf, ok := ret.Get(0).(func(context.Context, *flow.TransactionBody) *access.TransactionSimulationResult); ok {
+		r0 = rf(ctx, tx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*access.TransactionSimulationResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *flow.TransactionBody) error); ok {
+		r1 = rf(ctx, tx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SubscribeEvents provides a mock function with given fields: ctx, startHeight, isSealed, filter
+func (_m *API) SubscribeEvents(ctx context.Context, startHeight uint64, isSealed bool, filter access.EventFilter) (access.EventsSubscription, error) {
+	ret := _m.Called(ctx, startHeight, isSealed, filter)
+
+	var r0 access.EventsSubscription
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, bool, access.EventFilter) access.EventsSubscription); ok {
+		r0 = rf(ctx, startHeight, isSealed, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(access.EventsSubscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, bool, access.EventFilter) error); ok {
+		r1 = rf(ctx, startHeight, isSealed, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SubscribeTransactionStatus provides a mock function with given fields: ctx, id
+func (_m *API) SubscribeTransactionStatus(ctx context.Context, id flow.Identifier) (access.TransactionStatusSubscription, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 access.TransactionStatusSubscription
+	if rf, ok := ret.Get(0).(func(context.Context, flow.Identifier) access.TransactionStatusSubscription); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(access.TransactionStatusSubscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, flow.Identifier) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewAPI creates a new instance of API. It also registers the testing.TB interface on the mock and a cleanup function to assert the mocks expectations.
 func NewAPI(t testing.TB) *API {
 	mock := &API{}
@@ -0,0 +1,112 @@
+package access
+
+import (
+	"strings"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// EventFilter selects the events delivered to a subscriber.
+//
+// An event matches the filter if it matches any of the configured criteria. A filter
+// without any criteria matches all events.
+type EventFilter struct {
+	// EventTypes are fully qualified event types, e.g. A.0123456789abcdef.Contract.Event.
+	EventTypes []flow.EventType
+	// Addresses are the addresses of the contracts emitting the events.
+	Addresses []flow.Address
+	// NamePrefixes are matched against the beginning of the fully qualified event type,
+	// e.g. A.0123456789abcdef.Contract. matches all events emitted by Contract.
+	NamePrefixes []string
+}
+
+// IsEmpty returns true if the filter has no criteria and therefore matches all events.
+func (f EventFilter) IsEmpty() bool {
+	return len(f.EventTypes) == 0 && len(f.Addresses) == 0 && len(f.NamePrefixes) == 0
+}
+
+// Match returns true if the given event matches the filter.
+func (f EventFilter) Match(event flow.Event) bool {
+	if f.IsEmpty() {
+		return true
+	}
+
+	for _, eventType := range f.EventTypes {
+		if event.Type == eventType {
+			return true
+		}
+	}
+
+	for _, prefix := range f.NamePrefixes {
+		if strings.HasPrefix(string(event.Type), prefix) {
+			return true
+		}
+	}
+
+	if len(f.Addresses) > 0 {
+		address, ok := contractAddress(event.Type)
+		if !ok {
+			return false
+		}
+		for _, a := range f.Addresses {
+			if a == address {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Filter returns the events matching the filter, preserving their order.
+func (f EventFilter) Filter(events []flow.Event) []flow.Event {
+	if f.IsEmpty() {
+		return events
+	}
+
+	filtered := make([]flow.Event, 0, len(events))
+	for _, event := range events {
+		if f.Match(event) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+// contractAddress extracts the address of the emitting contract from an event type of
+// the form A.<address>.<contract>.<event>. Built-in events (flow.*) have no address.
+func contractAddress(eventType flow.EventType) (flow.Address, bool) {
+	parts := strings.Split(string(eventType), ".")
+	if len(parts) != 4 || parts[0] != "A" {
+		return flow.EmptyAddress, false
+	}
+	return flow.HexToAddress(parts[1]), true
+}
+
+// EventsSubscription is a stream of events delivered block by block, in increasing
+// height order, without gaps.
+type EventsSubscription interface {
+	// Channel returns the channel on which the filtered events of each block are delivered.
+	// Blocks without matching events are still delivered, so that subscribers can track
+	// their progress and resume from the next height. The channel is closed once the
+	// subscription ends.
+	Channel() <-chan flow.BlockEvents
+
+	// Err returns the reason the subscription ended, or nil if it ended because its
+	// context was cancelled. It must only be called once the channel is closed.
+	Err() error
+}
+
+// TransactionStatusSubscription is a stream of the status transitions of a single transaction.
+type TransactionStatusSubscription interface {
+	// Channel returns the channel on which a result is delivered for each status the transaction
+	// transitions to, starting with its status at the time of subscribing. Results for the executed
+	// and sealed statuses include the execution outcome. The channel is closed once the transaction
+	// is sealed or expired, or the subscription ends otherwise.
+	Channel() <-chan *TransactionResult
+
+	// Err returns the reason the subscription ended, or nil if it ended because the transaction
+	// reached a final status or its context was cancelled. It must only be called once the channel
+	// is closed.
+	Err() error
+}
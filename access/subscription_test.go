@@ -0,0 +1,47 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+func TestEventFilter(t *testing.T) {
+	address := flow.HexToAddress("f8d6e0586b0a20c7")
+
+	events := []flow.Event{
+		{Type: "A.f8d6e0586b0a20c7.Foo.Bar"},
+		{Type: "A.f8d6e0586b0a20c7.Foo.Baz"},
+		{Type: "A.179b6b1cb6755e31.Qux.Bar"},
+		{Type: flow.EventAccountCreated},
+	}
+
+	t.Run("empty filter matches all events", func(t *testing.T) {
+		assert.Equal(t, events, EventFilter{}.Filter(events))
+	})
+
+	t.Run("event types", func(t *testing.T) {
+		filter := EventFilter{EventTypes: []flow.EventType{"A.f8d6e0586b0a20c7.Foo.Bar", flow.EventAccountCreated}}
+		assert.Equal(t, []flow.Event{events[0], events[3]}, filter.Filter(events))
+	})
+
+	t.Run("contract addresses", func(t *testing.T) {
+		filter := EventFilter{Addresses: []flow.Address{address}}
+		assert.Equal(t, []flow.Event{events[0], events[1]}, filter.Filter(events))
+	})
+
+	t.Run("name prefixes", func(t *testing.T) {
+		filter := EventFilter{NamePrefixes: []string{"A.179b6b1cb6755e31.Qux."}}
+		assert.Equal(t, []flow.Event{events[2]}, filter.Filter(events))
+	})
+
+	t.Run("criteria are combined with or", func(t *testing.T) {
+		filter := EventFilter{
+			EventTypes:   []flow.EventType{flow.EventAccountCreated},
+			NamePrefixes: []string{"A.179b6b1cb6755e31."},
+		}
+		assert.Equal(t, []flow.Event{events[2], events[3]}, filter.Filter(events))
+	})
+}
@@ -0,0 +1,116 @@
+package execution
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/onflow/flow-go/admin"
+	"github.com/onflow/flow-go/admin/commands"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+var _ commands.AdminCommand = (*ReadAccountStorageCommand)(nil)
+
+type readAccountStorageRequest struct {
+	address flow.Address
+	commit  *ledger.State // nil to read the most recently updated state
+}
+
+// ReadAccountStorageCommand returns the registers of an account at a state of the execution ledger.
+// The ledger must be created with the owner index enabled.
+type ReadAccountStorageCommand struct {
+	// the ledger is created after the admin commands, so it is only looked up when handling a request
+	ledger func() *complete.Ledger
+}
+
+func (r *ReadAccountStorageCommand) Handler(ctx context.Context, req *admin.CommandRequest) (interface{}, error) {
+	data := req.ValidatorData.(*readAccountStorageRequest)
+
+	led := r.ledger()
+	if led == nil {
+		return nil, errors.New("execution state ledger is not initialized yet, or runs in a ledger service")
+	}
+
+	var commit ledger.State
+	if data.commit != nil {
+		commit = *data.commit
+	} else {
+		var err error
+		commit, err = led.MostRecentTouchedState()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get most recent state: %w", err)
+		}
+	}
+
+	payloads, err := led.OwnerPayloads(commit, data.address.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account storage: %w", err)
+	}
+
+	registers, err := commands.ConvertToInterfaceList(payloads)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"commit":    commit.String(),
+		"registers": registers,
+	}, nil
+}
+
+func (r *ReadAccountStorageCommand) Validator(req *admin.CommandRequest) error {
+	input, ok := req.Data.(map[string]interface{})
+	if !ok {
+		return errors.New("wrong input format")
+	}
+
+	data := &readAccountStorageRequest{}
+
+	address, ok := input["address"]
+	if !ok {
+		return errors.New("the \"address\" field is required")
+	}
+	errInvalidAddressValue := fmt.Errorf("invalid value for \"address\": expected a %d character long hex string, but got: %v", 2*flow.AddressLength, address)
+	addressStr, ok := address.(string)
+	if !ok {
+		return errInvalidAddressValue
+	}
+	addressBytes, err := hex.DecodeString(addressStr)
+	if err != nil || len(addressBytes) != flow.AddressLength {
+		return errInvalidAddressValue
+	}
+	data.address = flow.BytesToAddress(addressBytes)
+
+	if commit, ok := input["commit"]; ok {
+		errInvalidCommitValue := fmt.Errorf("invalid value for \"commit\": expected a state commitment represented as a 64 character long hex string, but got: %v", commit)
+		commitStr, ok := commit.(string)
+		if !ok {
+			return errInvalidCommitValue
+		}
+		commitBytes, err := hex.DecodeString(commitStr)
+		if err != nil {
+			return errInvalidCommitValue
+		}
+		state, err := ledger.ToState(commitBytes)
+		if err != nil {
+			return errInvalidCommitValue
+		}
+		data.commit = &state
+	}
+
+	req.ValidatorData = data
+
+	return nil
+}
+
+// NewReadAccountStorageCommand creates a command which reads the registers of an account
+// from the ledger returned by the given function.
+func NewReadAccountStorageCommand(ledger func() *complete.Ledger) commands.AdminCommand {
+	return &ReadAccountStorageCommand{
+		ledger: ledger,
+	}
+}
@@ -0,0 +1,93 @@
+package execution
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/admin"
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/ownerindex"
+	"github.com/onflow/flow-go/ledger/complete/wal/fixtures"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestReadAccountStorage(t *testing.T) {
+	t.Parallel()
+
+	db := unittest.BadgerDB(t, t.TempDir())
+	defer db.Close()
+
+	led, err := complete.NewLedger(&fixtures.NoopWAL{}, 100, &metrics.NoopCollector{}, zerolog.Nop(),
+		complete.DefaultPathFinderVersion, mtrie.WithOwnerIndex(ownerindex.New(db)))
+	require.NoError(t, err)
+
+	address := unittest.RandomAddressFixture()
+	otherAddress := unittest.RandomAddressFixture()
+	keys := []ledger.Key{
+		state.RegisterIDToKey(flow.NewRegisterID(string(address.Bytes()), "", "exists")),
+		state.RegisterIDToKey(flow.NewRegisterID(string(otherAddress.Bytes()), "", "exists")),
+	}
+	update, err := ledger.NewUpdate(led.InitialState(), keys, []ledger.Value{{1}, {2}})
+	require.NoError(t, err)
+	commit, _, err := led.Set(update)
+	require.NoError(t, err)
+
+	command := NewReadAccountStorageCommand(func() *complete.Ledger { return led })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, data := range []map[string]interface{}{
+		{"address": address.Hex(), "commit": commit.String()},
+		{"address": address.Hex()}, // most recent state
+	} {
+		req := &admin.CommandRequest{Data: data}
+		require.NoError(t, command.Validator(req))
+		result, err := command.Handler(ctx, req)
+		require.NoError(t, err)
+
+		resultMap := result.(map[string]interface{})
+		require.Equal(t, commit.String(), resultMap["commit"])
+		registers := resultMap["registers"].([]interface{})
+		require.Len(t, registers, 1)
+		require.Equal(t, "01", registers[0].(map[string]interface{})["Value"])
+	}
+
+	// the account has no registers at the initial state
+	req := &admin.CommandRequest{Data: map[string]interface{}{"address": address.Hex(), "commit": led.InitialState().String()}}
+	require.NoError(t, command.Validator(req))
+	result, err := command.Handler(ctx, req)
+	require.NoError(t, err)
+	require.Empty(t, result.(map[string]interface{})["registers"])
+}
+
+func TestReadAccountStorageValidator(t *testing.T) {
+	t.Parallel()
+
+	command := NewReadAccountStorageCommand(func() *complete.Ledger { return nil })
+
+	for _, data := range []interface{}{
+		"not a map",
+		map[string]interface{}{},
+		map[string]interface{}{"address": 1},
+		map[string]interface{}{"address": "zz"},
+		map[string]interface{}{"address": "0102"},
+		map[string]interface{}{"address": unittest.RandomAddressFixture().Hex(), "commit": "0102"},
+	} {
+		require.Error(t, command.Validator(&admin.CommandRequest{Data: data}))
+	}
+
+	// requests fail until the ledger is created
+	req := &admin.CommandRequest{Data: map[string]interface{}{"address": unittest.RandomAddressFixture().Hex()}}
+	require.NoError(t, command.Validator(req))
+	_, err := command.Handler(context.Background(), req)
+	require.Error(t, err)
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -23,6 +25,7 @@ import (
 	"github.com/onflow/flow-go/consensus/hotstuff/verification"
 	recovery "github.com/onflow/flow-go/consensus/recovery/protocol"
 	"github.com/onflow/flow-go/engine/access/ingestion"
+	"github.com/onflow/flow-go/engine/access/ratelimit"
 	"github.com/onflow/flow-go/engine/access/rpc"
 	"github.com/onflow/flow-go/engine/access/rpc/backend"
 	"github.com/onflow/flow-go/engine/common/follower"
@@ -38,6 +41,7 @@ import (
 	"github.com/onflow/flow-go/module/id"
 	"github.com/onflow/flow-go/module/mempool/stdmap"
 	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/module/state_synchronization"
 	"github.com/onflow/flow-go/module/synchronization"
 	"github.com/onflow/flow-go/network"
 	netcache "github.com/onflow/flow-go/network/cache"
@@ -98,6 +102,9 @@ type AccessNodeConfig struct {
 	logTxTimeToFinalizedExecuted bool
 	retryEnabled                 bool
 	rpcMetricsEnabled            bool
+	localScriptExecutionEnabled  bool   // whether to execute scripts locally against the registers indexed from execution data
+	executionDataDir             string // directory of the datastore holding the execution data blobs
+	rootCheckpointFile           string // checkpoint of the root execution state, defaults to the one in the bootstrap directory
 	baseOptions                  []cmd.Option
 
 	PublicNetworkConfig PublicNetworkConfig
@@ -112,6 +119,7 @@ type PublicNetworkConfig struct {
 
 // DefaultAccessNodeConfig defines all the default values for the AccessNodeConfig
 func DefaultAccessNodeConfig() *AccessNodeConfig {
+	homedir, _ := os.UserHomeDir()
 	return &AccessNodeConfig{
 		collectionGRPCPort: 9000,
 		executionGRPCPort:  9000,
@@ -127,6 +135,21 @@ func DefaultAccessNodeConfig() *AccessNodeConfig {
 			MaxHeightRange:            backend.DefaultMaxHeightRange,
 			PreferredExecutionNodeIDs: nil,
 			FixedExecutionNodeIDs:     nil,
+			ConnectionPoolSize:        0,
+			MaxConnectionIdleTime:     5 * time.Minute,
+			CircuitBreakerConfig: backend.CircuitBreakerConfig{
+				Enabled:        false,
+				MaxFailures:    5,
+				RestoreTimeout: 60 * time.Second,
+			},
+			ClientRateLimits: ratelimit.Config{
+				MethodLimits: nil,
+				MethodBursts: nil,
+				DefaultLimit: 0,
+				DefaultBurst: 0,
+				APIKeyHeader: "",
+				MaxClients:   ratelimit.DefaultMaxClients,
+			},
 		},
 		ExecutionNodeAddress:         "localhost:9000",
 		logTxTimeToFinalized:         false,
@@ -135,6 +158,9 @@ func DefaultAccessNodeConfig() *AccessNodeConfig {
 		pingEnabled:                  false,
 		retryEnabled:                 false,
 		rpcMetricsEnabled:            false,
+		localScriptExecutionEnabled:  false,
+		executionDataDir:             filepath.Join(homedir, ".flow", "execution_data_blobstore"),
+		rootCheckpointFile:           "",
 		nodeInfoFile:                 "",
 		apiRatelimits:                nil,
 		apiBurstlimits:               nil,
@@ -170,7 +196,11 @@ type FlowAccessNodeBuilder struct {
 	CollectionsToMarkExecuted  *stdmap.Times
 	BlocksToMarkExecuted       *stdmap.Times
 	TransactionMetrics         module.TransactionMetrics
+	AccessMetrics              module.AccessMetrics
 	PingMetrics                module.PingMetrics
+	ExecutionDataService       state_synchronization.ExecutionDataService
+	Registers                  *storage.Registers
+	ScriptExecutor             backend.ScriptExecutor // nil unless scripts are executed locally
 	Committee                  hotstuff.Committee
 	Finalized                  *flow.Header
 	Pending                    []*flow.Header
@@ -442,6 +472,15 @@ func (builder *FlowAccessNodeBuilder) extraFlags() {
 		flags.DurationVar(&builder.rpcConf.CollectionClientTimeout, "collection-client-timeout", defaultConfig.rpcConf.CollectionClientTimeout, "grpc client timeout for a collection node")
 		flags.DurationVar(&builder.rpcConf.ExecutionClientTimeout, "execution-client-timeout", defaultConfig.rpcConf.ExecutionClientTimeout, "grpc client timeout for an execution node")
 		flags.UintVar(&builder.rpcConf.MaxHeightRange, "rpc-max-height-range", defaultConfig.rpcConf.MaxHeightRange, "maximum size for height range requests")
+		flags.UintVar(&builder.rpcConf.ConnectionPoolSize, "connection-pool-size", defaultConfig.rpcConf.ConnectionPoolSize, "maximum number of cached connections to collection and execution nodes, connections are not reused if 0")
+		flags.DurationVar(&builder.rpcConf.MaxConnectionIdleTime, "connection-max-idle-time", defaultConfig.rpcConf.MaxConnectionIdleTime, "duration after which unused cached connections to collection and execution nodes are closed")
+		flags.BoolVar(&builder.rpcConf.CollectionNodeTLS.Enabled, "collection-tls-enabled", defaultConfig.rpcConf.CollectionNodeTLS.Enabled, "whether to use TLS for requests to collection nodes")
+		flags.StringVar(&builder.rpcConf.CollectionNodeTLS.CACertFile, "collection-tls-ca-cert-file", defaultConfig.rpcConf.CollectionNodeTLS.CACertFile, "PEM file of the CA certificates used to verify collection node certificates, if empty the certificates are pinned against the networking keys of the nodes")
+		flags.BoolVar(&builder.rpcConf.ExecutionNodeTLS.Enabled, "execution-tls-enabled", defaultConfig.rpcConf.ExecutionNodeTLS.Enabled, "whether to use TLS for requests to execution nodes")
+		flags.StringVar(&builder.rpcConf.ExecutionNodeTLS.CACertFile, "execution-tls-ca-cert-file", defaultConfig.rpcConf.ExecutionNodeTLS.CACertFile, "PEM file of the CA certificates used to verify execution node certificates, if empty the certificates are pinned against the networking keys of the nodes")
+		flags.BoolVar(&builder.rpcConf.CircuitBreakerConfig.Enabled, "circuit-breaker-enabled", defaultConfig.rpcConf.CircuitBreakerConfig.Enabled, "whether to stop sending requests to collection and execution nodes which repeatedly failed")
+		flags.Uint32Var(&builder.rpcConf.CircuitBreakerConfig.MaxFailures, "circuit-breaker-max-failures", defaultConfig.rpcConf.CircuitBreakerConfig.MaxFailures, "number of consecutive failed requests after which a node is considered unhealthy")
+		flags.DurationVar(&builder.rpcConf.CircuitBreakerConfig.RestoreTimeout, "circuit-breaker-restore-timeout", defaultConfig.rpcConf.CircuitBreakerConfig.RestoreTimeout, "duration after which requests to an unhealthy node are attempted again")
 		flags.StringSliceVar(&builder.rpcConf.PreferredExecutionNodeIDs, "preferred-execution-node-ids", defaultConfig.rpcConf.PreferredExecutionNodeIDs, "comma separated list of execution nodes ids to choose from when making an upstream call e.g. b4a4dbdcd443d...,fb386a6a... etc.")
 		flags.StringSliceVar(&builder.rpcConf.FixedExecutionNodeIDs, "fixed-execution-node-ids", defaultConfig.rpcConf.FixedExecutionNodeIDs, "comma separated list of execution nodes ids to choose from when making an upstream call if no matching preferred execution id is found e.g. b4a4dbdcd443d...,fb386a6a... etc.")
 		flags.BoolVar(&builder.logTxTimeToFinalized, "log-tx-time-to-finalized", defaultConfig.logTxTimeToFinalized, "log transaction time to finalized")
@@ -450,9 +489,19 @@ func (builder *FlowAccessNodeBuilder) extraFlags() {
 		flags.BoolVar(&builder.pingEnabled, "ping-enabled", defaultConfig.pingEnabled, "whether to enable the ping process that pings all other peers and report the connectivity to metrics")
 		flags.BoolVar(&builder.retryEnabled, "retry-enabled", defaultConfig.retryEnabled, "whether to enable the retry mechanism at the access node level")
 		flags.BoolVar(&builder.rpcMetricsEnabled, "rpc-metrics-enabled", defaultConfig.rpcMetricsEnabled, "whether to enable the rpc metrics")
+		flags.BoolVar(&builder.localScriptExecutionEnabled, "local-script-execution-enabled", defaultConfig.localScriptExecutionEnabled, "whether to execute scripts locally against the execution state indexed from execution data, scripts at heights which are not indexed are forwarded to execution nodes")
+		flags.BoolVar(&builder.rpcConf.SimulateTransactions, "transaction-simulation-enabled", defaultConfig.rpcConf.SimulateTransactions, "whether to simulate transactions without committing them, which requires local-script-execution-enabled as transactions are only simulated against the locally indexed execution state")
+		flags.StringVar(&builder.executionDataDir, "execution-data-dir", defaultConfig.executionDataDir, "directory to use for the execution data blobstore")
+		flags.StringVar(&builder.rootCheckpointFile, "root-checkpoint-file", defaultConfig.rootCheckpointFile, "checkpoint of the root execution state the register index is bootstrapped from, defaults to the root checkpoint in the bootstrap directory")
 		flags.StringVarP(&builder.nodeInfoFile, "node-info-file", "", defaultConfig.nodeInfoFile, "full path to a json file which provides more details about nodes when reporting its reachability metrics")
 		flags.StringToIntVar(&builder.apiRatelimits, "api-rate-limits", defaultConfig.apiRatelimits, "per second rate limits for Access API methods e.g. Ping=300,GetTransaction=500 etc.")
 		flags.StringToIntVar(&builder.apiBurstlimits, "api-burst-limits", defaultConfig.apiBurstlimits, "burst limits for Access API methods e.g. Ping=100,GetTransaction=100 etc.")
+		flags.StringToIntVar(&builder.rpcConf.ClientRateLimits.MethodLimits, "client-rate-limits", defaultConfig.rpcConf.ClientRateLimits.MethodLimits, "per second rate limits of each client for gRPC methods and REST routes e.g. ExecuteScriptAtLatestBlock=5,executeScript=5,GetEventsForHeightRange=10 etc.")
+		flags.StringToIntVar(&builder.rpcConf.ClientRateLimits.MethodBursts, "client-burst-limits", defaultConfig.rpcConf.ClientRateLimits.MethodBursts, "burst limits of each client for gRPC methods and REST routes, defaults to the rate limit e.g. ExecuteScriptAtLatestBlock=10 etc.")
+		flags.IntVar(&builder.rpcConf.ClientRateLimits.DefaultLimit, "client-default-rate-limit", defaultConfig.rpcConf.ClientRateLimits.DefaultLimit, "per second rate limit of each client for the methods without a client rate limit, unlimited if 0")
+		flags.IntVar(&builder.rpcConf.ClientRateLimits.DefaultBurst, "client-default-burst-limit", defaultConfig.rpcConf.ClientRateLimits.DefaultBurst, "burst limit of each client for the methods without a client rate limit, defaults to the default rate limit")
+		flags.StringVar(&builder.rpcConf.ClientRateLimits.APIKeyHeader, "client-api-key-header", defaultConfig.rpcConf.ClientRateLimits.APIKeyHeader, "request header identifying clients for rate limiting, only set it if the header is set by a trusted proxy, clients are identified by IP address if empty")
+		flags.UintVar(&builder.rpcConf.ClientRateLimits.MaxClients, "client-rate-limit-max-clients", defaultConfig.rpcConf.ClientRateLimits.MaxClients, "number of clients whose rate limits are tracked")
 		flags.BoolVar(&builder.staked, "staked", defaultConfig.staked, "whether this node is a staked access node or not")
 		flags.StringVar(&builder.observerNetworkingKeyPath, "observer-networking-key-path", defaultConfig.observerNetworkingKeyPath, "path to the networking key for observer")
 		flags.StringSliceVar(&builder.bootstrapNodeAddresses, "bootstrap-node-addresses", defaultConfig.bootstrapNodeAddresses, "the network addresses of the bootstrap access node if this is an unstaked access node e.g. access-001.mainnet.flow.org:9653,access-002.mainnet.flow.org:9653")
@@ -463,6 +512,9 @@ func (builder *FlowAccessNodeBuilder) extraFlags() {
 		if builder.supportsUnstakedFollower && (builder.PublicNetworkConfig.BindAddress == cmd.NotSet || builder.PublicNetworkConfig.BindAddress == "") {
 			return errors.New("public-network-address must be set if supports-unstaked-node is true")
 		}
+		if builder.rpcConf.SimulateTransactions && !(builder.staked && builder.localScriptExecutionEnabled) {
+			return errors.New("transaction-simulation-enabled requires a staked node with local-script-execution-enabled")
+		}
 
 		return nil
 	})
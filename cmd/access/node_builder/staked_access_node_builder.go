@@ -3,8 +3,11 @@ package node_builder
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
+	badgerds "github.com/ipfs/go-ds-badger2"
 	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/routing"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
@@ -17,12 +20,17 @@ import (
 
 	"github.com/onflow/flow-go/cmd"
 	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/engine/access/index"
 	"github.com/onflow/flow-go/engine/access/ingestion"
 	pingeng "github.com/onflow/flow-go/engine/access/ping"
 	"github.com/onflow/flow-go/engine/access/rpc"
 	"github.com/onflow/flow-go/engine/access/rpc/backend"
 	"github.com/onflow/flow-go/engine/common/requester"
 	synceng "github.com/onflow/flow-go/engine/common/synchronization"
+	"github.com/onflow/flow-go/engine/execution/history"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/model/bootstrap"
+	"github.com/onflow/flow-go/model/encoding/cbor"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
 	"github.com/onflow/flow-go/module"
@@ -30,12 +38,15 @@ import (
 	"github.com/onflow/flow-go/module/mempool/stdmap"
 	"github.com/onflow/flow-go/module/metrics"
 	"github.com/onflow/flow-go/module/metrics/unstaked"
+	"github.com/onflow/flow-go/module/state_synchronization"
 	"github.com/onflow/flow-go/network"
 	netcache "github.com/onflow/flow-go/network/cache"
+	"github.com/onflow/flow-go/network/compressor"
 	"github.com/onflow/flow-go/network/p2p"
 	"github.com/onflow/flow-go/network/p2p/unicast"
 	relaynet "github.com/onflow/flow-go/network/relay"
 	"github.com/onflow/flow-go/network/topology"
+	storage "github.com/onflow/flow-go/storage/badger"
 	"github.com/onflow/flow-go/utils/grpcutils"
 )
 
@@ -186,10 +197,39 @@ func (builder *StakedAccessNodeBuilder) Build() (cmd.Node, error) {
 				builder.logTxTimeToExecuted, builder.logTxTimeToFinalizedExecuted)
 			return nil
 		}).
+		Module("access metrics", func(node *cmd.NodeConfig) error {
+			builder.AccessMetrics = metrics.NewAccessCollector()
+			return nil
+		}).
 		Module("ping metrics", func(node *cmd.NodeConfig) error {
 			builder.PingMetrics = metrics.NewPingCollector()
 			return nil
 		}).
+		Module("local script executor", func(node *cmd.NodeConfig) error {
+			if !builder.localScriptExecutionEnabled {
+				return nil
+			}
+
+			registers, err := storage.NewRegisters(node.DB)
+			if err != nil {
+				return fmt.Errorf("could not create register index: %w", err)
+			}
+
+			checkpointFile := builder.rootCheckpointFile
+			if checkpointFile == "" {
+				checkpointFile = filepath.Join(node.BootstrapDir, bootstrap.PathRootCheckpoint)
+			}
+			err = history.Bootstrap(node.Logger, registers, checkpointFile, node.RootBlock.Header.Height, node.RootSeal.FinalState)
+			if err != nil {
+				return fmt.Errorf("could not bootstrap register index: %w", err)
+			}
+			builder.Registers = registers
+
+			vm := fvm.NewVirtualMachine(fvm.NewInterpreterRuntime())
+			vmCtx := fvm.NewContext(node.Logger, node.FvmOptions...)
+			builder.ScriptExecutor = index.NewScriptExecutor(node.Logger, vm, vmCtx, node.Storage.Headers, registers)
+			return nil
+		}).
 		Module("server certificate", func(node *cmd.NodeConfig) error {
 			// generate the server certificate that will be served by the GRPC server
 			x509Certificate, err := grpcutils.X509Certificate(node.NetworkKey)
@@ -201,7 +241,8 @@ func (builder *StakedAccessNodeBuilder) Build() (cmd.Node, error) {
 			return nil
 		}).
 		Component("RPC engine", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
-			builder.RpcEng = rpc.New(
+			var err error
+			builder.RpcEng, err = rpc.New(
 				node.Logger,
 				node.State,
 				builder.rpcConf,
@@ -215,6 +256,8 @@ func (builder *StakedAccessNodeBuilder) Build() (cmd.Node, error) {
 				node.Storage.Results,
 				node.RootChainID,
 				builder.TransactionMetrics,
+				builder.AccessMetrics,
+				builder.ScriptExecutor,
 				builder.collectionGRPCPort,
 				builder.executionGRPCPort,
 				builder.retryEnabled,
@@ -222,6 +265,11 @@ func (builder *StakedAccessNodeBuilder) Build() (cmd.Node, error) {
 				builder.apiRatelimits,
 				builder.apiBurstlimits,
 			)
+			if err != nil {
+				return nil, err
+			}
+			// look up the collection and execution nodes in the identity table of each new epoch
+			node.ProtocolEvents.AddConsumer(builder.RpcEng)
 			return builder.RpcEng, nil
 		}).
 		Component("ingestion engine", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
@@ -258,6 +306,10 @@ func (builder *StakedAccessNodeBuilder) Build() (cmd.Node, error) {
 			return builder.RequestEng, nil
 		})
 
+	if builder.localScriptExecutionEnabled {
+		builder.enqueueRegisterIndexer()
+	}
+
 	if builder.supportsUnstakedFollower {
 		builder.Component("unstaked sync request handler", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
 			syncRequestHandler, err := synceng.NewRequestHandlerEngine(
@@ -300,6 +352,54 @@ func (builder *StakedAccessNodeBuilder) Build() (cmd.Node, error) {
 	return builder.FlowAccessNodeBuilder.Build()
 }
 
+// enqueueRegisterIndexer enqueues the components downloading the execution data of sealed blocks from the
+// execution nodes and indexing the registers used to execute scripts locally
+func (builder *StakedAccessNodeBuilder) enqueueRegisterIndexer() {
+	builder.
+		Component("execution data service", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
+			err := os.MkdirAll(builder.executionDataDir, 0700)
+			if err != nil {
+				return nil, err
+			}
+
+			ds, err := badgerds.NewDatastore(builder.executionDataDir, &badgerds.DefaultOptions)
+			if err != nil {
+				return nil, err
+			}
+			builder.ShutdownFunc(ds.Close)
+
+			bs, err := node.Network.RegisterBlobService(engine.ExecutionDataService, ds)
+			if err != nil {
+				return nil, fmt.Errorf("could not register blob service: %w", err)
+			}
+
+			eds := state_synchronization.NewExecutionDataService(
+				&cbor.Codec{},
+				compressor.NewLz4Compressor(),
+				bs,
+				metrics.NewExecutionDataServiceCollector(),
+				node.Logger,
+			)
+			builder.ExecutionDataService = eds
+
+			return eds, nil
+		}).
+		Component("register indexer", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
+			indexer := index.NewIndexer(
+				node.Logger,
+				node.State,
+				node.Storage.Blocks,
+				node.Storage.Headers,
+				node.Storage.Results,
+				builder.Registers,
+				builder.ExecutionDataService,
+			)
+			builder.FinalizationDistributor.AddOnBlockFinalizedConsumer(indexer.OnFinalizedBlock)
+
+			return indexer, nil
+		})
+}
+
 // enqueueUnstakedNetworkInit enqueues the unstaked network component initialized for the staked node
 func (builder *StakedAccessNodeBuilder) enqueueUnstakedNetworkInit() {
 	builder.Component("unstaked network", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
@@ -48,6 +48,7 @@ import (
 	"github.com/onflow/flow-go/state/protocol/blocktimer"
 	"github.com/onflow/flow-go/state/protocol/events/gadgets"
 	storagekv "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/utils/grpcutils"
 )
 
 func main() {
@@ -72,6 +73,9 @@ func main() {
 		followerState           protocol.MutableState
 		ingestConf              = ingest.DefaultConfig()
 		rpcConf                 rpc.Config
+		rpcTLSEnabled           bool
+		rpcTLSCertFile          string
+		rpcTLSKeyFile           string
 		clusterComplianceConfig modulecompliance.Config
 
 		pools                   *epochpool.TransactionPools // epoch-scoped transaction pools
@@ -101,6 +105,12 @@ func main() {
 			"the address the ingress server listens on")
 		flags.BoolVar(&rpcConf.RpcMetricsEnabled, "rpc-metrics-enabled", false,
 			"whether to enable the rpc metrics")
+		flags.BoolVar(&rpcTLSEnabled, "rpc-tls-enabled", false,
+			"whether the ingress server serves requests over TLS")
+		flags.StringVar(&rpcTLSCertFile, "rpc-tls-cert-file", "",
+			"path to the PEM certificate of the ingress server, the certificate generated from the networking key is used if empty")
+		flags.StringVar(&rpcTLSKeyFile, "rpc-tls-key-file", "",
+			"path to the PEM private key of the ingress server certificate")
 		flags.Uint64Var(&ingestConf.MaxGasLimit, "ingest-max-gas-limit", flow.DefaultMaxTransactionGasLimit,
 			"maximum per-transaction computation limit (gas limit)")
 		flags.Uint64Var(&ingestConf.MaxTransactionByteSize, "ingest-max-tx-byte-size", flow.DefaultMaxTransactionByteSize,
@@ -362,6 +372,13 @@ func main() {
 			return ing, err
 		}).
 		Component("transaction ingress rpc server", func(node *cmd.NodeConfig) (module.ReadyDoneAware, error) {
+			if rpcTLSEnabled {
+				creds, err := grpcutils.ServerTransportCredentials(node.NetworkKey, rpcTLSCertFile, rpcTLSKeyFile)
+				if err != nil {
+					return nil, fmt.Errorf("could not create ingress server TLS credentials: %w", err)
+				}
+				rpcConf.TransportCredentials = creds
+			}
 			server := rpc.New(rpcConf, ing, node.Logger, node.RootChainID)
 			return server, nil
 		}).
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -12,6 +14,7 @@ import (
 
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	badgerdb "github.com/dgraph-io/badger/v2"
 	"github.com/ipfs/go-bitswap"
 	badger "github.com/ipfs/go-ds-badger2"
 	"github.com/onflow/cadence/runtime"
@@ -23,6 +26,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/onflow/flow-go/admin/commands"
+	executionCommands "github.com/onflow/flow-go/admin/commands/execution"
 	stateSyncCommands "github.com/onflow/flow-go/admin/commands/state_synchronization"
 	uploaderCommands "github.com/onflow/flow-go/admin/commands/uploader"
 	"github.com/onflow/flow-go/consensus"
@@ -40,6 +44,7 @@ import (
 	"github.com/onflow/flow-go/engine/execution/computation"
 	"github.com/onflow/flow-go/engine/execution/computation/committer"
 	"github.com/onflow/flow-go/engine/execution/computation/computer/uploader"
+	"github.com/onflow/flow-go/engine/execution/history"
 	"github.com/onflow/flow-go/engine/execution/ingestion"
 	exeprovider "github.com/onflow/flow-go/engine/execution/provider"
 	"github.com/onflow/flow-go/engine/execution/rpc"
@@ -52,7 +57,11 @@ import (
 	"github.com/onflow/flow-go/fvm/systemcontracts"
 	"github.com/onflow/flow-go/ledger/common/pathfinder"
 	ledger "github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/ownerindex"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/payloadstore"
 	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/ledger/remote"
 	bootstrapFilenames "github.com/onflow/flow-go/model/bootstrap"
 	"github.com/onflow/flow-go/model/encoding/cbor"
 	"github.com/onflow/flow-go/model/flow"
@@ -69,20 +78,42 @@ import (
 	"github.com/onflow/flow-go/state/protocol"
 	badgerState "github.com/onflow/flow-go/state/protocol/badger"
 	"github.com/onflow/flow-go/state/protocol/blocktimer"
+	storerr "github.com/onflow/flow-go/storage"
 	storage "github.com/onflow/flow-go/storage/badger"
+	sutil "github.com/onflow/flow-go/storage/util"
+	"github.com/onflow/flow-go/utils/grpcutils"
 )
 
+// ledgerServiceConnectTimeout is the time the node waits for the ledger service to be reachable on startup.
+const ledgerServiceConnectTimeout = 5 * time.Minute
+
 type ExecutionConfig struct {
 	rpcConf                     rpc.Config
+	rpcTLSEnabled               bool
+	rpcTLSCertFile              string
+	rpcTLSKeyFile               string
 	triedir                     string
 	executionDataDir            string
 	mTrieCacheSize              uint32
+	ledgerPayloadDir            string
+	ledgerPayloadCacheSize      uint
 	transactionResultsCacheSize uint
 	checkpointDistance          uint
 	checkpointsToKeep           uint
+	maxDeltaCheckpoints         uint
+	checkpointV6                bool
+	walRepair                   string
+	walStrictReplay             bool
+	ledgerOwnerIndexDir         string
+	ledgerServiceAddr           string
+	ledgerServiceTLSCAFile      string
+	ledgerServiceTLSCertFile    string
+	ledgerServiceTLSKeyFile     string
+	registerHistory             bool
 	stateDeltasLimit            uint
 	cadenceExecutionCache       uint
 	cadenceTracing              bool
+	parallelExecutionWorkers    int
 	chdpCacheSize               uint
 	requestInterval             time.Duration
 	preferredExeNodeIDStr       string
@@ -93,6 +124,9 @@ type ExecutionConfig struct {
 	pauseExecution              bool
 	scriptLogThreshold          time.Duration
 	scriptExecutionTimeLimit    time.Duration
+	scriptExecutionWorkers      int
+	scriptExecutionQueueSize    int
+	programsWarmUpLimit         uint
 	chdpQueryTimeout            uint
 	chdpDeliveryTimeout         uint
 	enableBlockDataUpload       bool
@@ -121,22 +155,63 @@ func (e *ExecutionNodeBuilder) LoadFlags() {
 
 			flags.StringVarP(&e.exeConf.rpcConf.ListenAddr, "rpc-addr", "i", "localhost:9000", "the address the gRPC server listens on")
 			flags.BoolVar(&e.exeConf.rpcConf.RpcMetricsEnabled, "rpc-metrics-enabled", false, "whether to enable the rpc metrics")
+			flags.BoolVar(&e.exeConf.rpcTLSEnabled, "rpc-tls-enabled", false, "whether the gRPC server serves requests over TLS")
+			flags.StringVar(&e.exeConf.rpcTLSCertFile, "rpc-tls-cert-file", "", "path to the PEM certificate of the gRPC server, the certificate generated from the networking key is used if empty")
+			flags.StringVar(&e.exeConf.rpcTLSKeyFile, "rpc-tls-key-file", "", "path to the PEM private key of the gRPC server certificate")
 			flags.StringVar(&e.exeConf.triedir, "triedir", datadir, "directory to store the execution State")
 			flags.StringVar(&e.exeConf.executionDataDir, "execution-data-dir", filepath.Join(homedir, ".flow", "execution_data_blobstore"),
 				"directory to use for Execution Data blobstore")
 			flags.Uint32Var(&e.exeConf.mTrieCacheSize, "mtrie-cache-size", 500, "cache size for MTrie")
+			flags.StringVar(&e.exeConf.ledgerPayloadDir, "ledger-payload-dir", "",
+				"directory to store the register payloads of the execution State on disk, instead of in memory, also while loading and creating checkpoints (empty to keep them in memory). Checkpoints of version 4 and earlier are still loaded into memory")
+			flags.UintVar(&e.exeConf.ledgerPayloadCacheSize, "ledger-payload-cache-size", payloadstore.DefaultCacheSize,
+				"number of register payloads cached in memory when they are stored on disk")
 			flags.UintVar(&e.exeConf.checkpointDistance, "checkpoint-distance", 20, "number of WAL segments between checkpoints")
 			flags.UintVar(&e.exeConf.checkpointsToKeep, "checkpoints-to-keep", 5, "number of recent checkpoints to keep (0 to keep all)")
+			flags.UintVar(&e.exeConf.maxDeltaCheckpoints, "max-delta-checkpoints", 0,
+				fmt.Sprintf("maximum number of delta checkpoints created between full checkpoints, at most %d (0 to only create full checkpoints)", wal.MaxDeltaCheckpoints))
+			flags.BoolVar(&e.exeConf.checkpointV6, "checkpoint-v6", false,
+				"create full checkpoints in version 6 instead of version 5. To roll back, disable it and wait for the next full checkpoint before downgrading")
+			flags.StringVar(&e.exeConf.walRepair, "ledger-wal-repair", "",
+				"check the integrity of the ledger WAL on startup, and handle a corrupted WAL tail: "+
+					"dry-run (only report it), truncate (remove it) or quarantine (move it to the quarantine directory in triedir). "+
+					"Empty to skip the check")
+			flags.BoolVar(&e.exeConf.walStrictReplay, "ledger-wal-strict-replay", false,
+				"fail on a corrupted record when replaying the ledger WAL on startup, instead of skipping the rest of the WAL")
+			flags.StringVar(&e.exeConf.ledgerOwnerIndexDir, "ledger-owner-index-dir", "",
+				"directory to store an index from account addresses to ledger registers, to list the registers of an account "+
+					"(empty to disable the index)")
+			flags.StringVar(&e.exeConf.ledgerServiceAddr, "ledger-service-addr", "",
+				"address of a ledger service (see cmd/ledger) to use instead of running the ledger in the node, "+
+					"the WAL, checkpoints and ledger flags then only apply to the service (empty to run the ledger in the node)")
+			flags.StringVar(&e.exeConf.ledgerServiceTLSCAFile, "ledger-service-tls-ca-file", "",
+				"path to the PEM certificates of the CAs of the ledger service certificate, the system CAs are used if empty")
+			flags.StringVar(&e.exeConf.ledgerServiceTLSCertFile, "ledger-service-tls-cert-file", "",
+				"path to the PEM client certificate presented to the ledger service, the connection is insecure if neither a CA nor a client certificate is set")
+			flags.StringVar(&e.exeConf.ledgerServiceTLSKeyFile, "ledger-service-tls-key-file", "",
+				"path to the PEM private key of the ledger service client certificate")
+			flags.BoolVar(&e.exeConf.registerHistory, "register-history", false,
+				"keep the history of the register values at each finalized height, to execute scripts and read registers at blocks "+
+					"which are no longer in the execution state forest. It must be enabled when the node is bootstrapped")
 			flags.UintVar(&e.exeConf.stateDeltasLimit, "state-deltas-limit", 100, "maximum number of state deltas in the memory pool")
 			flags.UintVar(&e.exeConf.cadenceExecutionCache, "cadence-execution-cache", computation.DefaultProgramsCacheSize,
 				"cache size for Cadence execution")
 			flags.BoolVar(&e.exeConf.cadenceTracing, "cadence-tracing", false, "enables cadence runtime level tracing")
+			flags.IntVar(&e.exeConf.parallelExecutionWorkers, "parallel-execution-workers", 1,
+				"number of transactions of a collection executed concurrently, transactions conflicting with the preceding ones "+
+					"are executed again (1 to execute transactions one after the other)")
 			flags.UintVar(&e.exeConf.chdpCacheSize, "chdp-cache", storage.DefaultCacheSize, "cache size for Chunk Data Packs")
 			flags.DurationVar(&e.exeConf.requestInterval, "request-interval", 60*time.Second, "the interval between requests for the requester engine")
 			flags.DurationVar(&e.exeConf.scriptLogThreshold, "script-log-threshold", computation.DefaultScriptLogThreshold,
 				"threshold for logging script execution")
 			flags.DurationVar(&e.exeConf.scriptExecutionTimeLimit, "script-execution-time-limit", computation.DefaultScriptExecutionTimeLimit,
 				"script execution time limit")
+			flags.IntVar(&e.exeConf.scriptExecutionWorkers, "script-execution-workers", computation.DefaultScriptExecutionWorkers,
+				"number of scripts executed concurrently, 0 for no limit")
+			flags.IntVar(&e.exeConf.scriptExecutionQueueSize, "script-execution-queue-size", computation.DefaultScriptExecutionQueueSize,
+				"number of scripts waiting to be executed before new scripts are rejected")
+			flags.UintVar(&e.exeConf.programsWarmUpLimit, "programs-warm-up-limit", computation.DefaultProgramsWarmUpLimit,
+				"number of the most used contract programs loaded at startup, 0 to disable")
 			flags.StringVar(&e.exeConf.preferredExeNodeIDStr, "preferred-exe-node-id", "", "node ID for preferred execution node used for state sync")
 			flags.UintVar(&e.exeConf.transactionResultsCacheSize, "transaction-results-cache-size", 10000, "number of transaction results to be cached")
 			flags.BoolVar(&e.exeConf.syncByBlocks, "sync-by-blocks", true, "deprecated, sync by blocks instead of execution state deltas")
@@ -158,6 +233,11 @@ func (e *ExecutionNodeBuilder) LoadFlags() {
 				"TTL for new blobs added to the execution data service blobstore")
 		}).
 		ValidateFlags(func() error {
+			if e.exeConf.walRepair != "" {
+				if _, err := wal.ParseRepairMode(e.exeConf.walRepair); err != nil {
+					return fmt.Errorf("invalid flag. ledger-wal-repair: %w", err)
+				}
+			}
 			if e.exeConf.enableBlockDataUpload {
 				if e.exeConf.gcpBucketName == "" && e.exeConf.s3BucketName == "" {
 					return fmt.Errorf("invalid flag. gcp-bucket-name or s3-bucket-name required when blockdata-uploader is enabled")
@@ -173,10 +253,14 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 		executionDataServiceCollector module.ExecutionDataServiceMetrics
 		executionState                state.ExecutionState
 		followerState                 protocol.MutableState
-		ledgerStorage                 *ledger.Ledger
+		ledgerStorage                 *ledger.Ledger             // nil if the ledger runs in a ledger service
+		executionLedger               remote.Ledger              // the local ledger or the ledger service client
+		payloadStorage                *payloadstore.PayloadStore // nil if payloads are held in memory
+		ownerIndex                    *ownerindex.OwnerIndex     // nil if the owner index is not enabled
 		events                        *storage.Events
 		serviceEvents                 *storage.ServiceEvents
 		txResults                     *storage.TransactionResults
+		txProfiles                    *storage.TransactionProfiles
 		results                       *storage.ExecutionResults
 		myReceipts                    *storage.MyExecutionReceipts
 		providerEngine                *exeprovider.Engine
@@ -189,6 +273,7 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 		computationManager            *computation.Manager
 		collectionRequester           *requester.Engine
 		ingestionEng                  *ingestion.Engine
+		registerHistory               *history.Indexer
 		finalizationDistributor       *pubsub.FinalizationDistributor
 		finalizedHeader               *synchronization.FinalizedHeaderCache
 		checkAuthorizedAtBlock        func(blockID flow.Identifier) (bool, error)
@@ -208,6 +293,11 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 		AdminCommand("set-uploader-enabled", func(config *NodeConfig) commands.AdminCommand {
 			return uploaderCommands.NewToggleUploaderCommand()
 		}).
+		AdminCommand("read-account-storage", func(config *NodeConfig) commands.AdminCommand {
+			return executionCommands.NewReadAccountStorageCommand(func() *ledger.Ledger {
+				return ledgerStorage
+			})
+		}).
 		Module("mutable follower state", func(node *NodeConfig) error {
 			// For now, we only support state implementations from package badger.
 			// If we ever support different implementations, the following can be replaced by a type-aware factory
@@ -332,10 +422,27 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 			return nil
 		}).
 		Component("Write-Ahead Log", func(node *NodeConfig) (module.ReadyDoneAware, error) {
+			// the ledger service owns the WAL
+			if e.exeConf.ledgerServiceAddr != "" {
+				return &module.NoopReadyDoneAware{}, nil
+			}
+
+			// the WAL must be checked before it is opened, as opening it creates a new segment
+			if e.exeConf.walRepair != "" {
+				err := repairWAL(node.Logger, e.exeConf.triedir, e.exeConf.walRepair)
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			var err error
 			diskWAL, err = wal.NewDiskWAL(node.Logger.With().Str("subcomponent", "wal").Logger(),
 				node.MetricsRegisterer, collector, e.exeConf.triedir, int(e.exeConf.mTrieCacheSize), pathfinder.PathByteSize, wal.SegmentSize)
-			return diskWAL, err
+			if err != nil {
+				return nil, err
+			}
+			diskWAL.SetStrictReplay(e.exeConf.walStrictReplay)
+			return diskWAL, nil
 		}).
 		Component("execution state ledger", func(node *NodeConfig) (module.ReadyDoneAware, error) {
 
@@ -351,9 +458,12 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 			if !bootstrapped {
 				// when bootstrapping, the bootstrap folder must have a checkpoint file
 				// we need to cover this file to the trie folder to restore the trie to restore the execution state.
-				err = copyBootstrapState(node.BootstrapDir, e.exeConf.triedir)
-				if err != nil {
-					return nil, fmt.Errorf("could not load bootstrap state from checkpoint file: %w", err)
+				// A ledger service is bootstrapped with the root checkpoint in its own trie folder.
+				if e.exeConf.ledgerServiceAddr == "" {
+					err = copyBootstrapState(node.BootstrapDir, e.exeConf.triedir)
+					if err != nil {
+						return nil, fmt.Errorf("could not load bootstrap state from checkpoint file: %w", err)
+					}
 				}
 
 				// TODO: check that the checkpoint file contains the root block's statecommit hash
@@ -372,11 +482,81 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 				}
 			}
 
-			ledgerStorage, err = ledger.NewLedger(diskWAL, int(e.exeConf.mTrieCacheSize), collector, node.Logger.With().Str("subcomponent",
-				"ledger").Logger(), ledger.DefaultPathFinderVersion)
+			if e.exeConf.ledgerServiceAddr != "" {
+				var tlsConfig *tls.Config
+				if e.exeConf.ledgerServiceTLSCAFile != "" || e.exeConf.ledgerServiceTLSCertFile != "" {
+					tlsConfig, err = remote.ClientTLSConfig(e.exeConf.ledgerServiceTLSCAFile, e.exeConf.ledgerServiceTLSCertFile, e.exeConf.ledgerServiceTLSKeyFile)
+					if err != nil {
+						return nil, fmt.Errorf("could not create ledger service TLS config: %w", err)
+					}
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), ledgerServiceConnectTimeout)
+				defer cancel()
+				client, err := remote.NewClient(ctx, e.exeConf.ledgerServiceAddr, tlsConfig)
+				if err != nil {
+					return nil, fmt.Errorf("could not connect to ledger service: %w", err)
+				}
+				executionLedger = client
+				return client, nil
+			}
+
+			// payloads are held in memory, unless a directory to store them on disk is given
+			if e.exeConf.ledgerPayloadDir != "" {
+				err = os.MkdirAll(e.exeConf.ledgerPayloadDir, 0700)
+				if err != nil {
+					return nil, fmt.Errorf("could not create ledger payload dir: %w", err)
+				}
+
+				opts := badgerdb.DefaultOptions(e.exeConf.ledgerPayloadDir).WithLogger(sutil.NewLogger(node.Logger))
+				payloadDB, err := badgerdb.Open(opts)
+				if err != nil {
+					return nil, fmt.Errorf("could not open ledger payload db: %w", err)
+				}
+				e.FlowNodeBuilder.ShutdownFunc(payloadDB.Close)
+
+				payloadStorage, err = payloadstore.New(payloadDB, int(e.exeConf.ledgerPayloadCacheSize))
+				if err != nil {
+					return nil, fmt.Errorf("could not create ledger payload storage: %w", err)
+				}
+			}
+
+			var forestOptions []mtrie.ForestOption
+			if e.exeConf.ledgerOwnerIndexDir != "" {
+				err = os.MkdirAll(e.exeConf.ledgerOwnerIndexDir, 0700)
+				if err != nil {
+					return nil, fmt.Errorf("could not create ledger owner index dir: %w", err)
+				}
+
+				opts := badgerdb.DefaultOptions(e.exeConf.ledgerOwnerIndexDir).WithLogger(sutil.NewLogger(node.Logger))
+				ownerIndexDB, err := badgerdb.Open(opts)
+				if err != nil {
+					return nil, fmt.Errorf("could not open ledger owner index db: %w", err)
+				}
+				e.FlowNodeBuilder.ShutdownFunc(ownerIndexDB.Close)
+
+				ownerIndex = ownerindex.New(ownerIndexDB)
+				forestOptions = append(forestOptions, mtrie.WithOwnerIndex(ownerIndex))
+			}
+
+			logger := node.Logger.With().Str("subcomponent", "ledger").Logger()
+			if payloadStorage != nil {
+				// checkpoints are loaded and created without holding the payloads in memory as well
+				diskWAL.SetPayloadStorage(payloadStorage)
+				ledgerStorage, err = ledger.NewLedgerWithPayloadStorage(diskWAL, int(e.exeConf.mTrieCacheSize), collector, logger,
+					ledger.DefaultPathFinderVersion, payloadStorage, forestOptions...)
+			} else {
+				ledgerStorage, err = ledger.NewLedger(diskWAL, int(e.exeConf.mTrieCacheSize), collector, logger,
+					ledger.DefaultPathFinderVersion, forestOptions...)
+			}
+			executionLedger = ledgerStorage
 			return ledgerStorage, err
 		}).
 		Component("execution state ledger WAL compactor", func(node *NodeConfig) (module.ReadyDoneAware, error) {
+			// the ledger service runs its own compactor
+			if e.exeConf.ledgerServiceAddr != "" {
+				return &module.NoopReadyDoneAware{}, nil
+			}
 
 			checkpointer, err := ledgerStorage.Checkpointer()
 			if err != nil {
@@ -386,8 +566,19 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 				10*time.Second,
 				e.exeConf.checkpointDistance,
 				e.exeConf.checkpointsToKeep,
+				e.exeConf.maxDeltaCheckpoints,
+				e.exeConf.checkpointV6,
 				node.Logger.With().Str("subcomponent", "checkpointer").Logger())
 
+			// payloads of tries evicted from the forest are removed once a checkpoint was created
+			if payloadStorage != nil {
+				compactor.Subscribe(ledger.NewPayloadPruner(ledgerStorage, payloadStorage, node.Logger))
+			}
+			// and so are the registers they hold from the owner index
+			if ownerIndex != nil {
+				compactor.Subscribe(ledger.NewOwnerIndexPruner(ledgerStorage, ownerIndex, node.Logger))
+			}
+
 			return compactor, nil
 		}).
 		Component("execution data service", func(node *NodeConfig) (module.ReadyDoneAware, error) {
@@ -478,7 +669,7 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 			vm := fvm.NewVirtualMachine(rt)
 			vmCtx := fvm.NewContext(node.Logger, node.FvmOptions...)
 
-			ledgerViewCommitter := committer.NewLedgerViewCommitter(ledgerStorage, node.Tracer)
+			ledgerViewCommitter := committer.NewLedgerViewCommitter(executionLedger, node.Tracer)
 			manager, err := computation.New(
 				node.Logger,
 				collector,
@@ -489,11 +680,15 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 				vmCtx,
 				e.exeConf.cadenceExecutionCache,
 				ledgerViewCommitter,
+				e.exeConf.parallelExecutionWorkers,
 				e.exeConf.scriptLogThreshold,
 				e.exeConf.scriptExecutionTimeLimit,
+				e.exeConf.scriptExecutionWorkers,
+				e.exeConf.scriptExecutionQueueSize,
 				blockDataUploaders,
 				executionDataService,
 				executionDataCIDCache,
+				storage.NewProgramUsages(node.DB),
 			)
 			if err != nil {
 				return nil, err
@@ -507,9 +702,10 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 			events = storage.NewEvents(node.Metrics.Cache, node.DB)
 			serviceEvents = storage.NewServiceEvents(node.Metrics.Cache, node.DB)
 			txResults = storage.NewTransactionResults(node.Metrics.Cache, node.DB, e.exeConf.transactionResultsCacheSize)
+			txProfiles = storage.NewTransactionProfiles(node.Metrics.Cache, node.DB, e.exeConf.transactionResultsCacheSize)
 
 			executionState = state.NewExecutionState(
-				ledgerStorage,
+				executionLedger,
 				stateCommitments,
 				node.Storage.Blocks,
 				node.Storage.Headers,
@@ -520,6 +716,7 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 				events,
 				serviceEvents,
 				txResults,
+				txProfiles,
 				node.DB,
 				node.Tracer,
 			)
@@ -589,6 +786,35 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 
 			return providerEngine, nil
 		}).
+		Component("programs warm up", func(node *NodeConfig) (module.ReadyDoneAware, error) {
+			if e.exeConf.programsWarmUpLimit == 0 {
+				return &module.NoopReadyDoneAware{}, nil
+			}
+
+			// Load the programs of the most used contracts at the latest executed block in the background, so
+			// that executing the next blocks doesn't need to parse and check them again.
+			ctx := context.Background()
+			_, blockID, err := executionState.GetHighestExecutedBlockID(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("cannot get the latest executed block id: %w", err)
+			}
+			stateCommit, err := executionState.StateCommitmentByBlockID(ctx, blockID)
+			if err != nil {
+				return nil, fmt.Errorf("cannot get the state comitment at latest executed block id %s: %w", blockID.String(), err)
+			}
+			header, err := node.Storage.Headers.ByBlockID(blockID)
+			if err != nil {
+				return nil, fmt.Errorf("cannot get the header of the latest executed block %s: %w", blockID.String(), err)
+			}
+
+			return computation.NewProgramsWarmUp(
+				node.Logger,
+				computationManager,
+				header,
+				executionState.NewView(stateCommit),
+				e.exeConf.programsWarmUpLimit,
+			), nil
+		}).
 		Component("checker engine", func(node *NodeConfig) (module.ReadyDoneAware, error) {
 			checkerEng = checker.New(
 				node.Logger,
@@ -598,6 +824,40 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 			)
 			return checkerEng, nil
 		}).
+		Component("register history", func(node *NodeConfig) (module.ReadyDoneAware, error) {
+			if !e.exeConf.registerHistory {
+				return &module.NoopReadyDoneAware{}, nil
+			}
+
+			registers, err := storage.NewRegisters(node.DB)
+			if err != nil {
+				return nil, fmt.Errorf("could not create register history: %w", err)
+			}
+
+			// the history is only complete if the registers of all blocks executed since the root were recorded
+			_, err = registers.FirstHeight()
+			if errors.Is(err, storerr.ErrNotFound) {
+				executedHeight, _, err := executionState.GetHighestExecutedBlockID(context.Background())
+				if err != nil {
+					return nil, fmt.Errorf("could not get highest executed block: %w", err)
+				}
+				if executedHeight > node.RootBlock.Header.Height {
+					return nil, fmt.Errorf("register history can only be enabled when the node is bootstrapped, "+
+						"blocks up to height %d have already been executed", executedHeight)
+				}
+			} else if err != nil {
+				return nil, fmt.Errorf("could not get first height of register history: %w", err)
+			}
+
+			checkpointFile := filepath.Join(node.BootstrapDir, bootstrapFilenames.PathRootCheckpoint)
+			err = history.Bootstrap(node.Logger, registers, checkpointFile, node.RootBlock.Header.Height, node.RootSeal.FinalState)
+			if err != nil {
+				return nil, fmt.Errorf("could not bootstrap register history: %w", err)
+			}
+
+			registerHistory = history.NewIndexer(node.Logger, node.DB, node.State, node.Storage.Headers, registers)
+			return registerHistory, nil
+		}).
 		Component("ingestion engine", func(node *NodeConfig) (module.ReadyDoneAware, error) {
 			var err error
 			collectionRequester, err = requester.New(node.Logger, node.Metrics.Engine, node.Network, node.Me, node.State,
@@ -648,6 +908,13 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 				checkAuthorizedAtBlock,
 				e.exeConf.pauseExecution,
 			)
+			if err != nil {
+				return nil, fmt.Errorf("could not create ingestion engine: %w", err)
+			}
+
+			if registerHistory != nil {
+				ingestionEng = ingestionEng.WithRegisterHistory(registerHistory)
+			}
 
 			// TODO: we should solve these mutual dependencies better
 			// => https://github.com/dapperlabs/flow-go/issues/4360
@@ -685,6 +952,9 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 
 			finalizationDistributor = pubsub.NewFinalizationDistributor()
 			finalizationDistributor.AddConsumer(checkerEng)
+			if registerHistory != nil {
+				finalizationDistributor.AddOnBlockFinalizedConsumer(registerHistory.OnFinalizedBlock)
+			}
 
 			// creates a consensus follower with ingestEngine as the notifier
 			// so that it gets notified upon each new finalized block
@@ -765,7 +1035,14 @@ func (e *ExecutionNodeBuilder) LoadComponentsAndModules() {
 			return syncEngine, nil
 		}).
 		Component("grpc server", func(node *NodeConfig) (module.ReadyDoneAware, error) {
-			rpcEng := rpc.New(node.Logger, e.exeConf.rpcConf, ingestionEng, node.Storage.Blocks, node.Storage.Headers, node.State, events, results, txResults, node.RootChainID)
+			if e.exeConf.rpcTLSEnabled {
+				creds, err := grpcutils.ServerTransportCredentials(node.NetworkKey, e.exeConf.rpcTLSCertFile, e.exeConf.rpcTLSKeyFile)
+				if err != nil {
+					return nil, fmt.Errorf("could not create gRPC server TLS credentials: %w", err)
+				}
+				e.exeConf.rpcConf.TransportCredentials = creds
+			}
+			rpcEng := rpc.New(node.Logger, e.exeConf.rpcConf, ingestionEng, node.Storage.Blocks, node.Storage.Headers, node.State, events, results, txResults, txProfiles, node.RootChainID)
 			return rpcEng, nil
 		})
 }
@@ -804,6 +1081,34 @@ func getContractEpochCounter(vm *fvm.VirtualMachine, vmCtx fvm.Context, view *de
 	return epochCounter, nil
 }
 
+// repairWAL checks the integrity of the ledger WAL in `dir`, and handles a corrupted WAL tail
+// with the given repair mode. Checkpoints are not checked, as loading them takes too long on startup.
+func repairWAL(logger zerolog.Logger, dir string, repair string) error {
+	mode, err := wal.ParseRepairMode(repair)
+	if err != nil {
+		return err
+	}
+
+	log := logger.With().Str("component", "wal_repair").Logger()
+	report, err := wal.CheckIntegrity(dir, false, log)
+	if err != nil {
+		return fmt.Errorf("could not check WAL integrity: %w", err)
+	}
+
+	log.Info().
+		Int("first_segment", report.FirstSegment).
+		Int("last_segment", report.LastSegment).
+		Int("records", report.Records).
+		Bool("corrupted", report.HasCorruptedSegments()).
+		Msg("checked WAL integrity")
+
+	err = wal.RepairTail(dir, report, mode, filepath.Join(dir, "quarantine"), log)
+	if err != nil {
+		return fmt.Errorf("could not repair WAL: %w", err)
+	}
+	return nil
+}
+
 // copy the checkpoint files from the bootstrap folder to the execution state folder
 // Checkpoint file is required to restore the trie, and has to be placed in the execution
 // state folder.
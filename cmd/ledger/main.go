@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/pflag"
+
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/ledger/remote"
+	"github.com/onflow/flow-go/module/metrics"
+)
+
+var (
+	flagTrieDir             string
+	flagAddress             string
+	flagMTrieCacheSize      uint32
+	flagCheckpointDistance  uint
+	flagCheckpointsToKeep   uint
+	flagMaxDeltaCheckpoints uint
+	flagCheckpointV6        bool
+	flagTLSCertFile         string
+	flagTLSKeyFile          string
+	flagTLSClientCAFile     string
+	flagLogLevel            string
+)
+
+// The ledger service runs the execution state ledger in its own process, and serves it over gRPC.
+// It owns the WAL and checkpoints in the trie directory, so that execution nodes can be restarted
+// without reloading the execution state.
+func main() {
+	pflag.StringVar(&flagTrieDir, "triedir", "", "directory to store the execution state (WAL and checkpoints)")
+	pflag.StringVar(&flagAddress, "ledger-service-addr", "localhost:9300", "address the gRPC ledger service listens on")
+	pflag.Uint32Var(&flagMTrieCacheSize, "mtrie-cache-size", 500, "cache size for MTrie")
+	pflag.UintVar(&flagCheckpointDistance, "checkpoint-distance", 20, "number of WAL segments between checkpoints")
+	pflag.UintVar(&flagCheckpointsToKeep, "checkpoints-to-keep", 5, "number of recent checkpoints to keep (0 to keep all)")
+	pflag.UintVar(&flagMaxDeltaCheckpoints, "max-delta-checkpoints", 0,
+		fmt.Sprintf("maximum number of delta checkpoints created between full checkpoints, at most %d (0 to only create full checkpoints)", wal.MaxDeltaCheckpoints))
+	pflag.BoolVar(&flagCheckpointV6, "checkpoint-v6", false,
+		"create full checkpoints in version 6 instead of version 5. To roll back, disable it and wait for the next full checkpoint before downgrading")
+	pflag.StringVar(&flagTLSCertFile, "tls-cert-file", "", "path to the PEM certificate of the ledger service, the service is insecure if empty")
+	pflag.StringVar(&flagTLSKeyFile, "tls-key-file", "", "path to the PEM private key of the ledger service certificate")
+	pflag.StringVar(&flagTLSClientCAFile, "tls-client-ca-file", "",
+		"path to the PEM certificates of the CAs signing client certificates, clients are not authenticated if empty")
+	pflag.StringVar(&flagLogLevel, "loglevel", "info", "level for logging output")
+	pflag.Parse()
+
+	log := zerolog.New(os.Stderr).With().Timestamp().Str("process", "ledger").Logger()
+	level, err := zerolog.ParseLevel(flagLogLevel)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid log level")
+	}
+	log = log.Level(level)
+
+	if flagTrieDir == "" {
+		log.Fatal().Msg("--triedir is required")
+	}
+
+	collector := metrics.NewNoopCollector()
+
+	diskWAL, err := wal.NewDiskWAL(log.With().Str("subcomponent", "wal").Logger(),
+		nil, collector, flagTrieDir, int(flagMTrieCacheSize), pathfinder.PathByteSize, wal.SegmentSize)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not create WAL")
+	}
+
+	log.Info().Str("triedir", flagTrieDir).Msg("loading execution state")
+	led, err := complete.NewLedger(diskWAL, int(flagMTrieCacheSize), collector,
+		log.With().Str("subcomponent", "ledger").Logger(), complete.DefaultPathFinderVersion)
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not create ledger")
+	}
+
+	checkpointer, err := led.Checkpointer()
+	if err != nil {
+		log.Fatal().Err(err).Msg("could not create checkpointer")
+	}
+	compactor := wal.NewCompactor(checkpointer,
+		10*time.Second,
+		flagCheckpointDistance,
+		flagCheckpointsToKeep,
+		flagMaxDeltaCheckpoints,
+		flagCheckpointV6,
+		log.With().Str("subcomponent", "checkpointer").Logger())
+	<-compactor.Ready()
+
+	listener, err := net.Listen("tcp", flagAddress)
+	if err != nil {
+		log.Fatal().Err(err).Str("address", flagAddress).Msg("could not listen")
+	}
+
+	var tlsConfig *tls.Config
+	if flagTLSCertFile != "" {
+		tlsConfig, err = remote.ServerTLSConfig(flagTLSCertFile, flagTLSKeyFile, flagTLSClientCAFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("could not create TLS config")
+		}
+	} else if flagTLSClientCAFile != "" {
+		log.Fatal().Msg("--tls-client-ca-file requires --tls-cert-file")
+	}
+	server := remote.NewServer(led, tlsConfig)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		log.Info().Msg("stopping ledger service")
+		server.GracefulStop()
+	}()
+
+	log.Info().Str("address", listener.Addr().String()).Msg("serving ledger")
+	err = server.Serve(listener)
+	if err != nil {
+		log.Error().Err(err).Msg("ledger service failed")
+	}
+
+	<-compactor.Done()
+	<-led.Done()
+	<-diskWAL.Done()
+	log.Info().Msg("ledger service stopped")
+}
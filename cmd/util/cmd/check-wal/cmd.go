@@ -0,0 +1,95 @@
+package check_wal
+
+import (
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/ledger/complete/wal"
+)
+
+var (
+	flagExecutionStateDir string
+	flagCheckCheckpoints  bool
+	flagRepair            string
+	flagQuarantineDir     string
+)
+
+var Cmd = &cobra.Command{
+	Use:   "check-wal",
+	Short: "checks the integrity of the WAL segments and checkpoints of an execution state, and optionally repairs a corrupted WAL tail",
+	Run:   run,
+}
+
+func init() {
+	Cmd.Flags().StringVar(&flagExecutionStateDir, "execution-state-dir", "",
+		"Execution Node state dir (where WAL logs are written")
+	_ = Cmd.MarkFlagRequired("execution-state-dir")
+
+	Cmd.Flags().BoolVar(&flagCheckCheckpoints, "check-checkpoints", true,
+		"load all checkpoints to check them (requires enough memory to hold the largest checkpoint)")
+
+	Cmd.Flags().StringVar(&flagRepair, "repair", "dry-run",
+		"what to do with a corrupted WAL tail: dry-run (only report it), truncate (remove it) or quarantine (move it to the quarantine dir)")
+
+	Cmd.Flags().StringVar(&flagQuarantineDir, "quarantine-dir", "",
+		"directory to move the corrupted WAL tail to (default: quarantine directory in the execution state dir)")
+}
+
+func run(*cobra.Command, []string) {
+	mode, err := wal.ParseRepairMode(flagRepair)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid repair mode")
+	}
+
+	quarantineDir := flagQuarantineDir
+	if quarantineDir == "" {
+		quarantineDir = filepath.Join(flagExecutionStateDir, "quarantine")
+	}
+
+	report, err := wal.CheckIntegrity(flagExecutionStateDir, flagCheckCheckpoints, log.Logger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot check WAL integrity")
+	}
+
+	logReport(report)
+
+	err = wal.RepairTail(flagExecutionStateDir, report, mode, quarantineDir, log.Logger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot repair WAL")
+	}
+}
+
+func logReport(report *wal.IntegrityReport) {
+	segments := log.Info()
+	if report.FirstSegment >= 0 {
+		segments = segments.Int("first_segment", report.FirstSegment).Int("last_segment", report.LastSegment)
+	}
+	if report.LastConsistent != nil {
+		segments = segments.
+			Int("last_consistent_segment", report.LastConsistent.Segment).
+			Int64("last_consistent_offset", report.LastConsistent.Offset)
+	}
+	segments.Int("consistent_records", report.Records).Msg("checked segments")
+
+	if report.HasCorruptedSegments() {
+		truncateAt := report.TruncationPoint()
+		log.Warn().Err(report.Corruption).
+			Int("corrupted_segment", report.CorruptedSegment).
+			Int("tail_segment", truncateAt.Segment).
+			Int64("tail_offset", truncateAt.Offset).
+			Int("records_after_corruption", report.RecordsAfterCorruption).
+			Msgf("corrupted tail: segment %d from offset %d, and segments up to %d", truncateAt.Segment, truncateAt.Offset, report.LastSegment)
+	} else {
+		log.Info().Msg("no corruption found in segments")
+	}
+
+	for _, checkpoint := range report.Checkpoints {
+		if checkpoint.Err != nil {
+			log.Warn().Err(checkpoint.Err).Int("checkpoint", checkpoint.Number).Msg("checkpoint is corrupted")
+		} else {
+			log.Info().Int("checkpoint", checkpoint.Number).Msg("checkpoint is consistent")
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package diff
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/module/metrics"
+)
+
+var (
+	flagExecutionStateDir string
+	flagStateBefore       string
+	flagStateAfter        string
+	flagOutputFile        string
+)
+
+var Cmd = &cobra.Command{
+	Use:   "diff-execution-state",
+	Short: "prints the registers which differ between two execution states as JSON, grouped by account owner",
+	Run:   run,
+}
+
+func init() {
+	Cmd.Flags().StringVar(&flagExecutionStateDir, "execution-state-dir", "",
+		"Execution Node state dir (where WAL logs are written")
+	_ = Cmd.MarkFlagRequired("execution-state-dir")
+
+	Cmd.Flags().StringVar(&flagStateBefore, "state-before", "",
+		"State commitment to diff from (hex-encoded, 64 characters)")
+	_ = Cmd.MarkFlagRequired("state-before")
+
+	Cmd.Flags().StringVar(&flagStateAfter, "state-after", "",
+		"State commitment to diff to (hex-encoded, 64 characters)")
+	_ = Cmd.MarkFlagRequired("state-after")
+
+	Cmd.Flags().StringVar(&flagOutputFile, "output-file", "",
+		"File to write the diff to (default: stdout)")
+}
+
+func run(*cobra.Command, []string) {
+	before, err := parseState(flagStateBefore)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid state before")
+	}
+	after, err := parseState(flagStateAfter)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid state after")
+	}
+
+	log.Info().Msgf("diffing state %s and state %s", before, after)
+
+	diffs, err := DiffStates(flagExecutionStateDir, before, after)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot diff states")
+	}
+
+	var output io.Writer = os.Stdout
+	if flagOutputFile != "" {
+		file, err := os.Create(flagOutputFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("cannot create output file")
+		}
+		defer file.Close()
+
+		fileWriter := bufio.NewWriter(file)
+		defer fileWriter.Flush()
+		output = fileWriter
+	}
+
+	err = WriteDiffJSON(output, diffs)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot write diff")
+	}
+
+	log.Info().Msgf("found %d differing registers", len(diffs))
+}
+
+func parseState(s string) (ledger.State, error) {
+	st, err := hex.DecodeString(s)
+	if err != nil {
+		return ledger.State{}, fmt.Errorf("failed to decode hex code of state: %w", err)
+	}
+	return ledger.ToState(st)
+}
+
+// DiffStates restores the forest from the checkpoint and WAL segments in the execution state dir,
+// and returns the registers whose payloads differ between the two states.
+// Both states must be among the most recent complete.DefaultCacheSize states.
+func DiffStates(ledgerPath string, before, after ledger.State) ([]trie.PayloadDiff, error) {
+	diskWal, err := wal.NewDiskWAL(
+		zerolog.Nop(),
+		nil,
+		metrics.NewNoopCollector(),
+		ledgerPath,
+		complete.DefaultCacheSize,
+		pathfinder.PathByteSize,
+		wal.SegmentSize,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create WAL: %w", err)
+	}
+	defer func() {
+		<-diskWal.Done()
+	}()
+
+	forest, err := mtrie.NewForest(complete.DefaultCacheSize, metrics.NewNoopCollector(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create forest: %w", err)
+	}
+
+	err = diskWal.ReplayOnForest(forest)
+	if err != nil {
+		return nil, fmt.Errorf("cannot replay execution state: %w", err)
+	}
+
+	return forest.Diff(ledger.RootHash(before), ledger.RootHash(after))
+}
+
+// RegisterDiff is the JSON representation of a register which differs between two states.
+// Before and After are nil if the register doesn't exist in the respective state.
+type RegisterDiff struct {
+	Path   string        `json:"path"`
+	Key    ledger.Key    `json:"key"`
+	Before *ledger.Value `json:"before"`
+	After  *ledger.Value `json:"after"`
+}
+
+// WriteDiffJSON writes the diffs as JSON object, which maps the hex-encoded account owners to the
+// registers of the account which differ. Registers without owner are grouped under the empty owner.
+func WriteDiffJSON(w io.Writer, diffs []trie.PayloadDiff) error {
+	byOwner := make(map[string][]RegisterDiff)
+	for _, d := range diffs {
+		registerDiff := RegisterDiff{
+			Path: hex.EncodeToString(d.Path[:]),
+		}
+		if d.Before != nil {
+			registerDiff.Key = d.Before.Key
+			registerDiff.Before = &d.Before.Value
+		}
+		if d.After != nil {
+			registerDiff.Key = d.After.Key
+			registerDiff.After = &d.After.Value
+		}
+
+		owner := ownerOf(registerDiff.Key)
+		byOwner[owner] = append(byOwner[owner], registerDiff)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(byOwner)
+}
+
+func ownerOf(key ledger.Key) string {
+	for _, kp := range key.KeyParts {
+		if kp.Type == state.KeyPartOwner {
+			return hex.EncodeToString(kp.Value)
+		}
+	}
+	return ""
+}
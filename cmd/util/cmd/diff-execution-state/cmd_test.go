@@ -0,0 +1,78 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestDiffStates(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		diskWal, err := wal.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, 100, pathfinder.PathByteSize, wal.SegmentSize)
+		require.NoError(t, err)
+		led, err := complete.NewLedger(diskWal, 100, &metrics.NoopCollector{}, zerolog.Nop(), complete.DefaultPathFinderVersion)
+		require.NoError(t, err)
+
+		owner1 := unittest.AddressFixture()
+		owner2 := unittest.RandomAddressFixture()
+		key1 := state.RegisterIDToKey(flow.NewRegisterID(string(owner1.Bytes()), "", "a"))
+		key2 := state.RegisterIDToKey(flow.NewRegisterID(string(owner1.Bytes()), "", "b"))
+		key3 := state.RegisterIDToKey(flow.NewRegisterID(string(owner2.Bytes()), "", "c"))
+
+		update, err := ledger.NewUpdate(led.InitialState(), []ledger.Key{key1, key2}, []ledger.Value{{1}, {2}})
+		require.NoError(t, err)
+		before, _, err := led.Set(update)
+		require.NoError(t, err)
+
+		update, err = ledger.NewUpdate(before, []ledger.Key{key2, key3}, []ledger.Value{{3}, {4}})
+		require.NoError(t, err)
+		after, _, err := led.Set(update)
+		require.NoError(t, err)
+
+		<-led.Done()
+
+		diffs, err := DiffStates(dir, before, after)
+		require.NoError(t, err)
+		require.Len(t, diffs, 2)
+
+		var buf bytes.Buffer
+		err = WriteDiffJSON(&buf, diffs)
+		require.NoError(t, err)
+
+		var byOwner map[string][]struct {
+			Before *string
+			After  *string
+		}
+		err = json.Unmarshal(buf.Bytes(), &byOwner)
+		require.NoError(t, err)
+		require.Len(t, byOwner, 2)
+
+		// changed register
+		registers := byOwner[hex.EncodeToString(owner1.Bytes())]
+		require.Len(t, registers, 1)
+		require.Equal(t, "02", *registers[0].Before)
+		require.Equal(t, "03", *registers[0].After)
+
+		// added register
+		registers = byOwner[hex.EncodeToString(owner2.Bytes())]
+		require.Len(t, registers, 1)
+		require.Nil(t, registers[0].Before)
+		require.Equal(t, "04", *registers[0].After)
+
+		_, err = DiffStates(dir, before, ledger.State(unittest.StateCommitmentFixture()))
+		require.Error(t, err)
+	})
+}
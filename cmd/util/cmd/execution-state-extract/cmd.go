@@ -28,6 +28,7 @@ var (
 	flagChain             string
 	flagNoMigration       bool
 	flagNoReport          bool
+	flagOwnerIndexDir     string
 )
 
 func getChain(chainName string) (chain flow.Chain, err error) {
@@ -72,6 +73,10 @@ func init() {
 
 	Cmd.Flags().BoolVar(&flagNoReport, "no-report", false,
 		"don't report the state")
+
+	Cmd.Flags().StringVar(&flagOwnerIndexDir, "owner-index-dir", "",
+		"directory to store an index of the registers of each account, read by the reports on each account "+
+			"(empty to not use an index)")
 }
 
 func run(*cobra.Command, []string) {
@@ -176,6 +181,7 @@ func run(*cobra.Command, []string) {
 		chain,
 		!flagNoMigration,
 		!flagNoReport,
+		flagOwnerIndexDir,
 	)
 
 	if err != nil {
@@ -3,6 +3,7 @@ package extract
 import (
 	"fmt"
 
+	"github.com/dgraph-io/badger/v2"
 	"github.com/rs/zerolog"
 
 	mgr "github.com/onflow/flow-go/cmd/util/ledger/migrations"
@@ -10,6 +11,8 @@ import (
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/pathfinder"
 	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/ownerindex"
 	"github.com/onflow/flow-go/ledger/complete/wal"
 	"github.com/onflow/flow-go/model/bootstrap"
 	"github.com/onflow/flow-go/model/flow"
@@ -29,6 +32,7 @@ func extractExecutionState(
 	chain flow.Chain,
 	migrate bool,
 	report bool,
+	ownerIndexDir string,
 ) error {
 
 	diskWal, err := wal.NewDiskWAL(
@@ -47,12 +51,26 @@ func extractExecutionState(
 		<-diskWal.Done()
 	}()
 
+	// the reporters reporting on each account read the registers of the account through an owner index,
+	// instead of sharding all registers by account
+	var forestOptions []mtrie.ForestOption
+	if report && ownerIndexDir != "" {
+		db, err := badger.Open(badger.DefaultOptions(ownerIndexDir).WithLogger(nil))
+		if err != nil {
+			return fmt.Errorf("cannot open owner index db: %w", err)
+		}
+		defer db.Close()
+
+		forestOptions = append(forestOptions, mtrie.WithOwnerIndex(ownerindex.New(db)))
+	}
+
 	led, err := complete.NewLedger(
 		diskWal,
 		complete.DefaultCacheSize,
 		&metrics.NoopCollector{},
 		log,
-		complete.DefaultPathFinderVersion)
+		complete.DefaultPathFinderVersion,
+		forestOptions...)
 	if err != nil {
 		return fmt.Errorf("cannot create ledger from write-a-head logs and checkpoints: %w", err)
 	}
@@ -24,6 +24,9 @@ type keyPair struct {
 }
 
 func TestExtractExecutionState(t *testing.T) {
+	// the checkpoint status file is written to the working directory
+	unittest.ChdirTempDir(t)
+
 	metr := &metrics.NoopCollector{}
 
 	t.Run("missing block->state commitment mapping", func(t *testing.T) {
@@ -65,6 +68,7 @@ func TestExtractExecutionState(t *testing.T) {
 				flow.Emulator.Chain(),
 				false,
 				false,
+				"",
 			)
 			require.Error(t, err)
 		})
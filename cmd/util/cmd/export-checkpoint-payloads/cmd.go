@@ -0,0 +1,55 @@
+package export_checkpoint_payloads
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagCheckpoint  string
+	flagOutputDir   string
+	flagFormat      string
+	flagRowsPerFile uint64
+)
+
+var Cmd = &cobra.Command{
+	Use:   "export-checkpoint-payloads",
+	Short: "exports the payloads of a single trie checkpoint to partitioned CSV or Parquet files",
+	Run:   run,
+}
+
+func init() {
+	Cmd.Flags().StringVar(&flagCheckpoint, "checkpoint", "",
+		"checkpoint file to export, such as a root checkpoint (must contain a single trie)")
+	_ = Cmd.MarkFlagRequired("checkpoint")
+
+	Cmd.Flags().StringVar(&flagOutputDir, "output-dir", "",
+		"directory to write the exported files to, an interrupted export is resumed when the same directory is used")
+	_ = Cmd.MarkFlagRequired("output-dir")
+
+	Cmd.Flags().StringVar(&flagFormat, "format", string(FormatParquet),
+		"format of the exported files: csv or parquet")
+
+	Cmd.Flags().Uint64Var(&flagRowsPerFile, "rows-per-file", 1_000_000,
+		"maximum number of payloads per exported file")
+}
+
+func run(*cobra.Command, []string) {
+	format, err := ParseFormat(flagFormat)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid format")
+	}
+
+	log.Info().
+		Str("checkpoint", flagCheckpoint).
+		Str("output_dir", flagOutputDir).
+		Str("format", string(format)).
+		Msg("exporting checkpoint payloads")
+
+	err = Export(flagCheckpoint, flagOutputDir, format, flagRowsPerFile, log.Logger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot export checkpoint payloads")
+	}
+
+	log.Info().Msg("checkpoint payloads exported")
+}
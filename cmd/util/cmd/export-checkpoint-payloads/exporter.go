@@ -0,0 +1,370 @@
+package export_checkpoint_payloads
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/onflow/atree"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/rs/zerolog"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+)
+
+// Format is the file format of the exported partitions.
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatParquet Format = "parquet"
+)
+
+// ParseFormat returns the format with the given name.
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case FormatCSV, FormatParquet:
+		return Format(name), nil
+	default:
+		return "", fmt.Errorf("unknown format %q, expected %q or %q", name, FormatCSV, FormatParquet)
+	}
+}
+
+const manifestFilename = "export.json"
+
+// manifest describes an export, so that an interrupted export is only resumed with the same settings.
+type manifest struct {
+	Checkpoint  string `json:"checkpoint"`
+	Format      Format `json:"format"`
+	RowsPerFile uint64 `json:"rows_per_file"`
+}
+
+// Row is the exported row of a payload.
+type Row struct {
+	// Owner is the hex encoded owner of the register, usually an account address.
+	Owner string `parquet:"name=owner, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	// Controller is the hex encoded controller of the register.
+	Controller string `parquet:"name=controller, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	// Key is the key of the register. The keys of atree slabs, which are a '$' followed by the 8 bytes of the
+	// storage index, are exported as a '$' followed by the decimal storage index.
+	Key string `parquet:"name=key, type=BYTE_ARRAY, convertedtype=UTF8"`
+	// ValueSize is the size of the register value in bytes.
+	ValueSize int64 `parquet:"name=value_size, type=INT64"`
+	// CadenceType is the Cadence type of the array or dictionary stored in the register, if the register holds
+	// the root slab of an array or dictionary. It is empty otherwise.
+	CadenceType string `parquet:"name=cadence_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+var csvHeader = []string{"owner", "controller", "key", "value_size", "cadence_type"}
+
+// Export streams the payloads of the single trie checkpoint into files of at most rowsPerFile rows in the
+// output directory, named payloads-000000.<format>, payloads-000001.<format>, and so on.
+//
+// A file is only given its final name once all its rows have been written, so an interrupted export can be
+// resumed by running it again with the same output directory: the payloads of the complete files are skipped.
+func Export(checkpointFile string, outputDir string, format Format, rowsPerFile uint64, log zerolog.Logger) error {
+	if rowsPerFile == 0 {
+		return fmt.Errorf("rows per file must be positive")
+	}
+
+	err := os.MkdirAll(outputDir, 0755)
+	if err != nil {
+		return fmt.Errorf("could not create output dir: %w", err)
+	}
+
+	err = checkManifest(outputDir, manifest{
+		Checkpoint:  filepath.Base(checkpointFile),
+		Format:      format,
+		RowsPerFile: rowsPerFile,
+	})
+	if err != nil {
+		return err
+	}
+
+	// resume after the last complete file
+	partition := 0
+	for fileExists(partitionPath(outputDir, partition, format)) {
+		partition++
+	}
+	skip := uint64(partition) * rowsPerFile
+	if partition > 0 {
+		log.Info().Int("files", partition).Uint64("skipped_payloads", skip).Msg("resuming export")
+	}
+
+	var current partitionWriter
+	var rows uint64
+
+	closeCurrent := func() error {
+		err := current.Close()
+		if err != nil {
+			return fmt.Errorf("could not close file %d: %w", partition, err)
+		}
+		path := partitionPath(outputDir, partition, format)
+		err = os.Rename(path+".tmp", path)
+		if err != nil {
+			return fmt.Errorf("could not rename file %d: %w", partition, err)
+		}
+		log.Info().Str("file", path).Uint64("rows", rows).Msg("exported payloads")
+
+		current = nil
+		rows = 0
+		partition++
+		return nil
+	}
+
+	err = wal.StreamCheckpointPayloads(checkpointFile, skip, func(payload *ledger.Payload) error {
+		if current == nil {
+			var err error
+			current, err = newPartitionWriter(partitionPath(outputDir, partition, format)+".tmp", format)
+			if err != nil {
+				return fmt.Errorf("could not create file %d: %w", partition, err)
+			}
+		}
+
+		row, err := newRow(payload)
+		if err != nil {
+			return err
+		}
+		err = current.Write(row)
+		if err != nil {
+			return fmt.Errorf("could not write row to file %d: %w", partition, err)
+		}
+
+		rows++
+		if rows == rowsPerFile {
+			return closeCurrent()
+		}
+		return nil
+	}, &log)
+	if err != nil {
+		if current != nil {
+			_ = current.Close()
+		}
+		return fmt.Errorf("could not export checkpoint payloads: %w", err)
+	}
+
+	if current != nil {
+		return closeCurrent()
+	}
+	return nil
+}
+
+// checkManifest writes the manifest of a new export, or checks that the export being resumed has the
+// same settings.
+func checkManifest(outputDir string, expected manifest) error {
+	path := filepath.Join(outputDir, manifestFilename)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		data, err = json.Marshal(expected)
+		if err != nil {
+			return fmt.Errorf("could not encode manifest: %w", err)
+		}
+		err = os.WriteFile(path, data, 0644)
+		if err != nil {
+			return fmt.Errorf("could not write manifest: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read manifest: %w", err)
+	}
+
+	var existing manifest
+	err = json.Unmarshal(data, &existing)
+	if err != nil {
+		return fmt.Errorf("could not decode manifest: %w", err)
+	}
+	if existing != expected {
+		return fmt.Errorf("output dir contains another export (%+v), which can't be resumed with %+v", existing, expected)
+	}
+	return nil
+}
+
+func partitionPath(outputDir string, partition int, format Format) string {
+	return filepath.Join(outputDir, fmt.Sprintf("payloads-%06d.%s", partition, format))
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func newRow(payload *ledger.Payload) (*Row, error) {
+	id, err := state.KeyToRegisterID(payload.Key)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert payload key: %w", err)
+	}
+
+	row := &Row{
+		Owner:      hex.EncodeToString([]byte(id.Owner)),
+		Controller: hex.EncodeToString([]byte(id.Controller)),
+		Key:        id.Key,
+		ValueSize:  int64(len(payload.Value)),
+	}
+
+	if isSlabKey(id.Key) {
+		row.Key = "$" + strconv.FormatUint(binary.BigEndian.Uint64([]byte(id.Key[1:])), 10)
+		row.CadenceType = cadenceType(id.Owner, id.Key, payload.Value)
+	}
+
+	return row, nil
+}
+
+func isSlabKey(key string) bool {
+	return len(key) == 1+len(atree.StorageIndex{}) && key[0] == '$'
+}
+
+// cadenceType returns the Cadence type of the array or dictionary whose root slab is stored in the register
+// with the given slab key, or an empty string if the slab can't be decoded or is not a root slab.
+func cadenceType(owner string, key string, value []byte) string {
+	var id atree.StorageID
+	copy(id.Address[:], owner)
+	copy(id.Index[:], key[1:])
+
+	slab, err := atree.DecodeSlab(id, value, interpreter.CBORDecMode, decodeStorable, decodeTypeInfo)
+	if err != nil {
+		return ""
+	}
+
+	var typeInfo atree.TypeInfo
+	switch slab := slab.(type) {
+	case atree.ArraySlab:
+		if extraData := slab.ExtraData(); extraData != nil {
+			typeInfo = extraData.TypeInfo
+		}
+	case atree.MapSlab:
+		if extraData := slab.ExtraData(); extraData != nil {
+			typeInfo = extraData.TypeInfo
+		}
+	}
+
+	staticType, ok := typeInfo.(interpreter.StaticType)
+	if !ok {
+		return ""
+	}
+	return staticType.String()
+}
+
+func decodeStorable(decoder *cbor.StreamDecoder, id atree.StorageID) (atree.Storable, error) {
+	return interpreter.DecodeStorable(decoder, id, nil)
+}
+
+func decodeTypeInfo(decoder *cbor.StreamDecoder) (atree.TypeInfo, error) {
+	return interpreter.DecodeTypeInfo(decoder, nil)
+}
+
+// partitionWriter writes the rows of one exported file.
+type partitionWriter interface {
+	Write(row *Row) error
+	Close() error
+}
+
+func newPartitionWriter(path string, format Format) (partitionWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(file)
+	case FormatParquet:
+		return newParquetWriter(file)
+	default:
+		_ = file.Close()
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+type csvWriter struct {
+	file     *os.File
+	buffered *bufio.Writer
+	writer   *csv.Writer
+}
+
+func newCSVWriter(file *os.File) (*csvWriter, error) {
+	buffered := bufio.NewWriter(file)
+	w := &csvWriter{
+		file:     file,
+		buffered: buffered,
+		writer:   csv.NewWriter(buffered),
+	}
+
+	err := w.writer.Write(csvHeader)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *csvWriter) Write(row *Row) error {
+	return w.writer.Write([]string{
+		row.Owner,
+		row.Controller,
+		row.Key,
+		strconv.FormatInt(row.ValueSize, 10),
+		row.CadenceType,
+	})
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	err := w.writer.Error()
+	if err == nil {
+		err = w.buffered.Flush()
+	}
+	return closeFile(w.file, err)
+}
+
+type parquetWriter struct {
+	file   *os.File
+	writer *writer.ParquetWriter
+}
+
+func newParquetWriter(file *os.File) (*parquetWriter, error) {
+	w, err := writer.NewParquetWriterFromWriter(file, new(Row), 1)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	w.CompressionType = parquet.CompressionCodec_SNAPPY
+
+	return &parquetWriter{
+		file:   file,
+		writer: w,
+	}, nil
+}
+
+func (w *parquetWriter) Write(row *Row) error {
+	return w.writer.Write(row)
+}
+
+func (w *parquetWriter) Close() error {
+	return closeFile(w.file, w.writer.WriteStop())
+}
+
+// closeFile syncs and closes the file, and returns the first error.
+func closeFile(file *os.File, err error) error {
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
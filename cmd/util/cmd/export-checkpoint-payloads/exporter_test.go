@@ -0,0 +1,151 @@
+package export_checkpoint_payloads
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onflow/atree"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/utils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestExport(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		checkpoint, expected := createCheckpoint(t, dir)
+		log := zerolog.Nop()
+
+		t.Run("csv", func(t *testing.T) {
+			outputDir := filepath.Join(dir, "csv")
+
+			err := Export(checkpoint, outputDir, FormatCSV, 4, log)
+			require.NoError(t, err)
+			rows := readCSVRows(t, outputDir, 3)
+			require.ElementsMatch(t, expected, rows)
+
+			// the export of an interrupted file is resumed
+			lastFile := partitionPath(outputDir, 2, FormatCSV)
+			require.NoError(t, os.Rename(lastFile, lastFile+".tmp"))
+			err = Export(checkpoint, outputDir, FormatCSV, 4, log)
+			require.NoError(t, err)
+			require.Equal(t, rows, readCSVRows(t, outputDir, 3))
+
+			// an export can't be resumed with other settings
+			err = Export(checkpoint, outputDir, FormatCSV, 5, log)
+			require.Error(t, err)
+		})
+
+		t.Run("parquet", func(t *testing.T) {
+			outputDir := filepath.Join(dir, "parquet")
+
+			err := Export(checkpoint, outputDir, FormatParquet, 4, log)
+			require.NoError(t, err)
+
+			var rows []Row
+			for i := 0; i < 3; i++ {
+				file, err := local.NewLocalFileReader(partitionPath(outputDir, i, FormatParquet))
+				require.NoError(t, err)
+				r, err := reader.NewParquetReader(file, new(Row), 1)
+				require.NoError(t, err)
+
+				read := make([]Row, r.GetNumRows())
+				require.NoError(t, r.Read(&read))
+				rows = append(rows, read...)
+
+				r.ReadStop()
+				require.NoError(t, file.Close())
+			}
+			require.NoFileExists(t, partitionPath(outputDir, 3, FormatParquet))
+			require.ElementsMatch(t, expected, rows)
+		})
+	})
+}
+
+// createCheckpoint stores a checkpoint with the root slab of a Cadence array and 9 other registers, and returns
+// the rows expected to be exported.
+func createCheckpoint(t *testing.T, dir string) (string, []Row) {
+	address := atree.Address{1, 2, 3, 4, 5, 6, 7, 8}
+
+	storage := atree.NewBasicSlabStorage(interpreter.CBOREncMode, interpreter.CBORDecMode, decodeStorable, decodeTypeInfo)
+	array, err := atree.NewArray(storage, address, interpreter.VariableSizedStaticType{Type: interpreter.PrimitiveStaticTypeInt})
+	require.NoError(t, err)
+	slabs, err := storage.Encode()
+	require.NoError(t, err)
+	id := array.StorageID()
+	slab := slabs[id]
+
+	slabKey := "$" + string(id.Index[:])
+	payloads := []ledger.Payload{
+		*ledger.NewPayload(state.RegisterIDToKey(flow.NewRegisterID(string(address[:]), "", slabKey)), slab),
+	}
+	expected := []Row{{
+		Owner:       hex.EncodeToString(address[:]),
+		Key:         "$1",
+		ValueSize:   int64(len(slab)),
+		CadenceType: "[Int]",
+	}}
+
+	for i := 0; i < 9; i++ {
+		owner := unittest.RandomAddressFixture()
+		value := []byte(fmt.Sprintf("value %d", i))
+		key := fmt.Sprintf("key %d", i)
+		payloads = append(payloads, *ledger.NewPayload(state.RegisterIDToKey(flow.NewRegisterID(string(owner[:]), "", key)), value))
+		expected = append(expected, Row{
+			Owner:     hex.EncodeToString(owner[:]),
+			Key:       key,
+			ValueSize: int64(len(value)),
+		})
+	}
+
+	updatedTrie, _, err := trie.NewTrieWithUpdatedRegisters(trie.NewEmptyMTrie(), utils.RandomPaths(len(payloads)), payloads, true)
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "root.checkpoint")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, wal.StoreCheckpoint(file, updatedTrie))
+	require.NoError(t, file.Close())
+
+	return path, expected
+}
+
+func readCSVRows(t *testing.T, outputDir string, files int) []Row {
+	var rows []Row
+	for i := 0; i < files; i++ {
+		file, err := os.Open(partitionPath(outputDir, i, FormatCSV))
+		require.NoError(t, err)
+		records, err := csv.NewReader(file).ReadAll()
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		require.Equal(t, csvHeader, records[0])
+		for _, record := range records[1:] {
+			var valueSize int64
+			_, err := fmt.Sscan(record[3], &valueSize)
+			require.NoError(t, err)
+			rows = append(rows, Row{
+				Owner:       record[0],
+				Controller:  record[1],
+				Key:         record[2],
+				ValueSize:   valueSize,
+				CadenceType: record[4],
+			})
+		}
+	}
+	require.NoFileExists(t, partitionPath(outputDir, files, FormatCSV))
+	return rows
+}
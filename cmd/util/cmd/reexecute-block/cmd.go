@@ -0,0 +1,215 @@
+package reexecute
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/onflow/flow-go/cmd/util/cmd/common"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/ledger/complete/wal/fixtures"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+)
+
+var (
+	flagExecutionStateDir     string
+	flagCheckpoint            string
+	flagDatadir               string
+	flagBlockID               string
+	flagOutputFile            string
+	flagStopAtFirstDivergence bool
+)
+
+var Cmd = &cobra.Command{
+	Use:   "reexecute-block",
+	Short: "re-executes a block and prints how the outcome compares with the stored execution results as JSON",
+	Run:   run,
+}
+
+func init() {
+	Cmd.Flags().StringVar(&flagExecutionStateDir, "execution-state-dir", "",
+		"Execution Node state dir (where WAL logs are written")
+
+	Cmd.Flags().StringVar(&flagCheckpoint, "checkpoint", "",
+		"checkpoint file to read the state of the parent block from, instead of the checkpoints and WAL in the execution state dir")
+
+	Cmd.Flags().StringVar(&flagDatadir, "datadir", "",
+		"directory that stores the protocol state")
+	_ = Cmd.MarkFlagRequired("datadir")
+
+	Cmd.Flags().StringVar(&flagBlockID, "block-id", "",
+		"ID of the block to re-execute")
+	_ = Cmd.MarkFlagRequired("block-id")
+
+	Cmd.Flags().StringVar(&flagOutputFile, "output-file", "",
+		"File to write the report to (default: stdout)")
+
+	Cmd.Flags().BoolVar(&flagStopAtFirstDivergence, "stop-at-first-divergence", false,
+		"stop comparing at the first diverging transaction, and only compare the chunks up to its chunk")
+}
+
+// readOnlyWAL replays the checkpoints and segments of a WAL, but doesn't record any update,
+// so that re-executing a block leaves the execution state dir untouched.
+type readOnlyWAL struct {
+	wal.LedgerWAL
+}
+
+func (w *readOnlyWAL) RecordUpdate(*ledger.TrieUpdate) error { return nil }
+
+func (w *readOnlyWAL) RecordDelete(ledger.RootHash) error { return nil }
+
+// checkpointWAL is a WAL without segments, which only replays the trie of the given state from a
+// checkpoint file, and doesn't record any update.
+type checkpointWAL struct {
+	fixtures.NoopWAL
+	checkpoint string
+	state      ledger.RootHash
+}
+
+func (w *checkpointWAL) ReplayOnForest(forest *mtrie.Forest) error {
+	tries, err := wal.LoadCheckpoint(w.checkpoint, &log.Logger)
+	if err != nil {
+		return fmt.Errorf("cannot load checkpoint %s: %w", w.checkpoint, err)
+	}
+
+	for _, t := range tries {
+		if t.RootHash() == w.state {
+			return forest.AddTrie(t)
+		}
+	}
+	return fmt.Errorf("checkpoint %s doesn't contain state %x", w.checkpoint, w.state[:])
+}
+
+func run(*cobra.Command, []string) {
+	if flagExecutionStateDir == "" && flagCheckpoint == "" {
+		log.Fatal().Msg("either --execution-state-dir or --checkpoint must be set")
+	}
+
+	err := reexecute()
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot re-execute block")
+	}
+}
+
+// reexecute re-executes the block, and writes the report comparing it with the stored execution.
+func reexecute() (err error) {
+	blockID, err := flow.HexStringToIdentifier(flagBlockID)
+	if err != nil {
+		return fmt.Errorf("malformed block ID: %w", err)
+	}
+
+	db := common.InitStorage(flagDatadir)
+	defer db.Close()
+	storages := common.InitStorages(db)
+
+	block, err := ExecutableBlock(storages, blockID)
+	if err != nil {
+		return fmt.Errorf("cannot read block: %w", err)
+	}
+
+	stored, err := LoadStoredExecution(storages, blockID)
+	if err != nil {
+		return fmt.Errorf("cannot read stored execution: %w", err)
+	}
+
+	var ledgerWAL wal.LedgerWAL
+	if flagCheckpoint != "" {
+		ledgerWAL = &checkpointWAL{checkpoint: flagCheckpoint, state: ledger.RootHash(*block.StartState)}
+	} else {
+		diskWal, err := wal.NewDiskWAL(
+			zerolog.Nop(),
+			nil,
+			metrics.NewNoopCollector(),
+			flagExecutionStateDir,
+			complete.DefaultCacheSize,
+			pathfinder.PathByteSize,
+			wal.SegmentSize,
+		)
+		if err != nil {
+			return fmt.Errorf("cannot create disk WAL: %w", err)
+		}
+		defer func() {
+			<-diskWal.Done()
+		}()
+		ledgerWAL = &readOnlyWAL{LedgerWAL: diskWal}
+	}
+
+	led, err := complete.NewLedger(
+		ledgerWAL,
+		complete.DefaultCacheSize,
+		&metrics.NoopCollector{},
+		log.Logger,
+		complete.DefaultPathFinderVersion)
+	if err != nil {
+		return fmt.Errorf("cannot create ledger from write-a-head logs and checkpoints: %w", err)
+	}
+
+	if !led.HasState(ledger.State(*block.StartState)) {
+		return fmt.Errorf("state %x of the parent block is missing, it may have been evicted from the execution state dir, use --checkpoint with a checkpoint containing it", block.StartState[:])
+	}
+
+	log.Info().
+		Hex("block_id", blockID[:]).
+		Uint64("height", block.Height()).
+		Int("collections", len(block.CompleteCollections)).
+		Msgf("re-executing block on top of state %x", block.StartState[:])
+
+	vmCtx := fvm.NewContext(log.Logger, fvm.NodeOptions(block.Block.Header.ChainID, storages.Headers)...)
+	computed, err := ExecuteBlock(log.Logger, led, vmCtx, block)
+	if err != nil {
+		return fmt.Errorf("cannot execute block: %w", err)
+	}
+
+	report, err := Compare(computed, stored, flagStopAtFirstDivergence)
+	if err != nil {
+		return fmt.Errorf("cannot compare execution: %w", err)
+	}
+
+	var output io.Writer = os.Stdout
+	if flagOutputFile != "" {
+		file, err := os.Create(flagOutputFile)
+		if err != nil {
+			return fmt.Errorf("cannot create output file: %w", err)
+		}
+		fileWriter := bufio.NewWriter(file)
+		defer func() {
+			flushErr := fileWriter.Flush()
+			closeErr := file.Close()
+			// Return flush and close errors if there isn't any prior error to return.
+			if err == nil {
+				err = flushErr
+			}
+			if err == nil {
+				err = closeErr
+			}
+		}()
+		output = fileWriter
+	}
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(report)
+	if err != nil {
+		return fmt.Errorf("cannot write report: %w", err)
+	}
+
+	log.Info().
+		Bool("diverged", report.Diverged).
+		Int("diverging_transactions", len(report.Transactions)).
+		Int("results", len(report.Results)).
+		Msg("comparison done")
+
+	return nil
+}
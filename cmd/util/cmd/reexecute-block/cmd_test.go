@@ -0,0 +1,61 @@
+package reexecute
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/utils"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/module/metrics"
+)
+
+func TestCheckpointWAL(t *testing.T) {
+	forest, err := mtrie.NewForest(10, &metrics.NoopCollector{}, nil)
+	require.NoError(t, err)
+
+	var tries []*trie.MTrie
+	rootHash := forest.GetEmptyRootHash()
+	for i := 0; i < 3; i++ {
+		rootHash, err = forest.Update(&ledger.TrieUpdate{
+			RootHash: rootHash,
+			Paths:    utils.RandomPaths(10),
+			Payloads: utils.RandomPayloads(10, 2, 10),
+		})
+		require.NoError(t, err)
+
+		updatedTrie, err := forest.GetTrie(rootHash)
+		require.NoError(t, err)
+		tries = append(tries, updatedTrie)
+	}
+
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint.00000001")
+	file, err := os.Create(checkpoint)
+	require.NoError(t, err)
+	err = wal.StoreCheckpoint(file, tries...)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	t.Run("replays the trie of the state", func(t *testing.T) {
+		state := tries[1].RootHash()
+		led, err := complete.NewLedger(&checkpointWAL{checkpoint: checkpoint, state: state}, 100, &metrics.NoopCollector{}, zerolog.Nop(), complete.DefaultPathFinderVersion)
+		require.NoError(t, err)
+
+		require.True(t, led.HasState(ledger.State(state)))
+		require.False(t, led.HasState(ledger.State(tries[0].RootHash())))
+		require.False(t, led.HasState(ledger.State(tries[2].RootHash())))
+	})
+
+	t.Run("fails if the checkpoint doesn't contain the state", func(t *testing.T) {
+		state := ledger.RootHash(forest.GetEmptyRootHash())
+		_, err := complete.NewLedger(&checkpointWAL{checkpoint: checkpoint, state: state}, 100, &metrics.NoopCollector{}, zerolog.Nop(), complete.DefaultPathFinderVersion)
+		require.ErrorContains(t, err, "doesn't contain state")
+	})
+}
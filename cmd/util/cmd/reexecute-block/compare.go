@@ -0,0 +1,379 @@
+package reexecute
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/onflow/flow-go/engine/execution"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// StoredExecution is the outcome of the execution of a block, as stored by the node.
+type StoredExecution struct {
+	// StateCommitment is the state commitment the node computed for the block,
+	// or flow.DummyStateCommitment if the node didn't execute the block.
+	StateCommitment flow.StateCommitment
+	// Results are the distinct execution results known for the block,
+	// the node's own result as well as the results of the receipts it received.
+	Results []*flow.ExecutionResult
+	// Events and TransactionResults are only stored by execution nodes.
+	Events             []flow.Event
+	TransactionResults []flow.TransactionResult
+}
+
+// LoadStoredExecution reads the stored outcome of the execution of the block.
+func LoadStoredExecution(storages *storage.All, blockID flow.Identifier) (*StoredExecution, error) {
+	stored := &StoredExecution{
+		StateCommitment: flow.DummyStateCommitment,
+	}
+
+	commit, err := storages.Commits.ByBlockID(blockID)
+	if err == nil {
+		stored.StateCommitment = commit
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("could not get state commitment: %w", err)
+	}
+
+	resultIDs := make(map[flow.Identifier]struct{})
+	addResult := func(result *flow.ExecutionResult) {
+		resultID := result.ID()
+		if _, ok := resultIDs[resultID]; ok {
+			return
+		}
+		resultIDs[resultID] = struct{}{}
+		stored.Results = append(stored.Results, result)
+	}
+
+	result, err := storages.Results.ByBlockID(blockID)
+	if err == nil {
+		addResult(result)
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("could not get execution result: %w", err)
+	}
+
+	receipts, err := storages.Receipts.ByBlockID(blockID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("could not get execution receipts: %w", err)
+	}
+	for _, receipt := range receipts {
+		addResult(&receipt.ExecutionResult)
+	}
+
+	stored.Events, err = storages.Events.ByBlockID(blockID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("could not get events: %w", err)
+	}
+
+	stored.TransactionResults, err = storages.TransactionResults.ByBlockID(blockID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("could not get transaction results: %w", err)
+	}
+
+	if stored.StateCommitment == flow.DummyStateCommitment && len(stored.Results) == 0 {
+		return nil, fmt.Errorf("no execution of block %v is stored", blockID)
+	}
+
+	return stored, nil
+}
+
+// Mismatch is a value which differs between the stored and the re-computed execution of a block.
+type Mismatch struct {
+	Field    string `json:"field"`
+	Stored   string `json:"stored"`
+	Computed string `json:"computed"`
+}
+
+// TransactionReport lists the mismatches of a transaction.
+type TransactionReport struct {
+	Index         int        `json:"index"`
+	TransactionID string     `json:"transaction_id"`
+	Chunk         int        `json:"chunk"`
+	Mismatches    []Mismatch `json:"mismatches"`
+}
+
+// ResultReport lists the mismatches between a stored execution result and the re-computed chunks
+// and service events.
+type ResultReport struct {
+	ResultID   string     `json:"result_id"`
+	Mismatches []Mismatch `json:"mismatches"`
+}
+
+// Report is the outcome of the comparison of the re-execution of a block with its stored execution.
+type Report struct {
+	BlockID     string `json:"block_id"`
+	BlockHeight uint64 `json:"block_height"`
+	StartState  string `json:"start_state"`
+	EndState    string `json:"end_state"`
+	// Diverged is true if any mismatch was found.
+	Diverged bool `json:"diverged"`
+	// Mismatches are the mismatches of the block, which aren't specific to a result or transaction.
+	Mismatches []Mismatch `json:"mismatches"`
+	// TransactionsCompared is the number of transactions compared, which is lower than the number of
+	// transactions of the block if the node doesn't store transaction results, or if the comparison
+	// stopped at the first diverging transaction.
+	TransactionsCompared int `json:"transactions_compared"`
+	// Transactions are the diverging transactions, in execution order.
+	Transactions []TransactionReport `json:"transactions"`
+	Results      []ResultReport      `json:"results"`
+}
+
+// Compare compares the re-computed execution of a block with its stored execution.
+// If stopAtFirstDivergence is set, the comparison stops at the first diverging transaction,
+// and only the chunks up to the chunk of this transaction are compared.
+func Compare(computed *execution.ComputationResult, stored *StoredExecution, stopAtFirstDivergence bool) (*Report, error) {
+	block := computed.ExecutableBlock
+	startState := *block.StartState
+
+	// the execution data isn't uploaded when re-executing, so the ID of the result can't be compared,
+	// only its chunks and service events
+	endState, _, result, err := execution.GenerateExecutionResultAndChunkDataPacks(flow.ZeroID, startState, computed)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate execution result: %w", err)
+	}
+
+	report := &Report{
+		BlockID:      block.ID().String(),
+		BlockHeight:  block.Height(),
+		StartState:   hex.EncodeToString(startState[:]),
+		EndState:     hex.EncodeToString(endState[:]),
+		Mismatches:   []Mismatch{},
+		Transactions: []TransactionReport{},
+		Results:      []ResultReport{},
+	}
+
+	// chunks compared, all of them unless the comparison stops early
+	chunks := len(result.Chunks)
+	chunkOfTransaction := transactionChunks(computed)
+
+	if len(stored.TransactionResults) > 0 {
+		computedEvents := eventsByTransaction(computed.Events...)
+		storedEvents := eventsByTransaction(stored.Events)
+		storedResults := make(map[flow.Identifier]flow.TransactionResult, len(stored.TransactionResults))
+		for _, txResult := range stored.TransactionResults {
+			storedResults[txResult.TransactionID] = txResult
+		}
+
+		for i, txResult := range computed.TransactionResults {
+			report.TransactionsCompared++
+
+			mismatches := compareTransaction(
+				txResult,
+				storedResults,
+				computedEvents[txResult.TransactionID],
+				storedEvents[txResult.TransactionID],
+			)
+			if len(mismatches) == 0 {
+				continue
+			}
+
+			report.Transactions = append(report.Transactions, TransactionReport{
+				Index:         i,
+				TransactionID: txResult.TransactionID.String(),
+				Chunk:         chunkOfTransaction[i],
+				Mismatches:    mismatches,
+			})
+
+			if stopAtFirstDivergence {
+				chunks = chunkOfTransaction[i] + 1
+				break
+			}
+		}
+	}
+
+	if stored.StateCommitment != flow.DummyStateCommitment && chunks == len(result.Chunks) && stored.StateCommitment != endState {
+		report.Mismatches = append(report.Mismatches, Mismatch{
+			Field:    "state_commitment",
+			Stored:   hex.EncodeToString(stored.StateCommitment[:]),
+			Computed: hex.EncodeToString(endState[:]),
+		})
+	}
+
+	for _, storedResult := range stored.Results {
+		mismatches, err := compareResult(result, storedResult, chunks)
+		if err != nil {
+			return nil, fmt.Errorf("could not compare result %v: %w", storedResult.ID(), err)
+		}
+		report.Results = append(report.Results, ResultReport{
+			ResultID:   storedResult.ID().String(),
+			Mismatches: mismatches,
+		})
+		if len(mismatches) > 0 {
+			report.Diverged = true
+		}
+	}
+
+	if len(report.Mismatches) > 0 || len(report.Transactions) > 0 {
+		report.Diverged = true
+	}
+
+	return report, nil
+}
+
+// transactionChunks returns the index of the chunk of each transaction of the block, in execution order.
+func transactionChunks(computed *execution.ComputationResult) []int {
+	block := computed.ExecutableBlock
+	chunks := make([]int, 0, len(computed.TransactionResults))
+	for i, guarantee := range block.Block.Payload.Guarantees {
+		collection := block.CompleteCollections[guarantee.ID()]
+		for range collection.Transactions {
+			chunks = append(chunks, i)
+		}
+	}
+	// the remaining transactions are executed in the system chunk
+	for len(chunks) < len(computed.TransactionResults) {
+		chunks = append(chunks, len(block.Block.Payload.Guarantees))
+	}
+	return chunks
+}
+
+func eventsByTransaction(eventLists ...flow.EventsList) map[flow.Identifier][]flow.Event {
+	events := make(map[flow.Identifier][]flow.Event)
+	for _, list := range eventLists {
+		for _, event := range list {
+			events[event.TransactionID] = append(events[event.TransactionID], event)
+		}
+	}
+	return events
+}
+
+func compareTransaction(
+	computed flow.TransactionResult,
+	storedResults map[flow.Identifier]flow.TransactionResult,
+	computedEvents []flow.Event,
+	storedEvents []flow.Event,
+) []Mismatch {
+	stored, ok := storedResults[computed.TransactionID]
+	if !ok {
+		return []Mismatch{{
+			Field:    "result",
+			Stored:   "",
+			Computed: computed.String(),
+		}}
+	}
+
+	var mismatches []Mismatch
+	if stored.ErrorMessage != computed.ErrorMessage {
+		mismatches = append(mismatches, Mismatch{
+			Field:    "error_message",
+			Stored:   stored.ErrorMessage,
+			Computed: computed.ErrorMessage,
+		})
+	}
+	// results stored before the computation used was recorded have no computation used
+	if stored.ComputationUsed != 0 && stored.ComputationUsed != computed.ComputationUsed {
+		mismatches = append(mismatches, Mismatch{
+			Field:    "computation_used",
+			Stored:   strconv.FormatUint(stored.ComputationUsed, 10),
+			Computed: strconv.FormatUint(computed.ComputationUsed, 10),
+		})
+	}
+
+	if len(storedEvents) != len(computedEvents) {
+		mismatches = append(mismatches, Mismatch{
+			Field:    "events",
+			Stored:   strconv.Itoa(len(storedEvents)),
+			Computed: strconv.Itoa(len(computedEvents)),
+		})
+	}
+	for i := 0; i < len(storedEvents) && i < len(computedEvents); i++ {
+		s, c := storedEvents[i], computedEvents[i]
+		if s.Type != c.Type {
+			mismatches = append(mismatches, Mismatch{
+				Field:    fmt.Sprintf("events[%d].type", i),
+				Stored:   string(s.Type),
+				Computed: string(c.Type),
+			})
+		}
+		if s.EventIndex != c.EventIndex {
+			mismatches = append(mismatches, Mismatch{
+				Field:    fmt.Sprintf("events[%d].event_index", i),
+				Stored:   strconv.FormatUint(uint64(s.EventIndex), 10),
+				Computed: strconv.FormatUint(uint64(c.EventIndex), 10),
+			})
+		}
+		if string(s.Payload) != string(c.Payload) {
+			mismatches = append(mismatches, Mismatch{
+				Field:    fmt.Sprintf("events[%d].payload", i),
+				Stored:   string(s.Payload),
+				Computed: string(c.Payload),
+			})
+		}
+	}
+
+	return mismatches
+}
+
+// compareResult compares the first chunks of the stored result with the computed result.
+// The service events are emitted by the system chunk, so they are only compared if all chunks are.
+func compareResult(computed *flow.ExecutionResult, stored *flow.ExecutionResult, chunks int) ([]Mismatch, error) {
+	mismatches := []Mismatch{}
+
+	if chunks == len(computed.Chunks) && len(stored.Chunks) != len(computed.Chunks) {
+		mismatches = append(mismatches, Mismatch{
+			Field:    "chunks",
+			Stored:   strconv.Itoa(len(stored.Chunks)),
+			Computed: strconv.Itoa(len(computed.Chunks)),
+		})
+	}
+
+	for i := 0; i < chunks && i < len(stored.Chunks); i++ {
+		s, c := stored.Chunks[i], computed.Chunks[i]
+		if s.StartState != c.StartState {
+			mismatches = append(mismatches, Mismatch{
+				Field:    fmt.Sprintf("chunks[%d].start_state", i),
+				Stored:   hex.EncodeToString(s.StartState[:]),
+				Computed: hex.EncodeToString(c.StartState[:]),
+			})
+		}
+		if s.EndState != c.EndState {
+			mismatches = append(mismatches, Mismatch{
+				Field:    fmt.Sprintf("chunks[%d].end_state", i),
+				Stored:   hex.EncodeToString(s.EndState[:]),
+				Computed: hex.EncodeToString(c.EndState[:]),
+			})
+		}
+		if s.EventCollection != c.EventCollection {
+			mismatches = append(mismatches, Mismatch{
+				Field:    fmt.Sprintf("chunks[%d].event_collection", i),
+				Stored:   s.EventCollection.String(),
+				Computed: c.EventCollection.String(),
+			})
+		}
+		if s.NumberOfTransactions != c.NumberOfTransactions {
+			mismatches = append(mismatches, Mismatch{
+				Field:    fmt.Sprintf("chunks[%d].number_of_transactions", i),
+				Stored:   strconv.FormatUint(s.NumberOfTransactions, 10),
+				Computed: strconv.FormatUint(c.NumberOfTransactions, 10),
+			})
+		}
+	}
+
+	if chunks < len(computed.Chunks) {
+		return mismatches, nil
+	}
+
+	equal, err := computed.ServiceEvents.EqualTo(stored.ServiceEvents)
+	if err != nil {
+		return nil, fmt.Errorf("could not compare service events: %w", err)
+	}
+	if !equal {
+		mismatches = append(mismatches, Mismatch{
+			Field:    "service_events",
+			Stored:   serviceEventTypes(stored.ServiceEvents),
+			Computed: serviceEventTypes(computed.ServiceEvents),
+		})
+	}
+
+	return mismatches, nil
+}
+
+func serviceEventTypes(events flow.ServiceEventList) string {
+	types := make([]string, len(events))
+	for i, event := range events {
+		types[i] = event.Type
+	}
+	return fmt.Sprintf("%v", types)
+}
@@ -0,0 +1,154 @@
+package reexecute
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution"
+	"github.com/onflow/flow-go/engine/execution/state/bootstrap"
+	"github.com/onflow/flow-go/engine/execution/testutil"
+	"github.com/onflow/flow-go/fvm"
+	fvmMock "github.com/onflow/flow-go/fvm/mock"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/wal/fixtures"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/epochs"
+	"github.com/onflow/flow-go/module/mempool/entity"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestReexecuteAndCompare(t *testing.T) {
+	chain := flow.Testnet.Chain()
+
+	led, err := complete.NewLedger(&fixtures.NoopWAL{}, 100, &metrics.NoopCollector{}, zerolog.Nop(), complete.DefaultPathFinderVersion)
+	require.NoError(t, err)
+
+	// set 0 clusters to pass n_collectors >= n_clusters check
+	epochConfig := epochs.DefaultEpochConfig()
+	epochConfig.NumCollectorClusters = 0
+	startState, err := bootstrap.NewBootstrapper(zerolog.Nop()).BootstrapLedger(
+		led,
+		unittest.ServiceAccountPublicKey,
+		chain,
+		fvm.WithInitialTokenSupply(unittest.GenesisTokenSupply),
+		fvm.WithEpochConfig(epochConfig),
+	)
+	require.NoError(t, err)
+
+	_, tx := testutil.CreateAccountCreationTransaction(t, chain)
+	err = testutil.SignTransactionAsServiceAccount(tx, 0, chain)
+	require.NoError(t, err)
+
+	collection := flow.Collection{Transactions: []*flow.TransactionBody{tx}}
+	guarantee := unittest.CollectionGuaranteeFixture(unittest.WithCollection(&collection))
+	block := unittest.BlockFixture()
+	block.Header.ChainID = chain.ChainID()
+	block.SetPayload(flow.Payload{Guarantees: []*flow.CollectionGuarantee{guarantee}})
+
+	executableBlock := &entity.ExecutableBlock{
+		Block: &block,
+		CompleteCollections: map[flow.Identifier]*entity.CompleteCollection{
+			guarantee.ID(): {
+				Guarantee:    guarantee,
+				Transactions: collection.Transactions,
+			},
+		},
+		StartState: &startState,
+	}
+
+	vmCtx := fvm.NewContext(zerolog.Nop(), fvm.WithChain(chain), fvm.WithBlocks(new(fvmMock.Blocks)))
+	computed, err := ExecuteBlock(zerolog.Nop(), led, vmCtx, executableBlock)
+	require.NoError(t, err)
+	// the transaction of the collection and the system transaction
+	require.Len(t, computed.TransactionResults, 2)
+	require.Empty(t, computed.TransactionResults[0].ErrorMessage)
+
+	// the node stored the same outcome
+	endState, _, result, err := execution.GenerateExecutionResultAndChunkDataPacks(unittest.IdentifierFixture(), startState, computed)
+	require.NoError(t, err)
+	var events []flow.Event
+	for _, list := range computed.Events {
+		events = append(events, list...)
+	}
+	require.NotEmpty(t, events)
+
+	stored := func() *StoredExecution {
+		txResults := make([]flow.TransactionResult, len(computed.TransactionResults))
+		copy(txResults, computed.TransactionResults)
+		storedResult := *result
+		storedResult.Chunks = make(flow.ChunkList, len(result.Chunks))
+		for i, chunk := range result.Chunks {
+			c := *chunk
+			storedResult.Chunks[i] = &c
+		}
+		return &StoredExecution{
+			StateCommitment:    endState,
+			Results:            []*flow.ExecutionResult{&storedResult},
+			Events:             events,
+			TransactionResults: txResults,
+		}
+	}
+
+	t.Run("same outcome", func(t *testing.T) {
+		report, err := Compare(computed, stored(), false)
+		require.NoError(t, err)
+
+		require.False(t, report.Diverged)
+		require.Equal(t, block.ID().String(), report.BlockID)
+		require.Equal(t, 2, report.TransactionsCompared)
+		require.Empty(t, report.Mismatches)
+		require.Empty(t, report.Transactions)
+		require.Len(t, report.Results, 1)
+		require.Empty(t, report.Results[0].Mismatches)
+	})
+
+	// the stored transaction failed and the chunk of its collection ended in another state
+	diverged := func() *StoredExecution {
+		s := stored()
+		s.StateCommitment = flow.StateCommitment(unittest.StateCommitmentFixture())
+		s.TransactionResults[0].ErrorMessage = "failed"
+		s.Results[0].Chunks[0].EndState = flow.StateCommitment(unittest.StateCommitmentFixture())
+		s.Results[0].ServiceEvents = flow.ServiceEventList{(&flow.EpochSetup{Counter: 1}).ServiceEvent()}
+		return s
+	}
+
+	t.Run("diverging outcome", func(t *testing.T) {
+		report, err := Compare(computed, diverged(), false)
+		require.NoError(t, err)
+
+		require.True(t, report.Diverged)
+		require.Equal(t, 2, report.TransactionsCompared)
+		require.Len(t, report.Mismatches, 1)
+		require.Equal(t, "state_commitment", report.Mismatches[0].Field)
+
+		require.Len(t, report.Transactions, 1)
+		require.Equal(t, 0, report.Transactions[0].Index)
+		require.Equal(t, tx.ID().String(), report.Transactions[0].TransactionID)
+		require.Equal(t, []Mismatch{{Field: "error_message", Stored: "failed", Computed: ""}}, report.Transactions[0].Mismatches)
+
+		require.Len(t, report.Results, 1)
+		fields := make([]string, 0)
+		for _, mismatch := range report.Results[0].Mismatches {
+			fields = append(fields, mismatch.Field)
+		}
+		require.Equal(t, []string{"chunks[0].end_state", "service_events"}, fields)
+	})
+
+	t.Run("stop at first divergence", func(t *testing.T) {
+		report, err := Compare(computed, diverged(), true)
+		require.NoError(t, err)
+
+		require.True(t, report.Diverged)
+		require.Equal(t, 1, report.TransactionsCompared)
+		require.Len(t, report.Transactions, 1)
+
+		// only the chunk of the diverging transaction is compared
+		require.Empty(t, report.Mismatches)
+		require.Len(t, report.Results, 1)
+		require.Len(t, report.Results[0].Mismatches, 1)
+		require.Equal(t, "chunks[0].end_state", report.Results[0].Mismatches[0].Field)
+	})
+}
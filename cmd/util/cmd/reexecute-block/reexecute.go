@@ -0,0 +1,87 @@
+package reexecute
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine/execution"
+	"github.com/onflow/flow-go/engine/execution/computation/committer"
+	"github.com/onflow/flow-go/engine/execution/computation/computer"
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/engine/execution/state/delta"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/programs"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/mempool/entity"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/module/trace"
+	"github.com/onflow/flow-go/storage"
+)
+
+// ExecutableBlock reads the block and its collections from storage, and returns it for execution
+// on top of the state commitment of its parent.
+func ExecutableBlock(storages *storage.All, blockID flow.Identifier) (*entity.ExecutableBlock, error) {
+	block, err := storages.Blocks.ByID(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get block %v: %w", blockID, err)
+	}
+
+	parentCommit, err := storages.Commits.ByBlockID(block.Header.ParentID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get state commitment of parent block %v: %w", block.Header.ParentID, err)
+	}
+
+	collections := make(map[flow.Identifier]*entity.CompleteCollection, len(block.Payload.Guarantees))
+	for _, guarantee := range block.Payload.Guarantees {
+		collection, err := storages.Collections.ByID(guarantee.CollectionID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get collection %v: %w", guarantee.CollectionID, err)
+		}
+		collections[guarantee.ID()] = &entity.CompleteCollection{
+			Guarantee:    guarantee,
+			Transactions: collection.Transactions,
+		}
+	}
+
+	return &entity.ExecutableBlock{
+		Block:               block,
+		CompleteCollections: collections,
+		StartState:          &parentCommit,
+	}, nil
+}
+
+// ExecuteBlock executes the block with a block computer, reading registers at the start state of the block
+// from the given ledger.
+func ExecuteBlock(
+	log zerolog.Logger,
+	led ledger.Ledger,
+	vmCtx fvm.Context,
+	block *entity.ExecutableBlock,
+) (*execution.ComputationResult, error) {
+	tracer := trace.NewNoopTracer()
+	vm := fvm.NewVirtualMachine(fvm.NewInterpreterRuntime())
+
+	blockComputer, err := computer.NewBlockComputer(
+		vm,
+		vmCtx,
+		metrics.NewNoopCollector(),
+		tracer,
+		log,
+		committer.NewLedgerViewCommitter(led, tracer),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create block computer: %w", err)
+	}
+
+	view := delta.NewView(state.LedgerGetRegister(led, *block.StartState))
+
+	result, err := blockComputer.ExecuteBlock(context.Background(), block, view, programs.NewEmptyPrograms())
+	if err != nil {
+		return nil, fmt.Errorf("could not execute block: %w", err)
+	}
+
+	return result, nil
+}
@@ -59,6 +59,7 @@ func run(*cobra.Command, []string) {
 
 	metrics := &metrics.NoopCollector{}
 	transactionResults := badger.NewTransactionResults(metrics, db, badger.DefaultCacheSize)
+	transactionProfiles := badger.NewTransactionProfiles(metrics, db, badger.DefaultCacheSize)
 	commits := badger.NewCommits(metrics, db)
 	chunkDataPacks := badger.NewChunkDataPacks(metrics, db, badger.NewCollections(db, badger.NewTransactions(metrics, db)), badger.DefaultCacheSize)
 	results := badger.NewExecutionResults(metrics, db)
@@ -72,6 +73,7 @@ func run(*cobra.Command, []string) {
 		state,
 		headers,
 		transactionResults,
+		transactionProfiles,
 		commits,
 		chunkDataPacks,
 		results,
@@ -104,6 +106,7 @@ func removeExecutionResultsFromHeight(
 	protoState protocol.State,
 	headers *badger.Headers,
 	transactionResults *badger.TransactionResults,
+	transactionProfiles *badger.TransactionProfiles,
 	commits *badger.Commits,
 	chunkDataPacks *badger.ChunkDataPacks,
 	results *badger.ExecutionResults,
@@ -143,7 +146,7 @@ func removeExecutionResultsFromHeight(
 
 		blockID := head.ID()
 
-		err = removeForBlockID(headers, commits, transactionResults, results, chunkDataPacks, myReceipts, events, serviceEvents, blockID)
+		err = removeForBlockID(headers, commits, transactionResults, transactionProfiles, results, chunkDataPacks, myReceipts, events, serviceEvents, blockID)
 		if err != nil {
 			return fmt.Errorf("could not remove result for finalized block: %v, %w", blockID, err)
 		}
@@ -162,7 +165,7 @@ func removeExecutionResultsFromHeight(
 	total = len(pendings)
 
 	for _, pending := range pendings {
-		err = removeForBlockID(headers, commits, transactionResults, results, chunkDataPacks, myReceipts, events, serviceEvents, pending)
+		err = removeForBlockID(headers, commits, transactionResults, transactionProfiles, results, chunkDataPacks, myReceipts, events, serviceEvents, pending)
 
 		if err != nil {
 			return fmt.Errorf("could not remove result for pending block %v: %w", pending, err)
@@ -183,6 +186,7 @@ func removeForBlockID(
 	headers *badger.Headers,
 	commits *badger.Commits,
 	transactionResults *badger.TransactionResults,
+	transactionProfiles *badger.TransactionProfiles,
 	results *badger.ExecutionResults,
 	chunks *badger.ChunkDataPacks,
 	myReceipts *badger.MyExecutionReceipts,
@@ -241,6 +245,12 @@ func removeForBlockID(
 		return fmt.Errorf("could not remove transaction results by BlockID %v: %w", blockID, err)
 	}
 
+	// remove transaction profiles
+	err = transactionProfiles.RemoveByBlockID(blockID)
+	if err != nil {
+		return fmt.Errorf("could not remove transaction profiles by BlockID %v: %w", blockID, err)
+	}
+
 	// remove own execution results index
 	err = myReceipts.RemoveIndexByBlockID(blockID)
 	if err != nil {
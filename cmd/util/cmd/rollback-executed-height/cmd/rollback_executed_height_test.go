@@ -32,6 +32,7 @@ func TestReExecuteBlock(t *testing.T) {
 
 		headers := bstorage.NewHeaders(metrics, db)
 		txResults := bstorage.NewTransactionResults(metrics, db, bstorage.DefaultCacheSize)
+		txProfiles := bstorage.NewTransactionProfiles(metrics, db, bstorage.DefaultCacheSize)
 		commits := bstorage.NewCommits(metrics, db)
 		chunkDataPacks := bstorage.NewChunkDataPacks(metrics, db, bstorage.NewCollections(db, bstorage.NewTransactions(metrics, db)), bstorage.DefaultCacheSize)
 		results := bstorage.NewExecutionResults(metrics, db)
@@ -58,6 +59,7 @@ func TestReExecuteBlock(t *testing.T) {
 			events,
 			serviceEvents,
 			txResults,
+			txProfiles,
 			db,
 			trace.NewNoopTracer(),
 		)
@@ -77,6 +79,7 @@ func TestReExecuteBlock(t *testing.T) {
 		// se := unittest.ServiceEventsFixture(2)
 		se := unittest.BlockEventsFixture(header, 8)
 		tes := unittest.TransactionResultsFixture(4)
+		tps := unittest.TransactionProfilesFixture(tes)
 
 		err = headers.Store(&header)
 		require.NoError(t, err)
@@ -91,6 +94,7 @@ func TestReExecuteBlock(t *testing.T) {
 			[]flow.EventsList{blockEvents.Events},
 			se.Events,
 			tes,
+			tps,
 		)
 
 		require.NoError(t, err)
@@ -100,6 +104,7 @@ func TestReExecuteBlock(t *testing.T) {
 			headers,
 			commits,
 			txResults,
+			txProfiles,
 			results,
 			chunkDataPacks,
 			myReceipts,
@@ -120,6 +125,7 @@ func TestReExecuteBlock(t *testing.T) {
 			[]flow.EventsList{blockEvents.Events},
 			se.Events,
 			tes,
+			tps,
 		)
 
 		require.NoError(t, err)
@@ -142,6 +148,7 @@ func TestReExecuteBlockWithDifferentResult(t *testing.T) {
 
 		headers := bstorage.NewHeaders(metrics, db)
 		txResults := bstorage.NewTransactionResults(metrics, db, bstorage.DefaultCacheSize)
+		txProfiles := bstorage.NewTransactionProfiles(metrics, db, bstorage.DefaultCacheSize)
 		commits := bstorage.NewCommits(metrics, db)
 		chunkDataPacks := bstorage.NewChunkDataPacks(metrics, db, bstorage.NewCollections(db, bstorage.NewTransactions(metrics, db)), bstorage.DefaultCacheSize)
 		results := bstorage.NewExecutionResults(metrics, db)
@@ -168,6 +175,7 @@ func TestReExecuteBlockWithDifferentResult(t *testing.T) {
 			events,
 			serviceEvents,
 			txResults,
+			txProfiles,
 			db,
 			trace.NewNoopTracer(),
 		)
@@ -187,6 +195,7 @@ func TestReExecuteBlockWithDifferentResult(t *testing.T) {
 		// se := unittest.ServiceEventsFixture(2)
 		se := unittest.BlockEventsFixture(header, 8)
 		tes := unittest.TransactionResultsFixture(4)
+		tps := unittest.TransactionProfilesFixture(tes)
 
 		err = headers.Store(&header)
 		require.NoError(t, err)
@@ -201,6 +210,7 @@ func TestReExecuteBlockWithDifferentResult(t *testing.T) {
 			[]flow.EventsList{blockEvents.Events},
 			se.Events,
 			tes,
+			tps,
 		)
 
 		require.NoError(t, err)
@@ -210,6 +220,7 @@ func TestReExecuteBlockWithDifferentResult(t *testing.T) {
 			headers,
 			commits,
 			txResults,
+			txProfiles,
 			results,
 			chunkDataPacks,
 			myReceipts,
@@ -239,6 +250,7 @@ func TestReExecuteBlockWithDifferentResult(t *testing.T) {
 			[]flow.EventsList{blockEvents.Events},
 			se.Events,
 			tes,
+			tps,
 		)
 
 		require.NoError(t, err)
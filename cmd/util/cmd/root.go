@@ -9,15 +9,19 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	check_wal "github.com/onflow/flow-go/cmd/util/cmd/check-wal"
 	checkpoint_list_tries "github.com/onflow/flow-go/cmd/util/cmd/checkpoint-list-tries"
+	diff_execution_state "github.com/onflow/flow-go/cmd/util/cmd/diff-execution-state"
 	epochs "github.com/onflow/flow-go/cmd/util/cmd/epochs/cmd"
 	export "github.com/onflow/flow-go/cmd/util/cmd/exec-data-json-export"
 	edbs "github.com/onflow/flow-go/cmd/util/cmd/execution-data-blobstore/cmd"
 	extract "github.com/onflow/flow-go/cmd/util/cmd/execution-state-extract"
+	export_checkpoint_payloads "github.com/onflow/flow-go/cmd/util/cmd/export-checkpoint-payloads"
 	ledger_json_exporter "github.com/onflow/flow-go/cmd/util/cmd/export-json-execution-state"
 	read_badger "github.com/onflow/flow-go/cmd/util/cmd/read-badger/cmd"
 	read_execution_state "github.com/onflow/flow-go/cmd/util/cmd/read-execution-state"
 	read_protocol_state "github.com/onflow/flow-go/cmd/util/cmd/read-protocol-state/cmd"
+	reexecute_block "github.com/onflow/flow-go/cmd/util/cmd/reexecute-block"
 	index_er "github.com/onflow/flow-go/cmd/util/cmd/reindex/cmd"
 	rollback_executed_height "github.com/onflow/flow-go/cmd/util/cmd/rollback-executed-height/cmd"
 	"github.com/onflow/flow-go/cmd/util/cmd/snapshot"
@@ -70,6 +74,10 @@ func addCommands() {
 	rootCmd.AddCommand(rollback_executed_height.Cmd)
 	rootCmd.AddCommand(read_execution_state.Cmd)
 	rootCmd.AddCommand(snapshot.Cmd)
+	rootCmd.AddCommand(diff_execution_state.Cmd)
+	rootCmd.AddCommand(check_wal.Cmd)
+	rootCmd.AddCommand(export_checkpoint_payloads.Cmd)
+	rootCmd.AddCommand(reexecute_block.Cmd)
 }
 
 func initConfig() {
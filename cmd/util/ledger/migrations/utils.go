@@ -13,18 +13,7 @@ import (
 )
 
 func KeyToRegisterID(key ledger.Key) (flow.RegisterID, error) {
-	if len(key.KeyParts) != 3 ||
-		key.KeyParts[0].Type != state.KeyPartOwner ||
-		key.KeyParts[1].Type != state.KeyPartController ||
-		key.KeyParts[2].Type != state.KeyPartKey {
-		return flow.RegisterID{}, fmt.Errorf("key not in expected format %s", key.String())
-	}
-
-	return flow.NewRegisterID(
-		string(key.KeyParts[0].Value),
-		string(key.KeyParts[1].Value),
-		string(key.KeyParts[2].Value),
-	), nil
+	return state.KeyToRegisterID(key)
 }
 
 func registerIDToKey(registerID flow.RegisterID) ledger.Key {
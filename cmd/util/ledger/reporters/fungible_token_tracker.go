@@ -79,20 +79,17 @@ type TokenDataPoint struct {
 
 type job struct {
 	owner    flow.Address
-	payloads []ledger.Payload
+	payloads func() ([]ledger.Payload, error)
 }
 
+var _ ledger.OwnerReporter = &FungibleTokenTracker{}
+
 // Report creates a fungible_token_report_*.json file that contains data on all fungible token Vaults in the state commitment.
 // I recommend using gojq to browse through the data, because of the large uint64 numbers which jq won't be able to handle.
 func (r *FungibleTokenTracker) Report(payloads []ledger.Payload) error {
-	r.rw = r.rwf.ReportWriter(FungibleTokenTrackerReportPrefix)
-	defer r.rw.Close()
-
-	wg := &sync.WaitGroup{}
-
 	// we need to shard by owner, otherwise ledger won't be thread-safe
-	addressCount := 0
 	payloadsByOwner := make(map[flow.Address][]ledger.Payload)
+	owners := make([]flow.Address, 0)
 
 	for _, pay := range payloads {
 		owner := flow.BytesToAddress(pay.Key.KeyParts[0].Value)
@@ -100,17 +97,45 @@ func (r *FungibleTokenTracker) Report(payloads []ledger.Payload) error {
 			m, ok := payloadsByOwner[owner]
 			if !ok {
 				payloadsByOwner[owner] = make([]ledger.Payload, 0)
-				addressCount++
+				owners = append(owners, owner)
 			}
 			payloadsByOwner[owner] = append(m, pay)
 		}
 	}
 
-	jobs := make(chan job, addressCount)
-	r.progress = progressbar.Default(int64(addressCount), "Processing:")
+	return r.report(owners, func(owner flow.Address) ([]ledger.Payload, error) {
+		return payloadsByOwner[owner], nil
+	})
+}
+
+// ReportOwners creates the same report as Report, reading the payloads of each account with ownerPayloads
+// instead of sharding all payloads by owner.
+func (r *FungibleTokenTracker) ReportOwners(owners [][]byte, ownerPayloads func(owner []byte) ([]ledger.Payload, error)) error {
+	addresses := make([]flow.Address, 0, len(owners))
+	for _, owner := range owners {
+		if len(owner) != flow.AddressLength { // ignoring payloads without ownership (fvm ones)
+			continue
+		}
+		addresses = append(addresses, flow.BytesToAddress(owner))
+	}
+
+	return r.report(addresses, func(owner flow.Address) ([]ledger.Payload, error) {
+		return ownerPayloads(owner.Bytes())
+	})
+}
+
+func (r *FungibleTokenTracker) report(owners []flow.Address, ownerPayloads func(owner flow.Address) ([]ledger.Payload, error)) error {
+	r.rw = r.rwf.ReportWriter(FungibleTokenTrackerReportPrefix)
+	defer r.rw.Close()
+
+	wg := &sync.WaitGroup{}
 
-	for k, v := range payloadsByOwner {
-		jobs <- job{k, v}
+	jobs := make(chan job, len(owners))
+	r.progress = progressbar.Default(int64(len(owners)), "Processing:")
+
+	for _, owner := range owners {
+		owner := owner
+		jobs <- job{owner, func() ([]ledger.Payload, error) { return ownerPayloads(owner) }}
 	}
 
 	close(jobs)
@@ -136,7 +161,12 @@ func (r *FungibleTokenTracker) worker(
 	wg *sync.WaitGroup) {
 	for j := range jobs {
 
-		view := migrations.NewView(j.payloads)
+		payloads, err := j.payloads()
+		if err != nil {
+			panic(err)
+		}
+
+		view := migrations.NewView(payloads)
 		st := state.NewState(view)
 		sth := state.NewStateHolder(st)
 		accounts := state.NewAccounts(sth)
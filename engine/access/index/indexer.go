@@ -0,0 +1,176 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/model"
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/engine/execution/history"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/state_synchronization"
+	"github.com/onflow/flow-go/state/protocol"
+	"github.com/onflow/flow-go/storage"
+)
+
+// Indexer indexes the registers of the execution state at each sealed height, using the trie updates from the
+// execution data of the sealed execution results. Heights are indexed in order, starting from the height at which
+// the registers were bootstrapped.
+type Indexer struct {
+	unit          *engine.Unit
+	log           zerolog.Logger
+	state         protocol.State
+	blocks        storage.Blocks
+	headers       storage.Headers
+	results       storage.ExecutionResults
+	registers     storage.Registers
+	executionData state_synchronization.ExecutionDataService
+	sealed        engine.Notifier // notified whenever new blocks may have been sealed
+}
+
+func NewIndexer(
+	log zerolog.Logger,
+	state protocol.State,
+	blocks storage.Blocks,
+	headers storage.Headers,
+	results storage.ExecutionResults,
+	registers storage.Registers,
+	executionData state_synchronization.ExecutionDataService,
+) *Indexer {
+	return &Indexer{
+		unit:          engine.NewUnit(),
+		log:           log.With().Str("engine", "register_indexer").Logger(),
+		state:         state,
+		blocks:        blocks,
+		headers:       headers,
+		results:       results,
+		registers:     registers,
+		executionData: executionData,
+		sealed:        engine.NewNotifier(),
+	}
+}
+
+// Ready returns a ready channel that is closed once the indexer has started. The registers must have been
+// bootstrapped beforehand.
+func (i *Indexer) Ready() <-chan struct{} {
+	i.unit.Launch(i.loop)
+	// index the heights sealed while the node was down
+	i.sealed.Notify()
+	return i.unit.Ready()
+}
+
+// Done returns a done channel that is closed once the indexer has stopped.
+func (i *Indexer) Done() <-chan struct{} {
+	return i.unit.Done()
+}
+
+// OnFinalizedBlock is called whenever a block is finalized. As the seals of the finalized block are now final,
+// the indexer catches up with the sealed height.
+func (i *Indexer) OnFinalizedBlock(*model.Block) {
+	i.sealed.Notify()
+}
+
+func (i *Indexer) loop() {
+	for {
+		select {
+		case <-i.unit.Quit():
+			return
+		case <-i.sealed.Channel():
+			err := i.indexSealedHeights(i.unit.Ctx())
+			if err != nil && !errors.Is(err, context.Canceled) {
+				// heights which could not be indexed are attempted again once the next block is finalized
+				i.log.Error().Err(err).Msg("could not index registers")
+			}
+		}
+	}
+}
+
+// indexSealedHeights indexes the registers at all sealed heights above the latest indexed height.
+func (i *Indexer) indexSealedHeights(ctx context.Context) error {
+	sealed, err := i.state.Sealed().Head()
+	if err != nil {
+		return fmt.Errorf("could not get sealed header: %w", err)
+	}
+
+	latest, err := i.registers.LatestHeight()
+	if err != nil {
+		return fmt.Errorf("could not get latest indexed height: %w", err)
+	}
+
+	for height := latest + 1; height <= sealed.Height; height++ {
+		err := i.indexHeight(ctx, height, sealed.Height)
+		if err != nil {
+			return fmt.Errorf("could not index height %d: %w", height, err)
+		}
+	}
+
+	return nil
+}
+
+// indexHeight indexes the registers updated by the sealed block at the given height.
+func (i *Indexer) indexHeight(ctx context.Context, height uint64, sealedHeight uint64) error {
+	header, err := i.headers.ByHeight(height)
+	if err != nil {
+		return fmt.Errorf("could not get header: %w", err)
+	}
+
+	seal, err := i.sealForBlock(header)
+	if err != nil {
+		return err
+	}
+
+	result, err := i.results.ByID(seal.ResultID)
+	if err != nil {
+		return fmt.Errorf("could not get sealed execution result %v: %w", seal.ResultID, err)
+	}
+
+	executionData, err := i.executionData.Get(ctx, result.ExecutionDataID)
+	if err != nil {
+		return fmt.Errorf("could not get execution data %v: %w", result.ExecutionDataID, err)
+	}
+
+	entries, err := history.RegisterEntries(executionData.TrieUpdates)
+	if err != nil {
+		return err
+	}
+
+	err = i.registers.Store(height, entries)
+	if err != nil {
+		return fmt.Errorf("could not store registers: %w", err)
+	}
+
+	i.log.Debug().
+		Uint64("height", height).
+		Uint64("sealed_height", sealedHeight).
+		Int("registers", len(entries)).
+		Msg("indexed registers")
+
+	return nil
+}
+
+// sealForBlock returns the seal for the given sealed block, which is included in one of the finalized blocks
+// following it.
+func (i *Indexer) sealForBlock(header *flow.Header) (*flow.Seal, error) {
+	final, err := i.state.Final().Head()
+	if err != nil {
+		return nil, fmt.Errorf("could not get finalized header: %w", err)
+	}
+
+	blockID := header.ID()
+	for height := header.Height + 1; height <= final.Height; height++ {
+		block, err := i.blocks.ByHeight(height)
+		if err != nil {
+			return nil, fmt.Errorf("could not get finalized block at height %d: %w", height, err)
+		}
+		for _, seal := range block.Payload.Seals {
+			if seal.BlockID == blockID {
+				return seal, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no seal found for block %v", blockID)
+}
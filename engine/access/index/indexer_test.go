@@ -0,0 +1,101 @@
+package index
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/state_synchronization"
+	syncmock "github.com/onflow/flow-go/module/state_synchronization/mock"
+	protocol "github.com/onflow/flow-go/state/protocol/mock"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	storagemock "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestIndexSealedHeights(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		registers, err := bstorage.NewRegisters(db)
+		require.NoError(t, err)
+
+		id := flow.NewRegisterID("owner", "", "key")
+		err = registers.Bootstrap(10, flow.RegisterEntries{{Key: id, Value: []byte("root")}})
+		require.NoError(t, err)
+
+		// blocks 11 and 12 are sealed by block 13
+		blocks := make(map[uint64]*flow.Block)
+		for height := uint64(11); height <= 13; height++ {
+			block := unittest.BlockFixture()
+			block.Header.Height = height
+			blocks[height] = &block
+		}
+
+		headers := new(storagemock.Headers)
+		blockStore := new(storagemock.Blocks)
+		results := new(storagemock.ExecutionResults)
+		executionData := new(syncmock.ExecutionDataService)
+
+		var seals []*flow.Seal
+		for height := uint64(11); height <= 12; height++ {
+			block := blocks[height]
+			result := unittest.ExecutionResultFixture(unittest.WithBlock(block))
+			seal := unittest.Seal.Fixture(unittest.Seal.WithResult(result))
+			seals = append(seals, seal)
+
+			// block 11 updates the register, block 12 leaves it untouched
+			var updates []*ledger.TrieUpdate
+			if height == 11 {
+				key := state.RegisterIDToKey(id)
+				updates = []*ledger.TrieUpdate{
+					{Payloads: []*ledger.Payload{ledger.NewPayload(key, []byte("first"))}},
+					{Payloads: []*ledger.Payload{ledger.NewPayload(key, []byte("second"))}},
+				}
+			}
+
+			headers.On("ByHeight", height).Return(block.Header, nil)
+			results.On("ByID", result.ID()).Return(result, nil)
+			executionData.On("Get", mock.Anything, result.ExecutionDataID).Return(
+				&state_synchronization.ExecutionData{BlockID: block.ID(), TrieUpdates: updates}, nil)
+		}
+		blocks[13].SetPayload(flow.Payload{Seals: seals})
+		for height, block := range blocks {
+			blockStore.On("ByHeight", height).Return(block, nil)
+		}
+
+		protoState := new(protocol.State)
+		sealed := new(protocol.Snapshot)
+		sealed.On("Head").Return(blocks[12].Header, nil)
+		final := new(protocol.Snapshot)
+		final.On("Head").Return(blocks[13].Header, nil)
+		protoState.On("Sealed").Return(sealed)
+		protoState.On("Final").Return(final)
+
+		indexer := NewIndexer(zerolog.Nop(), protoState, blockStore, headers, results, registers, executionData)
+		err = indexer.indexSealedHeights(context.Background())
+		require.NoError(t, err)
+
+		latest, err := registers.LatestHeight()
+		require.NoError(t, err)
+		require.Equal(t, uint64(12), latest)
+
+		// the last update of the register at a height determines its value
+		value, err := registers.Get(id, 10)
+		require.NoError(t, err)
+		require.Equal(t, []byte("root"), value)
+		value, err = registers.Get(id, 11)
+		require.NoError(t, err)
+		require.Equal(t, []byte("second"), value)
+		value, err = registers.Get(id, 12)
+		require.NoError(t, err)
+		require.Equal(t, []byte("second"), value)
+
+		executionData.AssertExpectations(t)
+	})
+}
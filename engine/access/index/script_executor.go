@@ -0,0 +1,119 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine/execution/history"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/programs"
+	"github.com/onflow/flow-go/storage"
+)
+
+// ErrHeightNotIndexed is returned when a script is executed at a height for which the registers are not indexed.
+var ErrHeightNotIndexed = errors.New("registers are not indexed at height")
+
+// ErrScriptFailed is returned when the script itself fails, as opposed to the script executor failing.
+var ErrScriptFailed = errors.New("script execution failed")
+
+// ScriptExecutor executes scripts against the execution state at an indexed height.
+type ScriptExecutor struct {
+	log       zerolog.Logger
+	vm        *fvm.VirtualMachine
+	vmCtx     fvm.Context
+	headers   storage.Headers
+	registers storage.Registers
+}
+
+func NewScriptExecutor(
+	log zerolog.Logger,
+	vm *fvm.VirtualMachine,
+	vmCtx fvm.Context,
+	headers storage.Headers,
+	registers storage.Registers,
+) *ScriptExecutor {
+	return &ScriptExecutor{
+		log:       log.With().Str("component", "script_executor").Logger(),
+		vm:        vm,
+		vmCtx:     vmCtx,
+		headers:   headers,
+		registers: registers,
+	}
+}
+
+// ExecuteAtBlockHeight executes the script against the execution state at the given height and returns the JSON-CDC
+// encoded value. It returns ErrHeightNotIndexed if the registers are not indexed at the height, and an error wrapping
+// ErrScriptFailed if the script failed.
+func (e *ScriptExecutor) ExecuteAtBlockHeight(
+	ctx context.Context,
+	script []byte,
+	arguments [][]byte,
+	height uint64,
+) ([]byte, error) {
+	err := e.checkIndexed(height)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := e.headers.ByHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not get header at height %d: %w", height, err)
+	}
+
+	view := history.NewView(e.registers, height)
+
+	scriptProc := fvm.NewScriptWithContextAndArgs(script, ctx, arguments...)
+	blockCtx := fvm.NewContextFromParent(e.vmCtx, fvm.WithBlockHeader(header))
+
+	err = func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				e.log.Error().
+					Hex("script_hex", script).
+					Interface("recovered", r).
+					Msg("script execution caused runtime panic")
+				err = fmt.Errorf("cadence runtime error: %s", r)
+			}
+		}()
+		return e.vm.Run(blockCtx, scriptProc, view, programs.NewEmptyPrograms())
+	}()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute script (internal error): %w", err)
+	}
+
+	if scriptProc.Err != nil {
+		return nil, fmt.Errorf("%w at height %d: %s", ErrScriptFailed, height, scriptProc.Err.Error())
+	}
+
+	encodedValue, err := jsoncdc.Encode(scriptProc.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode runtime value: %w", err)
+	}
+
+	return encodedValue, nil
+}
+
+// checkIndexed returns ErrHeightNotIndexed if the registers are not indexed at the given height.
+func (e *ScriptExecutor) checkIndexed(height uint64) error {
+	first, err := e.registers.FirstHeight()
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("%w %d", ErrHeightNotIndexed, height)
+	}
+	if err != nil {
+		return fmt.Errorf("could not get first indexed height: %w", err)
+	}
+
+	latest, err := e.registers.LatestHeight()
+	if err != nil {
+		return fmt.Errorf("could not get latest indexed height: %w", err)
+	}
+
+	if height < first || height > latest {
+		return fmt.Errorf("%w %d", ErrHeightNotIndexed, height)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/model/flow"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	storagemock "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestExecuteAtBlockHeight(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		registers, err := bstorage.NewRegisters(db)
+		require.NoError(t, err)
+
+		header := unittest.BlockHeaderFixture()
+		header.Height = 10
+		headers := new(storagemock.Headers)
+		headers.On("ByHeight", header.Height).Return(&header, nil)
+
+		vm := fvm.NewVirtualMachine(fvm.NewInterpreterRuntime())
+		vmCtx := fvm.NewContext(zerolog.Nop(), fvm.WithChain(flow.Testnet.Chain()))
+		executor := NewScriptExecutor(zerolog.Nop(), vm, vmCtx, headers, registers)
+
+		script := []byte(`pub fun main(a: Int): Int { return a + 1 }`)
+		arguments := [][]byte{[]byte(`{"type":"Int","value":"41"}`)}
+
+		// the registers are not bootstrapped yet
+		_, err = executor.ExecuteAtBlockHeight(context.Background(), script, arguments, header.Height)
+		require.True(t, errors.Is(err, ErrHeightNotIndexed))
+
+		err = registers.Bootstrap(header.Height, nil)
+		require.NoError(t, err)
+
+		value, err := executor.ExecuteAtBlockHeight(context.Background(), script, arguments, header.Height)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"type":"Int","value":"42"}`, string(value))
+
+		// heights above the latest indexed height are not indexed
+		_, err = executor.ExecuteAtBlockHeight(context.Background(), script, arguments, header.Height+1)
+		require.True(t, errors.Is(err, ErrHeightNotIndexed))
+
+		_, err = executor.ExecuteAtBlockHeight(context.Background(), []byte(`pub fun main() { panic("failed") }`), nil, header.Height)
+		require.True(t, errors.Is(err, ErrScriptFailed))
+	})
+}
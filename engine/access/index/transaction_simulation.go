@@ -0,0 +1,123 @@
+package index
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/execution/history"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/programs"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// SimulateAtBlockHeight runs the transaction against the execution state at the given height, without committing
+// the resulting state changes. Transaction signatures are not verified, so the fee of a transaction can be estimated
+// before it is signed. It returns ErrHeightNotIndexed if the registers are not indexed at the height.
+//
+// A failing transaction is not an error of the simulation, its fvm error is reported in the result.
+func (e *ScriptExecutor) SimulateAtBlockHeight(
+	ctx context.Context,
+	tx *flow.TransactionBody,
+	height uint64,
+) (*access.TransactionSimulationResult, error) {
+	err := e.checkIndexed(height)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := e.headers.ByHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not get header at height %d: %w", height, err)
+	}
+
+	view := history.NewView(e.registers, height)
+
+	txProc := fvm.Transaction(tx, 0)
+	blockCtx := fvm.NewContextFromParent(
+		e.vmCtx,
+		fvm.WithBlockHeader(header),
+		fvm.WithTransactionProcessors(
+			fvm.NewTransactionAccountFrozenChecker(),
+			fvm.NewTransactionSequenceNumberChecker(),
+			fvm.NewTransactionAccountFrozenEnabler(),
+			fvm.NewTransactionInvoker(e.log),
+		),
+	)
+
+	err = func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				e.log.Error().
+					Hex("tx_id", txProc.ID[:]).
+					Interface("recovered", r).
+					Msg("transaction simulation caused runtime panic")
+				err = fmt.Errorf("cadence runtime error: %s", r)
+			}
+		}()
+		return e.vm.Run(blockCtx, txProc, view, programs.NewEmptyPrograms())
+	}()
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate transaction (internal error): %w", err)
+	}
+
+	fee, err := transactionFee(e.vmCtx.Chain, txProc.Events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction fee: %w", err)
+	}
+
+	ids, values := view.Delta().RegisterUpdates()
+	updates := make([]flow.RegisterEntry, len(ids))
+	for i, id := range ids {
+		updates[i] = flow.RegisterEntry{Key: id, Value: values[i]}
+	}
+
+	result := &access.TransactionSimulationResult{
+		BlockID:         header.ID(),
+		BlockHeight:     height,
+		ComputationUsed: txProc.ComputationUsed,
+		MemoryUsed:      txProc.MemoryUsed,
+		Events:          txProc.Events,
+		RegisterUpdates: updates,
+		Fee:             fee,
+	}
+	if txProc.Err != nil {
+		result.ErrorCode = uint(txProc.Err.Code())
+		result.ErrorMessage = txProc.Err.Error()
+	}
+
+	return result, nil
+}
+
+// transactionFee returns the fee deducted by the FlowFees contract, as reported by its FeesDeducted event.
+// The fee is 0 if no fees were deducted.
+func transactionFee(chain flow.Chain, events []flow.Event) (uint64, error) {
+	feesDeducted := flow.EventType(fmt.Sprintf("A.%s.FlowFees.FeesDeducted", fvm.FlowFeesAddress(chain).Hex()))
+
+	for _, event := range events {
+		if event.Type != feesDeducted {
+			continue
+		}
+
+		value, err := jsoncdc.Decode(nil, event.Payload)
+		if err != nil {
+			return 0, fmt.Errorf("could not decode fees deducted event: %w", err)
+		}
+
+		// the fee is the amount field of FlowFees.FeesDeducted(amount: UFix64, inclusionEffort: UFix64, executionEffort: UFix64)
+		cadenceEvent, ok := value.(cadence.Event)
+		if !ok || len(cadenceEvent.Fields) == 0 {
+			return 0, fmt.Errorf("unexpected fees deducted event value %s", value)
+		}
+		amount, ok := cadenceEvent.Fields[0].(cadence.UFix64)
+		if !ok {
+			return 0, fmt.Errorf("unexpected fee amount type %T", cadenceEvent.Fields[0])
+		}
+		return uint64(amount), nil
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,98 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/model/flow"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	storagemock "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestSimulateAtBlockHeight(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		registers, err := bstorage.NewRegisters(db)
+		require.NoError(t, err)
+
+		header := unittest.BlockHeaderFixture()
+		header.Height = 10
+		headers := new(storagemock.Headers)
+		headers.On("ByHeight", header.Height).Return(&header, nil)
+
+		chain := flow.Testnet.Chain()
+		vm := fvm.NewVirtualMachine(fvm.NewInterpreterRuntime())
+		vmCtx := fvm.NewContext(zerolog.Nop(), fvm.WithChain(chain))
+		executor := NewScriptExecutor(zerolog.Nop(), vm, vmCtx, headers, registers)
+
+		tx := flow.NewTransactionBody().
+			SetScript([]byte(`transaction { prepare(signer: AuthAccount) {} }`)).
+			SetProposalKey(chain.ServiceAddress(), 0, 0).
+			SetPayer(chain.ServiceAddress()).
+			AddAuthorizer(chain.ServiceAddress())
+
+		// the registers are not bootstrapped yet
+		_, err = executor.SimulateAtBlockHeight(context.Background(), tx, header.Height)
+		require.True(t, errors.Is(err, ErrHeightNotIndexed))
+
+		err = registers.Bootstrap(header.Height, nil)
+		require.NoError(t, err)
+
+		// the service account does not exist in the empty execution state, which fails the transaction but not the
+		// simulation
+		result, err := executor.SimulateAtBlockHeight(context.Background(), tx, header.Height)
+		require.NoError(t, err)
+		require.Equal(t, header.ID(), result.BlockID)
+		require.Equal(t, header.Height, result.BlockHeight)
+		require.NotZero(t, result.ErrorCode)
+		require.NotEmpty(t, result.ErrorMessage)
+		require.Zero(t, result.Fee)
+	})
+}
+
+func TestTransactionFee(t *testing.T) {
+	chain := flow.Testnet.Chain()
+	feesAddress := fvm.FlowFeesAddress(chain)
+
+	eventType := &cadence.EventType{
+		Location: common.AddressLocation{
+			Address: common.Address(feesAddress),
+			Name:    "FlowFees",
+		},
+		QualifiedIdentifier: "FlowFees.FeesDeducted",
+		Fields: []cadence.Field{
+			{Identifier: "amount", Type: cadence.UFix64Type{}},
+			{Identifier: "inclusionEffort", Type: cadence.UFix64Type{}},
+			{Identifier: "executionEffort", Type: cadence.UFix64Type{}},
+		},
+	}
+	payload, err := jsoncdc.Encode(cadence.NewEvent([]cadence.Value{
+		cadence.UFix64(1_000),
+		cadence.UFix64(100_000_000),
+		cadence.UFix64(12),
+	}).WithType(eventType))
+	require.NoError(t, err)
+
+	events := []flow.Event{
+		{Type: flow.EventAccountCreated},
+		{Type: flow.EventType("A." + feesAddress.Hex() + ".FlowFees.FeesDeducted"), Payload: payload},
+	}
+
+	fee, err := transactionFee(chain, events)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1_000), fee)
+
+	// no fees are deducted if fees are disabled
+	fee, err = transactionFee(chain, events[:1])
+	require.NoError(t, err)
+	require.Zero(t, fee)
+}
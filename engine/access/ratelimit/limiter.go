@@ -0,0 +1,150 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	"golang.org/x/time/rate"
+
+	"github.com/onflow/flow-go/module"
+)
+
+// DefaultMaxClients is the default number of clients whose rate limiters are retained
+const DefaultMaxClients = 10_000
+
+// Config defines the per-client rate limits of the Access API methods. Methods are identified by their gRPC method
+// name (e.g. ExecuteScriptAtLatestBlock) or by their REST route name (e.g. executeScript).
+type Config struct {
+	MethodLimits map[string]int // calls per second allowed for each client for the given methods
+	MethodBursts map[string]int // calls allowed at once for each client for the given methods, defaults to the method limit
+	DefaultLimit int            // calls per second allowed for each client for the other methods, unlimited if 0
+	DefaultBurst int            // calls allowed at once for each client for the other methods, defaults to the default limit
+	// APIKeyHeader is the request header (or gRPC metadata key) identifying the client. Clients which do not set it are
+	// identified by their IP address. Since clients can choose any key, the header should only be configured if it is
+	// set by a trusted proxy in front of the node.
+	APIKeyHeader string
+	MaxClients   uint // number of clients whose rate limiters are retained, the least recently seen clients are reset
+}
+
+// Enabled returns true if any per-client rate limit is configured.
+func (c Config) Enabled() bool {
+	return c.DefaultLimit > 0 || len(c.MethodLimits) > 0
+}
+
+// ClientLimiter rate limits the calls to each Access API method separately for each client, using a token bucket
+// per client and method. The token buckets of the least recently seen clients are dropped once more than MaxClients
+// clients have been seen, whatever the number of methods they called.
+type ClientLimiter struct {
+	config   Config
+	limiters *lru.Cache // client to *clientLimiters
+	metrics  module.AccessMetrics
+}
+
+// clientLimiters holds the rate limiters of a client, one per method the client called.
+type clientLimiters struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func newClientLimiters() *clientLimiters {
+	return &clientLimiters{
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// limiter returns the rate limiter of the method, creating it with the given limit and burst if it doesn't exist.
+func (c *clientLimiters) limiter(method string, limit int, burst int) *rate.Limiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	limiter, ok := c.limiters[method]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(limit), burst)
+		c.limiters[method] = limiter
+	}
+	return limiter
+}
+
+// NewClientLimiter creates a new client limiter enforcing the limits of the given config.
+func NewClientLimiter(config Config, metrics module.AccessMetrics) (*ClientLimiter, error) {
+	if config.MaxClients == 0 {
+		config.MaxClients = DefaultMaxClients
+	}
+
+	limiters, err := lru.New(int(config.MaxClients))
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize rate limiters cache: %w", err)
+	}
+
+	return &ClientLimiter{
+		config:   config,
+		limiters: limiters,
+		metrics:  metrics,
+	}, nil
+}
+
+// APIKeyHeader returns the request header identifying the client, if configured.
+func (l *ClientLimiter) APIKeyHeader() string {
+	return l.config.APIKeyHeader
+}
+
+// Allow returns true if the client is within its rate limit for the method. Calls over the limit are recorded as
+// throttled.
+func (l *ClientLimiter) Allow(client string, method string) bool {
+	limit, burst := l.limits(method)
+	if limit == 0 {
+		return true
+	}
+
+	var limiters *clientLimiters
+	if cached, ok := l.limiters.Get(client); ok {
+		limiters = cached.(*clientLimiters)
+	} else {
+		limiters = newClientLimiters()
+		// another request of the client may have added its limiters in the meantime
+		if previous, ok, _ := l.limiters.PeekOrAdd(client, limiters); ok {
+			limiters = previous.(*clientLimiters)
+		}
+	}
+
+	if limiters.limiter(method, limit, burst).Allow() {
+		return true
+	}
+
+	l.metrics.RequestThrottled(method)
+	return false
+}
+
+// limits returns the rate limit and burst of the method, the limit is 0 if the method is not rate limited.
+func (l *ClientLimiter) limits(method string) (int, int) {
+	limit, ok := l.config.MethodLimits[method]
+	if ok {
+		burst, ok := l.config.MethodBursts[method]
+		if !ok {
+			burst = limit
+		}
+		return limit, burst
+	}
+
+	burst := l.config.DefaultBurst
+	if burst == 0 {
+		burst = l.config.DefaultLimit
+	}
+	return l.config.DefaultLimit, burst
+}
+
+// ClientID identifies a client by its API key if it is set, and by the host of its address otherwise.
+func ClientID(apiKey string, address string) string {
+	if apiKey != "" {
+		return "key:" + apiKey
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		// the address has no port
+		host = address
+	}
+	return "ip:" + host
+}
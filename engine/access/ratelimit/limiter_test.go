@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/module/metrics"
+)
+
+func TestClientLimiter(t *testing.T) {
+	limiter, err := NewClientLimiter(Config{
+		MethodLimits: map[string]int{"ExecuteScriptAtLatestBlock": 1},
+		MethodBursts: map[string]int{"ExecuteScriptAtLatestBlock": 2},
+		DefaultLimit: 1,
+	}, metrics.NewNoopCollector())
+	require.NoError(t, err)
+
+	t.Run("method budget", func(t *testing.T) {
+		assert.True(t, limiter.Allow("client1", "ExecuteScriptAtLatestBlock"))
+		assert.True(t, limiter.Allow("client1", "ExecuteScriptAtLatestBlock"))
+		assert.False(t, limiter.Allow("client1", "ExecuteScriptAtLatestBlock"))
+	})
+
+	t.Run("clients are limited separately", func(t *testing.T) {
+		assert.True(t, limiter.Allow("client2", "ExecuteScriptAtLatestBlock"))
+	})
+
+	t.Run("methods are limited separately", func(t *testing.T) {
+		assert.True(t, limiter.Allow("client1", "GetEvents"))
+		assert.False(t, limiter.Allow("client1", "GetEvents"))
+		assert.True(t, limiter.Allow("client1", "Ping"))
+	})
+}
+
+func TestClientLimiter_MaxClients(t *testing.T) {
+	limiter, err := NewClientLimiter(Config{
+		DefaultLimit: 1,
+		MaxClients:   2,
+	}, metrics.NewNoopCollector())
+	require.NoError(t, err)
+
+	// the limits of a client calling several methods count as a single client
+	for _, method := range []string{"Ping", "GetEvents", "ExecuteScriptAtLatestBlock"} {
+		assert.True(t, limiter.Allow("client1", method))
+	}
+	assert.True(t, limiter.Allow("client2", "Ping"))
+	for _, method := range []string{"Ping", "GetEvents", "ExecuteScriptAtLatestBlock"} {
+		assert.False(t, limiter.Allow("client1", method))
+	}
+
+	// the least recently seen client is reset
+	assert.True(t, limiter.Allow("client3", "Ping"))
+	assert.True(t, limiter.Allow("client2", "Ping"))
+}
+
+func TestClientLimiter_NoDefaultLimit(t *testing.T) {
+	limiter, err := NewClientLimiter(Config{
+		MethodLimits: map[string]int{"executeScript": 1},
+	}, metrics.NewNoopCollector())
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		assert.True(t, limiter.Allow("client", "getEvents"))
+	}
+	assert.True(t, limiter.Allow("client", "executeScript"))
+	assert.False(t, limiter.Allow("client", "executeScript"))
+}
+
+func TestClientID(t *testing.T) {
+	assert.Equal(t, "key:secret", ClientID("secret", "10.0.0.1:3569"))
+	assert.Equal(t, "ip:10.0.0.1", ClientID("", "10.0.0.1:3569"))
+	assert.Equal(t, "ip:::1", ClientID("", "[::1]:3569"))
+	assert.Equal(t, "ip:10.0.0.1", ClientID("", "10.0.0.1"))
+}
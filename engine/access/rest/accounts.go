@@ -31,3 +31,30 @@ func GetAccount(r *request.Request, backend access.API, link models.LinkGenerato
 	err = response.Build(account, link, r.ExpandFields)
 	return response, err
 }
+
+// GetAccounts handler retrieves a batch of accounts by address and returns the response, which reports the accounts
+// that could not be retrieved individually
+func GetAccounts(r *request.Request, backend access.API, link models.LinkGenerator) (interface{}, error) {
+	req, err := r.GetAccountsRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	// in case we receive special height values 'final' and 'sealed', fetch that height and overwrite request with it
+	if req.Height == request.FinalHeight || req.Height == request.SealedHeight {
+		header, err := backend.GetLatestBlockHeader(r.Context(), req.Height == request.SealedHeight)
+		if err != nil {
+			return nil, err
+		}
+		req.Height = header.Height
+	}
+
+	results, err := backend.GetAccountsAtBlockHeight(r.Context(), req.Addresses, req.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.AccountResults
+	err = response.Build(results, link, r.ExpandFields)
+	return response, err
+}
@@ -10,7 +10,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	mocktestify "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/access/mock"
 	"github.com/onflow/flow-go/engine/access/rest/middleware"
 	"github.com/onflow/flow-go/model/flow"
@@ -127,6 +130,99 @@ func TestGetAccount(t *testing.T) {
 	})
 }
 
+func accountsURL(t *testing.T, addresses []string, height string) string {
+	u, err := url.ParseRequestURI("/v1/accounts")
+	require.NoError(t, err)
+	q := u.Query()
+
+	if len(addresses) > 0 {
+		q.Add("addresses", strings.Join(addresses, ","))
+	}
+	if height != "" {
+		q.Add("block_height", height)
+	}
+
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func TestGetAccounts(t *testing.T) {
+	backend := &mock.API{}
+
+	t.Run("get by addresses at latest sealed block", func(t *testing.T) {
+		var height uint64 = 100
+		block := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(height))
+		account := accountFixture(t)
+		missing := unittest.RandomAddressFixture()
+
+		backend.Mock.
+			On("GetLatestBlockHeader", mocktestify.Anything, true).
+			Return(&block, nil).
+			Once()
+
+		backend.Mock.
+			On("GetAccountsAtBlockHeight", mocktestify.Anything, []flow.Address{account.Address, missing}, height).
+			Return([]access.AccountResult{
+				{Address: account.Address, Account: account},
+				{Address: missing, Err: status.Error(codes.NotFound, "account not found")},
+			}, nil).
+			Once()
+
+		req, _ := http.NewRequest("GET", accountsURL(t, []string{account.Address.String(), missing.String()}, ""), nil)
+
+		expected := fmt.Sprintf(`[
+			{
+				"address": "%s",
+				"account": %s
+			},
+			{
+				"address": "%s",
+				"error": {"code": 404, "message": "account not found"}
+			}
+		]`, account.Address, expectedCondensedResponse(account), missing)
+
+		assertOKResponse(t, req, expected, backend)
+		mocktestify.AssertExpectationsForObjects(t, backend)
+	})
+
+	t.Run("get by addresses at height", func(t *testing.T) {
+		var height uint64 = 1337
+		account := accountFixture(t)
+
+		backend.Mock.
+			On("GetAccountsAtBlockHeight", mocktestify.Anything, []flow.Address{account.Address}, height).
+			Return([]access.AccountResult{{Address: account.Address, Account: account}}, nil).
+			Once()
+
+		req, _ := http.NewRequest("GET", accountsURL(t, []string{account.Address.String()}, fmt.Sprintf("%d", height)), nil)
+
+		expected := fmt.Sprintf(`[{"address": "%s", "account": %s}]`, account.Address, expectedCondensedResponse(account))
+
+		assertOKResponse(t, req, expected, backend)
+		mocktestify.AssertExpectationsForObjects(t, backend)
+	})
+
+	t.Run("get invalid", func(t *testing.T) {
+		tests := []struct {
+			url string
+			out string
+		}{
+			{accountsURL(t, nil, ""), `{"code":400, "message":"no addresses provided"}`},
+			{accountsURL(t, []string{"123"}, ""), `{"code":400, "message":"invalid address"}`},
+			{accountsURL(t, []string{unittest.AddressFixture().String()}, "foo"), `{"code":400, "message":"invalid height format"}`},
+		}
+
+		for i, test := range tests {
+			req, _ := http.NewRequest("GET", test.url, nil)
+			rr, err := executeRequest(req, backend)
+			assert.NoError(t, err)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			assert.JSONEq(t, test.out, rr.Body.String(), fmt.Sprintf("test #%d failed: %v", i, test))
+		}
+	})
+}
+
 func expectedExpandedResponse(account *flow.Account) string {
 	return fmt.Sprintf(`{
 			  "address":"%s",
@@ -7,22 +7,35 @@ import (
 	"github.com/onflow/flow-go/engine/access/rest/request"
 
 	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/model/flow"
 )
 
 const blockQueryParam = "block_ids"
 const eventTypeQuery = "type"
 
-// GetEvents for the provided block range or list of block IDs filtered by type.
+// GetEvents for the provided block range or list of block IDs filtered by types and field values.
 func GetEvents(r *request.Request, backend access.API, _ models.LinkGenerator) (interface{}, error) {
 	req, err := r.GetEventsRequest()
 	if err != nil {
 		return nil, NewBadRequestError(err)
 	}
 
+	// a single type without field filters is served by the plain per-type query
+	query := access.EventQuery{
+		EventTypes:   req.Types,
+		FieldFilters: req.FieldFilters,
+	}
+	simple := len(req.Types) == 1 && len(req.FieldFilters) == 0
+
 	// if the request has block IDs provided then return events for block IDs
 	var blocksEvents models.BlocksEvents
 	if len(req.BlockIDs) > 0 {
-		events, err := backend.GetEventsForBlockIDs(r.Context(), req.Type, req.BlockIDs)
+		var events []flow.BlockEvents
+		if simple {
+			events, err = backend.GetEventsForBlockIDs(r.Context(), string(req.Types[0]), req.BlockIDs)
+		} else {
+			events, err = backend.QueryEventsForBlockIDs(r.Context(), query, req.BlockIDs)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -46,7 +59,12 @@ func GetEvents(r *request.Request, backend access.API, _ models.LinkGenerator) (
 	}
 
 	// if request provided block height range then return events for that range
-	events, err := backend.GetEventsForHeightRange(r.Context(), req.Type, req.StartHeight, req.EndHeight)
+	var events []flow.BlockEvents
+	if simple {
+		events, err = backend.GetEventsForHeightRange(r.Context(), string(req.Types[0]), req.StartHeight, req.EndHeight)
+	} else {
+		events, err = backend.QueryEventsForHeightRange(r.Context(), query, req.StartHeight, req.EndHeight)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/onflow/flow-go/engine/access/rest/util"
 
+	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/access/mock"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/utils/unittest"
@@ -116,6 +117,63 @@ func TestGetEvents(t *testing.T) {
 
 }
 
+func TestGetEventsQuery(t *testing.T) {
+	backend := &mock.API{}
+
+	header := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(5))
+	events := []flow.BlockEvents{unittest.BlockEventsFixture(header, 2)}
+
+	types := []string{"flow.AccountCreated", "A.179b6b1cb6755e31.Foo.Bar"}
+	query := access.EventQuery{
+		EventTypes: []flow.EventType{"flow.AccountCreated", "A.179b6b1cb6755e31.Foo.Bar"},
+		FieldFilters: []access.EventFieldFilter{
+			{Field: "address", Value: "0x179b6b1cb6755e31"},
+			{Field: "tags", Value: "a,b"},
+		},
+	}
+
+	backend.Mock.
+		On("QueryEventsForHeightRange", mocks.Anything, query, uint64(5), uint64(5)).
+		Return(events, nil)
+	backend.Mock.
+		On("QueryEventsForBlockIDs", mocks.Anything, query, []flow.Identifier{header.ID()}).
+		Return(events, nil)
+
+	t.Run("Get events of multiple types with field filters for height range", func(t *testing.T) {
+		req := getEventQueryReq(t, types, []string{"address:0x179b6b1cb6755e31", "tags:a,b"}, "5", "5", nil)
+		assertOKResponse(t, req, testBlockEventResponse(events), backend)
+	})
+
+	t.Run("Get events of multiple types with field filters for block IDs", func(t *testing.T) {
+		req := getEventQueryReq(t, types, []string{"address:0x179b6b1cb6755e31", "tags:a,b"}, "", "", []string{header.ID().String()})
+		assertOKResponse(t, req, testBlockEventResponse(events), backend)
+	})
+
+	t.Run("Get invalid - malformed field filter", func(t *testing.T) {
+		req := getEventQueryReq(t, types, []string{"address"}, "5", "5", nil)
+		assertResponse(t, req, http.StatusBadRequest, `{"code":400,"message":"invalid field filter format, must be field:value"}`, backend)
+	})
+
+	t.Run("Get invalid - invalid event type in list", func(t *testing.T) {
+		req := getEventQueryReq(t, []string{"flow.AccountCreated", "foo"}, nil, "5", "5", nil)
+		assertResponse(t, req, http.StatusBadRequest, `{"code":400,"message":"invalid event type format"}`, backend)
+	})
+}
+
+func getEventQueryReq(t *testing.T, eventTypes []string, fieldFilters []string, start string, end string, blockIDs []string) *http.Request {
+	req := getEventReq(t, strings.Join(eventTypes, ","), start, end, blockIDs)
+
+	if len(fieldFilters) > 0 {
+		q := req.URL.Query()
+		for _, filter := range fieldFilters {
+			q.Add("field_filters", filter)
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+
+	return req
+}
+
 func getEventReq(t *testing.T, eventType string, start string, end string, blockIDs []string) *http.Request {
 	u, _ := url.Parse("/v1/events")
 	q := u.Query()
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 
 	"github.com/onflow/flow-go/engine/access/rest/models"
@@ -29,6 +30,13 @@ type ApiHandlerFunc func(
 	generator models.LinkGenerator,
 ) (interface{}, error)
 
+// attachment is a response of an ApiHandlerFunc which clients should save to a file
+// with the given name, rather than display.
+type attachment struct {
+	filename string
+	content  interface{}
+}
+
 // Handler is custom http handler implementing custom handler function.
 // Handler function allows easier handling of errors and responses as it
 // wraps functionality for handling error and responses outside of endpoint handling.
@@ -80,6 +88,12 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var filename string
+	if a, ok := response.(attachment); ok {
+		filename = a.filename
+		response = a.content
+	}
+
 	// apply the select filter if any select fields have been specified
 	response, err = util.SelectFilter(response, decoratedRequest.Selects())
 	if err != nil {
@@ -87,6 +101,10 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if filename != "" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+
 	// write response to response stream
 	h.jsonResponse(w, http.StatusOK, response, errLog)
 }
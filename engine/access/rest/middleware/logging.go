@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"bufio"
+	"fmt"
+	"net"
 	"net/http"
 	"time"
 
@@ -20,7 +23,7 @@ func LoggingMiddleware(logger zerolog.Logger) mux.MiddlewareFunc {
 			// continue to the next handler
 			inner.ServeHTTP(respWriter, req)
 			log := logger.Info()
-			if respWriter.statusCode != http.StatusOK {
+			if respWriter.statusCode != http.StatusOK && respWriter.statusCode != http.StatusSwitchingProtocols {
 				log = logger.Error()
 			}
 			log.Str("method", req.Method).
@@ -48,3 +51,13 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Hijack lets the handler take over the connection, e.g. to upgrade it to a websocket connection
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking the connection")
+	}
+	rw.statusCode = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/onflow/flow-go/engine/access/ratelimit"
+	"github.com/onflow/flow-go/engine/access/rest/models"
+)
+
+// RateLimit creates a middleware which rejects the requests of clients exceeding their rate limit for the route with
+// the status code 429. Clients are identified by the configured API key header, or by their IP address.
+func RateLimit(limiter *ratelimit.ClientLimiter) mux.MiddlewareFunc {
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			// the routes are named after the API method they serve
+			method := mux.CurrentRoute(req).GetName()
+
+			var apiKey string
+			if header := limiter.APIKeyHeader(); header != "" {
+				apiKey = req.Header.Get(header)
+			}
+
+			if !limiter.Allow(ratelimit.ClientID(apiKey, req.RemoteAddr), method) {
+				w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+				w.WriteHeader(http.StatusTooManyRequests)
+				_ = json.NewEncoder(w).Encode(models.ModelError{
+					Code:    http.StatusTooManyRequests,
+					Message: fmt.Sprintf("%s rate limit reached, please retry later", method),
+				})
+				return
+			}
+
+			inner.ServeHTTP(w, req)
+		})
+	}
+}
@@ -1,6 +1,12 @@
 package models
 
 import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/engine/access/rest/util"
 	"github.com/onflow/flow-go/model/flow"
 )
@@ -66,3 +72,55 @@ func (a *AccountPublicKeys) Build(accountKeys []flow.AccountPublicKey) {
 
 	*a = keys
 }
+
+func (a *AccountResult) Build(result access.AccountResult, link LinkGenerator, expand map[string]bool) error {
+	a.Address = result.Address.String()
+
+	if result.Err != nil {
+		var modelError ModelError
+		modelError.Build(result.Err)
+		a.Error = &modelError
+		return nil
+	}
+
+	var account Account
+	err := account.Build(result.Account, link, expand)
+	if err != nil {
+		return err
+	}
+	a.Account = &account
+
+	return nil
+}
+
+type AccountResults []AccountResult
+
+func (a *AccountResults) Build(results []access.AccountResult, link LinkGenerator, expand map[string]bool) error {
+	accountResults := make([]AccountResult, len(results))
+	for i, r := range results {
+		var result AccountResult
+		err := result.Build(r, link, expand)
+		if err != nil {
+			return err
+		}
+		accountResults[i] = result
+	}
+
+	*a = accountResults
+	return nil
+}
+
+// Build builds the error model of an error that only affects part of a response, using the HTTP status code the
+// error would be returned with as the response of a single resource request.
+func (m *ModelError) Build(err error) {
+	errStatus := status.Convert(err)
+	switch errStatus.Code() {
+	case codes.NotFound:
+		m.Code = http.StatusNotFound
+	case codes.InvalidArgument:
+		m.Code = http.StatusBadRequest
+	default:
+		m.Code = http.StatusInternalServerError
+	}
+	m.Message = errStatus.Message()
+}
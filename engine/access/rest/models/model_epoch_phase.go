@@ -0,0 +1,19 @@
+/*
+ * Access API
+ *
+ * No description provided (generated by Swagger Codegen https://github.com/swagger-api/swagger-codegen)
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package models
+
+// EpochPhase : This value indicates the phase of the current epoch.
+type EpochPhase string
+
+// List of EpochPhase
+const (
+	STAKING_EpochPhase   EpochPhase = "Staking"
+	SETUP_EpochPhase     EpochPhase = "Setup"
+	COMMITTED_EpochPhase EpochPhase = "Committed"
+)
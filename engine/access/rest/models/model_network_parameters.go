@@ -0,0 +1,16 @@
+/*
+ * Access API
+ *
+ * No description provided (generated by Swagger Codegen https://github.com/swagger-api/swagger-codegen)
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package models
+
+type NetworkParameters struct {
+	ChainId              string      `json:"chain_id"`
+	SporkRootBlockHeight string      `json:"spork_root_block_height"`
+	EpochCounter         string      `json:"epoch_counter"`
+	EpochPhase           *EpochPhase `json:"epoch_phase"`
+}
@@ -0,0 +1,16 @@
+/*
+ * Access API
+ *
+ * No description provided (generated by Swagger Codegen https://github.com/swagger-api/swagger-codegen)
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package models
+
+type ProfiledKind struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Intensity string `json:"intensity"`
+	Used      string `json:"used"`
+}
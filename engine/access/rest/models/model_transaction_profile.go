@@ -0,0 +1,22 @@
+/*
+ * Access API
+ *
+ * No description provided (generated by Swagger Codegen https://github.com/swagger-api/swagger-codegen)
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package models
+
+type TransactionProfile struct {
+	BlockId         string `json:"block_id"`
+	TransactionId   string `json:"transaction_id"`
+	ComputationUsed string `json:"computation_used"`
+	MemoryUsed      string `json:"memory_used"`
+	// Computation kinds which contributed the most to the computation used, in descending order.
+	ComputationKinds []ProfiledKind `json:"computation_kinds"`
+	// Memory kinds which contributed the most to the memory used, in descending order.
+	MemoryKinds          []ProfiledKind `json:"memory_kinds"`
+	RegisterBytesRead    string         `json:"register_bytes_read"`
+	RegisterBytesWritten string         `json:"register_bytes_written"`
+}
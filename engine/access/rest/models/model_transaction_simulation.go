@@ -0,0 +1,23 @@
+/*
+ * Access API
+ *
+ * No description provided (generated by Swagger Codegen https://github.com/swagger-api/swagger-codegen)
+ *
+ * API version: 1.0.0
+ * Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ */
+package models
+
+type TransactionSimulation struct {
+	BlockId         string `json:"block_id"`
+	BlockHeight     string `json:"block_height"`
+	ComputationUsed string `json:"computation_used"`
+	MemoryUsed      string `json:"memory_used"`
+	// Fee that would be charged for the transaction, in UFix64 units.
+	Fee       string `json:"fee"`
+	ErrorCode int32  `json:"error_code"`
+	// Provided transaction error in case the transaction wasn't successful.
+	ErrorMessage    string          `json:"error_message"`
+	Events          []Event         `json:"events"`
+	RegisterUpdates []RegisterEntry `json:"register_updates"`
+}
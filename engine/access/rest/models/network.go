@@ -0,0 +1,43 @@
+package models
+
+import (
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/rest/util"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+func (n *NetworkParameters) Build(
+	params access.NetworkParameters,
+	versionInfo *access.NodeVersionInfo,
+	epochInfo *access.EpochInfo,
+) {
+	var phase EpochPhase
+	phase.Build(epochInfo.Phase)
+
+	n.ChainId = params.ChainID.String()
+	n.SporkRootBlockHeight = util.FromUint64(versionInfo.SporkRootBlockHeight)
+	n.EpochCounter = util.FromUint64(epochInfo.Counter)
+	n.EpochPhase = &phase
+}
+
+func (p *EpochPhase) Build(phase flow.EpochPhase) {
+	switch phase {
+	case flow.EpochPhaseStaking:
+		*p = STAKING_EpochPhase
+	case flow.EpochPhaseSetup:
+		*p = SETUP_EpochPhase
+	case flow.EpochPhaseCommitted:
+		*p = COMMITTED_EpochPhase
+	default:
+		*p = ""
+	}
+}
+
+func (n *NodeVersionInfo) Build(versionInfo *access.NodeVersionInfo, sealed *flow.Header, finalized *flow.Header) {
+	n.Semver = versionInfo.Semver
+	n.Commit = versionInfo.Commit
+	n.SporkId = versionInfo.SporkID.String()
+	n.ProtocolVersion = util.FromUint64(uint64(versionInfo.ProtocolVersion))
+	n.SealedHeight = util.FromUint64(sealed.Height)
+	n.FinalizedHeight = util.FromUint64(finalized.Height)
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/hex"
+
 	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/engine/access/rest/util"
 	"github.com/onflow/flow-go/model/flow"
@@ -145,3 +147,56 @@ func (p *ProposalKey) Build(key flow.ProposalKey) {
 	p.KeyIndex = util.FromUint64(key.KeyIndex)
 	p.SequenceNumber = util.FromUint64(key.SequenceNumber)
 }
+
+func (t *TransactionSimulation) Build(result *access.TransactionSimulationResult) {
+	var events Events
+	events.Build(result.Events)
+
+	updates := make([]RegisterEntry, len(result.RegisterUpdates))
+	for i, update := range result.RegisterUpdates {
+		updates[i].Build(update)
+	}
+
+	t.BlockId = result.BlockID.String()
+	t.BlockHeight = util.FromUint64(result.BlockHeight)
+	t.ComputationUsed = util.FromUint64(result.ComputationUsed)
+	t.MemoryUsed = util.FromUint64(result.MemoryUsed)
+	t.Fee = util.FromUint64(result.Fee)
+	t.ErrorCode = int32(result.ErrorCode)
+	t.ErrorMessage = result.ErrorMessage
+	t.Events = events
+	t.RegisterUpdates = updates
+}
+
+func (r *RegisterEntry) Build(entry flow.RegisterEntry) {
+	r.Owner = hex.EncodeToString([]byte(entry.Key.Owner))
+	r.Controller = hex.EncodeToString([]byte(entry.Key.Controller))
+	r.Key = util.ToBase64([]byte(entry.Key.Key))
+	r.Value = util.ToBase64(entry.Value)
+}
+
+func (t *TransactionProfile) Build(profile *access.TransactionProfile) {
+	t.BlockId = profile.BlockID.String()
+	t.TransactionId = profile.TransactionID.String()
+	t.ComputationUsed = util.FromUint64(profile.ComputationUsed)
+	t.MemoryUsed = util.FromUint64(profile.MemoryUsed)
+	t.ComputationKinds = buildProfiledKinds(profile.ComputationKinds)
+	t.MemoryKinds = buildProfiledKinds(profile.MemoryKinds)
+	t.RegisterBytesRead = util.FromUint64(profile.RegisterBytesRead)
+	t.RegisterBytesWritten = util.FromUint64(profile.RegisterBytesWritten)
+}
+
+func buildProfiledKinds(kinds []access.ProfiledKind) []ProfiledKind {
+	profiled := make([]ProfiledKind, len(kinds))
+	for i, kind := range kinds {
+		profiled[i].Build(kind)
+	}
+	return profiled
+}
+
+func (p *ProfiledKind) Build(kind access.ProfiledKind) {
+	p.Kind = util.FromUint64(uint64(kind.Kind))
+	p.Name = kind.Name
+	p.Intensity = util.FromUint64(kind.Intensity)
+	p.Used = util.FromUint64(kind.Used)
+}
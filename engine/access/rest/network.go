@@ -0,0 +1,67 @@
+package rest
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/rest/models"
+	"github.com/onflow/flow-go/engine/access/rest/request"
+	"github.com/onflow/flow-go/model/bootstrap"
+)
+
+// GetNetworkParameters returns the network parameters along with the current epoch counter and phase
+func GetNetworkParameters(r *request.Request, backend access.API, _ models.LinkGenerator) (interface{}, error) {
+	params := backend.GetNetworkParameters(r.Context())
+
+	versionInfo, err := backend.GetNodeVersionInfo(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	epochInfo, err := backend.GetLatestEpochInfo(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.NetworkParameters
+	response.Build(params, versionInfo, epochInfo)
+	return response, nil
+}
+
+// GetNodeVersionInfo returns the version of the node along with its latest sealed and finalized heights
+func GetNodeVersionInfo(r *request.Request, backend access.API, _ models.LinkGenerator) (interface{}, error) {
+	versionInfo, err := backend.GetNodeVersionInfo(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := backend.GetLatestBlockHeader(r.Context(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	finalized, err := backend.GetLatestBlockHeader(r.Context(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.NodeVersionInfo
+	response.Build(versionInfo, sealed, finalized)
+	return response, nil
+}
+
+// GetProtocolSnapshot returns the latest valid protocol state snapshot, encoded as JSON in the same format as the
+// root protocol state snapshot used to bootstrap a node, so the response can be saved and used as is.
+// The response is sent as an attachment named like the root protocol state snapshot file.
+func GetProtocolSnapshot(r *request.Request, backend access.API, _ models.LinkGenerator) (interface{}, error) {
+	snapshot, err := backend.GetLatestProtocolStateSnapshot(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	return attachment{
+		filename: filepath.Base(bootstrap.PathRootProtocolStateSnapshot),
+		content:  json.RawMessage(snapshot),
+	}, nil
+}
@@ -0,0 +1,129 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	mocks "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/access/mock"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func nodeVersionInfoFixture() *access.NodeVersionInfo {
+	return &access.NodeVersionInfo{
+		Semver:               "v0.25.0",
+		Commit:               "a4b0c1d",
+		SporkID:              unittest.IdentifierFixture(),
+		ProtocolVersion:      2,
+		SporkRootBlockHeight: 1000,
+	}
+}
+
+func TestGetNetworkParameters(t *testing.T) {
+	backend := &mock.API{}
+	versionInfo := nodeVersionInfoFixture()
+
+	backend.Mock.
+		On("GetNetworkParameters", mocks.Anything).
+		Return(access.NetworkParameters{ChainID: flow.Testnet})
+	backend.Mock.
+		On("GetNodeVersionInfo", mocks.Anything).
+		Return(versionInfo, nil)
+	backend.Mock.
+		On("GetLatestEpochInfo", mocks.Anything).
+		Return(&access.EpochInfo{Counter: 5, Phase: flow.EpochPhaseSetup}, nil)
+
+	t.Run("get network parameters", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/network/parameters", nil)
+
+		expected := `{
+			"chain_id": "flow-testnet",
+			"spork_root_block_height": "1000",
+			"epoch_counter": "5",
+			"epoch_phase": "Setup"
+		}`
+		assertOKResponse(t, req, expected, backend)
+	})
+
+	t.Run("get network parameters with select", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/network/parameters?select=chain_id,epoch_counter", nil)
+
+		expected := `{
+			"chain_id": "flow-testnet",
+			"epoch_counter": "5"
+		}`
+		assertOKResponse(t, req, expected, backend)
+	})
+}
+
+func TestGetNodeVersionInfo(t *testing.T) {
+	backend := &mock.API{}
+	versionInfo := nodeVersionInfoFixture()
+	sealed := unittest.BlockHeaderFixture()
+	finalized := unittest.BlockHeaderWithParentFixture(&sealed)
+
+	backend.Mock.
+		On("GetNodeVersionInfo", mocks.Anything).
+		Return(versionInfo, nil)
+	backend.Mock.
+		On("GetLatestBlockHeader", mocks.Anything, true).
+		Return(&sealed, nil)
+	backend.Mock.
+		On("GetLatestBlockHeader", mocks.Anything, false).
+		Return(&finalized, nil)
+
+	req, _ := http.NewRequest("GET", "/v1/node_version_info", nil)
+
+	expected := fmt.Sprintf(`{
+		"semver": "v0.25.0",
+		"commit": "a4b0c1d",
+		"spork_id": "%s",
+		"protocol_version": "2",
+		"sealed_height": "%d",
+		"finalized_height": "%d"
+	}`, versionInfo.SporkID, sealed.Height, finalized.Height)
+	assertOKResponse(t, req, expected, backend)
+}
+
+func TestGetProtocolSnapshot(t *testing.T) {
+	t.Run("get protocol snapshot", func(t *testing.T) {
+		backend := &mock.API{}
+		snapshot := `{"Head":{"Height":10},"SealingSegment":{"Blocks":[]}}`
+
+		backend.Mock.
+			On("GetLatestProtocolStateSnapshot", mocks.Anything).
+			Return([]byte(snapshot), nil)
+
+		req, _ := http.NewRequest("GET", "/v1/protocol_snapshot", nil)
+		assertOKResponse(t, req, snapshot, backend)
+
+		rr, err := executeRequest(req, backend)
+		require.NoError(t, err)
+		assert.Equal(t, `attachment; filename=root-protocol-state-snapshot.json`, rr.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("get protocol snapshot failure", func(t *testing.T) {
+		backend := &mock.API{}
+
+		backend.Mock.
+			On("GetLatestProtocolStateSnapshot", mocks.Anything).
+			Return(nil, status.Error(codes.Internal, "failed to get sealing segment"))
+
+		req, _ := http.NewRequest("GET", "/v1/protocol_snapshot", nil)
+
+		expected := `{"code":400, "message":"Invalid Flow request: failed to get sealing segment"}`
+		assertResponse(t, req, http.StatusBadRequest, expected, backend)
+
+		rr, err := executeRequest(req, backend)
+		require.NoError(t, err)
+		assert.Empty(t, rr.Header().Get("Content-Disposition"))
+	})
+}
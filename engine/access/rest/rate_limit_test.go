@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	mocks "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/access/mock"
+	"github.com/onflow/flow-go/engine/access/ratelimit"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestClientRateLimit(t *testing.T) {
+	backend := &mock.API{}
+	collection := unittest.CollectionFixture(1).Light()
+	backend.Mock.
+		On("GetCollectionByID", mocks.Anything, collection.ID()).
+		Return(&collection, nil)
+
+	limiter, err := ratelimit.NewClientLimiter(ratelimit.Config{
+		MethodLimits: map[string]int{"getCollectionByID": 1},
+		APIKeyHeader: "X-Api-Key",
+	}, metrics.NewNoopCollector())
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	router, err := newRouter(backend, zerolog.New(&b), flow.Testnet.Chain(), limiter)
+	require.NoError(t, err)
+
+	get := func(remoteAddr string, apiKey string) *httptest.ResponseRecorder {
+		req := getCollectionReq(collection.ID().String(), false)
+		req.RemoteAddr = remoteAddr
+		if apiKey != "" {
+			req.Header.Set("X-Api-Key", apiKey)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	assert.Equal(t, http.StatusOK, get("10.0.0.1:1234", "").Code)
+
+	// the second request of the client within the same second is rejected, even from another port
+	rr := get("10.0.0.1:5678", "")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.JSONEq(t, `{"code":429, "message":"getCollectionByID rate limit reached, please retry later"}`, rr.Body.String())
+
+	// other clients have their own budget
+	assert.Equal(t, http.StatusOK, get("10.0.0.2:1234", "").Code)
+	assert.Equal(t, http.StatusOK, get("10.0.0.1:1234", "key1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, get("10.0.0.3:1234", "key1").Code)
+}
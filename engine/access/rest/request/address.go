@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/model/flow"
 )
 
@@ -25,3 +26,38 @@ func (a *Address) Parse(raw string) error {
 func (a Address) Flow() flow.Address {
 	return flow.Address(a)
 }
+
+type Addresses []Address
+
+func (a *Addresses) Parse(raw []string) error {
+	if len(raw) > access.MaxAccountsPerRequest {
+		return fmt.Errorf("at most %d addresses can be requested at a time", access.MaxAccountsPerRequest)
+	}
+
+	// only keep the first occurrence of each address
+	addresses := make(Addresses, 0)
+	uniqueAddresses := make(map[Address]bool)
+	for _, r := range raw {
+		var address Address
+		err := address.Parse(r)
+		if err != nil {
+			return err
+		}
+
+		if !uniqueAddresses[address] {
+			uniqueAddresses[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+
+	*a = addresses
+	return nil
+}
+
+func (a Addresses) Flow() []flow.Address {
+	addresses := make([]flow.Address, len(a))
+	for i, address := range a {
+		addresses[i] = address.Flow()
+	}
+	return addresses
+}
@@ -0,0 +1,47 @@
+package request
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+type GetAccounts struct {
+	Addresses []flow.Address
+	Height    uint64
+}
+
+func (g *GetAccounts) Build(r *Request) error {
+	return g.Parse(
+		r.GetQueryParams(addressesQuery),
+		r.GetQueryParam(blockHeightQuery),
+	)
+}
+
+func (g *GetAccounts) Parse(rawAddresses []string, rawHeight string) error {
+	var addresses Addresses
+	err := addresses.Parse(rawAddresses)
+	if err != nil {
+		return err
+	}
+
+	var height Height
+	err = height.Parse(rawHeight)
+	if err != nil {
+		return err
+	}
+
+	g.Addresses = addresses.Flow()
+	g.Height = height.Flow()
+
+	if len(g.Addresses) == 0 {
+		return fmt.Errorf("no addresses provided")
+	}
+
+	// default to last block
+	if g.Height == EmptyHeight {
+		g.Height = SealedHeight
+	}
+
+	return nil
+}
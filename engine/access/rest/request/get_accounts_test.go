@@ -0,0 +1,52 @@
+package request
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onflow/flow-go/access"
+)
+
+func Test_GetAccounts_InvalidParse(t *testing.T) {
+	var getAccounts GetAccounts
+
+	tooManyAddresses := make([]string, access.MaxAccountsPerRequest+1)
+	for i := range tooManyAddresses {
+		tooManyAddresses[i] = fmt.Sprintf("%016x", i)
+	}
+
+	tests := []struct {
+		addresses []string
+		height    string
+		err       string
+	}{
+		{nil, "", "no addresses provided"},
+		{[]string{"f8d6e0586b0a20c7", "foo"}, "", "invalid address"},
+		{[]string{"f8d6e0586b0a20c7"}, "-1", "invalid height format"},
+		{tooManyAddresses, "", "at most 50 addresses can be requested at a time"},
+	}
+
+	for i, test := range tests {
+		err := getAccounts.Parse(test.addresses, test.height)
+		assert.EqualError(t, err, test.err, fmt.Sprintf("test #%d failed", i))
+	}
+}
+
+func Test_GetAccounts_ValidParse(t *testing.T) {
+	var getAccounts GetAccounts
+
+	addresses := []string{"f8d6e0586b0a20c7", "0x01cf0e2f2f715450", "f8d6e0586b0a20c7"}
+	err := getAccounts.Parse(addresses, "")
+	assert.NoError(t, err)
+	assert.Len(t, getAccounts.Addresses, 2)
+	assert.Equal(t, addresses[0], getAccounts.Addresses[0].String())
+	assert.Equal(t, strings.TrimPrefix(addresses[1], "0x"), getAccounts.Addresses[1].String())
+	assert.Equal(t, SealedHeight, getAccounts.Height)
+
+	err = getAccounts.Parse(addresses[:1], "100")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), getAccounts.Height)
+}
@@ -3,31 +3,45 @@ package request
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
+	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/model/flow"
 )
 
 const eventTypeQuery = "type"
 const blockQuery = "block_ids"
+const fieldFiltersQuery = "field_filters"
 const MaxEventRequestHeightRange = 250
 
 type GetEvents struct {
-	StartHeight uint64
-	EndHeight   uint64
-	Type        string
-	BlockIDs    []flow.Identifier
+	StartHeight  uint64
+	EndHeight    uint64
+	Types        []flow.EventType
+	BlockIDs     []flow.Identifier
+	FieldFilters []access.EventFieldFilter
 }
 
 func (g *GetEvents) Build(r *Request) error {
 	return g.Parse(
-		r.GetQueryParam(eventTypeQuery),
+		r.GetQueryParams(eventTypeQuery),
 		r.GetQueryParam(startHeightQuery),
 		r.GetQueryParam(endHeightQuery),
 		r.GetQueryParams(blockQuery),
+		r.GetQueryParamValues(fieldFiltersQuery),
 	)
 }
 
-func (g *GetEvents) Parse(rawType string, rawStart string, rawEnd string, rawBlockIDs []string) error {
+// Parse parses the event query. Events of any of the types are selected, and can be further filtered by the values of
+// their fields, with filters of the form field:value. Each field filter is a separate field_filters query parameter,
+// which is not split on commas, so that values may contain commas.
+func (g *GetEvents) Parse(
+	rawTypes []string,
+	rawStart string,
+	rawEnd string,
+	rawBlockIDs []string,
+	rawFieldFilters []string,
+) error {
 	var height Height
 	err := height.Parse(rawStart)
 	if err != nil {
@@ -57,18 +71,29 @@ func (g *GetEvents) Parse(rawType string, rawStart string, rawEnd string, rawBlo
 		return fmt.Errorf("must provide either block IDs or start and end height range")
 	}
 
-	g.Type = rawType
-	if g.Type == "" {
+	if len(rawTypes) == 0 {
 		return fmt.Errorf("event type must be provided")
 	}
+	if len(rawTypes) > access.MaxEventTypesPerQuery {
+		return fmt.Errorf("at most %d event types can be requested at a time", access.MaxEventTypesPerQuery)
+	}
 
-	// match basic format A.address.contract.event (ignore err since regex will always compile)
-	basic, _ := regexp.MatchString(`[A-Z]\.[a-f0-9]{16}\.[\w+]*\.[\w+]*`, g.Type)
-	// match core events flow.event
-	core, _ := regexp.MatchString(`flow\.[\w]*`, g.Type)
+	g.Types = make([]flow.EventType, len(rawTypes))
+	for i, rawType := range rawTypes {
+		err = validateEventType(rawType)
+		if err != nil {
+			return err
+		}
+		g.Types[i] = flow.EventType(rawType)
+	}
 
-	if !core && !basic {
-		return fmt.Errorf("invalid event type format")
+	g.FieldFilters = make([]access.EventFieldFilter, len(rawFieldFilters))
+	for i, rawFilter := range rawFieldFilters {
+		field, value, found := strings.Cut(rawFilter, ":")
+		if !found || field == "" {
+			return fmt.Errorf("invalid field filter format, must be field:value")
+		}
+		g.FieldFilters[i] = access.EventFieldFilter{Field: field, Value: value}
 	}
 
 	// validate start end height option
@@ -84,3 +109,17 @@ func (g *GetEvents) Parse(rawType string, rawStart string, rawEnd string, rawBlo
 
 	return nil
 }
+
+// validateEventType checks that the event type is either a contract event type of the
+// form A.address.contract.event or a core event type of the form flow.event.
+func validateEventType(eventType string) error {
+	// match basic format A.address.contract.event (ignore err since regex will always compile)
+	basic, _ := regexp.MatchString(`[A-Z]\.[a-f0-9]{16}\.[\w+]*\.[\w+]*`, eventType)
+	// match core events flow.event
+	core, _ := regexp.MatchString(`flow\.[\w]*`, eventType)
+
+	if !core && !basic {
+		return fmt.Errorf("invalid event type format")
+	}
+	return nil
+}
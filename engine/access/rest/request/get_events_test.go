@@ -5,6 +5,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/model/flow"
 )
 
 func TestGetEvents_InvalidParse(t *testing.T) {
@@ -29,34 +32,62 @@ func TestGetEvents_InvalidParse(t *testing.T) {
 	}
 
 	for i, test := range tests {
-		err := getEvents.Parse(test.eventType, test.start, test.end, test.ids)
+		err := getEvents.Parse([]string{test.eventType}, test.start, test.end, test.ids, nil)
 		assert.EqualError(t, err, test.err, fmt.Sprintf("test #%d failed", i))
 	}
+
+	err := getEvents.Parse(nil, "5", "10", nil, nil)
+	assert.EqualError(t, err, "event type must be provided")
+
+	err = getEvents.Parse(make([]string, 11), "5", "10", nil, nil)
+	assert.EqualError(t, err, "at most 10 event types can be requested at a time")
+
+	err = getEvents.Parse([]string{"flow.AccountCreated", "foo"}, "5", "10", nil, nil)
+	assert.EqualError(t, err, "invalid event type format")
+
+	for _, filter := range []string{"address", ":0x01"} {
+		err = getEvents.Parse([]string{"flow.AccountCreated"}, "5", "10", nil, []string{filter})
+		assert.EqualError(t, err, "invalid field filter format, must be field:value")
+	}
 }
 
 func TestGetEvents_ValidParse(t *testing.T) {
 	var getEvents GetEvents
 
 	event := "A.f8d6e0586b0a20c7.Foo.Bar"
-	err := getEvents.Parse(event, "5", "10", nil)
+	err := getEvents.Parse([]string{event}, "5", "10", nil, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, getEvents.Type, event)
+	assert.Equal(t, getEvents.Types, []flow.EventType{flow.EventType(event)})
 	assert.Equal(t, getEvents.StartHeight, uint64(5))
 	assert.Equal(t, getEvents.EndHeight, uint64(10))
 	assert.Equal(t, len(getEvents.BlockIDs), 0)
 
 	event = "flow.AccountCreated"
-	err = getEvents.Parse(event, "", "", []string{
+	err = getEvents.Parse([]string{event}, "", "", []string{
 		"7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7",
 		"7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7", // intentional duplication
 		"2ab81061b12d95fb81f2923001e340bc808e67e1eaae3c62479057cc14eb57fd",
-	})
+	}, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, getEvents.Type, event)
+	assert.Equal(t, getEvents.Types, []flow.EventType{flow.EventType(event)})
 	assert.Equal(t, getEvents.StartHeight, EmptyHeight)
 	assert.Equal(t, getEvents.EndHeight, EmptyHeight)
 	assert.Equal(t, len(getEvents.BlockIDs), 2)
 	assert.Equal(t, getEvents.BlockIDs[0].String(), "7bc42fe85d32ca513769a74f97f7e1a7bad6c9407f0d934c2aa645ef9cf613c7")
 	assert.Equal(t, getEvents.BlockIDs[1].String(), "2ab81061b12d95fb81f2923001e340bc808e67e1eaae3c62479057cc14eb57fd")
 
+	err = getEvents.Parse(
+		[]string{"flow.AccountCreated", "A.f8d6e0586b0a20c7.Foo.Bar"},
+		"5",
+		"10",
+		nil,
+		[]string{"address:0xf8d6e0586b0a20c7", "uri:https://example.com", "tags:a,b"},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, getEvents.Types, []flow.EventType{"flow.AccountCreated", "A.f8d6e0586b0a20c7.Foo.Bar"})
+	assert.Equal(t, getEvents.FieldFilters, []access.EventFieldFilter{
+		{Field: "address", Value: "0xf8d6e0586b0a20c7"},
+		{Field: "uri", Value: "https://example.com"},
+		{Field: "tags", Value: "a,b"},
+	})
 }
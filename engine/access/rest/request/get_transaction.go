@@ -17,3 +17,7 @@ func (g *GetTransaction) Build(r *Request) error {
 type GetTransactionResult struct {
 	GetByIDRequest
 }
+
+type GetTransactionProfile struct {
+	GetByIDRequest
+}
@@ -54,6 +54,12 @@ func (rd *Request) GetAccountRequest() (GetAccount, error) {
 	return req, err
 }
 
+func (rd *Request) GetAccountsRequest() (GetAccounts, error) {
+	var req GetAccounts
+	err := req.Build(rd)
+	return req, err
+}
+
 func (rd *Request) GetExecutionResultByBlockIDsRequest() (GetExecutionResultByBlockIDs, error) {
 	var req GetExecutionResultByBlockIDs
 	err := req.Build(rd)
@@ -78,18 +84,36 @@ func (rd *Request) GetTransactionResultRequest() (GetTransactionResult, error) {
 	return req, err
 }
 
+func (rd *Request) GetTransactionProfileRequest() (GetTransactionProfile, error) {
+	var req GetTransactionProfile
+	err := req.Build(rd)
+	return req, err
+}
+
 func (rd *Request) GetEventsRequest() (GetEvents, error) {
 	var req GetEvents
 	err := req.Build(rd)
 	return req, err
 }
 
+func (rd *Request) SubscribeEventsRequest() (SubscribeEvents, error) {
+	var req SubscribeEvents
+	err := req.Build(rd)
+	return req, err
+}
+
 func (rd *Request) CreateTransactionRequest() (CreateTransaction, error) {
 	var req CreateTransaction
 	err := req.Build(rd)
 	return req, err
 }
 
+func (rd *Request) SimulateTransactionRequest() (SimulateTransaction, error) {
+	var req SimulateTransaction
+	err := req.Build(rd)
+	return req, err
+}
+
 func (rd *Request) Expands(field string) bool {
 	return rd.ExpandFields[field]
 }
@@ -117,6 +141,12 @@ func (rd *Request) GetQueryParams(name string) []string {
 	return toStringArray(param)
 }
 
+// GetQueryParamValues returns the values of all query parameters with the given name, as they are.
+// Unlike GetQueryParams, the values are not split on commas.
+func (rd *Request) GetQueryParamValues(name string) []string {
+	return rd.Request.URL.Query()[name]
+}
+
 // Decorate takes http request and applies functions to produce our custom
 // request object decorated with values we need
 func Decorate(r *http.Request, chain flow.Chain) *Request {
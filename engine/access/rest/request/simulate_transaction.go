@@ -0,0 +1,27 @@
+package request
+
+import (
+	"io"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+type SimulateTransaction struct {
+	Transaction flow.TransactionBody
+}
+
+func (s *SimulateTransaction) Build(r *Request) error {
+	return s.Parse(r.Body, r.Chain)
+}
+
+func (s *SimulateTransaction) Parse(rawTransaction io.Reader, chain flow.Chain) error {
+	var tx Transaction
+	// transactions are simulated without verifying signatures, so they can be simulated before being signed
+	err := tx.ParseUnsigned(rawTransaction, chain)
+	if err != nil {
+		return err
+	}
+
+	s.Transaction = tx.Flow()
+	return nil
+}
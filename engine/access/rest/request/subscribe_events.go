@@ -0,0 +1,85 @@
+package request
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+const blockStatusQuery = "block_status"
+const eventTypesQuery = "event_types"
+const addressesQuery = "addresses"
+const namePrefixesQuery = "name_prefixes"
+
+type SubscribeEvents struct {
+	StartHeight  uint64
+	IsSealed     bool
+	EventTypes   []flow.EventType
+	Addresses    []flow.Address
+	NamePrefixes []string
+}
+
+func (s *SubscribeEvents) Build(r *Request) error {
+	return s.Parse(
+		r.GetQueryParam(startHeightQuery),
+		r.GetQueryParam(blockStatusQuery),
+		r.GetQueryParams(eventTypesQuery),
+		r.GetQueryParams(addressesQuery),
+		r.GetQueryParams(namePrefixesQuery),
+	)
+}
+
+func (s *SubscribeEvents) Parse(
+	rawStart string,
+	rawBlockStatus string,
+	rawTypes []string,
+	rawAddresses []string,
+	rawPrefixes []string,
+) error {
+	var height Height
+	err := height.Parse(rawStart)
+	if err != nil {
+		return fmt.Errorf("invalid start height: %w", err)
+	}
+
+	switch height.Flow() {
+	case EmptyHeight:
+		s.StartHeight = 0
+	case SealedHeight, FinalHeight:
+		return fmt.Errorf("invalid start height: must be a block height")
+	default:
+		s.StartHeight = height.Flow()
+	}
+
+	switch rawBlockStatus {
+	case "", final:
+		s.IsSealed = false
+	case sealed:
+		s.IsSealed = true
+	default:
+		return fmt.Errorf("invalid block status: must be either %s or %s", sealed, final)
+	}
+
+	s.EventTypes = make([]flow.EventType, len(rawTypes))
+	for i, rawType := range rawTypes {
+		err = validateEventType(rawType)
+		if err != nil {
+			return err
+		}
+		s.EventTypes[i] = flow.EventType(rawType)
+	}
+
+	s.Addresses = make([]flow.Address, len(rawAddresses))
+	for i, rawAddress := range rawAddresses {
+		var address Address
+		err = address.Parse(rawAddress)
+		if err != nil {
+			return err
+		}
+		s.Addresses[i] = address.Flow()
+	}
+
+	s.NamePrefixes = rawPrefixes
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package request
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+func TestSubscribeEvents_InvalidParse(t *testing.T) {
+	var subscribeEvents SubscribeEvents
+
+	tests := []struct {
+		start       string
+		blockStatus string
+		types       []string
+		addresses   []string
+		err         string
+	}{
+		{"foo", "", nil, nil, "invalid start height: invalid height format"},
+		{"sealed", "", nil, nil, "invalid start height: must be a block height"},
+		{"", "executed", nil, nil, "invalid block status: must be either sealed or final"},
+		{"", "", []string{"foo"}, nil, "invalid event type format"},
+		{"", "", nil, []string{"0x1"}, "invalid address"},
+	}
+
+	for i, test := range tests {
+		err := subscribeEvents.Parse(test.start, test.blockStatus, test.types, test.addresses, nil)
+		assert.EqualError(t, err, test.err, fmt.Sprintf("test #%d failed", i))
+	}
+}
+
+func TestSubscribeEvents_ValidParse(t *testing.T) {
+	var subscribeEvents SubscribeEvents
+
+	err := subscribeEvents.Parse("", "", nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), subscribeEvents.StartHeight)
+	assert.False(t, subscribeEvents.IsSealed)
+	assert.Empty(t, subscribeEvents.EventTypes)
+	assert.Empty(t, subscribeEvents.Addresses)
+
+	err = subscribeEvents.Parse(
+		"10",
+		"sealed",
+		[]string{"A.f8d6e0586b0a20c7.Foo.Bar", "flow.AccountCreated"},
+		[]string{"0xf8d6e0586b0a20c7"},
+		[]string{"A.f8d6e0586b0a20c7.Foo."},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), subscribeEvents.StartHeight)
+	assert.True(t, subscribeEvents.IsSealed)
+	assert.Equal(t, []flow.EventType{"A.f8d6e0586b0a20c7.Foo.Bar", "flow.AccountCreated"}, subscribeEvents.EventTypes)
+	assert.Equal(t, []flow.Address{flow.HexToAddress("f8d6e0586b0a20c7")}, subscribeEvents.Addresses)
+	assert.Equal(t, []string{"A.f8d6e0586b0a20c7.Foo."}, subscribeEvents.NamePrefixes)
+}
@@ -7,17 +7,26 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/ratelimit"
 	"github.com/onflow/flow-go/engine/access/rest/middleware"
 	"github.com/onflow/flow-go/engine/access/rest/models"
 	"github.com/onflow/flow-go/model/flow"
 )
 
-func newRouter(backend access.API, logger zerolog.Logger, chain flow.Chain) (*mux.Router, error) {
+func newRouter(
+	backend access.API,
+	logger zerolog.Logger,
+	chain flow.Chain,
+	limiter *ratelimit.ClientLimiter,
+) (*mux.Router, error) {
 	router := mux.NewRouter().StrictSlash(true)
 	v1SubRouter := router.PathPrefix("/v1").Subrouter()
 
 	// common middleware for all request
 	v1SubRouter.Use(middleware.LoggingMiddleware(logger))
+	if limiter != nil {
+		v1SubRouter.Use(middleware.RateLimit(limiter))
+	}
 	v1SubRouter.Use(middleware.QueryExpandable())
 	v1SubRouter.Use(middleware.QuerySelect())
 
@@ -31,6 +40,15 @@ func newRouter(backend access.API, logger zerolog.Logger, chain flow.Chain) (*mu
 			Name(r.Name).
 			Handler(h)
 	}
+
+	for _, r := range WSRoutes {
+		h := NewWSHandler(logger, backend, r.Handler, linkGenerator, chain)
+		v1SubRouter.
+			Methods(r.Method).
+			Path(r.Pattern).
+			Name(r.Name).
+			Handler(h)
+	}
 	return router, nil
 }
 
@@ -51,11 +69,21 @@ var Routes = []route{{
 	Pattern: "/transactions",
 	Name:    "createTransaction",
 	Handler: CreateTransaction,
+}, {
+	Method:  http.MethodPost,
+	Pattern: "/transactions/simulate",
+	Name:    "simulateTransaction",
+	Handler: SimulateTransaction,
 }, {
 	Method:  http.MethodGet,
 	Pattern: "/transaction_results/{id}",
 	Name:    "getTransactionResultByID",
 	Handler: GetTransactionResultByID,
+}, {
+	Method:  http.MethodGet,
+	Pattern: "/transaction_profiles/{id}",
+	Name:    "getTransactionProfileByID",
+	Handler: GetTransactionProfileByID,
 }, {
 	Method:  http.MethodGet,
 	Pattern: "/blocks/{id}",
@@ -96,9 +124,49 @@ var Routes = []route{{
 	Pattern: "/accounts/{address}",
 	Name:    "getAccount",
 	Handler: GetAccount,
+}, {
+	Method:  http.MethodGet,
+	Pattern: "/accounts",
+	Name:    "getAccounts",
+	Handler: GetAccounts,
 }, {
 	Method:  http.MethodGet,
 	Pattern: "/events",
 	Name:    "getEvents",
 	Handler: GetEvents,
+}, {
+	Method:  http.MethodGet,
+	Pattern: "/network/parameters",
+	Name:    "getNetworkParameters",
+	Handler: GetNetworkParameters,
+}, {
+	Method:  http.MethodGet,
+	Pattern: "/node_version_info",
+	Name:    "getNodeVersionInfo",
+	Handler: GetNodeVersionInfo,
+}, {
+	Method:  http.MethodGet,
+	Pattern: "/protocol_snapshot",
+	Name:    "getProtocolSnapshot",
+	Handler: GetProtocolSnapshot,
+}}
+
+type wsRoute struct {
+	Name    string
+	Method  string
+	Pattern string
+	Handler SubscribeHandlerFunc
+}
+
+// WSRoutes are subscription routes, which are served over websocket connections.
+var WSRoutes = []wsRoute{{
+	Method:  http.MethodGet,
+	Pattern: "/subscribe_events",
+	Name:    "subscribeEvents",
+	Handler: SubscribeEvents,
+}, {
+	Method:  http.MethodGet,
+	Pattern: "/subscribe_transaction_status/{id}",
+	Name:    "subscribeTransactionStatus",
+	Handler: SubscribeTransactionStatus,
 }}
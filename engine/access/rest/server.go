@@ -8,13 +8,21 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/ratelimit"
 	"github.com/onflow/flow-go/model/flow"
 )
 
-// NewServer returns an HTTP server initialized with the REST API handler
-func NewServer(backend access.API, listenAddress string, logger zerolog.Logger, chain flow.Chain) (*http.Server, error) {
+// NewServer returns an HTTP server initialized with the REST API handler. Clients are rate limited by the given limiter,
+// if not nil.
+func NewServer(
+	backend access.API,
+	listenAddress string,
+	logger zerolog.Logger,
+	chain flow.Chain,
+	limiter *ratelimit.ClientLimiter,
+) (*http.Server, error) {
 
-	router, err := newRouter(backend, logger, chain)
+	router, err := newRouter(backend, logger, chain, limiter)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,46 @@
+package rest
+
+import (
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/rest/models"
+	"github.com/onflow/flow-go/engine/access/rest/request"
+)
+
+// SubscribeEvents streams the events matching the provided filter block by block, starting at the provided height.
+func SubscribeEvents(r *request.Request, backend access.API, _ models.LinkGenerator) (*Subscription, error) {
+	req, err := r.SubscribeEventsRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	filter := access.EventFilter{
+		EventTypes:   req.EventTypes,
+		Addresses:    req.Addresses,
+		NamePrefixes: req.NamePrefixes,
+	}
+
+	sub, err := backend.SubscribeEvents(r.Context(), req.StartHeight, req.IsSealed, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make(chan interface{})
+	go func() {
+		defer close(responses)
+		for events := range sub.Channel() {
+			var blockEvents models.BlockEvents
+			blockEvents.Build(events)
+
+			select {
+			case <-r.Context().Done():
+				return
+			case responses <- blockEvents:
+			}
+		}
+	}()
+
+	return &Subscription{
+		Responses: responses,
+		Err:       sub.Err,
+	}, nil
+}
@@ -0,0 +1,124 @@
+package rest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+	mocks "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/access/mock"
+	"github.com/onflow/flow-go/engine/access/rest/models"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// testEventsSubscription is an access.EventsSubscription delivering a fixed list of block events.
+type testEventsSubscription struct {
+	ch chan flow.BlockEvents
+}
+
+func newTestEventsSubscription(events []flow.BlockEvents) *testEventsSubscription {
+	ch := make(chan flow.BlockEvents, len(events))
+	for _, e := range events {
+		ch <- e
+	}
+	close(ch)
+	return &testEventsSubscription{ch: ch}
+}
+
+func (s *testEventsSubscription) Channel() <-chan flow.BlockEvents {
+	return s.ch
+}
+
+func (s *testEventsSubscription) Err() error {
+	return nil
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	backend := &mock.API{}
+
+	events := make([]flow.BlockEvents, 3)
+	for i := range events {
+		header := unittest.BlockHeaderFixture(unittest.WithHeaderHeight(uint64(10 + i)))
+		events[i] = unittest.BlockEventsFixture(header, 2)
+	}
+
+	address := unittest.AddressFixture()
+	expectedFilter := access.EventFilter{
+		EventTypes:   []flow.EventType{"A.179b6b1cb6755e31.Foo.Bar"},
+		Addresses:    []flow.Address{address},
+		NamePrefixes: []string{"A.179b6b1cb6755e31.Foo."},
+	}
+
+	backend.Mock.
+		On("SubscribeEvents", mocks.Anything, uint64(10), true, expectedFilter).
+		Return(newTestEventsSubscription(events), nil)
+
+	server := httptest.NewServer(testRouter(t, backend))
+	defer server.Close()
+
+	t.Run("stream events", func(t *testing.T) {
+		u := subscribeEventsURL(server.URL, map[string]string{
+			"start_height":  "10",
+			"block_status":  "sealed",
+			"event_types":   "A.179b6b1cb6755e31.Foo.Bar",
+			"addresses":     address.String(),
+			"name_prefixes": "A.179b6b1cb6755e31.Foo.",
+		})
+
+		conn, _, err := websocket.DefaultDialer.Dial(u, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		for _, expected := range events {
+			var expectedModel models.BlockEvents
+			expectedModel.Build(expected)
+
+			var actual models.BlockEvents
+			err = conn.ReadJSON(&actual)
+			require.NoError(t, err)
+			require.Equal(t, expectedModel.BlockId, actual.BlockId)
+			require.Equal(t, expectedModel.BlockHeight, actual.BlockHeight)
+			require.Equal(t, expectedModel.Events, actual.Events)
+		}
+
+		// the subscription ended, so the server closes the connection
+		_, _, err = conn.ReadMessage()
+		require.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+	})
+
+	t.Run("invalid request is rejected before upgrading", func(t *testing.T) {
+		u := subscribeEventsURL(server.URL, map[string]string{
+			"block_status": "executed",
+		})
+
+		_, resp, err := websocket.DefaultDialer.Dial(u, nil)
+		require.ErrorIs(t, err, websocket.ErrBadHandshake)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func testRouter(t *testing.T, backend *mock.API) http.Handler {
+	var b bytes.Buffer
+	router, err := newRouter(backend, zerolog.New(&b), flow.Testnet.Chain(), nil)
+	require.NoError(t, err)
+	return router
+}
+
+func subscribeEventsURL(serverURL string, params map[string]string) string {
+	u, _ := url.Parse(strings.Replace(serverURL, "http", "ws", 1) + "/v1/subscribe_events")
+	q := u.Query()
+	for k, v := range params {
+		q.Add(k, v)
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
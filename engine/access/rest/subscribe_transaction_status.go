@@ -0,0 +1,40 @@
+package rest
+
+import (
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/rest/models"
+	"github.com/onflow/flow-go/engine/access/rest/request"
+)
+
+// SubscribeTransactionStatus streams the transaction result for each status the requested transaction transitions to.
+func SubscribeTransactionStatus(r *request.Request, backend access.API, link models.LinkGenerator) (*Subscription, error) {
+	req, err := r.GetTransactionResultRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	sub, err := backend.SubscribeTransactionStatus(r.Context(), req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make(chan interface{})
+	go func() {
+		defer close(responses)
+		for txr := range sub.Channel() {
+			var response models.TransactionResult
+			response.Build(txr, req.ID, link)
+
+			select {
+			case <-r.Context().Done():
+				return
+			case responses <- response:
+			}
+		}
+	}()
+
+	return &Subscription{
+		Responses: responses,
+		Err:       sub.Err,
+	}, nil
+}
@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	mocks "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/access/mock"
+	"github.com/onflow/flow-go/engine/access/rest/models"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// testTransactionStatusSubscription is an access.TransactionStatusSubscription delivering a fixed list of results.
+type testTransactionStatusSubscription struct {
+	ch chan *access.TransactionResult
+}
+
+func newTestTransactionStatusSubscription(results []*access.TransactionResult) *testTransactionStatusSubscription {
+	ch := make(chan *access.TransactionResult, len(results))
+	for _, r := range results {
+		ch <- r
+	}
+	close(ch)
+	return &testTransactionStatusSubscription{ch: ch}
+}
+
+func (s *testTransactionStatusSubscription) Channel() <-chan *access.TransactionResult {
+	return s.ch
+}
+
+func (s *testTransactionStatusSubscription) Err() error {
+	return nil
+}
+
+func TestSubscribeTransactionStatus(t *testing.T) {
+	backend := &mock.API{}
+
+	txID := unittest.IdentifierFixture()
+	blockID := unittest.IdentifierFixture()
+	events := []flow.Event{
+		unittest.EventFixture(flow.EventAccountCreated, 0, 0, txID, 0),
+		unittest.EventFixture(flow.EventAccountUpdated, 0, 1, txID, 0),
+	}
+	results := []*access.TransactionResult{{
+		Status:        flow.TransactionStatusPending,
+		TransactionID: txID,
+	}, {
+		Status:        flow.TransactionStatusFinalized,
+		BlockID:       blockID,
+		TransactionID: txID,
+	}, {
+		Status:        flow.TransactionStatusExecuted,
+		BlockID:       blockID,
+		TransactionID: txID,
+		Events:        events,
+	}, {
+		Status:        flow.TransactionStatusSealed,
+		BlockID:       blockID,
+		TransactionID: txID,
+		Events:        events,
+	}}
+
+	backend.Mock.
+		On("SubscribeTransactionStatus", mocks.Anything, txID).
+		Return(newTestTransactionStatusSubscription(results), nil)
+
+	server := httptest.NewServer(testRouter(t, backend))
+	defer server.Close()
+
+	t.Run("stream status transitions", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial(subscribeTransactionStatusURL(server.URL, txID.String()), nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		for _, expected := range results {
+			var expectedStatus models.TransactionStatus
+			expectedStatus.Build(expected.Status)
+
+			var actual models.TransactionResult
+			err = conn.ReadJSON(&actual)
+			require.NoError(t, err)
+			require.Equal(t, expectedStatus, *actual.Status)
+			require.Len(t, actual.Events, len(expected.Events))
+			if expected.BlockID != flow.ZeroID {
+				require.Equal(t, expected.BlockID.String(), actual.BlockId)
+			}
+		}
+
+		// the transaction is sealed, so the server closes the connection
+		_, _, err = conn.ReadMessage()
+		require.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+	})
+
+	t.Run("invalid transaction ID is rejected before upgrading", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(subscribeTransactionStatusURL(server.URL, "invalid"), nil)
+		require.ErrorIs(t, err, websocket.ErrBadHandshake)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func subscribeTransactionStatusURL(serverURL string, id string) string {
+	return strings.Replace(serverURL, "http", "ws", 1) + "/v1/subscribe_transaction_status/" + id
+}
@@ -49,6 +49,23 @@ func GetTransactionResultByID(r *request.Request, backend access.API, link model
 	return response, nil
 }
 
+// GetTransactionProfileByID retrieves the breakdown of the resources used to execute a transaction by the transaction ID.
+func GetTransactionProfileByID(r *request.Request, backend access.API, _ models.LinkGenerator) (interface{}, error) {
+	req, err := r.GetTransactionProfileRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	profile, err := backend.GetTransactionProfile(r.Context(), req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.TransactionProfile
+	response.Build(profile)
+	return response, nil
+}
+
 // CreateTransaction creates a new transaction from provided payload.
 func CreateTransaction(r *request.Request, backend access.API, link models.LinkGenerator) (interface{}, error) {
 	req, err := r.CreateTransactionRequest()
@@ -65,3 +82,20 @@ func CreateTransaction(r *request.Request, backend access.API, link models.LinkG
 	response.Build(&req.Transaction, nil, link)
 	return response, nil
 }
+
+// SimulateTransaction runs the provided transaction against the latest sealed execution state without committing it.
+func SimulateTransaction(r *request.Request, backend access.API, _ models.LinkGenerator) (interface{}, error) {
+	req, err := r.SimulateTransactionRequest()
+	if err != nil {
+		return nil, NewBadRequestError(err)
+	}
+
+	result, err := backend.SimulateTransaction(r.Context(), &req.Transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.TransactionSimulation
+	response.Build(result)
+	return response, nil
+}
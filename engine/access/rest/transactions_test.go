@@ -41,6 +41,11 @@ func getTransactionResultReq(id string) *http.Request {
 	return req
 }
 
+func getTransactionProfileReq(id string) *http.Request {
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/v1/transaction_profiles/%s", id), nil)
+	return req
+}
+
 func createTransactionReq(body interface{}) *http.Request {
 	jsonBody, _ := json.Marshal(body)
 	req, _ := http.NewRequest("POST", "/v1/transactions", bytes.NewBuffer(jsonBody))
@@ -320,6 +325,68 @@ func TestGetTransactionResult(t *testing.T) {
 	})
 }
 
+func TestGetTransactionProfile(t *testing.T) {
+
+	t.Run("get by ID", func(t *testing.T) {
+		backend := &mock.API{}
+		id := unittest.IdentifierFixture()
+		block := unittest.IdentifierFixture()
+
+		backend.Mock.
+			On("GetTransactionProfile", mocks.Anything, id).
+			Return(&access.TransactionProfile{
+				BlockID:         block,
+				TransactionID:   id,
+				ComputationUsed: 12,
+				MemoryUsed:      2048,
+				ComputationKinds: []access.ProfiledKind{
+					{Kind: 1001, Name: "Statement", Intensity: 20, Used: 12},
+				},
+				MemoryKinds: []access.ProfiledKind{
+					{Kind: 5, Name: "String", Intensity: 64, Used: 2048},
+				},
+				RegisterBytesRead:    300,
+				RegisterBytesWritten: 42,
+			}, nil)
+
+		expected := fmt.Sprintf(`
+			{
+			   "block_id":"%s",
+			   "transaction_id":"%s",
+			   "computation_used":"12",
+			   "memory_used":"2048",
+			   "computation_kinds":[
+				  {"kind":"1001", "name":"Statement", "intensity":"20", "used":"12"}
+			   ],
+			   "memory_kinds":[
+				  {"kind":"5", "name":"String", "intensity":"64", "used":"2048"}
+			   ],
+			   "register_bytes_read":"300",
+			   "register_bytes_written":"42"
+			}`, block, id)
+		assertOKResponse(t, getTransactionProfileReq(id.String()), expected, backend)
+	})
+
+	t.Run("get by ID not found", func(t *testing.T) {
+		backend := &mock.API{}
+		id := unittest.IdentifierFixture()
+
+		backend.Mock.
+			On("GetTransactionProfile", mocks.Anything, id).
+			Return(nil, status.Error(codes.NotFound, "transaction profile not found"))
+
+		expected := `{"code":404, "message":"Flow resource not found: transaction profile not found"}`
+		assertResponse(t, getTransactionProfileReq(id.String()), http.StatusNotFound, expected, backend)
+	})
+
+	t.Run("get by ID Invalid", func(t *testing.T) {
+		backend := &mock.API{}
+
+		expected := `{"code":400, "message":"invalid ID format"}`
+		assertResponse(t, getTransactionProfileReq("invalid"), http.StatusBadRequest, expected, backend)
+	})
+}
+
 func TestCreateTransaction(t *testing.T) {
 
 	t.Run("create", func(t *testing.T) {
@@ -404,6 +471,118 @@ func TestCreateTransaction(t *testing.T) {
 	})
 }
 
+func TestSimulateTransaction(t *testing.T) {
+
+	t.Run("simulate", func(t *testing.T) {
+		backend := &mock.API{}
+		tx := unittest.TransactionBodyFixture()
+		tx.PayloadSignatures = []flow.TransactionSignature{unittest.TransactionSignatureFixture()}
+		tx.Arguments = [][]uint8{}
+		req := simulateTransactionReq(validCreateBody(tx))
+
+		block := unittest.IdentifierFixture()
+		event := unittest.EventFixture(flow.EventAccountCreated, 0, 0, tx.ID(), 0)
+		owner := unittest.AddressFixture()
+
+		backend.Mock.
+			On("SimulateTransaction", mocks.Anything, &tx).
+			Return(&access.TransactionSimulationResult{
+				BlockID:         block,
+				BlockHeight:     42,
+				ComputationUsed: 17,
+				MemoryUsed:      1024,
+				Events:          []flow.Event{event},
+				RegisterUpdates: []flow.RegisterEntry{{
+					Key:   flow.NewRegisterID(string(owner.Bytes()), "", "storage_used"),
+					Value: []byte{1, 2},
+				}},
+				Fee:          1_000,
+				ErrorCode:    1101,
+				ErrorMessage: "[Error Code: 1101] cadence runtime error",
+			}, nil)
+
+		expected := fmt.Sprintf(`
+			{
+			   "block_id":"%s",
+			   "block_height":"42",
+			   "computation_used":"17",
+			   "memory_used":"1024",
+			   "fee":"1000",
+			   "error_code":1101,
+			   "error_message":"[Error Code: 1101] cadence runtime error",
+			   "events":[
+				  {
+					 "type":"flow.AccountCreated",
+					 "transaction_id":"%s",
+					 "transaction_index":"0",
+					 "event_index":"0",
+					 "payload":"%s"
+				  }
+			   ],
+			   "register_updates":[
+				  {
+					 "owner":"%s",
+					 "controller":"",
+					 "key":"%s",
+					 "value":"AQI="
+				  }
+			   ]
+			}`,
+			block, tx.ID(), util.ToBase64(event.Payload), owner.Hex(), util.ToBase64([]byte("storage_used")))
+		assertOKResponse(t, req, expected, backend)
+	})
+
+	t.Run("simulate unsigned transaction", func(t *testing.T) {
+		backend := &mock.API{}
+		tx := unittest.TransactionBodyFixture()
+		tx.PayloadSignatures = []flow.TransactionSignature{unittest.TransactionSignatureFixture()}
+		body := validCreateBody(tx)
+		delete(body, "payload_signatures")
+		delete(body, "envelope_signatures")
+		req := simulateTransactionReq(body)
+
+		block := unittest.IdentifierFixture()
+		backend.Mock.
+			On("SimulateTransaction", mocks.Anything, mocks.MatchedBy(func(simulated *flow.TransactionBody) bool {
+				return simulated.Payer == tx.Payer &&
+					len(simulated.PayloadSignatures) == 0 &&
+					len(simulated.EnvelopeSignatures) == 0
+			})).
+			Return(&access.TransactionSimulationResult{BlockID: block, BlockHeight: 42}, nil)
+
+		expected := fmt.Sprintf(`
+			{
+			   "block_id":"%s",
+			   "block_height":"42",
+			   "computation_used":"0",
+			   "memory_used":"0",
+			   "fee":"0",
+			   "error_code":0,
+			   "error_message":"",
+			   "events":[],
+			   "register_updates":[]
+			}`, block)
+		assertOKResponse(t, req, expected, backend)
+	})
+
+	t.Run("simulate invalid transaction", func(t *testing.T) {
+		backend := &mock.API{}
+		tx := unittest.TransactionBodyFixture()
+		tx.PayloadSignatures = []flow.TransactionSignature{unittest.TransactionSignatureFixture()}
+		testTx := validCreateBody(tx)
+		testTx["payer"] = "yo"
+		req := simulateTransactionReq(testTx)
+
+		assertResponse(t, req, http.StatusBadRequest, `{"code":400, "message":"invalid payer: invalid address"}`, backend)
+	})
+}
+
+func simulateTransactionReq(body interface{}) *http.Request {
+	jsonBody, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", "/v1/transactions/simulate", bytes.NewBuffer(jsonBody))
+	return req
+}
+
 func transactionResultFixture(tx flow.Transaction) *access.TransactionResult {
 	return &access.TransactionResult{
 		Status:     flow.TransactionStatusSealed,
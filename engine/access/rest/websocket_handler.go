@@ -0,0 +1,172 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/rest/models"
+	"github.com/onflow/flow-go/engine/access/rest/request"
+	"github.com/onflow/flow-go/engine/access/rest/util"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+const (
+	// wsWriteWait is the time allowed to write a message to the client.
+	wsWriteWait = 10 * time.Second
+	// wsPongWait is the time allowed to read the next pong message from the client.
+	wsPongWait = 60 * time.Second
+	// wsPingPeriod is the period at which pings are sent to the client, it must be less than wsPongWait.
+	wsPingPeriod = (wsPongWait * 9) / 10
+	// wsMaxCloseReasonSize is the maximum size of the reason sent in a close frame.
+	wsMaxCloseReasonSize = 123
+)
+
+// SubscribeHandlerFunc is a function that contains subscription endpoint logic,
+// it validates the request and starts the subscription, or returns an error.
+type SubscribeHandlerFunc func(
+	r *request.Request,
+	backend access.API,
+	generator models.LinkGenerator,
+) (*Subscription, error)
+
+// Subscription is a stream of response models produced by a subscription endpoint.
+type Subscription struct {
+	// Responses is closed once the subscription ends.
+	Responses <-chan interface{}
+	// Err returns the reason the subscription ended, it must only be called once Responses is closed.
+	Err func() error
+}
+
+// WSHandler is a http handler for subscription endpoints. The request is handled like any other
+// request until the subscription has been started, at which point the connection is upgraded to
+// a websocket connection and each response model is sent to the client as a JSON message.
+type WSHandler struct {
+	*Handler
+	subscribeFunc SubscribeHandlerFunc
+	upgrader      websocket.Upgrader
+}
+
+func NewWSHandler(
+	logger zerolog.Logger,
+	backend access.API,
+	subscribeFunc SubscribeHandlerFunc,
+	generator models.LinkGenerator,
+	chain flow.Chain,
+) *WSHandler {
+	return &WSHandler{
+		Handler:       NewHandler(logger, backend, nil, generator, chain),
+		subscribeFunc: subscribeFunc,
+		upgrader: websocket.Upgrader{
+			// origins are checked by the CORS configuration of the server
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// ServeHTTP starts the subscription, upgrades the connection and streams the subscription
+// responses until either the subscription ends or the client disconnects.
+func (h *WSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	errLog := h.logger.With().Str("request_url", r.URL.String()).Logger()
+
+	err := r.ParseForm()
+	if err != nil {
+		h.errorHandler(w, err, errLog)
+		return
+	}
+
+	// the subscription lives until the client disconnects, which is detected by the read loop
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	decoratedRequest := request.Decorate(r.WithContext(ctx), h.chain)
+
+	sub, err := h.subscribeFunc(decoratedRequest, h.backend, h.linkGenerator)
+	if err != nil {
+		h.errorHandler(w, err, errLog)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader already replied to the client with an http error
+		errLog.Debug().Err(err).Msg("failed to upgrade to websocket connection")
+		return
+	}
+	defer conn.Close()
+
+	go h.readMessages(ctx, cancel, conn)
+
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait))
+			if err != nil {
+				return
+			}
+		case response, ok := <-sub.Responses:
+			if !ok {
+				h.closeConnection(conn, sub.Err(), errLog)
+				return
+			}
+
+			// apply the select filter if any select fields have been specified
+			response, err := util.SelectFilter(response, decoratedRequest.Selects())
+			if err != nil {
+				h.closeConnection(conn, err, errLog)
+				return
+			}
+
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			err = conn.WriteJSON(response)
+			if err != nil {
+				errLog.Debug().Err(err).Msg("failed to write websocket message")
+				return
+			}
+		}
+	}
+}
+
+// readMessages discards all messages sent by the client, keeps track of the pong responses
+// to our pings and cancels the subscription once the client is gone.
+func (h *WSHandler) readMessages(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn) {
+	defer cancel()
+
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for ctx.Err() == nil {
+		_, _, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// closeConnection sends a close message to the client, including the reason the subscription ended.
+func (h *WSHandler) closeConnection(conn *websocket.Conn, err error, errLogger zerolog.Logger) {
+	code := websocket.CloseNormalClosure
+	reason := ""
+	if err != nil {
+		errLogger.Error().Err(err).Msg("subscription ended with error")
+		code = websocket.CloseInternalServerErr
+		reason = err.Error()
+		if len(reason) > wsMaxCloseReasonSize {
+			reason = reason[:wsMaxCloseReasonSize]
+		}
+	}
+
+	msg := websocket.FormatCloseMessage(code, reason)
+	_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsWriteWait))
+}
@@ -14,6 +14,8 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/cmd/build"
+	"github.com/onflow/flow-go/engine"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/model/flow/filter"
@@ -52,6 +54,8 @@ var SnapshotHistoryLimitErr = fmt.Errorf("reached the snapshot history limit")
 // Block Header related calls are handled by backendBlockHeaders.
 // Block details related calls are handled by backendBlockDetails.
 // Event related calls are handled by backendEvents.
+// Event subscriptions are handled by backendStreamEvents.
+// Transaction status subscriptions are handled by backendStreamTransactions.
 // Account related calls are handled by backendAccounts.
 //
 // All remaining calls are handled by the base Backend in this file.
@@ -59,6 +63,8 @@ type Backend struct {
 	backendScripts
 	backendTransactions
 	backendEvents
+	backendStreamEvents
+	backendStreamTransactions
 	backendBlockHeaders
 	backendBlockDetails
 	backendAccounts
@@ -70,6 +76,7 @@ type Backend struct {
 	executionReceipts    storage.ExecutionReceipts
 	connFactory          ConnectionFactory
 	snapshotHistoryLimit int
+	finalizedBlocks      *engine.Broadcaster
 }
 
 func New(
@@ -91,12 +98,15 @@ func New(
 	fixedExecutionNodeIDs []string,
 	log zerolog.Logger,
 	snapshotHistoryLimit int,
+	scriptExecutor ScriptExecutor,
 ) *Backend {
 	retry := newRetry()
 	if retryEnabled {
 		retry.Activate()
 	}
 
+	finalizedBlocks := engine.NewBroadcaster()
+
 	b := &Backend{
 		state: state,
 		// create the sub-backends
@@ -110,6 +120,7 @@ func New(
 				scripts: make(map[[md5.Size]byte]time.Time),
 				lock:    sync.RWMutex{},
 			},
+			scriptExecutor: scriptExecutor,
 		},
 		backendTransactions: backendTransactions{
 			staticCollectionRPC:  collectionRPC,
@@ -134,6 +145,19 @@ func New(
 			log:               log,
 			maxHeightRange:    maxHeightRange,
 		},
+		backendStreamEvents: backendStreamEvents{
+			state:             state,
+			headers:           headers,
+			executionReceipts: executionReceipts,
+			connFactory:       connFactory,
+			log:               log,
+			finalizedBlocks:   finalizedBlocks,
+		},
+		backendStreamTransactions: backendStreamTransactions{
+			executionReceipts: executionReceipts,
+			log:               log,
+			finalizedBlocks:   finalizedBlocks,
+		},
 		backendBlockHeaders: backendBlockHeaders{
 			headers: headers,
 			state:   state,
@@ -157,9 +181,11 @@ func New(
 		connFactory:          connFactory,
 		chainID:              chainID,
 		snapshotHistoryLimit: snapshotHistoryLimit,
+		finalizedBlocks:      finalizedBlocks,
 	}
 
 	retry.SetBackend(b)
+	b.backendStreamTransactions.txBackend = &b.backendTransactions
 
 	var err error
 	preferredENIdentifiers, err = identifierList(preferredExecutionNodeIDs)
@@ -218,6 +244,19 @@ func (b *Backend) Ping(ctx context.Context) error {
 	return nil
 }
 
+// NotifyFinalizedBlockHeight is called whenever a new block is finalized. It triggers the
+// retry of pending transactions and wakes up all active subscriptions.
+func (b *Backend) NotifyFinalizedBlockHeight(height uint64) {
+	b.backendTransactions.NotifyFinalizedBlockHeight(height)
+	b.finalizedBlocks.Publish()
+}
+
+// EnableTransactionSimulation enables simulating transactions with the given executor, which runs them
+// against the locally indexed execution state. Transactions are not simulated unless it is called.
+func (b *Backend) EnableTransactionSimulation(executor ScriptExecutor) {
+	b.backendTransactions.scriptExecutor = executor
+}
+
 func (b *Backend) GetCollectionByID(_ context.Context, colID flow.Identifier) (*flow.LightCollection, error) {
 	// retrieve the collection from the collection storage
 	col, err := b.collections.LightByID(colID)
@@ -239,6 +278,53 @@ func (b *Backend) GetNetworkParameters(_ context.Context) access.NetworkParamete
 	}
 }
 
+// GetNodeVersionInfo returns the version of the node software, along with the spork and protocol version of the
+// protocol state and the height of the root block of the spork.
+func (b *Backend) GetNodeVersionInfo(_ context.Context) (*access.NodeVersionInfo, error) {
+	params := b.state.Params()
+
+	sporkID, err := params.SporkID()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get spork ID: %v", err)
+	}
+
+	protocolVersion, err := params.ProtocolVersion()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get protocol version: %v", err)
+	}
+
+	sporkRootBlockHeight, err := params.SporkRootBlockHeight()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get spork root block height: %v", err)
+	}
+
+	return &access.NodeVersionInfo{
+		Semver:               build.Semver(),
+		Commit:               build.Commit(),
+		SporkID:              sporkID,
+		ProtocolVersion:      protocolVersion,
+		SporkRootBlockHeight: sporkRootBlockHeight,
+	}, nil
+}
+
+// GetLatestEpochInfo returns the counter and phase of the current epoch as of the latest finalized block.
+func (b *Backend) GetLatestEpochInfo(_ context.Context) (*access.EpochInfo, error) {
+	head, err := b.state.Final().Head()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get latest finalized block: %v", err)
+	}
+
+	counter, phase, err := b.getCounterAndPhase(head.Height)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get current epoch: %v", err)
+	}
+
+	return &access.EpochInfo{
+		Counter: counter,
+		Phase:   phase,
+	}, nil
+}
+
 // GetLatestProtocolStateSnapshot returns the latest finalized snapshot
 func (b *Backend) GetLatestProtocolStateSnapshot(_ context.Context) ([]byte, error) {
 	snapshot := b.state.Final()
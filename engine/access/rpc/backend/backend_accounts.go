@@ -5,12 +5,15 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
 	execproto "github.com/onflow/flow/protobuf/go/flow/execution"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	execextended "github.com/onflow/flow-go/engine/execution/rpc/extended"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/state/protocol"
 	"github.com/onflow/flow-go/storage"
@@ -102,6 +105,180 @@ func (b *backendAccounts) getAccountAtBlockID(
 	return account, nil
 }
 
+// GetAccountsAtLatestBlock gets the accounts with the given addresses at the latest sealed block. Errors which only
+// affect some of the accounts are reported in the result of each account.
+func (b *backendAccounts) GetAccountsAtLatestBlock(
+	ctx context.Context,
+	addresses []flow.Address,
+) ([]access.AccountResult, error) {
+	latestHeader, err := b.state.Sealed().Head()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get latest sealed header: %v", err)
+	}
+
+	return b.getAccountsAtBlockID(ctx, addresses, latestHeader.ID())
+}
+
+// GetAccountsAtBlockHeight gets the accounts with the given addresses at the given height. Errors which only affect
+// some of the accounts are reported in the result of each account.
+func (b *backendAccounts) GetAccountsAtBlockHeight(
+	ctx context.Context,
+	addresses []flow.Address,
+	height uint64,
+) ([]access.AccountResult, error) {
+	header, err := b.headers.ByHeight(height)
+	if err != nil {
+		err = convertStorageError(err)
+		return nil, err
+	}
+
+	return b.getAccountsAtBlockID(ctx, addresses, header.ID())
+}
+
+// getAccountsAtBlockID resolves the execution nodes for the block once and gets all accounts from the first of them,
+// with a single request. Accounts which could not be retrieved from an execution node are requested from the next.
+func (b *backendAccounts) getAccountsAtBlockID(
+	ctx context.Context,
+	addresses []flow.Address,
+	blockID flow.Identifier,
+) ([]access.AccountResult, error) {
+	if len(addresses) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "no addresses requested")
+	}
+	if len(addresses) > access.MaxAccountsPerRequest {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d accounts can be requested at a time", access.MaxAccountsPerRequest)
+	}
+
+	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
+	if err != nil {
+		return nil, getAccountError(err)
+	}
+
+	results := make([]access.AccountResult, len(addresses))
+	errors := make([]*multierror.Error, len(addresses))
+	pending := make([]int, len(addresses)) // indices of the accounts not retrieved yet
+	for i, address := range addresses {
+		results[i].Address = address
+		pending[i] = i
+	}
+
+	for _, execNode := range execNodes {
+		pending = b.tryGetAccounts(ctx, execNode, blockID, results, errors, pending)
+		if len(pending) == 0 {
+			break
+		}
+	}
+
+	for _, i := range pending {
+		results[i].Err = accountErrors(errors[i])
+	}
+
+	return results, nil
+}
+
+// tryGetAccounts gets the pending accounts from the execution node with a single request, and returns the indices of
+// the accounts which could not be retrieved.
+func (b *backendAccounts) tryGetAccounts(
+	ctx context.Context,
+	execNode *flow.Identity,
+	blockID flow.Identifier,
+	results []access.AccountResult,
+	errors []*multierror.Error,
+	pending []int,
+) []int {
+	start := time.Now()
+
+	failAll := func(err error) []int {
+		for _, i := range pending {
+			errors[i] = multierror.Append(errors[i], err)
+		}
+		return pending
+	}
+
+	execRPCClient, closer, err := b.connFactory.GetExtendedExecutionAPIClient(execNode.Address)
+	if err != nil {
+		return failAll(err)
+	}
+	defer closer.Close()
+
+	req := &execextended.GetAccountsAtBlockIDRequest{
+		BlockId:   blockID[:],
+		Addresses: make([][]byte, len(pending)),
+	}
+	for j, i := range pending {
+		req.Addresses[j] = results[i].Address.Bytes()
+	}
+
+	resp, err := execRPCClient.GetAccountsAtBlockID(ctx, req)
+	if err != nil {
+		b.log.Error().
+			Str("execution_node", execNode.String()).
+			Hex("block_id", blockID[:]).
+			Err(err).
+			Msg("failed to get batch of accounts")
+		return failAll(err)
+	}
+	if len(resp.GetResults()) != len(pending) {
+		return failAll(status.Errorf(codes.Internal, "execution node returned %d accounts for %d addresses", len(resp.GetResults()), len(pending)))
+	}
+
+	var remaining []int
+	for j, i := range pending {
+		result := resp.GetResults()[j]
+		if result.GetAccount() == nil {
+			code := codes.Code(result.GetErrorCode())
+			if code == codes.OK {
+				code = codes.Internal
+			}
+			errors[i] = multierror.Append(errors[i], status.Error(code, result.GetErrorMessage()))
+			remaining = append(remaining, i)
+			continue
+		}
+
+		account, err := extendedMessageToAccount(result.GetAccount())
+		if err != nil {
+			errors[i] = multierror.Append(errors[i], status.Errorf(codes.Internal, "failed to convert account message: %v", err))
+			remaining = append(remaining, i)
+			continue
+		}
+		results[i].Account = account
+	}
+
+	b.log.Debug().
+		Str("execution_node", execNode.String()).
+		Hex("block_id", blockID[:]).
+		Int("requested", len(pending)).
+		Int("failed", len(remaining)).
+		Int64("rtt_ms", time.Since(start).Milliseconds()).
+		Msg("got batch of accounts")
+
+	return remaining
+}
+
+// extendedMessageToAccount converts an account message of the ExtendedExecutionAPI to an account, the same way
+// accounts of the Flow Execution API are converted.
+func extendedMessageToAccount(m *execextended.Account) (*flow.Account, error) {
+	keys := make([]*entities.AccountKey, len(m.GetKeys()))
+	for i, k := range m.GetKeys() {
+		keys[i] = &entities.AccountKey{
+			Index:          k.GetIndex(),
+			PublicKey:      k.GetPublicKey(),
+			SignAlgo:       k.GetSignAlgo(),
+			HashAlgo:       k.GetHashAlgo(),
+			Weight:         k.GetWeight(),
+			SequenceNumber: k.GetSequenceNumber(),
+			Revoked:        k.GetRevoked(),
+		}
+	}
+
+	return convert.MessageToAccount(&entities.Account{
+		Address:   m.GetAddress(),
+		Balance:   m.GetBalance(),
+		Keys:      keys,
+		Contracts: m.GetContracts(),
+	})
+}
+
 func getAccountError(err error) error {
 	errStatus, _ := status.FromError(err)
 	if errStatus.Code() == codes.NotFound {
@@ -138,18 +315,24 @@ func (b *backendAccounts) getAccountFromAnyExeNode(ctx context.Context, execNode
 		errors = multierror.Append(errors, err)
 	}
 	// if we made it till here means there was at least one error
+	return nil, accountErrors(errors)
+}
+
+// accountErrors combines the errors returned by the execution nodes for an account. The combined error is
+// codes.NotFound if all execution nodes failed to find the account, and codes.Internal otherwise.
+func accountErrors(errors *multierror.Error) error {
 	errToReturn := errors.ErrorOrNil()
 
 	// if there were an any errors other than codes.NotFound, return those
-	for _, err := range errors.Errors {
+	for _, err := range errors.WrappedErrors() {
 		errStatus, _ := status.FromError(err)
 		if errStatus.Code() != codes.NotFound {
-			return nil, status.Errorf(codes.Internal, "failed to get account from the execution node: %v", errToReturn)
+			return status.Errorf(codes.Internal, "failed to get account from the execution node: %v", errToReturn)
 		}
 	}
 
 	// if all errors were codes.NotFound, then return a codes.NotFound error wrapping all those errors
-	return nil, status.Errorf(codes.NotFound, "failed to get account from the execution node: %v", errToReturn)
+	return status.Errorf(codes.NotFound, "failed to get account from the execution node: %v", errToReturn)
 }
 
 func (b *backendAccounts) tryGetAccount(ctx context.Context, execNode *flow.Identity, req execproto.GetAccountAtBlockIDRequest) (*execproto.GetAccountAtBlockIDResponse, error) {
@@ -5,13 +5,16 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/hashicorp/go-multierror"
 	execproto "github.com/onflow/flow/protobuf/go/flow/execution"
 	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/onflow/flow-go/access"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/state/protocol"
@@ -35,6 +38,61 @@ func (b *backendEvents) GetEventsForHeightRange(
 	startHeight, endHeight uint64,
 ) ([]flow.BlockEvents, error) {
 
+	blockHeaders, err := b.headersForHeightRange(startHeight, endHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.getBlockEventsFromExecutionNode(ctx, blockHeaders, eventType)
+}
+
+// QueryEventsForHeightRange retrieves the events matching the query for all sealed blocks between the start block
+// height and the end block height (inclusive).
+func (b *backendEvents) QueryEventsForHeightRange(
+	ctx context.Context,
+	query access.EventQuery,
+	startHeight, endHeight uint64,
+) ([]flow.BlockEvents, error) {
+	blockHeaders, err := b.headersForHeightRange(startHeight, endHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.queryBlockEvents(ctx, blockHeaders, query)
+}
+
+// GetEventsForBlockIDs retrieves events for all the specified block IDs that have the given type
+func (b *backendEvents) GetEventsForBlockIDs(
+	ctx context.Context,
+	eventType string,
+	blockIDs []flow.Identifier,
+) ([]flow.BlockEvents, error) {
+
+	blockHeaders, err := b.headersForBlockIDs(blockIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// forward the request to the execution node
+	return b.getBlockEventsFromExecutionNode(ctx, blockHeaders, eventType)
+}
+
+// QueryEventsForBlockIDs retrieves the events matching the query for all the specified block IDs.
+func (b *backendEvents) QueryEventsForBlockIDs(
+	ctx context.Context,
+	query access.EventQuery,
+	blockIDs []flow.Identifier,
+) ([]flow.BlockEvents, error) {
+	blockHeaders, err := b.headersForBlockIDs(blockIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.queryBlockEvents(ctx, blockHeaders, query)
+}
+
+// headersForHeightRange returns the headers of the sealed blocks between the start and end height (inclusive).
+func (b *backendEvents) headersForHeightRange(startHeight, endHeight uint64) ([]*flow.Header, error) {
 	if endHeight < startHeight {
 		return nil, status.Error(codes.InvalidArgument, "invalid start or end height")
 	}
@@ -73,16 +131,11 @@ func (b *backendEvents) GetEventsForHeightRange(
 		blockHeaders = append(blockHeaders, header)
 	}
 
-	return b.getBlockEventsFromExecutionNode(ctx, blockHeaders, eventType)
+	return blockHeaders, nil
 }
 
-// GetEventsForBlockIDs retrieves events for all the specified block IDs that have the given type
-func (b *backendEvents) GetEventsForBlockIDs(
-	ctx context.Context,
-	eventType string,
-	blockIDs []flow.Identifier,
-) ([]flow.BlockEvents, error) {
-
+// headersForBlockIDs returns the headers of the given blocks.
+func (b *backendEvents) headersForBlockIDs(blockIDs []flow.Identifier) ([]*flow.Header, error) {
 	if uint(len(blockIDs)) > b.maxHeightRange {
 		return nil, fmt.Errorf("requested block range (%d) exceeded maximum (%d)", len(blockIDs), b.maxHeightRange)
 	}
@@ -98,8 +151,87 @@ func (b *backendEvents) GetEventsForBlockIDs(
 		blockHeaders = append(blockHeaders, header)
 	}
 
-	// forward the request to the execution node
-	return b.getBlockEventsFromExecutionNode(ctx, blockHeaders, eventType)
+	return blockHeaders, nil
+}
+
+// queryBlockEvents retrieves the events of each type of the query from the execution nodes concurrently and merges
+// them by block, in the order they were emitted. The events are then filtered by the field filters of the query.
+func (b *backendEvents) queryBlockEvents(
+	ctx context.Context,
+	blockHeaders []*flow.Header,
+	query access.EventQuery,
+) ([]flow.BlockEvents, error) {
+	if len(query.EventTypes) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one event type must be provided")
+	}
+	if len(query.EventTypes) > access.MaxEventTypesPerQuery {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d event types can be queried at a time", access.MaxEventTypesPerQuery)
+	}
+
+	eventTypes := make([]flow.EventType, 0, len(query.EventTypes))
+	queried := make(map[flow.EventType]bool, len(query.EventTypes))
+	for _, eventType := range query.EventTypes {
+		if queried[eventType] {
+			continue
+		}
+		queried[eventType] = true
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	typeResults := make([][]flow.BlockEvents, len(eventTypes))
+	group, groupCtx := errgroup.WithContext(ctx)
+	for i, eventType := range eventTypes {
+		i, eventType := i, eventType
+		group.Go(func() error {
+			var err error
+			typeResults[i], err = b.getBlockEventsFromExecutionNode(groupCtx, blockHeaders, string(eventType))
+			return err
+		})
+	}
+	err := group.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	results := typeResults[0]
+	for _, other := range typeResults[1:] {
+		err = mergeBlockEvents(results, other)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to merge events: %v", err)
+		}
+	}
+
+	for i := range results {
+		events := results[i].Events
+		if len(eventTypes) > 1 {
+			sort.SliceStable(events, func(i, j int) bool {
+				if events[i].TransactionIndex != events[j].TransactionIndex {
+					return events[i].TransactionIndex < events[j].TransactionIndex
+				}
+				return events[i].EventIndex < events[j].EventIndex
+			})
+		}
+		results[i].Events = query.FilterFields(events)
+	}
+
+	return results, nil
+}
+
+// mergeBlockEvents appends the events of each block in other to the events of the same block in results.
+func mergeBlockEvents(results []flow.BlockEvents, other []flow.BlockEvents) error {
+	indices := make(map[flow.Identifier]int, len(results))
+	for i, result := range results {
+		indices[result.BlockID] = i
+	}
+
+	for _, blockEvents := range other {
+		i, ok := indices[blockEvents.BlockID]
+		if !ok {
+			return fmt.Errorf("unexpected block %v", blockEvents.BlockID)
+		}
+		results[i].Events = append(results[i].Events, blockEvents.Events...)
+	}
+	return nil
 }
 
 func (b *backendEvents) getBlockEventsFromExecutionNode(
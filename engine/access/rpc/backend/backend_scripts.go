@@ -3,6 +3,7 @@ package backend
 import (
 	"context"
 	"crypto/md5" //nolint:gosec
+	"errors"
 	"sync"
 	"time"
 
@@ -12,6 +13,8 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/index"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/state/protocol"
 	"github.com/onflow/flow-go/storage"
@@ -20,6 +23,18 @@ import (
 // uniqueScriptLoggingTimeWindow is the duration for checking the uniqueness of scripts sent for execution
 const uniqueScriptLoggingTimeWindow = 10 * time.Minute
 
+// ScriptExecutor executes scripts and simulates transactions locally against the execution state at a block height.
+type ScriptExecutor interface {
+	// ExecuteAtBlockHeight executes the script at the given height and returns the JSON-CDC encoded value.
+	// It returns index.ErrHeightNotIndexed if the execution state is not available at the height, and an error
+	// wrapping index.ErrScriptFailed if the script failed.
+	ExecuteAtBlockHeight(ctx context.Context, script []byte, arguments [][]byte, height uint64) ([]byte, error)
+
+	// SimulateAtBlockHeight runs the transaction at the given height without committing its state changes.
+	// It returns index.ErrHeightNotIndexed if the execution state is not available at the height.
+	SimulateAtBlockHeight(ctx context.Context, tx *flow.TransactionBody, height uint64) (*access.TransactionSimulationResult, error)
+}
+
 type backendScripts struct {
 	headers           storage.Headers
 	executionReceipts storage.ExecutionReceipts
@@ -27,6 +42,7 @@ type backendScripts struct {
 	connFactory       ConnectionFactory
 	log               zerolog.Logger
 	seenScripts       *scriptMap
+	scriptExecutor    ScriptExecutor // executes scripts locally if set, scripts are only sent to execution nodes if nil
 }
 
 type scriptMap struct {
@@ -59,11 +75,7 @@ func (b *backendScripts) ExecuteScriptAtLatestBlock(
 		return nil, status.Errorf(codes.Internal, "failed to get latest sealed header: %v", err)
 	}
 
-	// get the block id of the latest sealed header
-	latestBlockID := latestHeader.ID()
-
-	// execute script on the execution node at that block id
-	return b.executeScriptOnExecutionNode(ctx, latestBlockID, script, arguments)
+	return b.executeScript(ctx, latestHeader, script, arguments)
 }
 
 func (b *backendScripts) ExecuteScriptAtBlockID(
@@ -72,8 +84,18 @@ func (b *backendScripts) ExecuteScriptAtBlockID(
 	script []byte,
 	arguments [][]byte,
 ) ([]byte, error) {
-	// execute script on the execution node at that block id
-	return b.executeScriptOnExecutionNode(ctx, blockID, script, arguments)
+	if b.scriptExecutor == nil {
+		// execute script on the execution node at that block id
+		return b.executeScriptOnExecutionNode(ctx, blockID, script, arguments)
+	}
+
+	header, err := b.headers.ByBlockID(blockID)
+	if err != nil {
+		err = convertStorageError(err)
+		return nil, err
+	}
+
+	return b.executeScript(ctx, header, script, arguments)
 }
 
 func (b *backendScripts) ExecuteScriptAtBlockHeight(
@@ -89,8 +111,36 @@ func (b *backendScripts) ExecuteScriptAtBlockHeight(
 		return nil, err
 	}
 
+	return b.executeScript(ctx, header, script, arguments)
+}
+
+// executeScript executes the script locally if the execution state at the given block is indexed, and otherwise
+// forwards the script to the execution nodes
+func (b *backendScripts) executeScript(
+	ctx context.Context,
+	header *flow.Header,
+	script []byte,
+	arguments [][]byte,
+) ([]byte, error) {
 	blockID := header.ID()
 
+	if b.scriptExecutor != nil {
+		result, err := b.scriptExecutor.ExecuteAtBlockHeight(ctx, script, arguments, header.Height)
+		switch {
+		case err == nil:
+			return result, nil
+		case errors.Is(err, index.ErrScriptFailed):
+			return nil, status.Errorf(codes.InvalidArgument, "failed to execute script: %v", err)
+		case errors.Is(err, index.ErrHeightNotIndexed):
+			b.log.Debug().
+				Hex("block_id", blockID[:]).
+				Uint64("height", header.Height).
+				Msg("execution state not indexed, forwarding script to execution nodes")
+		default:
+			return nil, status.Errorf(codes.Internal, "failed to execute script locally: %v", err)
+		}
+	}
+
 	// execute script on the execution node at that block id
 	return b.executeScriptOnExecutionNode(ctx, blockID, script, arguments)
 }
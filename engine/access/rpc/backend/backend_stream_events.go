@@ -0,0 +1,271 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	execproto "github.com/onflow/flow/protobuf/go/flow/execution"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol"
+	"github.com/onflow/flow-go/storage"
+)
+
+// subscriptionBufferSize is the number of responses buffered for each subscriber before
+// the streaming routine waits for the subscriber to catch up.
+const subscriptionBufferSize = 100
+
+// maxEventsRetryDelay is the maximum delay before the events of a block are requested from the execution
+// nodes again, after they failed to return them.
+const maxEventsRetryDelay = 5 * time.Second
+
+// errBlockNotExecuted indicates that no execution receipt has been received for a block yet.
+var errBlockNotExecuted = errors.New("block has not been executed yet")
+
+type backendStreamEvents struct {
+	headers           storage.Headers
+	executionReceipts storage.ExecutionReceipts
+	state             protocol.State
+	connFactory       ConnectionFactory
+	log               zerolog.Logger
+	finalizedBlocks   *engine.Broadcaster
+}
+
+// SubscribeEvents streams the events matching the filter block by block, starting at the given height.
+// If isSealed is true, only sealed blocks are streamed, otherwise finalized blocks are streamed as soon
+// as they have been executed. A start height of 0 starts the stream at the block after the latest
+// sealed or finalized block respectively.
+func (b *backendStreamEvents) SubscribeEvents(
+	ctx context.Context,
+	startHeight uint64,
+	isSealed bool,
+	filter access.EventFilter,
+) (access.EventsSubscription, error) {
+
+	head, err := b.latestHeader(isSealed)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to subscribe to events: %v", err)
+	}
+
+	root, err := b.state.Params().Root()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to subscribe to events: %v", err)
+	}
+
+	if startHeight == 0 {
+		startHeight = head.Height + 1
+	}
+
+	if startHeight < root.Height {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"start height %d is lower than the root block height %d", startHeight, root.Height)
+	}
+
+	sub := &eventsSubscription{
+		ch: make(chan flow.BlockEvents, subscriptionBufferSize),
+	}
+
+	notifier := b.finalizedBlocks.Subscribe()
+	go b.streamEvents(ctx, sub, notifier, startHeight, isSealed, filter)
+
+	return sub, nil
+}
+
+// streamEvents delivers the events of each block from the given height onwards to the subscription,
+// until the context is cancelled or an unrecoverable error occurs. It waits for the next finalized
+// block whenever it has caught up with the chain, or the next block has not been executed yet.
+// If the execution nodes fail to return the events of a block, they are requested again from
+// randomly chosen execution nodes after an exponential backoff.
+func (b *backendStreamEvents) streamEvents(
+	ctx context.Context,
+	sub *eventsSubscription,
+	notifier engine.Notifier,
+	next uint64,
+	isSealed bool,
+	filter access.EventFilter,
+) {
+	defer close(sub.ch)
+	defer b.finalizedBlocks.Unsubscribe(notifier)
+
+	// attempt is the number of consecutive failed requests for the events of the next block
+	attempt := 0
+
+	for {
+		retry := false
+
+		head, err := b.latestHeader(isSealed)
+		if err != nil {
+			sub.err = status.Errorf(codes.Internal, "failed to get latest block: %v", err)
+			return
+		}
+
+		for ; next <= head.Height; next++ {
+			header, err := b.headers.ByHeight(next)
+			if err != nil {
+				sub.err = status.Errorf(codes.Internal, "failed to get block header at height %d: %v", next, err)
+				return
+			}
+
+			events, err := b.getAllBlockEvents(ctx, header, isSealed)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if errors.Is(err, errBlockNotExecuted) {
+					// retry once the next block is finalized, as execution receipts take a while
+					b.log.Debug().
+						Uint64("height", next).
+						Msg("block has not been executed yet, retrying on next finalized block")
+					break
+				}
+				// the execution nodes may be temporarily unavailable, retry with other execution nodes
+				b.log.Debug().Err(err).
+					Uint64("height", next).
+					Int("attempt", attempt).
+					Msg("could not get events for block, retrying")
+				retry = true
+				break
+			}
+			attempt = 0
+
+			select {
+			case <-ctx.Done():
+				return
+			case sub.ch <- flow.BlockEvents{
+				BlockID:        header.ID(),
+				BlockHeight:    header.Height,
+				BlockTimestamp: header.Timestamp,
+				Events:         filter.Filter(events),
+			}:
+			}
+		}
+
+		if retry {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(eventsRetryDelay(attempt)):
+			}
+			attempt++
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-notifier.Channel():
+		}
+	}
+}
+
+// eventsRetryDelay returns the delay before the given retry of a request for the events of a block,
+// which grows exponentially from 100 milliseconds up to maxEventsRetryDelay.
+func eventsRetryDelay(attempt int) time.Duration {
+	if attempt > 6 {
+		return maxEventsRetryDelay
+	}
+	delay := 100 * time.Millisecond << time.Duration(attempt)
+	if delay > maxEventsRetryDelay {
+		return maxEventsRetryDelay
+	}
+	return delay
+}
+
+func (b *backendStreamEvents) latestHeader(isSealed bool) (*flow.Header, error) {
+	if isSealed {
+		return b.state.Sealed().Head()
+	}
+	return b.state.Final().Head()
+}
+
+// getAllBlockEvents retrieves all events emitted in the given block from an execution node.
+func (b *backendStreamEvents) getAllBlockEvents(
+	ctx context.Context,
+	header *flow.Header,
+	isSealed bool,
+) ([]flow.Event, error) {
+	blockID := header.ID()
+
+	// sealed blocks have always been executed, but finalized blocks might not be yet
+	if !isSealed {
+		receipts, err := b.executionReceipts.ByBlockID(blockID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve execution receipts for block ID %v: %w", blockID, err)
+		}
+		if len(receipts) == 0 {
+			return nil, errBlockNotExecuted
+		}
+	}
+
+	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find execution nodes for block ID %v: %w", blockID, err)
+	}
+
+	req := execproto.GetTransactionsByBlockIDRequest{
+		BlockId: blockID[:],
+	}
+	resp, err := b.getTransactionResultsFromAnyExeNode(ctx, execNodes, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve events from execution nodes %s: %w", execNodes, err)
+	}
+
+	var events []flow.Event
+	for _, result := range resp.GetTransactionResults() {
+		events = append(events, convert.MessagesToEvents(result.GetEvents())...)
+	}
+
+	return events, nil
+}
+
+func (b *backendStreamEvents) getTransactionResultsFromAnyExeNode(
+	ctx context.Context,
+	execNodes flow.IdentityList,
+	req execproto.GetTransactionsByBlockIDRequest,
+) (*execproto.GetTransactionResultsResponse, error) {
+	var errs *multierror.Error
+	for _, execNode := range execNodes {
+		resp, err := b.tryGetTransactionResults(ctx, execNode, req)
+		if err == nil {
+			return resp, nil
+		}
+		errs = multierror.Append(errs, err)
+	}
+	return nil, errs.ErrorOrNil()
+}
+
+func (b *backendStreamEvents) tryGetTransactionResults(
+	ctx context.Context,
+	execNode *flow.Identity,
+	req execproto.GetTransactionsByBlockIDRequest,
+) (*execproto.GetTransactionResultsResponse, error) {
+	execRPCClient, closer, err := b.connFactory.GetExecutionAPIClient(execNode.Address)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return execRPCClient.GetTransactionResultsByBlockID(ctx, &req)
+}
+
+// eventsSubscription implements access.EventsSubscription.
+type eventsSubscription struct {
+	ch  chan flow.BlockEvents
+	err error
+}
+
+func (s *eventsSubscription) Channel() <-chan flow.BlockEvents {
+	return s.ch
+}
+
+func (s *eventsSubscription) Err() error {
+	return s.err
+}
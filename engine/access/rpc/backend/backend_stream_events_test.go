@@ -0,0 +1,285 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	execproto "github.com/onflow/flow/protobuf/go/flow/execution"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	protocol "github.com/onflow/flow-go/state/protocol/mock"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func (suite *Suite) TestSubscribeEvents() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const startHeight uint64 = 5
+	const endHeight uint64 = 7
+
+	var head *flow.Header
+	var nodeIdentities flow.IdentityList
+	headersDB := make(map[uint64]*flow.Header)
+	eventsDB := make(map[flow.Identifier][]flow.Event)
+
+	state := new(protocol.State)
+	snapshot := new(protocol.Snapshot)
+	state.On("Sealed").Return(snapshot, nil)
+	state.On("Final").Return(snapshot, nil)
+
+	rootHeader := unittest.BlockHeaderFixture()
+	rootHeader.Height = 0
+	params := new(protocol.Params)
+	params.On("Root").Return(&rootHeader, nil)
+	state.On("Params").Return(params)
+
+	snapshot.On("Head").Return(
+		func() *flow.Header { return head },
+		func() error { return nil },
+	)
+	snapshot.On("Identities", mock.Anything).Return(
+		func(flow.IdentityFilter) flow.IdentityList { return nodeIdentities },
+		func(flow.IdentityFilter) error { return nil },
+	)
+
+	suite.headers.On("ByHeight", mock.Anything).Return(
+		func(height uint64) *flow.Header { return headersDB[height] },
+		func(height uint64) error {
+			if _, ok := headersDB[height]; !ok {
+				return storage.ErrNotFound
+			}
+			return nil
+		})
+
+	for height := startHeight; height <= endHeight; height++ {
+		block := unittest.BlockFixture()
+		block.Header.Height = height
+		headersDB[height] = block.Header
+
+		receipts := make(flow.ExecutionReceiptList, 2)
+		for i := range receipts {
+			executor := &flow.Identity{NodeID: unittest.IdentifierFixture(), Role: flow.RoleExecution}
+			nodeIdentities = append(nodeIdentities, executor)
+			receipts[i] = unittest.ReceiptForBlockFixture(&block)
+			receipts[i].ExecutorID = executor.NodeID
+		}
+		receipts[1].ExecutionResult = receipts[0].ExecutionResult
+		suite.receipts.On("ByBlockID", block.ID()).Return(receipts, nil)
+
+		events := []flow.Event{
+			unittest.EventFixture(flow.EventAccountCreated, 0, 0, unittest.IdentifierFixture(), 0),
+			unittest.EventFixture("A.f8d6e0586b0a20c7.Foo.Bar", 0, 1, unittest.IdentifierFixture(), 0),
+		}
+		eventsDB[block.ID()] = events
+
+		blockID := block.ID()
+		suite.execClient.
+			On("GetTransactionResultsByBlockID", mock.Anything, &execproto.GetTransactionsByBlockIDRequest{BlockId: blockID[:]}).
+			Return(&execproto.GetTransactionResultsResponse{
+				TransactionResults: []*execproto.GetTransactionResultResponse{
+					{Events: convert.EventsToMessages(events)},
+				},
+			}, nil)
+	}
+
+	head = headersDB[startHeight]
+
+	backend := New(
+		state,
+		nil,
+		nil,
+		nil,
+		suite.headers,
+		nil,
+		nil,
+		suite.receipts,
+		nil,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		suite.setupConnectionFactory(),
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		nil,
+	)
+
+	filter := access.EventFilter{EventTypes: []flow.EventType{"A.f8d6e0586b0a20c7.Foo.Bar"}}
+	sub, err := backend.SubscribeEvents(ctx, startHeight, true, filter)
+	suite.Require().NoError(err)
+
+	receive := func(height uint64) {
+		select {
+		case blockEvents := <-sub.Channel():
+			header := headersDB[height]
+			suite.Require().Equal(header.ID(), blockEvents.BlockID)
+			suite.Require().Equal(height, blockEvents.BlockHeight)
+			suite.Require().Equal([]flow.Event{eventsDB[header.ID()][1]}, blockEvents.Events)
+		case <-time.After(time.Second):
+			suite.FailNow("timed out waiting for block events", "height %d", height)
+		}
+	}
+
+	// the block at the start height is already sealed
+	receive(startHeight)
+
+	// the following blocks are delivered once they become sealed
+	head = headersDB[endHeight]
+	backend.NotifyFinalizedBlockHeight(endHeight)
+	for height := startHeight + 1; height <= endHeight; height++ {
+		receive(height)
+	}
+
+	// the subscription ends without error once the context is cancelled
+	cancel()
+	select {
+	case _, ok := <-sub.Channel():
+		suite.Require().False(ok)
+	case <-time.After(time.Second):
+		suite.FailNow("timed out waiting for the subscription to end")
+	}
+	suite.Require().NoError(sub.Err())
+}
+
+// TestSubscribeEvents_ExecutionNodeError tests that the events of a block are requested again without
+// waiting for the next finalized block, if the execution nodes fail to return them.
+func (suite *Suite) TestSubscribeEvents_ExecutionNodeError() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	block := unittest.BlockFixture()
+	block.Header.Height = 5
+	blockID := block.ID()
+
+	state := new(protocol.State)
+	snapshot := new(protocol.Snapshot)
+	state.On("Sealed").Return(snapshot, nil)
+	state.On("Final").Return(snapshot, nil)
+
+	rootHeader := unittest.BlockHeaderFixture()
+	rootHeader.Height = 0
+	params := new(protocol.Params)
+	params.On("Root").Return(&rootHeader, nil)
+	state.On("Params").Return(params)
+	snapshot.On("Head").Return(block.Header, nil)
+
+	receipts := make(flow.ExecutionReceiptList, 2)
+	var nodeIdentities flow.IdentityList
+	for i := range receipts {
+		executor := &flow.Identity{NodeID: unittest.IdentifierFixture(), Role: flow.RoleExecution}
+		nodeIdentities = append(nodeIdentities, executor)
+		receipts[i] = unittest.ReceiptForBlockFixture(&block)
+		receipts[i].ExecutorID = executor.NodeID
+	}
+	receipts[1].ExecutionResult = receipts[0].ExecutionResult
+	snapshot.On("Identities", mock.Anything).Return(nodeIdentities, nil)
+	suite.receipts.On("ByBlockID", blockID).Return(receipts, nil)
+	suite.headers.On("ByHeight", block.Header.Height).Return(block.Header, nil)
+
+	events := []flow.Event{
+		unittest.EventFixture(flow.EventAccountCreated, 0, 0, unittest.IdentifierFixture(), 0),
+	}
+	req := &execproto.GetTransactionsByBlockIDRequest{BlockId: blockID[:]}
+	// both execution nodes fail the first request
+	suite.execClient.
+		On("GetTransactionResultsByBlockID", mock.Anything, req).
+		Return(nil, status.Error(codes.Unavailable, "execution node unavailable")).
+		Times(2)
+	suite.execClient.
+		On("GetTransactionResultsByBlockID", mock.Anything, req).
+		Return(&execproto.GetTransactionResultsResponse{
+			TransactionResults: []*execproto.GetTransactionResultResponse{
+				{Events: convert.EventsToMessages(events)},
+			},
+		}, nil)
+
+	backend := New(
+		state,
+		nil,
+		nil,
+		nil,
+		suite.headers,
+		nil,
+		nil,
+		suite.receipts,
+		nil,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		suite.setupConnectionFactory(),
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		nil,
+	)
+
+	sub, err := backend.SubscribeEvents(ctx, block.Header.Height, true, access.EventFilter{})
+	suite.Require().NoError(err)
+
+	// no block is finalized in the meantime
+	select {
+	case blockEvents := <-sub.Channel():
+		suite.Require().Equal(blockID, blockEvents.BlockID)
+		suite.Require().Equal(events, blockEvents.Events)
+	case <-time.After(time.Second):
+		suite.FailNow("timed out waiting for block events")
+	}
+}
+
+func (suite *Suite) TestEventsRetryDelay() {
+	suite.Require().Equal(100*time.Millisecond, eventsRetryDelay(0))
+	suite.Require().Equal(200*time.Millisecond, eventsRetryDelay(1))
+	suite.Require().Equal(3200*time.Millisecond, eventsRetryDelay(5))
+	suite.Require().Equal(maxEventsRetryDelay, eventsRetryDelay(6))
+	suite.Require().Equal(maxEventsRetryDelay, eventsRetryDelay(100))
+}
+
+func (suite *Suite) TestSubscribeEvents_StartHeightBelowRoot() {
+	state := new(protocol.State)
+	snapshot := new(protocol.Snapshot)
+	state.On("Sealed").Return(snapshot, nil)
+
+	rootHeader := unittest.BlockHeaderFixture()
+	rootHeader.Height = 100
+	params := new(protocol.Params)
+	params.On("Root").Return(&rootHeader, nil)
+	state.On("Params").Return(params)
+	snapshot.On("Head").Return(&rootHeader, nil)
+
+	backend := New(
+		state,
+		nil,
+		nil,
+		nil,
+		suite.headers,
+		nil,
+		nil,
+		suite.receipts,
+		nil,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		nil,
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		nil,
+	)
+
+	_, err := backend.SubscribeEvents(context.Background(), 10, true, access.EventFilter{})
+	suite.Require().Error(err)
+}
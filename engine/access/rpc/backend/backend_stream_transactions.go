@@ -0,0 +1,264 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// backendStreamTransactions streams transaction status transitions. The status of a transaction is
+// derived from the local protocol state and storage each time a block is finalized, the execution
+// nodes are only queried once for the execution outcome of the transaction.
+type backendStreamTransactions struct {
+	txBackend         *backendTransactions
+	executionReceipts storage.ExecutionReceipts
+	log               zerolog.Logger
+	finalizedBlocks   *engine.Broadcaster
+}
+
+// SubscribeTransactionStatus streams each status transition of the given transaction, until the
+// transaction is either sealed or expired.
+//
+// Like GetTransactionResult, transactions which are not known by this node are looked up on the
+// historical access nodes, in which case their result is the only update of the subscription.
+// Otherwise, the transaction may have been submitted to another node, and is looked up again each
+// time a block is finalized, until it is known or it would have expired.
+func (b *backendStreamTransactions) SubscribeTransactionStatus(
+	ctx context.Context,
+	txID flow.Identifier,
+) (access.TransactionStatusSubscription, error) {
+	tx, err := b.lookupTransaction(txID)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &transactionStatusSubscription{
+		// a transaction goes through at most 4 status transitions
+		ch: make(chan *access.TransactionResult, 4),
+	}
+
+	if tx == nil {
+		historicalResult, err := b.txBackend.getHistoricalTransactionResult(ctx, txID)
+		if err == nil {
+			historicalResult.TransactionID = txID
+			sub.ch <- historicalResult
+			close(sub.ch)
+			return sub, nil
+		}
+	}
+
+	notifier := b.finalizedBlocks.Subscribe()
+	go b.streamTransactionStatus(ctx, sub, notifier, txID, tx)
+
+	return sub, nil
+}
+
+// streamTransactionStatus delivers the status transitions of the transaction to the subscription,
+// re-evaluating the status each time a block is finalized. The transaction is nil if it's not known
+// yet, in which case it's looked up until it's known, for at most flow.DefaultTransactionExpiry
+// blocks after the block that was finalized when the subscription started.
+func (b *backendStreamTransactions) streamTransactionStatus(
+	ctx context.Context,
+	sub *transactionStatusSubscription,
+	notifier engine.Notifier,
+	txID flow.Identifier,
+	tx *flow.TransactionBody,
+) {
+	defer close(sub.ch)
+	defer b.finalizedBlocks.Unsubscribe(notifier)
+
+	lastStatus := flow.TransactionStatusUnknown
+
+	// executionResult is the execution outcome of the transaction, once it has been retrieved
+	var executionResult *access.TransactionResult
+
+	// unknownSince is the finalized height when the unknown transaction was first looked up
+	var unknownSince uint64
+	lookedUp := false
+
+	for {
+		if tx == nil {
+			var err error
+			tx, err = b.lookupTransaction(txID)
+			if err != nil {
+				sub.err = err
+				return
+			}
+		}
+
+		if tx == nil {
+			final, err := b.txBackend.state.Final().Head()
+			if err != nil {
+				sub.err = convertStorageError(err)
+				return
+			}
+			if !lookedUp {
+				unknownSince = final.Height
+				lookedUp = true
+			}
+			if final.Height-unknownSince >= flow.DefaultTransactionExpiry {
+				sub.err = status.Errorf(codes.NotFound, "no known transaction with ID %s", txID)
+				return
+			}
+		} else {
+			result, err := b.transactionStatus(tx)
+			if err != nil {
+				sub.err = convertStorageError(err)
+				return
+			}
+
+			if result.Status == flow.TransactionStatusExecuted || result.Status == flow.TransactionStatusSealed {
+				if executionResult == nil {
+					executionResult = b.executionResult(ctx, txID, result.BlockID)
+				}
+
+				// the execution result may not be available from the execution nodes yet, in which
+				// case we report the transaction as finalized and try again on the next finalized block
+				if executionResult != nil {
+					result.Events = executionResult.Events
+					result.StatusCode = executionResult.StatusCode
+					result.ErrorMessage = executionResult.ErrorMessage
+				} else {
+					result.Status = flow.TransactionStatusFinalized
+				}
+			}
+
+			for _, s := range statusTransitions(lastStatus, result.Status) {
+				update := *result
+				update.Status = s
+				if s < flow.TransactionStatusExecuted {
+					update.Events = nil
+					update.StatusCode = 0
+					update.ErrorMessage = ""
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case sub.ch <- &update:
+				}
+			}
+			lastStatus = result.Status
+
+			if lastStatus == flow.TransactionStatusSealed || lastStatus == flow.TransactionStatusExpired {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-notifier.Channel():
+		}
+	}
+}
+
+// lookupTransaction returns the transaction from storage, or nil if the transaction is not known.
+func (b *backendStreamTransactions) lookupTransaction(txID flow.Identifier) (*flow.TransactionBody, error) {
+	tx, err := b.txBackend.transactions.ByID(txID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, convertStorageError(err)
+	}
+	return tx, nil
+}
+
+// transactionStatus derives the current status of the transaction without contacting the
+// execution nodes. A transaction is considered executed once an execution receipt for its
+// block has been received.
+func (b *backendStreamTransactions) transactionStatus(tx *flow.TransactionBody) (*access.TransactionResult, error) {
+	txID := tx.ID()
+
+	// access node may not have the block if it hasn't yet been finalized, hence block can be nil at this point
+	block, err := b.txBackend.lookupBlock(txID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
+	var blockID flow.Identifier
+	var executed bool
+	if block != nil {
+		blockID = block.ID()
+		receipts, err := b.executionReceipts.ByBlockID(blockID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve execution receipts for block ID %v: %w", blockID, err)
+		}
+		executed = len(receipts) > 0
+	}
+
+	txStatus, err := b.txBackend.deriveTransactionStatus(tx, executed, block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &access.TransactionResult{
+		Status:        txStatus,
+		BlockID:       blockID,
+		TransactionID: txID,
+	}, nil
+}
+
+// executionResult retrieves the execution outcome of the transaction from the execution nodes.
+// It returns nil if the result could not be retrieved.
+func (b *backendStreamTransactions) executionResult(
+	ctx context.Context,
+	txID flow.Identifier,
+	blockID flow.Identifier,
+) *access.TransactionResult {
+	executed, events, statusCode, txError, err := b.txBackend.lookupTransactionResult(ctx, txID, blockID)
+	if err != nil || !executed {
+		b.log.Debug().Err(err).
+			Hex("transaction_id", txID[:]).
+			Msg("could not get execution result for transaction, retrying on next finalized block")
+		return nil
+	}
+
+	return &access.TransactionResult{
+		StatusCode:   uint(statusCode),
+		Events:       events,
+		ErrorMessage: txError,
+	}
+}
+
+// statusTransitions returns the statuses a transaction went through to get from the last reported
+// status to the current status. Nothing is reported before the transaction status is known, and
+// a transaction can only expire while pending.
+func statusTransitions(last, current flow.TransactionStatus) []flow.TransactionStatus {
+	if current <= last || current == flow.TransactionStatusUnknown {
+		return nil
+	}
+	if last == flow.TransactionStatusUnknown || current == flow.TransactionStatusExpired {
+		return []flow.TransactionStatus{current}
+	}
+
+	transitions := make([]flow.TransactionStatus, 0, current-last)
+	for s := last + 1; s <= current; s++ {
+		transitions = append(transitions, s)
+	}
+	return transitions
+}
+
+// transactionStatusSubscription implements access.TransactionStatusSubscription.
+type transactionStatusSubscription struct {
+	ch  chan *access.TransactionResult
+	err error
+}
+
+func (s *transactionStatusSubscription) Channel() <-chan *access.TransactionResult {
+	return s.ch
+}
+
+func (s *transactionStatusSubscription) Err() error {
+	return s.err
+}
@@ -0,0 +1,380 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	accessproto "github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	execproto "github.com/onflow/flow/protobuf/go/flow/execution"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	protocol "github.com/onflow/flow-go/state/protocol/mock"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func (suite *Suite) TestSubscribeTransactionStatus() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	refBlock := unittest.BlockFixture()
+	refBlock.Header.Height = 1
+
+	collection := unittest.CollectionFixture(1)
+	tx := collection.Transactions[0]
+	tx.ReferenceBlockID = refBlock.ID()
+	txID := tx.ID()
+	light := collection.Light()
+
+	block := unittest.BlockFixture()
+	block.Header.Height = 2
+	blockID := block.ID()
+
+	finalHead := unittest.BlockHeaderFixture()
+	finalHead.Height = 1
+	sealedHead := unittest.BlockHeaderFixture()
+	sealedHead.Height = 1
+
+	var included, executed bool
+
+	refSnapshot := new(protocol.Snapshot)
+	refSnapshot.On("Head").Return(refBlock.Header, nil)
+	finalSnapshot := new(protocol.Snapshot)
+	finalSnapshot.On("Head").Return(
+		func() *flow.Header { return &finalHead },
+		func() error { return nil },
+	)
+	sealedSnapshot := new(protocol.Snapshot)
+	sealedSnapshot.On("Head").Return(
+		func() *flow.Header { return &sealedHead },
+		func() error { return nil },
+	)
+	suite.state.On("AtBlockID", tx.ReferenceBlockID).Return(refSnapshot)
+	suite.state.On("Final").Return(finalSnapshot)
+	suite.state.On("Sealed").Return(sealedSnapshot)
+
+	suite.transactions.On("ByID", txID).Return(tx, nil)
+	suite.collections.On("LightByTransactionID", txID).Return(
+		func(flow.Identifier) *flow.LightCollection {
+			if !included {
+				return nil
+			}
+			return &light
+		},
+		func(flow.Identifier) error {
+			if !included {
+				return storage.ErrNotFound
+			}
+			return nil
+		},
+	)
+	suite.blocks.On("ByCollectionID", light.ID()).Return(&block, nil)
+
+	receipts := make(flow.ExecutionReceiptList, 2)
+	var executors flow.IdentityList
+	for i := range receipts {
+		executor := &flow.Identity{NodeID: unittest.IdentifierFixture(), Role: flow.RoleExecution}
+		executors = append(executors, executor)
+		receipts[i] = unittest.ReceiptForBlockFixture(&block)
+		receipts[i].ExecutorID = executor.NodeID
+	}
+	receipts[1].ExecutionResult = receipts[0].ExecutionResult
+	suite.receipts.On("ByBlockID", blockID).Return(
+		func(flow.Identifier) flow.ExecutionReceiptList {
+			if !executed {
+				return nil
+			}
+			return receipts
+		},
+		func(flow.Identifier) error { return nil },
+	)
+	finalSnapshot.On("Identities", mock.Anything).Return(executors, nil)
+
+	events := []flow.Event{
+		unittest.EventFixture(flow.EventAccountCreated, 0, 0, txID, 0),
+	}
+	// the execution result is only requested once, after the transaction was executed
+	suite.execClient.
+		On("GetTransactionResult", mock.Anything, &execproto.GetTransactionResultRequest{
+			BlockId:       blockID[:],
+			TransactionId: txID[:],
+		}).
+		Return(&execproto.GetTransactionResultResponse{
+			Events: convert.EventsToMessages(events),
+		}, nil).
+		Once()
+
+	backend := New(
+		suite.state,
+		nil,
+		nil,
+		suite.blocks,
+		suite.headers,
+		suite.collections,
+		suite.transactions,
+		suite.receipts,
+		nil,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		suite.setupConnectionFactory(),
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		nil,
+	)
+
+	sub, err := backend.SubscribeTransactionStatus(ctx, txID)
+	suite.Require().NoError(err)
+
+	receive := func(expected flow.TransactionStatus) *access.TransactionResult {
+		select {
+		case result := <-sub.Channel():
+			suite.Require().NotNil(result)
+			suite.Require().Equal(expected, result.Status)
+			suite.Require().Equal(txID, result.TransactionID)
+			return result
+		case <-time.After(time.Second):
+			suite.FailNow("timed out waiting for transaction status", "status %s", expected)
+			return nil
+		}
+	}
+
+	// the transaction has not been included in a block yet
+	result := receive(flow.TransactionStatusPending)
+	suite.Require().Equal(flow.ZeroID, result.BlockID)
+
+	// a block including the transaction is finalized
+	included = true
+	finalHead.Height = block.Header.Height
+	backend.NotifyFinalizedBlockHeight(finalHead.Height)
+	result = receive(flow.TransactionStatusFinalized)
+	suite.Require().Equal(blockID, result.BlockID)
+	suite.Require().Empty(result.Events)
+
+	// the block is executed
+	executed = true
+	finalHead.Height++
+	backend.NotifyFinalizedBlockHeight(finalHead.Height)
+	result = receive(flow.TransactionStatusExecuted)
+	suite.Require().Equal(blockID, result.BlockID)
+	suite.Require().Equal(events, result.Events)
+
+	// the block is sealed
+	sealedHead.Height = block.Header.Height
+	finalHead.Height++
+	backend.NotifyFinalizedBlockHeight(finalHead.Height)
+	result = receive(flow.TransactionStatusSealed)
+	suite.Require().Equal(events, result.Events)
+
+	// the subscription ends without error once the transaction is sealed
+	select {
+	case _, ok := <-sub.Channel():
+		suite.Require().False(ok)
+	case <-time.After(time.Second):
+		suite.FailNow("timed out waiting for the subscription to end")
+	}
+	suite.Require().NoError(sub.Err())
+
+	suite.execClient.AssertExpectations(suite.T())
+}
+
+// TestSubscribeTransactionStatus_HistoricalTransaction tests that the result of a transaction from a
+// previous spork is the only update of the subscription.
+func (suite *Suite) TestSubscribeTransactionStatus_HistoricalTransaction() {
+	ctx := context.Background()
+	txID := unittest.IdentifierFixture()
+	suite.transactions.On("ByID", txID).Return(nil, storage.ErrNotFound)
+
+	suite.historicalAccessClient.
+		On("GetTransactionResult", ctx, &accessproto.GetTransactionRequest{Id: txID[:]}).
+		Return(&accessproto.TransactionResultResponse{
+			Status: entities.TransactionStatus(flow.TransactionStatusSealed),
+		}, nil).
+		Once()
+
+	backend := New(
+		suite.state,
+		nil,
+		[]accessproto.AccessAPIClient{suite.historicalAccessClient},
+		nil,
+		nil,
+		nil,
+		suite.transactions,
+		nil,
+		nil,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		nil,
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		nil,
+	)
+
+	sub, err := backend.SubscribeTransactionStatus(ctx, txID)
+	suite.Require().NoError(err)
+
+	result, ok := <-sub.Channel()
+	suite.Require().True(ok)
+	suite.Require().Equal(flow.TransactionStatusSealed, result.Status)
+	suite.Require().Equal(txID, result.TransactionID)
+
+	_, ok = <-sub.Channel()
+	suite.Require().False(ok)
+	suite.Require().NoError(sub.Err())
+
+	suite.historicalAccessClient.AssertExpectations(suite.T())
+}
+
+// TestSubscribeTransactionStatus_UnknownTransaction tests that transactions which are not known yet,
+// e.g. because they were submitted to another node, are looked up on every finalized block.
+func (suite *Suite) TestSubscribeTransactionStatus_UnknownTransaction() {
+	refBlock := unittest.BlockFixture()
+	refBlock.Header.Height = 1
+
+	tx := unittest.TransactionBodyFixture()
+	tx.ReferenceBlockID = refBlock.ID()
+	txID := tx.ID()
+	unknownTxID := unittest.IdentifierFixture()
+
+	finalHead := unittest.BlockHeaderFixture()
+	finalHead.Height = 1
+	sealedHead := unittest.BlockHeaderFixture()
+	sealedHead.Height = 1
+
+	var known bool
+
+	refSnapshot := new(protocol.Snapshot)
+	refSnapshot.On("Head").Return(refBlock.Header, nil)
+	finalSnapshot := new(protocol.Snapshot)
+	finalSnapshot.On("Head").Return(
+		func() *flow.Header { return &finalHead },
+		func() error { return nil },
+	)
+	sealedSnapshot := new(protocol.Snapshot)
+	sealedSnapshot.On("Head").Return(
+		func() *flow.Header { return &sealedHead },
+		func() error { return nil },
+	)
+	suite.state.On("AtBlockID", tx.ReferenceBlockID).Return(refSnapshot)
+	suite.state.On("Final").Return(finalSnapshot)
+	suite.state.On("Sealed").Return(sealedSnapshot)
+
+	suite.transactions.On("ByID", txID).Return(
+		func(flow.Identifier) *flow.TransactionBody {
+			if !known {
+				return nil
+			}
+			return &tx
+		},
+		func(flow.Identifier) error {
+			if !known {
+				return storage.ErrNotFound
+			}
+			return nil
+		},
+	)
+	suite.transactions.On("ByID", unknownTxID).Return(nil, storage.ErrNotFound)
+	suite.collections.On("LightByTransactionID", txID).Return(nil, storage.ErrNotFound)
+
+	backend := New(
+		suite.state,
+		nil,
+		nil,
+		suite.blocks,
+		nil,
+		suite.collections,
+		suite.transactions,
+		suite.receipts,
+		nil,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		nil,
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		nil,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub, err := backend.SubscribeTransactionStatus(ctx, txID)
+	suite.Require().NoError(err)
+	unknownSub, err := backend.SubscribeTransactionStatus(ctx, unknownTxID)
+	suite.Require().NoError(err)
+
+	// nothing is reported while the transaction is unknown
+	select {
+	case result := <-sub.Channel():
+		suite.FailNow("unexpected transaction status", "status %s", result.Status)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// the transaction becomes known once its collection was received
+	known = true
+	finalHead.Height++
+	backend.NotifyFinalizedBlockHeight(finalHead.Height)
+	select {
+	case result := <-sub.Channel():
+		suite.Require().NotNil(result)
+		suite.Require().Equal(flow.TransactionStatusPending, result.Status)
+		suite.Require().Equal(txID, result.TransactionID)
+	case <-time.After(time.Second):
+		suite.FailNow("timed out waiting for transaction status")
+	}
+
+	// the other transaction is still unknown when it would have expired
+	finalHead.Height = 1 + flow.DefaultTransactionExpiry
+	backend.NotifyFinalizedBlockHeight(finalHead.Height)
+	select {
+	case _, ok := <-unknownSub.Channel():
+		suite.Require().False(ok)
+	case <-time.After(time.Second):
+		suite.FailNow("timed out waiting for the subscription to end")
+	}
+	suite.Require().Equal(codes.NotFound, status.Code(unknownSub.Err()))
+}
+
+func (suite *Suite) TestStatusTransitions() {
+	suite.Require().Empty(statusTransitions(flow.TransactionStatusUnknown, flow.TransactionStatusUnknown))
+	suite.Require().Empty(statusTransitions(flow.TransactionStatusExecuted, flow.TransactionStatusExecuted))
+
+	// the first status is reported as is
+	suite.Require().Equal(
+		[]flow.TransactionStatus{flow.TransactionStatusSealed},
+		statusTransitions(flow.TransactionStatusUnknown, flow.TransactionStatusSealed),
+	)
+
+	// skipped statuses are reported
+	suite.Require().Equal(
+		[]flow.TransactionStatus{
+			flow.TransactionStatusFinalized,
+			flow.TransactionStatusExecuted,
+			flow.TransactionStatusSealed,
+		},
+		statusTransitions(flow.TransactionStatusPending, flow.TransactionStatusSealed),
+	)
+
+	// a pending transaction expires without going through any other status
+	suite.Require().Equal(
+		[]flow.TransactionStatus{flow.TransactionStatusExpired},
+		statusTransitions(flow.TransactionStatusPending, flow.TransactionStatusExpired),
+	)
+}
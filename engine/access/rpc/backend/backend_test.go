@@ -22,9 +22,12 @@ import (
 	bprotocol "github.com/onflow/flow-go/state/protocol/badger"
 	"github.com/onflow/flow-go/state/protocol/util"
 
+	accessapi "github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/index"
 	access "github.com/onflow/flow-go/engine/access/mock"
 	backendmock "github.com/onflow/flow-go/engine/access/rpc/backend/mock"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	execextended "github.com/onflow/flow-go/engine/execution/rpc/extended"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module/metrics"
 	protocol "github.com/onflow/flow-go/state/protocol/mock"
@@ -107,6 +110,7 @@ func (suite *Suite) TestPing() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 
 	err := backend.Ping(context.Background())
@@ -139,6 +143,7 @@ func (suite *Suite) TestGetLatestFinalizedBlockHeader() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 
 	// query the handler for the latest finalized block
@@ -202,6 +207,7 @@ func (suite *Suite) TestGetLatestProtocolStateSnapshot_NoTransitionSpan() {
 			nil,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
 		)
 
 		// query the handler for the latest finalized snapshot
@@ -273,6 +279,7 @@ func (suite *Suite) TestGetLatestProtocolStateSnapshot_TransitionSpans() {
 			nil,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
 		)
 
 		// query the handler for the latest finalized snapshot
@@ -337,6 +344,7 @@ func (suite *Suite) TestGetLatestProtocolStateSnapshot_PhaseTransitionSpan() {
 			nil,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
 		)
 
 		// query the handler for the latest finalized snapshot
@@ -412,6 +420,7 @@ func (suite *Suite) TestGetLatestProtocolStateSnapshot_EpochTransitionSpan() {
 			nil,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
 		)
 
 		// query the handler for the latest finalized snapshot
@@ -471,6 +480,7 @@ func (suite *Suite) TestGetLatestProtocolStateSnapshot_HistoryLimit() {
 			nil,
 			suite.log,
 			snapshotHistoryLimit,
+			nil,
 		)
 
 		// the handler should return a snapshot history limit error
@@ -505,6 +515,7 @@ func (suite *Suite) TestGetLatestSealedBlockHeader() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 
 	// query the handler for the latest sealed block
@@ -549,6 +560,7 @@ func (suite *Suite) TestGetTransaction() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 
 	actual, err := backend.GetTransaction(context.Background(), transaction.ID())
@@ -588,6 +600,7 @@ func (suite *Suite) TestGetCollection() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 
 	actual, err := backend.GetCollectionByID(context.Background(), expected.ID())
@@ -650,6 +663,7 @@ func (suite *Suite) TestGetTransactionResultByIndex() {
 		flow.IdentifierList(fixedENIDs.NodeIDs()).Strings(),
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 	suite.execClient.
 		On("GetTransactionResultByIndex", ctx, &exeEventReq).
@@ -711,6 +725,7 @@ func (suite *Suite) TestGetTransactionResultsByBlockID() {
 		flow.IdentifierList(fixedENIDs.NodeIDs()).Strings(),
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 	suite.execClient.
 		On("GetTransactionResultsByBlockID", ctx, &exeEventReq).
@@ -723,6 +738,111 @@ func (suite *Suite) TestGetTransactionResultsByBlockID() {
 	suite.assertAllExpectations()
 }
 
+// TestGetTransactionProfile tests that the request is forwarded to EN
+func (suite *Suite) TestGetTransactionProfile() {
+	suite.state.On("Sealed").Return(suite.snapshot, nil).Maybe()
+
+	ctx := context.Background()
+	block := unittest.BlockFixture()
+	blockID := block.ID()
+	collection := unittest.CollectionFixture(1)
+	light := collection.Light()
+	txID := collection.Transactions[0].ID()
+
+	// collection and block storage return the collection and block of the transaction
+	suite.collections.
+		On("LightByTransactionID", txID).
+		Return(&light, nil)
+	suite.blocks.
+		On("ByCollectionID", light.ID()).
+		Return(&block, nil)
+
+	_, fixedENIDs := suite.setupReceipts(&block)
+	suite.state.On("Final").Return(suite.snapshot, nil).Maybe()
+	suite.snapshot.On("Identities", mock.Anything).Return(fixedENIDs, nil)
+
+	extendedClient := new(access.ExtendedExecutionAPIClient)
+
+	// create a mock connection factory
+	connFactory := new(backendmock.ConnectionFactory)
+	connFactory.On("GetExtendedExecutionAPIClient", mock.Anything).Return(extendedClient, &mockCloser{}, nil)
+
+	backend := New(
+		suite.state,
+		nil,
+		nil,
+		suite.blocks,
+		suite.headers,
+		suite.collections,
+		suite.transactions,
+		suite.receipts,
+		suite.results,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		connFactory, // the connection factory should be used to get the execution node client
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		flow.IdentifierList(fixedENIDs.NodeIDs()).Strings(),
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		nil,
+	)
+
+	exeReq := execextended.GetTransactionProfileRequest{
+		BlockId:       blockID[:],
+		TransactionId: txID[:],
+	}
+
+	suite.Run("happy path", func() {
+		exeResp := execextended.GetTransactionProfileResponse{
+			TransactionId:   txID[:],
+			ComputationUsed: 12,
+			MemoryUsed:      1_000,
+			ComputationKinds: []*execextended.ProfiledKind{
+				{Kind: 1001, Name: "Statement", Intensity: 20, Used: 12},
+			},
+			RegisterBytesRead:    100,
+			RegisterBytesWritten: 10,
+		}
+
+		extendedClient.
+			On("GetTransactionProfile", ctx, &exeReq).
+			Return(&exeResp, nil).
+			Once()
+
+		profile, err := backend.GetTransactionProfile(ctx, txID)
+		suite.Require().NoError(err)
+
+		expected := &accessapi.TransactionProfile{
+			BlockID:         blockID,
+			TransactionID:   txID,
+			ComputationUsed: 12,
+			MemoryUsed:      1_000,
+			ComputationKinds: []accessapi.ProfiledKind{
+				{Kind: 1001, Name: "Statement", Intensity: 20, Used: 12},
+			},
+			MemoryKinds:          []accessapi.ProfiledKind{},
+			RegisterBytesRead:    100,
+			RegisterBytesWritten: 10,
+		}
+		suite.Require().Equal(expected, profile)
+	})
+
+	suite.Run("profile not found on execution node", func() {
+		extendedClient.
+			On("GetTransactionProfile", ctx, &exeReq).
+			Return(nil, status.Error(codes.NotFound, "not found")).
+			Once()
+
+		_, err := backend.GetTransactionProfile(ctx, txID)
+		suite.Require().Equal(codes.NotFound, status.Code(err))
+	})
+
+	extendedClient.AssertExpectations(suite.T())
+	suite.assertAllExpectations()
+}
+
 // TestTransactionStatusTransition tests that the status of transaction changes from Finalized to Sealed
 // when the protocol state is updated
 func (suite *Suite) TestTransactionStatusTransition() {
@@ -795,6 +915,7 @@ func (suite *Suite) TestTransactionStatusTransition() {
 		flow.IdentifierList(fixedENIDs.NodeIDs()).Strings(),
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 
 	// Successfully return empty event list
@@ -914,6 +1035,7 @@ func (suite *Suite) TestTransactionExpiredStatusTransition() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 
 	// should return pending status when we have not observed an expiry block
@@ -1072,6 +1194,7 @@ func (suite *Suite) TestTransactionPendingToFinalizedStatusTransition() {
 		flow.IdentifierList(enIDs.NodeIDs()).Strings(),
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 
 	preferredENIdentifiers = flow.IdentifierList{receipts[0].ExecutorID}
@@ -1129,6 +1252,7 @@ func (suite *Suite) TestTransactionResultUnknown() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 
 	// first call - when block under test is greater height than the sealed head, but execution node does not know about Tx
@@ -1176,6 +1300,7 @@ func (suite *Suite) TestGetLatestFinalizedBlock() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 
 	// query the handler for the latest finalized header
@@ -1304,6 +1429,7 @@ func (suite *Suite) TestGetEventsForBlockIDs() {
 			validENIDs.Strings(), // set the fixed EN Identifiers to the generated execution IDs
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
 		)
 
 		// execute request
@@ -1335,6 +1461,7 @@ func (suite *Suite) TestGetEventsForBlockIDs() {
 			validENIDs.Strings(), // set the fixed EN Identifiers to the generated execution IDs
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
 		)
 
 		// execute request with an empty block id list and expect an empty list of events and no error
@@ -1393,6 +1520,7 @@ func (suite *Suite) TestGetExecutionResultByID() {
 			validENIDs.Strings(), // set the fixed EN Identifiers to the generated execution IDs
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
 		)
 
 		// execute request
@@ -1422,6 +1550,7 @@ func (suite *Suite) TestGetExecutionResultByID() {
 			validENIDs.Strings(), // set the fixed EN Identifiers to the generated execution IDs
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
 		)
 
 		// execute request
@@ -1484,6 +1613,7 @@ func (suite *Suite) TestGetExecutionResultByBlockID() {
 			validENIDs.Strings(), // set the fixed EN Identifiers to the generated execution IDs
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
 		)
 
 		// execute request
@@ -1514,6 +1644,7 @@ func (suite *Suite) TestGetExecutionResultByBlockID() {
 			validENIDs.Strings(), // set the fixed EN Identifiers to the generated execution IDs
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
 		)
 
 		// execute request
@@ -1663,6 +1794,7 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 			nil,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
 		)
 
 		_, err := backend.GetEventsForHeightRange(ctx, string(flow.EventAccountCreated), maxHeight, minHeight)
@@ -1701,6 +1833,7 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 			fixedENIdentifiersStr,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
 		)
 
 		// execute request
@@ -1738,6 +1871,7 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 			fixedENIdentifiersStr,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
 		)
 
 		actualResp, err := backend.GetEventsForHeightRange(ctx, string(flow.EventAccountCreated), minHeight, maxHeight)
@@ -1774,6 +1908,7 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 			fixedENIdentifiersStr,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
 		)
 
 		_, err := backend.GetEventsForHeightRange(ctx, string(flow.EventAccountCreated), minHeight, minHeight+1)
@@ -1810,6 +1945,7 @@ func (suite *Suite) TestGetEventsForHeightRange() {
 			fixedENIdentifiersStr,
 			suite.log,
 			DefaultSnapshotHistoryLimit,
+			nil,
 		)
 
 		_, err := backend.GetEventsForHeightRange(ctx, string(flow.EventAccountCreated), minHeight, maxHeight)
@@ -1886,6 +2022,7 @@ func (suite *Suite) TestGetAccount() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 
 	preferredENIdentifiers = flow.IdentifierList{receipts[0].ExecutorID}
@@ -1900,6 +2037,278 @@ func (suite *Suite) TestGetAccount() {
 	})
 }
 
+func (suite *Suite) TestGetAccountsAtBlockHeight() {
+	ctx := context.Background()
+	block := unittest.BlockFixture()
+	blockID := block.ID()
+	height := block.Header.Height
+
+	suite.headers.On("ByHeight", height).Return(block.Header, nil)
+
+	receipts := make(flow.ExecutionReceiptList, 2)
+	var executors flow.IdentityList
+	for i := range receipts {
+		receipts[i] = unittest.ReceiptForBlockFixture(&block)
+		executors = append(executors, &flow.Identity{NodeID: receipts[i].ExecutorID, Role: flow.RoleExecution})
+	}
+	receipts[1].ExecutionResult = receipts[0].ExecutionResult
+	suite.receipts.On("ByBlockID", blockID).Return(receipts, nil)
+	suite.state.On("Final").Return(suite.snapshot)
+	suite.snapshot.On("Identities", mock.Anything).Return(executors, nil)
+
+	found := unittest.RandomAddressFixture()
+	missing := unittest.RandomAddressFixture()
+
+	// the existing account is returned by the first execution node, the missing account is requested from both
+	extendedClient := new(access.ExtendedExecutionAPIClient)
+	extendedClient.
+		On("GetAccountsAtBlockID", mock.Anything, &execextended.GetAccountsAtBlockIDRequest{
+			BlockId:   blockID[:],
+			Addresses: [][]byte{found.Bytes(), missing.Bytes()},
+		}).
+		Return(&execextended.GetAccountsAtBlockIDResponse{
+			Results: []*execextended.AccountResult{
+				{Address: found.Bytes(), Account: &execextended.Account{Address: found.Bytes()}},
+				{Address: missing.Bytes(), ErrorCode: uint32(codes.NotFound), ErrorMessage: "account not found"},
+			},
+		}, nil).
+		Once()
+	extendedClient.
+		On("GetAccountsAtBlockID", mock.Anything, &execextended.GetAccountsAtBlockIDRequest{
+			BlockId:   blockID[:],
+			Addresses: [][]byte{missing.Bytes()},
+		}).
+		Return(&execextended.GetAccountsAtBlockIDResponse{
+			Results: []*execextended.AccountResult{
+				{Address: missing.Bytes(), ErrorCode: uint32(codes.NotFound), ErrorMessage: "account not found"},
+			},
+		}, nil).
+		Once()
+
+	// a single request is sent to each execution node
+	connFactory := new(backendmock.ConnectionFactory)
+	connFactory.On("GetExtendedExecutionAPIClient", mock.Anything).Return(extendedClient, &mockCloser{}, nil).Twice()
+
+	backend := New(
+		suite.state,
+		nil,
+		nil,
+		nil,
+		suite.headers,
+		nil,
+		nil,
+		suite.receipts,
+		suite.results,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		connFactory,
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		nil,
+	)
+
+	suite.Run("errors are reported per account", func() {
+		results, err := backend.GetAccountsAtBlockHeight(ctx, []flow.Address{found, missing}, height)
+		suite.checkResponse(results, err)
+
+		suite.Require().Len(results, 2)
+		suite.Require().Equal(found, results[0].Address)
+		suite.Require().NoError(results[0].Err)
+		suite.Require().Equal(found, results[0].Account.Address)
+		suite.Require().Equal(missing, results[1].Address)
+		suite.Require().Nil(results[1].Account)
+		suite.Require().Equal(codes.NotFound, status.Code(results[1].Err))
+
+		extendedClient.AssertExpectations(suite.T())
+		connFactory.AssertExpectations(suite.T())
+	})
+
+	suite.Run("too many addresses", func() {
+		addresses := make([]flow.Address, accessapi.MaxAccountsPerRequest+1)
+		_, err := backend.GetAccountsAtBlockHeight(ctx, addresses, height)
+		suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func (suite *Suite) TestQueryEventsForBlockIDs() {
+	ctx := context.Background()
+	block := unittest.BlockFixture()
+	blockID := block.ID()
+
+	suite.headers.On("ByBlockID", blockID).Return(block.Header, nil)
+
+	receipts := make(flow.ExecutionReceiptList, 2)
+	var executors flow.IdentityList
+	for i := range receipts {
+		receipts[i] = unittest.ReceiptForBlockFixture(&block)
+		executors = append(executors, &flow.Identity{NodeID: receipts[i].ExecutorID, Role: flow.RoleExecution})
+	}
+	receipts[1].ExecutionResult = receipts[0].ExecutionResult
+	suite.receipts.On("ByBlockID", blockID).Return(receipts, nil)
+	suite.state.On("Final").Return(suite.snapshot)
+	suite.snapshot.On("Identities", mock.Anything).Return(executors, nil)
+
+	created := unittest.EventFixture(flow.EventAccountCreated, 1, 0, unittest.IdentifierFixture(), 0)
+	updated := unittest.EventFixture(flow.EventAccountUpdated, 0, 1, unittest.IdentifierFixture(), 0)
+
+	// the events of each type are requested separately
+	for _, event := range []flow.Event{created, updated} {
+		suite.execClient.
+			On("GetEventsForBlockIDs", mock.Anything, &execproto.GetEventsForBlockIDsRequest{
+				BlockIds: convert.IdentifiersToMessages([]flow.Identifier{blockID}),
+				Type:     string(event.Type),
+			}).
+			Return(&execproto.GetEventsForBlockIDsResponse{
+				Results: []*execproto.GetEventsForBlockIDsResponse_Result{{
+					BlockId:     convert.IdentifierToMessage(blockID),
+					BlockHeight: block.Header.Height,
+					Events:      convert.EventsToMessages([]flow.Event{event}),
+				}},
+			}, nil)
+	}
+
+	connFactory := new(backendmock.ConnectionFactory)
+	connFactory.On("GetExecutionAPIClient", mock.Anything).Return(suite.execClient, &mockCloser{}, nil)
+
+	backend := New(
+		suite.state,
+		nil,
+		nil,
+		nil,
+		suite.headers,
+		nil,
+		nil,
+		suite.receipts,
+		suite.results,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		connFactory,
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		nil,
+	)
+
+	suite.Run("events of all types are merged in emission order", func() {
+		query := accessapi.EventQuery{
+			EventTypes: []flow.EventType{flow.EventAccountCreated, flow.EventAccountUpdated, flow.EventAccountCreated},
+		}
+		actual, err := backend.QueryEventsForBlockIDs(ctx, query, []flow.Identifier{blockID})
+		suite.checkResponse(actual, err)
+
+		suite.Require().Len(actual, 1)
+		suite.Require().Equal(blockID, actual[0].BlockID)
+		suite.Require().Equal([]flow.Event{updated, created}, actual[0].Events)
+	})
+
+	suite.Run("events are filtered by field", func() {
+		// the fixture events have no Cadence payload, so no event matches the field filter
+		query := accessapi.EventQuery{
+			EventTypes:   []flow.EventType{flow.EventAccountCreated, flow.EventAccountUpdated},
+			FieldFilters: []accessapi.EventFieldFilter{{Field: "address", Value: "0x01"}},
+		}
+		actual, err := backend.QueryEventsForBlockIDs(ctx, query, []flow.Identifier{blockID})
+		suite.checkResponse(actual, err)
+
+		suite.Require().Len(actual, 1)
+		suite.Require().Empty(actual[0].Events)
+	})
+
+	suite.Run("too many event types", func() {
+		query := accessapi.EventQuery{EventTypes: make([]flow.EventType, accessapi.MaxEventTypesPerQuery+1)}
+		_, err := backend.QueryEventsForBlockIDs(ctx, query, []flow.Identifier{blockID})
+		suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func (suite *Suite) TestSimulateTransaction() {
+	ctx := context.Background()
+	sealed := unittest.BlockHeaderFixture()
+
+	tx := unittest.TransactionBodyFixture()
+	tx.ReferenceBlockID = sealed.ID()
+
+	suite.state.On("Sealed").Return(suite.snapshot, nil)
+	suite.state.On("Final").Return(suite.snapshot, nil)
+	suite.state.On("AtBlockID", sealed.ID()).Return(suite.snapshot, nil)
+	suite.snapshot.On("Head").Return(&sealed, nil)
+
+	newBackend := func(scriptExecutor ScriptExecutor) *Backend {
+		backend := New(
+			suite.state,
+			nil,
+			nil,
+			nil,
+			suite.headers,
+			nil,
+			nil,
+			suite.receipts,
+			suite.results,
+			suite.chainID,
+			metrics.NewNoopCollector(),
+			nil,
+			false,
+			DefaultMaxHeightRange,
+			nil,
+			nil,
+			suite.log,
+			DefaultSnapshotHistoryLimit,
+			scriptExecutor,
+		)
+		if scriptExecutor != nil {
+			backend.EnableTransactionSimulation(scriptExecutor)
+		}
+		return backend
+	}
+
+	suite.Run("simulated against the latest sealed state", func() {
+		expected := &accessapi.TransactionSimulationResult{
+			BlockID:         sealed.ID(),
+			BlockHeight:     sealed.Height,
+			ComputationUsed: 10,
+			Fee:             1_000,
+		}
+
+		scriptExecutor := new(backendmock.ScriptExecutor)
+		scriptExecutor.On("SimulateAtBlockHeight", mock.Anything, &tx, sealed.Height).Return(expected, nil).Once()
+
+		actual, err := newBackend(scriptExecutor).SimulateTransaction(ctx, &tx)
+		suite.Require().NoError(err)
+		suite.Require().Equal(expected, actual)
+		scriptExecutor.AssertExpectations(suite.T())
+	})
+
+	suite.Run("execution state not indexed", func() {
+		scriptExecutor := new(backendmock.ScriptExecutor)
+		scriptExecutor.On("SimulateAtBlockHeight", mock.Anything, &tx, sealed.Height).
+			Return(nil, fmt.Errorf("%w %d", index.ErrHeightNotIndexed, sealed.Height)).
+			Once()
+
+		_, err := newBackend(scriptExecutor).SimulateTransaction(ctx, &tx)
+		suite.Require().Equal(codes.Unavailable, status.Code(err))
+	})
+
+	suite.Run("invalid transaction", func() {
+		invalid := tx
+		invalid.Script = nil
+
+		_, err := newBackend(new(backendmock.ScriptExecutor)).SimulateTransaction(ctx, &invalid)
+		suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+	})
+
+	suite.Run("simulation not enabled", func() {
+		_, err := newBackend(nil).SimulateTransaction(ctx, &tx)
+		suite.Require().Equal(codes.Unimplemented, status.Code(err))
+	})
+}
+
 func (suite *Suite) TestGetAccountAtBlockHeight() {
 	suite.state.On("Sealed").Return(suite.snapshot, nil).Maybe()
 	suite.state.On("Final").Return(suite.snapshot, nil).Maybe()
@@ -1966,6 +2375,7 @@ func (suite *Suite) TestGetAccountAtBlockHeight() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 
 	preferredENIdentifiers = flow.IdentifierList{receipts[0].ExecutorID}
@@ -2003,6 +2413,7 @@ func (suite *Suite) TestGetNetworkParameters() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 
 	params := backend.GetNetworkParameters(context.Background())
@@ -2010,6 +2421,91 @@ func (suite *Suite) TestGetNetworkParameters() {
 	suite.Require().Equal(expectedChainID, params.ChainID)
 }
 
+func (suite *Suite) TestGetNodeVersionInfo() {
+	sporkID := unittest.IdentifierFixture()
+	sporkRootBlockHeight := uint64(1000)
+
+	params := new(protocol.Params)
+	params.On("SporkID").Return(sporkID, nil)
+	params.On("ProtocolVersion").Return(uint(2), nil)
+	params.On("SporkRootBlockHeight").Return(sporkRootBlockHeight, nil)
+
+	state := new(protocol.State)
+	state.On("Params").Return(params)
+
+	backend := New(state,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		nil,
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		nil,
+	)
+
+	info, err := backend.GetNodeVersionInfo(context.Background())
+	suite.checkResponse(info, err)
+
+	suite.Require().Equal(sporkID, info.SporkID)
+	suite.Require().Equal(uint(2), info.ProtocolVersion)
+	suite.Require().Equal(sporkRootBlockHeight, info.SporkRootBlockHeight)
+}
+
+func (suite *Suite) TestGetLatestEpochInfo() {
+	head := unittest.BlockHeaderFixture()
+
+	epoch := new(protocol.Epoch)
+	epoch.On("Counter").Return(uint64(5), nil)
+	epochs := new(protocol.EpochQuery)
+	epochs.On("Current").Return(epoch)
+
+	suite.state.On("Final").Return(suite.snapshot)
+	suite.state.On("AtHeight", head.Height).Return(suite.snapshot)
+	suite.snapshot.On("Head").Return(&head, nil)
+	suite.snapshot.On("Epochs").Return(epochs)
+	suite.snapshot.On("Phase").Return(flow.EpochPhaseCommitted, nil)
+
+	backend := New(suite.state,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		nil,
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		nil,
+	)
+
+	info, err := backend.GetLatestEpochInfo(context.Background())
+	suite.checkResponse(info, err)
+
+	suite.Require().Equal(uint64(5), info.Counter)
+	suite.Require().Equal(flow.EpochPhaseCommitted, info.Phase)
+
+	suite.assertAllExpectations()
+}
+
 // TestExecutionNodesForBlockID tests the common method backend.executionNodesForBlockID used for serving all API calls
 // that need to talk to an execution node.
 func (suite *Suite) TestExecutionNodesForBlockID() {
@@ -2181,6 +2677,7 @@ func (suite *Suite) TestExecuteScriptOnExecutionNode() {
 		nil,
 		suite.log,
 		DefaultSnapshotHistoryLimit,
+		nil,
 	)
 
 	// mock parameters
@@ -2240,6 +2737,97 @@ func (suite *Suite) checkResponse(resp interface{}, err error) {
 	suite.Require().NotNil(resp)
 }
 
+// TestExecuteScriptLocally tests that scripts are executed locally at indexed heights, and forwarded to the
+// execution nodes otherwise
+func (suite *Suite) TestExecuteScriptLocally() {
+	ctx := context.Background()
+	block := unittest.BlockFixture()
+	blockID := block.ID()
+	height := block.Header.Height
+	script := []byte("dummy script")
+	arguments := [][]byte(nil)
+
+	suite.headers.On("ByBlockID", blockID).Return(block.Header, nil)
+	suite.headers.On("ByHeight", height).Return(block.Header, nil)
+
+	scriptExecutor := new(backendmock.ScriptExecutor)
+
+	backend := New(
+		suite.state,
+		nil,
+		nil,
+		nil,
+		suite.headers,
+		nil,
+		nil,
+		suite.receipts,
+		suite.results,
+		suite.chainID,
+		metrics.NewNoopCollector(),
+		suite.setupConnectionFactory(),
+		false,
+		DefaultMaxHeightRange,
+		nil,
+		nil,
+		suite.log,
+		DefaultSnapshotHistoryLimit,
+		scriptExecutor,
+	)
+
+	suite.Run("indexed height is executed locally", func() {
+		scriptExecutor.On("ExecuteAtBlockHeight", ctx, script, arguments, height).Return([]byte{1, 2, 3}, nil).Once()
+
+		res, err := backend.ExecuteScriptAtBlockID(ctx, blockID, script, arguments)
+		suite.checkResponse(res, err)
+		suite.Require().Equal([]byte{1, 2, 3}, res)
+	})
+
+	suite.Run("script failure returns status code InvalidArgument", func() {
+		scriptExecutor.
+			On("ExecuteAtBlockHeight", ctx, script, arguments, height).
+			Return(nil, fmt.Errorf("%w: failure", index.ErrScriptFailed)).
+			Once()
+
+		_, err := backend.ExecuteScriptAtBlockHeight(ctx, height, script, arguments)
+		suite.Require().Error(err)
+		suite.Require().Equal(codes.InvalidArgument, status.Code(err))
+	})
+
+	suite.Run("height not indexed is forwarded to the execution nodes", func() {
+		scriptExecutor.
+			On("ExecuteAtBlockHeight", ctx, script, arguments, height).
+			Return(nil, fmt.Errorf("%w %d", index.ErrHeightNotIndexed, height)).
+			Once()
+
+		receipts := make(flow.ExecutionReceiptList, 2)
+		var executors flow.IdentityList
+		for i := range receipts {
+			receipts[i] = unittest.ReceiptForBlockFixture(&block)
+			executors = append(executors, &flow.Identity{NodeID: receipts[i].ExecutorID, Role: flow.RoleExecution})
+		}
+		receipts[1].ExecutionResult = receipts[0].ExecutionResult
+		suite.receipts.On("ByBlockID", blockID).Return(receipts, nil)
+		suite.state.On("Final").Return(suite.snapshot)
+		suite.snapshot.On("Identities", mock.Anything).Return(executors, nil)
+
+		suite.execClient.
+			On("ExecuteScriptAtBlockID", ctx, &execproto.ExecuteScriptAtBlockIDRequest{
+				BlockId:   blockID[:],
+				Script:    script,
+				Arguments: arguments,
+			}).
+			Return(&execproto.ExecuteScriptAtBlockIDResponse{Value: []byte{4, 5, 6}}, nil).
+			Once()
+
+		res, err := backend.ExecuteScriptAtBlockHeight(ctx, height, script, arguments)
+		suite.checkResponse(res, err)
+		suite.Require().Equal([]byte{4, 5, 6}, res)
+		suite.execClient.AssertExpectations(suite.T())
+	})
+
+	scriptExecutor.AssertExpectations(suite.T())
+}
+
 func (suite *Suite) setupReceipts(block *flow.Block) ([]*flow.ExecutionReceipt, flow.IdentityList) {
 	ids := unittest.IdentityListFixture(2, unittest.WithRole(flow.RoleExecution))
 	receipt1 := unittest.ReceiptForBlockFixture(block)
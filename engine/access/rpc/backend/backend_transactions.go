@@ -15,7 +15,9 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/access/index"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	execextended "github.com/onflow/flow-go/engine/execution/rpc/extended"
 	"github.com/onflow/flow-go/fvm/blueprints"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module"
@@ -37,11 +39,44 @@ type backendTransactions struct {
 	transactionValidator *access.TransactionValidator
 	retry                *Retry
 	connFactory          ConnectionFactory
+	scriptExecutor       ScriptExecutor // simulates transactions locally, nil if transaction simulation is not enabled
 
 	previousAccessNodes []accessproto.AccessAPIClient
 	log                 zerolog.Logger
 }
 
+// SimulateTransaction runs the transaction against the latest sealed execution state without committing it, and
+// returns the resources it used, its events and state changes, and the fee that would be charged. Transaction
+// signatures are not verified.
+func (b *backendTransactions) SimulateTransaction(
+	ctx context.Context,
+	tx *flow.TransactionBody,
+) (*access.TransactionSimulationResult, error) {
+	if b.scriptExecutor == nil {
+		return nil, status.Error(codes.Unimplemented, "transaction simulation is not enabled on this node")
+	}
+
+	err := b.transactionValidator.Validate(tx)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid transaction: %s", err.Error())
+	}
+
+	latestHeader, err := b.state.Sealed().Head()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get latest sealed header: %v", err)
+	}
+
+	result, err := b.scriptExecutor.SimulateAtBlockHeight(ctx, tx, latestHeader.Height)
+	if errors.Is(err, index.ErrHeightNotIndexed) {
+		return nil, status.Errorf(codes.Unavailable, "execution state is not indexed at the latest sealed height %d", latestHeader.Height)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to simulate transaction: %v", err)
+	}
+
+	return result, nil
+}
+
 // SendTransaction forwards the transaction to the collection node
 func (b *backendTransactions) SendTransaction(
 	ctx context.Context,
@@ -516,6 +551,64 @@ func (b *backendTransactions) isExpired(refHeight, compareToHeight uint64) bool
 	return compareToHeight-refHeight > flow.DefaultTransactionExpiry
 }
 
+// GetTransactionProfile returns the breakdown of the resources used to execute a transaction, as recorded by an
+// execution node which executed the block of the transaction.
+func (b *backendTransactions) GetTransactionProfile(
+	ctx context.Context,
+	txID flow.Identifier,
+) (*access.TransactionProfile, error) {
+	// find the block for the transaction
+	block, err := b.lookupBlock(txID)
+	if err != nil {
+		return nil, convertStorageError(err)
+	}
+	blockID := block.ID()
+
+	req := &execextended.GetTransactionProfileRequest{
+		BlockId:       blockID[:],
+		TransactionId: txID[:],
+	}
+	execNodes, err := executionNodesForBlockID(ctx, blockID, b.executionReceipts, b.state, b.log)
+	if err != nil {
+		if errors.As(err, &InsufficientExecutionReceipts{}) {
+			return nil, status.Errorf(codes.NotFound, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to retrieve profile from any execution node: %v", err)
+	}
+
+	resp, err := b.getTransactionProfileFromAnyExeNode(ctx, execNodes, req)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Internal, "failed to retrieve profile from execution node: %v", err)
+	}
+
+	return &access.TransactionProfile{
+		BlockID:              blockID,
+		TransactionID:        txID,
+		ComputationUsed:      resp.GetComputationUsed(),
+		MemoryUsed:           resp.GetMemoryUsed(),
+		ComputationKinds:     messagesToProfiledKinds(resp.GetComputationKinds()),
+		MemoryKinds:          messagesToProfiledKinds(resp.GetMemoryKinds()),
+		RegisterBytesRead:    resp.GetRegisterBytesRead(),
+		RegisterBytesWritten: resp.GetRegisterBytesWritten(),
+	}, nil
+}
+
+func messagesToProfiledKinds(messages []*execextended.ProfiledKind) []access.ProfiledKind {
+	kinds := make([]access.ProfiledKind, len(messages))
+	for i, message := range messages {
+		kinds[i] = access.ProfiledKind{
+			Kind:      message.GetKind(),
+			Name:      message.GetName(),
+			Intensity: message.GetIntensity(),
+			Used:      message.GetUsed(),
+		}
+	}
+	return kinds
+}
+
 func (b *backendTransactions) lookupBlock(txID flow.Identifier) (*flow.Block, error) {
 
 	collection, err := b.collections.LightByTransactionID(txID)
@@ -782,3 +875,48 @@ func (b *backendTransactions) tryGetTransactionResultByIndex(
 	resp, err := execRPCClient.GetTransactionResultByIndex(ctx, &req)
 	return resp, err
 }
+
+func (b *backendTransactions) getTransactionProfileFromAnyExeNode(
+	ctx context.Context,
+	execNodes flow.IdentityList,
+	req *execextended.GetTransactionProfileRequest,
+) (*execextended.GetTransactionProfileResponse, error) {
+	var errs *multierror.Error
+	logAnyError := func() {
+		errToReturn := errs.ErrorOrNil()
+		if errToReturn != nil {
+			b.log.Info().Err(errToReturn).Msg("failed to get transaction profile from execution nodes")
+		}
+	}
+	defer logAnyError()
+	for _, execNode := range execNodes {
+		resp, err := b.tryGetTransactionProfile(ctx, execNode, req)
+		if err == nil {
+			b.log.Debug().
+				Str("execution_node", execNode.String()).
+				Hex("block_id", req.GetBlockId()).
+				Hex("transaction_id", req.GetTransactionId()).
+				Msg("Successfully got transaction profile from any node")
+			return resp, nil
+		}
+		if status.Code(err) == codes.NotFound {
+			return nil, err
+		}
+		errs = multierror.Append(errs, err)
+	}
+	return nil, errs.ErrorOrNil()
+}
+
+func (b *backendTransactions) tryGetTransactionProfile(
+	ctx context.Context,
+	execNode *flow.Identity,
+	req *execextended.GetTransactionProfileRequest,
+) (*execextended.GetTransactionProfileResponse, error) {
+	execRPCClient, closer, err := b.connFactory.GetExtendedExecutionAPIClient(execNode.Address)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	resp, err := execRPCClient.GetTransactionProfile(ctx, req)
+	return resp, err
+}
@@ -0,0 +1,115 @@
+package backend
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CircuitBreakerConfig configures the circuit breaker for requests to collection and execution nodes.
+type CircuitBreakerConfig struct {
+	Enabled        bool          // whether the circuit breaker is enabled
+	MaxFailures    uint32        // number of consecutive failed requests after which a node is considered unhealthy
+	RestoreTimeout time.Duration // duration after which requests to an unhealthy node are attempted again
+}
+
+// CircuitBreaker keeps track of the health of collection and execution nodes. Once MaxFailures consecutive
+// requests to a node have failed, the circuit breaker opens and no further connections to the node are
+// handed out, so that callers move on to the next node right away. Once RestoreTimeout has passed, the
+// circuit breaker is half-open and lets a single probe request through: it closes if the probe succeeds,
+// and opens again if the probe fails. If the outcome of the probe is never recorded, another probe is let
+// through once RestoreTimeout has passed again.
+type CircuitBreaker struct {
+	maxFailures    uint32
+	restoreTimeout time.Duration
+
+	mu    sync.Mutex
+	nodes map[string]*nodeHealth // health of the nodes with failed requests, by address
+}
+
+type nodeHealth struct {
+	failures uint32    // number of consecutive failed requests
+	openedAt time.Time // time the circuit breaker opened for the node, or let the latest probe through
+}
+
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{
+		maxFailures:    config.MaxFailures,
+		restoreTimeout: config.RestoreTimeout,
+		nodes:          make(map[string]*nodeHealth),
+	}
+}
+
+// Allow returns whether requests to the node with the given address may be made. While the circuit breaker
+// is half-open, only the caller making the probe request is allowed.
+func (cb *CircuitBreaker) Allow(address string) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	node, ok := cb.nodes[address]
+	if !ok || node.failures < cb.maxFailures {
+		return true
+	}
+
+	if time.Since(node.openedAt) < cb.restoreTimeout {
+		return false
+	}
+
+	// the next probe is only let through once the restore timeout has passed again
+	node.openedAt = time.Now()
+	return true
+}
+
+// onResult records the outcome of a request to the node with the given address.
+func (cb *CircuitBreaker) onResult(address string, failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if !failed {
+		delete(cb.nodes, address)
+		return
+	}
+
+	node, ok := cb.nodes[address]
+	if !ok {
+		node = &nodeHealth{}
+		cb.nodes[address] = node
+	}
+
+	node.failures++
+	if node.failures >= cb.maxFailures {
+		node.openedAt = time.Now()
+	}
+}
+
+// UnaryClientInterceptor returns an interceptor recording the outcome of each request made on a connection.
+func (cb *CircuitBreaker) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req interface{},
+		reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		cb.onResult(cc.Target(), isNodeFailure(err))
+		return err
+	}
+}
+
+// isNodeFailure returns whether the error indicates that the node is unreachable or unresponsive, as opposed
+// to the request being rejected by the node.
+func isNodeFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
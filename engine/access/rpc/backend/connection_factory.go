@@ -5,22 +5,43 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sync"
 	"time"
 
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/onflow/flow/protobuf/go/flow/access"
 	"github.com/onflow/flow/protobuf/go/flow/execution"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 
+	"github.com/onflow/flow-go/crypto"
+	"github.com/onflow/flow-go/engine/execution/rpc/extended"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/model/flow/filter"
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/state/protocol"
 	"github.com/onflow/flow-go/utils/grpcutils"
 )
 
 // the default timeout used when making a GRPC request to a collection node or an execution node
 const defaultClientTimeout = 3 * time.Second
 
+// the interval at which keepalive pings are sent on connections with in-flight requests, it must not be lower than
+// the minimum interval enforced by the gRPC servers of the collection and execution nodes (5 minutes by default)
+const keepaliveTime = 5 * time.Minute
+
+// the time to wait for a keepalive ping to be acknowledged before the connection is considered broken
+const keepaliveTimeout = 20 * time.Second
+
 // ConnectionFactory is used to create an access api client
 type ConnectionFactory interface {
 	GetAccessAPIClient(address string) (access.AccessAPIClient, io.Closer, error)
 	GetExecutionAPIClient(address string) (execution.ExecutionAPIClient, io.Closer, error)
+	GetExtendedExecutionAPIClient(address string) (extended.ExtendedExecutionAPIClient, io.Closer, error)
 }
 
 type ProxyConnectionFactory struct {
@@ -36,60 +57,338 @@ func (p *ProxyConnectionFactory) GetExecutionAPIClient(address string) (executio
 	return p.ConnectionFactory.GetExecutionAPIClient(p.targetAddress)
 }
 
+func (p *ProxyConnectionFactory) GetExtendedExecutionAPIClient(address string) (extended.ExtendedExecutionAPIClient, io.Closer, error) {
+	return p.ConnectionFactory.GetExtendedExecutionAPIClient(p.targetAddress)
+}
+
 type ConnectionFactoryImpl struct {
 	CollectionGRPCPort        uint
 	ExecutionGRPCPort         uint
 	CollectionNodeGRPCTimeout time.Duration
 	ExecutionNodeGRPCTimeout  time.Duration
+	ConnectionsCache          *lru.Cache      // cache of connections by address created with NewConnectionsCache, connections are not reused if nil
+	MaxIdleTime               time.Duration   // cached connections which have not been used for longer are closed by EvictIdleConnections, if non zero
+	CircuitBreaker            *CircuitBreaker // skips unhealthy nodes, disabled if nil
+	AccessMetrics             module.AccessMetrics
+	CollectionNodeTLS         *NodeTLS       // TLS configuration for collection nodes, connections are insecure if nil
+	ExecutionNodeTLS          *NodeTLS       // TLS configuration for execution nodes, connections are insecure if nil
+	State                     protocol.State // used to look up the nodes in the identity table when TLS is enabled
+
+	mu sync.Mutex // serializes access to the connections cache
+
+	identitiesMu      sync.RWMutex
+	identities        map[flow.Role]map[string]*flow.Identity // nodes by role and address, looked up once per epoch
+	identitiesVersion uint64                                  // incremented each time the identities are invalidated
+}
+
+// cachedClient is a connection to a collection or execution node held in the connections cache.
+type cachedClient struct {
+	conn        *grpc.ClientConn
+	timeout     time.Duration
+	lastUsed    time.Time
+	networkKey  crypto.PublicKey // networking key the TLS certificate of the node is pinned to, nil if TLS is disabled
+	invalidated bool             // whether the connection is removed from the cache because it can't be reused
+}
+
+// close closes the connection once requests in flight on the connection have timed out.
+func (c *cachedClient) close() {
+	time.AfterFunc(c.timeout, func() {
+		_ = c.conn.Close()
+	})
+}
+
+// NewConnectionsCache creates a cache holding connections to up to size collection and execution nodes. Connections
+// removed from the cache are closed, and recorded as either invalidated or evicted.
+func NewConnectionsCache(size uint, accessMetrics module.AccessMetrics) (*lru.Cache, error) {
+	return lru.NewWithEvict(int(size), func(_, value interface{}) {
+		client := value.(*cachedClient)
+		client.close()
+		if client.invalidated {
+			accessMetrics.ConnectionFromPoolInvalidated()
+		} else {
+			accessMetrics.ConnectionFromPoolEvicted()
+		}
+	})
 }
 
-// createConnection creates new gRPC connections to remote node
-func (cf *ConnectionFactoryImpl) createConnection(address string, timeout time.Duration) (*grpc.ClientConn, error) {
+// createConnection creates new gRPC connections to remote node, using TLS if transport credentials are given
+func (cf *ConnectionFactoryImpl) createConnection(
+	address string,
+	timeout time.Duration,
+	creds credentials.TransportCredentials,
+) (*grpc.ClientConn, error) {
 
 	if timeout == 0 {
 		timeout = defaultClientTimeout
 	}
 
-	conn, err := grpc.Dial(
-		address,
+	transportOpt := grpc.WithInsecure() //nolint:staticcheck
+	if creds != nil {
+		transportOpt = grpc.WithTransportCredentials(creds)
+	}
+
+	opts := []grpc.DialOption{
 		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(grpcutils.DefaultMaxMsgSize)),
-		grpc.WithInsecure(), //nolint:staticcheck
-		WithClientUnaryInterceptor(timeout))
+		transportOpt,
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    keepaliveTime,
+			Timeout: keepaliveTimeout,
+		}),
+		WithClientUnaryInterceptor(timeout),
+	}
+	if cf.CircuitBreaker != nil {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(cf.CircuitBreaker.UnaryClientInterceptor()))
+	}
+
+	conn, err := grpc.Dial(address, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to address %s: %w", address, err)
 	}
 	return conn, nil
 }
 
+// retrieveConnection returns the cached connection to the given address if it is healthy and pinned to the given
+// networking key, or otherwise creates a new connection and adds it to the cache.
+func (cf *ConnectionFactoryImpl) retrieveConnection(
+	address string,
+	timeout time.Duration,
+	creds credentials.TransportCredentials,
+	networkKey crypto.PublicKey,
+) (*grpc.ClientConn, error) {
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	if res, ok := cf.ConnectionsCache.Get(address); ok {
+		client := res.(*cachedClient)
+		if isHealthy(client.conn) && sameNetworkKey(client.networkKey, networkKey) {
+			client.lastUsed = time.Now()
+			cf.AccessMetrics.ConnectionFromPoolReused()
+			return client.conn, nil
+		}
+
+		client.invalidated = true
+		cf.ConnectionsCache.Remove(address)
+	}
+
+	conn, err := cf.createConnection(address, timeout, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	if timeout == 0 {
+		timeout = defaultClientTimeout
+	}
+	cf.ConnectionsCache.Add(address, &cachedClient{
+		conn:       conn,
+		timeout:    timeout,
+		lastUsed:   time.Now(),
+		networkKey: networkKey,
+	})
+	cf.AccessMetrics.NewConnectionEstablished()
+	cf.AccessMetrics.TotalConnectionsInPool(uint(cf.ConnectionsCache.Len()))
+
+	return conn, nil
+}
+
+// EvictIdleConnections removes the connections which have not been used for longer than MaxIdleTime from the cache.
+// It is called periodically by the owner of the factory, instead of on every request, as it scans the whole cache.
+func (cf *ConnectionFactoryImpl) EvictIdleConnections() {
+	if cf.ConnectionsCache == nil || cf.MaxIdleTime == 0 {
+		return
+	}
+
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+
+	evicted := false
+	for _, key := range cf.ConnectionsCache.Keys() {
+		res, ok := cf.ConnectionsCache.Peek(key)
+		if !ok {
+			continue
+		}
+		if time.Since(res.(*cachedClient).lastUsed) > cf.MaxIdleTime {
+			cf.ConnectionsCache.Remove(key)
+			evicted = true
+		}
+	}
+
+	if evicted {
+		cf.AccessMetrics.TotalConnectionsInPool(uint(cf.ConnectionsCache.Len()))
+	}
+}
+
+// isHealthy returns whether requests can be made on the connection. Idle connections are healthy, as they
+// reconnect on the next request.
+func isHealthy(conn *grpc.ClientConn) bool {
+	state := conn.GetState()
+	return state != connectivity.TransientFailure && state != connectivity.Shutdown
+}
+
+// sameNetworkKey returns whether a cached connection pinned to the cached key can be used for a node with the
+// expected networking key. The keys are both nil if TLS is disabled.
+func sameNetworkKey(cached crypto.PublicKey, expected crypto.PublicKey) bool {
+	if cached == nil || expected == nil {
+		return cached == nil && expected == nil
+	}
+	return cached.Equals(expected)
+}
+
+// getConnection returns a connection to the given address, which is taken from the connections cache if enabled.
+// The returned closer must be called once the connection is no longer needed.
+func (cf *ConnectionFactoryImpl) getConnection(
+	address string,
+	timeout time.Duration,
+	creds credentials.TransportCredentials,
+	networkKey crypto.PublicKey,
+) (*grpc.ClientConn, io.Closer, error) {
+	if cf.CircuitBreaker != nil && !cf.CircuitBreaker.Allow(address) {
+		return nil, nil, status.Errorf(codes.Unavailable, "circuit breaker is open for node %s", address)
+	}
+
+	if cf.ConnectionsCache != nil {
+		conn, err := cf.retrieveConnection(address, timeout, creds, networkKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		// cached connections are closed once evicted from the cache
+		return conn, &noopCloser{}, nil
+	}
+
+	conn, err := cf.createConnection(address, timeout, creds)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, io.Closer(conn), nil
+}
+
 func (cf *ConnectionFactoryImpl) GetAccessAPIClient(address string) (access.AccessAPIClient, io.Closer, error) {
 
 	grpcAddress, err := getGRPCAddress(address, cf.CollectionGRPCPort)
 	if err != nil {
 		return nil, nil, err
 	}
-	conn, err := cf.createConnection(grpcAddress, cf.CollectionNodeGRPCTimeout)
+	creds, networkKey, err := cf.transportCredentials(address, flow.RoleCollection, cf.CollectionNodeTLS)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, closer, err := cf.getConnection(grpcAddress, cf.CollectionNodeGRPCTimeout, creds, networkKey)
 	if err != nil {
 		return nil, nil, err
 	}
 	accessAPIClient := access.NewAccessAPIClient(conn)
-	closer := io.Closer(conn)
 	return accessAPIClient, closer, nil
 }
 
 func (cf *ConnectionFactoryImpl) GetExecutionAPIClient(address string) (execution.ExecutionAPIClient, io.Closer, error) {
+	conn, closer, err := cf.getExecutionConnection(address)
+	if err != nil {
+		return nil, nil, err
+	}
+	executionAPIClient := execution.NewExecutionAPIClient(conn)
+	return executionAPIClient, closer, nil
+}
+
+// GetExtendedExecutionAPIClient returns a client of the ExtendedExecutionAPI, which is served by the execution
+// nodes next to the Execution API, so both clients share the connection to a node.
+func (cf *ConnectionFactoryImpl) GetExtendedExecutionAPIClient(address string) (extended.ExtendedExecutionAPIClient, io.Closer, error) {
+	conn, closer, err := cf.getExecutionConnection(address)
+	if err != nil {
+		return nil, nil, err
+	}
+	extendedExecutionAPIClient := extended.NewExtendedExecutionAPIClient(conn)
+	return extendedExecutionAPIClient, closer, nil
+}
+
+func (cf *ConnectionFactoryImpl) getExecutionConnection(address string) (*grpc.ClientConn, io.Closer, error) {
 
 	grpcAddress, err := getGRPCAddress(address, cf.ExecutionGRPCPort)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	conn, err := cf.createConnection(grpcAddress, cf.ExecutionNodeGRPCTimeout)
+	creds, networkKey, err := cf.transportCredentials(address, flow.RoleExecution, cf.ExecutionNodeTLS)
 	if err != nil {
 		return nil, nil, err
 	}
-	executionAPIClient := execution.NewExecutionAPIClient(conn)
-	closer := io.Closer(conn)
-	return executionAPIClient, closer, nil
+	return cf.getConnection(grpcAddress, cf.ExecutionNodeGRPCTimeout, creds, networkKey)
+}
+
+// transportCredentials returns the TLS credentials for a connection to the node of the given role with the given
+// address, and the networking key of the node they are pinned to, or nil if TLS is not enabled for the role. The node
+// must be part of the identity table.
+func (cf *ConnectionFactoryImpl) transportCredentials(
+	address string,
+	role flow.Role,
+	nodeTLS *NodeTLS,
+) (credentials.TransportCredentials, crypto.PublicKey, error) {
+	if nodeTLS == nil {
+		return nil, nil, nil
+	}
+
+	identity, err := cf.nodeIdentity(address, role)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	creds, err := nodeTLS.transportCredentials(identity)
+	if err != nil {
+		return nil, nil, err
+	}
+	return creds, identity.NetworkPubKey, nil
+}
+
+// nodeIdentity returns the identity of the node of the given role with the given address. The nodes of a role are
+// looked up in the identity table on the first request and cached until InvalidateIdentities is called.
+func (cf *ConnectionFactoryImpl) nodeIdentity(address string, role flow.Role) (*flow.Identity, error) {
+	cf.identitiesMu.RLock()
+	byAddress, ok := cf.identities[role]
+	version := cf.identitiesVersion
+	cf.identitiesMu.RUnlock()
+
+	if !ok {
+		identities, err := cf.State.Final().Identities(filter.HasRole(role))
+		if err != nil {
+			return nil, fmt.Errorf("could not get %s node identities: %w", role, err)
+		}
+
+		byAddress = make(map[string]*flow.Identity, len(identities))
+		for _, identity := range identities {
+			byAddress[identity.Address] = identity
+		}
+
+		cf.identitiesMu.Lock()
+		// do not cache identities looked up before an invalidation, they might be outdated
+		if cf.identitiesVersion == version {
+			if cf.identities == nil {
+				cf.identities = make(map[flow.Role]map[string]*flow.Identity)
+			}
+			cf.identities[role] = byAddress
+		}
+		cf.identitiesMu.Unlock()
+	}
+
+	identity, ok := byAddress[address]
+	if !ok {
+		return nil, status.Errorf(codes.Unavailable, "no %s node with address %s found in the identity table", role, address)
+	}
+	return identity, nil
+}
+
+// InvalidateIdentities drops the cached identities of the collection and execution nodes, so they are looked up again
+// in the identity table on the next request. It must be called when the identity table changes, e.g. on epoch
+// transitions.
+func (cf *ConnectionFactoryImpl) InvalidateIdentities() {
+	cf.identitiesMu.Lock()
+	defer cf.identitiesMu.Unlock()
+
+	cf.identities = nil
+	cf.identitiesVersion++
+}
+
+type noopCloser struct{}
+
+func (c *noopCloser) Close() error {
+	return nil
 }
 
 // getExecutionNodeAddress translates flow.Identity address to the GRPC address of the node by switching the port to the
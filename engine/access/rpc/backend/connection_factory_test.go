@@ -2,21 +2,39 @@ package backend
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	"net"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	lcrypto "github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/onflow/flow/protobuf/go/flow/access"
 	"github.com/onflow/flow/protobuf/go/flow/execution"
 	"github.com/stretchr/testify/assert"
 	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/status"
 
+	fcrypto "github.com/onflow/flow-go/crypto"
 	"github.com/onflow/flow-go/engine/access/mock"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/network/p2p/keyutils"
+	protocolmock "github.com/onflow/flow-go/state/protocol/mock"
+	"github.com/onflow/flow-go/utils/grpcutils"
+	"github.com/onflow/flow-go/utils/unittest"
 )
 
 func TestProxyAccessAPI(t *testing.T) {
@@ -155,15 +173,501 @@ func TestCollectionNodeClientTimeout(t *testing.T) {
 	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
 }
 
+// TestExecutionNodeClientCaching tests that connections to execution nodes are reused from the cache
+func TestExecutionNodeClientCaching(t *testing.T) {
+	// create an execution node
+	en := new(executionNode)
+	en.start(t)
+	defer en.stop(t)
+
+	req := &execution.PingRequest{}
+	resp := &execution.PingResponse{}
+	en.handler.On("Ping", testifymock.Anything, req).Return(resp, nil)
+
+	// create the factory with a connections cache
+	connectionFactory := new(ConnectionFactoryImpl)
+	connectionFactory.ExecutionGRPCPort = en.port
+	connectionFactory.AccessMetrics = metrics.NewNoopCollector()
+	cache, err := NewConnectionsCache(2, connectionFactory.AccessMetrics)
+	require.NoError(t, err)
+	connectionFactory.ConnectionsCache = cache
+
+	address := en.listener.Addr().String()
+	grpcAddress, err := getGRPCAddress(address, en.port)
+	require.NoError(t, err)
+
+	client, closer, err := connectionFactory.GetExecutionAPIClient(address)
+	require.NoError(t, err)
+	_, err = client.Ping(context.Background(), req)
+	require.NoError(t, err)
+
+	// closing the client must not close the cached connection
+	require.NoError(t, closer.Close())
+
+	res, ok := cache.Get(grpcAddress)
+	require.True(t, ok)
+	conn := res.(*cachedClient).conn
+	assert.NotEqual(t, connectivity.Shutdown, conn.GetState())
+
+	// the cached connection is reused
+	client, _, err = connectionFactory.GetExecutionAPIClient(address)
+	require.NoError(t, err)
+	_, err = client.Ping(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cache.Len())
+
+	res, ok = cache.Get(grpcAddress)
+	require.True(t, ok)
+	assert.Same(t, conn, res.(*cachedClient).conn)
+}
+
+// connectionMetrics counts the connections removed from the connections cache.
+type connectionMetrics struct {
+	*metrics.NoopCollector
+	invalidated int
+	evicted     int
+}
+
+func (m *connectionMetrics) ConnectionFromPoolInvalidated() {
+	m.invalidated++
+}
+
+func (m *connectionMetrics) ConnectionFromPoolEvicted() {
+	m.evicted++
+}
+
+// TestConnectionsCacheIdleEviction tests that cached connections which have not been used for longer than the
+// max idle time are evicted and closed
+func TestConnectionsCacheIdleEviction(t *testing.T) {
+	en := new(executionNode)
+	en.start(t)
+	defer en.stop(t)
+
+	accessMetrics := &connectionMetrics{NoopCollector: metrics.NewNoopCollector()}
+	connectionFactory := new(ConnectionFactoryImpl)
+	connectionFactory.ExecutionGRPCPort = en.port
+	connectionFactory.ExecutionNodeGRPCTimeout = 10 * time.Millisecond
+	connectionFactory.MaxIdleTime = 10 * time.Millisecond
+	connectionFactory.AccessMetrics = accessMetrics
+	cache, err := NewConnectionsCache(2, connectionFactory.AccessMetrics)
+	require.NoError(t, err)
+	connectionFactory.ConnectionsCache = cache
+
+	address := en.listener.Addr().String()
+	grpcAddress, err := getGRPCAddress(address, en.port)
+	require.NoError(t, err)
+
+	_, _, err = connectionFactory.GetExecutionAPIClient(address)
+	require.NoError(t, err)
+	res, ok := cache.Get(grpcAddress)
+	require.True(t, ok)
+	idleConn := res.(*cachedClient).conn
+
+	// connections which have not been idle for long enough are kept
+	connectionFactory.EvictIdleConnections()
+	assert.Equal(t, 1, cache.Len())
+
+	time.Sleep(20 * time.Millisecond)
+
+	// the idle connection is evicted
+	connectionFactory.EvictIdleConnections()
+	assert.Equal(t, 0, cache.Len())
+	assert.Equal(t, 1, accessMetrics.evicted)
+	assert.Equal(t, 0, accessMetrics.invalidated)
+
+	// and replaced by a new connection
+	_, _, err = connectionFactory.GetExecutionAPIClient(address)
+	require.NoError(t, err)
+	res, ok = cache.Get(grpcAddress)
+	require.True(t, ok)
+	assert.NotSame(t, idleConn, res.(*cachedClient).conn)
+
+	// the evicted connection is closed once requests in flight have timed out
+	assert.Eventually(t, func() bool {
+		return idleConn.GetState() == connectivity.Shutdown
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestConnectionsCacheInvalidation tests that cached connections which can't be reused are replaced, and only
+// recorded as invalidated
+func TestConnectionsCacheInvalidation(t *testing.T) {
+	en := new(executionNode)
+	en.start(t)
+	defer en.stop(t)
+
+	accessMetrics := &connectionMetrics{NoopCollector: metrics.NewNoopCollector()}
+	connectionFactory := new(ConnectionFactoryImpl)
+	connectionFactory.ExecutionGRPCPort = en.port
+	connectionFactory.AccessMetrics = accessMetrics
+	cache, err := NewConnectionsCache(2, connectionFactory.AccessMetrics)
+	require.NoError(t, err)
+	connectionFactory.ConnectionsCache = cache
+
+	address := en.listener.Addr().String()
+	grpcAddress, err := getGRPCAddress(address, en.port)
+	require.NoError(t, err)
+
+	_, _, err = connectionFactory.GetExecutionAPIClient(address)
+	require.NoError(t, err)
+	res, ok := cache.Get(grpcAddress)
+	require.True(t, ok)
+	closedConn := res.(*cachedClient).conn
+	require.NoError(t, closedConn.Close())
+
+	_, _, err = connectionFactory.GetExecutionAPIClient(address)
+	require.NoError(t, err)
+	res, ok = cache.Get(grpcAddress)
+	require.True(t, ok)
+	assert.NotSame(t, closedConn, res.(*cachedClient).conn)
+
+	assert.Equal(t, 1, accessMetrics.invalidated)
+	assert.Equal(t, 0, accessMetrics.evicted)
+}
+
+// TestCircuitBreaker tests that no requests are made to an execution node after it failed repeatedly,
+// until the restore timeout has passed
+func TestCircuitBreaker(t *testing.T) {
+	timeout := 10 * time.Millisecond
+	restoreTimeout := 100 * time.Millisecond
+
+	en := new(executionNode)
+	en.start(t)
+	defer en.stop(t)
+
+	// the execution node does not respond within the timeout
+	req := &execution.PingRequest{}
+	resp := &execution.PingResponse{}
+	en.handler.On("Ping", testifymock.Anything, req).After(timeout+time.Second).Return(resp, nil).Twice()
+
+	connectionFactory := new(ConnectionFactoryImpl)
+	connectionFactory.ExecutionGRPCPort = en.port
+	connectionFactory.ExecutionNodeGRPCTimeout = timeout
+	connectionFactory.CircuitBreaker = NewCircuitBreaker(CircuitBreakerConfig{
+		Enabled:        true,
+		MaxFailures:    2,
+		RestoreTimeout: restoreTimeout,
+	})
+
+	address := en.listener.Addr().String()
+	ping := func() error {
+		client, closer, err := connectionFactory.GetExecutionAPIClient(address)
+		if err != nil {
+			return err
+		}
+		defer closer.Close()
+		_, err = client.Ping(context.Background(), req)
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(ping()))
+	}
+
+	// the circuit breaker is open, so no client is handed out
+	_, _, err := connectionFactory.GetExecutionAPIClient(address)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// once the restore timeout has passed, requests are attempted again and close the circuit breaker on success
+	en.handler.On("Ping", testifymock.Anything, req).Return(resp, nil)
+	time.Sleep(restoreTimeout)
+	assert.NoError(t, ping())
+	assert.NoError(t, ping())
+}
+
+// TestCircuitBreakerHalfOpen tests that a single probe request is let through once the restore timeout has passed
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	restoreTimeout := 50 * time.Millisecond
+	address := "en:9000"
+
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		Enabled:        true,
+		MaxFailures:    2,
+		RestoreTimeout: restoreTimeout,
+	})
+
+	cb.onResult(address, true)
+	assert.True(t, cb.Allow(address))
+	cb.onResult(address, true)
+	assert.False(t, cb.Allow(address))
+
+	// only one probe is let through
+	time.Sleep(restoreTimeout)
+	assert.True(t, cb.Allow(address))
+	assert.False(t, cb.Allow(address))
+
+	// the failed probe opens the circuit breaker again
+	cb.onResult(address, true)
+	assert.False(t, cb.Allow(address))
+
+	// another probe is let through if the outcome of the previous probe is not recorded
+	time.Sleep(restoreTimeout)
+	assert.True(t, cb.Allow(address))
+	assert.False(t, cb.Allow(address))
+	time.Sleep(restoreTimeout)
+	assert.True(t, cb.Allow(address))
+
+	// the successful probe closes the circuit breaker
+	cb.onResult(address, false)
+	assert.True(t, cb.Allow(address))
+	assert.True(t, cb.Allow(address))
+}
+
+// TestExecutionNodeTLS tests that the certificate of an execution node is pinned against the networking key of the
+// node in the identity table
+func TestExecutionNodeTLS(t *testing.T) {
+	networkingKey := unittest.NetworkingPrivKeyFixture()
+	cert, err := grpcutils.X509Certificate(networkingKey)
+	require.NoError(t, err)
+
+	// create an execution node serving gRPC with the certificate generated from its networking key
+	en := new(executionNode)
+	en.opts = []grpc.ServerOption{grpc.Creds(credentials.NewTLS(grpcutils.DefaultServerTLSConfig(cert)))}
+	en.start(t)
+	defer en.stop(t)
+
+	req := &execution.PingRequest{}
+	resp := &execution.PingResponse{}
+	en.handler.On("Ping", testifymock.Anything, req).Return(resp, nil)
+
+	address := en.listener.Addr().String()
+	identity := &flow.Identity{
+		NodeID:        unittest.IdentifierFixture(),
+		Address:       address,
+		Role:          flow.RoleExecution,
+		NetworkPubKey: networkingKey.PublicKey(),
+	}
+
+	snapshot := new(protocolmock.Snapshot)
+	snapshot.On("Identities", testifymock.Anything).Return(
+		func(selector flow.IdentityFilter) flow.IdentityList {
+			return flow.IdentityList{identity}.Filter(selector)
+		},
+		func(flow.IdentityFilter) error { return nil },
+	)
+	state := new(protocolmock.State)
+	state.On("Final").Return(snapshot)
+
+	nodeTLS, err := NewNodeTLS(NodeTLSConfig{Enabled: true})
+	require.NoError(t, err)
+
+	connectionFactory := new(ConnectionFactoryImpl)
+	connectionFactory.ExecutionGRPCPort = en.port
+	connectionFactory.ExecutionNodeTLS = nodeTLS
+	connectionFactory.State = state
+
+	t.Run("pinned networking key", func(t *testing.T) {
+		client, closer, err := connectionFactory.GetExecutionAPIClient(address)
+		require.NoError(t, err)
+		defer closer.Close()
+
+		_, err = client.Ping(context.Background(), req)
+		require.NoError(t, err)
+	})
+
+	t.Run("unexpected networking key", func(t *testing.T) {
+		identity.NetworkPubKey = unittest.NetworkingPrivKeyFixture().PublicKey()
+		defer func() { identity.NetworkPubKey = networkingKey.PublicKey() }()
+
+		client, closer, err := connectionFactory.GetExecutionAPIClient(address)
+		require.NoError(t, err)
+		defer closer.Close()
+
+		_, err = client.Ping(context.Background(), req)
+		require.Equal(t, codes.Unavailable, status.Code(err))
+	})
+
+	t.Run("node not in identity table", func(t *testing.T) {
+		_, _, err := connectionFactory.GetExecutionAPIClient("unknown:3569")
+		require.Error(t, err)
+	})
+
+	t.Run("TLS disabled for collection nodes", func(t *testing.T) {
+		creds, networkKey, err := connectionFactory.transportCredentials(address, flow.RoleCollection, connectionFactory.CollectionNodeTLS)
+		require.NoError(t, err)
+		require.Nil(t, creds)
+		require.Nil(t, networkKey)
+	})
+}
+
+// TestExecutionNodeTLSWithCA tests that the certificate of an execution node signed by a certificate authority is
+// pinned against the networking key of the node in the identity table
+func TestExecutionNodeTLSWithCA(t *testing.T) {
+	networkingKey := unittest.NetworkingPrivKeyFixture()
+	otherNetworkingKey := unittest.NetworkingPrivKeyFixture()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(caCert)
+
+	// issueCertificate returns a certificate for the given networking key signed by the certificate authority
+	issueCertificate := func(key fcrypto.PrivateKey) tls.Certificate {
+		libP2PKey, err := keyutils.LibP2PPrivKeyFromFlow(key)
+		require.NoError(t, err)
+		stdKey, err := lcrypto.PrivKeyToStdKey(libP2PKey)
+		require.NoError(t, err)
+		ecdsaKey := stdKey.(*ecdsa.PrivateKey)
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: "execution node"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			IPAddresses:  []net.IP{net.IPv4zero, net.IPv4(127, 0, 0, 1)},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &ecdsaKey.PublicKey, caKey)
+		require.NoError(t, err)
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: ecdsaKey}
+	}
+
+	req := &execution.PingRequest{}
+	resp := &execution.PingResponse{}
+
+	ping := func(t *testing.T, cert tls.Certificate) error {
+		en := new(executionNode)
+		en.opts = []grpc.ServerOption{grpc.Creds(credentials.NewTLS(grpcutils.DefaultServerTLSConfig(&cert)))}
+		en.start(t)
+		defer en.stop(t)
+		en.handler.On("Ping", testifymock.Anything, req).Return(resp, nil)
+
+		address := en.listener.Addr().String()
+		identity := &flow.Identity{
+			NodeID:        unittest.IdentifierFixture(),
+			Address:       address,
+			Role:          flow.RoleExecution,
+			NetworkPubKey: networkingKey.PublicKey(),
+		}
+		snapshot := new(protocolmock.Snapshot)
+		snapshot.On("Identities", testifymock.Anything).Return(flow.IdentityList{identity}, nil)
+		state := new(protocolmock.State)
+		state.On("Final").Return(snapshot)
+
+		connectionFactory := new(ConnectionFactoryImpl)
+		connectionFactory.ExecutionGRPCPort = en.port
+		connectionFactory.ExecutionNodeTLS = &NodeTLS{rootCAs: rootCAs}
+		connectionFactory.State = state
+
+		client, closer, err := connectionFactory.GetExecutionAPIClient(address)
+		require.NoError(t, err)
+		defer closer.Close()
+
+		_, err = client.Ping(context.Background(), req)
+		return err
+	}
+
+	t.Run("certificate issued for the networking key", func(t *testing.T) {
+		require.NoError(t, ping(t, issueCertificate(networkingKey)))
+	})
+
+	t.Run("certificate issued for another key", func(t *testing.T) {
+		require.Equal(t, codes.Unavailable, status.Code(ping(t, issueCertificate(otherNetworkingKey))))
+	})
+}
+
+// TestNodeIdentitiesCaching tests that the identities of the nodes are looked up in the identity table once until
+// they are invalidated, and that cached connections are rebuilt when the networking key of a node changes
+func TestNodeIdentitiesCaching(t *testing.T) {
+	networkingKey := unittest.NetworkingPrivKeyFixture()
+	cert, err := grpcutils.X509Certificate(networkingKey)
+	require.NoError(t, err)
+
+	en := new(executionNode)
+	en.opts = []grpc.ServerOption{grpc.Creds(credentials.NewTLS(grpcutils.DefaultServerTLSConfig(cert)))}
+	en.start(t)
+	defer en.stop(t)
+
+	req := &execution.PingRequest{}
+	resp := &execution.PingResponse{}
+	en.handler.On("Ping", testifymock.Anything, req).Return(resp, nil)
+
+	address := en.listener.Addr().String()
+	grpcAddress, err := getGRPCAddress(address, en.port)
+	require.NoError(t, err)
+
+	identities := flow.IdentityList{{
+		NodeID:        unittest.IdentifierFixture(),
+		Address:       address,
+		Role:          flow.RoleExecution,
+		NetworkPubKey: networkingKey.PublicKey(),
+	}}
+	snapshot := new(protocolmock.Snapshot)
+	snapshot.On("Identities", testifymock.Anything).Return(
+		func(flow.IdentityFilter) flow.IdentityList { return identities },
+		func(flow.IdentityFilter) error { return nil },
+	)
+	state := new(protocolmock.State)
+	state.On("Final").Return(snapshot)
+
+	nodeTLS, err := NewNodeTLS(NodeTLSConfig{Enabled: true})
+	require.NoError(t, err)
+
+	connectionFactory := new(ConnectionFactoryImpl)
+	connectionFactory.ExecutionGRPCPort = en.port
+	connectionFactory.ExecutionNodeTLS = nodeTLS
+	connectionFactory.State = state
+	connectionFactory.AccessMetrics = metrics.NewNoopCollector()
+	cache, err := NewConnectionsCache(2, connectionFactory.AccessMetrics)
+	require.NoError(t, err)
+	connectionFactory.ConnectionsCache = cache
+
+	ping := func() error {
+		client, closer, err := connectionFactory.GetExecutionAPIClient(address)
+		require.NoError(t, err)
+		defer closer.Close()
+		_, err = client.Ping(context.Background(), req)
+		return err
+	}
+
+	require.NoError(t, ping())
+	require.NoError(t, ping())
+	snapshot.AssertNumberOfCalls(t, "Identities", 1)
+
+	res, ok := cache.Get(grpcAddress)
+	require.True(t, ok)
+	conn := res.(*cachedClient).conn
+
+	// the networking key of the node changes in the next epoch, so the cached connection is not reused
+	identities = flow.IdentityList{{
+		NodeID:        identities[0].NodeID,
+		Address:       address,
+		Role:          flow.RoleExecution,
+		NetworkPubKey: unittest.NetworkingPrivKeyFixture().PublicKey(),
+	}}
+	connectionFactory.InvalidateIdentities()
+
+	require.Equal(t, codes.Unavailable, status.Code(ping()))
+	snapshot.AssertNumberOfCalls(t, "Identities", 2)
+
+	res, ok = cache.Get(grpcAddress)
+	require.True(t, ok)
+	assert.NotSame(t, conn, res.(*cachedClient).conn)
+}
+
 // node mocks a flow node that runs a GRPC server
 type node struct {
 	server   *grpc.Server
+	opts     []grpc.ServerOption
 	listener net.Listener
 	port     uint
 }
 
 func (n *node) setupNode(t *testing.T) {
-	n.server = grpc.NewServer()
+	n.server = grpc.NewServer(n.opts...)
 	listener, err := net.Listen("tcp4", ":0")
 	assert.NoError(t, err)
 	n.listener = listener
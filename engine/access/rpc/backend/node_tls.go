@@ -0,0 +1,67 @@
+package backend
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/grpcutils"
+)
+
+// NodeTLSConfig configures TLS for the requests of the access node to the nodes of a role.
+type NodeTLSConfig struct {
+	Enabled bool // whether to use TLS
+	// CACertFile is the path to a PEM file of the certificate authorities signing the certificates of the nodes.
+	// If empty, the nodes must present the certificate generated from their networking key (see
+	// grpcutils.X509Certificate). In both cases, the key of the certificate is pinned against the networking key of
+	// the node in the identity table, so the certificate authorities must issue the certificates for these keys.
+	CACertFile string
+}
+
+// NodeTLS holds the TLS configuration for the nodes of a role.
+type NodeTLS struct {
+	rootCAs *x509.CertPool // certificate authorities signing the node certificates, nil if the networking keys are pinned
+}
+
+// NewNodeTLS loads the TLS configuration for the nodes of a role. It returns nil if TLS is not enabled.
+func NewNodeTLS(config NodeTLSConfig) (*NodeTLS, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+
+	if config.CACertFile == "" {
+		return &NodeTLS{}, nil
+	}
+
+	pem, err := os.ReadFile(config.CACertFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read CA certificates from %s: %w", config.CACertFile, err)
+	}
+
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no valid CA certificate found in %s", config.CACertFile)
+	}
+
+	return &NodeTLS{rootCAs: rootCAs}, nil
+}
+
+// transportCredentials returns the credentials for a connection to the given node.
+func (t *NodeTLS) transportCredentials(identity *flow.Identity) (credentials.TransportCredentials, error) {
+	var tlsConfig *tls.Config
+	var err error
+	if t.rootCAs != nil {
+		// the server name is taken from the node address in the identity table
+		tlsConfig, err = grpcutils.CAClientTLSConfig(t.rootCAs, identity.NetworkPubKey)
+	} else {
+		tlsConfig, err = grpcutils.DefaultClientTLSConfig(identity.NetworkPubKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create TLS config for node %v: %w", identity.NodeID, err)
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
@@ -0,0 +1,92 @@
+package rpc
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/engine/access/ratelimit"
+)
+
+// clientRateLimiterInterceptor rate limits the calls of each client to each API method
+type clientRateLimiterInterceptor struct {
+	log     zerolog.Logger
+	limiter *ratelimit.ClientLimiter
+}
+
+func newClientRateLimiterInterceptor(log zerolog.Logger, limiter *ratelimit.ClientLimiter) *clientRateLimiterInterceptor {
+	return &clientRateLimiterInterceptor{
+		log:     log,
+		limiter: limiter,
+	}
+}
+
+// unaryServerInterceptor rejects the request if the client exceeded its rate limit for the method
+func (interceptor *clientRateLimiterInterceptor) unaryServerInterceptor(ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (resp interface{}, err error) {
+
+	err = interceptor.allow(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// streamServerInterceptor rejects the stream if the client exceeded its rate limit for the method
+func (interceptor *clientRateLimiterInterceptor) streamServerInterceptor(srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+
+	err := interceptor.allow(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, stream)
+}
+
+func (interceptor *clientRateLimiterInterceptor) allow(ctx context.Context, fullMethod string) error {
+	// remove the package name (e.g. "/flow.access.AccessAPI/Ping" to "Ping")
+	methodName := filepath.Base(fullMethod)
+	client := interceptor.clientID(ctx)
+
+	if interceptor.limiter.Allow(client, methodName) {
+		return nil
+	}
+
+	interceptor.log.Trace().
+		Str("method", methodName).
+		Str("client", client).
+		Msg("client rate limit exceeded")
+
+	return status.Errorf(codes.ResourceExhausted, "%s rate limit reached, please retry later.", fullMethod)
+}
+
+// clientID identifies the client by the configured API key metadata if present, and by its IP address otherwise
+func (interceptor *clientRateLimiterInterceptor) clientID(ctx context.Context) string {
+	var apiKey string
+	if header := interceptor.limiter.APIKeyHeader(); header != "" {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(header); len(values) > 0 {
+				apiKey = values[0]
+			}
+		}
+	}
+
+	var address string
+	if p, ok := peer.FromContext(ctx); ok {
+		address = p.Addr.String()
+	}
+
+	return ratelimit.ClientID(apiKey, address)
+}
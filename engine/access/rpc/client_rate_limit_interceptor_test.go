@@ -0,0 +1,52 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/engine/access/ratelimit"
+	"github.com/onflow/flow-go/module/metrics"
+)
+
+func TestClientRateLimiterInterceptor(t *testing.T) {
+	limiter, err := ratelimit.NewClientLimiter(ratelimit.Config{
+		MethodLimits: map[string]int{"ExecuteScriptAtLatestBlock": 1},
+		APIKeyHeader: "x-api-key",
+	}, metrics.NewNoopCollector())
+	require.NoError(t, err)
+
+	interceptor := newClientRateLimiterInterceptor(zerolog.Nop(), limiter)
+	info := &grpc.UnaryServerInfo{FullMethod: "/flow.access.AccessAPI/ExecuteScriptAtLatestBlock"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	call := func(ip string, apiKey string) error {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{
+			Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 3569},
+		})
+		if apiKey != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-api-key", apiKey))
+		}
+		_, err := interceptor.unaryServerInterceptor(ctx, nil, info, handler)
+		return err
+	}
+
+	assert.NoError(t, call("10.0.0.1", ""))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call("10.0.0.1", "")))
+
+	// other clients have their own budget
+	assert.NoError(t, call("10.0.0.2", ""))
+	assert.NoError(t, call("10.0.0.1", "key1"))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call("10.0.0.3", "key1")))
+}
@@ -17,13 +17,16 @@ import (
 	"google.golang.org/grpc/credentials"
 
 	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/access/extended"
 	legacyaccess "github.com/onflow/flow-go/access/legacy"
 	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/engine/access/ratelimit"
 	"github.com/onflow/flow-go/engine/access/rest"
 	"github.com/onflow/flow-go/engine/access/rpc/backend"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module"
 	"github.com/onflow/flow-go/state/protocol"
+	"github.com/onflow/flow-go/state/protocol/events"
 	"github.com/onflow/flow-go/storage"
 	"github.com/onflow/flow-go/utils/grpcutils"
 )
@@ -45,12 +48,21 @@ type Config struct {
 	MaxHeightRange            uint                             // max size of height range requests
 	PreferredExecutionNodeIDs []string                         // preferred list of upstream execution node IDs
 	FixedExecutionNodeIDs     []string                         // fixed list of execution node IDs to choose from if no node node ID can be chosen from the PreferredExecutionNodeIDs
+	ConnectionPoolSize        uint                             // size of the cache of connections to collection and execution nodes, connections are not reused if 0
+	MaxConnectionIdleTime     time.Duration                    // cached connections unused for longer are closed, if non zero. Checked at the same interval
+	CircuitBreakerConfig      backend.CircuitBreakerConfig     // configuration of the circuit breaker for collection and execution nodes
+	CollectionNodeTLS         backend.NodeTLSConfig            // TLS configuration for requests to collection nodes
+	ExecutionNodeTLS          backend.NodeTLSConfig            // TLS configuration for requests to execution nodes
+	ClientRateLimits          ratelimit.Config                 // per-client rate limits of the gRPC and REST API methods
+	SimulateTransactions      bool                             // whether transactions are simulated against the locally indexed execution state
 }
 
 // Engine exposes the server with a simplified version of the Access API.
 // An unsecured GRPC server (default port 9000), a secure GRPC server (default port 9001) and an HTTP Web proxy (default
 // port 8000) are brought up.
 type Engine struct {
+	events.Noop
+
 	unit               *engine.Unit
 	log                zerolog.Logger
 	backend            *backend.Backend // the gRPC service implementation
@@ -60,6 +72,8 @@ type Engine struct {
 	restServer         *http.Server
 	config             Config
 	chain              flow.Chain
+	clientLimiter      *ratelimit.ClientLimiter // rate limits each client, nil if no per-client rate limits are configured
+	connectionFactory  *backend.ConnectionFactoryImpl
 
 	addrLock            sync.RWMutex
 	unsecureGrpcAddress net.Addr
@@ -81,13 +95,15 @@ func New(log zerolog.Logger,
 	executionResults storage.ExecutionResults,
 	chainID flow.ChainID,
 	transactionMetrics module.TransactionMetrics,
+	accessMetrics module.AccessMetrics,
+	scriptExecutor backend.ScriptExecutor, // executes scripts locally if not nil
 	collectionGRPCPort uint,
 	executionGRPCPort uint,
 	retryEnabled bool,
 	rpcMetricsEnabled bool,
 	apiRatelimits map[string]int, // the api rate limit (max calls per second) for each of the Access API e.g. Ping->100, GetTransaction->300
 	apiBurstLimits map[string]int, // the api burst limit (max calls at the same time) for each of the Access API e.g. Ping->50, GetTransaction->10
-) *Engine {
+) (*Engine, error) {
 
 	log = log.With().Str("engine", "rpc").Logger()
 
@@ -102,6 +118,7 @@ func New(log zerolog.Logger,
 	}
 
 	var interceptors []grpc.UnaryServerInterceptor // ordered list of interceptors
+	var streamInterceptors []grpc.StreamServerInterceptor
 	// if rpc metrics is enabled, first create the grpc metrics interceptor
 	if rpcMetricsEnabled {
 		interceptors = append(interceptors, grpc_prometheus.UnaryServerInterceptor)
@@ -114,12 +131,28 @@ func New(log zerolog.Logger,
 		interceptors = append(interceptors, rateLimitInterceptor)
 	}
 
+	var clientLimiter *ratelimit.ClientLimiter
+	if config.ClientRateLimits.Enabled() {
+		var err error
+		clientLimiter, err = ratelimit.NewClientLimiter(config.ClientRateLimits, accessMetrics)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize client rate limiter: %w", err)
+		}
+		// create a per-client rate limit interceptor for unary calls and subscriptions
+		clientRateLimitInterceptor := newClientRateLimiterInterceptor(log, clientLimiter)
+		interceptors = append(interceptors, clientRateLimitInterceptor.unaryServerInterceptor)
+		streamInterceptors = append(streamInterceptors, clientRateLimitInterceptor.streamServerInterceptor)
+	}
+
 	// add the logging interceptor, ensure it is innermost wrapper
 	interceptors = append(interceptors, loggingInterceptor(log)...)
 
 	// create a chained unary interceptor
 	chainedInterceptors := grpc.ChainUnaryInterceptor(interceptors...)
 	grpcOpts = append(grpcOpts, chainedInterceptors)
+	if len(streamInterceptors) > 0 {
+		grpcOpts = append(grpcOpts, grpc.ChainStreamInterceptor(streamInterceptors...))
+	}
 
 	// create an unsecured grpc server
 	unsecureGrpcServer := grpc.NewServer(grpcOpts...)
@@ -136,6 +169,31 @@ func New(log zerolog.Logger,
 		ExecutionGRPCPort:         executionGRPCPort,
 		CollectionNodeGRPCTimeout: config.CollectionClientTimeout,
 		ExecutionNodeGRPCTimeout:  config.ExecutionClientTimeout,
+		MaxIdleTime:               config.MaxConnectionIdleTime,
+		AccessMetrics:             accessMetrics,
+		State:                     state,
+	}
+
+	var err error
+	connectionFactory.CollectionNodeTLS, err = backend.NewNodeTLS(config.CollectionNodeTLS)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize TLS for collection nodes: %w", err)
+	}
+	connectionFactory.ExecutionNodeTLS, err = backend.NewNodeTLS(config.ExecutionNodeTLS)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize TLS for execution nodes: %w", err)
+	}
+
+	if config.ConnectionPoolSize > 0 {
+		cache, err := backend.NewConnectionsCache(config.ConnectionPoolSize, accessMetrics)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize connections cache: %w", err)
+		}
+		connectionFactory.ConnectionsCache = cache
+	}
+
+	if config.CircuitBreakerConfig.Enabled {
+		connectionFactory.CircuitBreaker = backend.NewCircuitBreaker(config.CircuitBreakerConfig)
 	}
 
 	backend := backend.New(state,
@@ -156,8 +214,16 @@ func New(log zerolog.Logger,
 		config.FixedExecutionNodeIDs,
 		log,
 		backend.DefaultSnapshotHistoryLimit,
+		scriptExecutor,
 	)
 
+	if config.SimulateTransactions {
+		if scriptExecutor == nil {
+			return nil, fmt.Errorf("transaction simulation requires local script execution")
+		}
+		backend.EnableTransactionSimulation(scriptExecutor)
+	}
+
 	eng := &Engine{
 		log:                log,
 		unit:               engine.NewUnit(),
@@ -167,6 +233,8 @@ func New(log zerolog.Logger,
 		httpServer:         httpServer,
 		config:             config,
 		chain:              chainID.Chain(),
+		clientLimiter:      clientLimiter,
+		connectionFactory:  connectionFactory,
 	}
 
 	accessproto.RegisterAccessAPIServer(
@@ -179,6 +247,16 @@ func New(log zerolog.Logger,
 		access.NewHandler(backend, chainID.Chain()),
 	)
 
+	extended.RegisterExtendedAccessAPIServer(
+		eng.unsecureGrpcServer,
+		access.NewExtendedHandler(backend, chainID.Chain()),
+	)
+
+	extended.RegisterExtendedAccessAPIServer(
+		eng.secureGrpcServer,
+		access.NewExtendedHandler(backend, chainID.Chain()),
+	)
+
 	if rpcMetricsEnabled {
 		// Not interested in legacy metrics, so initialize here
 		grpc_prometheus.EnableHandlingTimeHistogram()
@@ -196,7 +274,19 @@ func New(log zerolog.Logger,
 		legacyaccess.NewHandler(backend, chainID.Chain()),
 	)
 
-	return eng
+	return eng, nil
+}
+
+// EpochSetupPhaseStarted drops the cached identities of the collection and execution nodes, as the nodes joining in
+// the next epoch are added to the identity table.
+func (e *Engine) EpochSetupPhaseStarted(uint64, *flow.Header) {
+	e.connectionFactory.InvalidateIdentities()
+}
+
+// EpochTransition drops the cached identities of the collection and execution nodes, as the identity table of the
+// new epoch replaces the one of the previous epoch.
+func (e *Engine) EpochTransition(uint64, *flow.Header) {
+	e.connectionFactory.InvalidateIdentities()
 }
 
 // Ready returns a ready channel that is closed once the engine has fully
@@ -209,6 +299,10 @@ func (e *Engine) Ready() <-chan struct{} {
 	if e.config.RESTListenAddr != "" {
 		e.unit.Launch(e.serveREST)
 	}
+	// idle connections are evicted from the connections cache in the background, so requests don't scan the cache
+	if e.connectionFactory.ConnectionsCache != nil && e.config.MaxConnectionIdleTime > 0 {
+		e.unit.LaunchPeriodically(e.connectionFactory.EvictIdleConnections, e.config.MaxConnectionIdleTime, e.config.MaxConnectionIdleTime)
+	}
 	return e.unit.Ready()
 }
 
@@ -345,7 +439,7 @@ func (e *Engine) serveREST() {
 
 	e.log.Info().Str("rest_api_address", e.config.RESTListenAddr).Msg("starting REST server on address")
 
-	r, err := rest.NewServer(e.backend, e.config.RESTListenAddr, e.log, e.chain)
+	r, err := rest.NewServer(e.backend, e.config.RESTListenAddr, e.log, e.chain, e.clientLimiter)
 	if err != nil {
 		e.log.Err(err).Msg("failed to initialize the REST server")
 		return
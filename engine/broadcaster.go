@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"sync"
+)
+
+// Broadcaster fans out notifications to any number of subscribers. Each subscriber
+// receives its own Notifier, so a slow subscriber never blocks the publisher or
+// the other subscribers. As with Notifier, multiple notifications published before
+// a subscriber gets around to reading them are coalesced into a single one.
+type Broadcaster struct {
+	mu          sync.RWMutex
+	subscribers map[Notifier]struct{}
+}
+
+// NewBroadcaster instantiates a Broadcaster without any subscribers.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{
+		subscribers: make(map[Notifier]struct{}),
+	}
+}
+
+// Subscribe registers a new subscriber and returns the Notifier on which it is
+// informed about published notifications.
+func (b *Broadcaster) Subscribe() Notifier {
+	n := NewNotifier()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[n] = struct{}{}
+
+	return n
+}
+
+// Unsubscribe removes the given subscriber. It is a no-op if the Notifier is not subscribed.
+func (b *Broadcaster) Unsubscribe(n Notifier) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subscribers, n)
+}
+
+// Publish sends a notification to all current subscribers.
+func (b *Broadcaster) Publish() {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for n := range b.subscribers {
+		n.Notify()
+	}
+}
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestBroadcaster_Publish verifies that every subscriber is notified on publish
+// and that unsubscribed notifiers no longer receive notifications.
+func TestBroadcaster_Publish(t *testing.T) {
+	t.Parallel()
+	b := NewBroadcaster()
+
+	first := b.Subscribe()
+	second := b.Subscribe()
+
+	b.Publish()
+	for _, n := range []Notifier{first, second} {
+		select {
+		case <-n.Channel(): // expected
+		default:
+			t.Fail()
+		}
+	}
+
+	b.Unsubscribe(first)
+	b.Publish()
+
+	select {
+	case <-first.Channel():
+		t.Fail()
+	default: // expected
+	}
+
+	select {
+	case <-second.Channel(): // expected
+	default:
+		t.Fail()
+	}
+}
+
+// TestBroadcaster_CoalescesNotifications verifies that notifications published
+// before a subscriber reads are coalesced into a single one.
+func TestBroadcaster_CoalescesNotifications(t *testing.T) {
+	t.Parallel()
+	b := NewBroadcaster()
+	n := b.Subscribe()
+
+	for i := 0; i < 10; i++ {
+		b.Publish()
+	}
+
+	<-n.Channel()
+	select {
+	case <-n.Channel():
+		require.Fail(t, "expected a single coalesced notification")
+	default: // expected
+	}
+}
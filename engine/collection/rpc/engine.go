@@ -12,6 +12,7 @@ import (
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/status"
 
 	"github.com/onflow/flow-go/engine"
@@ -29,9 +30,10 @@ type Backend interface {
 
 // Config defines the configurable options for the ingress server.
 type Config struct {
-	ListenAddr        string
-	MaxMsgSize        int  // in bytes
-	RpcMetricsEnabled bool // enable GRPC metrics
+	ListenAddr           string
+	MaxMsgSize           int                              // in bytes
+	RpcMetricsEnabled    bool                             // enable GRPC metrics
+	TransportCredentials credentials.TransportCredentials // the TLS credentials of the server, the server is insecure if nil
 }
 
 // Engine implements a gRPC server with a simplified version of the Observation
@@ -61,6 +63,11 @@ func New(config Config, backend Backend, log zerolog.Logger, chainID flow.ChainI
 		grpcOpts = append(grpcOpts, grpc.UnaryInterceptor(grpc_prometheus.UnaryServerInterceptor))
 	}
 
+	// if TLS is enabled, serve the requests over TLS
+	if config.TransportCredentials != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(config.TransportCredentials))
+	}
+
 	server := grpc.NewServer(grpcOpts...)
 
 	e := &Engine{
@@ -53,6 +53,22 @@ type blockComputer struct {
 	log            zerolog.Logger
 	systemChunkCtx fvm.Context
 	committer      ViewCommitter
+	workers        int // number of transactions of a collection executed concurrently
+}
+
+// BlockComputerOption configures a block computer.
+type BlockComputerOption func(*blockComputer)
+
+// WithParallelExecution makes the block computer execute the transactions of a collection optimistically,
+// up to the given number of transactions at a time. A transaction whose execution may have been affected by
+// the preceding transactions of the collection is executed again after them, so that the results are identical
+// to the results of executing the transactions one after the other.
+// With one worker or less, transactions are executed one after the other. With more workers, the read function
+// of the view passed to ExecuteBlock must be safe for concurrent use.
+func WithParallelExecution(workers int) BlockComputerOption {
+	return func(e *blockComputer) {
+		e.workers = workers
+	}
 }
 
 func SystemChunkContext(vmCtx fvm.Context, logger zerolog.Logger) fvm.Context {
@@ -75,8 +91,9 @@ func NewBlockComputer(
 	tracer module.Tracer,
 	logger zerolog.Logger,
 	committer ViewCommitter,
+	options ...BlockComputerOption,
 ) (BlockComputer, error) {
-	return &blockComputer{
+	e := &blockComputer{
 		vm:             vm,
 		vmCtx:          vmCtx,
 		metrics:        metrics,
@@ -84,7 +101,12 @@ func NewBlockComputer(
 		log:            logger,
 		systemChunkCtx: SystemChunkContext(vmCtx, logger),
 		committer:      committer,
-	}, nil
+		workers:        1,
+	}
+	for _, apply := range options {
+		apply(e)
+	}
+	return e, nil
 }
 
 // ExecuteBlock executes a block and returns the resulting chunks.
@@ -130,12 +152,13 @@ func (e *blockComputer) executeBlock(
 	chunksSize := len(collections) + 1 // + 1 system chunk
 
 	res := &execution.ComputationResult{
-		ExecutableBlock:    block,
-		Events:             make([]flow.EventsList, chunksSize),
-		ServiceEvents:      make(flow.EventsList, 0),
-		TransactionResults: make([]flow.TransactionResult, 0),
-		StateCommitments:   make([]flow.StateCommitment, 0),
-		Proofs:             make([][]byte, 0),
+		ExecutableBlock:     block,
+		Events:              make([]flow.EventsList, chunksSize),
+		ServiceEvents:       make(flow.EventsList, 0),
+		TransactionResults:  make([]flow.TransactionResult, 0),
+		TransactionProfiles: make([]flow.TransactionProfile, 0),
+		StateCommitments:    make([]flow.StateCommitment, 0),
+		Proofs:              make([][]byte, 0),
 	}
 
 	var txIndex uint32
@@ -300,18 +323,30 @@ func (e *blockComputer) executeCollection(
 	}()
 
 	txCtx := fvm.NewContextFromParent(blockCtx, fvm.WithMetricsReporter(e.metrics), fvm.WithTracer(e.tracer))
-	for _, txBody := range collection.Transactions {
-		err := e.executeTransaction(txBody, colSpan, collectionView, programs, txCtx, collectionIndex, txIndex, res, false)
-		txIndex++
+
+	reexecuted := 0
+	if e.workers > 1 && len(collection.Transactions) > 1 {
+		var err error
+		txIndex, reexecuted, err = e.executeTransactionsInParallel(collection.Transactions, colSpan, collectionView, programs, txCtx, collectionIndex, txIndex, res)
 		if err != nil {
 			return txIndex, err
 		}
+	} else {
+		for _, txBody := range collection.Transactions {
+			err := e.executeTransaction(txBody, colSpan, collectionView, programs, txCtx, collectionIndex, txIndex, res, false)
+			txIndex++
+			if err != nil {
+				return txIndex, err
+			}
+		}
 	}
+
 	res.AddStateSnapshot(collectionView.(*delta.View).Interactions())
 	e.log.Info().Str("collectionID", collection.Guarantee.CollectionID.String()).
 		Str("referenceBlockID", collection.Guarantee.ReferenceBlockID.String()).
 		Hex("blockID", logging.Entity(blockCtx.BlockHeader)).
 		Int("numberOfTransactions", len(collection.Transactions)).
+		Int("numberOfReexecutedTransactions", reexecuted).
 		Int64("timeSpentInMS", time.Since(startedAt).Milliseconds()).
 		Msg("collection executed")
 
@@ -331,21 +366,178 @@ func (e *blockComputer) executeTransaction(
 	res *execution.ComputationResult,
 	isSystemChunk bool,
 ) error {
-	startedAt := time.Now()
+	txSpan := e.startTransactionSpan(colSpan, txBody, collectionIndex, txIndex)
+	defer txSpan.Finish()
 
-	var memAllocBefore uint64
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-	memAllocBefore = m.TotalAlloc
+	run, err := e.runTransaction(txBody, collectionView.NewChild(), programs, ctx, txIndex, res, isSystemChunk)
+	if err != nil {
+		return err
+	}
 
-	txID := txBody.ID()
+	return e.mergeTransaction(run, txSpan, collectionView, collectionIndex, res)
+}
 
+// executeTransactionsInParallel executes the transactions of a collection optimistically: all transactions are
+// first run concurrently on the state at the start of the collection, then merged into the collection view in
+// order. A transaction is run again on the collection view before being merged if it may have been affected by
+// the preceding transactions, so that the collection view ends up identical to executing the transactions one
+// after the other. This is the case if
+//   - it read a register written by a preceding transaction,
+//   - it stored a program which a preceding transaction already stored, as loading a program is metered,
+//   - it or a preceding transaction cleaned the programs, for example when updating a contract.
+//
+// It returns the index of the next transaction and the number of transactions which were run again.
+func (e *blockComputer) executeTransactionsInParallel(
+	transactions []*flow.TransactionBody,
+	colSpan opentracing.Span,
+	collectionView state.View,
+	programs *programs.Programs,
+	ctx fvm.Context,
+	collectionIndex int,
+	txIndex uint32,
+	res *execution.ComputationResult,
+) (uint32, int, error) {
+
+	// the collection view and the programs are not modified until all transactions have been run
+	runs := make([]*transactionRun, len(transactions))
+	errs := make([]error, len(transactions))
+	indexes := make(chan int, len(transactions))
+	for i := range transactions {
+		indexes <- i
+	}
+	close(indexes)
+
+	var wg sync.WaitGroup
+	for w := 0; w < e.workers && w < len(transactions); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				// all registers read from the collection view are recorded, including the reads of child views
+				// which are discarded by the fvm
+				reads := make(map[string]struct{})
+				txView := delta.NewView(func(owner, controller, key string) (flow.RegisterValue, error) {
+					id := flow.NewRegisterID(owner, controller, key)
+					reads[id.String()] = struct{}{}
+					return collectionView.(*delta.View).Peek(owner, controller, key)
+				})
+
+				runs[i], errs[i] = e.runTransaction(transactions[i], txView, programs.ChildPrograms(), ctx, txIndex+uint32(i), res, false)
+				if errs[i] == nil {
+					// the registers read while loading the programs taken from the programs cache are only
+					// recorded as touches of the view
+					for id := range txView.Interactions().Reads {
+						reads[id] = struct{}{}
+					}
+					runs[i].reads = reads
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	written := make(map[string]struct{})
+	cleaned := false
+	reexecuted := 0
+
+	for i, txBody := range transactions {
+		txSpan := e.startTransactionSpan(colSpan, txBody, collectionIndex, txIndex)
+
+		run := runs[i]
+		if cleaned || errs[i] != nil || run.conflicts(written, programs) {
+			var err error
+			run, err = e.runTransaction(txBody, collectionView.NewChild(), programs.ChildPrograms(), ctx, txIndex, res, false)
+			if err != nil {
+				txSpan.Finish()
+				return txIndex + 1, reexecuted, err
+			}
+			reexecuted++
+		}
+
+		programs.MergeChild(run.programs)
+		cleaned = cleaned || run.programs.Cleaned()
+		for id := range run.view.(*delta.View).Delta().Data {
+			written[id] = struct{}{}
+		}
+
+		err := e.mergeTransaction(run, txSpan, collectionView, collectionIndex, res)
+		txSpan.Finish()
+		txIndex++
+		if err != nil {
+			return txIndex, reexecuted, err
+		}
+	}
+
+	return txIndex, reexecuted, nil
+}
+
+// transactionRun is the outcome of running a transaction on its own view, before merging it into the collection.
+type transactionRun struct {
+	tx             *fvm.TransactionProcedure
+	view           state.View
+	programs       *programs.Programs
+	reads          map[string]struct{} // registers read from the collection view, if recorded
+	traceID        string
+	startedAt      time.Time
+	memAllocBefore uint64
+}
+
+// conflicts returns true if the transaction, which was run on the state at the start of the collection, could
+// have had another outcome if it had been run after the transactions which wrote the given registers and stored
+// the given programs.
+func (r *transactionRun) conflicts(written map[string]struct{}, programs *programs.Programs) bool {
+	if r.programs.Cleaned() {
+		return true
+	}
+
+	for id := range r.reads {
+		if _, ok := written[id]; ok {
+			return true
+		}
+	}
+
+	// the transaction loaded programs which were missing at the start of the collection
+	for _, location := range r.programs.Locations() {
+		if _, _, has := programs.Peek(location); has {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *blockComputer) startTransactionSpan(
+	colSpan opentracing.Span,
+	txBody *flow.TransactionBody,
+	collectionIndex int,
+	txIndex uint32,
+) opentracing.Span {
 	// we capture two spans one for tx-based view and one for the current context (block-based) view
 	txSpan := e.tracer.StartSpanFromParent(colSpan, trace.EXEComputeTransaction)
-	txSpan.LogFields(log.String("tx_id", txID.String()))
+	txSpan.LogFields(log.String("tx_id", txBody.ID().String()))
 	txSpan.LogFields(log.Uint32("tx_index", txIndex))
 	txSpan.LogFields(log.Int("col_index", collectionIndex))
-	defer txSpan.Finish()
+	return txSpan
+}
+
+// runTransaction runs the transaction on the given transaction view, without merging it into the collection view.
+func (e *blockComputer) runTransaction(
+	txBody *flow.TransactionBody,
+	txView state.View,
+	programs *programs.Programs,
+	ctx fvm.Context,
+	txIndex uint32,
+	res *execution.ComputationResult,
+	isSystemChunk bool,
+) (*transactionRun, error) {
+	startedAt := time.Now()
+
+	// when transactions run in parallel, this includes the memory allocated by the other transactions
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	memAllocBefore := m.TotalAlloc
+
+	txID := txBody.ID()
 
 	var traceID string
 	txInternalSpan, _, isSampled := e.tracer.StartTransactionSpan(context.Background(), txID, trace.EXERunTransaction)
@@ -370,16 +562,35 @@ func (e *blockComputer) executeTransaction(
 		tx.SetTraceSpan(txInternalSpan)
 	}
 
-	txView := collectionView.NewChild()
 	err := e.vm.Run(ctx, tx, txView, programs)
 	if err != nil {
-		return fmt.Errorf("failed to execute transaction %v for block %v at height %v: %w",
+		return nil, fmt.Errorf("failed to execute transaction %v for block %v at height %v: %w",
 			txID.String(),
 			res.ExecutableBlock.ID(),
 			res.ExecutableBlock.Block.Header.Height,
 			err)
 	}
 
+	return &transactionRun{
+		tx:             tx,
+		view:           txView,
+		programs:       programs,
+		traceID:        traceID,
+		startedAt:      startedAt,
+		memAllocBefore: memAllocBefore,
+	}, nil
+}
+
+// mergeTransaction merges the view of the transaction run into the collection view, and adds its results.
+func (e *blockComputer) mergeTransaction(
+	run *transactionRun,
+	txSpan opentracing.Span,
+	collectionView state.View,
+	collectionIndex int,
+	res *execution.ComputationResult,
+) error {
+	tx := run.tx
+
 	txResult := flow.TransactionResult{
 		TransactionID:   tx.ID,
 		ComputationUsed: tx.ComputationUsed,
@@ -394,28 +605,40 @@ func (e *blockComputer) executeTransaction(
 
 	// always merge the view, fvm take cares of reverting changes
 	// of failed transaction invocation
-	err = collectionView.MergeView(txView)
+	err := collectionView.MergeView(run.view)
 	if err != nil {
 		return fmt.Errorf("merging tx view to collection view failed for tx %v: %w",
-			txID.String(), err)
+			tx.ID.String(), err)
 	}
 
 	res.AddEvents(collectionIndex, tx.Events)
 	res.AddServiceEvents(tx.ServiceEvents)
 	res.AddTransactionResult(&txResult)
+
+	profile := tx.Profile
+	if profile == nil {
+		// the transaction failed before it was invoked, so nothing was metered
+		profile = &flow.TransactionProfile{
+			TransactionID:   tx.ID,
+			ComputationUsed: tx.ComputationUsed,
+			MemoryUsed:      tx.MemoryUsed,
+		}
+	}
+	res.AddTransactionProfile(profile)
 	res.AddComputationUsed(tx.ComputationUsed)
 
+	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
 	memAllocAfter := m.TotalAlloc
 
 	evt := e.log.With().
 		Hex("tx_id", txResult.TransactionID[:]).
 		Str("block_id", res.ExecutableBlock.ID().String()).
-		Str("traceID", traceID).
+		Str("traceID", run.traceID).
 		Uint64("computation_used", txResult.ComputationUsed).
 		Uint64("memory_used", tx.MemoryUsed).
-		Uint64("memAlloc", memAllocAfter-memAllocBefore).
-		Int64("timeSpentInMS", time.Since(startedAt).Milliseconds())
+		Uint64("memAlloc", memAllocAfter-run.memAllocBefore).
+		Int64("timeSpentInMS", time.Since(run.startedAt).Milliseconds())
 
 	lg := evt.
 		Logger()
@@ -429,7 +652,7 @@ func (e *blockComputer) executeTransaction(
 		lg.Info().Msg("transaction executed successfully")
 	}
 
-	e.metrics.ExecutionTransactionExecuted(time.Since(startedAt), tx.ComputationUsed, len(tx.Events), tx.Err != nil)
+	e.metrics.ExecutionTransactionExecuted(time.Since(run.startedAt), tx.ComputationUsed, len(tx.Events), tx.Err != nil)
 	return nil
 }
 
@@ -2,8 +2,10 @@ package computer_test
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"math/rand"
+	"sync"
 	"testing"
 
 	"github.com/onflow/cadence"
@@ -272,6 +274,12 @@ func TestBlockExecutor_ExecuteBlock(t *testing.T) {
 		}
 		assert.ElementsMatch(t, expectedResults, result.TransactionResults[0:len(result.TransactionResults)-1]) //strip system chunk
 
+		// transactions which failed before they were invoked have empty profiles
+		require.Len(t, result.TransactionProfiles, totalTransactionCount)
+		for i, txResult := range result.TransactionResults {
+			assert.Equal(t, flow.TransactionProfile{TransactionID: txResult.TransactionID}, result.TransactionProfiles[i])
+		}
+
 		assertEventHashesMatch(t, collectionCount+1, result)
 
 		vm.AssertExpectations(t)
@@ -687,6 +695,153 @@ func Test_ExecutingSystemCollection(t *testing.T) {
 	committer.AssertExpectations(t)
 }
 
+func Test_ParallelExecutionMatchesSerialExecution(t *testing.T) {
+
+	chain := flow.Localnet.Chain()
+	logger := zerolog.Nop()
+
+	execCtx := fvm.NewContext(
+		logger,
+		fvm.WithChain(chain),
+		fvm.WithBlocks(&fvm.NoopBlockFinder{}),
+		// signatures and sequence numbers are not checked
+		fvm.WithTransactionProcessors(fvm.NewTransactionInvoker(logger)),
+	)
+
+	vm := fvm.NewVirtualMachine(fvm.NewInterpreterRuntime())
+
+	ledger := testutil.RootBootstrappedLedger(vm, execCtx)
+
+	service := chain.ServiceAddress()
+	counterCode := hex.EncodeToString([]byte(`
+		pub contract Counter {
+			pub var count: Int
+			init() { self.count = 0 }
+			pub fun increment(): Int {
+				self.count = self.count + 1
+				return self.count
+			}
+		}`))
+
+	transaction := func(script string, authorize bool) *flow.TransactionBody {
+		txBody := flow.NewTransactionBody().
+			SetScript([]byte(script)).
+			SetPayer(service)
+		if authorize {
+			txBody.AddAuthorizer(service)
+		}
+		return txBody
+	}
+
+	readSupply := fmt.Sprintf(`
+		import FlowToken from 0x%s
+		transaction { execute { log(FlowToken.totalSupply) } }`, fvm.FlowTokenAddress(chain))
+	incrementCounter := fmt.Sprintf(`
+		import Counter from 0x%s
+		transaction { execute { log(Counter.increment()) } }`, service)
+	incrementStored := `
+		transaction {
+			prepare(signer: AuthAccount) {
+				let value = signer.load<Int>(from: /storage/value) ?? 0
+				signer.save(value + 1, to: /storage/value)
+			}
+		}`
+
+	block := blockWithTransactions(
+		[]*flow.TransactionBody{
+			// both transactions load the same program
+			transaction(readSupply, false),
+			transaction(readSupply+"// again", false),
+			transaction(`transaction { execute { log("independent") } }`, false),
+			// the following transactions use the deployed contract
+			transaction(fmt.Sprintf(`
+				transaction {
+					prepare(signer: AuthAccount) {
+						signer.contracts.add(name: "Counter", code: "%s".decodeHex())
+					}
+				}`, counterCode), true),
+			transaction(incrementCounter, false),
+			transaction(incrementCounter+"// again", false),
+		},
+		[]*flow.TransactionBody{
+			// both transactions update the same register
+			transaction(incrementStored, true),
+			transaction(incrementStored+"// again", true),
+			transaction(incrementCounter+"// once more", false),
+		},
+	)
+
+	execute := func(options ...computer.BlockComputerOption) *execution.ComputationResult {
+		exe, err := computer.NewBlockComputer(vm, execCtx, metrics.NewNoopCollector(), trace.NewNoopTracer(), logger, committer.NewNoopViewCommitter(), options...)
+		require.NoError(t, err)
+
+		// the registers are read concurrently when transactions are executed in parallel
+		var lock sync.Mutex
+		view := delta.NewView(func(owner, controller, key string) (flow.RegisterValue, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			return ledger.Get(owner, controller, key)
+		})
+
+		result, err := exe.ExecuteBlock(context.Background(), block, view, programs.NewEmptyPrograms())
+		require.NoError(t, err)
+		return result
+	}
+
+	expected := execute()
+	require.Len(t, expected.TransactionProfiles, len(expected.TransactionResults))
+	for i, txResult := range expected.TransactionResults {
+		require.Empty(t, txResult.ErrorMessage)
+		require.Equal(t, txResult.TransactionID, expected.TransactionProfiles[i].TransactionID)
+		require.Equal(t, txResult.ComputationUsed, expected.TransactionProfiles[i].ComputationUsed)
+	}
+
+	for i := 0; i < 5; i++ {
+		result := execute(computer.WithParallelExecution(4))
+
+		require.Equal(t, expected.StateSnapshots, result.StateSnapshots)
+		require.Equal(t, expected.Events, result.Events)
+		require.Equal(t, expected.EventsHashes, result.EventsHashes)
+		require.Equal(t, expected.ServiceEvents, result.ServiceEvents)
+		require.Equal(t, expected.TransactionResults, result.TransactionResults)
+		require.Equal(t, expected.TransactionProfiles, result.TransactionProfiles)
+		require.Equal(t, expected.ComputationUsed, result.ComputationUsed)
+		require.Equal(t, expected.StateReads, result.StateReads)
+	}
+}
+
+func blockWithTransactions(transactions ...[]*flow.TransactionBody) *entity.ExecutableBlock {
+	guarantees := make([]*flow.CollectionGuarantee, 0, len(transactions))
+	completeCollections := make(map[flow.Identifier]*entity.CompleteCollection)
+
+	for _, txs := range transactions {
+		collection := flow.Collection{Transactions: txs}
+		guarantee := &flow.CollectionGuarantee{CollectionID: collection.ID()}
+		guarantees = append(guarantees, guarantee)
+		completeCollections[guarantee.ID()] = &entity.CompleteCollection{
+			Guarantee:    guarantee,
+			Transactions: txs,
+		}
+	}
+
+	block := flow.Block{
+		Header: &flow.Header{
+			Timestamp: flow.GenesisTime,
+			Height:    42,
+			View:      42,
+		},
+		Payload: &flow.Payload{
+			Guarantees: guarantees,
+		},
+	}
+
+	return &entity.ExecutableBlock{
+		Block:               &block,
+		CompleteCollections: completeCollections,
+		StartState:          unittest.StateCommitmentPointerFixture(),
+	}
+}
+
 func generateBlock(collectionCount, transactionCount int, addressGenerator flow.AddressGenerator) *entity.ExecutableBlock {
 	return generateBlockWithVisitor(collectionCount, transactionCount, addressGenerator, nil)
 }
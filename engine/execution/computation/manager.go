@@ -3,8 +3,10 @@ package computation
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/rand"
+	"runtime"
 	"strings"
 	"time"
 
@@ -19,13 +21,16 @@ import (
 	"github.com/onflow/flow-go/engine/execution"
 	"github.com/onflow/flow-go/engine/execution/computation/computer"
 	"github.com/onflow/flow-go/fvm"
+	fvmErrors "github.com/onflow/flow-go/fvm/errors"
 	"github.com/onflow/flow-go/fvm/programs"
 	"github.com/onflow/flow-go/fvm/state"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module"
 	"github.com/onflow/flow-go/module/mempool/entity"
+	"github.com/onflow/flow-go/module/metrics"
 	"github.com/onflow/flow-go/module/state_synchronization"
 	"github.com/onflow/flow-go/state/protocol"
+	"github.com/onflow/flow-go/storage"
 	"github.com/onflow/flow-go/utils/logging"
 )
 
@@ -54,6 +59,8 @@ type ComputationManager interface {
 
 var DefaultScriptLogThreshold = 1 * time.Second
 var DefaultScriptExecutionTimeLimit = 10 * time.Second
+var DefaultScriptExecutionWorkers = runtime.NumCPU()
+var DefaultScriptExecutionQueueSize = 100
 
 const MaxScriptErrorMessageSize = 1000 // 1000 chars
 
@@ -69,9 +76,11 @@ type Manager struct {
 	programsCache            *ProgramsCache
 	scriptLogThreshold       time.Duration
 	scriptExecutionTimeLimit time.Duration
+	scriptWorkers            *scriptWorkers
 	uploaders                []uploader.Uploader
 	eds                      state_synchronization.ExecutionDataService
 	edCache                  state_synchronization.ExecutionDataCIDCache
+	programUsages            *programUsagesRecorder
 }
 
 func New(
@@ -84,11 +93,15 @@ func New(
 	vmCtx fvm.Context,
 	programsCacheSize uint,
 	committer computer.ViewCommitter,
+	parallelExecutionWorkers int,
 	scriptLogThreshold time.Duration,
 	scriptExecutionTimeLimit time.Duration,
+	scriptExecutionWorkers int,
+	scriptExecutionQueueSize int,
 	uploaders []uploader.Uploader,
 	eds state_synchronization.ExecutionDataService,
 	edCache state_synchronization.ExecutionDataCIDCache,
+	programUsages storage.ProgramUsages,
 ) (*Manager, error) {
 	log := logger.With().Str("engine", "computation").Logger()
 
@@ -99,6 +112,7 @@ func New(
 		tracer,
 		log.With().Str("component", "block_computer").Logger(),
 		committer,
+		computer.WithParallelExecution(parallelExecutionWorkers),
 	)
 
 	if err != nil {
@@ -121,11 +135,16 @@ func New(
 		programsCache:            programsCache,
 		scriptLogThreshold:       scriptLogThreshold,
 		scriptExecutionTimeLimit: scriptExecutionTimeLimit,
+		scriptWorkers:            newScriptWorkers(metrics, scriptExecutionWorkers, scriptExecutionQueueSize),
 		uploaders:                uploaders,
 		eds:                      eds,
 		edCache:                  edCache,
 	}
 
+	if programUsages != nil {
+		e.programUsages = newProgramUsagesRecorder(programUsages, DefaultProgramUsagesFlushInterval)
+	}
+
 	return &e, nil
 }
 
@@ -157,14 +176,22 @@ func (e *Manager) ExecuteScript(
 		e.log.Info().Uint32("trackerID", trackerID).Msg("script execution is complete")
 	}()
 
+	// the time limit includes the time spent waiting for a script worker
 	requestCtx, cancel := context.WithTimeout(ctx, e.scriptExecutionTimeLimit)
 	defer cancel()
 
+	release, err := e.scriptWorkers.acquire(requestCtx)
+	if err != nil {
+		e.reportAbortedScript(err)
+		return nil, fmt.Errorf("failed to execute script at block (%s): %w", blockHeader.ID(), err)
+	}
+	defer release()
+
 	script := fvm.NewScriptWithContextAndArgs(code, requestCtx, arguments...)
 	blockCtx := fvm.NewContextFromParent(e.vmCtx, fvm.WithBlockHeader(blockHeader))
 	programs := e.getChildProgramsOrEmpty(blockHeader.ID())
 
-	err := func() (err error) {
+	err = func() (err error) {
 
 		start := time.Now()
 
@@ -205,6 +232,8 @@ func (e *Manager) ExecuteScript(
 	}
 
 	if script.Err != nil {
+		e.reportAbortedScript(script.Err)
+
 		scriptErrMsg := script.Err.Error()
 		if len(scriptErrMsg) > MaxScriptErrorMessageSize {
 			split := int(MaxScriptErrorMessageSize/2) - 1
@@ -228,6 +257,24 @@ func (e *Manager) ExecuteScript(
 	return encodedValue, nil
 }
 
+// reportAbortedScript reports the script as aborted if the error is caused by the script being timed out,
+// cancelled or rejected.
+func (e *Manager) reportAbortedScript(err error) {
+	var fvmErr fvmErrors.Error
+	if !errors.As(err, &fvmErr) {
+		return
+	}
+
+	switch fvmErr.Code() {
+	case fvmErrors.ErrCodeScriptExecutionTimedOutError:
+		e.metrics.ExecutionScriptAborted(metrics.ScriptAbortedTimedOut)
+	case fvmErrors.ErrCodeScriptExecutionCancelledError:
+		e.metrics.ExecutionScriptAborted(metrics.ScriptAbortedCancelled)
+	case fvmErrors.ErrCodeScriptExecutionRejectedError:
+		e.metrics.ExecutionScriptAborted(metrics.ScriptAbortedRejected)
+	}
+}
+
 func (e *Manager) ComputeBlock(
 	ctx context.Context,
 	block *entity.ExecutableBlock,
@@ -256,6 +303,17 @@ func (e *Manager) ComputeBlock(
 		return nil, fmt.Errorf("failed to execute block: %w", err)
 	}
 
+	if e.programUsages != nil {
+		err = e.programUsages.record(blockPrograms, view)
+		if err != nil {
+			// the program usages are only used to warm up the programs cache after a restart
+			e.log.Warn().
+				Err(err).
+				Hex("block_id", logging.Entity(block.Block)).
+				Msg("failed to record program usages")
+		}
+	}
+
 	toInsert := blockPrograms
 
 	// if we have item from cache and there were no changes
@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/dgraph-io/badger/v2"
 	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -35,6 +38,8 @@ import (
 	module "github.com/onflow/flow-go/module/mock"
 	state_synchronization "github.com/onflow/flow-go/module/state_synchronization/mock"
 	"github.com/onflow/flow-go/module/trace"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	storagemock "github.com/onflow/flow-go/storage/mock"
 	"github.com/onflow/flow-go/utils/unittest"
 )
 
@@ -141,6 +146,168 @@ func TestComputeBlockWithStorage(t *testing.T) {
 	assert.True(t, returnedComputationResult.ComputationUsed > 0)
 }
 
+func TestComputeBlock_ProgramUsages(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		rt := fvm.NewInterpreterRuntime()
+
+		chain := flow.Mainnet.Chain()
+
+		vm := fvm.NewVirtualMachine(rt)
+		execCtx := fvm.NewContext(zerolog.Nop(), fvm.WithChain(chain))
+
+		privateKeys, err := testutil.GenerateAccountPrivateKeys(2)
+		require.NoError(t, err)
+
+		ledger := testutil.RootBootstrappedLedger(vm, execCtx)
+		accounts, err := testutil.CreateAccounts(vm, ledger, programs.NewEmptyPrograms(), privateKeys, chain)
+		require.NoError(t, err)
+
+		// the contract is deployed, then used by the next transaction
+		tx1 := testutil.DeployCounterContractTransaction(accounts[0], chain)
+		tx1.SetProposalKey(chain.ServiceAddress(), 0, 0).
+			SetGasLimit(1000).
+			SetPayer(chain.ServiceAddress())
+
+		err = testutil.SignPayload(tx1, accounts[0], privateKeys[0])
+		require.NoError(t, err)
+
+		err = testutil.SignEnvelope(tx1, chain.ServiceAddress(), unittest.ServiceAccountPrivateKey)
+		require.NoError(t, err)
+
+		tx2 := testutil.CreateCounterTransaction(accounts[0], accounts[1])
+		tx2.SetProposalKey(chain.ServiceAddress(), 0, 1).
+			SetGasLimit(1000).
+			SetPayer(chain.ServiceAddress())
+
+		err = testutil.SignPayload(tx2, accounts[1], privateKeys[1])
+		require.NoError(t, err)
+
+		err = testutil.SignEnvelope(tx2, chain.ServiceAddress(), unittest.ServiceAccountPrivateKey)
+		require.NoError(t, err)
+
+		transactions := []*flow.TransactionBody{tx1, tx2}
+
+		col := flow.Collection{Transactions: transactions}
+
+		guarantee := flow.CollectionGuarantee{
+			CollectionID: col.ID(),
+			Signature:    nil,
+		}
+
+		block := flow.Block{
+			Header: &flow.Header{
+				View: 42,
+			},
+			Payload: &flow.Payload{
+				Guarantees: []*flow.CollectionGuarantee{&guarantee},
+			},
+		}
+
+		executableBlock := &entity.ExecutableBlock{
+			Block: &block,
+			CompleteCollections: map[flow.Identifier]*entity.CompleteCollection{
+				guarantee.ID(): {
+					Guarantee:    &guarantee,
+					Transactions: transactions,
+				},
+			},
+			StartState: unittest.StateCommitmentPointerFixture(),
+		}
+
+		me := new(module.Local)
+		me.On("NodeID").Return(flow.ZeroID)
+
+		blockComputer, err := computer.NewBlockComputer(vm, execCtx, metrics.NewNoopCollector(), trace.NewNoopTracer(), zerolog.Nop(), committer.NewNoopViewCommitter())
+		require.NoError(t, err)
+
+		programsCache, err := NewProgramsCache(10)
+		require.NoError(t, err)
+
+		eds := new(state_synchronization.ExecutionDataService)
+		eds.On("Add", mock.Anything, mock.Anything).Return(flow.ZeroID, nil, nil)
+
+		eCache := new(state_synchronization.ExecutionDataCIDCache)
+		eCache.On("Insert", mock.AnythingOfType("*flow.Header"), mock.AnythingOfType("state_synchronization.BlobTree"))
+
+		programUsages := bstorage.NewProgramUsages(db)
+
+		// the contract was used with another code before
+		contract := flow.ProgramUsage{Address: accounts[0], Name: "Container", CodeHash: unittest.IdentifierFixture(), Uses: 100}
+		err = programUsages.Add([]flow.ProgramUsage{contract})
+		require.NoError(t, err)
+
+		manager := &Manager{
+			log:           zerolog.Nop(),
+			vm:            vm,
+			vmCtx:         execCtx,
+			blockComputer: blockComputer,
+			me:            me,
+			programsCache: programsCache,
+			eds:           eds,
+			edCache:       eCache,
+			programUsages: newProgramUsagesRecorder(programUsages, 1),
+		}
+
+		view := delta.NewView(ledger.Get)
+		blockView := view.NewChild()
+
+		result, err := manager.ComputeBlock(context.Background(), executableBlock, blockView)
+		require.NoError(t, err)
+		// the transactions of the collection succeeded
+		require.Empty(t, result.TransactionResults[0].ErrorMessage)
+		require.Empty(t, result.TransactionResults[1].ErrorMessage)
+
+		// the usages with the previous code were removed when the contract was deployed
+		usages, err := programUsages.MostUsed(math.MaxUint32)
+		require.NoError(t, err)
+
+		var recorded []flow.ProgramUsage
+		for _, usage := range usages {
+			if usage.Address == contract.Address && usage.Name == contract.Name {
+				recorded = append(recorded, usage)
+			}
+		}
+		require.Len(t, recorded, 1)
+		require.Equal(t, flow.MakeID([]byte(testutil.CounterContract)), recorded[0].CodeHash)
+		require.Greater(t, recorded[0].Uses, uint64(0))
+		require.Less(t, recorded[0].Uses, contract.Uses)
+
+		t.Run("warm up", func(t *testing.T) {
+			// stale program usage of a contract updated since it was recorded
+			err = programUsages.Add([]flow.ProgramUsage{{Address: accounts[0], Name: "Container", CodeHash: unittest.IdentifierFixture(), Uses: 1000}})
+			require.NoError(t, err)
+
+			header := unittest.BlockHeaderFixture()
+
+			// no programs are kept if the warm up is stopped
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			err = manager.WarmUpPrograms(ctx, &header, blockView.NewChild(), uint(len(usages)+1))
+			require.ErrorIs(t, err, context.Canceled)
+			require.Nil(t, programsCache.Get(header.ID()))
+
+			err = manager.WarmUpPrograms(context.Background(), &header, blockView.NewChild(), uint(len(usages)+1))
+			require.NoError(t, err)
+
+			blockPrograms := programsCache.Get(header.ID())
+			require.NotNil(t, blockPrograms)
+
+			location := common.AddressLocation{Address: common.Address(accounts[0]), Name: "Container"}
+			// the registers read to load the program are kept with it
+			program, programState, has := blockPrograms.Get(location)
+			require.True(t, has)
+			require.NotNil(t, program)
+			require.NotNil(t, programState)
+
+			// only the programs of the contracts are kept
+			for _, location := range blockPrograms.Locations() {
+				require.IsType(t, common.AddressLocation{}, location)
+			}
+			require.Len(t, blockPrograms.Locations(), len(usages))
+		})
+	})
+}
+
 func TestComputeBlock_Uploader(t *testing.T) {
 
 	noopCollector := &metrics.NoopCollector{}
@@ -192,6 +359,36 @@ func TestComputeBlock_Uploader(t *testing.T) {
 	assert.Equal(t, computationResult, retrievedResult)
 }
 
+func TestProgramUsagesRecorder(t *testing.T) {
+	address := flow.HexToAddress("01")
+	updated := programs.ContractUpdateKey{Address: address, Name: "Container"}
+
+	programUsages := new(storagemock.ProgramUsages)
+	recorder := newProgramUsagesRecorder(programUsages, 2)
+
+	view := delta.NewView(func(owner, controller, key string) (flow.RegisterValue, error) {
+		return nil, nil
+	})
+
+	// nothing is written until enough blocks were recorded
+	blockPrograms := programs.NewEmptyPrograms()
+	blockPrograms.Cleanup([]programs.ContractUpdateKey{updated})
+	err := recorder.record(blockPrograms, view)
+	require.NoError(t, err)
+	programUsages.AssertNotCalled(t, "RemoveByLocation", mock.Anything, mock.Anything)
+
+	// the usages of the removed contract are dropped
+	blockPrograms = programs.NewEmptyPrograms()
+	blockPrograms.Set(common.AddressLocation{Address: common.Address(address), Name: "Container"}, nil, nil)
+	programUsages.On("RemoveByLocation", address, "Container").Return(nil).Once()
+	err = recorder.record(blockPrograms, view)
+	require.NoError(t, err)
+	programUsages.AssertExpectations(t)
+	programUsages.AssertNotCalled(t, "Add", mock.Anything)
+	require.Empty(t, recorder.uses)
+	require.Empty(t, recorder.updated)
+}
+
 func TestExecuteScript(t *testing.T) {
 
 	logger := zerolog.Nop()
@@ -232,11 +429,15 @@ func TestExecuteScript(t *testing.T) {
 		execCtx,
 		DefaultProgramsCacheSize,
 		committer.NewNoopViewCommitter(),
+		1,
 		scriptLogThreshold,
 		DefaultScriptExecutionTimeLimit,
+		DefaultScriptExecutionWorkers,
+		DefaultScriptExecutionQueueSize,
 		nil,
 		eds,
-		edCache)
+		edCache,
+		nil)
 	require.NoError(t, err)
 
 	header := unittest.BlockHeaderFixture()
@@ -267,11 +468,15 @@ func TestExecuteScripPanicsAreHandled(t *testing.T) {
 		ctx,
 		DefaultProgramsCacheSize,
 		committer.NewNoopViewCommitter(),
+		1,
 		scriptLogThreshold,
 		DefaultScriptExecutionTimeLimit,
+		DefaultScriptExecutionWorkers,
+		DefaultScriptExecutionQueueSize,
 		nil,
 		eds,
-		edCache)
+		edCache,
+		nil)
 	require.NoError(t, err)
 
 	_, err = manager.ExecuteScript(context.Background(), []byte("whatever"), nil, &header, noopView())
@@ -304,11 +509,15 @@ func TestExecuteScript_LongScriptsAreLogged(t *testing.T) {
 		ctx,
 		DefaultProgramsCacheSize,
 		committer.NewNoopViewCommitter(),
+		1,
 		1*time.Millisecond,
 		DefaultScriptExecutionTimeLimit,
+		DefaultScriptExecutionWorkers,
+		DefaultScriptExecutionQueueSize,
 		nil,
 		eds,
-		edCache)
+		edCache,
+		nil)
 	require.NoError(t, err)
 
 	_, err = manager.ExecuteScript(context.Background(), []byte("whatever"), nil, &header, noopView())
@@ -341,11 +550,15 @@ func TestExecuteScript_ShortScriptsAreNotLogged(t *testing.T) {
 		ctx,
 		DefaultProgramsCacheSize,
 		committer.NewNoopViewCommitter(),
+		1,
 		1*time.Second,
 		DefaultScriptExecutionTimeLimit,
+		DefaultScriptExecutionWorkers,
+		DefaultScriptExecutionQueueSize,
 		nil,
 		eds,
-		edCache)
+		edCache,
+		nil)
 	require.NoError(t, err)
 
 	_, err = manager.ExecuteScript(context.Background(), []byte("whatever"), nil, &header, noopView())
@@ -422,8 +635,12 @@ func TestExecuteScriptTimeout(t *testing.T) {
 		fvm.NewContext(zerolog.Nop()),
 		DefaultProgramsCacheSize,
 		committer.NewNoopViewCommitter(),
+		1,
 		DefaultScriptLogThreshold,
 		timeout,
+		DefaultScriptExecutionWorkers,
+		DefaultScriptExecutionQueueSize,
+		nil,
 		nil,
 		nil,
 		nil)
@@ -461,8 +678,12 @@ func TestExecuteScriptCancelled(t *testing.T) {
 		fvm.NewContext(zerolog.Nop()),
 		DefaultProgramsCacheSize,
 		committer.NewNoopViewCommitter(),
+		1,
 		DefaultScriptLogThreshold,
 		timeout,
+		DefaultScriptExecutionWorkers,
+		DefaultScriptExecutionQueueSize,
+		nil,
 		nil,
 		nil,
 		nil)
@@ -495,3 +716,46 @@ func TestExecuteScriptCancelled(t *testing.T) {
 	require.Nil(t, value)
 	require.Contains(t, err.Error(), fvmErrors.ErrCodeScriptExecutionCancelledError.String())
 }
+
+func TestExecuteScriptRejected(t *testing.T) {
+
+	manager, err := New(
+		zerolog.Nop(),
+		metrics.NewNoopCollector(),
+		nil,
+		nil,
+		nil,
+		fvm.NewVirtualMachine(fvm.NewInterpreterRuntime()),
+		fvm.NewContext(zerolog.Nop()),
+		DefaultProgramsCacheSize,
+		committer.NewNoopViewCommitter(),
+		1,
+		DefaultScriptLogThreshold,
+		DefaultScriptExecutionTimeLimit,
+		1,
+		0,
+		nil,
+		nil,
+		nil,
+		nil)
+
+	require.NoError(t, err)
+
+	// the only worker is busy executing another script
+	release, err := manager.scriptWorkers.acquire(context.Background())
+	require.NoError(t, err)
+	defer release()
+
+	script := []byte(`
+	pub fun main(): Int {
+		return 1
+	}
+	`)
+
+	header := unittest.BlockHeaderFixture()
+	value, err := manager.ExecuteScript(context.Background(), script, nil, &header, noopView())
+
+	require.Error(t, err)
+	require.Nil(t, value)
+	require.Contains(t, err.Error(), fvmErrors.ErrCodeScriptExecutionRejectedError.String())
+}
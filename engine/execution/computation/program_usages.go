@@ -0,0 +1,231 @@
+package computation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/programs"
+	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/logging"
+)
+
+const DefaultProgramsWarmUpLimit = 100
+
+// DefaultProgramUsagesFlushInterval is the number of executed blocks the program usages are accumulated for
+// before being persisted.
+const DefaultProgramUsagesFlushInterval = 100
+
+// programUsagesRecorder accumulates how many times the transactions of executed blocks used the programs of
+// contracts, and persists them every flushInterval blocks, so that executing a block doesn't write to the
+// database. The usages accumulated since the last flush are lost on shutdown, which only makes the warm up
+// slightly less accurate.
+//
+// Checked programs (see programs.ProgramEntry) hold the elaboration of the Cadence checker, which can't be
+// encoded, so only the usages are persisted, keyed by contract location and code hash. The programs are checked
+// again at startup by WarmUpPrograms.
+type programUsagesRecorder struct {
+	mu            sync.Mutex
+	storage       storage.ProgramUsages
+	flushInterval uint
+	blocks        uint
+	uses          map[common.AddressLocation]uint64
+	updated       map[programs.ContractUpdateKey]struct{}
+}
+
+func newProgramUsagesRecorder(storage storage.ProgramUsages, flushInterval uint) *programUsagesRecorder {
+	if flushInterval == 0 {
+		flushInterval = 1
+	}
+
+	return &programUsagesRecorder{
+		storage:       storage,
+		flushInterval: flushInterval,
+		uses:          make(map[common.AddressLocation]uint64),
+		updated:       make(map[programs.ContractUpdateKey]struct{}),
+	}
+}
+
+// record accumulates the program usages of an executed block, and persists the accumulated usages once enough
+// blocks were recorded. The view must be the view of the block after its execution.
+func (r *programUsagesRecorder) record(blockPrograms *programs.Programs, view state.View) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, updated := range blockPrograms.UpdatedContracts() {
+		r.updated[updated] = struct{}{}
+		// the uses accumulated so far are for the previous code of the contract
+		delete(r.uses, common.AddressLocation{Address: common.Address(updated.Address), Name: updated.Name})
+	}
+
+	for location, count := range blockPrograms.Uses() {
+		r.uses[location] += count
+	}
+
+	r.blocks++
+	if r.blocks < r.flushInterval {
+		return nil
+	}
+
+	return r.flush(view)
+}
+
+// flush persists the accumulated program usages, keyed by the code of the contracts in the given view, and
+// removes the program usages of the contracts updated since the last flush.
+func (r *programUsagesRecorder) flush(view state.View) error {
+	for updated := range r.updated {
+		err := r.storage.RemoveByLocation(updated.Address, updated.Name)
+		if err != nil {
+			return fmt.Errorf("could not remove program usages of %s.%s: %w", updated.Address, updated.Name, err)
+		}
+		delete(r.updated, updated)
+	}
+
+	accounts := readOnlyAccounts(view)
+	usages := make([]flow.ProgramUsage, 0, len(r.uses))
+	for location, count := range r.uses {
+		address := flow.Address(location.Address)
+		code, err := accounts.GetContract(location.Name, address)
+		if err != nil {
+			return fmt.Errorf("could not get code of %s.%s: %w", address, location.Name, err)
+		}
+		// the contract was removed
+		if len(code) == 0 {
+			continue
+		}
+
+		usages = append(usages, flow.ProgramUsage{
+			Address:  address,
+			Name:     location.Name,
+			CodeHash: flow.MakeID(code),
+			Uses:     count,
+		})
+	}
+
+	// the usages are dropped if they can't be stored, rather than being stored with a later code
+	r.uses = make(map[common.AddressLocation]uint64)
+	r.blocks = 0
+
+	if len(usages) == 0 {
+		return nil
+	}
+	return r.storage.Add(usages)
+}
+
+// WarmUpPrograms loads the programs of the most used contracts at the given block, up to limit programs, so that
+// the blocks executed on top of it and the scripts executed at it don't need to parse and check them again after
+// a restart. Checked programs can't be persisted, so they are loaded by executing a script importing each contract.
+// Program usages recorded for another code than the code of the contract at the block are skipped.
+// Warming up stops with the context's error if the context is cancelled, and no programs are kept then.
+func (e *Manager) WarmUpPrograms(ctx context.Context, blockHeader *flow.Header, view state.View, limit uint) error {
+	if e.programUsages == nil || limit == 0 {
+		return nil
+	}
+
+	usages, err := e.programUsages.storage.MostUsed(limit)
+	if err != nil {
+		return fmt.Errorf("could not get most used programs: %w", err)
+	}
+
+	blockPrograms := programs.NewEmptyPrograms()
+	blockCtx := fvm.NewContextFromParent(e.vmCtx, fvm.WithBlockHeader(blockHeader))
+	accounts := readOnlyAccounts(view)
+
+	loaded := 0
+	for _, usage := range usages {
+		if ctx.Err() != nil {
+			return fmt.Errorf("programs warm up stopped: %w", ctx.Err())
+		}
+
+		code, err := accounts.GetContract(usage.Name, usage.Address)
+		if err != nil {
+			return fmt.Errorf("could not get code of %s.%s: %w", usage.Address, usage.Name, err)
+		}
+		if len(code) == 0 || flow.MakeID(code) != usage.CodeHash {
+			continue
+		}
+
+		script := fvm.Script([]byte(fmt.Sprintf("import %s from 0x%s\n\npub fun main() {}\n", usage.Name, usage.Address.Hex())))
+		err = e.vm.Run(blockCtx, script, view, blockPrograms)
+		if err != nil {
+			return fmt.Errorf("could not load program of %s.%s: %w", usage.Address, usage.Name, err)
+		}
+		if script.Err != nil {
+			e.log.Warn().
+				Err(script.Err).
+				Str("address", usage.Address.String()).
+				Str("name", usage.Name).
+				Msg("could not load program")
+			continue
+		}
+
+		loaded++
+	}
+
+	// remove the programs of the scripts, only the programs of the contracts are kept
+	blockPrograms.Cleanup(nil)
+	e.programsCache.Set(blockHeader.ID(), blockPrograms)
+
+	e.log.Info().
+		Hex("block_id", logging.ID(blockHeader.ID())).
+		Int("loaded", loaded).
+		Int("program_usages", len(usages)).
+		Msg("programs warmed up")
+
+	return nil
+}
+
+// ProgramsWarmUp warms up the programs of the most used contracts at a block in the background (see
+// Manager.WarmUpPrograms), so that loading the programs doesn't delay starting the node. The blocks executed
+// before the warm up is done load their programs when executing their transactions, as without warm up.
+type ProgramsWarmUp struct {
+	unit    *engine.Unit
+	log     zerolog.Logger
+	manager *Manager
+	header  *flow.Header
+	view    state.View
+	limit   uint
+}
+
+func NewProgramsWarmUp(log zerolog.Logger, manager *Manager, header *flow.Header, view state.View, limit uint) *ProgramsWarmUp {
+	return &ProgramsWarmUp{
+		unit:    engine.NewUnit(),
+		log:     log.With().Str("component", "programs_warm_up").Logger(),
+		manager: manager,
+		header:  header,
+		view:    view,
+		limit:   limit,
+	}
+}
+
+// Ready returns a ready channel that is closed once the warm up has started.
+func (w *ProgramsWarmUp) Ready() <-chan struct{} {
+	w.unit.Launch(func() {
+		err := w.manager.WarmUpPrograms(w.unit.Ctx(), w.header, w.view, w.limit)
+		if err != nil && w.unit.Ctx().Err() == nil {
+			// the programs are loaded when executing blocks otherwise
+			w.log.Warn().Err(err).Msg("failed to warm up programs")
+		}
+	})
+	return w.unit.Ready()
+}
+
+// Done returns a done channel that is closed once the warm up is done or has been stopped.
+func (w *ProgramsWarmUp) Done() <-chan struct{} {
+	return w.unit.Done()
+}
+
+// readOnlyAccounts returns accounts reading from a child of the view, so that reading contracts doesn't record
+// register touches on the view.
+func readOnlyAccounts(view state.View) *state.StatefulAccounts {
+	st := state.NewState(view.NewChild(), state.WithMaxInteractionSizeAllowed(math.MaxUint64))
+	return state.NewAccounts(state.NewStateHolder(st))
+}
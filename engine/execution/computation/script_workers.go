@@ -0,0 +1,89 @@
+package computation
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	fvmErrors "github.com/onflow/flow-go/fvm/errors"
+	"github.com/onflow/flow-go/module"
+)
+
+// scriptWorkers bounds the number of scripts executed concurrently. Scripts wait in a bounded queue
+// until a worker is available, and are rejected if the queue is full.
+type scriptWorkers struct {
+	metrics   module.ExecutionMetrics
+	workers   chan struct{} // nil if the number of scripts executed concurrently isn't bounded
+	queueSize int
+	mu        sync.Mutex
+	queued    int
+}
+
+// newScriptWorkers returns script workers executing up to the given number of scripts at a time,
+// with up to queueSize scripts waiting. With zero workers or less, the number of scripts executed
+// concurrently isn't bounded.
+func newScriptWorkers(metrics module.ExecutionMetrics, workers int, queueSize int) *scriptWorkers {
+	w := &scriptWorkers{
+		metrics:   metrics,
+		queueSize: queueSize,
+	}
+	if workers > 0 {
+		w.workers = make(chan struct{}, workers)
+	}
+	return w
+}
+
+// acquire waits until a worker is available, and returns the function to call to release the worker once the
+// script is executed. It returns a ScriptExecutionRejectedError if the queue is full, and a
+// ScriptExecutionTimedOutError or ScriptExecutionCancelledError if the context is done before a worker is available.
+func (w *scriptWorkers) acquire(ctx context.Context) (func(), error) {
+	if w.workers == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case w.workers <- struct{}{}:
+		return w.release, nil
+	default:
+	}
+
+	err := w.enqueue()
+	if err != nil {
+		return nil, err
+	}
+	defer w.dequeue()
+
+	select {
+	case w.workers <- struct{}{}:
+		return w.release, nil
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fvmErrors.NewScriptExecutionTimedOutError()
+		}
+		return nil, fvmErrors.NewScriptExecutionCancelledError(ctx.Err())
+	}
+}
+
+func (w *scriptWorkers) release() {
+	<-w.workers
+}
+
+func (w *scriptWorkers) enqueue() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.queued >= w.queueSize {
+		return fvmErrors.NewScriptExecutionRejectedError(w.queued)
+	}
+	w.queued++
+	w.metrics.ExecutionScriptsQueued(w.queued)
+	return nil
+}
+
+func (w *scriptWorkers) dequeue() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.queued--
+	w.metrics.ExecutionScriptsQueued(w.queued)
+}
@@ -0,0 +1,98 @@
+package computation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	fvmErrors "github.com/onflow/flow-go/fvm/errors"
+	"github.com/onflow/flow-go/module/metrics"
+)
+
+func TestScriptWorkers(t *testing.T) {
+
+	t.Run("unbounded", func(t *testing.T) {
+		workers := newScriptWorkers(metrics.NewNoopCollector(), 0, 0)
+
+		for i := 0; i < 10; i++ {
+			_, err := workers.acquire(context.Background())
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("rejected when the queue is full", func(t *testing.T) {
+		workers := newScriptWorkers(metrics.NewNoopCollector(), 1, 0)
+
+		release, err := workers.acquire(context.Background())
+		require.NoError(t, err)
+
+		_, err = workers.acquire(context.Background())
+		var rejected *fvmErrors.ScriptExecutionRejectedError
+		require.True(t, errors.As(err, &rejected))
+
+		// the worker is available again once released
+		release()
+		release, err = workers.acquire(context.Background())
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("waiting script acquires released worker", func(t *testing.T) {
+		workers := newScriptWorkers(metrics.NewNoopCollector(), 1, 1)
+
+		release, err := workers.acquire(context.Background())
+		require.NoError(t, err)
+
+		acquired := make(chan error)
+		go func() {
+			release, err := workers.acquire(context.Background())
+			if err == nil {
+				release()
+			}
+			acquired <- err
+		}()
+
+		require.Eventually(t, func() bool {
+			workers.mu.Lock()
+			defer workers.mu.Unlock()
+			return workers.queued == 1
+		}, time.Second, 10*time.Millisecond)
+
+		release()
+		require.NoError(t, <-acquired)
+		require.Equal(t, 0, workers.queued)
+	})
+
+	t.Run("waiting script timed out", func(t *testing.T) {
+		workers := newScriptWorkers(metrics.NewNoopCollector(), 1, 1)
+
+		release, err := workers.acquire(context.Background())
+		require.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err = workers.acquire(ctx)
+		var timedOut *fvmErrors.ScriptExecutionTimedOutError
+		require.True(t, errors.As(err, &timedOut))
+	})
+
+	t.Run("waiting script cancelled", func(t *testing.T) {
+		workers := newScriptWorkers(metrics.NewNoopCollector(), 1, 1)
+
+		release, err := workers.acquire(context.Background())
+		require.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = workers.acquire(ctx)
+		var cancelled *fvmErrors.ScriptExecutionCancelledError
+		require.True(t, errors.As(err, &cancelled))
+	})
+}
@@ -0,0 +1,223 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/consensus/hotstuff/model"
+	"github.com/onflow/flow-go/engine"
+	"github.com/onflow/flow-go/engine/execution/state/delta"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/state/protocol"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+	"github.com/onflow/flow-go/utils/logging"
+)
+
+// ErrNotIndexed is returned when the registers are not indexed at a block.
+var ErrNotIndexed = errors.New("registers are not indexed at block")
+
+// errNotExecuted is returned internally when a finalized block has not been executed yet.
+var errNotExecuted = errors.New("block has not been executed")
+
+// Indexer keeps the history of the register values of the execution state, so that the state at any finalized
+// height since the root can be read after its trie has been evicted from the forest.
+//
+// The registers updated by a block are recorded when the block is executed. As several blocks may be executed at
+// the same height before one of them is finalized, they are only indexed at the height of the block once it is
+// finalized. Heights are indexed in order, starting from the height at which the registers were bootstrapped.
+type Indexer struct {
+	unit      *engine.Unit
+	log       zerolog.Logger
+	db        *badger.DB
+	state     protocol.State
+	headers   storage.Headers
+	registers storage.Registers
+	finalized engine.Notifier // notified whenever new blocks may have been finalized or executed
+}
+
+func NewIndexer(
+	log zerolog.Logger,
+	db *badger.DB,
+	state protocol.State,
+	headers storage.Headers,
+	registers storage.Registers,
+) *Indexer {
+	return &Indexer{
+		unit:      engine.NewUnit(),
+		log:       log.With().Str("engine", "register_history").Logger(),
+		db:        db,
+		state:     state,
+		headers:   headers,
+		registers: registers,
+		finalized: engine.NewNotifier(),
+	}
+}
+
+// Ready returns a ready channel that is closed once the indexer has started. The registers must have been
+// bootstrapped beforehand.
+func (i *Indexer) Ready() <-chan struct{} {
+	i.unit.Launch(i.loop)
+	// index the heights finalized while the node was down
+	i.finalized.Notify()
+	return i.unit.Ready()
+}
+
+// Done returns a done channel that is closed once the indexer has stopped.
+func (i *Indexer) Done() <-chan struct{} {
+	return i.unit.Done()
+}
+
+// OnFinalizedBlock is called whenever a block is finalized, the indexer then catches up with the finalized height.
+func (i *Indexer) OnFinalizedBlock(*model.Block) {
+	i.finalized.Notify()
+}
+
+// RecordExecuted records the registers updated by the given trie updates of the executed block, until the block
+// is finalized and its height indexed. It must be called before the execution results of the block are persisted,
+// so that the registers of all executed blocks are recorded.
+func (i *Indexer) RecordExecuted(header *flow.Header, updates []*ledger.TrieUpdate) error {
+	latest, err := i.registers.LatestHeight()
+	if err != nil {
+		return fmt.Errorf("could not get latest indexed height: %w", err)
+	}
+	// blocks executed again after a restart may already be indexed
+	if header.Height <= latest {
+		return nil
+	}
+
+	entries, err := RegisterEntries(updates)
+	if err != nil {
+		return err
+	}
+
+	batch := i.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	err = operation.BatchInsertBlockRegisters(header.ID(), entries)(batch)
+	if err != nil {
+		return fmt.Errorf("could not record registers of block %v: %w", header.ID(), err)
+	}
+	err = batch.Flush()
+	if err != nil {
+		return fmt.Errorf("could not flush registers of block %v: %w", header.ID(), err)
+	}
+
+	// the block may already be finalized
+	i.finalized.Notify()
+	return nil
+}
+
+// ViewAtBlock returns a view of the execution state at the end of the given block, reading the registers from the
+// register history. It returns ErrNotIndexed if the block is not finalized, or its height is not indexed yet.
+func (i *Indexer) ViewAtBlock(header *flow.Header) (*delta.View, error) {
+	first, err := i.registers.FirstHeight()
+	if err != nil {
+		return nil, fmt.Errorf("could not get first indexed height: %w", err)
+	}
+	latest, err := i.registers.LatestHeight()
+	if err != nil {
+		return nil, fmt.Errorf("could not get latest indexed height: %w", err)
+	}
+	if header.Height < first || header.Height > latest {
+		return nil, fmt.Errorf("%w %v", ErrNotIndexed, header.ID())
+	}
+
+	finalized, err := i.headers.ByHeight(header.Height)
+	if err != nil {
+		return nil, fmt.Errorf("could not get finalized header at height %d: %w", header.Height, err)
+	}
+	if finalized.ID() != header.ID() {
+		return nil, fmt.Errorf("%w %v", ErrNotIndexed, header.ID())
+	}
+
+	return NewView(i.registers, header.Height), nil
+}
+
+func (i *Indexer) loop() {
+	for {
+		select {
+		case <-i.unit.Quit():
+			return
+		case <-i.finalized.Channel():
+			err := i.indexFinalizedHeights()
+			if err != nil {
+				// heights which could not be indexed are attempted again once the next block is finalized
+				i.log.Error().Err(err).Msg("could not index registers")
+			}
+		}
+	}
+}
+
+// indexFinalizedHeights indexes the registers at all finalized and executed heights above the latest indexed
+// height.
+func (i *Indexer) indexFinalizedHeights() error {
+	final, err := i.state.Final().Head()
+	if err != nil {
+		return fmt.Errorf("could not get finalized header: %w", err)
+	}
+
+	latest, err := i.registers.LatestHeight()
+	if err != nil {
+		return fmt.Errorf("could not get latest indexed height: %w", err)
+	}
+
+	for height := latest + 1; height <= final.Height; height++ {
+		err := i.indexHeight(height)
+		if errors.Is(err, errNotExecuted) {
+			// the following heights are indexed once the block has been executed
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not index height %d: %w", height, err)
+		}
+	}
+
+	return nil
+}
+
+// indexHeight indexes the registers recorded for the finalized block at the given height. The registers recorded
+// for the other blocks at this height, which can't be finalized anymore, are removed.
+func (i *Indexer) indexHeight(height uint64) error {
+	header, err := i.headers.ByHeight(height)
+	if err != nil {
+		return fmt.Errorf("could not get header: %w", err)
+	}
+
+	var entries flow.RegisterEntries
+	err = i.db.View(operation.RetrieveBlockRegisters(header.ID(), &entries))
+	if errors.Is(err, storage.ErrNotFound) {
+		return errNotExecuted
+	}
+	if err != nil {
+		return fmt.Errorf("could not retrieve registers of block %v: %w", header.ID(), err)
+	}
+
+	err = i.registers.Store(height, entries)
+	if err != nil {
+		return fmt.Errorf("could not store registers: %w", err)
+	}
+
+	siblings, err := i.headers.ByParentID(header.ParentID)
+	if err != nil {
+		return fmt.Errorf("could not get blocks at height %d: %w", height, err)
+	}
+	for _, sibling := range siblings {
+		err = i.db.Update(operation.RemoveBlockRegisters(sibling.ID()))
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("could not remove registers of block %v: %w", sibling.ID(), err)
+		}
+	}
+
+	i.log.Debug().
+		Uint64("height", height).
+		Hex("block_id", logging.ID(header.ID())).
+		Int("registers", len(entries)).
+		Msg("indexed registers")
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/model/flow"
+	protocol "github.com/onflow/flow-go/state/protocol/mock"
+	"github.com/onflow/flow-go/storage"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/storage/badger/operation"
+	storagemock "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestIndexFinalizedHeights(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		registers, err := bstorage.NewRegisters(db)
+		require.NoError(t, err)
+
+		id := flow.NewRegisterID("owner", "", "key")
+		err = registers.Bootstrap(10, flow.RegisterEntries{{Key: id, Value: []byte("root")}})
+		require.NoError(t, err)
+
+		root := unittest.BlockHeaderFixture()
+		root.Height = 10
+
+		// blocks 11 and 12 are finalized, block 11 has a fork which is executed but never finalized
+		block11 := unittest.BlockHeaderWithParentFixture(&root)
+		fork11 := unittest.BlockHeaderWithParentFixture(&root)
+		block12 := unittest.BlockHeaderWithParentFixture(&block11)
+
+		headers := new(storagemock.Headers)
+		headers.On("ByHeight", uint64(11)).Return(&block11, nil)
+		headers.On("ByHeight", uint64(12)).Return(&block12, nil)
+		headers.On("ByParentID", root.ID()).Return([]*flow.Header{&block11, &fork11}, nil)
+		headers.On("ByParentID", block11.ID()).Return([]*flow.Header{&block12}, nil)
+
+		final := new(protocol.Snapshot)
+		final.On("Head").Return(&block12, nil)
+		protoState := new(protocol.State)
+		protoState.On("Final").Return(final)
+
+		indexer := NewIndexer(zerolog.Nop(), db, protoState, headers, registers)
+
+		update := func(value string) []*ledger.TrieUpdate {
+			return []*ledger.TrieUpdate{{Payloads: []*ledger.Payload{ledger.NewPayload(state.RegisterIDToKey(id), []byte(value))}}}
+		}
+		require.NoError(t, indexer.RecordExecuted(&block11, update("block11")))
+		require.NoError(t, indexer.RecordExecuted(&fork11, update("fork11")))
+
+		// block 12 has not been executed yet, so only block 11 is indexed
+		err = indexer.indexFinalizedHeights()
+		require.NoError(t, err)
+		latest, err := registers.LatestHeight()
+		require.NoError(t, err)
+		require.Equal(t, uint64(11), latest)
+
+		// the registers of the fork are removed once the height is indexed
+		var entries flow.RegisterEntries
+		err = db.View(operation.RetrieveBlockRegisters(fork11.ID(), &entries))
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		// block 12 doesn't update the register
+		require.NoError(t, indexer.RecordExecuted(&block12, nil))
+		err = indexer.indexFinalizedHeights()
+		require.NoError(t, err)
+		latest, err = registers.LatestHeight()
+		require.NoError(t, err)
+		require.Equal(t, uint64(12), latest)
+
+		// blocks executed again after they have been indexed are ignored
+		require.NoError(t, indexer.RecordExecuted(&block11, update("again")))
+		err = db.View(operation.RetrieveBlockRegisters(block11.ID(), &entries))
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		for header, expected := range map[*flow.Header]string{&block11: "block11", &block12: "block11"} {
+			view, err := indexer.ViewAtBlock(header)
+			require.NoError(t, err)
+			value, err := view.Get(id.Owner, id.Controller, id.Key)
+			require.NoError(t, err)
+			require.Equal(t, []byte(expected), value)
+		}
+
+		// the state of blocks which are not finalized or not indexed yet is not available
+		_, err = indexer.ViewAtBlock(&fork11)
+		require.ErrorIs(t, err, ErrNotIndexed)
+		block13 := unittest.BlockHeaderWithParentFixture(&block12)
+		_, err = indexer.ViewAtBlock(&block13)
+		require.ErrorIs(t, err, ErrNotIndexed)
+	})
+}
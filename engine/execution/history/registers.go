@@ -0,0 +1,99 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/engine/execution/state/delta"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// NewView returns a view of the execution state at the given height, which reads the registers from the register
+// history. Registers written to the view are kept in its delta, the register history is never updated.
+// The height must be indexed.
+func NewView(registers storage.Registers, height uint64) *delta.View {
+	return delta.NewView(func(owner, controller, key string) (flow.RegisterValue, error) {
+		return registers.Get(flow.NewRegisterID(owner, controller, key), height)
+	})
+}
+
+// RegisterEntries returns the register entries updated by the given trie updates. As trie updates are applied in
+// order, the last update of a register determines its value.
+func RegisterEntries(updates []*ledger.TrieUpdate) (flow.RegisterEntries, error) {
+	values := make(map[flow.RegisterID]flow.RegisterValue)
+	for _, update := range updates {
+		for _, payload := range update.Payloads {
+			id, err := state.KeyToRegisterID(payload.Key)
+			if err != nil {
+				return nil, fmt.Errorf("could not convert payload key: %w", err)
+			}
+			values[id] = payload.Value
+		}
+	}
+
+	entries := make(flow.RegisterEntries, 0, len(values))
+	for id, value := range values {
+		entries = append(entries, flow.RegisterEntry{Key: id, Value: value})
+	}
+	return entries, nil
+}
+
+// Bootstrap indexes the registers of the root execution state, loaded from the root checkpoint, at the root height.
+// It is a no-op if the registers have already been bootstrapped.
+func Bootstrap(
+	log zerolog.Logger,
+	registers storage.Registers,
+	checkpointFile string,
+	rootHeight uint64,
+	rootCommit flow.StateCommitment,
+) error {
+	_, err := registers.FirstHeight()
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("could not get first indexed height: %w", err)
+	}
+
+	log.Info().Str("checkpoint", checkpointFile).Msg("bootstrapping registers from root checkpoint")
+
+	tries, err := wal.LoadCheckpoint(checkpointFile, &log)
+	if err != nil {
+		return fmt.Errorf("could not load root checkpoint: %w", err)
+	}
+
+	for _, trie := range tries {
+		if flow.StateCommitment(trie.RootHash()) != rootCommit {
+			continue
+		}
+
+		payloads, err := trie.AllPayloads()
+		if err != nil {
+			return fmt.Errorf("could not get root checkpoint payloads: %w", err)
+		}
+		entries := make(flow.RegisterEntries, 0, len(payloads))
+		for _, payload := range payloads {
+			id, err := state.KeyToRegisterID(payload.Key)
+			if err != nil {
+				return fmt.Errorf("could not convert payload key: %w", err)
+			}
+			entries = append(entries, flow.RegisterEntry{Key: id, Value: payload.Value})
+		}
+
+		err = registers.Bootstrap(rootHeight, entries)
+		if err != nil {
+			return fmt.Errorf("could not bootstrap registers: %w", err)
+		}
+
+		log.Info().Uint64("root_height", rootHeight).Int("registers", len(entries)).Msg("bootstrapped registers")
+		return nil
+	}
+
+	return fmt.Errorf("root checkpoint does not contain the root state commitment %x", rootCommit)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/onflow/flow-go/engine"
 	"github.com/onflow/flow-go/engine/execution"
 	"github.com/onflow/flow-go/engine/execution/computation"
+	"github.com/onflow/flow-go/engine/execution/history"
 	"github.com/onflow/flow-go/engine/execution/provider"
 	"github.com/onflow/flow-go/engine/execution/state"
 	"github.com/onflow/flow-go/engine/execution/state/delta"
@@ -65,6 +66,7 @@ type Engine struct {
 	syncFast               bool                // sync fast allows execution node to skip fetching collection during state syncing, and rely on state syncing to catch up
 	checkAuthorizedAtBlock func(blockID flow.Identifier) (bool, error)
 	pauseExecution         bool
+	registerHistory        *history.Indexer // optional, keeps the execution state of blocks evicted from the forest
 }
 
 func New(
@@ -134,6 +136,14 @@ func New(
 	return &eng, nil
 }
 
+// WithRegisterHistory records the registers updated by executed blocks in the given register history, and reads
+// the execution state of finalized blocks from it when executing scripts and reading registers or accounts.
+// It must be called before the engine is started.
+func (e *Engine) WithRegisterHistory(registerHistory *history.Indexer) *Engine {
+	e.registerHistory = registerHistory
+	return e
+}
+
 // Ready returns a channel that will close when the engine has
 // successfully started.
 func (e *Engine) Ready() <-chan struct{} {
@@ -1023,7 +1033,10 @@ func (e *Engine) ExecuteScriptAtBlockID(ctx context.Context, script []byte, argu
 		return nil, fmt.Errorf("failed to get block (%s): %w", blockID, err)
 	}
 
-	blockView := e.execState.NewView(stateCommit)
+	blockView, err := e.newBlockView(block, stateCommit)
+	if err != nil {
+		return nil, err
+	}
 
 	if e.extensiveLogging {
 		args := make([]string, 0)
@@ -1048,7 +1061,15 @@ func (e *Engine) GetRegisterAtBlockID(ctx context.Context, owner, controller, ke
 		return nil, fmt.Errorf("failed to get state commitment for block (%s): %w", blockID, err)
 	}
 
-	blockView := e.execState.NewView(stateCommit)
+	block, err := e.state.AtBlockID(blockID).Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block (%s): %w", blockID, err)
+	}
+
+	blockView, err := e.newBlockView(block, stateCommit)
+	if err != nil {
+		return nil, err
+	}
 
 	data, err := blockView.Get(string(owner), string(controller), string(key))
 	if err != nil {
@@ -1059,6 +1080,18 @@ func (e *Engine) GetRegisterAtBlockID(ctx context.Context, owner, controller, ke
 }
 
 func (e *Engine) GetAccount(ctx context.Context, addr flow.Address, blockID flow.Identifier) (*flow.Account, error) {
+	getAccount, err := e.GetAccountReader(ctx, blockID)
+	if err != nil {
+		return nil, err
+	}
+
+	return getAccount(addr)
+}
+
+// GetAccountReader returns an AccountReader reading the accounts at the given block. The state commitment, header
+// and view of the block are resolved once, so that reading several accounts doesn't resolve them for each account.
+// The AccountReader is not concurrency safe.
+func (e *Engine) GetAccountReader(ctx context.Context, blockID flow.Identifier) (AccountReader, error) {
 	stateCommit, err := e.execState.StateCommitmentByBlockID(ctx, blockID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get state commitment for block (%s): %w", blockID, err)
@@ -1069,9 +1102,32 @@ func (e *Engine) GetAccount(ctx context.Context, addr flow.Address, blockID flow
 		return nil, fmt.Errorf("failed to get block (%s): %w", blockID, err)
 	}
 
-	blockView := e.execState.NewView(stateCommit)
+	blockView, err := e.newBlockView(block, stateCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(addr flow.Address) (*flow.Account, error) {
+		return e.computationManager.GetAccount(addr, block, blockView)
+	}, nil
+}
+
+// newBlockView returns a view of the execution state at the end of the given block. The state is read from the
+// ledger while its trie is held in the forest. Otherwise, the state of finalized blocks indexed in the register
+// history is read from the history, as their tries have been evicted from the forest.
+func (e *Engine) newBlockView(block *flow.Header, stateCommit flow.StateCommitment) (*delta.View, error) {
+	if e.registerHistory == nil || e.execState.HasState(stateCommit) {
+		return e.execState.NewView(stateCommit), nil
+	}
 
-	return e.computationManager.GetAccount(addr, block, blockView)
+	blockView, err := e.registerHistory.ViewAtBlock(block)
+	if errors.Is(err, history.ErrNotIndexed) {
+		return e.execState.NewView(stateCommit), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get register history view for block (%s): %w", block.ID(), err)
+	}
+	return blockView, nil
 }
 
 func (e *Engine) handleComputationResult(
@@ -1141,6 +1197,13 @@ func (e *Engine) saveExecutionResults(
 		return nil, fmt.Errorf("could not generate execution receipt: %w", err)
 	}
 
+	if e.registerHistory != nil {
+		err = e.registerHistory.RecordExecuted(block.Header, result.TrieUpdates)
+		if err != nil {
+			return nil, fmt.Errorf("cannot record register history: %w", err)
+		}
+	}
+
 	err = e.execState.SaveExecutionResults(childCtx,
 		block.Header,
 		endState,
@@ -1148,7 +1211,8 @@ func (e *Engine) saveExecutionResults(
 		executionReceipt,
 		result.Events,
 		result.ServiceEvents,
-		result.TransactionResults)
+		result.TransactionResults,
+		result.TransactionProfiles)
 	if err != nil {
 		return nil, fmt.Errorf("cannot persist execution state: %w", err)
 	}
@@ -8,7 +8,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dgraph-io/badger/v2"
 	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -17,6 +19,7 @@ import (
 	engineCommon "github.com/onflow/flow-go/engine"
 	"github.com/onflow/flow-go/engine/execution"
 	computation "github.com/onflow/flow-go/engine/execution/computation/mock"
+	"github.com/onflow/flow-go/engine/execution/history"
 	provider "github.com/onflow/flow-go/engine/execution/provider/mock"
 	"github.com/onflow/flow-go/engine/execution/state/delta"
 	state "github.com/onflow/flow-go/engine/execution/state/mock"
@@ -32,6 +35,8 @@ import (
 	stateProtocol "github.com/onflow/flow-go/state/protocol"
 	protocol "github.com/onflow/flow-go/state/protocol/mock"
 	storageerr "github.com/onflow/flow-go/storage"
+	bstorage "github.com/onflow/flow-go/storage/badger"
+	storagemock "github.com/onflow/flow-go/storage/mock"
 	storage "github.com/onflow/flow-go/storage/mocks"
 	"github.com/onflow/flow-go/utils/unittest"
 	"github.com/onflow/flow-go/utils/unittest/mocks"
@@ -251,6 +256,7 @@ func (ctx *testingContext) assertSuccessfulBlockComputation(
 			mock.Anything,
 			mock.Anything,
 			mock.Anything,
+			mock.Anything,
 		).
 		Return(nil)
 
@@ -902,7 +908,7 @@ func TestExecutionGenerationResultsAreChained(t *testing.T) {
 		Return(previousExecutionResultID, nil)
 
 	execState.
-		On("SaveExecutionResults", mock.Anything, executableBlock.Block.Header, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		On("SaveExecutionResults", mock.Anything, executableBlock.Block.Header, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 		Return(nil)
 
 	e := Engine{
@@ -1424,3 +1430,59 @@ func TestLoadingUnexecutedBlocks(t *testing.T) {
 			pending)
 	})
 }
+
+// TestNewBlockView tests that the state of a block is read from the ledger while it holds the state, and from the
+// register history otherwise
+func TestNewBlockView(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		registers, err := bstorage.NewRegisters(db)
+		require.NoError(t, err)
+
+		id := flow.NewRegisterID("owner", "", "key")
+		err = registers.Bootstrap(10, flow.RegisterEntries{{Key: id, Value: []byte("history")}})
+		require.NoError(t, err)
+
+		indexed := unittest.BlockHeaderFixture()
+		indexed.Height = 10
+		notIndexed := unittest.BlockHeaderWithParentFixture(&indexed)
+
+		headers := new(storagemock.Headers)
+		headers.On("ByHeight", uint64(10)).Return(&indexed, nil)
+
+		executionState := new(state.ExecutionState)
+		e := &Engine{
+			execState:       executionState,
+			registerHistory: history.NewIndexer(zerolog.Nop(), db, new(protocol.State), headers, registers),
+		}
+
+		inForest := unittest.StateCommitmentFixture()
+		evicted := unittest.StateCommitmentFixture()
+		executionState.On("HasState", inForest).Return(true)
+		executionState.On("HasState", evicted).Return(false)
+		ledgerView := delta.NewView(func(owner, controller, key string) (flow.RegisterValue, error) {
+			return []byte("ledger"), nil
+		})
+		// the ledger is only read for the state in the forest, and the state which is not indexed
+		executionState.On("NewView", inForest).Return(ledgerView).Once()
+		executionState.On("NewView", evicted).Return(ledgerView).Once()
+
+		// states held in the forest are read from the ledger, even if they are indexed in the history
+		view, err := e.newBlockView(&indexed, inForest)
+		require.NoError(t, err)
+		require.Same(t, ledgerView, view)
+
+		// evicted states are read from the history
+		view, err = e.newBlockView(&indexed, evicted)
+		require.NoError(t, err)
+		value, err := view.Get(id.Owner, id.Controller, id.Key)
+		require.NoError(t, err)
+		require.Equal(t, flow.RegisterValue("history"), value)
+
+		// and from the ledger if they are not indexed
+		view, err = e.newBlockView(&notIndexed, evicted)
+		require.NoError(t, err)
+		require.Same(t, ledgerView, view)
+
+		executionState.AssertExpectations(t)
+	})
+}
@@ -6,6 +6,9 @@ import (
 	"github.com/onflow/flow-go/model/flow"
 )
 
+// AccountReader returns the Account details at the block it was created for, or nil if the account doesn't exist.
+type AccountReader func(address flow.Address) (*flow.Account, error)
+
 // IngestRPC represents the RPC calls that the execution ingest engine exposes to support the Access Node API calls
 type IngestRPC interface {
 
@@ -15,6 +18,9 @@ type IngestRPC interface {
 	// GetAccount returns the Account details at the given Block id
 	GetAccount(ctx context.Context, address flow.Address, blockID flow.Identifier) (*flow.Account, error)
 
+	// GetAccountReader returns an AccountReader reading the accounts at the given Block id, all from the same view
+	GetAccountReader(ctx context.Context, blockID flow.Identifier) (AccountReader, error)
+
 	// GetRegisterAtBlockID returns the value of a register at the given Block id (if available)
 	GetRegisterAtBlockID(ctx context.Context, owner, controller, key []byte, blockID flow.Identifier) ([]byte, error)
 }
@@ -5,6 +5,8 @@ package mock
 import (
 	context "context"
 
+	ingestion "github.com/onflow/flow-go/engine/execution/ingestion"
+
 	flow "github.com/onflow/flow-go/model/flow"
 
 	mock "github.com/stretchr/testify/mock"
@@ -63,6 +65,29 @@ func (_m *IngestRPC) GetAccount(ctx context.Context, address flow.Address, block
 	return r0, r1
 }
 
+// GetAccountReader provides a mock function with given fields: ctx, blockID
+func (_m *IngestRPC) GetAccountReader(ctx context.Context, blockID flow.Identifier) (ingestion.AccountReader, error) {
+	ret := _m.Called(ctx, blockID)
+
+	var r0 ingestion.AccountReader
+	if rf, ok := ret.Get(0).(func(context.Context, flow.Identifier) ingestion.AccountReader); ok {
+		r0 = rf(ctx, blockID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(ingestion.AccountReader)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, flow.Identifier) error); ok {
+		r1 = rf(ctx, blockID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetRegisterAtBlockID provides a mock function with given fields: ctx, owner, controller, key, blockID
 func (_m *IngestRPC) GetRegisterAtBlockID(ctx context.Context, owner []byte, controller []byte, key []byte, blockID flow.Identifier) ([]byte, error) {
 	ret := _m.Called(ctx, owner, controller, key, blockID)
@@ -17,18 +17,19 @@ type ComputationOrder struct {
 }
 
 type ComputationResult struct {
-	ExecutableBlock    *entity.ExecutableBlock
-	StateSnapshots     []*delta.SpockSnapshot
-	StateCommitments   []flow.StateCommitment
-	Proofs             [][]byte
-	Events             []flow.EventsList
-	EventsHashes       []flow.Identifier
-	ServiceEvents      flow.EventsList
-	TransactionResults []flow.TransactionResult
-	ComputationUsed    uint64
-	StateReads         uint64
-	TrieUpdates        []*ledger.TrieUpdate
-	ExecutionDataID    flow.Identifier
+	ExecutableBlock     *entity.ExecutableBlock
+	StateSnapshots      []*delta.SpockSnapshot
+	StateCommitments    []flow.StateCommitment
+	Proofs              [][]byte
+	Events              []flow.EventsList
+	EventsHashes        []flow.Identifier
+	ServiceEvents       flow.EventsList
+	TransactionResults  []flow.TransactionResult
+	TransactionProfiles []flow.TransactionProfile
+	ComputationUsed     uint64
+	StateReads          uint64
+	TrieUpdates         []*ledger.TrieUpdate
+	ExecutionDataID     flow.Identifier
 }
 
 func (cr *ComputationResult) AddEvents(chunkIndex int, inp []flow.Event) {
@@ -43,6 +44,10 @@ func (cr *ComputationResult) AddTransactionResult(inp *flow.TransactionResult) {
 	cr.TransactionResults = append(cr.TransactionResults, *inp)
 }
 
+func (cr *ComputationResult) AddTransactionProfile(inp *flow.TransactionProfile) {
+	cr.TransactionProfiles = append(cr.TransactionProfiles, *inp)
+}
+
 func (cr *ComputationResult) AddComputationUsed(inp uint64) {
 	cr.ComputationUsed += inp
 }
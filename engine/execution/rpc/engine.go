@@ -13,6 +13,7 @@ import (
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/status"
 
 	"github.com/onflow/flow/protobuf/go/flow/execution"
@@ -20,6 +21,8 @@ import (
 	"github.com/onflow/flow-go/engine"
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	"github.com/onflow/flow-go/engine/execution/ingestion"
+	"github.com/onflow/flow-go/engine/execution/rpc/extended"
+	fvmErrors "github.com/onflow/flow-go/fvm/errors"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/state/protocol"
 	"github.com/onflow/flow-go/storage"
@@ -28,9 +31,10 @@ import (
 
 // Config defines the configurable options for the gRPC server.
 type Config struct {
-	ListenAddr        string
-	MaxMsgSize        int  // In bytes
-	RpcMetricsEnabled bool // enable GRPC metrics reporting
+	ListenAddr           string
+	MaxMsgSize           int                              // In bytes
+	RpcMetricsEnabled    bool                             // enable GRPC metrics reporting
+	TransportCredentials credentials.TransportCredentials // the TLS credentials of the server, the server is insecure if nil
 }
 
 // Engine implements a gRPC server with a simplified version of the Observation API.
@@ -53,6 +57,7 @@ func New(
 	events storage.Events,
 	exeResults storage.ExecutionResults,
 	txResults storage.TransactionResults,
+	txProfiles storage.TransactionProfiles,
 	chainID flow.ChainID) *Engine {
 	log = log.With().Str("engine", "rpc").Logger()
 
@@ -70,6 +75,11 @@ func New(
 		serverOptions = append(serverOptions, grpc.UnaryInterceptor(grpc_prometheus.UnaryServerInterceptor))
 	}
 
+	// if TLS is enabled, serve the requests over TLS
+	if config.TransportCredentials != nil {
+		serverOptions = append(serverOptions, grpc.Creds(config.TransportCredentials))
+	}
+
 	server := grpc.NewServer(serverOptions...)
 
 	eng := &Engine{
@@ -96,6 +106,11 @@ func New(
 	}
 
 	execution.RegisterExecutionAPIServer(eng.server, eng.handler)
+	extended.RegisterExtendedExecutionAPIServer(eng.server, &extendedHandler{
+		engine:              e,
+		chain:               chainID,
+		transactionProfiles: txProfiles,
+	})
 
 	return eng
 }
@@ -164,6 +179,11 @@ func (h *handler) ExecuteScriptAtBlockID(
 
 	value, err := h.engine.ExecuteScriptAtBlockID(ctx, req.GetScript(), req.GetArguments(), blockID)
 	if err != nil {
+		// the node is too busy to execute the script, another node may be able to execute it
+		var rejected *fvmErrors.ScriptExecutionRejectedError
+		if errors.As(err, &rejected) {
+			return nil, status.Errorf(codes.ResourceExhausted, "failed to execute script: %v", err)
+		}
 		// return code 3 as this passes the litmus test in our context
 		return nil, status.Errorf(codes.InvalidArgument, "failed to execute script: %v", err)
 	}
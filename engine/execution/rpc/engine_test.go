@@ -3,6 +3,7 @@ package rpc
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/rand"
 	"testing"
 
@@ -17,6 +18,7 @@ import (
 
 	"github.com/onflow/flow-go/engine/common/rpc/convert"
 	ingestion "github.com/onflow/flow-go/engine/execution/ingestion/mock"
+	fvmErrors "github.com/onflow/flow-go/fvm/errors"
 	"github.com/onflow/flow-go/model/flow"
 	realstorage "github.com/onflow/flow-go/storage"
 	storage "github.com/onflow/flow-go/storage/mock"
@@ -86,6 +88,14 @@ func (suite *Suite) TestExecuteScriptAtBlockID() {
 		errors.Is(err, status.Error(codes.InvalidArgument, ""))
 	})
 
+	suite.Run("valid request with script execution rejected", func() {
+		mockEngine.On("ExecuteScriptAtBlockID", ctx, script, arguments, mockIdentifier).
+			Return(nil, fmt.Errorf("failed to execute script: %w", fvmErrors.NewScriptExecutionRejectedError(1))).Once()
+		_, err := handler.ExecuteScriptAtBlockID(ctx, &executionReq)
+		suite.Require().Error(err)
+		suite.Require().Equal(codes.ResourceExhausted, status.Code(err))
+	})
+
 	suite.Run("invalid request with nil blockID", func() {
 		executionReqWithNilBlock := execution.ExecuteScriptAtBlockIDRequest{
 			BlockId: nil,
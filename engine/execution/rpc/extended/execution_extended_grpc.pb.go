@@ -0,0 +1,147 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package extended
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ExtendedExecutionAPIClient is the client API for ExtendedExecutionAPI service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ExtendedExecutionAPIClient interface {
+	// GetTransactionProfile gets the breakdown of the resources used to execute
+	// a transaction of an executed block.
+	GetTransactionProfile(ctx context.Context, in *GetTransactionProfileRequest, opts ...grpc.CallOption) (*GetTransactionProfileResponse, error)
+	// GetAccountsAtBlockID gets a batch of accounts at the given block. Accounts
+	// which could not be retrieved are reported in their result instead of
+	// failing the whole request.
+	GetAccountsAtBlockID(ctx context.Context, in *GetAccountsAtBlockIDRequest, opts ...grpc.CallOption) (*GetAccountsAtBlockIDResponse, error)
+}
+
+type extendedExecutionAPIClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewExtendedExecutionAPIClient(cc grpc.ClientConnInterface) ExtendedExecutionAPIClient {
+	return &extendedExecutionAPIClient{cc}
+}
+
+func (c *extendedExecutionAPIClient) GetTransactionProfile(ctx context.Context, in *GetTransactionProfileRequest, opts ...grpc.CallOption) (*GetTransactionProfileResponse, error) {
+	out := new(GetTransactionProfileResponse)
+	err := c.cc.Invoke(ctx, "/execution.extended.ExtendedExecutionAPI/GetTransactionProfile", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *extendedExecutionAPIClient) GetAccountsAtBlockID(ctx context.Context, in *GetAccountsAtBlockIDRequest, opts ...grpc.CallOption) (*GetAccountsAtBlockIDResponse, error) {
+	out := new(GetAccountsAtBlockIDResponse)
+	err := c.cc.Invoke(ctx, "/execution.extended.ExtendedExecutionAPI/GetAccountsAtBlockID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExtendedExecutionAPIServer is the server API for ExtendedExecutionAPI service.
+// All implementations must embed UnimplementedExtendedExecutionAPIServer
+// for forward compatibility
+type ExtendedExecutionAPIServer interface {
+	// GetTransactionProfile gets the breakdown of the resources used to execute
+	// a transaction of an executed block.
+	GetTransactionProfile(context.Context, *GetTransactionProfileRequest) (*GetTransactionProfileResponse, error)
+	// GetAccountsAtBlockID gets a batch of accounts at the given block. Accounts
+	// which could not be retrieved are reported in their result instead of
+	// failing the whole request.
+	GetAccountsAtBlockID(context.Context, *GetAccountsAtBlockIDRequest) (*GetAccountsAtBlockIDResponse, error)
+	mustEmbedUnimplementedExtendedExecutionAPIServer()
+}
+
+// UnimplementedExtendedExecutionAPIServer must be embedded to have forward compatible implementations.
+type UnimplementedExtendedExecutionAPIServer struct {
+}
+
+func (UnimplementedExtendedExecutionAPIServer) GetTransactionProfile(context.Context, *GetTransactionProfileRequest) (*GetTransactionProfileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionProfile not implemented")
+}
+func (UnimplementedExtendedExecutionAPIServer) GetAccountsAtBlockID(context.Context, *GetAccountsAtBlockIDRequest) (*GetAccountsAtBlockIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountsAtBlockID not implemented")
+}
+func (UnimplementedExtendedExecutionAPIServer) mustEmbedUnimplementedExtendedExecutionAPIServer() {}
+
+// UnsafeExtendedExecutionAPIServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ExtendedExecutionAPIServer will
+// result in compilation errors.
+type UnsafeExtendedExecutionAPIServer interface {
+	mustEmbedUnimplementedExtendedExecutionAPIServer()
+}
+
+func RegisterExtendedExecutionAPIServer(s grpc.ServiceRegistrar, srv ExtendedExecutionAPIServer) {
+	s.RegisterService(&ExtendedExecutionAPI_ServiceDesc, srv)
+}
+
+func _ExtendedExecutionAPI_GetTransactionProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExtendedExecutionAPIServer).GetTransactionProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/execution.extended.ExtendedExecutionAPI/GetTransactionProfile",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExtendedExecutionAPIServer).GetTransactionProfile(ctx, req.(*GetTransactionProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExtendedExecutionAPI_GetAccountsAtBlockID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountsAtBlockIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExtendedExecutionAPIServer).GetAccountsAtBlockID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/execution.extended.ExtendedExecutionAPI/GetAccountsAtBlockID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExtendedExecutionAPIServer).GetAccountsAtBlockID(ctx, req.(*GetAccountsAtBlockIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ExtendedExecutionAPI_ServiceDesc is the grpc.ServiceDesc for ExtendedExecutionAPI service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ExtendedExecutionAPI_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "execution.extended.ExtendedExecutionAPI",
+	HandlerType: (*ExtendedExecutionAPIServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTransactionProfile",
+			Handler:    _ExtendedExecutionAPI_GetTransactionProfile_Handler,
+		},
+		{
+			MethodName: "GetAccountsAtBlockID",
+			Handler:    _ExtendedExecutionAPI_GetAccountsAtBlockID_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "extended/execution_extended.proto",
+}
@@ -0,0 +1,174 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/onflow/cadence/runtime/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/engine/execution/ingestion"
+	"github.com/onflow/flow-go/engine/execution/rpc/extended"
+	"github.com/onflow/flow-go/fvm/meter"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+)
+
+// extendedHandler serves the ExtendedExecutionAPI, which complements the Flow Execution API
+// served by handler.
+type extendedHandler struct {
+	extended.UnimplementedExtendedExecutionAPIServer
+	engine              ingestion.IngestRPC
+	chain               flow.ChainID
+	transactionProfiles storage.TransactionProfiles
+}
+
+// GetTransactionProfile returns the profile of a transaction of an executed block.
+func (h *extendedHandler) GetTransactionProfile(
+	_ context.Context,
+	req *extended.GetTransactionProfileRequest,
+) (*extended.GetTransactionProfileResponse, error) {
+
+	blockID, err := convert.BlockID(req.GetBlockId())
+	if err != nil {
+		return nil, err
+	}
+
+	txID, err := convert.TransactionID(req.GetTransactionId())
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := h.transactionProfiles.ByBlockIDTransactionID(blockID, txID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "transaction profile not found")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to get transaction profile: %v", err)
+	}
+
+	return TransactionProfileToMessage(profile), nil
+}
+
+// GetAccountsAtBlockID returns the accounts with the given addresses at the given block, all read from the same view
+// of the execution state. Errors getting an account are reported in the result of the account.
+func (h *extendedHandler) GetAccountsAtBlockID(
+	ctx context.Context,
+	req *extended.GetAccountsAtBlockIDRequest,
+) (*extended.GetAccountsAtBlockIDResponse, error) {
+
+	blockID, err := convert.BlockID(req.GetBlockId())
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := req.GetAddresses()
+	if len(addresses) > access.MaxAccountsPerRequest {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d accounts can be requested at a time", access.MaxAccountsPerRequest)
+	}
+
+	getAccount, err := h.engine.GetAccountReader(ctx, blockID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get accounts: %v", err)
+	}
+
+	results := make([]*extended.AccountResult, len(addresses))
+	for i, address := range addresses {
+		results[i] = h.getAccount(getAccount, address)
+	}
+
+	return &extended.GetAccountsAtBlockIDResponse{
+		Results: results,
+	}, nil
+}
+
+// getAccount gets a single account of a batch, with the same errors as handler.GetAccountAtBlockID.
+func (h *extendedHandler) getAccount(getAccount ingestion.AccountReader, address []byte) *extended.AccountResult {
+	result := &extended.AccountResult{
+		Address: address,
+	}
+	fail := func(err error) *extended.AccountResult {
+		errStatus := status.Convert(err)
+		result.ErrorCode = uint32(errStatus.Code())
+		result.ErrorMessage = errStatus.Message()
+		return result
+	}
+
+	flowAddress, err := convert.Address(address, h.chain.Chain())
+	if err != nil {
+		return fail(err)
+	}
+
+	account, err := getAccount(flowAddress)
+	if err != nil {
+		return fail(status.Errorf(codes.Internal, "failed to get account: %v", err))
+	}
+
+	if account == nil {
+		return fail(status.Errorf(codes.NotFound, "account with address %s does not exist", flowAddress))
+	}
+
+	result.Account = AccountToExtendedMessage(account)
+	return result
+}
+
+// AccountToExtendedMessage converts an account to the account message of the ExtendedExecutionAPI.
+func AccountToExtendedMessage(a *flow.Account) *extended.Account {
+	keys := make([]*extended.AccountKey, len(a.Keys))
+	for i, k := range a.Keys {
+		keys[i] = &extended.AccountKey{
+			Index:          uint32(k.Index),
+			PublicKey:      k.PublicKey.Encode(),
+			SignAlgo:       uint32(k.SignAlgo),
+			HashAlgo:       uint32(k.HashAlgo),
+			Weight:         uint32(k.Weight),
+			SequenceNumber: uint32(k.SeqNumber),
+			Revoked:        k.Revoked,
+		}
+	}
+
+	return &extended.Account{
+		Address:   a.Address.Bytes(),
+		Balance:   a.Balance,
+		Keys:      keys,
+		Contracts: a.Contracts,
+	}
+}
+
+// TransactionProfileToMessage converts a transaction profile to a response message, which names the
+// computation and memory kinds.
+func TransactionProfileToMessage(profile *flow.TransactionProfile) *extended.GetTransactionProfileResponse {
+	computationKinds := make([]*extended.ProfiledKind, 0, len(profile.ComputationKinds))
+	for _, kind := range profile.ComputationKinds {
+		computationKinds = append(computationKinds, &extended.ProfiledKind{
+			Kind:      kind.Kind,
+			Name:      meter.ComputationKindName(common.ComputationKind(kind.Kind)),
+			Intensity: kind.Intensity,
+			Used:      kind.Used,
+		})
+	}
+
+	memoryKinds := make([]*extended.ProfiledKind, 0, len(profile.MemoryKinds))
+	for _, kind := range profile.MemoryKinds {
+		memoryKinds = append(memoryKinds, &extended.ProfiledKind{
+			Kind:      kind.Kind,
+			Name:      common.MemoryKind(kind.Kind).String(),
+			Intensity: kind.Intensity,
+			Used:      kind.Used,
+		})
+	}
+
+	return &extended.GetTransactionProfileResponse{
+		TransactionId:        profile.TransactionID[:],
+		ComputationUsed:      profile.ComputationUsed,
+		MemoryUsed:           profile.MemoryUsed,
+		ComputationKinds:     computationKinds,
+		MemoryKinds:          memoryKinds,
+		RegisterBytesRead:    profile.RegisterBytesRead,
+		RegisterBytesWritten: profile.RegisterBytesWritten,
+	}
+}
@@ -0,0 +1,163 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/access"
+	realingestion "github.com/onflow/flow-go/engine/execution/ingestion"
+	ingestion "github.com/onflow/flow-go/engine/execution/ingestion/mock"
+	"github.com/onflow/flow-go/engine/execution/rpc/extended"
+	"github.com/onflow/flow-go/fvm/meter"
+	"github.com/onflow/flow-go/model/flow"
+	realstorage "github.com/onflow/flow-go/storage"
+	storage "github.com/onflow/flow-go/storage/mock"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestGetTransactionProfile(t *testing.T) {
+	blockID := unittest.IdentifierFixture()
+	txID := unittest.IdentifierFixture()
+
+	req := &extended.GetTransactionProfileRequest{
+		BlockId:       blockID[:],
+		TransactionId: txID[:],
+	}
+
+	t.Run("profile found", func(t *testing.T) {
+		profile := &flow.TransactionProfile{
+			TransactionID:   txID,
+			ComputationUsed: 12,
+			MemoryUsed:      3400,
+			ComputationKinds: []flow.ProfiledKind{
+				{Kind: uint32(common.ComputationKindStatement), Intensity: 10, Used: 10},
+				{Kind: uint32(meter.ComputationKindGetValue), Intensity: 4},
+			},
+			MemoryKinds: []flow.ProfiledKind{
+				{Kind: uint32(common.MemoryKindString), Intensity: 2, Used: 2},
+			},
+			RegisterBytesRead:    120,
+			RegisterBytesWritten: 30,
+		}
+
+		profiles := storage.NewTransactionProfiles(t)
+		profiles.On("ByBlockIDTransactionID", blockID, txID).Return(profile, nil).Once()
+		handler := &extendedHandler{transactionProfiles: profiles}
+
+		resp, err := handler.GetTransactionProfile(context.Background(), req)
+		require.NoError(t, err)
+
+		require.Equal(t, txID[:], resp.GetTransactionId())
+		require.Equal(t, uint64(12), resp.GetComputationUsed())
+		require.Equal(t, uint64(3400), resp.GetMemoryUsed())
+		require.Equal(t, uint64(120), resp.GetRegisterBytesRead())
+		require.Equal(t, uint64(30), resp.GetRegisterBytesWritten())
+
+		require.Len(t, resp.GetComputationKinds(), 2)
+		require.Equal(t, "Statement", resp.GetComputationKinds()[0].GetName())
+		require.Equal(t, uint64(10), resp.GetComputationKinds()[0].GetUsed())
+		require.Equal(t, "GetValue", resp.GetComputationKinds()[1].GetName())
+		require.Equal(t, uint64(4), resp.GetComputationKinds()[1].GetIntensity())
+
+		require.Len(t, resp.GetMemoryKinds(), 1)
+		require.Equal(t, "String", resp.GetMemoryKinds()[0].GetName())
+	})
+
+	t.Run("profile not found", func(t *testing.T) {
+		profiles := storage.NewTransactionProfiles(t)
+		profiles.On("ByBlockIDTransactionID", blockID, txID).Return(nil, realstorage.ErrNotFound).Once()
+		handler := &extendedHandler{transactionProfiles: profiles}
+
+		_, err := handler.GetTransactionProfile(context.Background(), req)
+		require.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("missing transaction ID", func(t *testing.T) {
+		handler := &extendedHandler{transactionProfiles: storage.NewTransactionProfiles(t)}
+
+		_, err := handler.GetTransactionProfile(context.Background(), &extended.GetTransactionProfileRequest{
+			BlockId: blockID[:],
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestGetAccountsAtBlockID(t *testing.T) {
+	blockID := unittest.IdentifierFixture()
+	chain := flow.Mainnet.Chain()
+	found := chain.ServiceAddress()
+	missing := flow.HexToAddress("f233dcee88fe0abe")
+	failing := flow.HexToAddress("1654653399040a61")
+
+	t.Run("errors are reported per account", func(t *testing.T) {
+		var read []flow.Address
+		getAccount := func(address flow.Address) (*flow.Account, error) {
+			read = append(read, address)
+			switch address {
+			case found:
+				return &flow.Account{Address: found, Balance: 10}, nil
+			case failing:
+				return nil, errors.New("failed")
+			default:
+				return nil, nil
+			}
+		}
+
+		// the accounts are all read at the block resolved once
+		engine := new(ingestion.IngestRPC)
+		engine.On("GetAccountReader", mock.Anything, blockID).Return(realingestion.AccountReader(getAccount), nil).Once()
+		handler := &extendedHandler{engine: engine, chain: flow.Mainnet}
+
+		invalid := []byte{1, 2, 3}
+		resp, err := handler.GetAccountsAtBlockID(context.Background(), &extended.GetAccountsAtBlockIDRequest{
+			BlockId:   blockID[:],
+			Addresses: [][]byte{found.Bytes(), missing.Bytes(), failing.Bytes(), invalid},
+		})
+		require.NoError(t, err)
+		engine.AssertExpectations(t)
+		require.Equal(t, []flow.Address{found, missing, failing}, read)
+
+		results := resp.GetResults()
+		require.Len(t, results, 4)
+
+		require.Equal(t, found.Bytes(), results[0].GetAddress())
+		require.Equal(t, uint64(10), results[0].GetAccount().GetBalance())
+		require.Equal(t, uint32(codes.OK), results[0].GetErrorCode())
+
+		require.Nil(t, results[1].GetAccount())
+		require.Equal(t, uint32(codes.NotFound), results[1].GetErrorCode())
+		require.Nil(t, results[2].GetAccount())
+		require.Equal(t, uint32(codes.Internal), results[2].GetErrorCode())
+		require.Nil(t, results[3].GetAccount())
+		require.Equal(t, uint32(codes.InvalidArgument), results[3].GetErrorCode())
+	})
+
+	t.Run("block state can't be resolved", func(t *testing.T) {
+		engine := new(ingestion.IngestRPC)
+		engine.On("GetAccountReader", mock.Anything, blockID).Return(nil, errors.New("not executed")).Once()
+		handler := &extendedHandler{engine: engine, chain: flow.Mainnet}
+
+		_, err := handler.GetAccountsAtBlockID(context.Background(), &extended.GetAccountsAtBlockIDRequest{
+			BlockId:   blockID[:],
+			Addresses: [][]byte{found.Bytes()},
+		})
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("too many addresses", func(t *testing.T) {
+		handler := &extendedHandler{engine: new(ingestion.IngestRPC), chain: flow.Mainnet}
+
+		_, err := handler.GetAccountsAtBlockID(context.Background(), &extended.GetAccountsAtBlockIDRequest{
+			BlockId:   blockID[:],
+			Addresses: make([][]byte, access.MaxAccountsPerRequest+1),
+		})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -188,6 +188,20 @@ func (_m *ExecutionState) GetRegisters(_a0 context.Context, _a1 flow.StateCommit
 	return r0, r1
 }
 
+// HasState provides a mock function with given fields: _a0
+func (_m *ExecutionState) HasState(_a0 flow.StateCommitment) bool {
+	ret := _m.Called(_a0)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(flow.StateCommitment) bool); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // NewView provides a mock function with given fields: _a0
 func (_m *ExecutionState) NewView(_a0 flow.StateCommitment) *delta.View {
 	ret := _m.Called(_a0)
@@ -227,13 +241,13 @@ func (_m *ExecutionState) RetrieveStateDelta(_a0 context.Context, _a1 flow.Ident
 	return r0, r1
 }
 
-// SaveExecutionResults provides a mock function with given fields: ctx, header, endState, chunkDataPacks, executionReceipt, events, serviceEvents, results
-func (_m *ExecutionState) SaveExecutionResults(ctx context.Context, header *flow.Header, endState flow.StateCommitment, chunkDataPacks []*flow.ChunkDataPack, executionReceipt *flow.ExecutionReceipt, events []flow.EventsList, serviceEvents flow.EventsList, results []flow.TransactionResult) error {
-	ret := _m.Called(ctx, header, endState, chunkDataPacks, executionReceipt, events, serviceEvents, results)
+// SaveExecutionResults provides a mock function with given fields: ctx, header, endState, chunkDataPacks, executionReceipt, events, serviceEvents, results, profiles
+func (_m *ExecutionState) SaveExecutionResults(ctx context.Context, header *flow.Header, endState flow.StateCommitment, chunkDataPacks []*flow.ChunkDataPack, executionReceipt *flow.ExecutionReceipt, events []flow.EventsList, serviceEvents flow.EventsList, results []flow.TransactionResult, profiles []flow.TransactionProfile) error {
+	ret := _m.Called(ctx, header, endState, chunkDataPacks, executionReceipt, events, serviceEvents, results, profiles)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *flow.Header, flow.StateCommitment, []*flow.ChunkDataPack, *flow.ExecutionReceipt, []flow.EventsList, flow.EventsList, []flow.TransactionResult) error); ok {
-		r0 = rf(ctx, header, endState, chunkDataPacks, executionReceipt, events, serviceEvents, results)
+	if rf, ok := ret.Get(0).(func(context.Context, *flow.Header, flow.StateCommitment, []*flow.ChunkDataPack, *flow.ExecutionReceipt, []flow.EventsList, flow.EventsList, []flow.TransactionResult, []flow.TransactionProfile) error); ok {
+		r0 = rf(ctx, header, endState, chunkDataPacks, executionReceipt, events, serviceEvents, results, profiles)
 	} else {
 		r0 = ret.Error(0)
 	}
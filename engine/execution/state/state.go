@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/dgraph-io/badger/v2"
@@ -25,6 +26,9 @@ type ReadOnlyExecutionState interface {
 	// NewView creates a new ready-only view at the given state commitment.
 	NewView(flow.StateCommitment) *delta.View
 
+	// HasState returns true if the ledger holds the given state commitment.
+	HasState(flow.StateCommitment) bool
+
 	GetRegisters(
 		context.Context,
 		flow.StateCommitment,
@@ -66,7 +70,8 @@ type ExecutionState interface {
 
 	SaveExecutionResults(ctx context.Context, header *flow.Header, endState flow.StateCommitment,
 		chunkDataPacks []*flow.ChunkDataPack,
-		executionReceipt *flow.ExecutionReceipt, events []flow.EventsList, serviceEvents flow.EventsList, results []flow.TransactionResult) error
+		executionReceipt *flow.ExecutionReceipt, events []flow.EventsList, serviceEvents flow.EventsList, results []flow.TransactionResult,
+		profiles []flow.TransactionProfile) error
 }
 
 const (
@@ -76,19 +81,20 @@ const (
 )
 
 type state struct {
-	tracer             module.Tracer
-	ls                 ledger.Ledger
-	commits            storage.Commits
-	blocks             storage.Blocks
-	headers            storage.Headers
-	collections        storage.Collections
-	chunkDataPacks     storage.ChunkDataPacks
-	results            storage.ExecutionResults
-	myReceipts         storage.MyExecutionReceipts
-	events             storage.Events
-	serviceEvents      storage.ServiceEvents
-	transactionResults storage.TransactionResults
-	db                 *badger.DB
+	tracer              module.Tracer
+	ls                  ledger.Ledger
+	commits             storage.Commits
+	blocks              storage.Blocks
+	headers             storage.Headers
+	collections         storage.Collections
+	chunkDataPacks      storage.ChunkDataPacks
+	results             storage.ExecutionResults
+	myReceipts          storage.MyExecutionReceipts
+	events              storage.Events
+	serviceEvents       storage.ServiceEvents
+	transactionResults  storage.TransactionResults
+	transactionProfiles storage.TransactionProfiles
+	db                  *badger.DB
 }
 
 func RegisterIDToKey(reg flow.RegisterID) ledger.Key {
@@ -99,6 +105,22 @@ func RegisterIDToKey(reg flow.RegisterID) ledger.Key {
 	})
 }
 
+// KeyToRegisterID converts a ledger key created with RegisterIDToKey back to the register ID.
+func KeyToRegisterID(key ledger.Key) (flow.RegisterID, error) {
+	if len(key.KeyParts) != 3 ||
+		key.KeyParts[0].Type != KeyPartOwner ||
+		key.KeyParts[1].Type != KeyPartController ||
+		key.KeyParts[2].Type != KeyPartKey {
+		return flow.RegisterID{}, fmt.Errorf("key not in expected format %s", key.String())
+	}
+
+	return flow.NewRegisterID(
+		string(key.KeyParts[0].Value),
+		string(key.KeyParts[1].Value),
+		string(key.KeyParts[2].Value),
+	), nil
+}
+
 // NewExecutionState returns a new execution state access layer for the given ledger storage.
 func NewExecutionState(
 	ls ledger.Ledger,
@@ -112,23 +134,25 @@ func NewExecutionState(
 	events storage.Events,
 	serviceEvents storage.ServiceEvents,
 	transactionResults storage.TransactionResults,
+	transactionProfiles storage.TransactionProfiles,
 	db *badger.DB,
 	tracer module.Tracer,
 ) ExecutionState {
 	return &state{
-		tracer:             tracer,
-		ls:                 ls,
-		commits:            commits,
-		blocks:             blocks,
-		headers:            headers,
-		collections:        collections,
-		chunkDataPacks:     chunkDataPacks,
-		results:            results,
-		myReceipts:         myReceipts,
-		events:             events,
-		serviceEvents:      serviceEvents,
-		transactionResults: transactionResults,
-		db:                 db,
+		tracer:              tracer,
+		ls:                  ls,
+		commits:             commits,
+		blocks:              blocks,
+		headers:             headers,
+		collections:         collections,
+		chunkDataPacks:      chunkDataPacks,
+		results:             results,
+		myReceipts:          myReceipts,
+		events:              events,
+		serviceEvents:       serviceEvents,
+		transactionResults:  transactionResults,
+		transactionProfiles: transactionProfiles,
+		db:                  db,
 	}
 
 }
@@ -168,6 +192,8 @@ func RegisterValuesToValues(values []flow.RegisterValue) []ledger.Value {
 
 func LedgerGetRegister(ldg ledger.Ledger, commitment flow.StateCommitment) delta.GetRegisterFunc {
 
+	// registers may be read concurrently when transactions are executed in parallel
+	var readCacheLock sync.RWMutex
 	readCache := make(map[flow.RegisterID]flow.RegisterEntry)
 
 	return func(owner, controller, key string) (flow.RegisterValue, error) {
@@ -177,7 +203,10 @@ func LedgerGetRegister(ldg ledger.Ledger, commitment flow.StateCommitment) delta
 			Key:        key,
 		}
 
-		if value, ok := readCache[regID]; ok {
+		readCacheLock.RLock()
+		value, ok := readCache[regID]
+		readCacheLock.RUnlock()
+		if ok {
 			return value.Value, nil
 		}
 
@@ -204,7 +233,9 @@ func LedgerGetRegister(ldg ledger.Ledger, commitment flow.StateCommitment) delta
 		}
 
 		// don't cache value with len zero
+		readCacheLock.Lock()
 		readCache[regID] = flow.RegisterEntry{Key: regID, Value: values[0]}
+		readCacheLock.Unlock()
 
 		return values[0], nil
 	}
@@ -214,6 +245,10 @@ func (s *state) NewView(commitment flow.StateCommitment) *delta.View {
 	return delta.NewView(LedgerGetRegister(s.ls, commitment))
 }
 
+func (s *state) HasState(commitment flow.StateCommitment) bool {
+	return s.ls.HasState(ledger.State(commitment))
+}
+
 type RegisterUpdatesHolder interface {
 	RegisterUpdates() ([]flow.RegisterID, []flow.RegisterValue)
 }
@@ -332,13 +367,13 @@ func (s *state) GetExecutionResultID(ctx context.Context, blockID flow.Identifie
 
 func (s *state) SaveExecutionResults(ctx context.Context, header *flow.Header, endState flow.StateCommitment,
 	chunkDataPacks []*flow.ChunkDataPack, executionReceipt *flow.ExecutionReceipt, events []flow.EventsList, serviceEvents flow.EventsList,
-	results []flow.TransactionResult) error {
-	return s.saveExecutionResults(ctx, header, endState, chunkDataPacks, executionReceipt, events, serviceEvents, results)
+	results []flow.TransactionResult, profiles []flow.TransactionProfile) error {
+	return s.saveExecutionResults(ctx, header, endState, chunkDataPacks, executionReceipt, events, serviceEvents, results, profiles)
 }
 
 func (s *state) saveExecutionResults(ctx context.Context, header *flow.Header, endState flow.StateCommitment,
 	chunkDataPacks []*flow.ChunkDataPack, executionReceipt *flow.ExecutionReceipt, events []flow.EventsList, serviceEvents flow.EventsList,
-	results []flow.TransactionResult) error {
+	results []flow.TransactionResult, profiles []flow.TransactionProfile) error {
 
 	spew.Config.DisableMethods = true
 	spew.Config.DisablePointerMethods = true
@@ -387,6 +422,11 @@ func (s *state) saveExecutionResults(ctx context.Context, header *flow.Header, e
 		return fmt.Errorf("cannot store transaction result: %w", err)
 	}
 
+	err = s.transactionProfiles.BatchStore(blockID, profiles, batch)
+	if err != nil {
+		return fmt.Errorf("cannot store transaction profiles: %w", err)
+	}
+
 	executionResult := &executionReceipt.ExecutionResult
 	err = s.results.BatchStore(executionResult, batch)
 	if err != nil {
@@ -52,7 +52,7 @@ func prepareTest(f func(t *testing.T, es state.ExecutionState, l *ledger.Ledger)
 			myReceipts := new(storage.MyExecutionReceipts)
 
 			es := state.NewExecutionState(
-				ls, stateCommitments, blocks, headers, collections, chunkDataPacks, results, myReceipts, events, serviceEvents, txResults, badgerDB, trace.NewNoopTracer(),
+				ls, stateCommitments, blocks, headers, collections, chunkDataPacks, results, myReceipts, events, serviceEvents, txResults, new(storage.TransactionProfiles), badgerDB, trace.NewNoopTracer(),
 			)
 
 			f(t, es, ls)
@@ -478,6 +478,7 @@ func ExecutionNode(t *testing.T, hub *stub.Hub, identity *flow.Identity, identit
 	eventsStorage := storage.NewEvents(node.Metrics, node.PublicDB)
 	serviceEventsStorage := storage.NewServiceEvents(node.Metrics, node.PublicDB)
 	txResultStorage := storage.NewTransactionResults(node.Metrics, node.PublicDB, storage.DefaultCacheSize)
+	txProfileStorage := storage.NewTransactionProfiles(node.Metrics, node.PublicDB, storage.DefaultCacheSize)
 	commitsStorage := storage.NewCommits(node.Metrics, node.PublicDB)
 	chunkDataPackStorage := storage.NewChunkDataPacks(node.Metrics, node.PublicDB, collectionsStorage, 100)
 	results := storage.NewExecutionResults(node.Metrics, node.PublicDB)
@@ -521,7 +522,7 @@ func ExecutionNode(t *testing.T, hub *stub.Hub, identity *flow.Identity, identit
 	require.NoError(t, err)
 
 	execState := executionState.NewExecutionState(
-		ls, commitsStorage, node.Blocks, node.Headers, collectionsStorage, chunkDataPackStorage, results, myReceipts, eventsStorage, serviceEventsStorage, txResultStorage, node.PublicDB, node.Tracer,
+		ls, commitsStorage, node.Blocks, node.Headers, collectionsStorage, chunkDataPackStorage, results, myReceipts, eventsStorage, serviceEventsStorage, txResultStorage, txProfileStorage, node.PublicDB, node.Tracer,
 	)
 
 	requestEngine, err := requester.New(
@@ -567,11 +568,15 @@ func ExecutionNode(t *testing.T, hub *stub.Hub, identity *flow.Identity, identit
 		vmCtx,
 		computation.DefaultProgramsCacheSize,
 		committer,
+		1,
 		computation.DefaultScriptLogThreshold,
 		computation.DefaultScriptExecutionTimeLimit,
+		computation.DefaultScriptExecutionWorkers,
+		computation.DefaultScriptExecutionQueueSize,
 		nil,
 		eds,
 		edCache,
+		nil,
 	)
 	require.NoError(t, err)
 
@@ -9,6 +9,7 @@ import (
 	"github.com/onflow/flow-go/fvm/state"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/storage"
 )
 
 // A Context defines a set of execution parameters used by the virtual machine.
@@ -46,6 +47,27 @@ func NewContext(logger zerolog.Logger, opts ...Option) Context {
 	return newContext(defaultContext(logger), opts...)
 }
 
+// NodeOptions returns the options nodes of the given chain run the virtual machine with,
+// reading historical block information from the given headers.
+func NodeOptions(chainID flow.ChainID, headers storage.Headers) []Option {
+	vmOpts := []Option{
+		WithChain(chainID.Chain()),
+		WithBlocks(NewBlockFinder(headers)),
+		WithAccountStorageLimit(true),
+	}
+	if chainID == flow.Testnet || chainID == flow.Canary || chainID == flow.Mainnet {
+		vmOpts = append(vmOpts,
+			WithTransactionFeesEnabled(true),
+		)
+	}
+	if chainID == flow.Testnet || chainID == flow.Canary || chainID == flow.Localnet || chainID == flow.Benchnet {
+		vmOpts = append(vmOpts,
+			WithRestrictedDeployment(false),
+		)
+	}
+	return vmOpts
+}
+
 // NewContextFromParent spawns a child execution context with the provided options.
 func NewContextFromParent(parent Context, opts ...Option) Context {
 	return newContext(parent, opts...)
@@ -0,0 +1,28 @@
+package fvm
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	storageMock "github.com/onflow/flow-go/storage/mock"
+)
+
+func TestNodeOptions(t *testing.T) {
+	headers := new(storageMock.Headers)
+
+	mainnet := NewContext(zerolog.Nop(), NodeOptions(flow.Mainnet, headers)...)
+	require.Equal(t, flow.Mainnet, mainnet.Chain.ChainID())
+	require.NotNil(t, mainnet.Blocks)
+	require.True(t, mainnet.LimitAccountStorage)
+	require.True(t, mainnet.TransactionFeesEnabled)
+	require.True(t, mainnet.RestrictedDeploymentEnabled)
+
+	localnet := NewContext(zerolog.Nop(), NodeOptions(flow.Localnet, headers)...)
+	require.Equal(t, flow.Localnet, localnet.Chain.ChainID())
+	require.True(t, localnet.LimitAccountStorage)
+	require.False(t, localnet.TransactionFeesEnabled)
+	require.False(t, localnet.RestrictedDeploymentEnabled)
+}
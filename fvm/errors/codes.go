@@ -64,6 +64,7 @@ const (
 	ErrCodeCouldNotDecodeExecutionParameterFromState ErrorCode = 1112
 	ErrCodeScriptExecutionCancelledError             ErrorCode = 1114
 	ErrCodeScriptExecutionTimedOutError              ErrorCode = 1113
+	ErrCodeScriptExecutionRejectedError              ErrorCode = 1115
 
 	// accounts errors 1200 - 1250
 	// ErrCodeAccountError              ErrorCode = 1200 - reserved
@@ -360,6 +360,33 @@ func (e *ScriptExecutionTimedOutError) Code() ErrorCode {
 	return ErrCodeScriptExecutionTimedOutError
 }
 
+// ScriptExecutionRejectedError indicates that Cadence Script execution
+// has been rejected, because too many scripts are waiting to be executed.
+//
+// note: this error is used by scripts only and
+// won't be emitted for transactions since transaction execution has to be deterministic.
+type ScriptExecutionRejectedError struct {
+	queued int
+}
+
+// NewScriptExecutionRejectedError construct a new ScriptExecutionRejectedError
+func NewScriptExecutionRejectedError(queued int) *ScriptExecutionRejectedError {
+	return &ScriptExecutionRejectedError{queued: queued}
+}
+
+func (e *ScriptExecutionRejectedError) Error() string {
+	return fmt.Sprintf(
+		"%s script execution is rejected, %d scripts are already waiting to be executed",
+		e.Code().String(),
+		e.queued,
+	)
+}
+
+// Code returns the error code for this error
+func (e *ScriptExecutionRejectedError) Code() ErrorCode {
+	return ErrCodeScriptExecutionRejectedError
+}
+
 // An CouldNotGetExecutionParameterFromStateError indicates that computation has exceeded its limit.
 type CouldNotGetExecutionParameterFromStateError struct {
 	address    string
@@ -4051,3 +4051,69 @@ func TestEnforcingComputationLimit(t *testing.T) {
 		})
 	}
 }
+
+func TestTransactionProfile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("profile contains the top kinds and the register bytes", newVMTest().withBootstrapProcedureOptions(
+		fvm.WithExecutionEffortWeights(
+			weightedMeter.ExecutionEffortWeights{
+				common.ComputationKindStatement:          1 << weightedMeter.MeterExecutionInternalPrecisionBytes,
+				common.ComputationKindLoop:               0,
+				common.ComputationKindFunctionInvocation: 0,
+			},
+		),
+	).run(
+		func(t *testing.T, vm *fvm.VirtualMachine, chain flow.Chain, ctx fvm.Context, view state.View, programs *programs.Programs) {
+			loops := uint64(10)
+			txBody := flow.NewTransactionBody().
+				SetScript([]byte(fmt.Sprintf(`
+				transaction() {
+					prepare(signer: AuthAccount) {
+						var i = 0
+						while i < %d { i = i + 1 }
+						signer.save(i, to: /storage/counter)
+					}
+				}
+			`, loops))).
+				SetProposalKey(chain.ServiceAddress(), 0, 0).
+				AddAuthorizer(chain.ServiceAddress()).
+				SetPayer(chain.ServiceAddress())
+
+			err := testutil.SignTransactionAsServiceAccount(txBody, 0, chain)
+			require.NoError(t, err)
+
+			tx := fvm.Transaction(txBody, 0)
+			err = vm.Run(ctx, tx, view, programs)
+			require.NoError(t, err)
+			require.NoError(t, tx.Err)
+
+			profile := tx.Profile
+			require.NotNil(t, profile)
+			require.Equal(t, tx.ID, profile.TransactionID)
+			require.Equal(t, tx.ComputationUsed, profile.ComputationUsed)
+			require.Equal(t, tx.MemoryUsed, profile.MemoryUsed)
+			require.Greater(t, profile.RegisterBytesRead, uint64(0))
+			require.Greater(t, profile.RegisterBytesWritten, uint64(0))
+
+			require.NotEmpty(t, profile.ComputationKinds)
+			require.LessOrEqual(t, len(profile.ComputationKinds), fvm.TransactionProfileKinds)
+			require.LessOrEqual(t, len(profile.MemoryKinds), fvm.TransactionProfileKinds)
+
+			// statements are the only weighted kind, so they come first
+			statements := profile.ComputationKinds[0]
+			require.Equal(t, uint32(common.ComputationKindStatement), statements.Kind)
+			require.Equal(t, statements.Intensity, statements.Used)
+
+			var foundLoops bool
+			for _, kind := range profile.ComputationKinds {
+				if kind.Kind == uint32(common.ComputationKindLoop) {
+					foundLoops = true
+					require.Equal(t, loops, kind.Intensity)
+					require.Equal(t, uint64(0), kind.Used)
+				}
+			}
+			require.True(t, foundLoops)
+		},
+	))
+}
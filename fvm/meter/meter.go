@@ -38,6 +38,46 @@ const (
 	ComputationKindValueExists
 )
 
+var fvmComputationKindNames = map[common.ComputationKind]string{
+	ComputationKindHash:                       "Hash",
+	ComputationKindVerifySignature:            "VerifySignature",
+	ComputationKindAddAccountKey:              "AddAccountKey",
+	ComputationKindAddEncodedAccountKey:       "AddEncodedAccountKey",
+	ComputationKindAllocateStorageIndex:       "AllocateStorageIndex",
+	ComputationKindCreateAccount:              "CreateAccount",
+	ComputationKindEmitEvent:                  "EmitEvent",
+	ComputationKindGenerateUUID:               "GenerateUUID",
+	ComputationKindGetAccountAvailableBalance: "GetAccountAvailableBalance",
+	ComputationKindGetAccountBalance:          "GetAccountBalance",
+	ComputationKindGetAccountContractCode:     "GetAccountContractCode",
+	ComputationKindGetAccountContractNames:    "GetAccountContractNames",
+	ComputationKindGetAccountKey:              "GetAccountKey",
+	ComputationKindGetBlockAtHeight:           "GetBlockAtHeight",
+	ComputationKindGetCode:                    "GetCode",
+	ComputationKindGetCurrentBlockHeight:      "GetCurrentBlockHeight",
+	ComputationKindGetProgram:                 "GetProgram",
+	ComputationKindGetStorageCapacity:         "GetStorageCapacity",
+	ComputationKindGetStorageUsed:             "GetStorageUsed",
+	ComputationKindGetValue:                   "GetValue",
+	ComputationKindRemoveAccountContractCode:  "RemoveAccountContractCode",
+	ComputationKindResolveLocation:            "ResolveLocation",
+	ComputationKindRevokeAccountKey:           "RevokeAccountKey",
+	ComputationKindRevokeEncodedAccountKey:    "RevokeEncodedAccountKey",
+	ComputationKindSetProgram:                 "SetProgram",
+	ComputationKindSetValue:                   "SetValue",
+	ComputationKindUpdateAccountContractCode:  "UpdateAccountContractCode",
+	ComputationKindValidatePublicKey:          "ValidatePublicKey",
+	ComputationKindValueExists:                "ValueExists",
+}
+
+// ComputationKindName returns the name of a Cadence or FVM computation kind.
+func ComputationKindName(kind common.ComputationKind) string {
+	if name, ok := fvmComputationKindNames[kind]; ok {
+		return name
+	}
+	return kind.String()
+}
+
 type MeteredComputationIntensities map[common.ComputationKind]uint
 type MeteredMemoryIntensities map[common.MemoryKind]uint
 
@@ -351,6 +351,11 @@ func (m *Meter) SetComputationWeights(weights ExecutionEffortWeights) {
 	m.computationWeights = weights
 }
 
+// ComputationWeights returns the computation weights
+func (m *Meter) ComputationWeights() ExecutionEffortWeights {
+	return m.computationWeights
+}
+
 // MeterComputation captures computation usage and returns an error if it goes beyond the limit
 func (m *Meter) MeterComputation(kind common.ComputationKind, intensity uint) error {
 	m.computationIntensities[kind] += intensity
@@ -385,6 +390,11 @@ func (m *Meter) SetMemoryWeights(weights ExecutionMemoryWeights) {
 	m.memoryWeights = weights
 }
 
+// MemoryWeights returns the memory weights
+func (m *Meter) MemoryWeights() ExecutionMemoryWeights {
+	return m.memoryWeights
+}
+
 // MeterMemory captures memory usage and returns an error if it goes beyond the limit
 func (m *Meter) MeterMemory(kind common.MemoryKind, intensity uint) error {
 	m.memoryIntensities[kind] += intensity
@@ -5,6 +5,7 @@ import (
 
 	"github.com/onflow/cadence/runtime/common"
 	"github.com/onflow/cadence/runtime/interpreter"
+	"go.uber.org/atomic"
 
 	"github.com/onflow/flow-go/model/flow"
 
@@ -38,10 +39,12 @@ func emptyProgramGetFunc(_ common.Location) (*ProgramEntry, bool) {
 // during a cleanup method, which must be called only when the Cadence execution has finished.
 // It it also fork-aware, support cheap creation of children capturing local changes.
 type Programs struct {
-	lock       sync.RWMutex
-	programs   map[common.LocationID]ProgramEntry
-	parentFunc ProgramGetFunc
-	cleaned    bool
+	lock             sync.RWMutex
+	programs         map[common.LocationID]ProgramEntry
+	parentFunc       ProgramGetFunc
+	cleaned          bool
+	uses             sync.Map // common.AddressLocation -> *atomic.Uint64
+	updatedContracts []ContractUpdateKey
 }
 
 func NewEmptyPrograms() *Programs {
@@ -70,12 +73,41 @@ func (p *Programs) Get(location common.Location) (*interpreter.Program, *state.S
 	programEntry, has := p.get(location)
 
 	if has {
+		p.use(location, 1)
 		return programEntry.Program, programEntry.State, true
 	}
 
 	return nil, nil, false
 }
 
+// Peek returns the same as Get, without counting a use of the program.
+func (p *Programs) Peek(location common.Location) (*interpreter.Program, *state.State, bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	programEntry, has := p.get(location)
+
+	if has {
+		return programEntry.Program, programEntry.State, true
+	}
+
+	return nil, nil, false
+}
+
+// use counts uses of the program of a contract, it is safe to call under the read lock
+func (p *Programs) use(location common.Location, uses uint64) {
+	addressLocation, is := location.(common.AddressLocation)
+	if !is {
+		return
+	}
+
+	counter, ok := p.uses.Load(addressLocation)
+	if !ok {
+		counter, _ = p.uses.LoadOrStore(addressLocation, atomic.NewUint64(0))
+	}
+	counter.(*atomic.Uint64).Add(uses)
+}
+
 func (p *Programs) get(location common.Location) (*ProgramEntry, bool) {
 	programEntry, ok := p.programs[location.ID()]
 	if !ok {
@@ -93,6 +125,8 @@ func (p *Programs) Set(location common.Location, program *interpreter.Program, s
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
+	p.use(location, 1)
+
 	p.programs[location.ID()] = ProgramEntry{
 		Location: location,
 		Program:  program,
@@ -135,6 +169,7 @@ func (p *Programs) Cleanup(changedContracts []ContractUpdateKey) {
 	if len(changedContracts) > 0 {
 
 		p.cleaned = true
+		p.updatedContracts = append(p.updatedContracts, changedContracts...)
 
 		// Sop using parent's data to prevent
 		// infinite chaining of objects
@@ -154,3 +189,79 @@ func (p *Programs) Cleanup(changedContracts []ContractUpdateKey) {
 		}
 	}
 }
+
+// Cleaned indicates if the programs were cleaned up after contracts were updated, or forcefully,
+// so that the programs of the parent are not used anymore.
+func (p *Programs) Cleaned() bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.cleaned
+}
+
+// Locations returns the locations of the programs stored in this object, not including the
+// programs of the parent.
+func (p *Programs) Locations() []common.Location {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	locations := make([]common.Location, 0, len(p.programs))
+	for _, entry := range p.programs {
+		locations = append(locations, entry.Location)
+	}
+	return locations
+}
+
+// MergeChild applies the changes of a child created with ChildPrograms to this object,
+// leaving it in the same state as if the changes had been made to it directly.
+// Programs set on this object after the child was created are kept, unless the child was cleaned.
+func (p *Programs) MergeChild(child *Programs) {
+	child.lock.RLock()
+	defer child.lock.RUnlock()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if child.cleaned {
+		p.cleaned = true
+
+		// Stop using parent's data, as the child does
+		p.parentFunc = emptyProgramGetFunc
+
+		p.programs = make(map[common.LocationID]ProgramEntry, len(child.programs))
+	}
+
+	for id, entry := range child.programs {
+		p.programs[id] = entry
+	}
+
+	child.uses.Range(func(location, uses interface{}) bool {
+		p.use(location.(common.AddressLocation), uses.(*atomic.Uint64).Load())
+		return true
+	})
+	p.updatedContracts = append(p.updatedContracts, child.updatedContracts...)
+}
+
+// Uses returns how many times the programs of contracts were used, either loaded or retrieved, through this object.
+// Uses through children are included once the children are merged with MergeChild.
+func (p *Programs) Uses() map[common.AddressLocation]uint64 {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	uses := make(map[common.AddressLocation]uint64)
+	p.uses.Range(func(location, count interface{}) bool {
+		uses[location.(common.AddressLocation)] = count.(*atomic.Uint64).Load()
+		return true
+	})
+	return uses
+}
+
+// UpdatedContracts returns the contracts updated since this object was created, which caused it to be cleaned.
+func (p *Programs) UpdatedContracts() []ContractUpdateKey {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	updated := make([]ContractUpdateKey, len(p.updatedContracts))
+	copy(updated, p.updatedContracts)
+	return updated
+}
@@ -1,6 +1,7 @@
 package programs
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/onflow/cadence/runtime/ast"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/onflow/flow-go/fvm/state"
 	"github.com/onflow/flow-go/fvm/utils"
+	"github.com/onflow/flow-go/model/flow"
 )
 
 func Test_Programs(t *testing.T) {
@@ -169,4 +171,103 @@ func Test_Programs(t *testing.T) {
 		require.True(t, child.HasChanges())
 	})
 
+	t.Run("merging children", func(t *testing.T) {
+		parentLocation := common.IdentifierLocation("parent")
+
+		parent := NewEmptyPrograms()
+		parent.Set(parentLocation, &interpreter.Program{}, newState)
+
+		programs := parent.ChildPrograms()
+
+		child := programs.ChildPrograms()
+		child.Set(addressLocation, &interpreter.Program{}, newState)
+		require.False(t, child.Cleaned())
+		require.Equal(t, []common.Location{addressLocation}, child.Locations())
+
+		programs.MergeChild(child)
+		require.False(t, programs.Cleaned())
+		require.Equal(t, []common.Location{addressLocation}, programs.Locations())
+
+		_, _, has := programs.Get(parentLocation)
+		require.True(t, has)
+
+		// a cleaned child doesn't use the programs of the parent anymore, and neither does the merged object
+		child = programs.ChildPrograms()
+		child.Cleanup([]ContractUpdateKey{{}})
+		child.Set(someLocation, someProgram, newState)
+		require.True(t, child.Cleaned())
+
+		programs.MergeChild(child)
+		require.True(t, programs.Cleaned())
+		require.Equal(t, []common.Location{someLocation}, programs.Locations())
+
+		_, _, has = programs.Get(parentLocation)
+		require.False(t, has)
+		_, _, has = programs.Get(addressLocation)
+		require.False(t, has)
+	})
+
+	t.Run("uses and updated contracts", func(t *testing.T) {
+		parent := NewEmptyPrograms()
+		parent.Set(addressLocation, &interpreter.Program{}, newState)
+		require.Equal(t, map[common.AddressLocation]uint64{addressLocation: 1}, parent.Uses())
+
+		programs := parent.ChildPrograms()
+		require.Empty(t, programs.Uses())
+
+		// only the programs of contracts are counted
+		child := programs.ChildPrograms()
+		_, _, has := child.Get(addressLocation)
+		require.True(t, has)
+		_, _, has = child.Get(addressLocation)
+		require.True(t, has)
+		child.Set(someLocation, someProgram, newState)
+		_, _, has = child.Get(someLocation)
+		require.True(t, has)
+		require.Equal(t, map[common.AddressLocation]uint64{addressLocation: 2}, child.Uses())
+
+		// peeking doesn't count as a use
+		_, _, has = child.Peek(addressLocation)
+		require.True(t, has)
+		require.Equal(t, map[common.AddressLocation]uint64{addressLocation: 2}, child.Uses())
+
+		programs.MergeChild(child)
+		require.Equal(t, map[common.AddressLocation]uint64{addressLocation: 2}, programs.Uses())
+		require.Empty(t, programs.UpdatedContracts())
+
+		updated := ContractUpdateKey{
+			Address: flow.BytesToAddress(addressLocation.Address.Bytes()),
+			Name:    addressLocation.Name,
+		}
+		child = programs.ChildPrograms()
+		child.Cleanup([]ContractUpdateKey{updated})
+		require.Equal(t, []ContractUpdateKey{updated}, child.UpdatedContracts())
+
+		// uses are kept when cleaning up
+		_, _, has = programs.Get(addressLocation)
+		require.True(t, has)
+		programs.MergeChild(child)
+		require.Equal(t, []ContractUpdateKey{updated}, programs.UpdatedContracts())
+		require.Equal(t, map[common.AddressLocation]uint64{addressLocation: 3}, programs.Uses())
+	})
+
+	t.Run("concurrent uses", func(t *testing.T) {
+		programs := NewEmptyPrograms()
+		programs.Set(addressLocation, &interpreter.Program{}, newState)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					_, _, has := programs.Get(addressLocation)
+					require.True(t, has)
+				}
+			}()
+		}
+		wg.Wait()
+
+		require.Equal(t, map[common.AddressLocation]uint64{addressLocation: 1 + 10*100}, programs.Uses())
+	})
 }
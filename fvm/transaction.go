@@ -37,6 +37,8 @@ type TransactionProcedure struct {
 	Err             errors.Error
 	Retried         int
 	TraceSpan       opentracing.Span
+	// Profile is the breakdown of the resources used by the transaction
+	Profile *flow.TransactionProfile
 }
 
 func (proc *TransactionProcedure) SetTraceSpan(traceSpan opentracing.Span) {
@@ -170,6 +170,8 @@ func (i *TransactionInvoker) Process(
 	// log te execution intensities here, so tha they do not contain data from storage limit checks and
 	// transaction deduction, because the payer is not charged for those.
 	i.logExecutionIntensities(sth, txIDStr)
+	// the profile is captured here for the same reason
+	proc.Profile = newTransactionProfile(proc.ID, sth.State().Meter())
 
 	// disable the limit checks on states
 	sth.DisableAllLimitEnforcements()
@@ -249,6 +251,11 @@ func (i *TransactionInvoker) Process(
 	proc.ComputationUsed = proc.ComputationUsed + computationUsed
 	proc.MemoryUsed = proc.MemoryUsed + memoryUsed
 
+	proc.Profile.ComputationUsed = proc.ComputationUsed
+	proc.Profile.MemoryUsed = proc.MemoryUsed
+	proc.Profile.RegisterBytesRead = childState.TotalBytesRead
+	proc.Profile.RegisterBytesWritten = childState.TotalBytesWritten
+
 	// based on the contract updates we decide how to clean up the programs
 	// for failed transactions we also do the same as
 	// transaction without any deployed contracts
@@ -0,0 +1,80 @@
+package fvm
+
+import (
+	"sort"
+
+	"github.com/onflow/flow-go/fvm/meter"
+	"github.com/onflow/flow-go/fvm/meter/weighted"
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// TransactionProfileKinds is the maximum number of computation and memory kinds kept in a transaction profile.
+const TransactionProfileKinds = 10
+
+// newTransactionProfile returns the profile of a transaction, with the computation and memory kinds metered by
+// the given meter which contributed the most to the usage. The totals and the register bytes are filled in
+// once the transaction is done.
+func newTransactionProfile(txID flow.Identifier, m meter.Meter) *flow.TransactionProfile {
+	var computationWeights weighted.ExecutionEffortWeights
+	var memoryWeights weighted.ExecutionMemoryWeights
+	if weightedMeter, ok := m.(*weighted.Meter); ok {
+		computationWeights = weightedMeter.ComputationWeights()
+		memoryWeights = weightedMeter.MemoryWeights()
+	}
+
+	computationKinds := make([]flow.ProfiledKind, 0, len(m.ComputationIntensities()))
+	for kind, intensity := range m.ComputationIntensities() {
+		if intensity == 0 {
+			continue
+		}
+		computationKinds = append(computationKinds, flow.ProfiledKind{
+			Kind:      uint32(kind),
+			Intensity: uint64(intensity),
+			Used:      computationWeights[kind] * uint64(intensity),
+		})
+	}
+	// the kinds are ranked with the internal precision of the meter, so that kinds with small weights are
+	// still ordered correctly
+	computationKinds = topProfiledKinds(computationKinds)
+	for i := range computationKinds {
+		computationKinds[i].Used >>= weighted.MeterExecutionInternalPrecisionBytes
+	}
+
+	memoryKinds := make([]flow.ProfiledKind, 0, len(m.MemoryIntensities()))
+	for kind, intensity := range m.MemoryIntensities() {
+		if intensity == 0 {
+			continue
+		}
+		memoryKinds = append(memoryKinds, flow.ProfiledKind{
+			Kind:      uint32(kind),
+			Intensity: uint64(intensity),
+			Used:      memoryWeights[kind] * uint64(intensity),
+		})
+	}
+	memoryKinds = topProfiledKinds(memoryKinds)
+
+	return &flow.TransactionProfile{
+		TransactionID:    txID,
+		ComputationKinds: computationKinds,
+		MemoryKinds:      memoryKinds,
+	}
+}
+
+// topProfiledKinds sorts the kinds by descending usage, then by descending intensity, and keeps the first
+// TransactionProfileKinds kinds.
+func topProfiledKinds(kinds []flow.ProfiledKind) []flow.ProfiledKind {
+	sort.Slice(kinds, func(i, j int) bool {
+		if kinds[i].Used != kinds[j].Used {
+			return kinds[i].Used > kinds[j].Used
+		}
+		if kinds[i].Intensity != kinds[j].Intensity {
+			return kinds[i].Intensity > kinds[j].Intensity
+		}
+		return kinds[i].Kind < kinds[j].Kind
+	})
+
+	if len(kinds) > TransactionProfileKinds {
+		kinds = kinds[:TransactionProfileKinds]
+	}
+	return kinds
+}
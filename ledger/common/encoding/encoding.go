@@ -21,6 +21,8 @@ const (
 	TrieUpdateVersion     = uint16(0) // Use payload version 0 encoding
 	TrieProofVersion      = uint16(0) // Use payload version 0 encoding
 	TrieBatchProofVersion = uint16(0) // Use payload version 0 encoding
+
+	CompactTrieBatchProofVersion = uint16(0) // Use payload version 0 encoding
 )
 
 // Type capture the type of encoded entity (e.g. State, Key, Value, Path)
@@ -52,12 +54,14 @@ const (
 	TypeUpdate
 	// TypeTrieUpdate - type for trie update
 	TypeTrieUpdate
+	// TypeCompactBatchProof - type for compact BatchProofs
+	TypeCompactBatchProof
 	// this is used to flag types from the future
 	typeUnsuported
 )
 
 func (e Type) String() string {
-	return [...]string{"Unknown", "State", "KeyPart", "Key", "Value", "Path", "Payload", "Proof", "BatchProof", "Query", "Update", "Trie Update"}[e]
+	return [...]string{"Unknown", "State", "KeyPart", "Key", "Value", "Path", "Payload", "Proof", "BatchProof", "Query", "Update", "Trie Update", "CompactBatchProof"}[e]
 }
 
 // CheckVersion extracts encoding bytes from a raw encoded message
@@ -871,3 +875,175 @@ func decodeTrieBatchProof(inp []byte, version uint16) (*ledger.TrieBatchProof, e
 	}
 	return bp, nil
 }
+
+// EncodeCompactTrieBatchProof encodes a compact batch proof into a byte slice
+func EncodeCompactTrieBatchProof(cp *ledger.CompactTrieBatchProof) []byte {
+	if cp == nil {
+		return []byte{}
+	}
+	// encode version
+	buffer := utils.AppendUint16([]byte{}, CompactTrieBatchProofVersion)
+
+	// encode compact batch proof entity type
+	buffer = utils.AppendUint8(buffer, TypeCompactBatchProof)
+	// encode compact batch proof content
+	buffer = append(buffer, encodeCompactTrieBatchProof(cp, CompactTrieBatchProofVersion)...)
+
+	return buffer
+}
+
+func encodeCompactTrieBatchProof(cp *ledger.CompactTrieBatchProof, version uint16) []byte {
+	buffer := make([]byte, 0)
+	// encode number of proofs
+	buffer = utils.AppendUint32(buffer, uint32(len(cp.Paths)))
+	// iterate over proofs
+	for i, path := range cp.Paths {
+		// include path size and content
+		buffer = utils.AppendUint16(buffer, uint16(ledger.PathLen))
+		buffer = append(buffer, path[:]...)
+
+		// steps are encoded as a single byte
+		buffer = utils.AppendUint8(buffer, cp.Steps[i])
+
+		// include encoded payload size and content
+		encPayload := encodePayload(cp.Payloads[i], version)
+		buffer = utils.AppendUint64(buffer, uint64(len(encPayload)))
+		buffer = append(buffer, encPayload...)
+	}
+
+	// include sibling flags count and content
+	buffer = utils.AppendUint32(buffer, cp.SiblingCount)
+	buffer = append(buffer, cp.SiblingFlags...)
+
+	// and finally include all non-default siblings
+	buffer = utils.AppendUint32(buffer, uint32(len(cp.Siblings)))
+	for _, sibling := range cp.Siblings {
+		buffer = append(buffer, sibling[:]...)
+	}
+	return buffer
+}
+
+// DecodeCompactTrieBatchProof constructs a compact batch proof from an encoded byte slice
+func DecodeCompactTrieBatchProof(encodedProof []byte) (*ledger.CompactTrieBatchProof, error) {
+	// check the enc dec version
+	rest, version, err := CheckVersion(encodedProof, CompactTrieBatchProofVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding compact batch proof: %w", err)
+	}
+	// check the encoding type
+	rest, err = CheckType(rest, TypeCompactBatchProof)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding compact batch proof: %w", err)
+	}
+
+	// decode the compact batch proof content
+	cp, err := decodeCompactTrieBatchProof(rest, version)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding compact batch proof: %w", err)
+	}
+	return cp, nil
+}
+
+func decodeCompactTrieBatchProof(inp []byte, version uint16) (*ledger.CompactTrieBatchProof, error) {
+	// number of proofs
+	numOfProofs, rest, err := utils.ReadUint32(inp)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding compact batch proof (content): %w", err)
+	}
+
+	// the number of proofs isn't trusted, so the slices are not preallocated
+	cp := &ledger.CompactTrieBatchProof{}
+
+	for i := 0; i < int(numOfProofs); i++ {
+		// read path
+		var pathSize uint16
+		pathSize, rest, err = utils.ReadUint16(rest)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding compact batch proof (content): %w", err)
+		}
+		var encPath []byte
+		encPath, rest, err = utils.ReadSlice(rest, int(pathSize))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding compact batch proof (content): %w", err)
+		}
+		path, err := ledger.ToPath(encPath)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding compact batch proof (content): %w", err)
+		}
+
+		// read steps
+		var steps uint8
+		steps, rest, err = utils.ReadUint8(rest)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding compact batch proof (content): %w", err)
+		}
+
+		// read payload
+		var encPayloadSize uint64
+		encPayloadSize, rest, err = utils.ReadUint64(rest)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding compact batch proof (content): %w", err)
+		}
+		var encPayload []byte
+		encPayload, rest, err = utils.ReadSlice(rest, int(encPayloadSize))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding compact batch proof (content): %w", err)
+		}
+		// Decode payload (zerocopy)
+		payload, err := decodePayload(encPayload, true, version)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding compact batch proof (content): %w", err)
+		}
+
+		cp.Paths = append(cp.Paths, path)
+		cp.Steps = append(cp.Steps, steps)
+		cp.Payloads = append(cp.Payloads, payload)
+	}
+
+	// read sibling flags
+	cp.SiblingCount, rest, err = utils.ReadUint32(rest)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding compact batch proof (content): %w", err)
+	}
+	cp.SiblingFlags, rest, err = utils.ReadSlice(rest, (int(cp.SiblingCount)+7)>>3)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding compact batch proof (content): %w", err)
+	}
+
+	// read siblings
+	siblingCount, rest, err := utils.ReadUint32(rest)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding compact batch proof (content): %w", err)
+	}
+	if uint64(siblingCount) > uint64(cp.SiblingCount) {
+		return nil, fmt.Errorf("error decoding compact batch proof (content): %d siblings exceed sibling count %d", siblingCount, cp.SiblingCount)
+	}
+	if uint64(siblingCount)*hash.HashLen > uint64(len(rest)) {
+		return nil, fmt.Errorf("error decoding compact batch proof (content): %d siblings exceed remaining %d bytes", siblingCount, len(rest))
+	}
+	cp.Siblings = make([]hash.Hash, siblingCount)
+	for i := range cp.Siblings {
+		var encSibling []byte
+		encSibling, rest, err = utils.ReadSlice(rest, hash.HashLen)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding compact batch proof (content): %w", err)
+		}
+		cp.Siblings[i], err = hash.ToHash(encSibling)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding compact batch proof (content): %w", err)
+		}
+	}
+	return cp, nil
+}
+
+// IsCompactTrieBatchProof returns true if the encoded data is a compact batch proof,
+// without decoding it.
+func IsCompactTrieBatchProof(encoded []byte) bool {
+	// skip the version
+	_, rest, err := utils.ReadUint16(encoded)
+	if err != nil {
+		return false
+	}
+	t, _, err := utils.ReadUint8(rest)
+	return err == nil && t == TypeCompactBatchProof
+}
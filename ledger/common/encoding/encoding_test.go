@@ -3,6 +3,7 @@ package encoding_test
 import (
 	"encoding/binary"
 	"encoding/hex"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -540,6 +541,106 @@ func TestBatchProofSerialization(t *testing.T) {
 	})
 }
 
+// TestCompactBatchProofSerialization tests encoding and decoding functionality of a compact batch proof
+func TestCompactBatchProofSerialization(t *testing.T) {
+	siblingBytes, _ := hex.DecodeString("accb0399dd2b3a7a48618b2376f5e61d822e0c7736b044c364a05c2904a2f315")
+
+	var sibling hash.Hash
+	copy(sibling[:], siblingBytes)
+
+	cp := &ledger.CompactTrieBatchProof{
+		Paths:        []ledger.Path{utils.PathByUint16(330)},
+		Payloads:     []*ledger.Payload{utils.LightPayload8('A', 'A')},
+		Steps:        []uint8{2},
+		SiblingFlags: []byte{0x80},
+		SiblingCount: 2,
+		Siblings:     []hash.Hash{sibling},
+	}
+
+	encodedV0 := []byte{
+		0x00, 0x00, // version 0
+		0x0c,                   // type
+		0x00, 0x00, 0x00, 0x01, // number of proofs
+		0x00, 0x20, // length of path
+		0x01, 0x4a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // path
+		0x02,                                           // steps
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x16, // length of encoded payload
+		0x00, 0x00, 0x00, 0x09, // length of encoded payload key
+		0x00, 0x01, // number of payload key parts
+		0x00, 0x00, 0x00, 0x03, // length of encoded payload key part 0
+		0x00, 0x00, // payload key part type
+		0x41,                                           // payload key part value
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, // length of encoded payload value
+		0x41,                   // payload value
+		0x00, 0x00, 0x00, 0x02, // number of sibling flags
+		0x80,                   // sibling flags
+		0x00, 0x00, 0x00, 0x01, // number of siblings
+		0xac, 0xcb, 0x03, 0x99, 0xdd, 0x2b, 0x3a, 0x7a,
+		0x48, 0x61, 0x8b, 0x23, 0x76, 0xf5, 0xe6, 0x1d,
+		0x82, 0x2e, 0x0c, 0x77, 0x36, 0xb0, 0x44, 0xc3,
+		0x64, 0xa0, 0x5c, 0x29, 0x04, 0xa2, 0xf3, 0x15, // sibling
+	}
+
+	t.Run("encoding", func(t *testing.T) {
+		encoded := encoding.EncodeCompactTrieBatchProof(cp)
+		require.Equal(t, encodedV0, encoded)
+		require.True(t, encoding.IsCompactTrieBatchProof(encoded))
+	})
+
+	t.Run("decoding", func(t *testing.T) {
+		decoded, err := encoding.DecodeCompactTrieBatchProof(encodedV0)
+		require.NoError(t, err)
+		require.True(t, decoded.Equals(cp))
+	})
+
+	t.Run("batch proof", func(t *testing.T) {
+		bp, _ := utils.TrieBatchProofFixture()
+		encoded := encoding.EncodeTrieBatchProof(bp)
+		require.False(t, encoding.IsCompactTrieBatchProof(encoded))
+
+		_, err := encoding.DecodeCompactTrieBatchProof(encoded)
+		require.Error(t, err)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		_, err := encoding.DecodeCompactTrieBatchProof(encodedV0[:len(encodedV0)-1])
+		require.Error(t, err)
+	})
+
+	t.Run("large number of proofs", func(t *testing.T) {
+		encoded := []byte{
+			0x00, 0x00, // version 0
+			0x0c,                   // type
+			0xff, 0xff, 0xff, 0xff, // number of proofs
+		}
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, err := encoding.DecodeCompactTrieBatchProof(encoded)
+		runtime.ReadMemStats(&after)
+		require.Error(t, err)
+
+		// memory isn't allocated for the proofs before they are read
+		require.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+	})
+
+	t.Run("large number of siblings", func(t *testing.T) {
+		encoded := make([]byte, len(encodedV0)-hash.HashLen)
+		copy(encoded, encodedV0)
+		siblingCount := len(encoded) - 4
+		sizeOfFlags := siblingCount - 1 - 4
+		binary.BigEndian.PutUint32(encoded[sizeOfFlags:], 8)
+		binary.BigEndian.PutUint32(encoded[siblingCount:], 8)
+
+		// siblings are rejected before memory is allocated for them
+		_, err := encoding.DecodeCompactTrieBatchProof(encoded)
+		require.ErrorContains(t, err, "8 siblings exceed remaining 0 bytes")
+	})
+}
+
 // TestTrieUpdateSerialization tests encoding and decoding functionality of a trie update
 func TestTrieUpdateSerialization(t *testing.T) {
 
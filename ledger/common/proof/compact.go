@@ -0,0 +1,368 @@
+package proof
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/bitutils"
+	"github.com/onflow/flow-go/ledger/common/hash"
+)
+
+// NewCompactTrieBatchProof converts a batch of inclusion proofs into a compact batch proof,
+// which stores each sibling hash shared by several proofs only once.
+// Proofs for the same path are allowed, as long as they are identical.
+// An error is returned for non-inclusion proofs and for proofs which can't be
+// proofs of registers in the same trie.
+func NewCompactTrieBatchProof(bp *ledger.TrieBatchProof) (*ledger.CompactTrieBatchProof, error) {
+	cp := &ledger.CompactTrieBatchProof{
+		Paths:    make([]ledger.Path, len(bp.Proofs)),
+		Payloads: make([]*ledger.Payload, len(bp.Proofs)),
+		Steps:    make([]uint8, len(bp.Proofs)),
+	}
+
+	// proofs of unique paths
+	unique := make(map[ledger.Path]*ledger.TrieProof, len(bp.Proofs))
+	for i, p := range bp.Proofs {
+		if !p.Inclusion {
+			return nil, fmt.Errorf("proof %d for path %x is not an inclusion proof", i, p.Path)
+		}
+		if other, ok := unique[p.Path]; ok {
+			if !other.Equals(p) {
+				return nil, fmt.Errorf("conflicting proofs for path %x", p.Path)
+			}
+		} else {
+			unique[p.Path] = p
+		}
+		cp.Paths[i] = p.Path
+		cp.Payloads[i] = p.Payload
+		cp.Steps[i] = p.Steps
+	}
+
+	leaves := make([]*compactLeaf, 0, len(unique))
+	for path, p := range unique {
+		siblings, err := expandSiblings(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proof for path %x: %w", path, err)
+		}
+		leaves = append(leaves, &compactLeaf{path: p.Path, steps: int(p.Steps), siblings: siblings})
+	}
+	sortCompactLeaves(leaves)
+
+	flags := make([]byte, 0)
+	if len(leaves) > 0 {
+		err := compactSiblings(leaves, 0, func(isDefault bool, sibling hash.Hash) {
+			if cp.SiblingCount%8 == 0 {
+				flags = append(flags, 0)
+			}
+			if !isDefault {
+				bitutils.SetBit(flags, int(cp.SiblingCount))
+				cp.Siblings = append(cp.Siblings, sibling)
+			}
+			cp.SiblingCount++
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	cp.SiblingFlags = flags
+
+	return cp, nil
+}
+
+// ExpandCompactTrieBatchProof converts a compact batch proof back into a batch of inclusion proofs,
+// in the order the proofs were added to the compact batch proof.
+// The siblings omitted from the compact batch proof are recomputed from the payloads of the registers.
+func ExpandCompactTrieBatchProof(cp *ledger.CompactTrieBatchProof) (*ledger.TrieBatchProof, error) {
+	leaves, err := uniqueCompactLeaves(cp)
+	if err != nil {
+		return nil, err
+	}
+
+	byPath := make(map[ledger.Path]*compactLeaf, len(leaves))
+	for _, leaf := range leaves {
+		leaf.siblings = make([]compactSibling, leaf.steps)
+		byPath[leaf.path] = leaf
+	}
+
+	if len(leaves) > 0 {
+		reader := newSiblingReader(cp)
+		_, err = computeCompactRoot(leaves, 0, reader, true)
+		if err != nil {
+			return nil, err
+		}
+		err = reader.checkConsumed()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	bp := ledger.NewTrieBatchProofWithEmptyProofs(cp.Size())
+	for i, p := range bp.Proofs {
+		leaf := byPath[cp.Paths[i]]
+		p.Path = cp.Paths[i]
+		p.Payload = cp.Payloads[i]
+		p.Inclusion = true
+		p.Steps = cp.Steps[i]
+		for depth, sibling := range leaf.siblings {
+			if !sibling.isDefault {
+				bitutils.SetBit(p.Flags, depth)
+				p.Interims = append(p.Interims, sibling.hash)
+			}
+		}
+	}
+	return bp, nil
+}
+
+// VerifyCompactTrieBatchProof verifies all proofs inside the compact batch proof,
+// by computing the root hash from the registers and the siblings included in the proof.
+// It is equivalent to VerifyTrieBatchProof on the expanded batch proof, but computes the
+// hash of each node only once.
+func VerifyCompactTrieBatchProof(cp *ledger.CompactTrieBatchProof, expectedState ledger.State) bool {
+	leaves, err := uniqueCompactLeaves(cp)
+	if err != nil {
+		return false
+	}
+	if len(leaves) == 0 {
+		return true
+	}
+
+	reader := newSiblingReader(cp)
+	computed, err := computeCompactRoot(leaves, 0, reader, false)
+	if err != nil {
+		return false
+	}
+	if reader.checkConsumed() != nil {
+		return false
+	}
+	return computed == hash.Hash(expectedState)
+}
+
+// compactLeaf is a register in a compact batch proof
+type compactLeaf struct {
+	path    ledger.Path
+	payload *ledger.Payload
+	steps   int
+	// siblings holds the sibling of the node on the register's path at each depth,
+	// i.e. siblings[d] is the sibling of the node at depth d+1.
+	siblings []compactSibling
+}
+
+type compactSibling struct {
+	isDefault bool
+	hash      hash.Hash
+}
+
+// sortCompactLeaves sorts leaves by path
+func sortCompactLeaves(leaves []*compactLeaf) {
+	sort.Slice(leaves, func(i, j int) bool {
+		return bytes.Compare(leaves[i].path[:], leaves[j].path[:]) < 0
+	})
+}
+
+// expandSiblings returns the sibling at each depth of the path of an inclusion proof.
+func expandSiblings(p *ledger.TrieProof) ([]compactSibling, error) {
+	if 8*len(p.Flags) < int(p.Steps) {
+		return nil, fmt.Errorf("too few flags (%d bytes) for %d steps", len(p.Flags), p.Steps)
+	}
+	siblings := make([]compactSibling, p.Steps)
+	interimIndex := 0
+	for depth := range siblings {
+		if bitutils.ReadBit(p.Flags, depth) == 0 {
+			siblings[depth] = compactSibling{isDefault: true, hash: ledger.GetDefaultHashForHeight(ledger.NodeMaxHeight - depth - 1)}
+			continue
+		}
+		if interimIndex >= len(p.Interims) {
+			return nil, fmt.Errorf("too few interims (%d)", len(p.Interims))
+		}
+		siblings[depth] = compactSibling{hash: p.Interims[interimIndex]}
+		interimIndex++
+	}
+	if interimIndex != len(p.Interims) {
+		return nil, fmt.Errorf("too many interims (%d), expected %d", len(p.Interims), interimIndex)
+	}
+	return siblings, nil
+}
+
+// splitCompactLeaves returns the index of the first leaf whose path has bit 1 at the given depth.
+// Leaves must be sorted by path and share the first `depth` bits of their paths.
+func splitCompactLeaves(leaves []*compactLeaf, depth int) int {
+	return sort.Search(len(leaves), func(i int) bool {
+		return bitutils.ReadBit(leaves[i].path[:], depth) == 1
+	})
+}
+
+// checkCompactLeaves checks that the leaves can be in the same subtrie at the given depth:
+// either there is a single leaf, or all leaves are below that depth.
+func checkCompactLeaves(leaves []*compactLeaf, depth int) error {
+	if len(leaves) == 1 {
+		if leaves[0].steps < depth {
+			return fmt.Errorf("path %x has %d steps, but is at depth %d", leaves[0].path, leaves[0].steps, depth)
+		}
+		return nil
+	}
+	for _, leaf := range leaves {
+		if leaf.steps <= depth {
+			return fmt.Errorf("path %x has %d steps, but shares a subtrie at depth %d with other paths", leaf.path, leaf.steps, depth)
+		}
+	}
+	return nil
+}
+
+// compactSiblings calls `add` for each sibling of the subtrie at the given depth containing
+// the leaves which can't be computed from the leaves, in depth-first order (left child first).
+func compactSiblings(leaves []*compactLeaf, depth int, add func(isDefault bool, sibling hash.Hash)) error {
+	err := checkCompactLeaves(leaves, depth)
+	if err != nil {
+		return err
+	}
+	if len(leaves) == 1 && leaves[0].steps == depth {
+		return nil
+	}
+
+	split := splitCompactLeaves(leaves, depth)
+	left, right := leaves[:split], leaves[split:]
+	if len(left) > 0 && len(right) > 0 {
+		err = compactSiblings(left, depth+1, add)
+		if err != nil {
+			return err
+		}
+		return compactSiblings(right, depth+1, add)
+	}
+
+	// all leaves are on the same side, the other side is a sibling shared by all leaves
+	sibling := leaves[0].siblings[depth]
+	for _, leaf := range leaves[1:] {
+		if leaf.siblings[depth] != sibling {
+			return fmt.Errorf("paths %x and %x have different siblings at depth %d", leaves[0].path, leaf.path, depth)
+		}
+	}
+	add(sibling.isDefault, sibling.hash)
+	return compactSiblings(leaves, depth+1, add)
+}
+
+// computeCompactRoot computes the hash of the subtrie at the given depth containing the leaves,
+// reading the siblings which can't be computed from the leaves from `reader`.
+// If recordSiblings is true, the sibling at each depth is recorded in the leaves.
+func computeCompactRoot(leaves []*compactLeaf, depth int, reader *siblingReader, recordSiblings bool) (hash.Hash, error) {
+	err := checkCompactLeaves(leaves, depth)
+	if err != nil {
+		return hash.DummyHash, err
+	}
+	height := ledger.NodeMaxHeight - depth
+	if len(leaves) == 1 && leaves[0].steps == depth {
+		return ledger.ComputeCompactValue(hash.Hash(leaves[0].path), leaves[0].payload.Value, height), nil
+	}
+
+	split := splitCompactLeaves(leaves, depth)
+	left, right := leaves[:split], leaves[split:]
+
+	var leftHash, rightHash hash.Hash
+	if len(left) > 0 && len(right) > 0 {
+		leftHash, err = computeCompactRoot(left, depth+1, reader, recordSiblings)
+		if err != nil {
+			return hash.DummyHash, err
+		}
+		rightHash, err = computeCompactRoot(right, depth+1, reader, recordSiblings)
+		if err != nil {
+			return hash.DummyHash, err
+		}
+		if recordSiblings {
+			// subtries with only empty registers have a default hash,
+			// which is not included in proofs
+			defaultHash := ledger.GetDefaultHashForHeight(height - 1)
+			recordSibling(left, depth, compactSibling{isDefault: rightHash == defaultHash, hash: rightHash})
+			recordSibling(right, depth, compactSibling{isDefault: leftHash == defaultHash, hash: leftHash})
+		}
+		return hash.HashInterNode(leftHash, rightHash), nil
+	}
+
+	// all leaves are on the same side, the sibling on the other side is included in the proof
+	sibling, err := reader.next(height - 1)
+	if err != nil {
+		return hash.DummyHash, err
+	}
+	computed, err := computeCompactRoot(leaves, depth+1, reader, recordSiblings)
+	if err != nil {
+		return hash.DummyHash, err
+	}
+	if recordSiblings {
+		recordSibling(leaves, depth, sibling)
+	}
+	if len(left) > 0 {
+		return hash.HashInterNode(computed, sibling.hash), nil
+	}
+	return hash.HashInterNode(sibling.hash, computed), nil
+}
+
+func recordSibling(leaves []*compactLeaf, depth int, sibling compactSibling) {
+	for _, leaf := range leaves {
+		leaf.siblings[depth] = sibling
+	}
+}
+
+// uniqueCompactLeaves returns the registers of a compact batch proof with unique paths, sorted by path.
+func uniqueCompactLeaves(cp *ledger.CompactTrieBatchProof) ([]*compactLeaf, error) {
+	if len(cp.Payloads) != len(cp.Paths) || len(cp.Steps) != len(cp.Paths) {
+		return nil, fmt.Errorf("inconsistent number of paths (%d), payloads (%d) and steps (%d)",
+			len(cp.Paths), len(cp.Payloads), len(cp.Steps))
+	}
+
+	unique := make(map[ledger.Path]*compactLeaf, len(cp.Paths))
+	leaves := make([]*compactLeaf, 0, len(cp.Paths))
+	for i, path := range cp.Paths {
+		if cp.Payloads[i] == nil {
+			return nil, fmt.Errorf("missing payload for path %x", path)
+		}
+		if leaf, ok := unique[path]; ok {
+			if leaf.steps != int(cp.Steps[i]) || !leaf.payload.Equals(cp.Payloads[i]) {
+				return nil, fmt.Errorf("conflicting proofs for path %x", path)
+			}
+			continue
+		}
+		leaf := &compactLeaf{path: path, payload: cp.Payloads[i], steps: int(cp.Steps[i])}
+		unique[path] = leaf
+		leaves = append(leaves, leaf)
+	}
+	sortCompactLeaves(leaves)
+	return leaves, nil
+}
+
+// siblingReader reads the siblings of a compact batch proof in order.
+type siblingReader struct {
+	proof        *ledger.CompactTrieBatchProof
+	flagIndex    uint32
+	siblingIndex int
+}
+
+func newSiblingReader(cp *ledger.CompactTrieBatchProof) *siblingReader {
+	return &siblingReader{proof: cp}
+}
+
+// next returns the next sibling, which is a node at the given height.
+func (r *siblingReader) next(height int) (compactSibling, error) {
+	if r.flagIndex >= r.proof.SiblingCount || int(r.flagIndex) >= 8*len(r.proof.SiblingFlags) {
+		return compactSibling{}, fmt.Errorf("too few siblings in proof (%d)", r.proof.SiblingCount)
+	}
+	flag := bitutils.ReadBit(r.proof.SiblingFlags, int(r.flagIndex))
+	r.flagIndex++
+	if flag == 0 {
+		return compactSibling{isDefault: true, hash: ledger.GetDefaultHashForHeight(height)}, nil
+	}
+	if r.siblingIndex >= len(r.proof.Siblings) {
+		return compactSibling{}, fmt.Errorf("too few sibling hashes in proof (%d)", len(r.proof.Siblings))
+	}
+	sibling := r.proof.Siblings[r.siblingIndex]
+	r.siblingIndex++
+	return compactSibling{hash: sibling}, nil
+}
+
+// checkConsumed returns an error if not all siblings of the proof were read.
+func (r *siblingReader) checkConsumed() error {
+	if r.flagIndex != r.proof.SiblingCount || r.siblingIndex != len(r.proof.Siblings) {
+		return fmt.Errorf("unused siblings in proof: read %d of %d flags and %d of %d hashes",
+			r.flagIndex, r.proof.SiblingCount, r.siblingIndex, len(r.proof.Siblings))
+	}
+	return nil
+}
@@ -5,8 +5,13 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/encoding"
+	"github.com/onflow/flow-go/ledger/common/hash"
 	"github.com/onflow/flow-go/ledger/common/proof"
 	"github.com/onflow/flow-go/ledger/common/utils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/module/metrics"
 )
 
 // Test_ProofVerify tests proof verification
@@ -20,3 +25,93 @@ func Test_TrieBatchProofVerify(t *testing.T) {
 	bp, sc := utils.TrieBatchProofFixture()
 	require.True(t, proof.VerifyTrieBatchProof(bp, sc))
 }
+
+// Test_CompactTrieBatchProof tests converting batch proofs to compact batch proofs and back
+func Test_CompactTrieBatchProof(t *testing.T) {
+	forest, err := mtrie.NewForest(10, &metrics.NoopCollector{}, nil)
+	require.NoError(t, err)
+
+	paths := utils.RandomPaths(200)
+	payloads := utils.RandomPayloads(len(paths), 2, 32)
+	rootHash, err := forest.Update(&ledger.TrieUpdate{RootHash: forest.GetEmptyRootHash(), Paths: paths, Payloads: payloads})
+	require.NoError(t, err)
+
+	// query existing registers, unknown registers and some registers twice
+	queried := append([]ledger.Path{}, paths[:50]...)
+	queried = append(queried, utils.RandomPaths(10)...)
+	queried = append(queried, paths[10], paths[20])
+
+	bp, err := forest.Proofs(&ledger.TrieRead{RootHash: rootHash, Paths: queried})
+	require.NoError(t, err)
+	require.True(t, proof.VerifyTrieBatchProof(bp, ledger.State(rootHash)))
+
+	cp, err := proof.NewCompactTrieBatchProof(bp)
+	require.NoError(t, err)
+	require.Equal(t, len(queried), cp.Size())
+	require.True(t, proof.VerifyCompactTrieBatchProof(cp, ledger.State(rootHash)))
+
+	t.Run("roundtrip", func(t *testing.T) {
+		expanded, err := proof.ExpandCompactTrieBatchProof(cp)
+		require.NoError(t, err)
+		require.True(t, expanded.Equals(bp))
+	})
+
+	t.Run("smaller encoding", func(t *testing.T) {
+		interims := 0
+		for _, p := range bp.Proofs {
+			interims += len(p.Interims)
+		}
+		require.Less(t, len(cp.Siblings), interims)
+		require.Less(t, len(encoding.EncodeCompactTrieBatchProof(cp)), len(encoding.EncodeTrieBatchProof(bp)))
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		empty, err := proof.NewCompactTrieBatchProof(ledger.NewTrieBatchProof())
+		require.NoError(t, err)
+		require.True(t, proof.VerifyCompactTrieBatchProof(empty, ledger.State(rootHash)))
+
+		expanded, err := proof.ExpandCompactTrieBatchProof(empty)
+		require.NoError(t, err)
+		require.Equal(t, 0, expanded.Size())
+	})
+
+	t.Run("wrong state", func(t *testing.T) {
+		require.False(t, proof.VerifyCompactTrieBatchProof(cp, ledger.State(forest.GetEmptyRootHash())))
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		tampered := *cp
+		tampered.Payloads = append([]*ledger.Payload{}, cp.Payloads...)
+		tampered.Payloads[0] = utils.RandomPayloads(1, 2, 32)[0]
+		require.False(t, proof.VerifyCompactTrieBatchProof(&tampered, ledger.State(rootHash)))
+	})
+
+	t.Run("tampered sibling", func(t *testing.T) {
+		tampered := *cp
+		tampered.Siblings = append([]hash.Hash{}, cp.Siblings...)
+		tampered.Siblings[0][0] ^= 1
+		require.False(t, proof.VerifyCompactTrieBatchProof(&tampered, ledger.State(rootHash)))
+	})
+
+	t.Run("missing sibling", func(t *testing.T) {
+		tampered := *cp
+		tampered.Siblings = cp.Siblings[:len(cp.Siblings)-1]
+		require.False(t, proof.VerifyCompactTrieBatchProof(&tampered, ledger.State(rootHash)))
+		_, err := proof.ExpandCompactTrieBatchProof(&tampered)
+		require.Error(t, err)
+	})
+
+	t.Run("conflicting duplicate", func(t *testing.T) {
+		tampered := *cp
+		tampered.Steps = append([]uint8{}, cp.Steps...)
+		tampered.Steps[len(queried)-1]++
+		require.False(t, proof.VerifyCompactTrieBatchProof(&tampered, ledger.State(rootHash)))
+	})
+
+	t.Run("non-inclusion proof", func(t *testing.T) {
+		p, _ := utils.TrieProofFixture()
+		p.Inclusion = false
+		_, err := proof.NewCompactTrieBatchProof(&ledger.TrieBatchProof{Proofs: []*ledger.TrieProof{p}})
+		require.Error(t, err)
+	})
+}
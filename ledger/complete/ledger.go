@@ -13,7 +13,9 @@ import (
 	"github.com/onflow/flow-go/ledger/common/encoding"
 	"github.com/onflow/flow-go/ledger/common/hash"
 	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/common/proof"
 	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	"github.com/onflow/flow-go/ledger/complete/wal"
 	"github.com/onflow/flow-go/module"
@@ -42,21 +44,37 @@ type Ledger struct {
 }
 
 // NewLedger creates a new in-memory trie-backed ledger storage with persistence.
+// Optional features of the forest, like the owner index, are enabled with forestOptions.
 func NewLedger(
 	wal wal.LedgerWAL,
 	capacity int,
 	metrics module.LedgerMetrics,
 	log zerolog.Logger,
-	pathFinderVer uint8) (*Ledger, error) {
+	pathFinderVer uint8,
+	forestOptions ...mtrie.ForestOption) (*Ledger, error) {
+	return NewLedgerWithPayloadStorage(wal, capacity, metrics, log, pathFinderVer, nil, forestOptions...)
+}
+
+// NewLedgerWithPayloadStorage creates a new trie-backed ledger storage with persistence, which keeps the
+// trie structure and node hashes in memory and stores the leaf payloads in the given payload storage.
+// If payloadStorage is nil, all payloads are held in memory, like for a ledger created with NewLedger.
+func NewLedgerWithPayloadStorage(
+	wal wal.LedgerWAL,
+	capacity int,
+	metrics module.LedgerMetrics,
+	log zerolog.Logger,
+	pathFinderVer uint8,
+	payloadStorage node.PayloadStorage,
+	forestOptions ...mtrie.ForestOption) (*Ledger, error) {
 
 	logger := log.With().Str("ledger", "complete").Logger()
 
-	forest, err := mtrie.NewForest(capacity, metrics, func(evictedTrie *trie.MTrie) {
+	forest, err := mtrie.NewForestWithPayloadStorage(capacity, metrics, func(evictedTrie *trie.MTrie) {
 		err := wal.RecordDelete(evictedTrie.RootHash())
 		if err != nil {
 			logger.Error().Err(err).Msg("failed to save delete record in wal")
 		}
-	})
+	}, payloadStorage, forestOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create forest: %w", err)
 	}
@@ -107,6 +125,11 @@ func (l *Ledger) InitialState() ledger.State {
 	return ledger.State(l.forest.GetEmptyRootHash())
 }
 
+// HasState returns true if the given state is held in memory by the forest.
+func (l *Ledger) HasState(state ledger.State) bool {
+	return l.forest.HasTrie(ledger.RootHash(state))
+}
+
 // ValueSizes read the values of the given keys at the given state.
 // It returns value sizes in the same order as given registerIDs and errors (if any)
 func (l *Ledger) ValueSizes(query *ledger.Query) (valueSizes []int, err error) {
@@ -243,6 +266,63 @@ func (l *Ledger) Prove(query *ledger.Query) (proof ledger.Proof, err error) {
 	return proofToGo, err
 }
 
+// ProveCompact provides proofs for a ledger query like Prove, but encodes them as a compact batch proof,
+// which stores sibling hashes shared by several proofs only once.
+func (l *Ledger) ProveCompact(query *ledger.Query) (ledger.Proof, error) {
+
+	paths, err := pathfinder.KeysToPaths(query.Keys(), l.pathFinderVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	trieRead := &ledger.TrieRead{RootHash: ledger.RootHash(query.State()), Paths: paths}
+	batchProof, err := l.forest.Proofs(trieRead)
+	if err != nil {
+		return nil, fmt.Errorf("could not get proofs: %w", err)
+	}
+
+	compactProof, err := proof.NewCompactTrieBatchProof(batchProof)
+	if err != nil {
+		return nil, fmt.Errorf("could not compact proofs: %w", err)
+	}
+
+	proofToGo := encoding.EncodeCompactTrieBatchProof(compactProof)
+
+	if len(paths) > 0 {
+		l.metrics.ProofSize(uint32(len(proofToGo) / len(paths)))
+	}
+
+	return proofToGo, nil
+}
+
+// OwnerPayloads returns the non-empty payloads of all registers of the given owner (e.g. the address
+// of an account) at the given state, ordered by path. The state must be retained by the ledger,
+// and the ledger must be created with the mtrie.WithOwnerIndex option.
+func (l *Ledger) OwnerPayloads(state ledger.State, owner []byte) ([]*ledger.Payload, error) {
+	start := time.Now()
+
+	payloads, err := l.forest.OwnerPayloads(ledger.RootHash(state), owner)
+	if err != nil {
+		return nil, fmt.Errorf("could not read payloads of owner %x: %w", owner, err)
+	}
+
+	l.metrics.ReadValuesNumber(uint64(len(payloads)))
+	l.metrics.ReadDuration(time.Since(start))
+
+	return payloads, nil
+}
+
+// Owners returns the owners of the registers of the retained states (e.g. the addresses of accounts).
+// Owners without registers at a given state may be included. The ledger must be created with the
+// mtrie.WithOwnerIndex option.
+func (l *Ledger) Owners() ([][]byte, error) {
+	owners, err := l.forest.Owners()
+	if err != nil {
+		return nil, fmt.Errorf("could not read owners: %w", err)
+	}
+	return owners, nil
+}
+
 // MemSize return the amount of memory used by ledger
 // TODO implement an approximate MemSize method
 func (l *Ledger) MemSize() (int64, error) {
@@ -306,7 +386,10 @@ func (l *Ledger) ExportCheckpointAt(
 	// l.logger.Info().Msg("Trie is valid.")
 
 	// get all payloads
-	payloads := t.AllPayloads()
+	payloads, err := t.AllPayloads()
+	if err != nil {
+		return ledger.State(hash.DummyHash), fmt.Errorf("cannot get payloads: %w", err)
+	}
 	payloadSize := len(payloads)
 
 	// migrate payloads
@@ -356,10 +439,20 @@ func (l *Ledger) ExportCheckpointAt(
 
 	l.logger.Info().Msgf("successfully built new trie. NEW ROOT STATECOMMIEMENT: %v", statecommitment.String())
 
+	// reporters reporting on each owner separately read the new trie through the owner index
+	if l.forest.HasOwnerIndex() {
+		l.logger.Info().Msg("indexing the owners of the new trie")
+
+		err = l.forest.AddTrie(newTrie)
+		if err != nil {
+			return ledger.State(hash.DummyHash), fmt.Errorf("failed to index the new trie: %w", err)
+		}
+	}
+
 	// If defined, run extraction report BEFORE writing checkpoint file
 	// This is used to optmize the spork process
 	if extractionReport, ok := reporters[extractionReportName]; ok {
-		err := runReport(extractionReport, payloads, l.logger)
+		err := l.runReport(extractionReport, payloads, statecommitment)
 		if err != nil {
 			return ledger.State(hash.DummyHash), err
 		}
@@ -394,7 +487,7 @@ func (l *Ledger) ExportCheckpointAt(
 
 	// run reporters
 	for _, reporter := range reporters {
-		err := runReport(reporter, payloads, l.logger)
+		err := l.runReport(reporter, payloads, statecommitment)
 		if err != nil {
 			return ledger.State(hash.DummyHash), err
 		}
@@ -442,16 +535,23 @@ func (l *Ledger) keepOnlyOneTrie(state ledger.State) error {
 	return nil
 }
 
-func runReport(r ledger.Reporter, p []ledger.Payload, l zerolog.Logger) error {
-	l.Info().
+// runReport runs the reporter on the payloads of the given state. Owner reporters are served by the owner index,
+// if the ledger has one.
+func (l *Ledger) runReport(r ledger.Reporter, p []ledger.Payload, state ledger.State) error {
+	l.logger.Info().
 		Str("name", r.Name()).
 		Msg("starting reporter")
 
 	start := time.Now()
-	err := r.Report(p)
+	var err error
+	if ownerReporter, ok := r.(ledger.OwnerReporter); ok && l.forest.HasOwnerIndex() {
+		err = l.reportOwners(ownerReporter, state)
+	} else {
+		err = r.Report(p)
+	}
 	elapsed := time.Since(start)
 
-	l.Info().
+	l.logger.Info().
 		Str("timeTaken", elapsed.String()).
 		Str("name", r.Name()).
 		Msg("reporter done")
@@ -461,6 +561,26 @@ func runReport(r ledger.Reporter, p []ledger.Payload, l zerolog.Logger) error {
 	return nil
 }
 
+// reportOwners runs the owner reporter on the payloads of each owner of the owner index at the given state.
+func (l *Ledger) reportOwners(r ledger.OwnerReporter, state ledger.State) error {
+	owners, err := l.Owners()
+	if err != nil {
+		return err
+	}
+
+	return r.ReportOwners(owners, func(owner []byte) ([]ledger.Payload, error) {
+		payloads, err := l.OwnerPayloads(state, owner)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]ledger.Payload, len(payloads))
+		for i, payload := range payloads {
+			result[i] = *payload
+		}
+		return result, nil
+	})
+}
+
 func writeStatusFile(fileName string, e error) error {
 	checkpointStatus := map[string]bool{"succeeded": e == nil}
 	checkpointStatusJson, _ := json.MarshalIndent(checkpointStatus, "", " ")
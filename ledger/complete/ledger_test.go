@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dgraph-io/badger/v2"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,6 +21,8 @@ import (
 	"github.com/onflow/flow-go/ledger/common/proof"
 	"github.com/onflow/flow-go/ledger/common/utils"
 	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/ownerindex"
 	"github.com/onflow/flow-go/ledger/complete/wal"
 	"github.com/onflow/flow-go/ledger/complete/wal/fixtures"
 	"github.com/onflow/flow-go/ledger/partial/ptrie"
@@ -317,6 +320,78 @@ func TestLedger_Proof(t *testing.T) {
 	})
 }
 
+func TestLedger_OwnerPayloads(t *testing.T) {
+	metricsCollector := &metrics.NoopCollector{}
+
+	registerKey := func(owner string, key string) ledger.Key {
+		return ledger.NewKey([]ledger.KeyPart{
+			ledger.NewKeyPart(0, []byte(owner)),
+			ledger.NewKeyPart(1, []byte(owner)),
+			ledger.NewKeyPart(2, []byte(key)),
+		})
+	}
+
+	unittest.RunWithTempDir(t, func(dir string) {
+		unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+			index := ownerindex.New(db)
+
+			diskWal, err := wal.NewDiskWAL(zerolog.Nop(), nil, metricsCollector, dir, 100, pathfinder.PathByteSize, wal.SegmentSize)
+			require.NoError(t, err)
+
+			led, err := complete.NewLedger(diskWal, 100, metricsCollector, zerolog.Nop(), complete.DefaultPathFinderVersion, mtrie.WithOwnerIndex(index))
+			require.NoError(t, err)
+
+			keys := []ledger.Key{registerKey("a", "1"), registerKey("b", "1"), registerKey("a", "2")}
+			values := []ledger.Value{[]byte{1}, []byte{2}, []byte{3}}
+			update, err := ledger.NewUpdate(led.InitialState(), keys, values)
+			require.NoError(t, err)
+			state1, _, err := led.Set(update)
+			require.NoError(t, err)
+
+			// remove a register of owner a, and create a new one
+			update, err = ledger.NewUpdate(state1, []ledger.Key{keys[0], registerKey("a", "3")}, []ledger.Value{nil, []byte{4}})
+			require.NoError(t, err)
+			state2, _, err := led.Set(update)
+			require.NoError(t, err)
+
+			<-diskWal.Done()
+			<-led.Done()
+
+			// the index is kept across restarts
+			diskWal2, err := wal.NewDiskWAL(zerolog.Nop(), nil, metricsCollector, dir, 100, pathfinder.PathByteSize, wal.SegmentSize)
+			require.NoError(t, err)
+			led2, err := complete.NewLedger(diskWal2, 100, metricsCollector, zerolog.Nop(), complete.DefaultPathFinderVersion, mtrie.WithOwnerIndex(index))
+			require.NoError(t, err)
+
+			valuesOf := func(payloads []*ledger.Payload) map[string]ledger.Value {
+				values := make(map[string]ledger.Value, len(payloads))
+				for _, p := range payloads {
+					require.Equal(t, "a", string(p.Key.KeyParts[0].Value))
+					values[string(p.Key.KeyParts[2].Value)] = p.Value
+				}
+				return values
+			}
+
+			for _, l := range []*complete.Ledger{led, led2} {
+				payloads, err := l.OwnerPayloads(state1, []byte("a"))
+				require.NoError(t, err)
+				require.Equal(t, map[string]ledger.Value{"1": {1}, "2": {3}}, valuesOf(payloads))
+
+				payloads, err = l.OwnerPayloads(state2, []byte("a"))
+				require.NoError(t, err)
+				require.Equal(t, map[string]ledger.Value{"2": {3}, "3": {4}}, valuesOf(payloads))
+
+				owners, err := l.Owners()
+				require.NoError(t, err)
+				require.Equal(t, [][]byte{[]byte("a"), []byte("b")}, owners)
+			}
+
+			<-diskWal2.Done()
+			<-led2.Done()
+		})
+	})
+}
+
 func Test_WAL(t *testing.T) {
 	numInsPerStep := 2
 	keyNumberOfParts := 10
@@ -513,6 +588,9 @@ func TestLedgerFunctionality(t *testing.T) {
 }
 
 func Test_ExportCheckpointAt(t *testing.T) {
+	// the checkpoint status file is written to the working directory
+	unittest.ChdirTempDir(t)
+
 	t.Run("noop migration", func(t *testing.T) {
 		// the exported state has two key/value pairs
 		// (/1/1/22/2, "A") and (/1/3/22/4, "B")
@@ -642,6 +720,70 @@ func Test_ExportCheckpointAt(t *testing.T) {
 			})
 		})
 	})
+	t.Run("owner reporter with owner index", func(t *testing.T) {
+		// the owner reporter reads the migrated registers through the owner index,
+		// the registers of the fixture have no owner key part
+		unittest.RunWithTempDir(t, func(dbDir string) {
+			unittest.RunWithTempDir(t, func(dir2 string) {
+				unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+					diskWal, err := wal.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dbDir, 100, pathfinder.PathByteSize, wal.SegmentSize)
+					require.NoError(t, err)
+					led, err := complete.NewLedger(diskWal, 100, &metrics.NoopCollector{}, zerolog.Logger{}, complete.DefaultPathFinderVersion,
+						mtrie.WithOwnerIndex(ownerindex.New(db)))
+					require.NoError(t, err)
+
+					state := led.InitialState()
+					u := utils.UpdateFixture()
+					u.SetState(state)
+
+					state, _, err = led.Set(u)
+					require.NoError(t, err)
+
+					reporter := &ownerReporter{payloads: make(map[string][]ledger.Payload)}
+					_, err = led.ExportCheckpointAt(state, []ledger.Migration{migrationByValue}, map[string]ledger.Reporter{"owners": reporter}, "fakeExtractionReport", complete.DefaultPathFinderVersion, dir2, "root.checkpoint")
+					require.NoError(t, err)
+
+					require.False(t, reporter.reported)
+					require.Len(t, reporter.payloads, 1)
+					values := make([]ledger.Value, 0)
+					for _, payload := range reporter.payloads[""] {
+						values = append(values, payload.Value)
+					}
+					require.ElementsMatch(t, []ledger.Value{{'C'}, {'B'}}, values)
+
+					<-diskWal.Done()
+				})
+			})
+		})
+	})
+}
+
+// ownerReporter records the payloads it is given for each owner
+type ownerReporter struct {
+	reported bool
+	payloads map[string][]ledger.Payload
+}
+
+var _ ledger.OwnerReporter = (*ownerReporter)(nil)
+
+func (r *ownerReporter) Name() string {
+	return "owner reporter"
+}
+
+func (r *ownerReporter) Report([]ledger.Payload) error {
+	r.reported = true
+	return nil
+}
+
+func (r *ownerReporter) ReportOwners(owners [][]byte, ownerPayloads func(owner []byte) ([]ledger.Payload, error)) error {
+	for _, owner := range owners {
+		payloads, err := ownerPayloads(owner)
+		if err != nil {
+			return err
+		}
+		r.payloads[string(owner)] = payloads
+	}
+	return nil
 }
 
 func TestWALUpdateIsRunInParallel(t *testing.T) {
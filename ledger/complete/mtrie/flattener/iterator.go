@@ -110,12 +110,29 @@ func NewUniqueNodeIterator(mTrie *trie.MTrie, visitedNodes map[*node.Node]uint64
 	return i
 }
 
+// NewUniqueSubtrieNodeIterator returns a node NodeIterator, which iterates through all unique nodes
+// of the subtrie with the given root that weren't visited. Like NewUniqueNodeIterator, it guarantees
+// a DESCENDANTS-FIRST-RELATIONSHIP in the sequence of nodes it generates.
+// WARNING: visitedNodes is not safe for concurrent use.
+func NewUniqueSubtrieNodeIterator(root *node.Node, visitedNodes map[*node.Node]uint64) *NodeIterator {
+	// For a subtrie with height H (measured by number of edges), the longest possible path
+	// contains H+1 vertices.
+	stackSize := ledger.NodeMaxHeight + 1
+	i := &NodeIterator{
+		stack:        make([]*node.Node, 0, stackSize),
+		visitedNodes: visitedNodes,
+	}
+	i.unprocessedRoot = root
+	return i
+}
+
 func (i *NodeIterator) Next() bool {
 	if i.unprocessedRoot != nil {
 		// initial call to Next() for a non-empty trie
 		i.dig(i.unprocessedRoot)
 		i.unprocessedRoot = nil
-		return true
+		// the root might have been visited already, in which case there are no nodes to iterate
+		return len(i.stack) > 0
 	}
 
 	// the current head of the stack, `n`, has been recalled
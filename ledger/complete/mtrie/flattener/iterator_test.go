@@ -53,12 +53,12 @@ func TestPopulatedTrie(t *testing.T) {
 	require.True(t, itr.Next())
 	p1_leaf := itr.Value()
 	require.Equal(t, p1, *p1_leaf.Path())
-	require.Equal(t, v1, p1_leaf.Payload())
+	require.Equal(t, v1, leafPayload(t, p1_leaf))
 
 	require.True(t, itr.Next())
 	p2_leaf := itr.Value()
 	require.Equal(t, p2, *p2_leaf.Path())
-	require.Equal(t, v2, p2_leaf.Payload())
+	require.Equal(t, v2, leafPayload(t, p2_leaf))
 
 	require.True(t, itr.Next())
 	p_parent := itr.Value()
@@ -266,3 +266,81 @@ func TestUniqueNodeIterator(t *testing.T) {
 		require.Equal(t, i, len(expectedNodes))
 	})
 }
+
+// TestUniqueNodeIteratorVisitedRoot tests that unique node iterators don't iterate
+// any node if the root of the trie or subtrie was visited already.
+func TestUniqueNodeIteratorVisitedRoot(t *testing.T) {
+	emptyTrie := trie.NewEmptyMTrie()
+
+	paths := []ledger.Path{utils.PathByUint8(1), utils.PathByUint8(64)}
+	payloads := []ledger.Payload{*utils.LightPayload8('A', 'a'), *utils.LightPayload8('B', 'b')}
+
+	updatedTrie, _, err := trie.NewTrieWithUpdatedRegisters(emptyTrie, paths, payloads, true)
+	require.NoError(t, err)
+
+	visitedNodes := make(map[*node.Node]uint64)
+	for itr := flattener.NewUniqueNodeIterator(updatedTrie, visitedNodes); itr.Next(); {
+		visitedNodes[itr.Value()] = uint64(len(visitedNodes))
+	}
+	require.Len(t, visitedNodes, 4)
+
+	itr := flattener.NewUniqueNodeIterator(updatedTrie, visitedNodes)
+	require.False(t, itr.Next())
+	require.Nil(t, itr.Value())
+
+	itr = flattener.NewUniqueSubtrieNodeIterator(updatedTrie.RootNode().LeftChild(), visitedNodes)
+	require.False(t, itr.Next())
+	require.Nil(t, itr.Value())
+}
+
+func TestUniqueSubtrieNodeIterator(t *testing.T) {
+	emptyTrie := trie.NewEmptyMTrie()
+
+	// key: 0000...
+	p1 := utils.PathByUint8(1)
+	v1 := utils.LightPayload8('A', 'a')
+
+	// key: 0100....
+	p2 := utils.PathByUint8(64)
+	v2 := utils.LightPayload8('B', 'b')
+
+	paths := []ledger.Path{p1, p2}
+	payloads := []ledger.Payload{*v1, *v2}
+
+	updatedTrie, _, err := trie.NewTrieWithUpdatedRegisters(emptyTrie, paths, payloads, true)
+	require.NoError(t, err)
+
+	//              n4
+	//             /
+	//            /
+	//          n3
+	//        /     \
+	//      /         \
+	//   n1 (p1/v1)     n2 (p2/v2)
+	//
+
+	subtrieRoot := updatedTrie.RootNode().LeftChild() // n3
+	expectedNodes := []*node.Node{
+		subtrieRoot.LeftChild(),  // n1
+		subtrieRoot.RightChild(), // n2
+		subtrieRoot,              // n3
+	}
+
+	// nil subtrie has no nodes
+	itr := flattener.NewUniqueSubtrieNodeIterator(nil, nil)
+	require.False(t, itr.Next())
+
+	visitedNodes := map[*node.Node]uint64{
+		expectedNodes[0]: 1,
+	}
+	i := 1 // n1 was visited already
+	for itr := flattener.NewUniqueSubtrieNodeIterator(subtrieRoot, visitedNodes); itr.Next(); {
+		n := itr.Value()
+		visitedNodes[n] = uint64(i)
+
+		require.True(t, i < len(expectedNodes))
+		require.Equal(t, expectedNodes[i], n)
+		i++
+	}
+	require.Equal(t, i, len(expectedNodes))
+}
@@ -3,11 +3,13 @@ package mtrie
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	lru "github.com/hashicorp/golang-lru"
 
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	"github.com/onflow/flow-go/module"
 )
@@ -21,6 +23,10 @@ import (
 // tries that are still needed. In fully matured Flow, we will have an
 // explicit eviction policy.
 //
+// The payloads of the tries' leaves are held in memory, unless the Forest is created with a
+// payload storage. In that case, the payloads are stored in the payload storage while node
+// hashes and the trie structure are kept in memory.
+//
 // TODO: Storage Eviction Policy for Forest
 //       For the execution node: we only evict on sealing a result.
 type Forest struct {
@@ -31,6 +37,25 @@ type Forest struct {
 	forestCapacity int
 	onTreeEvicted  func(tree *trie.MTrie)
 	metrics        module.LedgerMetrics
+	payloadStorage node.PayloadStorage // storage of the leaf payloads (nil if payloads are held in memory)
+	ownerIndex     OwnerIndex          // index from register owners to paths (nil if not enabled)
+	// updateLock is held for reading while tries are created and added to the forest, and
+	// for writing while the tries of the forest are listed to find the referenced payloads
+	updateLock sync.RWMutex
+}
+
+// ForestOption configures optional features of a Forest.
+type ForestOption func(*Forest)
+
+// WithOwnerIndex enables a secondary index from the owners of registers to their paths,
+// which is required to list the registers of an owner with OwnerPayloads.
+// Tries added to the forest which the index doesn't hold yet are indexed by loading all their
+// payloads. The paths of registers which no trie of the forest holds anymore are only removed
+// from the index by pruning it (see LiveRegisters).
+func WithOwnerIndex(index OwnerIndex) ForestOption {
+	return func(f *Forest) {
+		f.ownerIndex = index
+	}
 }
 
 // NewForest returns a new instance of memory forest.
@@ -40,7 +65,23 @@ type Forest struct {
 // THIS IS A ROUGH HEURISTIC as it might evict tries that are still needed.
 // Make sure you chose a sufficiently large forestCapacity, such that, when reaching the capacity, the
 // Least Recently Used trie will never be needed again.
-func NewForest(forestCapacity int, metrics module.LedgerMetrics, onTreeEvicted func(tree *trie.MTrie)) (*Forest, error) {
+func NewForest(forestCapacity int, metrics module.LedgerMetrics, onTreeEvicted func(tree *trie.MTrie), opts ...ForestOption) (*Forest, error) {
+	return NewForestWithPayloadStorage(forestCapacity, metrics, onTreeEvicted, nil, opts...)
+}
+
+// NewForestWithPayloadStorage returns a new instance of memory forest, whose tries store their leaf
+// payloads in the given payload storage. Tries added to the forest with payloads held in memory
+// are converted to tries with stored payloads. If payloadStorage is nil, the forest behaves like
+// the one returned by NewForest.
+//
+// See NewForest for cautions on forestCapacity.
+func NewForestWithPayloadStorage(
+	forestCapacity int,
+	metrics module.LedgerMetrics,
+	onTreeEvicted func(tree *trie.MTrie),
+	payloadStorage node.PayloadStorage,
+	opts ...ForestOption,
+) (*Forest, error) {
 	// init LRU cache as a SHORTCUT for a usage-related storage eviction policy
 	var cache *lru.Cache
 	var err error
@@ -63,10 +104,14 @@ func NewForest(forestCapacity int, metrics module.LedgerMetrics, onTreeEvicted f
 		forestCapacity: forestCapacity,
 		onTreeEvicted:  onTreeEvicted,
 		metrics:        metrics,
+		payloadStorage: payloadStorage,
+	}
+	for _, opt := range opts {
+		opt(forest)
 	}
 
 	// add trie with no allocated registers
-	emptyTrie := trie.NewEmptyMTrie()
+	emptyTrie := trie.NewEmptyMTrieWithPayloadStorage(payloadStorage)
 	err = forest.AddTrie(emptyTrie)
 	if err != nil {
 		return nil, fmt.Errorf("adding empty trie to forest failed: %w", err)
@@ -153,7 +198,10 @@ func (f *Forest) Read(r *ledger.TrieRead) ([]*ledger.Payload, error) {
 		pathOrgIndex[path] = append(indices, i)
 	}
 
-	payloads := trie.UnsafeRead(deduplicatedPaths) // this sorts deduplicatedPaths IN-PLACE
+	payloads, err := trie.UnsafeRead(deduplicatedPaths) // this sorts deduplicatedPaths IN-PLACE
+	if err != nil {
+		return nil, fmt.Errorf("reading trie %x failed: %w", r.RootHash, err)
+	}
 
 	// reconstruct the payloads in the same key order that called the method
 	orderedPayloads := make([]*ledger.Payload, len(r.Paths))
@@ -176,6 +224,9 @@ func (f *Forest) Read(r *ledger.TrieRead) ([]*ledger.Payload, error) {
 // In case there are multiple updates to the same register, Update will persist the latest
 // written value.
 func (f *Forest) Update(u *ledger.TrieUpdate) (ledger.RootHash, error) {
+	f.updateLock.RLock()
+	defer f.updateLock.RUnlock()
+
 	emptyHash := ledger.RootHash(hash.DummyHash)
 
 	parentTrie, err := f.GetTrie(u.RootHash)
@@ -213,6 +264,13 @@ func (f *Forest) Update(u *ledger.TrieUpdate) (ledger.RootHash, error) {
 	f.metrics.UpdateValuesNumber(uint64(len(deduplicatedPayloads)))
 	f.metrics.UpdateValuesSize(uint64(totalPayloadSize))
 
+	if f.ownerIndex != nil {
+		err := indexPayloads(f.ownerIndex, deduplicatedPaths, deduplicatedPayloads)
+		if err != nil {
+			return emptyHash, fmt.Errorf("indexing owners of updated registers failed: %w", err)
+		}
+	}
+
 	// apply pruning on update
 	applyPruning := true
 	newTrie, maxDepthTouched, err := trie.NewTrieWithUpdatedRegisters(parentTrie, deduplicatedPaths, deduplicatedPayloads, applyPruning)
@@ -226,7 +284,14 @@ func (f *Forest) Update(u *ledger.TrieUpdate) (ledger.RootHash, error) {
 	f.metrics.LatestTrieRegSizeDiff(int64(newTrie.AllocatedRegSize() - parentTrie.AllocatedRegSize()))
 	f.metrics.LatestTrieMaxDepthTouched(maxDepthTouched)
 
-	err = f.AddTrie(newTrie)
+	// the updated registers are already indexed, and the other registers are indexed for the parent trie
+	if f.ownerIndex != nil {
+		err = f.ownerIndex.AddTrie(newTrie.RootHash())
+		if err != nil {
+			return emptyHash, fmt.Errorf("indexing owners of updated trie failed: %w", err)
+		}
+	}
+	err = f.addTrie(newTrie, make(map[*node.Node]*node.Node))
 	if err != nil {
 		return emptyHash, fmt.Errorf("adding updated trie to forest failed: %w", err)
 	}
@@ -286,10 +351,101 @@ func (f *Forest) Proofs(r *ledger.TrieRead) (*ledger.TrieBatchProof, error) {
 		stateTrie = newTrie
 	}
 
-	bp := stateTrie.UnsafeProofs(r.Paths)
+	bp, err := stateTrie.UnsafeProofs(r.Paths)
+	if err != nil {
+		return nil, fmt.Errorf("proving trie %x failed: %w", r.RootHash, err)
+	}
 	return bp, nil
 }
 
+// Diff returns the registers whose payloads differ between the tries with the given root hashes,
+// ordered by path. Payloads are nil for registers that are only in one of the tries.
+func (f *Forest) Diff(before, after ledger.RootHash) ([]trie.PayloadDiff, error) {
+	beforeTrie, err := f.GetTrie(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterTrie, err := f.GetTrie(after)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs, err := trie.Diff(beforeTrie, afterTrie)
+	if err != nil {
+		return nil, fmt.Errorf("diffing trie %x and trie %x failed: %w", before, after, err)
+	}
+
+	// the returned payloads must not reference the tries' payloads
+	for i, d := range diffs {
+		if d.Before != nil {
+			diffs[i].Before = d.Before.DeepCopy()
+		}
+		if d.After != nil {
+			diffs[i].After = d.After.DeepCopy()
+		}
+	}
+
+	return diffs, nil
+}
+
+// OwnerPayloads returns the non-empty payloads of all registers of the given owner in the trie
+// with the given root hash, ordered by path. The owner is the value of the key part of type
+// OwnerKeyPartType. The forest must be created with the WithOwnerIndex option.
+func (f *Forest) OwnerPayloads(rootHash ledger.RootHash, owner []byte) ([]*ledger.Payload, error) {
+	if f.ownerIndex == nil {
+		return nil, fmt.Errorf("owner index is not enabled")
+	}
+
+	trie, err := f.GetTrie(rootHash)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := f.ownerIndex.OwnerPaths(owner)
+	if err != nil {
+		return nil, err
+	}
+	payloads, err := trie.UnsafeRead(paths) // this sorts paths IN-PLACE
+	if err != nil {
+		return nil, fmt.Errorf("reading trie %x failed: %w", rootHash, err)
+	}
+
+	// the index also holds paths of registers which don't exist in this trie
+	result := make([]*ledger.Payload, 0, len(payloads))
+	totalPayloadSize := 0
+	for _, payload := range payloads {
+		if payload.IsEmpty() {
+			continue
+		}
+		result = append(result, payload.DeepCopy())
+		totalPayloadSize += payload.Size()
+	}
+	f.metrics.ReadValuesSize(uint64(totalPayloadSize))
+
+	return result, nil
+}
+
+// HasOwnerIndex returns whether the forest was created with the WithOwnerIndex option.
+func (f *Forest) HasOwnerIndex() bool {
+	return f.ownerIndex != nil
+}
+
+// Owners returns the owners of the registers held by the owner index, which may include owners
+// without registers in some tries. The forest must be created with the WithOwnerIndex option.
+func (f *Forest) Owners() ([][]byte, error) {
+	if f.ownerIndex == nil {
+		return nil, fmt.Errorf("owner index is not enabled")
+	}
+	return f.ownerIndex.Owners()
+}
+
+// HasTrie returns true if the forest holds the trie with the given rootHash.
+func (f *Forest) HasTrie(rootHash ledger.RootHash) bool {
+	_, found := f.tries.Get(rootHash)
+	return found
+}
+
 // GetTrie returns trie at specific rootHash
 // warning, use this function for read-only operation
 func (f *Forest) GetTrie(rootHash ledger.RootHash) (*trie.MTrie, error) {
@@ -323,10 +479,102 @@ func (f *Forest) GetTries() ([]*trie.MTrie, error) {
 	return tries, nil
 }
 
+// StoredPayloadKeys returns the payload storage keys of the payloads of all tries in the forest.
+// Tries which are being created and added to the forest when StoredPayloadKeys is called are
+// waited for, tries added afterwards are ignored.
+// CAUTION: holds the key of every stored payload in memory.
+func (f *Forest) StoredPayloadKeys() (map[hash.Hash]struct{}, error) {
+	f.updateLock.Lock()
+	tries, err := f.GetTries()
+	f.updateLock.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get tries of forest: %w", err)
+	}
+
+	keys := make(map[hash.Hash]struct{})
+	// interim nodes shared by several tries are only visited once
+	visited := make(map[*node.Node]struct{})
+	for _, t := range tries {
+		addStoredPayloadKeys(t.RootNode(), keys, visited)
+	}
+	return keys, nil
+}
+
+// addStoredPayloadKeys adds the payload storage keys of the leaves in the subtrie with `head` as root node to `keys`.
+func addStoredPayloadKeys(head *node.Node, keys map[hash.Hash]struct{}, visited map[*node.Node]struct{}) {
+	if head == nil {
+		return
+	}
+	if head.IsLeaf() {
+		if key, ok := head.StoredPayloadKey(); ok {
+			keys[key] = struct{}{}
+		}
+		return
+	}
+	if _, ok := visited[head]; ok {
+		return
+	}
+	visited[head] = struct{}{}
+
+	addStoredPayloadKeys(head.LeftChild(), keys, visited)
+	addStoredPayloadKeys(head.RightChild(), keys, visited)
+}
+
+// LiveRegisters returns the root hashes of the tries of the forest and the paths of their registers,
+// to prune the owner index. See StoredPayloadKeys regarding tries created concurrently.
+// CAUTION: holds the path of every register in memory.
+func (f *Forest) LiveRegisters() (map[ledger.RootHash]struct{}, map[ledger.Path]struct{}, error) {
+	f.updateLock.Lock()
+	tries, err := f.GetTries()
+	f.updateLock.Unlock()
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot get tries of forest: %w", err)
+	}
+
+	rootHashes := make(map[ledger.RootHash]struct{}, len(tries))
+	paths := make(map[ledger.Path]struct{})
+	// interim nodes shared by several tries are only visited once
+	visited := make(map[*node.Node]struct{})
+	for _, t := range tries {
+		rootHashes[t.RootHash()] = struct{}{}
+		addLeafPaths(t.RootNode(), paths, visited)
+	}
+	return rootHashes, paths, nil
+}
+
+// addLeafPaths adds the paths of the leaves in the subtrie with `head` as root node to `paths`.
+func addLeafPaths(head *node.Node, paths map[ledger.Path]struct{}, visited map[*node.Node]struct{}) {
+	if head == nil {
+		return
+	}
+	if head.IsLeaf() {
+		paths[*head.Path()] = struct{}{}
+		return
+	}
+	if _, ok := visited[head]; ok {
+		return
+	}
+	visited[head] = struct{}{}
+
+	addLeafPaths(head.LeftChild(), paths, visited)
+	addLeafPaths(head.RightChild(), paths, visited)
+}
+
 // AddTries adds a trie to the forest
 func (f *Forest) AddTries(newTries []*trie.MTrie) error {
+	f.updateLock.RLock()
+	defer f.updateLock.RUnlock()
+
+	// nodes shared by the tries are only converted once to nodes with stored payloads,
+	// and only indexed once
+	replaced := make(map[*node.Node]*node.Node)
+	indexer := f.newOwnerIndexer()
 	for _, t := range newTries {
-		err := f.AddTrie(t)
+		err := f.indexOwners(t, indexer)
+		if err != nil {
+			return fmt.Errorf("adding tries to forest failed: %w", err)
+		}
+		err = f.addTrie(t, replaced)
 		if err != nil {
 			return fmt.Errorf("adding tries to forest failed: %w", err)
 		}
@@ -336,6 +584,43 @@ func (f *Forest) AddTries(newTries []*trie.MTrie) error {
 
 // AddTrie adds a trie to the forest
 func (f *Forest) AddTrie(newTrie *trie.MTrie) error {
+	f.updateLock.RLock()
+	defer f.updateLock.RUnlock()
+
+	err := f.indexOwners(newTrie, f.newOwnerIndexer())
+	if err != nil {
+		return err
+	}
+	return f.addTrie(newTrie, make(map[*node.Node]*node.Node))
+}
+
+// newOwnerIndexer returns an indexer for the owner index, or nil if it isn't enabled.
+func (f *Forest) newOwnerIndexer() *ownerIndexer {
+	if f.ownerIndex == nil {
+		return nil
+	}
+	return newOwnerIndexer(f.ownerIndex)
+}
+
+// indexOwners adds the registers of the trie to the owner index, if it is enabled
+// and doesn't hold them yet.
+func (f *Forest) indexOwners(newTrie *trie.MTrie, indexer *ownerIndexer) error {
+	if indexer == nil || newTrie == nil {
+		return nil
+	}
+	if _, found := f.tries.Peek(newTrie.RootHash()); found {
+		return nil
+	}
+	err := indexer.indexTrie(newTrie)
+	if err != nil {
+		return fmt.Errorf("cannot index owners of trie %x: %w", newTrie.RootHash(), err)
+	}
+	return nil
+}
+
+// addTrie adds a trie to the forest, after converting it to a trie with stored payloads
+// if the forest has a payload storage. Converted nodes are recorded in `replaced`.
+func (f *Forest) addTrie(newTrie *trie.MTrie, replaced map[*node.Node]*node.Node) error {
 	if newTrie == nil {
 		return nil
 	}
@@ -352,6 +637,15 @@ func (f *Forest) AddTrie(newTrie *trie.MTrie) error {
 		}
 		return fmt.Errorf("forest already contains a tree with same root hash but other properties")
 	}
+
+	if f.payloadStorage != nil && newTrie.PayloadStorage() != f.payloadStorage {
+		var err error
+		newTrie, err = trie.NewMTrieWithPayloadStorage(newTrie, f.payloadStorage, replaced)
+		if err != nil {
+			return fmt.Errorf("could not store payloads of trie: %w", err)
+		}
+	}
+
 	f.tries.Add(rootHash, newTrie)
 	f.metrics.ForestNumberOfTrees(uint64(f.tries.Len()))
 
@@ -2,21 +2,29 @@ package mtrie
 
 import (
 	"bytes"
+	"errors"
 	"math/rand"
 	"sort"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/dgraph-io/badger/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/encoding"
+	"github.com/onflow/flow-go/ledger/common/hash"
 	prf "github.com/onflow/flow-go/ledger/common/proof"
 	"github.com/onflow/flow-go/ledger/common/utils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/ownerindex"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/payloadstore"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	"github.com/onflow/flow-go/ledger/partial/ptrie"
 	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
 )
 
 // TestTrieOperations tests adding removing and retrieving Trie from Forest
@@ -982,3 +990,352 @@ func TestValueSizesWithDuplicatedKeys(t *testing.T) {
 		require.Equal(t, expectedValueSizes[i], retValueSizes[i])
 	}
 }
+
+// TestForestDiff verifies that the diff between two tries of the forest contains the updated registers.
+func TestForestDiff(t *testing.T) {
+	forest, err := NewForest(5, &metrics.NoopCollector{}, nil)
+	require.NoError(t, err)
+
+	p1 := pathByUint8s([]uint8{uint8(53), uint8(74)})
+	v1 := payloadBySlices([]byte{'A'}, []byte{'A'})
+	p2 := pathByUint8s([]uint8{uint8(116), uint8(129)})
+	v2 := payloadBySlices([]byte{'B'}, []byte{'B'})
+	v3 := payloadBySlices([]byte{'A'}, []byte{'C'})
+
+	update := &ledger.TrieUpdate{RootHash: forest.GetEmptyRootHash(), Paths: []ledger.Path{p1}, Payloads: []*ledger.Payload{v1}}
+	baseRoot, err := forest.Update(update)
+	require.NoError(t, err)
+
+	update = &ledger.TrieUpdate{RootHash: baseRoot, Paths: []ledger.Path{p1, p2}, Payloads: []*ledger.Payload{v3, v2}}
+	updatedRoot, err := forest.Update(update)
+	require.NoError(t, err)
+
+	diffs, err := forest.Diff(baseRoot, updatedRoot)
+	require.NoError(t, err)
+	require.Len(t, diffs, 2)
+	require.Equal(t, p1, diffs[0].Path)
+	require.True(t, v1.Equals(diffs[0].Before))
+	require.True(t, v3.Equals(diffs[0].After))
+	require.Equal(t, p2, diffs[1].Path)
+	require.True(t, diffs[1].IsAdded())
+	require.True(t, v2.Equals(diffs[1].After))
+
+	// unknown tries can't be diffed
+	_, err = forest.Diff(baseRoot, ledger.RootHash(hash.DummyHash))
+	require.Error(t, err)
+}
+
+// TestForestOwnerPayloads verifies that the owner index lists the registers of an owner
+// at any state of the forest.
+func TestForestOwnerPayloads(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		index := ownerindex.New(db)
+		forest, err := NewForest(5, &metrics.NoopCollector{}, nil, WithOwnerIndex(index))
+		require.NoError(t, err)
+
+		ownerA := []byte{'A'}
+		ownerB := []byte{'B'}
+		pA1 := pathByUint8s([]uint8{uint8(53), uint8(74)})
+		vA1 := payloadBySlices(ownerA, []byte{'a'})
+		pA2 := pathByUint8s([]uint8{uint8(116), uint8(129)})
+		vA2 := payloadBySlices(ownerA, []byte{'b'})
+		pB1 := pathByUint8s([]uint8{uint8(12), uint8(1)})
+		vB1 := payloadBySlices(ownerB, []byte{'c'})
+
+		update := &ledger.TrieUpdate{
+			RootHash: forest.GetEmptyRootHash(),
+			Paths:    []ledger.Path{pA2, pB1, pA1},
+			Payloads: []*ledger.Payload{vA2, vB1, vA1},
+		}
+		baseRoot, err := forest.Update(update)
+		require.NoError(t, err)
+
+		// remove a register of owner A
+		update = &ledger.TrieUpdate{
+			RootHash: baseRoot,
+			Paths:    []ledger.Path{pA1},
+			Payloads: []*ledger.Payload{ledger.EmptyPayload()},
+		}
+		updatedRoot, err := forest.Update(update)
+		require.NoError(t, err)
+
+		payloads, err := forest.OwnerPayloads(baseRoot, ownerA)
+		require.NoError(t, err)
+		requirePayloadsEqual(t, []*ledger.Payload{vA1, vA2}, payloads)
+
+		payloads, err = forest.OwnerPayloads(updatedRoot, ownerA)
+		require.NoError(t, err)
+		requirePayloadsEqual(t, []*ledger.Payload{vA2}, payloads)
+
+		payloads, err = forest.OwnerPayloads(updatedRoot, ownerB)
+		require.NoError(t, err)
+		requirePayloadsEqual(t, []*ledger.Payload{vB1}, payloads)
+
+		payloads, err = forest.OwnerPayloads(forest.GetEmptyRootHash(), ownerA)
+		require.NoError(t, err)
+		require.Empty(t, payloads)
+
+		payloads, err = forest.OwnerPayloads(updatedRoot, []byte{'C'})
+		require.NoError(t, err)
+		require.Empty(t, payloads)
+
+		owners, err := forest.Owners()
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{ownerA, ownerB}, owners)
+
+		// unknown tries can't be read
+		_, err = forest.OwnerPayloads(ledger.RootHash(hash.DummyHash), ownerA)
+		require.Error(t, err)
+
+		baseTrie, err := forest.GetTrie(baseRoot)
+		require.NoError(t, err)
+		updatedTrie, err := forest.GetTrie(updatedRoot)
+		require.NoError(t, err)
+
+		t.Run("tries added to the forest are indexed", func(t *testing.T) {
+			unittest.RunWithBadgerDB(t, func(restoredDB *badger.DB) {
+				restoredForest, err := NewForest(5, &metrics.NoopCollector{}, nil, WithOwnerIndex(ownerindex.New(restoredDB)))
+				require.NoError(t, err)
+				err = restoredForest.AddTries([]*trie.MTrie{baseTrie, updatedTrie})
+				require.NoError(t, err)
+
+				payloads, err := restoredForest.OwnerPayloads(baseRoot, ownerA)
+				require.NoError(t, err)
+				requirePayloadsEqual(t, []*ledger.Payload{vA1, vA2}, payloads)
+
+				payloads, err = restoredForest.OwnerPayloads(updatedRoot, ownerB)
+				require.NoError(t, err)
+				requirePayloadsEqual(t, []*ledger.Payload{vB1}, payloads)
+			})
+		})
+
+		t.Run("the index is pruned", func(t *testing.T) {
+			// the index already holds the tries, so they aren't indexed again
+			indexed, err := index.HasTrie(baseRoot)
+			require.NoError(t, err)
+			require.True(t, indexed)
+
+			restoredForest, err := NewForest(5, &metrics.NoopCollector{}, nil, WithOwnerIndex(index))
+			require.NoError(t, err)
+			err = restoredForest.AddTrie(updatedTrie)
+			require.NoError(t, err)
+
+			// the removed register of owner A is only held by the base trie
+			removed, err := index.Prune(restoredForest.LiveRegisters)
+			require.NoError(t, err)
+			require.Equal(t, 1, removed)
+
+			indexed, err = index.HasTrie(baseRoot)
+			require.NoError(t, err)
+			require.False(t, indexed)
+
+			paths, err := index.OwnerPaths(ownerA)
+			require.NoError(t, err)
+			require.Equal(t, []ledger.Path{pA2}, paths)
+
+			payloads, err := restoredForest.OwnerPayloads(updatedRoot, ownerA)
+			require.NoError(t, err)
+			requirePayloadsEqual(t, []*ledger.Payload{vA2}, payloads)
+		})
+
+		// the index must be enabled
+		forestWithoutIndex, err := NewForest(5, &metrics.NoopCollector{}, nil)
+		require.NoError(t, err)
+		_, err = forestWithoutIndex.OwnerPayloads(forestWithoutIndex.GetEmptyRootHash(), ownerA)
+		require.Error(t, err)
+	})
+}
+
+// TestForestWithPayloadStorage applies the same random updates to a forest holding payloads in memory and a
+// forest storing payloads in a payload storage, and verifies that both forests return the same results.
+func TestForestWithPayloadStorage(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		rep := 10
+		maxNumPathsPerStep := 64
+		seed := time.Now().UnixNano()
+		rand.Seed(seed)
+		t.Log(seed)
+
+		// a small cache makes most reads go to the database
+		storage, err := payloadstore.New(db, 8)
+		require.NoError(t, err)
+
+		memForest, err := NewForest(5, &metrics.NoopCollector{}, nil)
+		require.NoError(t, err)
+		storedForest, err := NewForestWithPayloadStorage(5, &metrics.NoopCollector{}, nil, storage)
+		require.NoError(t, err)
+
+		activeRoot := memForest.GetEmptyRootHash()
+		allPaths := make([]ledger.Path, 0)
+		for e := 0; e < rep; e++ {
+			paths := utils.RandomPathsRandLen(maxNumPathsPerStep)
+			payloads := utils.RandomPayloads(len(paths), 2, 10)
+			allPaths = append(allPaths, paths...)
+
+			update := &ledger.TrieUpdate{RootHash: activeRoot, Paths: paths, Payloads: payloads}
+			memRoot, err := memForest.Update(update)
+			require.NoError(t, err)
+			storedRoot, err := storedForest.Update(update)
+			require.NoError(t, err)
+			require.Equal(t, memRoot, storedRoot)
+			activeRoot = memRoot
+
+			queryPaths := append(append([]ledger.Path{}, allPaths...), utils.RandomPathsRandLen(maxNumPathsPerStep)...)
+			read := &ledger.TrieRead{RootHash: activeRoot, Paths: queryPaths}
+
+			memPayloads, err := memForest.Read(read)
+			require.NoError(t, err)
+			storedPayloads, err := storedForest.Read(read)
+			require.NoError(t, err)
+			requirePayloadsEqual(t, memPayloads, storedPayloads)
+
+			memSizes, err := memForest.ValueSizes(read)
+			require.NoError(t, err)
+			storedSizes, err := storedForest.ValueSizes(read)
+			require.NoError(t, err)
+			require.Equal(t, memSizes, storedSizes)
+
+			memProofs, err := memForest.Proofs(&ledger.TrieRead{RootHash: activeRoot, Paths: append([]ledger.Path{}, queryPaths...)})
+			require.NoError(t, err)
+			storedProofs, err := storedForest.Proofs(&ledger.TrieRead{RootHash: activeRoot, Paths: append([]ledger.Path{}, queryPaths...)})
+			require.NoError(t, err)
+			require.True(t, memProofs.Equals(storedProofs))
+			require.True(t, prf.VerifyTrieBatchProof(storedProofs, ledger.State(activeRoot)))
+		}
+
+		// tries holding payloads in memory are converted when they are added to the forest
+		memTrie, err := memForest.GetTrie(activeRoot)
+		require.NoError(t, err)
+		storedForest, err = NewForestWithPayloadStorage(5, &metrics.NoopCollector{}, nil, storage)
+		require.NoError(t, err)
+		err = storedForest.AddTries([]*trie.MTrie{memTrie})
+		require.NoError(t, err)
+
+		storedTrie, err := storedForest.GetTrie(activeRoot)
+		require.NoError(t, err)
+		require.True(t, storedTrie.Equals(memTrie))
+		require.Equal(t, node.PayloadStorage(storage), storedTrie.PayloadStorage())
+		require.Nil(t, memTrie.PayloadStorage())
+
+		read := &ledger.TrieRead{RootHash: activeRoot, Paths: append([]ledger.Path{}, allPaths...)}
+		memPayloads, err := memForest.Read(read)
+		require.NoError(t, err)
+		storedPayloads, err := storedForest.Read(read)
+		require.NoError(t, err)
+		requirePayloadsEqual(t, memPayloads, storedPayloads)
+	})
+}
+
+// TestForestPrunePayloads verifies that pruning the payload storage with the stored payload keys of the
+// forest only removes the payloads of tries evicted from the forest.
+func TestForestPrunePayloads(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		storage, err := payloadstore.New(db, 8)
+		require.NoError(t, err)
+
+		forest, err := NewForestWithPayloadStorage(3, &metrics.NoopCollector{}, nil, storage)
+		require.NoError(t, err)
+
+		path := pathByUint8s([]uint8{uint8(53), uint8(74)})
+		otherPath := pathByUint8s([]uint8{uint8(116), uint8(129)})
+		root := forest.GetEmptyRootHash()
+		for i := 0; i < 5; i++ {
+			// the payload at `otherPath` is shared by all tries
+			paths := []ledger.Path{path}
+			payloads := []*ledger.Payload{payloadBySlices([]byte{'A'}, []byte{byte(i)})}
+			if i == 0 {
+				paths = append(paths, otherPath)
+				payloads = append(payloads, payloadBySlices([]byte{'B'}, []byte{'B'}))
+			}
+			root, err = forest.Update(&ledger.TrieUpdate{RootHash: root, Paths: paths, Payloads: payloads})
+			require.NoError(t, err)
+		}
+
+		keys, err := forest.StoredPayloadKeys()
+		require.NoError(t, err)
+		// the forest holds the last three tries, whose leaves at `path` have different payloads
+		require.Len(t, keys, 4)
+
+		removed, err := storage.Prune(forest.StoredPayloadKeys)
+		require.NoError(t, err)
+		require.Equal(t, 2, removed)
+
+		tries, err := forest.GetTries()
+		require.NoError(t, err)
+		for _, tr := range tries {
+			_, err := tr.AllPayloads()
+			require.NoError(t, err)
+		}
+
+		read, err := forest.Read(&ledger.TrieRead{RootHash: root, Paths: []ledger.Path{path, otherPath}})
+		require.NoError(t, err)
+		require.Equal(t, ledger.Value([]byte{4}), read[0].Value)
+		require.Equal(t, ledger.Value([]byte{'B'}), read[1].Value)
+
+		// nothing is removed while all stored payloads are referenced
+		removed, err = storage.Prune(forest.StoredPayloadKeys)
+		require.NoError(t, err)
+		require.Equal(t, 0, removed)
+	})
+}
+
+func requirePayloadsEqual(t *testing.T, expected []*ledger.Payload, actual []*ledger.Payload) {
+	require.Equal(t, len(expected), len(actual))
+	for i := range expected {
+		require.True(t, expected[i].Equals(actual[i]))
+	}
+}
+
+// TestForestWithFailingPayloadStorage verifies that errors of the payload storage are returned by the forest.
+func TestForestWithFailingPayloadStorage(t *testing.T) {
+	storage := &failingPayloadStorage{}
+	forest, err := NewForestWithPayloadStorage(5, &metrics.NoopCollector{}, nil, storage)
+	require.NoError(t, err)
+
+	paths := []ledger.Path{pathByUint8s([]uint8{uint8(53), uint8(74)})}
+	payloads := []*ledger.Payload{payloadBySlices([]byte{'A'}, []byte{'A'})}
+	update := &ledger.TrieUpdate{RootHash: forest.GetEmptyRootHash(), Paths: paths, Payloads: payloads}
+	root, err := forest.Update(update)
+	require.NoError(t, err)
+
+	storage.failLoad = true
+	_, err = forest.Read(&ledger.TrieRead{RootHash: root, Paths: paths})
+	require.Error(t, err)
+	_, err = forest.Proofs(&ledger.TrieRead{RootHash: root, Paths: paths})
+	require.Error(t, err)
+
+	// value sizes are kept with the trie and don't need the storage
+	sizes, err := forest.ValueSizes(&ledger.TrieRead{RootHash: root, Paths: paths})
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, sizes)
+
+	storage.failStore = true
+	_, err = forest.Update(&ledger.TrieUpdate{RootHash: root, Paths: paths, Payloads: []*ledger.Payload{payloadBySlices([]byte{'A'}, []byte{'B'})}})
+	require.Error(t, err)
+}
+
+// failingPayloadStorage is a payload storage holding payloads in memory, which fails on demand.
+type failingPayloadStorage struct {
+	payloads  sync.Map
+	failStore bool
+	failLoad  bool
+}
+
+func (s *failingPayloadStorage) Store(key hash.Hash, payload *ledger.Payload) error {
+	if s.failStore {
+		return errors.New("store failed")
+	}
+	s.payloads.Store(key, payload)
+	return nil
+}
+
+func (s *failingPayloadStorage) Load(key hash.Hash) (*ledger.Payload, error) {
+	if s.failLoad {
+		return nil, errors.New("load failed")
+	}
+	payload, ok := s.payloads.Load(key)
+	if !ok {
+		return nil, errors.New("payload not found")
+	}
+	return payload.(*ledger.Payload), nil
+}
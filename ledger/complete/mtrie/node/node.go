@@ -22,6 +22,9 @@ import (
 // representative for any empty (sub)-trie (i.e. a trie without allocated
 // registers).
 //
+// The payload of a leaf is either held in memory, or, for leaves created with a
+// PayloadStorage, stored outside of the trie and loaded on access.
+//
 // Nodes are supposed to be treated as _immutable_ data structures.
 // TODO: optimized data structures might be able to reduce memory consumption
 type Node struct {
@@ -30,12 +33,50 @@ type Node struct {
 	// the current implementation is designed to operate on a sparsely populated
 	// tree, holding much less than 2^64 registers.
 
-	lChild    *Node           // Left Child
-	rChild    *Node           // Right Child
-	height    int             // height where the Node is at
-	path      ledger.Path     // the storage path (dummy value for interim nodes)
-	payload   *ledger.Payload // the payload this node is storing (leaf nodes only)
-	hashValue hash.Hash       // hash value of node (cached)
+	lChild        *Node           // Left Child
+	rChild        *Node           // Right Child
+	height        int             // height where the Node is at
+	path          ledger.Path     // the storage path (dummy value for interim nodes)
+	payload       *ledger.Payload // the payload this node is storing (leaf nodes only, nil if the payload is stored)
+	storedPayload *storedPayload  // reference to the payload in a payload storage (stored leaf nodes only)
+	hashValue     hash.Hash       // hash value of node (cached)
+}
+
+// PayloadStorage stores leaf payloads outside of the trie. Payloads are stored under the
+// hash of the leaf that was created for them and are never modified afterwards.
+// Implementations must be safe for concurrent use.
+type PayloadStorage interface {
+	// Store stores the payload under the given leaf hash.
+	Store(key hash.Hash, payload *ledger.Payload) error
+	// Load returns the payload stored under the given leaf hash.
+	Load(key hash.Hash) (*ledger.Payload, error)
+}
+
+// BatchPayloadStorage is a PayloadStorage that can write the payloads of many leaves at once.
+type BatchPayloadStorage interface {
+	PayloadStorage
+	// NewBatch returns an empty batch of payloads to be written to the storage.
+	NewBatch() PayloadBatch
+}
+
+// PayloadBatch collects the payloads of new leaves and writes them to the payload storage
+// it was created from when flushed. Payloads stored in the batch can be loaded before the
+// batch is flushed.
+// Implementations must be safe for concurrent use.
+type PayloadBatch interface {
+	PayloadStorage
+	// Flush writes all payloads of the batch to the payload storage.
+	Flush() error
+	// Cancel discards the payloads of the batch, unless it was flushed already.
+	Cancel()
+}
+
+// storedPayload references a payload in a payload storage. Leaves whose payload is
+// carried to a different height (compactified leaves) share the reference.
+type storedPayload struct {
+	storage   PayloadStorage
+	key       hash.Hash
+	valueSize int
 }
 
 // NewNode creates a new Node.
@@ -74,10 +115,76 @@ func NewLeaf(path ledger.Path,
 		path:    path,
 		payload: payload,
 	}
-	n.hashValue = n.computeHash()
+	n.hashValue = n.computeHash(payload)
 	return n
 }
 
+// NewStoredLeaf creates a compact leaf Node whose payload is stored in the given payload storage
+// instead of being held in memory.
+// UNCHECKED requirement: height must be non-negative
+// UNCHECKED requirement: payload is non nil
+func NewStoredLeaf(path ledger.Path,
+	payload *ledger.Payload,
+	height int,
+	storage PayloadStorage,
+) (*Node, error) {
+	n := NewLeaf(path, payload, height)
+	err := storage.Store(n.hashValue, payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not store payload of leaf %x: %w", n.hashValue, err)
+	}
+
+	n.payload = nil
+	n.storedPayload = &storedPayload{
+		storage:   storage,
+		key:       n.hashValue,
+		valueSize: payload.Value.Size(),
+	}
+	return n, nil
+}
+
+// NewLeafFromLeaf creates a compact leaf Node at the given height, for the path and payload of the
+// given leaf. A stored payload is shared with the given leaf instead of being stored again.
+// UNCHECKED requirement: height must be non-negative
+// UNCHECKED requirement: leaf is a leaf node
+func NewLeafFromLeaf(leaf *Node, height int) (*Node, error) {
+	payload, err := leaf.LoadPayload()
+	if err != nil {
+		return nil, err
+	}
+
+	n := NewLeaf(leaf.path, payload, height)
+	if leaf.storedPayload != nil {
+		n.payload = nil
+		n.storedPayload = leaf.storedPayload
+	}
+	return n, nil
+}
+
+// NewStoredNode returns a copy of the leaf Node whose payload is stored in the given payload
+// storage. Leaves whose payload is already stored and interim nodes are returned as they are.
+func (n *Node) NewStoredNode(storage PayloadStorage) (*Node, error) {
+	if n == nil || !n.IsLeaf() || n.storedPayload != nil {
+		return n, nil
+	}
+
+	err := storage.Store(n.hashValue, n.payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not store payload of leaf %x: %w", n.hashValue, err)
+	}
+
+	return &Node{
+		height: n.height,
+		path:   n.path,
+		storedPayload: &storedPayload{
+			storage:   storage,
+			key:       n.hashValue,
+			valueSize: n.payload.Value.Size(),
+		},
+		hashValue: n.hashValue,
+	}, nil
+}
+
 // NewInterimNode creates a new interim Node.
 // UNCHECKED requirement:
 //  * for any child `c` that is non-nil, its height must satisfy: height = c.height + 1
@@ -88,7 +195,7 @@ func NewInterimNode(height int, lchild, rchild *Node) *Node {
 		height:  height,
 		payload: nil,
 	}
-	n.hashValue = n.computeHash()
+	n.hashValue = n.computeHash(nil)
 	return n
 }
 
@@ -120,11 +227,11 @@ func NewInterimCompactifiedNode(height int, lChild, rChild *Node) *Node {
 	// an empty subtrie => in total we have one allocated register, which we represent as single leaf node
 	if rChild == nil && lChild.IsLeaf() {
 		h := hash.HashInterNode(lChild.hashValue, ledger.GetDefaultHashForHeight(lChild.height))
-		return &Node{height: height, path: lChild.path, payload: lChild.payload, hashValue: h}
+		return &Node{height: height, path: lChild.path, payload: lChild.payload, storedPayload: lChild.storedPayload, hashValue: h}
 	}
 	if lChild == nil && rChild.IsLeaf() {
 		h := hash.HashInterNode(ledger.GetDefaultHashForHeight(rChild.height), rChild.hashValue)
-		return &Node{height: height, path: rChild.path, payload: rChild.payload, hashValue: h}
+		return &Node{height: height, path: rChild.path, payload: rChild.payload, storedPayload: rChild.storedPayload, hashValue: h}
 	}
 
 	// CASE (b): both children contain some allocated registers => we can't compactify; return a full interim leaf
@@ -141,13 +248,13 @@ func (n *Node) IsDefaultNode() bool {
 	return n.hashValue == ledger.GetDefaultHashForHeight(n.height)
 }
 
-// computeHash returns the hashValue of the node
-func (n *Node) computeHash() hash.Hash {
+// computeHash returns the hashValue of the node, given the payload of the node if it is a leaf
+func (n *Node) computeHash(payload *ledger.Payload) hash.Hash {
 	// check for leaf node
 	if n.lChild == nil && n.rChild == nil {
 		// if payload is non-nil, compute the hash based on the payload content
-		if n.payload != nil {
-			return ledger.ComputeCompactValue(hash.Hash(n.path), n.payload.Value, n.height)
+		if payload != nil {
+			return ledger.ComputeCompactValue(hash.Hash(n.path), payload.Value, n.height)
 		}
 		// if payload is nil, return the default hash
 		return ledger.GetDefaultHashForHeight(n.height)
@@ -178,11 +285,17 @@ func verifyCachedHashRecursive(n *Node) bool {
 		return false
 	}
 
-	computedHash := n.computeHash()
+	payload, err := n.LoadPayload()
+	if err != nil {
+		// the hash of the leaf can't be verified without its payload
+		return false
+	}
+	computedHash := n.computeHash(payload)
 	return n.hashValue == computedHash
 }
 
-// VerifyCachedHash verifies the hash of a node is valid
+// VerifyCachedHash verifies the hash of a node is valid.
+// It returns false if a stored payload of the subtrie cannot be loaded.
 func (n *Node) VerifyCachedHash() bool {
 	return verifyCachedHashRecursive(n)
 }
@@ -209,10 +322,44 @@ func (n *Node) Path() *ledger.Path {
 	return nil
 }
 
-// Payload returns the the Node's payload.
+// LoadPayload returns the Node's payload, loading it from the payload storage if it is stored.
 // Do NOT MODIFY returned slices!
-func (n *Node) Payload() *ledger.Payload {
-	return n.payload
+func (n *Node) LoadPayload() (*ledger.Payload, error) {
+	if n.storedPayload == nil {
+		return n.payload, nil
+	}
+
+	payload, err := n.storedPayload.storage.Load(n.storedPayload.key)
+	if err != nil {
+		return nil, fmt.Errorf("could not load payload of leaf %x: %w", n.storedPayload.key, err)
+	}
+	return payload, nil
+}
+
+// PayloadValueSize returns the size of the value of the Node's payload, without loading stored payloads.
+// It returns 0 for interim nodes.
+func (n *Node) PayloadValueSize() int {
+	if n.storedPayload != nil {
+		return n.storedPayload.valueSize
+	}
+	if n.payload == nil {
+		return 0
+	}
+	return n.payload.Value.Size()
+}
+
+// IsPayloadStored returns true if and only if the Node is a leaf whose payload is stored in a payload storage.
+func (n *Node) IsPayloadStored() bool {
+	return n != nil && n.storedPayload != nil
+}
+
+// StoredPayloadKey returns the key of the Node's payload in the payload storage, and false
+// if the Node is not a leaf whose payload is stored.
+func (n *Node) StoredPayloadKey() (hash.Hash, bool) {
+	if !n.IsPayloadStored() {
+		return hash.DummyHash, false
+	}
+	return n.storedPayload.key, true
 }
 
 // LeftChild returns the the Node's left child.
@@ -241,30 +388,39 @@ func (n *Node) FmtStr(prefix string, subpath string) string {
 	if n.lChild != nil {
 		left = fmt.Sprintf("\n%v", n.lChild.FmtStr(prefix+"\t", subpath+"0"))
 	}
-	payloadSize := 0
-	if n.payload != nil {
-		payloadSize = n.payload.Size()
+	payloadSize := "0"
+	payload, err := n.LoadPayload()
+	if err != nil {
+		payloadSize = fmt.Sprintf("unknown (%v)", err)
+	} else if payload != nil {
+		payloadSize = fmt.Sprint(payload.Size())
 	}
 	hashStr := hex.EncodeToString(n.hashValue[:])
 	hashStr = hashStr[:3] + "..." + hashStr[len(hashStr)-3:]
-	return fmt.Sprintf("%v%v: (path:%v, payloadSize:%d hash:%v)[%s] (obj %p) %v %v ", prefix, n.height, n.path, payloadSize, hashStr, subpath, n, left, right)
+	return fmt.Sprintf("%v%v: (path:%v, payloadSize:%s hash:%v)[%s] (obj %p) %v %v ", prefix, n.height, n.path, payloadSize, hashStr, subpath, n, left, right)
 }
 
 // AllPayloads returns the payload of this node and all payloads of the subtrie
-func (n *Node) AllPayloads() []ledger.Payload {
+func (n *Node) AllPayloads() ([]ledger.Payload, error) {
 	return n.appendSubtreePayloads([]ledger.Payload{})
 }
 
 // appendSubtreePayloads appends the payloads of the subtree with this node as root
 // to the provided Payload slice. Follows same pattern as Go's native append method.
-func (n *Node) appendSubtreePayloads(result []ledger.Payload) []ledger.Payload {
+func (n *Node) appendSubtreePayloads(result []ledger.Payload) ([]ledger.Payload, error) {
 	if n == nil {
-		return result
+		return result, nil
 	}
 	if n.IsLeaf() {
-		return append(result, *n.Payload())
+		payload, err := n.LoadPayload()
+		if err != nil {
+			return nil, err
+		}
+		return append(result, *payload), nil
+	}
+	result, err := n.lChild.appendSubtreePayloads(result)
+	if err != nil {
+		return nil, err
 	}
-	result = n.lChild.appendSubtreePayloads(result)
-	result = n.rChild.appendSubtreePayloads(result)
-	return result
+	return n.rChild.appendSubtreePayloads(result)
 }
@@ -2,6 +2,7 @@ package node_test
 
 import (
 	"encoding/hex"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -100,7 +101,9 @@ func Test_AllPayloads(t *testing.T) {
 	n3 := node.NewLeaf(path, payload, 1)
 	n4 := node.NewInterimNode(1, n1, n2)
 	n5 := node.NewInterimNode(2, n4, n3)
-	require.Equal(t, 3, len(n5.AllPayloads()))
+	payloads, err := n5.AllPayloads()
+	require.NoError(t, err)
+	require.Equal(t, 3, len(payloads))
 }
 
 func Test_VerifyCachedHash(t *testing.T) {
@@ -276,6 +279,55 @@ func Test_Compactify_BothChildrenPopulated(t *testing.T) {
 	require.Equal(t, n5.Hash(), nn5.Hash())
 }
 
+// Test_StoredLeaf verifies that a leaf whose payload is stored in a payload storage has the same hash
+// as a leaf holding its payload in memory, and that its payload is loaded from the storage.
+func Test_StoredLeaf(t *testing.T) {
+	path := utils.PathByUint16(56809)
+	payload := utils.LightPayload(56810, 59656)
+	storage := newMemoryPayloadStorage()
+
+	n := node.NewLeaf(path, payload, 9)
+	sn, err := node.NewStoredLeaf(path, payload, 9, storage)
+	require.NoError(t, err)
+	require.Equal(t, n.Hash(), sn.Hash())
+	require.True(t, sn.VerifyCachedHash())
+	require.True(t, sn.IsPayloadStored())
+	require.False(t, n.IsPayloadStored())
+	require.Len(t, storage.payloads, 1)
+
+	loaded, err := sn.LoadPayload()
+	require.NoError(t, err)
+	require.True(t, payload.Equals(loaded))
+	require.Equal(t, payload.Value.Size(), sn.PayloadValueSize())
+
+	// an in-memory leaf can be converted to a stored leaf
+	cn, err := n.NewStoredNode(storage)
+	require.NoError(t, err)
+	require.True(t, cn.IsPayloadStored())
+	require.Equal(t, n.Hash(), cn.Hash())
+
+	// compactified leaves share the stored payload
+	nn := node.NewInterimCompactifiedNode(10, sn, nil)
+	require.True(t, nn.IsPayloadStored())
+	require.True(t, nn.VerifyCachedHash())
+	require.Equal(t, node.NewLeaf(path, payload, 10).Hash(), nn.Hash())
+
+	ln, err := node.NewLeafFromLeaf(nn, 3)
+	require.NoError(t, err)
+	require.True(t, ln.IsPayloadStored())
+	require.Equal(t, node.NewLeaf(path, payload, 3).Hash(), ln.Hash())
+	require.Len(t, storage.payloads, 1)
+
+	// a payload that can't be loaded is reported
+	delete(storage.payloads, sn.Hash())
+	_, err = sn.LoadPayload()
+	require.Error(t, err)
+	_, err = sn.AllPayloads()
+	require.Error(t, err)
+	require.False(t, sn.VerifyCachedHash())
+	require.NotPanics(t, func() { sn.FmtStr("", "") })
+}
+
 func hashToString(hash hash.Hash) string {
 	return hex.EncodeToString(hash[:])
 }
@@ -295,3 +347,25 @@ func requireIsLeafWithHash(t *testing.T, node *node.Node, expectedHash hash.Hash
 	require.True(t, node.VerifyCachedHash())
 	require.True(t, node.IsLeaf())
 }
+
+// memoryPayloadStorage is a payload storage holding the payloads in a map.
+type memoryPayloadStorage struct {
+	payloads map[hash.Hash]*ledger.Payload
+}
+
+func newMemoryPayloadStorage() *memoryPayloadStorage {
+	return &memoryPayloadStorage{payloads: make(map[hash.Hash]*ledger.Payload)}
+}
+
+func (s *memoryPayloadStorage) Store(key hash.Hash, payload *ledger.Payload) error {
+	s.payloads[key] = payload
+	return nil
+}
+
+func (s *memoryPayloadStorage) Load(key hash.Hash) (*ledger.Payload, error) {
+	payload, ok := s.payloads[key]
+	if !ok {
+		return nil, fmt.Errorf("payload %x not found", key)
+	}
+	return payload, nil
+}
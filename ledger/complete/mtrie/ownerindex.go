@@ -0,0 +1,147 @@
+package mtrie
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+)
+
+// OwnerKeyPartType is the type of the key part which holds the owner of a register.
+// For the execution state, the owner is the address of the account (see state.KeyPartOwner).
+const OwnerKeyPartType = uint16(0)
+
+// ownerIndexBatchSize is the maximum number of registers indexed at once when indexing a trie.
+const ownerIndexBatchSize = 10_000
+
+// OwnerIndex is a persistent index from the owners of registers to the paths of their registers
+// (see ownerindex.OwnerIndex).
+//
+// The index may hold paths of registers which don't exist in a given trie, but it must hold the
+// paths of all registers of the tries recorded with AddTrie.
+type OwnerIndex interface {
+	// Add indexes the paths of registers with the given owners.
+	Add(owners [][]byte, paths []ledger.Path) error
+	// AddTrie records that the registers of the trie with the given root hash are all indexed.
+	AddTrie(rootHash ledger.RootHash) error
+	// HasTrie returns whether the registers of the trie with the given root hash are all indexed.
+	HasTrie(rootHash ledger.RootHash) (bool, error)
+	// OwnerPaths returns the indexed paths of the registers of the given owner.
+	OwnerPaths(owner []byte) ([]ledger.Path, error)
+	// Owners returns the owners of the indexed registers.
+	Owners() ([][]byte, error)
+}
+
+// OwnerOf returns the value of the owner key part of the given key,
+// or nil if the key has no owner key part.
+func OwnerOf(key *ledger.Key) []byte {
+	for _, kp := range key.KeyParts {
+		if kp.Type == OwnerKeyPartType {
+			return kp.Value
+		}
+	}
+	return nil
+}
+
+// indexPayloads indexes the paths of the given registers. Empty payloads are skipped,
+// as a removed register was indexed when it was created.
+func indexPayloads(index OwnerIndex, paths []ledger.Path, payloads []ledger.Payload) error {
+	owners := make([][]byte, 0, len(paths))
+	indexed := make([]ledger.Path, 0, len(paths))
+	for i := range paths {
+		if payloads[i].IsEmpty() {
+			continue
+		}
+		owners = append(owners, OwnerOf(&payloads[i].Key))
+		indexed = append(indexed, paths[i])
+	}
+	return index.Add(owners, indexed)
+}
+
+// ownerIndexer indexes the registers of tries in batches. Subtries indexed by the same indexer
+// are only visited once.
+type ownerIndexer struct {
+	index   OwnerIndex
+	visited map[*node.Node]struct{}
+	owners  [][]byte
+	paths   []ledger.Path
+}
+
+func newOwnerIndexer(index OwnerIndex) *ownerIndexer {
+	return &ownerIndexer{
+		index:   index,
+		visited: make(map[*node.Node]struct{}),
+	}
+}
+
+// indexTrie indexes all registers of the trie, unless the index already holds them, and records the trie as indexed.
+func (i *ownerIndexer) indexTrie(t *trie.MTrie) error {
+	indexed, err := i.index.HasTrie(t.RootHash())
+	if err != nil {
+		return err
+	}
+	if indexed {
+		return nil
+	}
+
+	err = i.indexSubtrie(t.RootNode())
+	if err != nil {
+		return err
+	}
+	err = i.flush()
+	if err != nil {
+		return err
+	}
+	return i.index.AddTrie(t.RootHash())
+}
+
+func (i *ownerIndexer) indexSubtrie(n *node.Node) error {
+	if n == nil {
+		return nil
+	}
+	if _, ok := i.visited[n]; ok {
+		return nil
+	}
+
+	if n.IsLeaf() {
+		payload, err := n.LoadPayload()
+		if err != nil {
+			return fmt.Errorf("cannot load payload of leaf %x: %w", *n.Path(), err)
+		}
+		if payload.IsEmpty() {
+			return nil
+		}
+		i.owners = append(i.owners, OwnerOf(&payload.Key))
+		i.paths = append(i.paths, *n.Path())
+		if len(i.paths) >= ownerIndexBatchSize {
+			return i.flush()
+		}
+		return nil
+	}
+
+	err := i.indexSubtrie(n.LeftChild())
+	if err != nil {
+		return err
+	}
+	err = i.indexSubtrie(n.RightChild())
+	if err != nil {
+		return err
+	}
+	i.visited[n] = struct{}{}
+	return nil
+}
+
+// flush adds the registers collected so far to the index.
+func (i *ownerIndexer) flush() error {
+	if len(i.paths) == 0 {
+		return nil
+	}
+	err := i.index.Add(i.owners, i.paths)
+	if err != nil {
+		return err
+	}
+	i.owners = nil
+	i.paths = nil
+	return nil
+}
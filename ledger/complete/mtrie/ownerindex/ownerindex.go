@@ -0,0 +1,305 @@
+package ownerindex
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/ledger"
+)
+
+const (
+	// codeOwnerPath prefixes the keys of indexed register paths: code, owner length, owner, path.
+	codeOwnerPath = byte(1)
+	// codeIndexedTrie prefixes the keys of the root hashes of the tries whose registers are all indexed.
+	codeIndexedTrie = byte(2)
+)
+
+// pruneBatchSize is the maximum number of keys removed in a single badger write batch.
+const pruneBatchSize = 10_000
+
+// OwnerIndex stores an index from the owners of registers to the paths of their registers in a badger
+// database, along with the root hashes of the tries whose registers are all indexed.
+//
+// The index is kept across restarts, so that the tries loaded from checkpoints aren't indexed again.
+// Paths are only added, and removed by Prune, so paths of the index can refer to registers which don't
+// exist in a given trie.
+type OwnerIndex struct {
+	db *badger.DB
+
+	// pruneLock serializes the removal of keys with the tracking of added keys
+	pruneLock sync.Mutex
+	// addedWhilePruning holds the keys added since pruning started (nil if not pruning)
+	addedWhilePruning map[string]struct{}
+}
+
+// New returns a new owner index backed by the given database.
+func New(db *badger.DB) *OwnerIndex {
+	return &OwnerIndex{
+		db: db,
+	}
+}
+
+func ownerPrefix(owner []byte) []byte {
+	prefix := make([]byte, 3, 3+len(owner)+ledger.PathLen)
+	prefix[0] = codeOwnerPath
+	binary.BigEndian.PutUint16(prefix[1:], uint16(len(owner)))
+	return append(prefix, owner...)
+}
+
+func ownerPathKey(owner []byte, path ledger.Path) []byte {
+	return append(ownerPrefix(owner), path[:]...)
+}
+
+func trieKey(rootHash ledger.RootHash) []byte {
+	return append([]byte{codeIndexedTrie}, rootHash[:]...)
+}
+
+// track records a key added while pruning, so it isn't removed.
+func (idx *OwnerIndex) track(key []byte) {
+	idx.pruneLock.Lock()
+	defer idx.pruneLock.Unlock()
+
+	if idx.addedWhilePruning != nil {
+		idx.addedWhilePruning[string(key)] = struct{}{}
+	}
+}
+
+// Add indexes the paths of registers with the given owners, in a single badger write batch.
+// Concurrency safe.
+func (idx *OwnerIndex) Add(owners [][]byte, paths []ledger.Path) error {
+	if len(owners) != len(paths) {
+		return fmt.Errorf("owners (%d) and paths (%d) don't match", len(owners), len(paths))
+	}
+
+	writes := idx.db.NewWriteBatch()
+	defer writes.Cancel()
+
+	for i := range paths {
+		if len(owners[i]) > math.MaxUint16 {
+			return fmt.Errorf("owner of register %x is too long (%d bytes)", paths[i], len(owners[i]))
+		}
+		key := ownerPathKey(owners[i], paths[i])
+		idx.track(key)
+		err := writes.Set(key, nil)
+		if err != nil {
+			return fmt.Errorf("could not index register %x: %w", paths[i], err)
+		}
+	}
+
+	err := writes.Flush()
+	if err != nil {
+		return fmt.Errorf("could not write owner index batch: %w", err)
+	}
+	return nil
+}
+
+// AddTrie records that the registers of the trie with the given root hash are all indexed.
+// Concurrency safe.
+func (idx *OwnerIndex) AddTrie(rootHash ledger.RootHash) error {
+	key := trieKey(rootHash)
+	idx.track(key)
+
+	err := idx.db.Update(func(tx *badger.Txn) error {
+		return tx.Set(key, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("could not record indexed trie %x: %w", rootHash, err)
+	}
+	return nil
+}
+
+// HasTrie returns whether the registers of the trie with the given root hash are all indexed.
+// Concurrency safe.
+func (idx *OwnerIndex) HasTrie(rootHash ledger.RootHash) (bool, error) {
+	indexed := false
+	err := idx.db.View(func(tx *badger.Txn) error {
+		_, err := tx.Get(trieKey(rootHash))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		indexed = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("could not check indexed trie %x: %w", rootHash, err)
+	}
+	return indexed, nil
+}
+
+// OwnerPaths returns the indexed paths of the registers of the given owner, ordered by path.
+// Concurrency safe.
+func (idx *OwnerIndex) OwnerPaths(owner []byte) ([]ledger.Path, error) {
+	prefix := ownerPrefix(owner)
+
+	var paths []ledger.Path
+	err := idx.db.View(func(tx *badger.Txn) error {
+		it := tx.NewIterator(badger.IteratorOptions{PrefetchValues: false, Prefix: prefix})
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			path, err := ledger.ToPath(it.Item().Key()[len(prefix):])
+			if err != nil {
+				return fmt.Errorf("invalid owner index key: %w", err)
+			}
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read paths of owner %x: %w", owner, err)
+	}
+	return paths, nil
+}
+
+// Owners returns the owners of the indexed registers, ordered by length and value.
+// Concurrency safe.
+func (idx *OwnerIndex) Owners() ([][]byte, error) {
+	prefix := []byte{codeOwnerPath}
+
+	var owners [][]byte
+	err := idx.db.View(func(tx *badger.Txn) error {
+		it := tx.NewIterator(badger.IteratorOptions{PrefetchValues: false, Prefix: prefix})
+		defer it.Close()
+
+		var last []byte
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			key := it.Item().Key()
+			if len(key) < 3 || len(key) != 3+int(binary.BigEndian.Uint16(key[1:3]))+ledger.PathLen {
+				return fmt.Errorf("invalid owner index key %x", key)
+			}
+			owner := key[3 : len(key)-ledger.PathLen]
+
+			// the paths of an owner are contiguous
+			if last != nil && string(owner) == string(last) {
+				continue
+			}
+			last = make([]byte, len(owner))
+			copy(last, owner)
+			owners = append(owners, last)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read owners: %w", err)
+	}
+	return owners, nil
+}
+
+// Prune removes the paths which are not in the paths returned by `live`, and the indexed tries which are not in
+// the tries returned by `live`, and returns the number of removed paths. Keys added after Prune was called are never
+// removed, so `live` must return the paths and root hashes of all tries that were created before it was called.
+// Prune must not be called concurrently with itself, but is concurrency safe otherwise.
+func (idx *OwnerIndex) Prune(live func() (map[ledger.RootHash]struct{}, map[ledger.Path]struct{}, error)) (int, error) {
+	idx.pruneLock.Lock()
+	idx.addedWhilePruning = make(map[string]struct{})
+	idx.pruneLock.Unlock()
+
+	defer func() {
+		idx.pruneLock.Lock()
+		idx.addedWhilePruning = nil
+		idx.pruneLock.Unlock()
+	}()
+
+	liveTries, livePaths, err := live()
+	if err != nil {
+		return 0, fmt.Errorf("could not get live registers: %w", err)
+	}
+
+	var stalePaths, staleTries [][]byte
+	err = idx.db.View(func(tx *badger.Txn) error {
+		it := tx.NewIterator(badger.IteratorOptions{PrefetchValues: false})
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			key := it.Item().KeyCopy(nil)
+			switch key[0] {
+			case codeOwnerPath:
+				path, err := ledger.ToPath(key[len(key)-ledger.PathLen:])
+				if err != nil {
+					return fmt.Errorf("invalid owner index key: %w", err)
+				}
+				if _, ok := livePaths[path]; !ok {
+					stalePaths = append(stalePaths, key)
+				}
+			case codeIndexedTrie:
+				rootHash, err := ledger.ToRootHash(key[1:])
+				if err != nil {
+					return fmt.Errorf("invalid indexed trie key: %w", err)
+				}
+				if _, ok := liveTries[rootHash]; !ok {
+					staleTries = append(staleTries, key)
+				}
+			default:
+				return fmt.Errorf("invalid owner index key %x", key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not find stale owner index entries: %w", err)
+	}
+
+	// tries are removed first, so that a trie is never recorded as indexed without its paths
+	_, err = idx.removeAll(staleTries)
+	if err != nil {
+		return 0, fmt.Errorf("could not remove stale indexed tries: %w", err)
+	}
+	removed, err := idx.removeAll(stalePaths)
+	if err != nil {
+		return removed, fmt.Errorf("could not remove stale paths: %w", err)
+	}
+	return removed, nil
+}
+
+// removeAll removes the given keys in batches of at most pruneBatchSize keys.
+func (idx *OwnerIndex) removeAll(keys [][]byte) (int, error) {
+	removed := 0
+	for len(keys) > 0 {
+		size := pruneBatchSize
+		if len(keys) < size {
+			size = len(keys)
+		}
+
+		n, err := idx.remove(keys[:size])
+		if err != nil {
+			return removed, err
+		}
+		removed += n
+		keys = keys[size:]
+	}
+	return removed, nil
+}
+
+// remove removes the given keys, except the ones added since pruning started, and returns the number of removed keys.
+func (idx *OwnerIndex) remove(keys [][]byte) (int, error) {
+	idx.pruneLock.Lock()
+	defer idx.pruneLock.Unlock()
+
+	writes := idx.db.NewWriteBatch()
+	defer writes.Cancel()
+
+	removed := 0
+	for _, key := range keys {
+		if _, ok := idx.addedWhilePruning[string(key)]; ok {
+			continue
+		}
+		err := writes.Delete(key)
+		if err != nil {
+			return 0, err
+		}
+		removed++
+	}
+
+	err := writes.Flush()
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
@@ -0,0 +1,111 @@
+package ownerindex_test
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/utils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/ownerindex"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestOwnerIndex(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		index := ownerindex.New(db)
+
+		ownerA := []byte{'A'}
+		ownerB := []byte{'B', 'B'}
+		paths := []ledger.Path{utils.PathByUint16(3), utils.PathByUint16(1), utils.PathByUint16(2), utils.PathByUint16(4)}
+		err := index.Add([][]byte{ownerA, ownerA, ownerB, nil}, paths)
+		require.NoError(t, err)
+
+		// paths are only indexed once
+		err = index.Add([][]byte{ownerA}, paths[:1])
+		require.NoError(t, err)
+
+		indexed, err := index.OwnerPaths(ownerA)
+		require.NoError(t, err)
+		require.Equal(t, []ledger.Path{paths[1], paths[0]}, indexed)
+
+		indexed, err = index.OwnerPaths(ownerB)
+		require.NoError(t, err)
+		require.Equal(t, []ledger.Path{paths[2]}, indexed)
+
+		// owners which are prefixes of other owners are distinct
+		indexed, err = index.OwnerPaths([]byte{'B'})
+		require.NoError(t, err)
+		require.Empty(t, indexed)
+
+		// registers without owner are indexed under the empty owner
+		indexed, err = index.OwnerPaths(nil)
+		require.NoError(t, err)
+		require.Equal(t, []ledger.Path{paths[3]}, indexed)
+
+		owners, err := index.Owners()
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{{}, ownerA, ownerB}, owners)
+
+		rootHash := ledger.RootHash(unittest.StateCommitmentFixture())
+		has, err := index.HasTrie(rootHash)
+		require.NoError(t, err)
+		require.False(t, has)
+
+		err = index.AddTrie(rootHash)
+		require.NoError(t, err)
+		has, err = index.HasTrie(rootHash)
+		require.NoError(t, err)
+		require.True(t, has)
+
+		// the index is kept in the database
+		reopened := ownerindex.New(db)
+		indexed, err = reopened.OwnerPaths(ownerA)
+		require.NoError(t, err)
+		require.Equal(t, []ledger.Path{paths[1], paths[0]}, indexed)
+		has, err = reopened.HasTrie(rootHash)
+		require.NoError(t, err)
+		require.True(t, has)
+
+		err = index.Add([][]byte{ownerA}, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestOwnerIndexPrune(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		index := ownerindex.New(db)
+
+		owner := []byte{'A'}
+		paths := []ledger.Path{utils.PathByUint16(1), utils.PathByUint16(2), utils.PathByUint16(3)}
+		err := index.Add([][]byte{owner, owner, owner}, paths)
+		require.NoError(t, err)
+
+		liveRoot := ledger.RootHash(unittest.StateCommitmentFixture())
+		staleRoot := ledger.RootHash(unittest.StateCommitmentFixture())
+		require.NoError(t, index.AddTrie(liveRoot))
+		require.NoError(t, index.AddTrie(staleRoot))
+
+		// a register and a trie added while pruning are kept
+		addedRoot := ledger.RootHash(unittest.StateCommitmentFixture())
+		addedPath := utils.PathByUint16(4)
+		removed, err := index.Prune(func() (map[ledger.RootHash]struct{}, map[ledger.Path]struct{}, error) {
+			require.NoError(t, index.Add([][]byte{owner}, []ledger.Path{addedPath}))
+			require.NoError(t, index.AddTrie(addedRoot))
+			return map[ledger.RootHash]struct{}{liveRoot: {}}, map[ledger.Path]struct{}{paths[1]: {}}, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, removed)
+
+		indexed, err := index.OwnerPaths(owner)
+		require.NoError(t, err)
+		require.Equal(t, []ledger.Path{paths[1], addedPath}, indexed)
+
+		for rootHash, expected := range map[ledger.RootHash]bool{liveRoot: true, staleRoot: false, addedRoot: true} {
+			has, err := index.HasTrie(rootHash)
+			require.NoError(t, err)
+			require.Equal(t, expected, has)
+		}
+	})
+}
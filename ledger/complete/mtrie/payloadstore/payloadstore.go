@@ -0,0 +1,306 @@
+package payloadstore
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/dgraph-io/badger/v2"
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/encoding"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+)
+
+// DefaultCacheSize is the default number of payloads kept in memory.
+const DefaultCacheSize = 100_000
+
+// ErrNotFound is returned when no payload is stored under a leaf hash.
+var ErrNotFound = errors.New("payload not found")
+
+// PayloadStore stores the payloads of trie leaves in a badger database, keyed by the leaf hash.
+// The payloads of the most recently used leaves are cached in memory.
+//
+// As a leaf hash commits to the leaf's path and payload, a key always references the same payload.
+// Hence, payloads stored by earlier runs can be kept in the database, and a payload stored for
+// several leaves with the same hash is only written once.
+// Leaves can be shared between tries and there is no reference counting of leaves, so payloads
+// are only removed from the database by Prune, which removes the payloads no trie references anymore.
+type PayloadStore struct {
+	db    *badger.DB
+	cache *lru.Cache
+
+	// pruneLock serializes the removal of payloads with the tracking of stored payloads
+	pruneLock sync.Mutex
+	// storedWhilePruning holds the keys of payloads stored since pruning started (nil if not pruning)
+	storedWhilePruning map[hash.Hash]struct{}
+}
+
+var _ node.BatchPayloadStorage = (*PayloadStore)(nil)
+
+// New returns a new payload store backed by the given database, which caches up to `cacheSize` payloads.
+func New(db *badger.DB, cacheSize int) (*PayloadStore, error) {
+	cache, err := lru.New(cacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create payload cache: %w", err)
+	}
+
+	return &PayloadStore{
+		db:    db,
+		cache: cache,
+	}, nil
+}
+
+// Store stores the payload under the given leaf hash.
+// Concurrency safe.
+func (s *PayloadStore) Store(key hash.Hash, payload *ledger.Payload) error {
+	s.track(key)
+	if s.cache.Contains(key) {
+		return nil
+	}
+
+	err := s.db.Update(func(tx *badger.Txn) error {
+		return tx.Set(key[:], encoding.EncodePayload(payload))
+	})
+	if err != nil {
+		return fmt.Errorf("could not store payload: %w", err)
+	}
+
+	s.cache.Add(key, payload)
+	return nil
+}
+
+// NewBatch returns a batch of payloads, which are written to the store in a single badger write batch.
+func (s *PayloadStore) NewBatch() node.PayloadBatch {
+	return &Batch{
+		store:    s,
+		writes:   s.db.NewWriteBatch(),
+		payloads: make(map[hash.Hash]*ledger.Payload),
+	}
+}
+
+// track records the key of a stored payload while pruning, so the payload isn't removed. Keys must
+// be tracked before checking whether the payload is cached, as pruning removes payloads from the cache.
+func (s *PayloadStore) track(key hash.Hash) {
+	s.pruneLock.Lock()
+	defer s.pruneLock.Unlock()
+
+	if s.storedWhilePruning != nil {
+		s.storedWhilePruning[key] = struct{}{}
+	}
+}
+
+// Load returns the payload stored under the given leaf hash, or ErrNotFound if there is none.
+// Concurrency safe.
+// Do NOT MODIFY the returned payload!
+func (s *PayloadStore) Load(key hash.Hash) (*ledger.Payload, error) {
+	if cached, ok := s.cache.Get(key); ok {
+		return cached.(*ledger.Payload), nil
+	}
+
+	var payload *ledger.Payload
+	err := s.db.View(func(tx *badger.Txn) error {
+		item, err := tx.Get(key[:])
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// the value is only valid during the transaction and decoding doesn't copy it
+		val, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		payload, err = encoding.DecodePayload(val)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not load payload %x: %w", key, err)
+	}
+
+	s.cache.Add(key, payload)
+	return payload, nil
+}
+
+// pruneBatchSize is the maximum number of payloads removed in a single badger write batch.
+const pruneBatchSize = 10_000
+
+// Prune removes all payloads from the store that are not referenced by the keys returned by `liveKeys`,
+// and returns the number of removed payloads. Payloads stored after Prune was called are never removed,
+// so `liveKeys` must return the keys of all tries that were created before it was called.
+// Prune must not be called concurrently with itself, but is concurrency safe otherwise.
+func (s *PayloadStore) Prune(liveKeys func() (map[hash.Hash]struct{}, error)) (int, error) {
+	s.pruneLock.Lock()
+	s.storedWhilePruning = make(map[hash.Hash]struct{})
+	s.pruneLock.Unlock()
+
+	defer func() {
+		s.pruneLock.Lock()
+		s.storedWhilePruning = nil
+		s.pruneLock.Unlock()
+	}()
+
+	live, err := liveKeys()
+	if err != nil {
+		return 0, fmt.Errorf("could not get live payload keys: %w", err)
+	}
+
+	var unreferenced []hash.Hash
+	err = s.db.View(func(tx *badger.Txn) error {
+		it := tx.NewIterator(badger.IteratorOptions{PrefetchValues: false})
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			key, err := hash.ToHash(it.Item().Key())
+			if err != nil {
+				return fmt.Errorf("invalid payload key: %w", err)
+			}
+			if _, ok := live[key]; !ok {
+				unreferenced = append(unreferenced, key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not find unreferenced payloads: %w", err)
+	}
+
+	removed := 0
+	for len(unreferenced) > 0 {
+		size := pruneBatchSize
+		if len(unreferenced) < size {
+			size = len(unreferenced)
+		}
+
+		n, err := s.remove(unreferenced[:size])
+		if err != nil {
+			return removed, fmt.Errorf("could not remove unreferenced payloads: %w", err)
+		}
+		removed += n
+		unreferenced = unreferenced[size:]
+	}
+
+	return removed, nil
+}
+
+// remove removes the payloads with the given keys, except the ones stored since pruning started,
+// and returns the number of removed payloads.
+func (s *PayloadStore) remove(keys []hash.Hash) (int, error) {
+	s.pruneLock.Lock()
+	defer s.pruneLock.Unlock()
+
+	writes := s.db.NewWriteBatch()
+	defer writes.Cancel()
+
+	removed := 0
+	for i := range keys {
+		key := keys[i]
+		if _, ok := s.storedWhilePruning[key]; ok {
+			continue
+		}
+		// the write batch keeps the key slice until it's written, so it must not reference the loop variable
+		err := writes.Delete(keys[i][:])
+		if err != nil {
+			return 0, err
+		}
+		s.cache.Remove(key)
+		removed++
+	}
+
+	err := writes.Flush()
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// Batch is a batch of payloads, which are written to the payload store in a single badger write batch.
+type Batch struct {
+	store    *PayloadStore
+	mu       sync.Mutex
+	writes   *badger.WriteBatch
+	payloads map[hash.Hash]*ledger.Payload // payloads written by the batch, added to the cache once flushed
+}
+
+var _ node.PayloadBatch = (*Batch)(nil)
+
+// Store adds the payload to the batch, unless it is already cached by the payload store.
+// Concurrency safe.
+func (b *Batch) Store(key hash.Hash, payload *ledger.Payload) error {
+	b.store.track(key)
+	if b.store.cache.Contains(key) {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.writes == nil {
+		return fmt.Errorf("payload batch was already flushed or cancelled")
+	}
+	if _, ok := b.payloads[key]; ok {
+		return nil
+	}
+
+	err := b.writes.Set(key[:], encoding.EncodePayload(payload))
+	if err != nil {
+		return fmt.Errorf("could not add payload to batch: %w", err)
+	}
+	b.payloads[key] = payload
+	return nil
+}
+
+// Load returns the payload stored under the given leaf hash, which is either in the batch or in the payload store.
+// Concurrency safe.
+// Do NOT MODIFY the returned payload!
+func (b *Batch) Load(key hash.Hash) (*ledger.Payload, error) {
+	b.mu.Lock()
+	payload, ok := b.payloads[key]
+	b.mu.Unlock()
+
+	if ok {
+		return payload, nil
+	}
+	return b.store.Load(key)
+}
+
+// Flush writes the payloads of the batch to the payload store.
+// Concurrency safe.
+func (b *Batch) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.writes == nil {
+		return fmt.Errorf("payload batch was already flushed or cancelled")
+	}
+
+	err := b.writes.Flush()
+	b.writes = nil
+	if err != nil {
+		return fmt.Errorf("could not write payload batch: %w", err)
+	}
+
+	for key, payload := range b.payloads {
+		b.store.cache.Add(key, payload)
+	}
+	b.payloads = nil
+	return nil
+}
+
+// Cancel discards the payloads of the batch, unless it was flushed already.
+// Concurrency safe.
+func (b *Batch) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.writes == nil {
+		return
+	}
+	b.writes.Cancel()
+	b.writes = nil
+	b.payloads = nil
+}
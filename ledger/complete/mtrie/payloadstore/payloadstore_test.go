@@ -0,0 +1,157 @@
+package payloadstore_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/common/utils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/payloadstore"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestPayloadStore(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		// the cache only holds one payload, so the other payloads are loaded from the database
+		store, err := payloadstore.New(db, 1)
+		require.NoError(t, err)
+
+		payloads := utils.RandomPayloads(10, 2, 100)
+		keys := make([]hash.Hash, len(payloads))
+		for i, payload := range payloads {
+			keys[i] = hash.Hash(utils.PathByUint16(uint16(i)))
+			err := store.Store(keys[i], payload)
+			require.NoError(t, err)
+		}
+
+		for i, key := range keys {
+			payload, err := store.Load(key)
+			require.NoError(t, err)
+			require.True(t, payloads[i].Equals(payload))
+		}
+
+		// payloads are kept in the database across stores
+		reopened, err := payloadstore.New(db, 1)
+		require.NoError(t, err)
+		payload, err := reopened.Load(keys[0])
+		require.NoError(t, err)
+		require.True(t, payloads[0].Equals(payload))
+
+		_, err = store.Load(hash.Hash(utils.PathByUint16(uint16(len(keys)))))
+		require.True(t, errors.Is(err, payloadstore.ErrNotFound))
+	})
+}
+
+func TestPayloadStoreBatch(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		store, err := payloadstore.New(db, 1)
+		require.NoError(t, err)
+
+		payloads := utils.RandomPayloads(10, 2, 100)
+		keys := make([]hash.Hash, len(payloads))
+		batch := store.NewBatch()
+		for i, payload := range payloads {
+			keys[i] = hash.Hash(utils.PathByUint16(uint16(i)))
+			err := batch.Store(keys[i], payload)
+			require.NoError(t, err)
+		}
+
+		// payloads of the batch are only written to the database when the batch is flushed
+		for i, key := range keys {
+			_, err := store.Load(key)
+			require.True(t, errors.Is(err, payloadstore.ErrNotFound))
+
+			payload, err := batch.Load(key)
+			require.NoError(t, err)
+			require.True(t, payloads[i].Equals(payload))
+		}
+
+		err = batch.Flush()
+		require.NoError(t, err)
+
+		for i, key := range keys {
+			payload, err := store.Load(key)
+			require.NoError(t, err)
+			require.True(t, payloads[i].Equals(payload))
+
+			payload, err = batch.Load(key)
+			require.NoError(t, err)
+			require.True(t, payloads[i].Equals(payload))
+		}
+		require.Error(t, batch.Flush())
+
+		// payloads of cancelled batches are discarded
+		cancelled := store.NewBatch()
+		key := hash.Hash(utils.PathByUint16(uint16(len(keys))))
+		err = cancelled.Store(key, payloads[0])
+		require.NoError(t, err)
+		cancelled.Cancel()
+
+		_, err = store.Load(key)
+		require.True(t, errors.Is(err, payloadstore.ErrNotFound))
+		require.Error(t, cancelled.Store(key, payloads[0]))
+	})
+}
+
+func TestPayloadStorePrune(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		store, err := payloadstore.New(db, 100)
+		require.NoError(t, err)
+
+		payloads := utils.RandomPayloads(10, 2, 100)
+		keys := make([]hash.Hash, len(payloads))
+		for i, payload := range payloads {
+			keys[i] = hash.Hash(utils.PathByUint16(uint16(i)))
+			err := store.Store(keys[i], payload)
+			require.NoError(t, err)
+		}
+
+		// the first half of the payloads is live, the second half is stored while pruning
+		removed, err := store.Prune(func() (map[hash.Hash]struct{}, error) {
+			live := make(map[hash.Hash]struct{})
+			for _, key := range keys[:5] {
+				live[key] = struct{}{}
+			}
+
+			for i, key := range keys[5:8] {
+				err := store.Store(key, payloads[5+i])
+				require.NoError(t, err)
+			}
+			return live, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, removed)
+
+		for i, key := range keys[:8] {
+			payload, err := store.Load(key)
+			require.NoError(t, err)
+			require.True(t, payloads[i].Equals(payload))
+		}
+		for _, key := range keys[8:] {
+			_, err := store.Load(key)
+			require.True(t, errors.Is(err, payloadstore.ErrNotFound))
+		}
+
+		// payloads stored during an earlier pruning can be removed
+		removed, err = store.Prune(func() (map[hash.Hash]struct{}, error) {
+			return map[hash.Hash]struct{}{keys[0]: {}}, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 7, removed)
+
+		_, err = store.Load(keys[0])
+		require.NoError(t, err)
+		_, err = store.Load(keys[1])
+		require.True(t, errors.Is(err, payloadstore.ErrNotFound))
+
+		// removed payloads can be stored again
+		err = store.Store(keys[1], payloads[1])
+		require.NoError(t, err)
+		payload, err := store.Load(keys[1])
+		require.NoError(t, err)
+		require.True(t, payloads[1].Equals(payload))
+	})
+}
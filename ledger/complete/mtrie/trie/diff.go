@@ -0,0 +1,122 @@
+package trie
+
+import (
+	"bytes"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+)
+
+// PayloadDiff is a register whose payload differs between two tries.
+type PayloadDiff struct {
+	Path ledger.Path
+	// Before is the payload in the first trie, or nil if the register was added.
+	Before *ledger.Payload
+	// After is the payload in the second trie, or nil if the register was removed.
+	After *ledger.Payload
+}
+
+// IsAdded returns true if the register is only in the second trie.
+func (d PayloadDiff) IsAdded() bool {
+	return d.Before == nil
+}
+
+// IsRemoved returns true if the register is only in the first trie.
+func (d PayloadDiff) IsRemoved() bool {
+	return d.After == nil
+}
+
+// Diff returns the registers whose payloads differ between the `before` and `after` tries, ordered by path.
+// Both tries are walked in parallel, and subtries with the same hash are skipped.
+// Registers with an empty value are reported like any other register, as they are stored in the trie
+// if the trie was updated without pruning.
+// Do NOT MODIFY the returned payloads!
+func Diff(before, after *MTrie) ([]PayloadDiff, error) {
+	return diff(nil, before.root, after.root)
+}
+
+// diff appends the registers whose payloads differ between the subtries with roots `a` and `b`,
+// which must be at the same position in their tries, to `result`.
+func diff(result []PayloadDiff, a, b *node.Node) ([]PayloadDiff, error) {
+	// identical subtries, which includes both subtries being empty
+	if a == b {
+		return result, nil
+	}
+	if a != nil && b != nil && a.Hash() == b.Hash() {
+		return result, nil
+	}
+
+	// both subtries have children: compare them pairwise
+	if a != nil && !a.IsLeaf() && b != nil && !b.IsLeaf() {
+		result, err := diff(result, a.LeftChild(), b.LeftChild())
+		if err != nil {
+			return nil, err
+		}
+		return diff(result, a.RightChild(), b.RightChild())
+	}
+
+	// At least one subtrie is empty or a compactified leaf, which can correspond to any
+	// leaf in the other subtrie. Merge the leaves of both subtries by path.
+	aLeaves := appendLeaves(nil, a)
+	bLeaves := appendLeaves(nil, b)
+
+	i, j := 0, 0
+	for i < len(aLeaves) || j < len(bLeaves) {
+		var cmp int
+		switch {
+		case i == len(aLeaves):
+			cmp = 1
+		case j == len(bLeaves):
+			cmp = -1
+		default:
+			cmp = bytes.Compare(aLeaves[i].Path()[:], bLeaves[j].Path()[:])
+		}
+
+		switch {
+		case cmp < 0:
+			payload, err := aLeaves[i].LoadPayload()
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, PayloadDiff{Path: *aLeaves[i].Path(), Before: payload})
+			i++
+		case cmp > 0:
+			payload, err := bLeaves[j].LoadPayload()
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, PayloadDiff{Path: *bLeaves[j].Path(), After: payload})
+			j++
+		default:
+			// compactified leaves at different heights have different hashes,
+			// so the payloads need to be compared
+			before, err := aLeaves[i].LoadPayload()
+			if err != nil {
+				return nil, err
+			}
+			after, err := bLeaves[j].LoadPayload()
+			if err != nil {
+				return nil, err
+			}
+			if !before.Equals(after) {
+				result = append(result, PayloadDiff{Path: *aLeaves[i].Path(), Before: before, After: after})
+			}
+			i++
+			j++
+		}
+	}
+
+	return result, nil
+}
+
+// appendLeaves appends the leaves of the subtrie with root `n` to `leaves`, ordered by path.
+func appendLeaves(leaves []*node.Node, n *node.Node) []*node.Node {
+	if n == nil {
+		return leaves
+	}
+	if n.IsLeaf() {
+		return append(leaves, n)
+	}
+	leaves = appendLeaves(leaves, n.LeftChild())
+	return appendLeaves(leaves, n.RightChild())
+}
@@ -0,0 +1,135 @@
+package trie_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/utils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+)
+
+func Test_Diff(t *testing.T) {
+	paths := utils.RandomPaths(100)
+	payloads := make([]ledger.Payload, len(paths))
+	for i, p := range utils.RandomPayloads(len(paths), 2, 100) {
+		payloads[i] = *p
+	}
+
+	baseTrie, _, err := trie.NewTrieWithUpdatedRegisters(trie.NewEmptyMTrie(), paths, payloads, true)
+	require.NoError(t, err)
+
+	// change 10 registers, remove 5 registers and add 10 registers
+	changedPaths := append([]ledger.Path{}, paths[:15]...)
+	changedPayloads := make([]ledger.Payload, 0, 25)
+	for _, p := range utils.RandomPayloads(10, 2, 100) {
+		changedPayloads = append(changedPayloads, *p)
+	}
+	for i := 0; i < 5; i++ {
+		changedPayloads = append(changedPayloads, *ledger.EmptyPayload())
+	}
+	addedPaths := utils.RandomPaths(10)
+	changedPaths = append(changedPaths, addedPaths...)
+	for _, p := range utils.RandomPayloads(10, 2, 100) {
+		changedPayloads = append(changedPayloads, *p)
+	}
+
+	// updating the trie permutes the paths and payloads
+	updatedTrie, _, err := trie.NewTrieWithUpdatedRegisters(
+		baseTrie,
+		append([]ledger.Path{}, changedPaths...),
+		append([]ledger.Payload{}, changedPayloads...),
+		true,
+	)
+	require.NoError(t, err)
+
+	t.Run("identical tries", func(t *testing.T) {
+		diffs, err := trie.Diff(baseTrie, baseTrie)
+		require.NoError(t, err)
+		require.Empty(t, diffs)
+
+		diffs, err = trie.Diff(trie.NewEmptyMTrie(), trie.NewEmptyMTrie())
+		require.NoError(t, err)
+		require.Empty(t, diffs)
+	})
+
+	t.Run("empty trie", func(t *testing.T) {
+		diffs, err := trie.Diff(trie.NewEmptyMTrie(), baseTrie)
+		require.NoError(t, err)
+		require.Len(t, diffs, len(paths))
+		for _, d := range diffs {
+			require.True(t, d.IsAdded())
+			require.False(t, d.IsRemoved())
+		}
+
+		diffs, err = trie.Diff(baseTrie, trie.NewEmptyMTrie())
+		require.NoError(t, err)
+		require.Len(t, diffs, len(paths))
+		for _, d := range diffs {
+			require.True(t, d.IsRemoved())
+		}
+	})
+
+	t.Run("updated trie", func(t *testing.T) {
+		diffs, err := trie.Diff(baseTrie, updatedTrie)
+		require.NoError(t, err)
+		require.Len(t, diffs, 25)
+
+		byPath := make(map[ledger.Path]trie.PayloadDiff)
+		for i, d := range diffs {
+			byPath[d.Path] = d
+			if i > 0 {
+				require.Equal(t, -1, bytes.Compare(diffs[i-1].Path[:], d.Path[:]), "diffs are ordered by path")
+			}
+		}
+
+		for i := 0; i < 10; i++ {
+			d := byPath[paths[i]]
+			require.True(t, payloads[i].Equals(d.Before))
+			require.True(t, changedPayloads[i].Equals(d.After))
+		}
+		for i := 10; i < 15; i++ {
+			d := byPath[paths[i]]
+			require.True(t, d.IsRemoved())
+			require.True(t, payloads[i].Equals(d.Before))
+		}
+		for i, path := range addedPaths {
+			d := byPath[path]
+			require.True(t, d.IsAdded())
+			require.True(t, changedPayloads[15+i].Equals(d.After))
+		}
+
+		// the reverse diff swaps added and removed registers
+		reverse, err := trie.Diff(updatedTrie, baseTrie)
+		require.NoError(t, err)
+		require.Len(t, reverse, len(diffs))
+		for i, d := range reverse {
+			require.Equal(t, diffs[i].Path, d.Path)
+			require.Equal(t, diffs[i].Before, d.After)
+			require.Equal(t, diffs[i].After, d.Before)
+		}
+	})
+
+	t.Run("compactified leaf", func(t *testing.T) {
+		// key: 0000...
+		p1 := utils.PathByUint8(1)
+		v1 := utils.LightPayload8('A', 'a')
+
+		// key: 0100....
+		p2 := utils.PathByUint8(64)
+		v2 := utils.LightPayload8('B', 'b')
+
+		singleLeafTrie, _, err := trie.NewTrieWithUpdatedRegisters(trie.NewEmptyMTrie(), []ledger.Path{p1}, []ledger.Payload{*v1}, true)
+		require.NoError(t, err)
+
+		// the leaf of p1 is moved down, which changes its hash but not its payload
+		twoLeavesTrie, _, err := trie.NewTrieWithUpdatedRegisters(singleLeafTrie, []ledger.Path{p2}, []ledger.Payload{*v2}, true)
+		require.NoError(t, err)
+
+		diffs, err := trie.Diff(singleLeafTrie, twoLeavesTrie)
+		require.NoError(t, err)
+		require.Equal(t, []trie.PayloadDiff{{Path: p2, After: v2}}, diffs)
+	})
+}
@@ -30,10 +30,15 @@ import (
 //   * HEIGHT of a node v in a tree is the number of edges on the longest downward path
 //     between v and a tree leaf. The height of a tree is the height of its root.
 //     The height of a Trie is always the height of the fully-expanded tree.
+//
+// The payloads of the trie's leaves are either held in memory, or, if the trie has a payload
+// storage, stored in the payload storage. Tries derived from a trie through updates inherit
+// its payload storage.
 type MTrie struct {
-	root     *node.Node
-	regCount uint64 // number of registers allocated in the trie
-	regSize  uint64 // size of registers allocated in the trie
+	root           *node.Node
+	regCount       uint64              // number of registers allocated in the trie
+	regSize        uint64              // size of registers allocated in the trie
+	payloadStorage node.PayloadStorage // storage of the leaf payloads (nil if payloads are held in memory)
 }
 
 // NewEmptyMTrie returns an empty Mtrie (root is nil)
@@ -41,6 +46,12 @@ func NewEmptyMTrie() *MTrie {
 	return &MTrie{root: nil}
 }
 
+// NewEmptyMTrieWithPayloadStorage returns an empty Mtrie (root is nil), whose leaf payloads
+// are stored in the given payload storage.
+func NewEmptyMTrieWithPayloadStorage(storage node.PayloadStorage) *MTrie {
+	return &MTrie{root: nil, payloadStorage: storage}
+}
+
 // IsEmpty checks if a trie is empty.
 //
 // An empty try doesn't mean a trie with no allocated registers.
@@ -60,6 +71,116 @@ func NewMTrie(root *node.Node, regCount uint64, regSize uint64) (*MTrie, error)
 	}, nil
 }
 
+// NewMTrieWithStoredPayloads returns a Mtrie given the root, whose leaf payloads are stored in the given
+// payload storage.
+// UNCHECKED requirement: all leaves of the trie must store their payloads in the given storage, e.g. leaves
+// created by NewStoredNode with the storage or one of its batches.
+func NewMTrieWithStoredPayloads(root *node.Node, regCount uint64, regSize uint64, storage node.PayloadStorage) (*MTrie, error) {
+	mt, err := NewMTrie(root, regCount, regSize)
+	if err != nil {
+		return nil, err
+	}
+	mt.payloadStorage = storage
+	return mt, nil
+}
+
+// NewMTrieWithPayloadStorage returns a trie with the same registers as the given trie, whose
+// leaf payloads are stored in the given payload storage. Leaves whose payload is held in memory
+// are replaced by leaves whose payload is stored, all node hashes are kept as they are.
+// Nodes that are replaced are recorded in `replaced`, so nodes shared by several tries are only
+// replaced once when the map is shared between calls.
+// CAUTION: `replaced` is not concurrency safe.
+func NewMTrieWithPayloadStorage(mt *MTrie, storage node.PayloadStorage, replaced map[*node.Node]*node.Node) (*MTrie, error) {
+	leafStorage, batch := newPayloadBatch(storage)
+	root, err := withStoredPayloads(mt.root, leafStorage, replaced)
+	if err == nil {
+		err = flushPayloadBatch(batch)
+	} else {
+		cancelPayloadBatch(batch)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not store payloads of trie %x: %w", mt.RootHash(), err)
+	}
+	return &MTrie{
+		root:           root,
+		regCount:       mt.regCount,
+		regSize:        mt.regSize,
+		payloadStorage: storage,
+	}, nil
+}
+
+// withStoredPayloads returns the subtrie with `head` as root node, where all leaf payloads
+// are stored in the given payload storage.
+func withStoredPayloads(head *node.Node, storage node.PayloadStorage, replaced map[*node.Node]*node.Node) (*node.Node, error) {
+	if head == nil {
+		return nil, nil
+	}
+	if n, ok := replaced[head]; ok {
+		return n, nil
+	}
+
+	var n *node.Node
+	var err error
+	if head.IsLeaf() {
+		n, err = head.NewStoredNode(storage)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		lChild, err := withStoredPayloads(head.LeftChild(), storage, replaced)
+		if err != nil {
+			return nil, err
+		}
+		rChild, err := withStoredPayloads(head.RightChild(), storage, replaced)
+		if err != nil {
+			return nil, err
+		}
+		n = head
+		if lChild != head.LeftChild() || rChild != head.RightChild() {
+			n = node.NewNode(head.Height(), lChild, rChild, ledger.DummyPath, nil, head.Hash())
+		}
+	}
+
+	replaced[head] = n
+	return n, nil
+}
+
+// newPayloadBatch returns the storage new leaves store their payloads in. If the payload storage
+// can write payloads in batches, it is a new batch of the storage, which is also returned and must
+// be flushed or cancelled once all leaves are created. Otherwise, it is the storage itself.
+func newPayloadBatch(storage node.PayloadStorage) (node.PayloadStorage, node.PayloadBatch) {
+	batchStorage, ok := storage.(node.BatchPayloadStorage)
+	if !ok {
+		return storage, nil
+	}
+	batch := batchStorage.NewBatch()
+	return batch, batch
+}
+
+// flushPayloadBatch writes the payloads of the batch to its payload storage. The batch may be nil.
+func flushPayloadBatch(batch node.PayloadBatch) error {
+	if batch == nil {
+		return nil
+	}
+	err := batch.Flush()
+	if err != nil {
+		return fmt.Errorf("could not write payload batch: %w", err)
+	}
+	return nil
+}
+
+// cancelPayloadBatch discards the payloads of the batch. The batch may be nil.
+func cancelPayloadBatch(batch node.PayloadBatch) {
+	if batch != nil {
+		batch.Cancel()
+	}
+}
+
+// PayloadStorage returns the storage of the trie's leaf payloads, or nil if the payloads are held in memory.
+func (mt *MTrie) PayloadStorage() node.PayloadStorage {
+	return mt.payloadStorage
+}
+
 // RootHash returns the trie's root hash.
 // Concurrency safe (as Tries are immutable structures by convention)
 func (mt *MTrie) RootHash() ledger.RootHash {
@@ -133,10 +254,7 @@ func valueSizes(sizes []int, paths []ledger.Path, head *node.Node) {
 	if head.IsLeaf() {
 		for i, p := range paths {
 			if *head.Path() == p {
-				payload := head.Payload()
-				if payload != nil {
-					sizes[i] = payload.Value.Size()
-				}
+				sizes[i] = head.PayloadValueSize()
 				// NOTE: break isn't used here because precondition
 				// doesn't require paths being deduplicated.
 			}
@@ -203,11 +321,15 @@ func valueSizes(sizes []int, paths []ledger.Path, head *node.Node) {
 //     For each path, the corresponding payload is written into payloads. AFTER
 //     the read operation completes, the order of `path` and `payloads` are such that
 //     for `path[i]` the corresponding register value is referenced by 0`payloads[i]`.
+//  * error if a stored payload could not be loaded
 // TODO move consistency checks from Forest into Trie to obtain a safe, self-contained API
-func (mt *MTrie) UnsafeRead(paths []ledger.Path) []*ledger.Payload {
+func (mt *MTrie) UnsafeRead(paths []ledger.Path) ([]*ledger.Payload, error) {
 	payloads := make([]*ledger.Payload, len(paths)) // pre-allocate slice for the result
-	read(payloads, paths, mt.root)
-	return payloads
+	err := read(payloads, paths, mt.root)
+	if err != nil {
+		return nil, err
+	}
+	return payloads, nil
 }
 
 // read reads all the registers in subtree with `head` as root node. For each
@@ -215,10 +337,10 @@ func (mt *MTrie) UnsafeRead(paths []ledger.Path) []*ledger.Payload {
 // CAUTION:
 //  * while reading the payloads, `paths` is permuted IN-PLACE for optimized processing.
 //  * unchecked requirement: all paths must go through the `head` node
-func read(payloads []*ledger.Payload, paths []ledger.Path, head *node.Node) {
+func read(payloads []*ledger.Payload, paths []ledger.Path, head *node.Node) error {
 	// check for empty paths
 	if len(paths) == 0 {
-		return
+		return nil
 	}
 
 	// path not found
@@ -226,18 +348,22 @@ func read(payloads []*ledger.Payload, paths []ledger.Path, head *node.Node) {
 		for i := range paths {
 			payloads[i] = ledger.EmptyPayload()
 		}
-		return
+		return nil
 	}
 	// reached a leaf node
 	if head.IsLeaf() {
 		for i, p := range paths {
 			if *head.Path() == p {
-				payloads[i] = head.Payload()
+				payload, err := head.LoadPayload()
+				if err != nil {
+					return err
+				}
+				payloads[i] = payload
 			} else {
 				payloads[i] = ledger.EmptyPayload()
 			}
 		}
-		return
+		return nil
 	}
 
 	// partition step to quick sort the paths:
@@ -251,19 +377,27 @@ func read(payloads []*ledger.Payload, paths []ledger.Path, head *node.Node) {
 	// read values from left and right subtrees in parallel
 	parallelRecursionThreshold := 32 // threshold to avoid the parallelization going too deep in the recursion
 	if len(lpaths) < parallelRecursionThreshold || len(rpaths) < parallelRecursionThreshold {
-		read(lpayloads, lpaths, head.LeftChild())
-		read(rpayloads, rpaths, head.RightChild())
-	} else {
-		// concurrent read of left and right subtree
-		wg := sync.WaitGroup{}
-		wg.Add(1)
-		go func() {
-			read(lpayloads, lpaths, head.LeftChild())
-			wg.Done()
-		}()
-		read(rpayloads, rpaths, head.RightChild())
-		wg.Wait() // wait for all threads
-	}
+		err := read(lpayloads, lpaths, head.LeftChild())
+		if err != nil {
+			return err
+		}
+		return read(rpayloads, rpaths, head.RightChild())
+	}
+
+	// concurrent read of left and right subtree
+	var lErr error
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		lErr = read(lpayloads, lpaths, head.LeftChild())
+		wg.Done()
+	}()
+	rErr := read(rpayloads, rpaths, head.RightChild())
+	wg.Wait() // wait for all threads
+	if lErr != nil {
+		return lErr
+	}
+	return rErr
 }
 
 // NewTrieWithUpdatedRegisters constructs a new trie containing all registers from the parent trie,
@@ -279,6 +413,8 @@ func read(payloads []*ledger.Payload, paths []ledger.Path, head *node.Node) {
 //   * keys are NOT duplicated
 //   * requires _all_ paths to have a length of mt.Height bits.
 // CAUTION: `updatedPaths` and `updatedPayloads` are permuted IN-PLACE for optimized processing.
+// The updated trie stores its new leaf payloads in the payload storage of the parent trie, if any.
+// If the payload storage is a node.BatchPayloadStorage, the payloads are written in a single batch.
 // TODO: move consistency checks from MForest to here, to make API safe and self-contained
 func NewTrieWithUpdatedRegisters(
 	parentTrie *MTrie,
@@ -286,14 +422,25 @@ func NewTrieWithUpdatedRegisters(
 	updatedPayloads []ledger.Payload,
 	prune bool,
 ) (*MTrie, uint16, error) {
-	updatedRoot, regCountDelta, regSizeDelta, lowestHeightTouched := update(
+	// the payloads of all new leaves are written at once, after the update
+	storage, batch := newPayloadBatch(parentTrie.payloadStorage)
+	updatedRoot, regCountDelta, regSizeDelta, lowestHeightTouched, err := update(
 		ledger.NodeMaxHeight,
 		parentTrie.root,
 		updatedPaths,
 		updatedPayloads,
 		nil,
 		prune,
+		storage,
 	)
+	if err != nil {
+		cancelPayloadBatch(batch)
+		return nil, 0, fmt.Errorf("updating trie failed: %w", err)
+	}
+	err = flushPayloadBatch(batch)
+	if err != nil {
+		return nil, 0, fmt.Errorf("updating trie failed: %w", err)
+	}
 
 	updatedTrieRegCount := int64(parentTrie.AllocatedRegCount()) + regCountDelta
 	updatedTrieRegSize := int64(parentTrie.AllocatedRegSize()) + regSizeDelta
@@ -303,6 +450,7 @@ func NewTrieWithUpdatedRegisters(
 	if err != nil {
 		return nil, 0, fmt.Errorf("constructing updated trie failed: %w", err)
 	}
+	updatedTrie.payloadStorage = parentTrie.payloadStorage
 	return updatedTrie, maxDepthTouched, nil
 }
 
@@ -313,6 +461,7 @@ type updateResult struct {
 	allocatedRegCountDelta int64
 	allocatedRegSizeDelta  int64
 	lowestHeightTouched    int
+	err                    error
 }
 
 // update traverses the subtree, updates the stored registers, and returns:
@@ -320,6 +469,8 @@ type updateResult struct {
 //   * allocated register count delta in subtrie (allocatedRegCountDelta)
 //   * allocated register size delta in subtrie (allocatedRegSizeDelta)
 //   * lowest height reached during recursive update in subtrie (lowestHeightTouched)
+//   * error if a payload could not be loaded from or stored in the payload storage
+// New leaves store their payloads in `storage`, unless it is nil.
 // allocatedRegCountDelta and allocatedRegSizeDelta are used to compute updated
 // trie's allocated register count and size.  lowestHeightTouched is used to
 // compute max depth touched during update.
@@ -331,27 +482,33 @@ type updateResult struct {
 func update(
 	nodeHeight int, parentNode *node.Node,
 	paths []ledger.Path, payloads []ledger.Payload, compactLeaf *node.Node,
-	prune bool,
-) (n *node.Node, allocatedRegCountDelta int64, allocatedRegSizeDelta int64, lowestHeightTouched int) {
+	prune bool, storage node.PayloadStorage,
+) (n *node.Node, allocatedRegCountDelta int64, allocatedRegSizeDelta int64, lowestHeightTouched int, err error) {
 	// No new paths to write
 	if len(paths) == 0 {
 		// check is a compactLeaf from a higher height is still left.
 		if compactLeaf != nil {
 			// create a new node for the compact leaf path and payload. The old node shouldn't
 			// be recycled as it is still used by the tree copy before the update.
-			n = node.NewLeaf(*compactLeaf.Path(), compactLeaf.Payload(), nodeHeight)
-			return n, 0, 0, nodeHeight
+			n, err = node.NewLeafFromLeaf(compactLeaf, nodeHeight)
+			if err != nil {
+				return nil, 0, 0, 0, err
+			}
+			return n, 0, 0, nodeHeight, nil
 		}
-		return parentNode, 0, 0, nodeHeight
+		return parentNode, 0, 0, nodeHeight, nil
 	}
 
 	if len(paths) == 1 && parentNode == nil && compactLeaf == nil {
-		n = node.NewLeaf(paths[0], payloads[0].DeepCopy(), nodeHeight)
+		n, err = newLeaf(paths[0], payloads[0].DeepCopy(), nodeHeight, storage)
+		if err != nil {
+			return nil, 0, 0, 0, err
+		}
 		if payloads[0].IsEmpty() {
 			// Unallocated register doesn't affect allocatedRegCountDelta and allocatedRegSizeDelta.
-			return n, 0, 0, nodeHeight
+			return n, 0, 0, nodeHeight, nil
 		}
-		return n, 1, int64(payloads[0].Size()), nodeHeight
+		return n, 1, int64(payloads[0].Size()), nodeHeight, nil
 	}
 
 	if parentNode != nil && parentNode.IsLeaf() { // if we're here then compactLeaf == nil
@@ -360,24 +517,32 @@ func update(
 		parentPath := *parentNode.Path()
 		for i, p := range paths {
 			if p == parentPath {
+				parentPayload, err := parentNode.LoadPayload()
+				if err != nil {
+					return nil, 0, 0, 0, err
+				}
+
 				// the case where the recursion stops: only one path to update
 				if len(paths) == 1 {
-					if !parentNode.Payload().Equals(&payloads[i]) {
-						n = node.NewLeaf(paths[i], payloads[i].DeepCopy(), nodeHeight)
+					if !parentPayload.Equals(&payloads[i]) {
+						n, err = newLeaf(paths[i], payloads[i].DeepCopy(), nodeHeight, storage)
+						if err != nil {
+							return nil, 0, 0, 0, err
+						}
 
 						allocatedRegCountDelta, allocatedRegSizeDelta =
-							computeAllocatedRegDeltas(parentNode.Payload(), &payloads[i])
+							computeAllocatedRegDeltas(parentPayload, &payloads[i])
 
-						return n, allocatedRegCountDelta, allocatedRegSizeDelta, nodeHeight
+						return n, allocatedRegCountDelta, allocatedRegSizeDelta, nodeHeight, nil
 					}
 					// avoid creating a new node when the same payload is written
-					return parentNode, 0, 0, nodeHeight
+					return parentNode, 0, 0, nodeHeight, nil
 				}
 				// the case where the recursion carries on: len(paths)>1
 				found = true
 
 				allocatedRegCountDelta, allocatedRegSizeDelta =
-					computeAllocatedRegDeltasFromHigherHeight(parentNode.Payload())
+					computeAllocatedRegDeltasFromHigherHeight(parentPayload)
 
 				break
 			}
@@ -426,30 +591,40 @@ func update(
 	var lRegCountDelta, rRegCountDelta int64
 	var lRegSizeDelta, rRegSizeDelta int64
 	var lLowestHeightTouched, rLowestHeightTouched int
+	var lErr, rErr error
 	parallelRecursionThreshold := 16
 	if len(lpaths) < parallelRecursionThreshold || len(rpaths) < parallelRecursionThreshold {
 		// runtime optimization: if there are _no_ updates for either left or right sub-tree, proceed single-threaded
-		lChild, lRegCountDelta, lRegSizeDelta, lLowestHeightTouched = update(nodeHeight-1, lchildParent, lpaths, lpayloads, lcompactLeaf, prune)
-		rChild, rRegCountDelta, rRegSizeDelta, rLowestHeightTouched = update(nodeHeight-1, rchildParent, rpaths, rpayloads, rcompactLeaf, prune)
+		lChild, lRegCountDelta, lRegSizeDelta, lLowestHeightTouched, lErr = update(nodeHeight-1, lchildParent, lpaths, lpayloads, lcompactLeaf, prune, storage)
+		if lErr != nil {
+			return nil, 0, 0, 0, lErr
+		}
+		rChild, rRegCountDelta, rRegSizeDelta, rLowestHeightTouched, rErr = update(nodeHeight-1, rchildParent, rpaths, rpayloads, rcompactLeaf, prune, storage)
 	} else {
 		// runtime optimization: process the left child is a separate thread
 
-		// Since we're receiving 4 values from goroutine, use a
+		// Since we're receiving 5 values from goroutine, use a
 		// struct and channel to reduce allocs/op.
 		// Although WaitGroup approach can be faster than channel (esp. with 2+ goroutines),
 		// we only use 1 goroutine here and need to communicate results from it. So using
 		// channel is faster and uses fewer allocs/op in this case.
 		results := make(chan updateResult, 1)
 		go func(retChan chan<- updateResult) {
-			child, regCountDelta, regSizeDelta, lowestHeightTouched := update(nodeHeight-1, lchildParent, lpaths, lpayloads, lcompactLeaf, prune)
-			retChan <- updateResult{child, regCountDelta, regSizeDelta, lowestHeightTouched}
+			child, regCountDelta, regSizeDelta, lowestHeightTouched, err := update(nodeHeight-1, lchildParent, lpaths, lpayloads, lcompactLeaf, prune, storage)
+			retChan <- updateResult{child, regCountDelta, regSizeDelta, lowestHeightTouched, err}
 		}(results)
 
-		rChild, rRegCountDelta, rRegSizeDelta, rLowestHeightTouched = update(nodeHeight-1, rchildParent, rpaths, rpayloads, rcompactLeaf, prune)
+		rChild, rRegCountDelta, rRegSizeDelta, rLowestHeightTouched, rErr = update(nodeHeight-1, rchildParent, rpaths, rpayloads, rcompactLeaf, prune, storage)
 
 		// Wait for results from goroutine.
 		ret := <-results
-		lChild, lRegCountDelta, lRegSizeDelta, lLowestHeightTouched = ret.child, ret.allocatedRegCountDelta, ret.allocatedRegSizeDelta, ret.lowestHeightTouched
+		lChild, lRegCountDelta, lRegSizeDelta, lLowestHeightTouched, lErr = ret.child, ret.allocatedRegCountDelta, ret.allocatedRegSizeDelta, ret.lowestHeightTouched, ret.err
+	}
+	if lErr != nil {
+		return nil, 0, 0, 0, lErr
+	}
+	if rErr != nil {
+		return nil, 0, 0, 0, rErr
 	}
 
 	allocatedRegCountDelta += lRegCountDelta + rRegCountDelta
@@ -461,18 +636,27 @@ func update(
 	// unchanged. This is only sufficient for interim nodes (for leaf nodes, the children
 	// might be unchanged, i.e. both nil, but the payload could have changed).
 	if !parentNode.IsLeaf() && lChild == lchildParent && rChild == rchildParent {
-		return parentNode, 0, 0, lowestHeightTouched
+		return parentNode, 0, 0, lowestHeightTouched, nil
 	}
 
 	// In case the parent node was a leaf, we _cannot reuse_ it, because we potentially
 	// updated registers in the sub-trie
 	if prune {
 		n = node.NewInterimCompactifiedNode(nodeHeight, lChild, rChild)
-		return n, allocatedRegCountDelta, allocatedRegSizeDelta, lowestHeightTouched
+		return n, allocatedRegCountDelta, allocatedRegSizeDelta, lowestHeightTouched, nil
 	}
 
 	n = node.NewInterimNode(nodeHeight, lChild, rChild)
-	return n, allocatedRegCountDelta, allocatedRegSizeDelta, lowestHeightTouched
+	return n, allocatedRegCountDelta, allocatedRegSizeDelta, lowestHeightTouched, nil
+}
+
+// newLeaf creates a compact leaf Node, whose payload is stored in the given payload storage
+// or held in memory if the storage is nil.
+func newLeaf(path ledger.Path, payload *ledger.Payload, height int, storage node.PayloadStorage) (*node.Node, error) {
+	if storage == nil {
+		return node.NewLeaf(path, payload, height), nil
+	}
+	return node.NewStoredLeaf(path, payload, height, storage)
 }
 
 // computeAllocatedRegDeltasFromHigherHeight returns the deltas
@@ -515,10 +699,14 @@ func computeAllocatedRegDeltas(oldPayload, newPayload *ledger.Payload) (allocate
 // UNSAFE: requires _all_ paths to have a length of mt.Height bits.
 // Paths in the input query don't have to be deduplicated, though deduplication would
 // result in allocating less dynamic memory to store the proofs.
-func (mt *MTrie) UnsafeProofs(paths []ledger.Path) *ledger.TrieBatchProof {
+// An error is returned if a stored payload could not be loaded.
+func (mt *MTrie) UnsafeProofs(paths []ledger.Path) (*ledger.TrieBatchProof, error) {
 	batchProofs := ledger.NewTrieBatchProofWithEmptyProofs(len(paths))
-	prove(mt.root, paths, batchProofs.Proofs)
-	return batchProofs
+	err := prove(mt.root, paths, batchProofs.Proofs)
+	if err != nil {
+		return nil, err
+	}
+	return batchProofs, nil
 }
 
 // prove traverses the subtree and stores proofs for the given register paths in
@@ -527,17 +715,17 @@ func (mt *MTrie) UnsafeProofs(paths []ledger.Path) *ledger.TrieBatchProof {
 // UNSAFE: method requires the following conditions to be satisfied:
 //   * paths all share the same common prefix [0 : mt.maxHeight-1 - nodeHeight)
 //     (excluding the bit at index headHeight)
-func prove(head *node.Node, paths []ledger.Path, proofs []*ledger.TrieProof) {
+func prove(head *node.Node, paths []ledger.Path, proofs []*ledger.TrieProof) error {
 	// check for empty paths
 	if len(paths) == 0 {
-		return
+		return nil
 	}
 
 	// we've reached the end of a trie
 	// and path is not found (noninclusion proof)
 	if head == nil {
 		// by default, proofs are non-inclusion proofs
-		return
+		return nil
 	}
 
 	// we've reached a leaf
@@ -545,13 +733,17 @@ func prove(head *node.Node, paths []ledger.Path, proofs []*ledger.TrieProof) {
 		for i, path := range paths {
 			// value matches (inclusion proof)
 			if *head.Path() == path {
+				payload, err := head.LoadPayload()
+				if err != nil {
+					return err
+				}
 				proofs[i].Path = *head.Path()
-				proofs[i].Payload = head.Payload()
+				proofs[i].Payload = payload
 				proofs[i].Inclusion = true
 			}
 		}
 		// by default, proofs are non-inclusion proofs
-		return
+		return nil
 	}
 
 	// increment steps for all the proofs
@@ -571,23 +763,31 @@ func prove(head *node.Node, paths []ledger.Path, proofs []*ledger.TrieProof) {
 	if len(lpaths) < parallelRecursionThreshold || len(rpaths) < parallelRecursionThreshold {
 		// runtime optimization: below the parallelRecursionThreshold, we proceed single-threaded
 		addSiblingTrieHashToProofs(head.RightChild(), depth, lproofs)
-		prove(head.LeftChild(), lpaths, lproofs)
+		err := prove(head.LeftChild(), lpaths, lproofs)
+		if err != nil {
+			return err
+		}
 
 		addSiblingTrieHashToProofs(head.LeftChild(), depth, rproofs)
-		prove(head.RightChild(), rpaths, rproofs)
-	} else {
-		wg := sync.WaitGroup{}
-		wg.Add(1)
-		go func() {
-			addSiblingTrieHashToProofs(head.RightChild(), depth, lproofs)
-			prove(head.LeftChild(), lpaths, lproofs)
-			wg.Done()
-		}()
+		return prove(head.RightChild(), rpaths, rproofs)
+	}
 
-		addSiblingTrieHashToProofs(head.LeftChild(), depth, rproofs)
-		prove(head.RightChild(), rpaths, rproofs)
-		wg.Wait()
+	var lErr error
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		addSiblingTrieHashToProofs(head.RightChild(), depth, lproofs)
+		lErr = prove(head.LeftChild(), lpaths, lproofs)
+		wg.Done()
+	}()
+
+	addSiblingTrieHashToProofs(head.LeftChild(), depth, rproofs)
+	rErr := prove(head.RightChild(), rpaths, rproofs)
+	wg.Wait()
+	if lErr != nil {
+		return lErr
 	}
+	return rErr
 }
 
 // addSiblingTrieHashToProofs inspects the sibling Trie and adds its root hash
@@ -648,7 +848,11 @@ func (mt *MTrie) DumpAsJSON(w io.Writer) error {
 func dumpAsJSON(n *node.Node, encoder *json.Encoder) error {
 	if n.IsLeaf() {
 		if n != nil {
-			err := encoder.Encode(n.Payload())
+			payload, err := n.LoadPayload()
+			if err != nil {
+				return err
+			}
+			err = encoder.Encode(payload)
 			if err != nil {
 				return err
 			}
@@ -678,7 +882,7 @@ func EmptyTrieRootHash() ledger.RootHash {
 }
 
 // AllPayloads returns all payloads
-func (mt *MTrie) AllPayloads() []ledger.Payload {
+func (mt *MTrie) AllPayloads() ([]ledger.Payload, error) {
 	return mt.root.AllPayloads()
 }
 
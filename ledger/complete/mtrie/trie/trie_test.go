@@ -701,13 +701,15 @@ func Test_Pruning(t *testing.T) {
 				queryPaths = append(queryPaths, path)
 			}
 
-			payloads := activeTrie.UnsafeRead(queryPaths)
+			payloads, err := activeTrie.UnsafeRead(queryPaths)
+			require.NoError(t, err)
 			for i, pp := range payloads {
 				expectedPayload := allPaths[queryPaths[i]]
 				require.True(t, pp.Equals(&expectedPayload))
 			}
 
-			payloads = activeTrieWithPruning.UnsafeRead(queryPaths)
+			payloads, err = activeTrieWithPruning.UnsafeRead(queryPaths)
+			require.NoError(t, err)
 			for i, pp := range payloads {
 				expectedPayload := allPaths[queryPaths[i]]
 				require.True(t, pp.Equals(&expectedPayload))
@@ -0,0 +1,70 @@
+package complete
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/atomic"
+
+	"github.com/onflow/flow-go/ledger/complete/mtrie/ownerindex"
+	"github.com/onflow/flow-go/module/observable"
+)
+
+// OwnerIndexPruner removes the paths of the registers no trie of the ledger holds anymore from an owner index.
+// It observes the WAL compactor and prunes the index every time a checkpoint was created, like PayloadPruner.
+type OwnerIndexPruner struct {
+	ledger  *Ledger
+	index   *ownerindex.OwnerIndex
+	logger  zerolog.Logger
+	pruning *atomic.Bool
+}
+
+var _ observable.Observer = (*OwnerIndexPruner)(nil)
+
+// NewOwnerIndexPruner returns a pruner for the owner index of the given ledger.
+func NewOwnerIndexPruner(ledger *Ledger, index *ownerindex.OwnerIndex, log zerolog.Logger) *OwnerIndexPruner {
+	return &OwnerIndexPruner{
+		ledger:  ledger,
+		index:   index,
+		logger:  log.With().Str("component", "owner_index_pruner").Logger(),
+		pruning: atomic.NewBool(false),
+	}
+}
+
+// OnNext starts pruning the owner index in the background after a checkpoint was created.
+// Checkpoints created while pruning don't start another pruning.
+func (p *OwnerIndexPruner) OnNext(checkpoint interface{}) {
+	if !p.pruning.CAS(false, true) {
+		p.logger.Debug().Interface("checkpoint", checkpoint).Msg("owner index pruning in progress, skipping")
+		return
+	}
+
+	go func() {
+		defer p.pruning.Store(false)
+
+		_, _ = p.Prune()
+	}()
+}
+
+// OnError does nothing, as the pruning doesn't depend on checkpointing errors.
+func (p *OwnerIndexPruner) OnError(error) {}
+
+// OnComplete does nothing.
+func (p *OwnerIndexPruner) OnComplete() {}
+
+// Prune removes the paths of the registers that aren't held by the tries of the ledger from the owner index,
+// and returns the number of removed paths. It must not be called while OnNext prunes the index.
+func (p *OwnerIndexPruner) Prune() (int, error) {
+	start := time.Now()
+	removed, err := p.index.Prune(p.ledger.forest.LiveRegisters)
+	if err != nil {
+		p.logger.Error().Err(err).Int("removed", removed).Msg("could not prune owner index")
+		return removed, err
+	}
+
+	p.logger.Info().
+		Int("removed", removed).
+		Dur("duration", time.Since(start)).
+		Msg("pruned owner index")
+	return removed, nil
+}
@@ -0,0 +1,75 @@
+package complete
+
+import (
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/atomic"
+
+	"github.com/onflow/flow-go/ledger/complete/mtrie/payloadstore"
+	"github.com/onflow/flow-go/module/observable"
+)
+
+// PayloadPruner removes the payloads no trie of the ledger references anymore from a payload store.
+// It observes the WAL compactor and prunes the store every time a checkpoint was created, as tries
+// evicted from the forest are then no longer needed to restore the ledger.
+//
+// The compactor stores the payloads of the tries it creates checkpoints from in the same store, see
+// wal.DiskWAL.SetPayloadStorage. As the next checkpoint is only created after pruning started, the
+// payloads it stores while the store is pruned are kept.
+type PayloadPruner struct {
+	ledger  *Ledger
+	store   *payloadstore.PayloadStore
+	logger  zerolog.Logger
+	pruning *atomic.Bool
+}
+
+var _ observable.Observer = (*PayloadPruner)(nil)
+
+// NewPayloadPruner returns a pruner for the payload store of the given ledger.
+func NewPayloadPruner(ledger *Ledger, store *payloadstore.PayloadStore, log zerolog.Logger) *PayloadPruner {
+	return &PayloadPruner{
+		ledger:  ledger,
+		store:   store,
+		logger:  log.With().Str("component", "payload_pruner").Logger(),
+		pruning: atomic.NewBool(false),
+	}
+}
+
+// OnNext starts pruning the payload store in the background after a checkpoint was created.
+// Checkpoints created while pruning don't start another pruning.
+func (p *PayloadPruner) OnNext(checkpoint interface{}) {
+	if !p.pruning.CAS(false, true) {
+		p.logger.Debug().Interface("checkpoint", checkpoint).Msg("payload pruning in progress, skipping")
+		return
+	}
+
+	go func() {
+		defer p.pruning.Store(false)
+
+		_, _ = p.Prune()
+	}()
+}
+
+// OnError does nothing, as the pruning doesn't depend on checkpointing errors.
+func (p *PayloadPruner) OnError(error) {}
+
+// OnComplete does nothing.
+func (p *PayloadPruner) OnComplete() {}
+
+// Prune removes the payloads that aren't referenced by the tries of the ledger from the payload store,
+// and returns the number of removed payloads. It must not be called while OnNext prunes the store.
+func (p *PayloadPruner) Prune() (int, error) {
+	start := time.Now()
+	removed, err := p.store.Prune(p.ledger.forest.StoredPayloadKeys)
+	if err != nil {
+		p.logger.Error().Err(err).Int("removed", removed).Msg("could not prune payloads")
+		return removed, err
+	}
+
+	p.logger.Info().
+		Int("removed", removed).
+		Dur("duration", time.Since(start)).
+		Msg("pruned unreferenced payloads")
+	return removed, nil
+}
@@ -0,0 +1,93 @@
+package wal
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+)
+
+// leafPayloadBatchSize is the maximum number of leaf payloads of a loaded checkpoint written in a single batch.
+const leafPayloadBatchSize = 10_000
+
+// leafStorer stores the payloads of the leaves read from a checkpoint in a payload storage as they are read,
+// so loading a checkpoint doesn't hold all payloads in memory. If the storage is a node.BatchPayloadStorage,
+// the payloads are written in batches of up to leafPayloadBatchSize payloads.
+// A nil leafStorer keeps the payloads in memory.
+// leafStorer is not concurrency safe.
+type leafStorer struct {
+	storage node.PayloadStorage
+	batch   node.PayloadBatch // nil if there are no unflushed payloads or the storage doesn't support batches
+	batched int               // number of leaves stored in the batch
+}
+
+// newLeafStorer returns a leafStorer for the given payload storage, or nil if the storage is nil.
+func newLeafStorer(storage node.PayloadStorage) *leafStorer {
+	if storage == nil {
+		return nil
+	}
+	return &leafStorer{storage: storage}
+}
+
+// store returns the node with its payload stored in the payload storage, if it is a leaf.
+// Interim nodes and leaves whose payload is already stored are returned as they are.
+func (s *leafStorer) store(n *node.Node) (*node.Node, error) {
+	if s == nil || !n.IsLeaf() {
+		return n, nil
+	}
+
+	storage := s.storage
+	if batchStorage, ok := s.storage.(node.BatchPayloadStorage); ok {
+		if s.batch == nil {
+			s.batch = batchStorage.NewBatch()
+		}
+		storage = s.batch
+	}
+
+	n, err := n.NewStoredNode(storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.batch != nil {
+		s.batched++
+		if s.batched >= leafPayloadBatchSize {
+			return n, s.flush()
+		}
+	}
+	return n, nil
+}
+
+// flush writes the payloads of the current batch to the payload storage.
+func (s *leafStorer) flush() error {
+	if s == nil || s.batch == nil {
+		return nil
+	}
+
+	err := s.batch.Flush()
+	s.batch = nil
+	s.batched = 0
+	if err != nil {
+		return fmt.Errorf("could not write leaf payloads: %w", err)
+	}
+	return nil
+}
+
+// cancel discards the payloads of the current batch, which haven't been flushed.
+func (s *leafStorer) cancel() {
+	if s == nil || s.batch == nil {
+		return
+	}
+	s.batch.Cancel()
+	s.batch = nil
+	s.batched = 0
+}
+
+// trie returns the trie read from the checkpoint, whose leaves were all stored by the leafStorer,
+// as a trie with stored payloads.
+func (s *leafStorer) trie(t *trie.MTrie) (*trie.MTrie, error) {
+	if s == nil {
+		return t, nil
+	}
+	return trie.NewMTrieWithStoredPayloads(t.RootNode(), t.AllocatedRegCount(), t.AllocatedRegSize(), s.storage)
+}
@@ -0,0 +1,235 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/flattener"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+)
+
+// StreamCheckpointPayloads reads the payloads of the single trie stored in the checkpoint file, and passes
+// them to visit in the order in which they are stored. The checkpoint is read node by node, without building
+// the trie, so that checkpoints of any size can be read with little memory.
+//
+// The payloads are always read in the same order, so an interrupted read can be resumed by skipping the
+// payloads which were already visited: the first skip payloads are read but not passed to visit.
+//
+// Only checkpoints of version 5 and 6 which contain a single trie are supported, such as root checkpoints and
+// checkpoints created by Ledger.ExportCheckpointAt. The payloads of checkpoints with several tries can't be
+// attributed to a trie without building the tries.
+// The checksums of the checkpoint files are verified once their payloads have been visited.
+func StreamCheckpointPayloads(
+	filePath string,
+	skip uint64,
+	visit func(payload *ledger.Payload) error,
+	logger *zerolog.Logger,
+) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("cannot open checkpoint file %s: %w", filePath, err)
+	}
+	defer func() {
+		evictErr := evictFileFromLinuxPageCache(f, false, logger)
+		if evictErr != nil {
+			logger.Warn().Msgf("failed to evict file %s from Linux page cache: %s", filePath, evictErr)
+			// No need to return this error because it's possible to continue normal operations.
+		}
+		_ = f.Close()
+	}()
+
+	header := make([]byte, headerSize)
+	_, err = io.ReadFull(f, header)
+	if err != nil {
+		return fmt.Errorf("cannot read header: %w", err)
+	}
+
+	magicBytes := binary.BigEndian.Uint16(header)
+	version := binary.BigEndian.Uint16(header[encMagicSize:])
+	if magicBytes != MagicBytes {
+		return fmt.Errorf("unknown file format. Magic constant %x does not match expected %x", magicBytes, MagicBytes)
+	}
+
+	leaves := &leafVisitor{skip: skip, visit: visit}
+
+	switch version {
+	case VersionV5:
+		nodeCount, err := readSingleTrieFooter(f)
+		if err != nil {
+			return err
+		}
+		return streamCheckpointLeaves(f, nodeCount, leaves)
+
+	case VersionV6:
+		checksums, err := readCheckpointV6Header(f)
+		if err != nil {
+			return err
+		}
+
+		dir, filename := filepath.Split(f.Name())
+		for i := 0; i < partCount; i++ {
+			err := streamCheckpointV6Part(filepath.Join(dir, partFileName(filename, i)), i, checksums[i], leaves, logger)
+			if err != nil {
+				return fmt.Errorf("cannot read checkpoint part file %d: %w", i, err)
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported file version %x, only checkpoints of version %x and %x can be streamed",
+			version, VersionV5, VersionV6)
+	}
+}
+
+// leafVisitor passes the payloads of the leaves after the first skip leaves to visit.
+type leafVisitor struct {
+	skip  uint64
+	count uint64
+	visit func(payload *ledger.Payload) error
+}
+
+func (v *leafVisitor) onLeaf(n *node.Node) error {
+	v.count++
+	if v.count <= v.skip {
+		return nil
+	}
+	payload, err := n.LoadPayload()
+	if err != nil {
+		return err
+	}
+	return v.visit(payload)
+}
+
+func streamCheckpointV6Part(partPath string, index int, checksum uint32, leaves *leafVisitor, logger *zerolog.Logger) error {
+	f, err := os.Open(partPath)
+	if err != nil {
+		return fmt.Errorf("cannot open checkpoint part file %s: %w", partPath, err)
+	}
+	defer func() {
+		evictErr := evictFileFromLinuxPageCache(f, false, logger)
+		if evictErr != nil {
+			logger.Warn().Msgf("failed to evict file %s from Linux page cache: %s", partPath, evictErr)
+		}
+		_ = f.Close()
+	}()
+
+	footerSize := encNodeCountSize
+	if index == topTriesPartIndex {
+		footerSize += encTrieCountSize
+	}
+	nodeCount, err := readCheckpointV6PartFooter(f, footerSize, checksum)
+	if err != nil {
+		return err
+	}
+	if index == topTriesPartIndex {
+		_, err = readSingleTrieFooter(f)
+		if err != nil {
+			return err
+		}
+	}
+
+	return streamCheckpointLeaves(f, nodeCount, leaves)
+}
+
+// readSingleTrieFooter reads the footer of a version 5 checkpoint file, or of the top tries part file of a
+// version 6 checkpoint, and returns its node count. It returns an error if the file doesn't contain a single trie.
+func readSingleTrieFooter(f *os.File) (uint64, error) {
+	const footerOffset = encNodeCountSize + encTrieCountSize + crc32SumSize
+
+	_, err := f.Seek(-footerOffset, io.SeekEnd)
+	if err != nil {
+		return 0, fmt.Errorf("cannot seek to footer: %w", err)
+	}
+
+	footer := make([]byte, encNodeCountSize+encTrieCountSize)
+	_, err = io.ReadFull(f, footer)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read footer: %w", err)
+	}
+
+	trieCount := binary.BigEndian.Uint16(footer[encNodeCountSize:])
+	if trieCount != 1 {
+		return 0, fmt.Errorf("checkpoint contains %d tries, only checkpoints of a single trie can be streamed", trieCount)
+	}
+
+	return binary.BigEndian.Uint64(footer), nil
+}
+
+// streamCheckpointLeaves reads the nodeCount nodes following the header of the checkpoint file or part file,
+// and passes its leaves to the leaf visitor. Nodes reference their children by index, which is ignored, so
+// that no node needs to be kept in memory. The rest of the file is read to verify its CRC32 checksum.
+func streamCheckpointLeaves(f *os.File, nodeCount uint64, leaves *leafVisitor) error {
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("cannot stat file: %w", err)
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("cannot seek to start of file: %w", err)
+	}
+
+	// Scratch buffer is used as temporary buffer that reader can read into, see readCheckpointV5Nodes.
+	scratch := make([]byte, 1024*4)
+
+	bufReader := bufio.NewReaderSize(f, defaultBufioReadSize)
+	crcReader := NewCRC32Reader(io.LimitReader(bufReader, info.Size()-crc32SumSize))
+	var reader io.Reader = crcReader
+
+	_, err = io.ReadFull(reader, scratch[:headerSize])
+	if err != nil {
+		return fmt.Errorf("cannot read header: %w", err)
+	}
+
+	ignoreChild := func(uint64) (*node.Node, error) {
+		return nil, nil
+	}
+
+	for i := uint64(0); i < nodeCount; i++ {
+		n, err := flattener.ReadNode(reader, scratch, ignoreChild)
+		if err != nil {
+			return fmt.Errorf("cannot read node %d: %w", i, err)
+		}
+		// only leaves have a payload, which is read from the checkpoint
+		payload, err := n.LoadPayload()
+		if err != nil {
+			return fmt.Errorf("cannot read payload of node %d: %w", i, err)
+		}
+		if payload == nil {
+			continue
+		}
+		err = leaves.onLeaf(n)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Read the tries and the footer for crc32 computation
+	// No action is needed.
+	_, err = io.Copy(io.Discard, reader)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("cannot read tries and footer: %w", err)
+	}
+
+	crc32buf := scratch[:crc32SumSize]
+	_, err = io.ReadFull(bufReader, crc32buf)
+	if err != nil {
+		return fmt.Errorf("cannot read CRC32: %w", err)
+	}
+
+	readCrc32 := binary.BigEndian.Uint32(crc32buf)
+	calculatedCrc32 := crcReader.Crc32()
+	if calculatedCrc32 != readCrc32 {
+		return fmt.Errorf("checkpoint checksum failed! File contains %x but calculated crc32 is %x", readCrc32, calculatedCrc32)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package wal_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/utils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	realWAL "github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestStreamCheckpointPayloads(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		logger := zerolog.Nop()
+
+		payloads := make([]ledger.Payload, 0, 500)
+		for _, payload := range utils.RandomPayloads(500, 2, 100) {
+			payloads = append(payloads, *payload)
+		}
+		emptyTrie := trie.NewEmptyMTrie()
+		updatedTrie, _, err := trie.NewTrieWithUpdatedRegisters(emptyTrie, utils.RandomPaths(500), payloads, true)
+		require.NoError(t, err)
+
+		allPayloads, err := updatedTrie.AllPayloads()
+		require.NoError(t, err)
+		expected := make(map[string]struct{})
+		for _, payload := range allPayloads {
+			expected[string(payload.Key.CanonicalForm())+string(payload.Value)] = struct{}{}
+		}
+
+		// the payloads are streamed from checkpoints of both supported versions
+		file, err := os.Create(path.Join(dir, "checkpoint-v5"))
+		require.NoError(t, err)
+		require.NoError(t, realWAL.StoreCheckpoint(file, updatedTrie))
+		require.NoError(t, file.Close())
+
+		err = realWAL.StoreCheckpointV6([]*trie.MTrie{updatedTrie}, dir, "checkpoint.00000001", &logger)
+		require.NoError(t, err)
+
+		for _, name := range []string{"checkpoint-v5", "checkpoint.00000001"} {
+			t.Run(name, func(t *testing.T) {
+				var streamed []*ledger.Payload
+				err := realWAL.StreamCheckpointPayloads(path.Join(dir, name), 0, func(payload *ledger.Payload) error {
+					streamed = append(streamed, payload)
+					return nil
+				}, &logger)
+				require.NoError(t, err)
+
+				require.Len(t, streamed, len(expected))
+				for _, payload := range streamed {
+					require.Contains(t, expected, string(payload.Key.CanonicalForm())+string(payload.Value))
+				}
+
+				// a resumed read continues with the payloads which were skipped
+				var resumed []*ledger.Payload
+				err = realWAL.StreamCheckpointPayloads(path.Join(dir, name), 200, func(payload *ledger.Payload) error {
+					resumed = append(resumed, payload)
+					return nil
+				}, &logger)
+				require.NoError(t, err)
+				require.Equal(t, streamed[200:], resumed)
+			})
+		}
+
+		t.Run("rejects checkpoints with several tries", func(t *testing.T) {
+			err := realWAL.StoreCheckpointV6([]*trie.MTrie{emptyTrie, updatedTrie}, dir, "checkpoint.00000002", &logger)
+			require.NoError(t, err)
+
+			err = realWAL.StreamCheckpointPayloads(path.Join(dir, "checkpoint.00000002"), 0, func(*ledger.Payload) error {
+				return nil
+			}, &logger)
+			require.Error(t, err)
+		})
+
+		t.Run("detects corrupted checkpoints", func(t *testing.T) {
+			randomlyModifyFile(t, path.Join(dir, "checkpoint-v5"))
+
+			err := realWAL.StreamCheckpointPayloads(path.Join(dir, "checkpoint-v5"), 0, func(*ledger.Payload) error {
+				return nil
+			}, &logger)
+			require.Error(t, err)
+		})
+	})
+}
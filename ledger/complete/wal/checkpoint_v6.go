@@ -0,0 +1,550 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/onflow/flow-go/ledger/complete/mtrie/flattener"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	utilsio "github.com/onflow/flow-go/utils/io"
+)
+
+const (
+	// subtrieLevel is the depth at which version 6 checkpoints split the tries into subtries.
+	subtrieLevel = 4
+	// subtrieCount is the number of subtries, and subtrie part files, of version 6 checkpoints.
+	subtrieCount = 1 << subtrieLevel
+	// topTriesPartIndex is the index of the part file with the nodes above the subtries and the tries.
+	topTriesPartIndex = subtrieCount
+	// partCount is the number of part files of version 6 checkpoints.
+	partCount = subtrieCount + 1
+
+	encPartCountSize = 2
+)
+
+// partFileName returns the name of the part file with the given index of the version 6 checkpoint file.
+func partFileName(filename string, index int) string {
+	return fmt.Sprintf("%s.%03d", filename, index)
+}
+
+// StoreCheckpointV6 writes the given tries to a version 6 checkpoint file `outputFile` and its part files
+// in `outputDir`. The tries are split at depth subtrieLevel into subtries, and the nodes of the subtries
+// with the same path prefix are stored in the same subtrie part file. The part files are stored concurrently.
+//
+// The checkpoint consists of:
+//   - subtrieCount subtrie part files, named `<outputFile>.000` to `<outputFile>.015`.
+//     Each contains the header, the unique nodes of the subtries, the node count (8 bytes) and a CRC32
+//     checksum. Nodes reference their children by their index within the part file.
+//   - the top tries part file, named `<outputFile>.016`, which has the same layout as a version 5
+//     checkpoint file. It contains the unique nodes above the subtries and the tries. Nodes of the
+//     subtrie part files are referenced by their index in the combined list of all subtrie part
+//     files' nodes, which starts with the nil node at index 0.
+//   - the checkpoint file `<outputFile>`, which contains the header, the part count (2 bytes), the
+//     CRC32 checksums of all part files (4 bytes each) and its own CRC32 checksum.
+//
+// The checkpoint file is written last, so the checkpoint is only listed once all part files are written.
+// If storing fails, the part files which have been written are removed.
+func StoreCheckpointV6(tries []*trie.MTrie, outputDir, outputFile string, logger *zerolog.Logger) (err error) {
+	// don't remove the part files of an existing checkpoint
+	fullname := path.Join(outputDir, outputFile)
+	if utilsio.FileExists(fullname) {
+		return fmt.Errorf("checkpoint file %s already exists", fullname)
+	}
+
+	defer func() {
+		if err != nil {
+			removeErr := removeCheckpointParts(outputDir, outputFile)
+			if removeErr != nil {
+				logger.Warn().Err(removeErr).Msgf("failed to remove part files of checkpoint %s", outputFile)
+			}
+		}
+	}()
+
+	// subtrieRoots[i][j] is the root of subtrie i of trie j
+	subtrieRoots := make([][]*node.Node, subtrieCount)
+	for i := range subtrieRoots {
+		subtrieRoots[i] = make([]*node.Node, len(tries))
+	}
+	for j, t := range tries {
+		for i, root := range getSubtrieRoots(t) {
+			subtrieRoots[i][j] = root
+		}
+	}
+
+	subtrieNodes := make([]map[*node.Node]uint64, subtrieCount)
+	nodeCounts := make([]uint64, subtrieCount)
+	checksums := make([]uint32, partCount)
+
+	group := new(errgroup.Group)
+	for i := 0; i < subtrieCount; i++ {
+		i := i
+		group.Go(func() error {
+			var err error
+			subtrieNodes[i], nodeCounts[i], checksums[i], err = storeSubtriePart(outputDir, partFileName(outputFile, i), subtrieRoots[i], logger)
+			if err != nil {
+				return fmt.Errorf("cannot store subtrie part file %d: %w", i, err)
+			}
+			return nil
+		})
+	}
+	err = group.Wait()
+	if err != nil {
+		return err
+	}
+
+	// the top tries reference the subtrie roots by their index in the combined list of subtrie nodes
+	allNodes := make(map[*node.Node]uint64, 1+subtrieCount*len(tries))
+	allNodes[nil] = 0
+	offset := uint64(0)
+	for i, roots := range subtrieRoots {
+		for _, root := range roots {
+			if root != nil {
+				allNodes[root] = offset + subtrieNodes[i][root]
+			}
+		}
+		offset += nodeCounts[i]
+	}
+
+	checksums[topTriesPartIndex], err = storeTopTriesPart(outputDir, partFileName(outputFile, topTriesPartIndex), allNodes, offset+1, tries, logger)
+	if err != nil {
+		return fmt.Errorf("cannot store top tries part file: %w", err)
+	}
+
+	return storeCheckpointV6Header(outputDir, outputFile, checksums, logger)
+}
+
+// getSubtrieRoots returns the roots of the subtries at depth subtrieLevel of the given trie, ordered by
+// their path prefix. Subtries without nodes, including subtries of leaves above subtrieLevel, are nil.
+func getSubtrieRoots(t *trie.MTrie) []*node.Node {
+	roots := make([]*node.Node, subtrieCount)
+
+	var collect func(n *node.Node, depth int, index int)
+	collect = func(n *node.Node, depth int, index int) {
+		if n == nil {
+			return
+		}
+		if depth == subtrieLevel {
+			roots[index] = n
+			return
+		}
+		// leaves above subtrieLevel are stored with the top tries
+		if n.IsLeaf() {
+			return
+		}
+		collect(n.LeftChild(), depth+1, index<<1)
+		collect(n.RightChild(), depth+1, index<<1+1)
+	}
+	collect(t.RootNode(), 0, 0)
+
+	return roots
+}
+
+// storeSubtriePart writes the unique nodes of the subtries with the given roots to the part file,
+// and returns the nodes and their index within the part file, the node count and the CRC32 checksum.
+func storeSubtriePart(dir string, filename string, roots []*node.Node, logger *zerolog.Logger) (_ map[*node.Node]uint64, _ uint64, _ uint32, err error) {
+	writer, err := CreateCheckpointWriterForFile(dir, filename, logger)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("cannot create writer: %w", err)
+	}
+	defer func() {
+		closeErr := writer.Close()
+		// Return close error if there isn't any prior error to return.
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	crc32Writer := NewCRC32Writer(writer)
+
+	// Scratch buffer is used as temporary buffer that node can encode into.
+	// Data in scratch buffer should be copied or used before scratch buffer is used again.
+	// If the scratch buffer isn't large enough, a new buffer will be allocated.
+	scratch := make([]byte, 1024*4)
+
+	// Write header: magic (2 bytes) + version (2 bytes)
+	header := scratch[:headerSize]
+	binary.BigEndian.PutUint16(header, MagicBytes)
+	binary.BigEndian.PutUint16(header[encMagicSize:], VersionV6)
+
+	_, err = crc32Writer.Write(header)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("cannot write checkpoint header: %w", err)
+	}
+
+	// Index 0 is a special case with nil node.
+	allNodes := make(map[*node.Node]uint64)
+	allNodes[nil] = 0
+
+	nodeCounter, err := storeUniqueNodes(crc32Writer, roots, allNodes, 1, scratch)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	nodeCount := nodeCounter - 1
+
+	// Write footer with nodes count
+	footer := scratch[:encNodeCountSize]
+	binary.BigEndian.PutUint64(footer, nodeCount)
+
+	_, err = crc32Writer.Write(footer)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("cannot write checkpoint footer: %w", err)
+	}
+
+	// Write CRC32 sum
+	checksum := crc32Writer.Crc32()
+	crc32buf := scratch[:crc32SumSize]
+	binary.BigEndian.PutUint32(crc32buf, checksum)
+
+	_, err = writer.Write(crc32buf)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("cannot write CRC32: %w", err)
+	}
+
+	return allNodes, nodeCount, checksum, nil
+}
+
+// storeTopTriesPart writes the unique nodes of the tries which are not in `allNodes` and the tries to
+// the part file, and returns its CRC32 checksum. New nodes are indexed from firstNodeIndex on.
+func storeTopTriesPart(dir string, filename string, allNodes map[*node.Node]uint64, firstNodeIndex uint64, tries []*trie.MTrie, logger *zerolog.Logger) (_ uint32, err error) {
+	writer, err := CreateCheckpointWriterForFile(dir, filename, logger)
+	if err != nil {
+		return 0, fmt.Errorf("cannot create writer: %w", err)
+	}
+	defer func() {
+		closeErr := writer.Close()
+		// Return close error if there isn't any prior error to return.
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	// Write header: magic (2 bytes) + version (2 bytes)
+	header := make([]byte, headerSize)
+	binary.BigEndian.PutUint16(header, MagicBytes)
+	binary.BigEndian.PutUint16(header[encMagicSize:], VersionV6)
+
+	return storeCheckpoint(writer, header, allNodes, firstNodeIndex, tries)
+}
+
+// storeCheckpointV6Header writes the checkpoint file with the checksums of its part files.
+func storeCheckpointV6Header(dir string, filename string, checksums []uint32, logger *zerolog.Logger) (err error) {
+	writer, err := CreateCheckpointWriterForFile(dir, filename, logger)
+	if err != nil {
+		return fmt.Errorf("cannot create writer: %w", err)
+	}
+	defer func() {
+		closeErr := writer.Close()
+		// Return close error if there isn't any prior error to return.
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	crc32Writer := NewCRC32Writer(writer)
+
+	// Write header: magic (2 bytes) + version (2 bytes) + part count (2 bytes) + part checksums (4 bytes each)
+	header := make([]byte, headerSize+encPartCountSize+len(checksums)*crc32SumSize)
+	binary.BigEndian.PutUint16(header, MagicBytes)
+	binary.BigEndian.PutUint16(header[encMagicSize:], VersionV6)
+	binary.BigEndian.PutUint16(header[headerSize:], uint16(len(checksums)))
+	for i, checksum := range checksums {
+		binary.BigEndian.PutUint32(header[headerSize+encPartCountSize+i*crc32SumSize:], checksum)
+	}
+
+	_, err = crc32Writer.Write(header)
+	if err != nil {
+		return fmt.Errorf("cannot write checkpoint header: %w", err)
+	}
+
+	// Write CRC32 sum
+	crc32buf := make([]byte, crc32SumSize)
+	binary.BigEndian.PutUint32(crc32buf, crc32Writer.Crc32())
+
+	_, err = writer.Write(crc32buf)
+	if err != nil {
+		return fmt.Errorf("cannot write CRC32: %w", err)
+	}
+
+	return nil
+}
+
+// removeCheckpointParts removes the part files of the version 6 checkpoint file, if they exist.
+func removeCheckpointParts(dir string, filename string) error {
+	for i := 0; i < partCount; i++ {
+		err := os.Remove(path.Join(dir, partFileName(filename, i)))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// readCheckpointV6 decodes checkpoint file (version 6) and its part files and returns a list of tries.
+// Checkpoint file header (magic and version) are verified by the caller.
+func readCheckpointV6(f *os.File, payloadStorage node.PayloadStorage, logger *zerolog.Logger) ([]*trie.MTrie, error) {
+	_, tries, err := readCheckpointV6Nodes(f, payloadStorage, logger)
+	return tries, err
+}
+
+// readCheckpointV6Nodes deserializes the part files of the checkpoint file (version 6) from the same
+// directory, and returns all nodes, with the nil node at index 0, and the tries.
+// The subtrie part files are read concurrently.
+// If payloadStorage isn't nil, the leaf payloads are stored in it as they are read.
+func readCheckpointV6Nodes(f *os.File, payloadStorage node.PayloadStorage, logger *zerolog.Logger) ([]*node.Node, []*trie.MTrie, error) {
+
+	checksums, err := readCheckpointV6Header(f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dir, filename := filepath.Split(f.Name())
+
+	parts := make([]*os.File, partCount)
+	defer func() {
+		for _, part := range parts {
+			if part == nil {
+				continue
+			}
+			evictErr := evictFileFromLinuxPageCache(part, false, logger)
+			if evictErr != nil {
+				logger.Warn().Msgf("failed to evict file %s from Linux page cache: %s", part.Name(), evictErr)
+				// No need to return this error because it's possible to continue normal operations.
+			}
+			_ = part.Close()
+		}
+	}()
+
+	// Open all part files and read their node counts to know where their nodes are placed
+	// in the combined list of nodes.
+	nodeCounts := make([]uint64, partCount)
+	for i := range parts {
+		parts[i], err = os.Open(filepath.Join(dir, partFileName(filename, i)))
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot open checkpoint part file %d: %w", i, err)
+		}
+
+		footerSize := encNodeCountSize
+		if i == topTriesPartIndex {
+			footerSize += encTrieCountSize
+		}
+		nodeCounts[i], err = readCheckpointV6PartFooter(parts[i], footerSize, checksums[i])
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot read checkpoint part file %d: %w", i, err)
+		}
+	}
+
+	totalNodeCount := uint64(0)
+	for _, count := range nodeCounts {
+		totalNodeCount += count
+	}
+
+	// nodes's element at index 0 is a special, meaning nil .
+	nodes := make([]*node.Node, 1, totalNodeCount+1)
+	offsets := make([]uint64, subtrieCount)
+	for i := 0; i < subtrieCount; i++ {
+		offsets[i] = uint64(len(nodes)) - 1
+		nodes = append(nodes, make([]*node.Node, nodeCounts[i])...)
+	}
+
+	// Each subtrie part file is read by its own goroutine into its range of the nodes.
+	group := new(errgroup.Group)
+	for i := 0; i < subtrieCount; i++ {
+		i := i
+		group.Go(func() error {
+			err := readSubtriePart(parts[i], nodes[offsets[i]:offsets[i]+nodeCounts[i]+1], payloadStorage)
+			if err != nil {
+				return fmt.Errorf("cannot read checkpoint part file %d: %w", i, err)
+			}
+			return nil
+		})
+	}
+	err = group.Wait()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes, tries, err := readCheckpointV5Nodes(parts[topTriesPartIndex], headerSize, nodes, payloadStorage)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read checkpoint part file %d: %w", topTriesPartIndex, err)
+	}
+
+	return nodes, tries, nil
+}
+
+// readCheckpointV6Header reads the checkpoint file (version 6) and returns the checksums of its part files.
+// Header (magic and version) is verified by the caller.
+func readCheckpointV6Header(f *os.File) ([]uint32, error) {
+
+	_, err := f.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("cannot seek to start of file: %w", err)
+	}
+
+	bufReader := bufio.NewReader(f)
+	crcReader := NewCRC32Reader(bufReader)
+
+	header := make([]byte, headerSize+encPartCountSize)
+	_, err = io.ReadFull(crcReader, header)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read header: %w", err)
+	}
+
+	parts := binary.BigEndian.Uint16(header[headerSize:])
+	if parts != partCount {
+		return nil, fmt.Errorf("unsupported checkpoint part count %d, expected %d", parts, partCount)
+	}
+
+	buf := make([]byte, crc32SumSize)
+	checksums := make([]uint32, parts)
+	for i := range checksums {
+		_, err = io.ReadFull(crcReader, buf)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read checksum of part file %d: %w", i, err)
+		}
+		checksums[i] = binary.BigEndian.Uint32(buf)
+	}
+
+	// Read CRC32
+	_, err = io.ReadFull(bufReader, buf)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read CRC32: %w", err)
+	}
+
+	readCrc32 := binary.BigEndian.Uint32(buf)
+
+	calculatedCrc32 := crcReader.Crc32()
+
+	if calculatedCrc32 != readCrc32 {
+		return nil, fmt.Errorf("checkpoint checksum failed! File contains %x but calculated crc32 is %x", readCrc32, calculatedCrc32)
+	}
+
+	return checksums, nil
+}
+
+// readCheckpointV6PartFooter verifies the header of the part file and that its CRC32 checksum matches
+// the expected checksum, and returns the node count from its footer of size footerSize.
+// The file content is verified against its checksum when it is read.
+func readCheckpointV6PartFooter(f *os.File, footerSize int, expectedChecksum uint32) (uint64, error) {
+
+	header := make([]byte, headerSize)
+	_, err := io.ReadFull(f, header)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read header: %w", err)
+	}
+
+	magicBytes := binary.BigEndian.Uint16(header)
+	version := binary.BigEndian.Uint16(header[encMagicSize:])
+	if magicBytes != MagicBytes {
+		return 0, fmt.Errorf("unknown file format. Magic constant %x does not match expected %x", magicBytes, MagicBytes)
+	}
+	if version != VersionV6 {
+		return 0, fmt.Errorf("unsupported file version %x", version)
+	}
+
+	// Seek to footer
+	_, err = f.Seek(-int64(footerSize+crc32SumSize), io.SeekEnd)
+	if err != nil {
+		return 0, fmt.Errorf("cannot seek to footer: %w", err)
+	}
+
+	footer := make([]byte, footerSize+crc32SumSize)
+	_, err = io.ReadFull(f, footer)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read footer: %w", err)
+	}
+
+	checksum := binary.BigEndian.Uint32(footer[footerSize:])
+	if checksum != expectedChecksum {
+		return 0, fmt.Errorf("part file checksum %x does not match checksum %x in checkpoint file", checksum, expectedChecksum)
+	}
+
+	return binary.BigEndian.Uint64(footer), nil
+}
+
+// readSubtriePart deserializes the nodes of the subtrie part file into nodes[1:].
+// Nodes reference their children by their index within the part file, where index 0 means nil.
+// If payloadStorage isn't nil, the leaf payloads are stored in it as they are read.
+// Header is verified by readCheckpointV6PartFooter.
+func readSubtriePart(f *os.File, nodes []*node.Node, payloadStorage node.PayloadStorage) error {
+
+	// Scratch buffer is used as temporary buffer that reader can read into.
+	// Raw data in scratch buffer should be copied or converted into desired
+	// objects before next Read operation.  If the scratch buffer isn't large
+	// enough, a new buffer will be allocated.  However, 4096 bytes will
+	// be large enough to handle almost all payloads and 100% of interim nodes.
+	scratch := make([]byte, 1024*4) // must not be less than 1024
+
+	_, err := f.Seek(0, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("cannot seek to start of file: %w", err)
+	}
+
+	var bufReader io.Reader = bufio.NewReaderSize(f, defaultBufioReadSize)
+	crcReader := NewCRC32Reader(bufReader)
+	var reader io.Reader = crcReader
+
+	_, err = io.ReadFull(reader, scratch[:headerSize])
+	if err != nil {
+		return fmt.Errorf("cannot read header: %w", err)
+	}
+
+	leaves := newLeafStorer(payloadStorage)
+	defer leaves.cancel()
+
+	for i := uint64(1); i < uint64(len(nodes)); i++ {
+		n, err := flattener.ReadNode(reader, scratch, func(nodeIndex uint64) (*node.Node, error) {
+			if nodeIndex >= i {
+				return nil, fmt.Errorf("sequence of serialized nodes does not satisfy Descendents-First-Relationship")
+			}
+			if nodeIndex == 0 {
+				return nil, nil
+			}
+			return nodes[nodeIndex], nil
+		})
+		if err != nil {
+			return fmt.Errorf("cannot read node %d: %w", i, err)
+		}
+		nodes[i], err = leaves.store(n)
+		if err != nil {
+			return fmt.Errorf("cannot store payload of node %d: %w", i, err)
+		}
+	}
+
+	err = leaves.flush()
+	if err != nil {
+		return err
+	}
+
+	// Read footer for crc32 computation
+	// No action is needed.
+	_, err = io.ReadFull(reader, scratch[:encNodeCountSize])
+	if err != nil {
+		return fmt.Errorf("cannot read footer: %w", err)
+	}
+
+	// Read CRC32
+	crc32buf := scratch[:crc32SumSize]
+	_, err = io.ReadFull(bufReader, crc32buf)
+	if err != nil {
+		return fmt.Errorf("cannot read CRC32: %w", err)
+	}
+
+	readCrc32 := binary.BigEndian.Uint32(crc32buf)
+
+	calculatedCrc32 := crcReader.Crc32()
+
+	if calculatedCrc32 != readCrc32 {
+		return fmt.Errorf("checkpoint checksum failed! File contains %x but calculated crc32 is %x", readCrc32, calculatedCrc32)
+	}
+
+	return nil
+}
@@ -45,6 +45,16 @@ const VersionV4 uint16 = 0x04
 // See EncodeNode() and EncodeTrie() for more details.
 const VersionV5 uint16 = 0x05
 
+// Version delta 5 is used by delta checkpoints, which only contain the nodes created since the
+// previous checkpoint and reference the nodes of the previous checkpoints by index.
+// Nodes and tries are encoded like in version 5. See StoreDeltaCheckpoint() for more details.
+const VersionDeltaV5 uint16 = 0x0105
+
+// Version 6 splits the tries at a fixed depth into subtries, which are stored in separate part files
+// and can be stored and loaded concurrently. Nodes and tries are encoded like in version 5.
+// See StoreCheckpointV6() for more details.
+const VersionV6 uint16 = 0x06
+
 const (
 	encMagicSize     = 2
 	encVersionSize   = 2
@@ -69,6 +79,7 @@ type Checkpointer struct {
 	wal            *DiskWAL
 	keyByteSize    int
 	forestCapacity int
+	payloadStorage node.PayloadStorage // storage of the leaf payloads of loaded tries (nil if payloads are held in memory)
 }
 
 func NewCheckpointer(wal *DiskWAL, keyByteSize int, forestCapacity int) *Checkpointer {
@@ -77,6 +88,7 @@ func NewCheckpointer(wal *DiskWAL, keyByteSize int, forestCapacity int) *Checkpo
 		wal:            wal,
 		keyByteSize:    keyByteSize,
 		forestCapacity: forestCapacity,
+		payloadStorage: wal.payloadStorage,
 	}
 }
 
@@ -168,7 +180,34 @@ func (c *Checkpointer) NotCheckpointedSegments() (from, to int, err error) {
 }
 
 // Checkpoint creates new checkpoint stopping at given segment
-func (c *Checkpointer) Checkpoint(to int, targetWriter func() (io.WriteCloser, error)) (err error) {
+func (c *Checkpointer) Checkpoint(to int, targetWriter func() (io.WriteCloser, error)) error {
+	return c.checkpoint(to, func(tries []*trie.MTrie) (err error) {
+		writer, err := targetWriter()
+		if err != nil {
+			return fmt.Errorf("cannot generate writer: %w", err)
+		}
+		defer func() {
+			closeErr := writer.Close()
+			// Return close error if there isn't any prior error to return.
+			if err == nil {
+				err = closeErr
+			}
+		}()
+
+		return StoreCheckpoint(writer, tries...)
+	})
+}
+
+// CheckpointV6 creates new version 6 checkpoint stopping at given segment.
+// The checkpoint part files are stored concurrently in the checkpoint directory.
+func (c *Checkpointer) CheckpointV6(to int) error {
+	return c.checkpoint(to, func(tries []*trie.MTrie) error {
+		return StoreCheckpointV6(tries, c.dir, NumberToFilename(to), &c.wal.log)
+	})
+}
+
+// checkpoint replays the WAL up to the given segment and stores the resulting tries as checkpoint.
+func (c *Checkpointer) checkpoint(to int, store func(tries []*trie.MTrie) error) error {
 
 	_, notCheckpointedTo, err := c.NotCheckpointedSegments()
 	if err != nil {
@@ -188,7 +227,7 @@ func (c *Checkpointer) Checkpoint(to int, targetWriter func() (io.WriteCloser, e
 		return fmt.Errorf("no segments to checkpoint to %d, latests not checkpointed segment: %d", to, notCheckpointedTo)
 	}
 
-	forest, err := mtrie.NewForest(c.forestCapacity, &metrics.NoopCollector{}, nil)
+	forest, err := mtrie.NewForestWithPayloadStorage(c.forestCapacity, &metrics.NoopCollector{}, nil, c.payloadStorage)
 	if err != nil {
 		return fmt.Errorf("cannot create Forest: %w", err)
 	}
@@ -217,19 +256,7 @@ func (c *Checkpointer) Checkpoint(to int, targetWriter func() (io.WriteCloser, e
 
 	c.wal.log.Info().Msgf("serializing checkpoint %d", to)
 
-	writer, err := targetWriter()
-	if err != nil {
-		return fmt.Errorf("cannot generate writer: %w", err)
-	}
-	defer func() {
-		closeErr := writer.Close()
-		// Return close error if there isn't any prior error to return.
-		if err == nil {
-			err = closeErr
-		}
-	}()
-
-	err = StoreCheckpoint(writer, tries...)
+	err = store(tries)
 
 	c.wal.log.Info().Msgf("created checkpoint %d with %d tries", to, len(tries))
 
@@ -285,70 +312,51 @@ func CreateCheckpointWriterForFile(dir, filename string, logger *zerolog.Logger)
 // When rebuilding the trie from the sequence of nodes, build the trie on the fly,
 // as for each node, the children have been previously encountered.
 // TODO: evaluate alternatives to CRC32 since checkpoint file is many GB in size.
-// TODO: add concurrency if the performance gains are enough to offset complexity.
+// See StoreCheckpointV6 for storing checkpoint files concurrently.
 func StoreCheckpoint(writer io.Writer, tries ...*trie.MTrie) error {
 
-	crc32Writer := NewCRC32Writer(writer)
-
-	// Scratch buffer is used as temporary buffer that node can encode into.
-	// Data in scratch buffer should be copied or used before scratch buffer is used again.
-	// If the scratch buffer isn't large enough, a new buffer will be allocated.
-	// However, 4096 bytes will be large enough to handle almost all payloads
-	// and 100% of interim nodes.
-	scratch := make([]byte, 1024*4)
-
 	// Write header: magic (2 bytes) + version (2 bytes)
-	header := scratch[:headerSize]
+	header := make([]byte, headerSize)
 	binary.BigEndian.PutUint16(header, MagicBytes)
 	binary.BigEndian.PutUint16(header[encMagicSize:], VersionV5)
 
-	_, err := crc32Writer.Write(header)
-	if err != nil {
-		return fmt.Errorf("cannot write checkpoint header: %w", err)
-	}
-
 	// allNodes contains all unique nodes of given tries and their index
 	// (ordered by node traversal sequence).
 	// Index 0 is a special case with nil node.
 	allNodes := make(map[*node.Node]uint64)
 	allNodes[nil] = 0
 
-	// Serialize all unique nodes
-	nodeCounter := uint64(1) // start from 1, as 0 marks nil node
-	for _, t := range tries {
+	_, err := storeCheckpoint(writer, header, allNodes, 1, tries)
+	return err
+}
 
-		// Traverse all unique nodes for trie t.
-		for itr := flattener.NewUniqueNodeIterator(t, allNodes); itr.Next(); {
-			n := itr.Value()
+// storeCheckpoint writes the given header, the nodes of the given tries which are not in `allNodes`,
+// the tries, the footer and the CRC32 file checksum, which it returns.
+// Nodes in `allNodes` are referenced by their index, and new nodes are indexed from firstNodeIndex on.
+func storeCheckpoint(writer io.Writer, header []byte, allNodes map[*node.Node]uint64, firstNodeIndex uint64, tries []*trie.MTrie) (uint32, error) {
 
-			allNodes[n] = nodeCounter
-			nodeCounter++
+	crc32Writer := NewCRC32Writer(writer)
 
-			var lchildIndex, rchildIndex uint64
+	// Scratch buffer is used as temporary buffer that node can encode into.
+	// Data in scratch buffer should be copied or used before scratch buffer is used again.
+	// If the scratch buffer isn't large enough, a new buffer will be allocated.
+	// However, 4096 bytes will be large enough to handle almost all payloads
+	// and 100% of interim nodes.
+	scratch := make([]byte, 1024*4)
 
-			if lchild := n.LeftChild(); lchild != nil {
-				var found bool
-				lchildIndex, found = allNodes[lchild]
-				if !found {
-					hash := lchild.Hash()
-					return fmt.Errorf("internal error: missing node with hash %s", hex.EncodeToString(hash[:]))
-				}
-			}
-			if rchild := n.RightChild(); rchild != nil {
-				var found bool
-				rchildIndex, found = allNodes[rchild]
-				if !found {
-					hash := rchild.Hash()
-					return fmt.Errorf("internal error: missing node with hash %s", hex.EncodeToString(hash[:]))
-				}
-			}
+	_, err := crc32Writer.Write(header)
+	if err != nil {
+		return 0, fmt.Errorf("cannot write checkpoint header: %w", err)
+	}
 
-			encNode := flattener.EncodeNode(n, lchildIndex, rchildIndex, scratch)
-			_, err = crc32Writer.Write(encNode)
-			if err != nil {
-				return fmt.Errorf("cannot serialize node: %w", err)
-			}
-		}
+	// Serialize all unique nodes
+	roots := make([]*node.Node, len(tries))
+	for i, t := range tries {
+		roots[i] = t.RootNode()
+	}
+	nodeCounter, err := storeUniqueNodes(crc32Writer, roots, allNodes, firstNodeIndex, scratch)
+	if err != nil {
+		return 0, err
 	}
 
 	// Serialize trie root nodes
@@ -359,24 +367,24 @@ func StoreCheckpoint(writer io.Writer, tries ...*trie.MTrie) error {
 		rootIndex, found := allNodes[rootNode]
 		if !found {
 			rootHash := t.RootHash()
-			return fmt.Errorf("internal error: missing node with hash %s", hex.EncodeToString(rootHash[:]))
+			return 0, fmt.Errorf("internal error: missing node with hash %s", hex.EncodeToString(rootHash[:]))
 		}
 
 		encTrie := flattener.EncodeTrie(t, rootIndex, scratch)
 		_, err = crc32Writer.Write(encTrie)
 		if err != nil {
-			return fmt.Errorf("cannot serialize trie: %w", err)
+			return 0, fmt.Errorf("cannot serialize trie: %w", err)
 		}
 	}
 
 	// Write footer with nodes count and tries count
 	footer := scratch[:encNodeCountSize+encTrieCountSize]
-	binary.BigEndian.PutUint64(footer, uint64(len(allNodes)-1)) // -1 to account for 0 node meaning nil
+	binary.BigEndian.PutUint64(footer, nodeCounter-firstNodeIndex)
 	binary.BigEndian.PutUint16(footer[encNodeCountSize:], uint16(len(tries)))
 
 	_, err = crc32Writer.Write(footer)
 	if err != nil {
-		return fmt.Errorf("cannot write checkpoint footer: %w", err)
+		return 0, fmt.Errorf("cannot write checkpoint footer: %w", err)
 	}
 
 	// Write CRC32 sum
@@ -385,20 +393,74 @@ func StoreCheckpoint(writer io.Writer, tries ...*trie.MTrie) error {
 
 	_, err = writer.Write(crc32buf)
 	if err != nil {
-		return fmt.Errorf("cannot write CRC32: %w", err)
+		return 0, fmt.Errorf("cannot write CRC32: %w", err)
 	}
 
-	return nil
+	return crc32Writer.Crc32(), nil
 }
 
+// storeUniqueNodes writes the nodes of the subtries with the given roots which are not in `allNodes`,
+// in an order satisfying the Descendents-First-Relationship, and adds them to `allNodes`.
+// Nodes are indexed from nodeIndex on, and the index following the last written node is returned.
+func storeUniqueNodes(writer io.Writer, roots []*node.Node, allNodes map[*node.Node]uint64, nodeIndex uint64, scratch []byte) (uint64, error) {
+	for _, root := range roots {
+		// Skip subtries which were stored already, for example by the checkpoints a delta checkpoint is based on.
+		if _, ok := allNodes[root]; ok {
+			continue
+		}
+
+		// Traverse all unique nodes of the subtrie.
+		for itr := flattener.NewUniqueSubtrieNodeIterator(root, allNodes); itr.Next(); {
+			n := itr.Value()
+
+			allNodes[n] = nodeIndex
+			nodeIndex++
+
+			var lchildIndex, rchildIndex uint64
+
+			if lchild := n.LeftChild(); lchild != nil {
+				var found bool
+				lchildIndex, found = allNodes[lchild]
+				if !found {
+					hash := lchild.Hash()
+					return 0, fmt.Errorf("internal error: missing node with hash %s", hex.EncodeToString(hash[:]))
+				}
+			}
+			if rchild := n.RightChild(); rchild != nil {
+				var found bool
+				rchildIndex, found = allNodes[rchild]
+				if !found {
+					hash := rchild.Hash()
+					return 0, fmt.Errorf("internal error: missing node with hash %s", hex.EncodeToString(hash[:]))
+				}
+			}
+
+			encNode, err := flattener.EncodeNode(n, lchildIndex, rchildIndex, scratch)
+			if err != nil {
+				return 0, fmt.Errorf("cannot encode node: %w", err)
+			}
+			_, err = writer.Write(encNode)
+			if err != nil {
+				return 0, fmt.Errorf("cannot serialize node: %w", err)
+			}
+		}
+	}
+
+	return nodeIndex, nil
+}
+
+// LoadCheckpoint loads the tries of the checkpoint, whose leaf payloads are stored in the payload
+// storage of the WAL as they are read, if it has one.
 func (c *Checkpointer) LoadCheckpoint(checkpoint int) ([]*trie.MTrie, error) {
 	filepath := path.Join(c.dir, NumberToFilename(checkpoint))
-	return LoadCheckpoint(filepath, &c.wal.log)
+	return loadCheckpoint(filepath, c.payloadStorage, &c.wal.log)
 }
 
+// LoadRootCheckpoint loads the tries of the root checkpoint, whose leaf payloads are stored in the
+// payload storage of the WAL as they are read, if it has one.
 func (c *Checkpointer) LoadRootCheckpoint() ([]*trie.MTrie, error) {
 	filepath := path.Join(c.dir, bootstrap.FilenameWALRootCheckpoint)
-	return LoadCheckpoint(filepath, &c.wal.log)
+	return loadCheckpoint(filepath, c.payloadStorage, &c.wal.log)
 }
 
 func (c *Checkpointer) HasRootCheckpoint() (bool, error) {
@@ -411,11 +473,23 @@ func (c *Checkpointer) HasRootCheckpoint() (bool, error) {
 	}
 }
 
+// RemoveCheckpoint removes the checkpoint file, and the part files of version 6 checkpoints.
 func (c *Checkpointer) RemoveCheckpoint(checkpoint int) error {
-	return os.Remove(path.Join(c.dir, NumberToFilename(checkpoint)))
+	err := os.Remove(path.Join(c.dir, NumberToFilename(checkpoint)))
+	if err != nil {
+		return err
+	}
+	return removeCheckpointParts(c.dir, NumberToFilename(checkpoint))
 }
 
 func LoadCheckpoint(filepath string, logger *zerolog.Logger) ([]*trie.MTrie, error) {
+	return loadCheckpoint(filepath, nil, logger)
+}
+
+// loadCheckpoint loads the tries of the checkpoint file. If payloadStorage isn't nil, the leaf payloads
+// are stored in it as they are read, so they aren't held in memory, except for checkpoints of version 4
+// and earlier, whose tries are loaded into memory and converted when they are added to a forest.
+func loadCheckpoint(filepath string, payloadStorage node.PayloadStorage, logger *zerolog.Logger) ([]*trie.MTrie, error) {
 	file, err := os.Open(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("cannot open checkpoint file %s: %w", filepath, err)
@@ -430,10 +504,10 @@ func LoadCheckpoint(filepath string, logger *zerolog.Logger) ([]*trie.MTrie, err
 		_ = file.Close()
 	}()
 
-	return readCheckpoint(file)
+	return readCheckpoint(file, payloadStorage, logger)
 }
 
-func readCheckpoint(f *os.File) ([]*trie.MTrie, error) {
+func readCheckpoint(f *os.File, payloadStorage node.PayloadStorage, logger *zerolog.Logger) ([]*trie.MTrie, error) {
 
 	// Read header: magic (2 bytes) + version (2 bytes)
 	header := make([]byte, headerSize)
@@ -462,7 +536,12 @@ func readCheckpoint(f *os.File) ([]*trie.MTrie, error) {
 	case VersionV4:
 		return readCheckpointV4(f)
 	case VersionV5:
-		return readCheckpointV5(f)
+		return readCheckpointV5(f, payloadStorage)
+	case VersionDeltaV5:
+		_, tries, err := readDeltaCheckpoint(f, 0, payloadStorage, logger)
+		return tries, err
+	case VersionV6:
+		return readCheckpointV6(f, payloadStorage, logger)
 	default:
 		return nil, fmt.Errorf("unsupported file version %x", version)
 	}
@@ -671,7 +750,17 @@ func readCheckpointV4(f *os.File) ([]*trie.MTrie, error) {
 
 // readCheckpointV5 decodes checkpoint file (version 5) and returns a list of tries.
 // Checkpoint file header (magic and version) are verified by the caller.
-func readCheckpointV5(f *os.File) ([]*trie.MTrie, error) {
+func readCheckpointV5(f *os.File, payloadStorage node.PayloadStorage) ([]*trie.MTrie, error) {
+	_, tries, err := readCheckpointV5Nodes(f, headerSize, nil, payloadStorage)
+	return tries, err
+}
+
+// readCheckpointV5Nodes deserializes the nodes and tries of a checkpoint file using the version 5
+// encoding, whose header has size fileHeaderSize, and returns all nodes and the tries.
+// The file's nodes are appended to the given nodes, which they can reference by index.
+// The given nodes must be nil or start with the nil node at index 0.
+// If payloadStorage isn't nil, the payloads of the file's leaves are stored in it as they are read.
+func readCheckpointV5Nodes(f *os.File, fileHeaderSize int, nodes []*node.Node, payloadStorage node.PayloadStorage) ([]*node.Node, []*trie.MTrie, error) {
 
 	// Scratch buffer is used as temporary buffer that reader can read into.
 	// Raw data in scratch buffer should be copied or converted into desired
@@ -689,14 +778,14 @@ func readCheckpointV5(f *os.File) ([]*trie.MTrie, error) {
 	// Seek to footer
 	_, err := f.Seek(-footerOffset, io.SeekEnd)
 	if err != nil {
-		return nil, fmt.Errorf("cannot seek to footer: %w", err)
+		return nil, nil, fmt.Errorf("cannot seek to footer: %w", err)
 	}
 
 	footer := scratch[:footerSize]
 
 	_, err = io.ReadFull(f, footer)
 	if err != nil {
-		return nil, fmt.Errorf("cannot read footer: %w", err)
+		return nil, nil, fmt.Errorf("cannot read footer: %w", err)
 	}
 
 	// Decode node count and trie count
@@ -706,26 +795,33 @@ func readCheckpointV5(f *os.File) ([]*trie.MTrie, error) {
 	// Seek to the start of file
 	_, err = f.Seek(0, io.SeekStart)
 	if err != nil {
-		return nil, fmt.Errorf("cannot seek to start of file: %w", err)
+		return nil, nil, fmt.Errorf("cannot seek to start of file: %w", err)
 	}
 
 	var bufReader io.Reader = bufio.NewReaderSize(f, defaultBufioReadSize)
 	crcReader := NewCRC32Reader(bufReader)
 	var reader io.Reader = crcReader
 
-	// Read header: magic (2 bytes) + version (2 bytes)
+	// Read header: magic (2 bytes) + version (2 bytes), followed by version specific fields
 	// No action is needed for header because it is verified by the caller.
 
-	_, err = io.ReadFull(reader, scratch[:headerSize])
+	_, err = io.ReadFull(reader, scratch[:fileHeaderSize])
 	if err != nil {
-		return nil, fmt.Errorf("cannot read header: %w", err)
+		return nil, nil, fmt.Errorf("cannot read header: %w", err)
 	}
 
 	// nodes's element at index 0 is a special, meaning nil .
-	nodes := make([]*node.Node, nodesCount+1) //+1 for 0 index meaning nil
+	if len(nodes) == 0 {
+		nodes = make([]*node.Node, 1, nodesCount+1) //+1 for 0 index meaning nil
+	}
+	firstNodeIndex := uint64(len(nodes))
+	nodes = append(nodes, make([]*node.Node, nodesCount)...)
 	tries := make([]*trie.MTrie, triesCount)
 
-	for i := uint64(1); i <= nodesCount; i++ {
+	leaves := newLeafStorer(payloadStorage)
+	defer leaves.cancel()
+
+	for i := firstNodeIndex; i < firstNodeIndex+nodesCount; i++ {
 		n, err := flattener.ReadNode(reader, scratch, func(nodeIndex uint64) (*node.Node, error) {
 			if nodeIndex >= uint64(i) {
 				return nil, fmt.Errorf("sequence of serialized nodes does not satisfy Descendents-First-Relationship")
@@ -733,9 +829,17 @@ func readCheckpointV5(f *os.File) ([]*trie.MTrie, error) {
 			return nodes[nodeIndex], nil
 		})
 		if err != nil {
-			return nil, fmt.Errorf("cannot read node %d: %w", i, err)
+			return nil, nil, fmt.Errorf("cannot read node %d: %w", i, err)
+		}
+		nodes[i], err = leaves.store(n)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot store payload of node %d: %w", i, err)
 		}
-		nodes[i] = n
+	}
+
+	err = leaves.flush()
+	if err != nil {
+		return nil, nil, err
 	}
 
 	for i := uint16(0); i < triesCount; i++ {
@@ -746,23 +850,26 @@ func readCheckpointV5(f *os.File) ([]*trie.MTrie, error) {
 			return nodes[nodeIndex], nil
 		})
 		if err != nil {
-			return nil, fmt.Errorf("cannot read trie %d: %w", i, err)
+			return nil, nil, fmt.Errorf("cannot read trie %d: %w", i, err)
+		}
+		tries[i], err = leaves.trie(trie)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot read trie %d: %w", i, err)
 		}
-		tries[i] = trie
 	}
 
 	// Read footer again for crc32 computation
 	// No action is needed.
 	_, err = io.ReadFull(reader, footer)
 	if err != nil {
-		return nil, fmt.Errorf("cannot read footer: %w", err)
+		return nil, nil, fmt.Errorf("cannot read footer: %w", err)
 	}
 
 	// Read CRC32
 	crc32buf := scratch[:crc32SumSize]
 	_, err = io.ReadFull(bufReader, crc32buf)
 	if err != nil {
-		return nil, fmt.Errorf("cannot read CRC32: %w", err)
+		return nil, nil, fmt.Errorf("cannot read CRC32: %w", err)
 	}
 
 	readCrc32 := binary.BigEndian.Uint32(crc32buf)
@@ -770,10 +877,10 @@ func readCheckpointV5(f *os.File) ([]*trie.MTrie, error) {
 	calculatedCrc32 := crcReader.Crc32()
 
 	if calculatedCrc32 != readCrc32 {
-		return nil, fmt.Errorf("checkpoint checksum failed! File contains %x but calculated crc32 is %x", readCrc32, calculatedCrc32)
+		return nil, nil, fmt.Errorf("checkpoint checksum failed! File contains %x but calculated crc32 is %x", readCrc32, calculatedCrc32)
 	}
 
-	return tries, nil
+	return nodes, tries, nil
 }
 
 // EvictAllCheckpointsFromLinuxPageCache advises Linux to evict all checkpoint files
@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/dgraph-io/badger/v2"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,6 +23,8 @@ import (
 	"github.com/onflow/flow-go/ledger/common/utils"
 	"github.com/onflow/flow-go/ledger/complete"
 	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/payloadstore"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	"github.com/onflow/flow-go/ledger/complete/wal"
 	realWAL "github.com/onflow/flow-go/ledger/complete/wal"
@@ -571,11 +574,413 @@ func Test_StoringLoadingCheckpoints(t *testing.T) {
 	})
 }
 
+func Test_StoringLoadingCheckpointV6(t *testing.T) {
+
+	unittest.RunWithTempDir(t, func(dir string) {
+
+		f, err := mtrie.NewForest(size*10, metricsCollector, nil)
+		require.NoError(t, err)
+
+		// tries share nodes, and contain leaves above and below the depth at which they are split
+		emptyTrie := trie.NewEmptyMTrie()
+		singleLeafTrie, _, err := trie.NewTrieWithUpdatedRegisters(emptyTrie, utils.RandomPaths(1), []ledger.Payload{*utils.LightPayload8('A', 'a')}, true)
+		require.NoError(t, err)
+		tries := []*trie.MTrie{emptyTrie, singleLeafTrie}
+
+		rootHash := f.GetEmptyRootHash()
+		for i := 0; i < size; i++ {
+			paths := utils.RandomPaths(100)
+			payloads := utils.RandomPayloads(100, 2, 100)
+
+			rootHash, err = f.Update(&ledger.TrieUpdate{RootHash: rootHash, Paths: paths, Payloads: payloads})
+			require.NoError(t, err)
+
+			updatedTrie, err := f.GetTrie(rootHash)
+			require.NoError(t, err)
+			tries = append(tries, updatedTrie)
+		}
+
+		logger := zerolog.Nop()
+		err = realWAL.StoreCheckpointV6(tries, dir, "checkpoint.00000001", &logger)
+		require.NoError(t, err)
+
+		t.Run("stores part files", func(t *testing.T) {
+			require.FileExists(t, path.Join(dir, "checkpoint.00000001"))
+			for i := 0; i <= 16; i++ {
+				require.FileExists(t, path.Join(dir, fmt.Sprintf("checkpoint.00000001.%03d", i)))
+			}
+		})
+
+		t.Run("works without data modification", func(t *testing.T) {
+			loadedTries, err := realWAL.LoadCheckpoint(path.Join(dir, "checkpoint.00000001"), &logger)
+			require.NoError(t, err)
+			require.Equal(t, len(tries), len(loadedTries))
+			for i, loaded := range loadedTries {
+				require.True(t, tries[i].Equals(loaded))
+				require.Equal(t, tries[i].AllocatedRegCount(), loaded.AllocatedRegCount())
+				require.Equal(t, tries[i].AllocatedRegSize(), loaded.AllocatedRegSize())
+			}
+		})
+
+		t.Run("loads the same tries as version 5", func(t *testing.T) {
+			file, err := os.Create(path.Join(dir, "checkpoint-v5"))
+			require.NoError(t, err)
+			err = realWAL.StoreCheckpoint(file, tries...)
+			require.NoError(t, err)
+			require.NoError(t, file.Close())
+
+			triesV5, err := realWAL.LoadCheckpoint(path.Join(dir, "checkpoint-v5"), &logger)
+			require.NoError(t, err)
+			triesV6, err := realWAL.LoadCheckpoint(path.Join(dir, "checkpoint.00000001"), &logger)
+			require.NoError(t, err)
+			require.Equal(t, len(triesV5), len(triesV6))
+			for i := range triesV5 {
+				require.Equal(t, triesV5[i].RootHash(), triesV6[i].RootHash())
+			}
+		})
+
+		t.Run("keeps existing checkpoint", func(t *testing.T) {
+			err := realWAL.StoreCheckpointV6(tries, dir, "checkpoint.00000001", &logger)
+			require.Error(t, err)
+
+			_, err = realWAL.LoadCheckpoint(path.Join(dir, "checkpoint.00000001"), &logger)
+			require.NoError(t, err)
+		})
+
+		t.Run("detects mismatching part file", func(t *testing.T) {
+			err := realWAL.StoreCheckpointV6(tries[:1], dir, "checkpoint.00000002", &logger)
+			require.NoError(t, err)
+
+			// replace a part file with the part file of the other checkpoint
+			err = os.Rename(path.Join(dir, "checkpoint.00000002.016"), path.Join(dir, "checkpoint.00000001.016"))
+			require.NoError(t, err)
+
+			tries, err := realWAL.LoadCheckpoint(path.Join(dir, "checkpoint.00000001"), &logger)
+			require.Error(t, err)
+			require.Nil(t, tries)
+			require.Contains(t, err.Error(), "checksum")
+		})
+
+		t.Run("detects modified data", func(t *testing.T) {
+			err := realWAL.StoreCheckpointV6(tries, dir, "checkpoint.00000003", &logger)
+			require.NoError(t, err)
+
+			randomlyModifyFile(t, path.Join(dir, "checkpoint.00000003.005"))
+
+			tries, err := realWAL.LoadCheckpoint(path.Join(dir, "checkpoint.00000003"), &logger)
+			require.Error(t, err)
+			require.Nil(t, tries)
+		})
+
+		t.Run("detects missing part file", func(t *testing.T) {
+			err := realWAL.StoreCheckpointV6(tries, dir, "checkpoint.00000004", &logger)
+			require.NoError(t, err)
+
+			err = os.Remove(path.Join(dir, "checkpoint.00000004.010"))
+			require.NoError(t, err)
+
+			tries, err := realWAL.LoadCheckpoint(path.Join(dir, "checkpoint.00000004"), &logger)
+			require.Error(t, err)
+			require.Nil(t, tries)
+		})
+	})
+}
+
+func Test_DeltaCheckpointing(t *testing.T) {
+
+	unittest.RunWithTempDir(t, func(dir string) {
+
+		f, err := mtrie.NewForest(size*10, metricsCollector, nil)
+		require.NoError(t, err)
+
+		rootHash := f.GetEmptyRootHash()
+
+		diskWAL, err := realWAL.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, size*10, pathByteSize, segmentSize)
+		require.NoError(t, err)
+
+		// every update is larger than a segment, so we get at least `size` segments
+		for i := 0; i < size; i++ {
+			keys := utils.RandomUniqueKeys(numInsPerStep, keyNumberOfParts, 1600, 1600)
+			values := utils.RandomValues(numInsPerStep, valueMaxByteSize/2, valueMaxByteSize)
+			update, err := ledger.NewUpdate(ledger.State(rootHash), keys, values)
+			require.NoError(t, err)
+
+			trieUpdate, err := pathfinder.UpdateToTrieUpdate(update, pathFinderVersion)
+			require.NoError(t, err)
+
+			err = diskWAL.RecordUpdate(trieUpdate)
+			require.NoError(t, err)
+
+			rootHash, err = f.Update(trieUpdate)
+			require.NoError(t, err)
+		}
+		<-diskWAL.Done()
+
+		require.FileExists(t, path.Join(dir, "00000010")) //make sure we have enough segments saved
+
+		diskWAL2, err := realWAL.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, size*10, pathByteSize, segmentSize)
+		require.NoError(t, err)
+		checkpointer, err := diskWAL2.NewCheckpointer()
+		require.NoError(t, err)
+
+		// delta checkpoints need a previous checkpoint
+		err = checkpointer.DeltaCheckpoint(2, func() (io.WriteCloser, error) {
+			return checkpointer.CheckpointWriter(2)
+		})
+		require.True(t, errors.Is(err, realWAL.ErrNoDeltaCheckpointBase))
+
+		err = checkpointer.Checkpoint(2, func() (io.WriteCloser, error) {
+			return checkpointer.CheckpointWriter(2)
+		})
+		require.NoError(t, err)
+
+		err = checkpointer.DeltaCheckpoint(5, func() (io.WriteCloser, error) {
+			return checkpointer.CheckpointWriter(5)
+		})
+		require.NoError(t, err)
+
+		// full checkpoint with the same state as the delta checkpoint 8, for comparison
+		fullDir := t.TempDir()
+		err = checkpointer.Checkpoint(8, func() (io.WriteCloser, error) {
+			return realWAL.CreateCheckpointWriterForFile(fullDir, realWAL.NumberToFilename(8), &logger)
+		})
+		require.NoError(t, err)
+
+		err = checkpointer.DeltaCheckpoint(8, func() (io.WriteCloser, error) {
+			return checkpointer.CheckpointWriter(8)
+		})
+		require.NoError(t, err)
+
+		<-diskWAL2.Done()
+
+		checkpoints, err := checkpointer.Checkpoints()
+		require.NoError(t, err)
+		require.Equal(t, []int{2, 5, 8}, checkpoints)
+
+		chain, err := checkpointer.CheckpointChain(8)
+		require.NoError(t, err)
+		require.Equal(t, []int{2, 5, 8}, chain)
+
+		chain, err = checkpointer.CheckpointChain(2)
+		require.NoError(t, err)
+		require.Equal(t, []int{2}, chain)
+
+		t.Run("delta checkpoint is smaller than full checkpoint", func(t *testing.T) {
+			deltaInfo, err := os.Stat(path.Join(dir, realWAL.NumberToFilename(8)))
+			require.NoError(t, err)
+			fullInfo, err := os.Stat(path.Join(fullDir, realWAL.NumberToFilename(8)))
+			require.NoError(t, err)
+			require.Less(t, deltaInfo.Size(), fullInfo.Size())
+		})
+
+		t.Run("delta checkpoint loads the same tries as full checkpoint", func(t *testing.T) {
+			deltaTries, err := checkpointer.LoadCheckpoint(8)
+			require.NoError(t, err)
+			fullTries, err := realWAL.LoadCheckpoint(path.Join(fullDir, realWAL.NumberToFilename(8)), &logger)
+			require.NoError(t, err)
+
+			require.Equal(t, len(fullTries), len(deltaTries))
+			for i := range fullTries {
+				require.True(t, fullTries[i].Equals(deltaTries[i]))
+				requireSamePayloads(t, fullTries[i], deltaTries[i])
+			}
+		})
+
+		t.Run("WAL is replayed from delta checkpoint", func(t *testing.T) {
+			f2, err := mtrie.NewForest(size*10, metricsCollector, nil)
+			require.NoError(t, err)
+
+			diskWAL3, err := realWAL.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, size*10, pathByteSize, segmentSize)
+			require.NoError(t, err)
+			err = diskWAL3.ReplayOnForest(f2)
+			require.NoError(t, err)
+			<-diskWAL3.Done()
+
+			expectedTrie, err := f.GetTrie(rootHash)
+			require.NoError(t, err)
+			replayedTrie, err := f2.GetTrie(rootHash)
+			require.NoError(t, err)
+			require.True(t, expectedTrie.Equals(replayedTrie))
+			requireSamePayloads(t, expectedTrie, replayedTrie)
+		})
+
+		t.Run("delta checkpoint fails to load without the checkpoint it is based on", func(t *testing.T) {
+			err := checkpointer.RemoveCheckpoint(5)
+			require.NoError(t, err)
+
+			_, err = checkpointer.LoadCheckpoint(8)
+			require.Error(t, err)
+		})
+	})
+}
+
+func Test_DeltaCheckpointChainLength(t *testing.T) {
+	unittest.RunWithTempDir(t, func(dir string) {
+		logger := zerolog.Nop()
+
+		writer, err := realWAL.CreateCheckpointWriterForFile(dir, realWAL.NumberToFilename(0), &logger)
+		require.NoError(t, err)
+		require.NoError(t, realWAL.StoreCheckpoint(writer))
+		require.NoError(t, writer.Close())
+
+		// checkpoints without tries, each delta checkpoint based on the previous checkpoint
+		for i := 1; i <= realWAL.MaxDeltaCheckpoints+1; i++ {
+			writer, err := realWAL.CreateCheckpointWriterForFile(dir, realWAL.NumberToFilename(i), &logger)
+			require.NoError(t, err)
+			require.NoError(t, realWAL.StoreDeltaCheckpoint(writer, i-1, []*node.Node{nil}))
+			require.NoError(t, writer.Close())
+		}
+
+		diskWAL, err := realWAL.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, 10, pathfinder.PathByteSize, realWAL.SegmentSize)
+		require.NoError(t, err)
+		checkpointer, err := diskWAL.NewCheckpointer()
+		require.NoError(t, err)
+
+		chain, err := checkpointer.CheckpointChain(realWAL.MaxDeltaCheckpoints)
+		require.NoError(t, err)
+		require.Len(t, chain, realWAL.MaxDeltaCheckpoints+1)
+
+		_, err = checkpointer.LoadCheckpoint(realWAL.MaxDeltaCheckpoints)
+		require.NoError(t, err)
+
+		// chains with too many delta checkpoints are rejected
+		_, err = checkpointer.CheckpointChain(realWAL.MaxDeltaCheckpoints + 1)
+		require.Error(t, err)
+
+		_, err = checkpointer.LoadCheckpoint(realWAL.MaxDeltaCheckpoints + 1)
+		require.Error(t, err)
+	})
+}
+
 type writeCloserWithErrors struct {
 	writeError error
 	closeError error
 }
 
+// requireSamePayloads checks that both tries hold the same payloads.
+// Test_CheckpointingWithPayloadStorage verifies that the payloads of checkpoints loaded by a WAL with a
+// payload storage are stored in the storage, for full, version 6 and delta checkpoints.
+func Test_CheckpointingWithPayloadStorage(t *testing.T) {
+
+	unittest.RunWithTempDir(t, func(dir string) {
+		unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+
+			// a small cache makes most reads go to the database
+			storage, err := payloadstore.New(db, 8)
+			require.NoError(t, err)
+
+			f, err := mtrie.NewForest(size*10, metricsCollector, nil)
+			require.NoError(t, err)
+
+			rootHash := f.GetEmptyRootHash()
+
+			diskWAL, err := realWAL.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, size*10, pathByteSize, segmentSize)
+			require.NoError(t, err)
+
+			// every update is larger than a segment, so we get at least `size` segments
+			for i := 0; i < size; i++ {
+				keys := utils.RandomUniqueKeys(numInsPerStep, keyNumberOfParts, 1600, 1600)
+				values := utils.RandomValues(numInsPerStep, valueMaxByteSize/2, valueMaxByteSize)
+				update, err := ledger.NewUpdate(ledger.State(rootHash), keys, values)
+				require.NoError(t, err)
+
+				trieUpdate, err := pathfinder.UpdateToTrieUpdate(update, pathFinderVersion)
+				require.NoError(t, err)
+
+				err = diskWAL.RecordUpdate(trieUpdate)
+				require.NoError(t, err)
+
+				rootHash, err = f.Update(trieUpdate)
+				require.NoError(t, err)
+			}
+			<-diskWAL.Done()
+
+			diskWAL2, err := realWAL.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, size*10, pathByteSize, segmentSize)
+			require.NoError(t, err)
+			diskWAL2.SetPayloadStorage(storage)
+			checkpointer, err := diskWAL2.NewCheckpointer()
+			require.NoError(t, err)
+
+			err = checkpointer.Checkpoint(2, func() (io.WriteCloser, error) {
+				return checkpointer.CheckpointWriter(2)
+			})
+			require.NoError(t, err)
+
+			err = checkpointer.CheckpointV6(5)
+			require.NoError(t, err)
+
+			err = checkpointer.DeltaCheckpoint(8, func() (io.WriteCloser, error) {
+				return checkpointer.CheckpointWriter(8)
+			})
+			require.NoError(t, err)
+
+			<-diskWAL2.Done()
+
+			for _, checkpoint := range []int{2, 5, 8} {
+				t.Run(fmt.Sprintf("checkpoint %d is loaded into the payload storage", checkpoint), func(t *testing.T) {
+					storedTries, err := checkpointer.LoadCheckpoint(checkpoint)
+					require.NoError(t, err)
+					memTries, err := realWAL.LoadCheckpoint(path.Join(dir, realWAL.NumberToFilename(checkpoint)), &logger)
+					require.NoError(t, err)
+
+					require.Equal(t, len(memTries), len(storedTries))
+					for i := range memTries {
+						require.Equal(t, node.PayloadStorage(storage), storedTries[i].PayloadStorage())
+						require.Nil(t, memTries[i].PayloadStorage())
+						require.True(t, memTries[i].Equals(storedTries[i]))
+						require.Equal(t, memTries[i].AllocatedRegCount(), storedTries[i].AllocatedRegCount())
+						require.Equal(t, memTries[i].AllocatedRegSize(), storedTries[i].AllocatedRegSize())
+						requireSamePayloads(t, memTries[i], storedTries[i])
+						requireStoredLeaves(t, storedTries[i].RootNode())
+					}
+				})
+			}
+
+			t.Run("WAL is replayed on a forest with the payload storage", func(t *testing.T) {
+				f2, err := mtrie.NewForestWithPayloadStorage(size*10, metricsCollector, nil, storage)
+				require.NoError(t, err)
+
+				diskWAL3, err := realWAL.NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, size*10, pathByteSize, segmentSize)
+				require.NoError(t, err)
+				diskWAL3.SetPayloadStorage(storage)
+				err = diskWAL3.ReplayOnForest(f2)
+				require.NoError(t, err)
+				<-diskWAL3.Done()
+
+				expectedTrie, err := f.GetTrie(rootHash)
+				require.NoError(t, err)
+				replayedTrie, err := f2.GetTrie(rootHash)
+				require.NoError(t, err)
+				require.Equal(t, node.PayloadStorage(storage), replayedTrie.PayloadStorage())
+				require.True(t, expectedTrie.Equals(replayedTrie))
+				requireSamePayloads(t, expectedTrie, replayedTrie)
+				requireStoredLeaves(t, replayedTrie.RootNode())
+			})
+		})
+	})
+}
+
+// requireStoredLeaves requires that all leaves of the subtrie store their payloads in a payload storage.
+func requireStoredLeaves(t *testing.T, n *node.Node) {
+	if n == nil {
+		return
+	}
+	if n.IsLeaf() {
+		require.True(t, n.IsPayloadStored())
+		return
+	}
+	requireStoredLeaves(t, n.LeftChild())
+	requireStoredLeaves(t, n.RightChild())
+}
+
+func requireSamePayloads(t *testing.T, expected *trie.MTrie, actual *trie.MTrie) {
+	expectedPayloads, err := expected.AllPayloads()
+	require.NoError(t, err)
+	actualPayloads, err := actual.AllPayloads()
+	require.NoError(t, err)
+	require.Equal(t, expectedPayloads, actualPayloads)
+}
+
 func newWriteCloserWithErrors(writeError error, closeError error) *writeCloserWithErrors {
 	return &writeCloserWithErrors{
 		writeError: writeError,
@@ -1,8 +1,10 @@
 package wal
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"path"
 	"sync"
 	"time"
 
@@ -22,21 +24,44 @@ type Compactor struct {
 	interval           time.Duration
 	checkpointDistance uint
 	checkpointsToKeep  uint
+	// maxDeltaCheckpoints is the maximum number of delta checkpoints based on a full checkpoint,
+	// 0 if only full checkpoints are created
+	maxDeltaCheckpoints uint
+	// checkpointV6 is true if full checkpoints are created in version 6 instead of version 5
+	checkpointV6 bool
 }
 
-func NewCompactor(checkpointer *Checkpointer, interval time.Duration, checkpointDistance uint, checkpointsToKeep uint, logger zerolog.Logger) *Compactor {
+// NewCompactor creates a compactor which creates a checkpoint every `checkpointDistance` segments, and keeps
+// the latest `checkpointsToKeep` checkpoints (0 to keep all). Up to `maxDeltaCheckpoints` delta checkpoints are
+// created between full checkpoints, which bounds the number of checkpoints loaded to restore the state.
+// `maxDeltaCheckpoints` is capped to MaxDeltaCheckpoints.
+//
+// Full checkpoints are created in version 5, unless `checkpointV6` is true. Version 5 checkpoints can be
+// loaded by all releases, version 6 checkpoints only by releases which support them. To upgrade, enable
+// version 6 checkpoints; existing version 5 checkpoints are still loaded. To roll back, disable version 6
+// checkpoints and wait for the next full checkpoint, which is created in version 5, as delta checkpoints
+// are then no longer based on version 6 checkpoints. Only then downgrade, and remove the remaining
+// version 6 checkpoint files along with their part files (`<checkpoint file>.000` to `.016`), which
+// releases without version 6 support don't remove.
+func NewCompactor(checkpointer *Checkpointer, interval time.Duration, checkpointDistance uint, checkpointsToKeep uint, maxDeltaCheckpoints uint, checkpointV6 bool, logger zerolog.Logger) *Compactor {
 	if checkpointDistance < 1 {
 		checkpointDistance = 1
 	}
+	if maxDeltaCheckpoints > MaxDeltaCheckpoints {
+		logger.Warn().Msgf("capping max delta checkpoints %d to %d", maxDeltaCheckpoints, MaxDeltaCheckpoints)
+		maxDeltaCheckpoints = MaxDeltaCheckpoints
+	}
 	return &Compactor{
-		checkpointer:       checkpointer,
-		logger:             logger,
-		stopc:              make(chan struct{}),
-		observers:          make(map[observable.Observer]struct{}),
-		lm:                 lifecycle.NewLifecycleManager(),
-		interval:           interval,
-		checkpointDistance: checkpointDistance,
-		checkpointsToKeep:  checkpointsToKeep,
+		checkpointer:        checkpointer,
+		logger:              logger,
+		stopc:               make(chan struct{}),
+		observers:           make(map[observable.Observer]struct{}),
+		lm:                  lifecycle.NewLifecycleManager(),
+		interval:            interval,
+		checkpointDistance:  checkpointDistance,
+		checkpointsToKeep:   checkpointsToKeep,
+		maxDeltaCheckpoints: maxDeltaCheckpoints,
+		checkpointV6:        checkpointV6,
 	}
 }
 
@@ -119,10 +144,30 @@ func (c *Compactor) createCheckpoints() (int, error) {
 		startTime := time.Now()
 
 		checkpointNumber := to - 1
-		c.logger.Info().Msgf("creating checkpoint %d from segment %d to segment %d", checkpointNumber, from, checkpointNumber)
-		err = c.checkpointer.Checkpoint(checkpointNumber, func() (io.WriteCloser, error) {
+		writer := func() (io.WriteCloser, error) {
 			return c.checkpointer.CheckpointWriter(checkpointNumber)
-		})
+		}
+
+		delta, err := c.deltaCheckpointAllowed()
+		if err != nil {
+			return -1, fmt.Errorf("cannot check whether a delta checkpoint can be created: %w", err)
+		}
+		if delta {
+			c.logger.Info().Msgf("creating delta checkpoint %d from segment %d to segment %d", checkpointNumber, from, checkpointNumber)
+			err = c.checkpointer.DeltaCheckpoint(checkpointNumber, writer)
+			if errors.Is(err, ErrNoDeltaCheckpointBase) {
+				c.logger.Info().Err(err).Msg("falling back to full checkpoint")
+				delta = false
+			}
+		}
+		if !delta {
+			c.logger.Info().Bool("v6", c.checkpointV6).Msgf("creating checkpoint %d from segment %d to segment %d", checkpointNumber, from, checkpointNumber)
+			if c.checkpointV6 {
+				err = c.checkpointer.CheckpointV6(checkpointNumber)
+			} else {
+				err = c.checkpointer.Checkpoint(checkpointNumber, writer)
+			}
+		}
 		if err != nil {
 			return -1, fmt.Errorf("error creating checkpoint (%d): %w", checkpointNumber, err)
 		}
@@ -134,6 +179,44 @@ func (c *Compactor) createCheckpoints() (int, error) {
 	return newLatestCheckpoint, nil
 }
 
+// deltaCheckpointAllowed returns true if delta checkpoints are enabled and the chain of the latest
+// checkpoint contains less than maxDeltaCheckpoints delta checkpoints. If version 6 checkpoints are
+// disabled, delta checkpoints are not based on version 6 checkpoints, so that a version 5 checkpoint
+// is created after rolling back.
+func (c *Compactor) deltaCheckpointAllowed() (bool, error) {
+	if c.maxDeltaCheckpoints == 0 {
+		return false, nil
+	}
+
+	latestCheckpoint, err := c.checkpointer.LatestCheckpoint()
+	if err != nil {
+		return false, fmt.Errorf("cannot get latest checkpoint: %w", err)
+	}
+	if latestCheckpoint == -1 {
+		return false, nil
+	}
+
+	chain, err := c.checkpointer.CheckpointChain(latestCheckpoint)
+	if err != nil {
+		return false, fmt.Errorf("cannot get chain of checkpoint %d: %w", latestCheckpoint, err)
+	}
+
+	if !c.checkpointV6 {
+		baseVersion, _, _, err := readCheckpointHeader(path.Join(c.checkpointer.dir, NumberToFilename(chain[0])))
+		if err != nil {
+			return false, fmt.Errorf("cannot read header of checkpoint %d: %w", chain[0], err)
+		}
+		if baseVersion == VersionV6 {
+			return false, nil
+		}
+	}
+
+	deltas := len(chain) - 1
+	return deltas < int(c.maxDeltaCheckpoints), nil
+}
+
+// cleanupCheckpoints removes all but the latest checkpointsToKeep checkpoints, except for
+// checkpoints that the kept delta checkpoints are based on.
 func (c *Compactor) cleanupCheckpoints() error {
 	// don't bother listing checkpoints if we keep them all
 	if c.checkpointsToKeep == 0 {
@@ -145,8 +228,24 @@ func (c *Compactor) cleanupCheckpoints() error {
 	}
 	if len(checkpoints) > int(c.checkpointsToKeep) {
 		checkpointsToRemove := checkpoints[:len(checkpoints)-int(c.checkpointsToKeep)] // if condition guarantees this never fails
+		checkpointsToKeep := checkpoints[len(checkpoints)-int(c.checkpointsToKeep):]
+
+		// checkpoints needed to load the kept checkpoints are kept as well
+		neededCheckpoints := make(map[int]struct{})
+		for _, checkpoint := range checkpointsToKeep {
+			chain, err := c.checkpointer.CheckpointChain(checkpoint)
+			if err != nil {
+				return fmt.Errorf("cannot get chain of checkpoint %d: %w", checkpoint, err)
+			}
+			for _, needed := range chain {
+				neededCheckpoints[needed] = struct{}{}
+			}
+		}
 
 		for _, checkpoint := range checkpointsToRemove {
+			if _, ok := neededCheckpoints[checkpoint]; ok {
+				continue
+			}
 			err := c.checkpointer.RemoveCheckpoint(checkpoint)
 			if err != nil {
 				return fmt.Errorf("cannot remove checkpoint %d: %w", checkpoint, err)
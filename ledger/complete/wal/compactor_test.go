@@ -72,7 +72,7 @@ func Test_Compactor(t *testing.T) {
 			checkpointer, err := wal.NewCheckpointer()
 			require.NoError(t, err)
 
-			compactor := NewCompactor(checkpointer, 100*time.Millisecond, checkpointDistance, 1, zerolog.Nop()) //keep only latest checkpoint
+			compactor := NewCompactor(checkpointer, 100*time.Millisecond, checkpointDistance, 1, 0, false, zerolog.Nop()) //keep only latest checkpoint
 			co := CompactorObserver{fromBound: 9, done: make(chan struct{})}
 			compactor.Subscribe(&co)
 
@@ -248,7 +248,7 @@ func Test_Compactor_checkpointInterval(t *testing.T) {
 			checkpointer, err := wal.NewCheckpointer()
 			require.NoError(t, err)
 
-			compactor := NewCompactor(checkpointer, 100*time.Millisecond, checkpointDistance, 2, zerolog.Nop())
+			compactor := NewCompactor(checkpointer, 100*time.Millisecond, checkpointDistance, 2, 0, false, zerolog.Nop())
 
 			// Generate the tree and create WAL
 			for i := 0; i < size; i++ {
@@ -329,3 +329,178 @@ func Test_Compactor_checkpointInterval(t *testing.T) {
 		})
 	})
 }
+
+func Test_Compactor_deltaCheckpoints(t *testing.T) {
+
+	numInsPerStep := 2
+	pathByteSize := 32
+	minPayloadByteSize := 100
+	maxPayloadByteSize := 2 << 16
+	size := 20
+	metricsCollector := &metrics.NoopCollector{}
+	checkpointDistance := uint(3) // there should be 3 WAL not checkpointed
+	maxDeltaCheckpoints := uint(2)
+
+	for _, checkpointV6 := range []bool{false, true} {
+		t.Run(fmt.Sprintf("version 6 checkpoints: %v", checkpointV6), func(t *testing.T) {
+			unittest.RunWithTempDir(t, func(dir string) {
+
+				f, err := mtrie.NewForest(size*10, metricsCollector, nil)
+				require.NoError(t, err)
+
+				var rootHash = f.GetEmptyRootHash()
+
+				wal, err := NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, size*10, pathByteSize, 32*1024)
+				require.NoError(t, err)
+
+				checkpointer, err := wal.NewCheckpointer()
+				require.NoError(t, err)
+
+				compactor := NewCompactor(checkpointer, 100*time.Millisecond, checkpointDistance, 2, maxDeltaCheckpoints, checkpointV6, zerolog.Nop())
+
+				// Generate the tree and create WAL
+				for i := 0; i < size; i++ {
+
+					paths := utils.RandomPaths(numInsPerStep)
+					payloads := utils.RandomPayloads(numInsPerStep, minPayloadByteSize, maxPayloadByteSize)
+
+					update := &ledger.TrieUpdate{RootHash: rootHash, Paths: paths, Payloads: payloads}
+
+					err = wal.RecordUpdate(update)
+					require.NoError(t, err)
+
+					rootHash, err = f.Update(update)
+					require.NoError(t, err)
+
+					require.FileExists(t, path.Join(dir, NumberToFilenamePart(i)))
+
+					// run checkpoint creation and cleanup after every file
+					err = compactor.Run()
+					require.NoError(t, err)
+
+					// checkpoints a kept delta checkpoint is based on are not removed
+					if i == 12 {
+						checkpoints, err := checkpointer.Checkpoints()
+						require.NoError(t, err)
+						require.Equal(t, []int{3, 7, 11}, checkpoints)
+					}
+				}
+
+				<-wal.Done()
+
+				// checkpoints 3 and 15 are full checkpoints, followed by at most 2 delta checkpoints
+				checkpoints, err := checkpointer.Checkpoints()
+				require.NoError(t, err)
+				require.Equal(t, []int{15, 19}, checkpoints)
+
+				chain, err := checkpointer.CheckpointChain(19)
+				require.NoError(t, err)
+				require.Equal(t, []int{15, 19}, chain)
+
+				chain, err = checkpointer.CheckpointChain(15)
+				require.NoError(t, err)
+				require.Equal(t, []int{15}, chain)
+
+				version, _, _, err := readCheckpointHeader(path.Join(dir, NumberToFilename(15)))
+				require.NoError(t, err)
+				if checkpointV6 {
+					require.Equal(t, VersionV6, version)
+				} else {
+					require.Equal(t, VersionV5, version)
+				}
+
+				// part files of version 6 checkpoints are removed with them
+				for i := 0; i < partCount; i++ {
+					if checkpointV6 {
+						require.FileExists(t, path.Join(dir, partFileName(NumberToFilename(15), i)))
+					} else {
+						require.NoFileExists(t, path.Join(dir, partFileName(NumberToFilename(15), i)))
+					}
+					require.NoFileExists(t, path.Join(dir, partFileName(NumberToFilename(3), i)))
+				}
+
+				// the tries of the delta checkpoint are loaded from the whole chain
+				tries, err := checkpointer.LoadCheckpoint(19)
+				require.NoError(t, err)
+
+				for _, loaded := range tries {
+					expected, err := f.GetTrie(loaded.RootHash())
+					require.NoError(t, err)
+					require.True(t, expected.Equals(loaded))
+				}
+			})
+		})
+	}
+}
+
+func Test_Compactor_rollbackFromV6(t *testing.T) {
+
+	numInsPerStep := 2
+	pathByteSize := 32
+	minPayloadByteSize := 100
+	maxPayloadByteSize := 2 << 16
+	size := 12
+	checkpointDistance := uint(3)
+	maxDeltaCheckpoints := uint(2)
+
+	unittest.RunWithTempDir(t, func(dir string) {
+
+		f, err := mtrie.NewForest(size*10, &metrics.NoopCollector{}, nil)
+		require.NoError(t, err)
+
+		var rootHash = f.GetEmptyRootHash()
+
+		wal, err := NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, size*10, pathByteSize, 32*1024)
+		require.NoError(t, err)
+
+		checkpointer, err := wal.NewCheckpointer()
+		require.NoError(t, err)
+
+		// version 6 checkpoints are enabled for the first segments, and disabled afterwards
+		compactorV6 := NewCompactor(checkpointer, 100*time.Millisecond, checkpointDistance, 0, maxDeltaCheckpoints, true, zerolog.Nop())
+		compactorV5 := NewCompactor(checkpointer, 100*time.Millisecond, checkpointDistance, 0, maxDeltaCheckpoints, false, zerolog.Nop())
+
+		for i := 0; i < size; i++ {
+
+			paths := utils.RandomPaths(numInsPerStep)
+			payloads := utils.RandomPayloads(numInsPerStep, minPayloadByteSize, maxPayloadByteSize)
+
+			update := &ledger.TrieUpdate{RootHash: rootHash, Paths: paths, Payloads: payloads}
+
+			err = wal.RecordUpdate(update)
+			require.NoError(t, err)
+
+			rootHash, err = f.Update(update)
+			require.NoError(t, err)
+
+			if i < 8 {
+				err = compactorV6.Run()
+			} else {
+				err = compactorV5.Run()
+			}
+			require.NoError(t, err)
+		}
+
+		<-wal.Done()
+
+		// checkpoint 3 is a version 6 checkpoint followed by a delta checkpoint, and the first
+		// checkpoint after disabling version 6 checkpoints is a full version 5 checkpoint
+		checkpoints, err := checkpointer.Checkpoints()
+		require.NoError(t, err)
+		require.Equal(t, []int{3, 7, 11}, checkpoints)
+
+		for checkpoint, expected := range map[int][]int{3: {3}, 7: {3, 7}, 11: {11}} {
+			chain, err := checkpointer.CheckpointChain(checkpoint)
+			require.NoError(t, err)
+			require.Equal(t, expected, chain)
+		}
+
+		version, _, _, err := readCheckpointHeader(path.Join(dir, NumberToFilename(3)))
+		require.NoError(t, err)
+		require.Equal(t, VersionV6, version)
+
+		version, _, _, err = readCheckpointHeader(path.Join(dir, NumberToFilename(11)))
+		require.NoError(t, err)
+		require.Equal(t, VersionV5, version)
+	})
+}
@@ -0,0 +1,303 @@
+package wal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/module/metrics"
+)
+
+const (
+	encCheckpointNumberSize = 8
+	deltaHeaderSize         = headerSize + encCheckpointNumberSize + encNodeCountSize
+)
+
+// MaxDeltaCheckpoints is the maximum number of delta checkpoints in the chain of a checkpoint.
+// Loading a delta checkpoint keeps the nodes of all checkpoints of its chain in memory, including
+// nodes that later checkpoints of the chain no longer reference, so the length of chains is capped.
+const MaxDeltaCheckpoints = 16
+
+// ErrNoDeltaCheckpointBase is returned when a delta checkpoint can't be created, because there
+// is no previous checkpoint or the previous checkpoint isn't based on a version 5 or 6 checkpoint.
+var ErrNoDeltaCheckpointBase = errors.New("no checkpoint to base the delta checkpoint on")
+
+// DeltaCheckpoint creates a new delta checkpoint stopping at given segment. The delta checkpoint
+// only contains the nodes created since the latest checkpoint, which it is based on. Loading the
+// delta checkpoint loads the chain of checkpoints it is based on, starting with a full checkpoint.
+// It returns ErrNoDeltaCheckpointBase if the latest checkpoint can't be the base of a delta checkpoint.
+func (c *Checkpointer) DeltaCheckpoint(to int, targetWriter func() (io.WriteCloser, error)) (err error) {
+
+	_, notCheckpointedTo, err := c.NotCheckpointedSegments()
+	if err != nil {
+		return fmt.Errorf("cannot get not checkpointed segments: %w", err)
+	}
+
+	latestCheckpoint, err := c.LatestCheckpoint()
+	if err != nil {
+		return fmt.Errorf("cannot get latest checkpoint: %w", err)
+	}
+
+	if latestCheckpoint == to {
+		return nil //nothing to do
+	}
+
+	if latestCheckpoint == -1 {
+		return ErrNoDeltaCheckpointBase
+	}
+
+	if notCheckpointedTo < to {
+		return fmt.Errorf("no segments to checkpoint to %d, latests not checkpointed segment: %d", to, notCheckpointedTo)
+	}
+
+	chain, err := c.CheckpointChain(latestCheckpoint)
+	if err != nil {
+		return fmt.Errorf("cannot get chain of checkpoint %d: %w", latestCheckpoint, err)
+	}
+	baseVersion, _, _, err := readCheckpointHeader(path.Join(c.dir, NumberToFilename(chain[0])))
+	if err != nil {
+		return fmt.Errorf("cannot read header of checkpoint %d: %w", chain[0], err)
+	}
+	if baseVersion != VersionV5 && baseVersion != VersionV6 {
+		return fmt.Errorf("checkpoint %d has version %d: %w", chain[0], baseVersion, ErrNoDeltaCheckpointBase)
+	}
+
+	c.wal.log.Info().Msgf("creating delta checkpoint %d based on checkpoint %d", to, latestCheckpoint)
+
+	nodes, tries, err := loadCheckpointNodes(path.Join(c.dir, NumberToFilename(latestCheckpoint)), c.payloadStorage, &c.wal.log)
+	if err != nil {
+		return fmt.Errorf("cannot load checkpoint %d: %w", latestCheckpoint, err)
+	}
+
+	forest, err := mtrie.NewForestWithPayloadStorage(c.forestCapacity, &metrics.NoopCollector{}, nil, c.payloadStorage)
+	if err != nil {
+		return fmt.Errorf("cannot create Forest: %w", err)
+	}
+
+	err = forest.AddTries(tries)
+	if err != nil {
+		return fmt.Errorf("cannot add tries of checkpoint %d: %w", latestCheckpoint, err)
+	}
+
+	err = c.wal.replay(latestCheckpoint+1, to,
+		func(tries []*trie.MTrie) error {
+			return forest.AddTries(tries)
+		},
+		func(update *ledger.TrieUpdate) error {
+			_, err := forest.Update(update)
+			return err
+		}, func(rootHash ledger.RootHash) error {
+			return nil
+		}, false)
+
+	if err != nil {
+		return fmt.Errorf("cannot replay WAL: %w", err)
+	}
+
+	tries, err = forest.GetTries()
+	if err != nil {
+		return fmt.Errorf("cannot get forest tries: %w", err)
+	}
+
+	c.wal.log.Info().Msgf("serializing delta checkpoint %d", to)
+
+	writer, err := targetWriter()
+	if err != nil {
+		return fmt.Errorf("cannot generate writer: %w", err)
+	}
+	defer func() {
+		closeErr := writer.Close()
+		// Return close error if there isn't any prior error to return.
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	err = StoreDeltaCheckpoint(writer, latestCheckpoint, nodes, tries...)
+
+	c.wal.log.Info().Msgf("created delta checkpoint %d with %d tries", to, len(tries))
+
+	return err
+}
+
+// CheckpointChain returns the numbers of the checkpoints needed to load the given checkpoint, in the
+// order they are loaded: the full checkpoint the chain is based on, followed by the delta checkpoints
+// up to the given checkpoint. The chain of a full checkpoint only contains the checkpoint itself.
+// It returns an error if the chain contains more than MaxDeltaCheckpoints delta checkpoints.
+func (c *Checkpointer) CheckpointChain(checkpoint int) ([]int, error) {
+	return checkpointChain(c.dir, checkpoint)
+}
+
+// checkpointChain returns the numbers of the checkpoints needed to load the given checkpoint
+// of the given directory (see Checkpointer.CheckpointChain).
+func checkpointChain(dir string, checkpoint int) ([]int, error) {
+	chain := []int{checkpoint}
+	for current := checkpoint; ; {
+		version, previous, _, err := readCheckpointHeader(path.Join(dir, NumberToFilename(current)))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read header of checkpoint %d: %w", current, err)
+		}
+		if version != VersionDeltaV5 {
+			break
+		}
+		if previous >= current {
+			return nil, fmt.Errorf("delta checkpoint %d is based on later checkpoint %d", current, previous)
+		}
+		if len(chain) > MaxDeltaCheckpoints {
+			return nil, fmt.Errorf("checkpoint %d is based on more than %d delta checkpoints", checkpoint, MaxDeltaCheckpoints)
+		}
+		chain = append(chain, previous)
+		current = previous
+	}
+
+	// reverse the chain to start with the full checkpoint
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
+// StoreDeltaCheckpoint writes a delta checkpoint of the given tries to the writer, based on the previous
+// checkpoint whose chain contains the given nodes, and appends a CRC32 file checksum for integrity check.
+// `previousNodes` are the nodes of the previous checkpoint's chain in the order they are loaded, with the
+// nil node at index 0.
+// Delta checkpoint files have the same layout as version 5 checkpoint files, except that:
+//   - the header is followed by the number of the previous checkpoint (8 bytes) and the number of nodes
+//     in the previous checkpoint's chain (8 bytes).
+//   - only nodes which are not in `previousNodes` are encoded. Nodes are referenced by their index in
+//     the combined list of the nodes in the previous checkpoint's chain and the encoded nodes.
+//   - the node count in the footer is the number of encoded nodes.
+func StoreDeltaCheckpoint(writer io.Writer, previous int, previousNodes []*node.Node, tries ...*trie.MTrie) error {
+	if len(previousNodes) == 0 || previousNodes[0] != nil {
+		return fmt.Errorf("previous nodes must start with the nil node")
+	}
+
+	// Write header: magic (2 bytes) + version (2 bytes) + previous checkpoint (8 bytes) + previous node count (8 bytes)
+	header := make([]byte, deltaHeaderSize)
+	binary.BigEndian.PutUint16(header, MagicBytes)
+	binary.BigEndian.PutUint16(header[encMagicSize:], VersionDeltaV5)
+	binary.BigEndian.PutUint64(header[headerSize:], uint64(previous))
+	binary.BigEndian.PutUint64(header[headerSize+encCheckpointNumberSize:], uint64(len(previousNodes)-1))
+
+	allNodes := make(map[*node.Node]uint64, len(previousNodes))
+	for i, n := range previousNodes {
+		allNodes[n] = uint64(i)
+	}
+
+	_, err := storeCheckpoint(writer, header, allNodes, uint64(len(allNodes)), tries)
+	return err
+}
+
+// readCheckpointHeader reads the version of the checkpoint file. For delta checkpoints, it also
+// returns the number of the previous checkpoint and the number of nodes in its chain.
+func readCheckpointHeader(filepath string) (version uint16, previous int, previousNodeCount uint64, err error) {
+	f, err := os.Open(filepath)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("cannot open checkpoint file %s: %w", filepath, err)
+	}
+	defer f.Close()
+
+	header := make([]byte, deltaHeaderSize)
+	_, err = io.ReadFull(f, header[:headerSize])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("cannot read header: %w", err)
+	}
+
+	magicBytes := binary.BigEndian.Uint16(header)
+	version = binary.BigEndian.Uint16(header[encMagicSize:])
+	if magicBytes != MagicBytes {
+		return 0, 0, 0, fmt.Errorf("unknown file format. Magic constant %x does not match expected %x", magicBytes, MagicBytes)
+	}
+	if version != VersionDeltaV5 {
+		return version, -1, 0, nil
+	}
+
+	_, err = io.ReadFull(f, header[headerSize:])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("cannot read delta header: %w", err)
+	}
+	previous = int(binary.BigEndian.Uint64(header[headerSize:]))
+	previousNodeCount = binary.BigEndian.Uint64(header[headerSize+encCheckpointNumberSize:])
+
+	return version, previous, previousNodeCount, nil
+}
+
+// loadCheckpointNodes loads the checkpoint file and the chain of checkpoints it is based on, and returns
+// the nodes of the chain in the order they are loaded, with the nil node at index 0, and the tries of
+// the checkpoint. Only version 5, version 6 and delta checkpoints are supported.
+// If payloadStorage isn't nil, the leaf payloads are stored in it as they are read.
+func loadCheckpointNodes(filepath string, payloadStorage node.PayloadStorage, logger *zerolog.Logger) ([]*node.Node, []*trie.MTrie, error) {
+	return loadChainNodes(filepath, 0, payloadStorage, logger)
+}
+
+// loadChainNodes is loadCheckpointNodes for a checkpoint which `deltas` delta checkpoints are based on.
+func loadChainNodes(filepath string, deltas int, payloadStorage node.PayloadStorage, logger *zerolog.Logger) ([]*node.Node, []*trie.MTrie, error) {
+	version, _, _, err := readCheckpointHeader(filepath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot open checkpoint file %s: %w", filepath, err)
+	}
+	defer func() {
+		evictErr := evictFileFromLinuxPageCache(file, false, logger)
+		if evictErr != nil {
+			logger.Warn().Msgf("failed to evict file %s from Linux page cache: %s", filepath, evictErr)
+			// No need to return this error because it's possible to continue normal operations.
+		}
+
+		_ = file.Close()
+	}()
+
+	switch version {
+	case VersionV5:
+		return readCheckpointV5Nodes(file, headerSize, nil, payloadStorage)
+	case VersionDeltaV5:
+		return readDeltaCheckpoint(file, deltas, payloadStorage, logger)
+	case VersionV6:
+		return readCheckpointV6Nodes(file, payloadStorage, logger)
+	default:
+		return nil, nil, fmt.Errorf("checkpoint file %s has version %d: %w", filepath, version, ErrNoDeltaCheckpointBase)
+	}
+}
+
+// readDeltaCheckpoint loads the chain of checkpoints the delta checkpoint file is based on from the
+// same directory, and deserializes the delta checkpoint. It returns the nodes of the chain including
+// the nodes of the delta checkpoint, and the tries of the delta checkpoint.
+// `deltas` is the number of delta checkpoints loaded after this one. Chains with more than
+// MaxDeltaCheckpoints delta checkpoints are rejected before any of their nodes are loaded.
+// Header (magic and version) is verified by the caller.
+func readDeltaCheckpoint(f *os.File, deltas int, payloadStorage node.PayloadStorage, logger *zerolog.Logger) ([]*node.Node, []*trie.MTrie, error) {
+	if deltas >= MaxDeltaCheckpoints {
+		return nil, nil, fmt.Errorf("delta checkpoint is based on more than %d delta checkpoints", MaxDeltaCheckpoints)
+	}
+
+	_, previous, previousNodeCount, err := readCheckpointHeader(f.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	previousPath := filepath.Join(filepath.Dir(f.Name()), NumberToFilename(previous))
+	nodes, _, err := loadChainNodes(previousPath, deltas+1, payloadStorage, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot load checkpoint %d the delta checkpoint is based on: %w", previous, err)
+	}
+
+	if uint64(len(nodes)-1) != previousNodeCount {
+		return nil, nil, fmt.Errorf("delta checkpoint is based on %d nodes, but checkpoint %d has %d nodes", previousNodeCount, previous, len(nodes)-1)
+	}
+
+	return readCheckpointV5Nodes(f, deltaHeaderSize, nodes, payloadStorage)
+}
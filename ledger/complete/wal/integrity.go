@@ -0,0 +1,506 @@
+package wal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+
+	prometheusWAL "github.com/m4ksio/wal/wal"
+	"github.com/rs/zerolog"
+)
+
+// RepairMode defines what is done with the corrupted tail of a WAL.
+type RepairMode int
+
+const (
+	// RepairDryRun only reports the corruption, without modifying the WAL.
+	RepairDryRun RepairMode = iota
+	// RepairTruncate removes the corrupted tail of the WAL.
+	RepairTruncate
+	// RepairQuarantine moves the corrupted tail of the WAL to a quarantine directory.
+	RepairQuarantine
+)
+
+// ParseRepairMode parses the name of a repair mode: "dry-run", "truncate" or "quarantine".
+func ParseRepairMode(s string) (RepairMode, error) {
+	switch s {
+	case "dry-run":
+		return RepairDryRun, nil
+	case "truncate":
+		return RepairTruncate, nil
+	case "quarantine":
+		return RepairQuarantine, nil
+	default:
+		return RepairDryRun, fmt.Errorf("unknown WAL repair mode %q, expected one of dry-run, truncate or quarantine", s)
+	}
+}
+
+func (m RepairMode) String() string {
+	return [...]string{"dry-run", "truncate", "quarantine"}[m]
+}
+
+// RecordPosition is the position right after a record in the WAL.
+type RecordPosition struct {
+	Segment int
+	Offset  int64
+}
+
+// CheckpointStatus is the result of loading a checkpoint.
+type CheckpointStatus struct {
+	Number int
+	Err    error // nil if the checkpoint could be loaded
+}
+
+// IntegrityReport is the result of checking the segments and checkpoints of a WAL.
+type IntegrityReport struct {
+	FirstSegment int // -1 if there are no segments
+	LastSegment  int // -1 if there are no segments
+	Records      int // number of consistent records before the corruption, if any
+	// LastConsistent is the position after the last consistent record, or nil if there is none.
+	LastConsistent *RecordPosition
+	// Corruption is the error found in the segments, or nil if all segments are consistent.
+	Corruption error
+	// CorruptedSegment is the segment which contains the corruption, or -1 if there is none.
+	CorruptedSegment int
+	// RecordsAfterCorruption is the number of consistent records found after the corruption, which would be lost
+	// by removing the corrupted tail.
+	RecordsAfterCorruption int
+	// DataAfterCorruptedSegment is true if a segment after the corrupted segment is not empty.
+	DataAfterCorruptedSegment bool
+	// Checkpoints is the status of each checkpoint, if checkpoints were checked.
+	Checkpoints []CheckpointStatus
+}
+
+// HasCorruptedSegments returns true if a corruption was found in the segments.
+func (r *IntegrityReport) HasCorruptedSegments() bool {
+	return r.Corruption != nil
+}
+
+// CorruptedCheckpoints returns the numbers of the checkpoints which could not be loaded.
+func (r *IntegrityReport) CorruptedCheckpoints() []int {
+	var corrupted []int
+	for _, c := range r.Checkpoints {
+		if c.Err != nil {
+			corrupted = append(corrupted, c.Number)
+		}
+	}
+	return corrupted
+}
+
+// TruncationPoint returns the position at which the corrupted tail of the WAL starts,
+// which is right after the last consistent record.
+// The corruption can be detected in a later segment than the truncation point, for example
+// if a partially written record is followed by records written after a restart.
+// Only valid if the report has corrupted segments.
+func (r *IntegrityReport) TruncationPoint() RecordPosition {
+	if r.LastConsistent != nil {
+		return *r.LastConsistent
+	}
+	return RecordPosition{Segment: r.FirstSegment}
+}
+
+// CanRepairTail returns an error explaining why the corrupted tail of the WAL cannot be removed by RepairTail, or nil
+// if it can. Only a corruption in the last segment which is not followed by any consistent record can be removed
+// without losing updates which were recorded successfully.
+func (r *IntegrityReport) CanRepairTail() error {
+	if !r.HasCorruptedSegments() {
+		return nil
+	}
+
+	truncateAt := r.TruncationPoint()
+	manualRepair := fmt.Sprintf("stop the node, back up the WAL, and either restore the execution state from a backup, "+
+		"or move segments %d to %d out of the WAL directory and truncate segment %d at offset %d once you have checked "+
+		"that the blocks they contain can be executed again",
+		truncateAt.Segment+1, r.LastSegment, truncateAt.Segment, truncateAt.Offset)
+
+	if r.RecordsAfterCorruption > 0 {
+		return fmt.Errorf("corruption in segment %d is followed by %d consistent records which would be lost by "+
+			"removing the corrupted tail: %s", r.CorruptedSegment, r.RecordsAfterCorruption, manualRepair)
+	}
+	if r.DataAfterCorruptedSegment {
+		return fmt.Errorf("corruption in segment %d is followed by data in segments up to %d, which would be lost by "+
+			"removing the corrupted tail: %s", r.CorruptedSegment, r.LastSegment, manualRepair)
+	}
+	return nil
+}
+
+// CheckIntegrity reads all segments of the WAL in `dir`, and validates the checksums and encoding
+// of each record. It reports the last consistent record and the first corruption, if any.
+// A record which was only partially written, for example because of a crash, is reported as a corruption.
+// If checkCheckpoints is true, all checkpoints are loaded to validate them, which requires
+// enough memory to hold the largest checkpoint.
+// CheckIntegrity must not be used while the WAL is open for writing.
+func CheckIntegrity(dir string, checkCheckpoints bool, logger zerolog.Logger) (*IntegrityReport, error) {
+	first, last, err := prometheusWAL.Segments(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list segments: %w", err)
+	}
+
+	report := &IntegrityReport{
+		FirstSegment:     first,
+		LastSegment:      last,
+		CorruptedSegment: -1,
+	}
+
+	if first >= 0 {
+		err = checkSegments(dir, report, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if checkCheckpoints {
+		checkpoints, err := (&Checkpointer{dir: dir}).Checkpoints()
+		if err != nil {
+			return nil, fmt.Errorf("cannot list checkpoints: %w", err)
+		}
+		for _, checkpoint := range checkpoints {
+			logger.Info().Int("checkpoint", checkpoint).Msg("checking checkpoint")
+			_, err := LoadCheckpoint(filepath.Join(dir, NumberToFilename(checkpoint)), &logger)
+			report.Checkpoints = append(report.Checkpoints, CheckpointStatus{Number: checkpoint, Err: err})
+		}
+	}
+
+	return report, nil
+}
+
+// checkSegments reads all segments and records the result in the report.
+func checkSegments(dir string, report *IntegrityReport, logger zerolog.Logger) error {
+	logger.Info().Msgf("checking segments from %d to %d", report.FirstSegment, report.LastSegment)
+
+	sr, err := prometheusWAL.NewSegmentsRangeReader(prometheusWAL.SegmentRange{
+		Dir:   dir,
+		First: report.FirstSegment,
+		Last:  report.LastSegment,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create segment reader: %w", err)
+	}
+	defer sr.Close()
+
+	reader := prometheusWAL.NewReader(sr)
+	for reader.Next() {
+		_, _, _, err := Decode(reader.Record())
+		if err != nil {
+			report.Corruption = fmt.Errorf("cannot decode record in segment %d: %w", reader.Segment(), err)
+			report.CorruptedSegment = reader.Segment()
+			return checkAfterCorruption(dir, report)
+		}
+		report.Records++
+		report.LastConsistent = &RecordPosition{Segment: reader.Segment(), Offset: reader.Offset()}
+	}
+
+	err = reader.Err()
+	if err != nil {
+		var corruption *prometheusWAL.CorruptionErr
+		if errors.As(err, &corruption) {
+			report.Corruption = err
+			report.CorruptedSegment = corruption.Segment
+			return checkAfterCorruption(dir, report)
+		}
+		return fmt.Errorf("cannot read segments: %w", err)
+	}
+
+	// The reader doesn't detect a record which was partially written at the end of the WAL,
+	// as the rest of the page is assumed to be padded. So the rest of the segment containing
+	// the last record, and all segments after it, must only contain zeros.
+	from := RecordPosition{Segment: report.FirstSegment}
+	if report.LastConsistent != nil {
+		from = *report.LastConsistent
+	}
+	for segment := from.Segment; segment <= report.LastSegment; segment++ {
+		offset := int64(0)
+		if segment == from.Segment {
+			offset = from.Offset
+		}
+		torn, err := hasNonZeroBytes(prometheusWAL.SegmentName(dir, segment), offset)
+		if err != nil {
+			return err
+		}
+		if torn {
+			report.Corruption = fmt.Errorf("partially written record in segment %d after offset %d", segment, offset)
+			report.CorruptedSegment = segment
+			return checkAfterCorruption(dir, report)
+		}
+	}
+
+	return nil
+}
+
+// checkAfterCorruption records in the report whether data follows the corruption, which would be lost by removing
+// the corrupted tail of the WAL.
+func checkAfterCorruption(dir string, report *IntegrityReport) error {
+	for segment := report.CorruptedSegment + 1; segment <= report.LastSegment; segment++ {
+		data, err := hasNonZeroBytes(prometheusWAL.SegmentName(dir, segment), 0)
+		if err != nil {
+			return err
+		}
+		if data {
+			report.DataAfterCorruptedSegment = true
+			break
+		}
+	}
+
+	records, err := countRecordsAfter(dir, report.TruncationPoint(), report.LastSegment)
+	if err != nil {
+		return err
+	}
+	report.RecordsAfterCorruption = records
+	return nil
+}
+
+// the layout of the pages of the WAL segments: records are split into fragments which don't cross page boundaries,
+// and each fragment has a header with its type, length and checksum
+const (
+	walPageSize        = 32 * 1024
+	fragmentHeaderSize = 7
+	fragmentTypeMask   = 1<<3 - 1
+
+	fragmentPadding = 0 // the rest of the page is empty
+	fragmentFull    = 1 // full record
+	fragmentFirst   = 2 // first fragment of a record
+	fragmentMiddle  = 3 // middle fragment of a record
+	fragmentLast    = 4 // last fragment of a record
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// countRecordsAfter returns the number of consistent records in the WAL in `dir` after the given position, in the
+// segment of the position and all later segments up to `last`. The WAL reader stops at the first corruption, so the
+// fragments are read directly: the rest of a page is skipped after a fragment with an invalid checksum, and the
+// search resumes at the next page.
+func countRecordsAfter(dir string, from RecordPosition, last int) (int, error) {
+	records := 0
+	for segment := from.Segment; segment <= last; segment++ {
+		data, err := os.ReadFile(prometheusWAL.SegmentName(dir, segment))
+		if err != nil {
+			return 0, fmt.Errorf("cannot read segment %d: %w", segment, err)
+		}
+
+		start := 0
+		if segment == from.Segment {
+			start = int(from.Offset)
+		}
+		records += countRecords(data, start)
+	}
+	return records, nil
+}
+
+// countRecords returns the number of consistent records starting after `start` in the data of a segment.
+func countRecords(data []byte, start int) int {
+	records := 0
+	var record []byte
+	inRecord := false // whether the first fragment of the current record was read
+
+	offset := start
+	for offset < len(data) {
+		pageEnd := (offset/walPageSize + 1) * walPageSize
+		if pageEnd > len(data) {
+			pageEnd = len(data)
+		}
+
+		if offset+fragmentHeaderSize > pageEnd || data[offset]&fragmentTypeMask == fragmentPadding {
+			offset = pageEnd
+			continue
+		}
+
+		typ := data[offset] & fragmentTypeMask
+		length := int(binary.BigEndian.Uint16(data[offset+1:]))
+		checksum := binary.BigEndian.Uint32(data[offset+3:])
+		fragmentStart := offset + fragmentHeaderSize
+		fragmentEnd := fragmentStart + length
+		if fragmentEnd > pageEnd || crc32.Checksum(data[fragmentStart:fragmentEnd], castagnoliTable) != checksum {
+			// the rest of the page can't be parsed
+			inRecord = false
+			offset = pageEnd
+			continue
+		}
+		fragment := data[fragmentStart:fragmentEnd]
+		offset = fragmentEnd
+
+		switch typ {
+		case fragmentFull:
+			record = append(record[:0], fragment...)
+		case fragmentFirst:
+			record = append(record[:0], fragment...)
+			inRecord = true
+			continue
+		case fragmentMiddle:
+			if inRecord {
+				record = append(record, fragment...)
+			}
+			continue
+		case fragmentLast:
+			if !inRecord {
+				// the record started before the search
+				continue
+			}
+			record = append(record, fragment...)
+			inRecord = false
+		default:
+			inRecord = false
+			continue
+		}
+
+		_, _, _, err := Decode(record)
+		if err == nil {
+			records++
+		}
+	}
+
+	return records
+}
+
+// hasNonZeroBytes returns true if the file has a non-zero byte after the given offset.
+func hasNonZeroBytes(filename string, offset int64) (bool, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return false, fmt.Errorf("cannot open segment: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		return false, fmt.Errorf("cannot seek in segment %s: %w", filename, err)
+	}
+
+	buf := make([]byte, defaultBufioReadSize)
+	zeros := make([]byte, defaultBufioReadSize)
+	for {
+		n, err := f.Read(buf)
+		if !bytes.Equal(buf[:n], zeros[:n]) {
+			return true, nil
+		}
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("cannot read segment %s: %w", filename, err)
+		}
+	}
+}
+
+// RepairTail removes the corrupted tail of the WAL in `dir` reported by CheckIntegrity:
+// the segment with the last consistent record is truncated after this record, and all later segments are removed.
+// With RepairQuarantine, the truncated segment and all later segments are moved to `quarantineDir`
+// first, otherwise the corrupted data is lost. RepairDryRun doesn't modify the WAL.
+// The tail is only removed if the corruption is in the last segment and no consistent record follows it (see
+// CanRepairTail), otherwise an error is returned and the WAL must be repaired manually.
+// Checkpoints are never modified.
+// RepairTail must not be used while the WAL is open for writing.
+func RepairTail(dir string, report *IntegrityReport, mode RepairMode, quarantineDir string, logger zerolog.Logger) error {
+	if !report.HasCorruptedSegments() {
+		return nil
+	}
+
+	truncateAt := report.TruncationPoint()
+	log := logger.With().
+		Int("segment", truncateAt.Segment).
+		Int64("offset", truncateAt.Offset).
+		Str("mode", mode.String()).
+		Logger()
+
+	repairErr := report.CanRepairTail()
+
+	if mode == RepairDryRun {
+		if repairErr != nil {
+			log.Warn().Err(report.Corruption).
+				Msgf("WAL is corrupted and cannot be repaired automatically: %v", repairErr)
+			return nil
+		}
+		log.Warn().Err(report.Corruption).
+			Msgf("WAL is corrupted, segment %d would be truncated and segments up to %d removed", truncateAt.Segment, report.LastSegment)
+		return nil
+	}
+
+	if repairErr != nil {
+		return fmt.Errorf("cannot repair WAL automatically: %w", repairErr)
+	}
+
+	if mode == RepairQuarantine {
+		if quarantineDir == "" {
+			return fmt.Errorf("quarantine directory is required to quarantine the corrupted WAL tail")
+		}
+		err := os.MkdirAll(quarantineDir, 0700)
+		if err != nil {
+			return fmt.Errorf("cannot create quarantine directory: %w", err)
+		}
+		err = copyFile(prometheusWAL.SegmentName(dir, truncateAt.Segment), prometheusWAL.SegmentName(quarantineDir, truncateAt.Segment))
+		if err != nil {
+			return fmt.Errorf("cannot quarantine segment %d: %w", truncateAt.Segment, err)
+		}
+	}
+
+	// remove later segments first, so that an interrupted repair leaves the WAL
+	// in a state which can be repaired again
+	for segment := report.LastSegment; segment > truncateAt.Segment; segment-- {
+		var err error
+		if mode == RepairQuarantine {
+			target := prometheusWAL.SegmentName(quarantineDir, segment)
+			if _, statErr := os.Stat(target); statErr == nil {
+				return fmt.Errorf("cannot quarantine segment %d: %s already exists", segment, target)
+			}
+			err = os.Rename(prometheusWAL.SegmentName(dir, segment), target)
+		} else {
+			err = os.Remove(prometheusWAL.SegmentName(dir, segment))
+		}
+		if err != nil {
+			return fmt.Errorf("cannot remove segment %d: %w", segment, err)
+		}
+	}
+
+	err := truncateFile(prometheusWAL.SegmentName(dir, truncateAt.Segment), truncateAt.Offset)
+	if err != nil {
+		return fmt.Errorf("cannot truncate segment %d: %w", truncateAt.Segment, err)
+	}
+
+	log.Warn().Err(report.Corruption).
+		Msgf("repaired corrupted WAL, truncated segment %d and removed segments up to %d", truncateAt.Segment, report.LastSegment)
+	return nil
+}
+
+// copyFile copies the file `from` to the new file `to`.
+func copyFile(from, to string) (err error) {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := dst.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	_, err = io.Copy(dst, src)
+	if err != nil {
+		return err
+	}
+	return dst.Sync()
+}
+
+// truncateFile truncates the file to the given size, and syncs it to disk.
+func truncateFile(filename string, size int64) error {
+	f, err := os.OpenFile(filename, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = f.Truncate(size)
+	if err != nil {
+		return err
+	}
+	return f.Sync()
+}
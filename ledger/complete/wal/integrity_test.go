@@ -0,0 +1,319 @@
+package wal
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path"
+	"testing"
+
+	prometheusWAL "github.com/m4ksio/wal/wal"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/utils"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+// writeUpdates records `count` updates of a trie in a new WAL in `dir`,
+// and returns the root hashes of the updated tries.
+func writeUpdates(t *testing.T, dir string, count int) []ledger.RootHash {
+	wal, err := NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, count+1, pathByteSize, segmentSize)
+	require.NoError(t, err)
+	defer func() {
+		<-wal.Done()
+	}()
+
+	forest, err := mtrie.NewForest(count+1, &metrics.NoopCollector{}, nil)
+	require.NoError(t, err)
+
+	rootHash := forest.GetEmptyRootHash()
+	rootHashes := make([]ledger.RootHash, 0, count)
+	for i := 0; i < count; i++ {
+		paths := utils.RandomPaths(10)
+		update := &ledger.TrieUpdate{RootHash: rootHash, Paths: paths, Payloads: utils.RandomPayloads(len(paths), 500, 1000)}
+
+		err = wal.RecordUpdate(update)
+		require.NoError(t, err)
+
+		rootHash, err = forest.Update(update)
+		require.NoError(t, err)
+		rootHashes = append(rootHashes, rootHash)
+	}
+	return rootHashes
+}
+
+// replayedRootHashes returns the root hashes of the tries rebuilt from the WAL in `dir`, with a strict replay failing
+// on a corrupted record.
+func replayedRootHashes(t *testing.T, dir string, count int, strict bool) ([]ledger.RootHash, error) {
+	wal, err := NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, count+1, pathByteSize, segmentSize)
+	require.NoError(t, err)
+	wal.SetStrictReplay(strict)
+	defer func() {
+		<-wal.Done()
+	}()
+
+	forest, err := mtrie.NewForest(count+1, &metrics.NoopCollector{}, nil)
+	require.NoError(t, err)
+
+	var rootHashes []ledger.RootHash
+	err = wal.Replay(
+		func(tries []*trie.MTrie) error {
+			return forest.AddTries(tries)
+		},
+		func(update *ledger.TrieUpdate) error {
+			rootHash, err := forest.Update(update)
+			rootHashes = append(rootHashes, rootHash)
+			return err
+		},
+		func(ledger.RootHash) error {
+			return nil
+		},
+	)
+	return rootHashes, err
+}
+
+// requireUnmodified checks that the segments of the WAL in `dir` were not modified since the given report.
+func requireUnmodified(t *testing.T, dir string, report *IntegrityReport) {
+	unmodified, err := CheckIntegrity(dir, false, zerolog.Nop())
+	require.NoError(t, err)
+	require.Equal(t, report.LastSegment, unmodified.LastSegment)
+	require.Equal(t, report.Records, unmodified.Records)
+	require.Equal(t, report.LastConsistent, unmodified.LastConsistent)
+	require.Equal(t, report.CorruptedSegment, unmodified.CorruptedSegment)
+	require.Equal(t, report.RecordsAfterCorruption, unmodified.RecordsAfterCorruption)
+}
+
+func Test_CheckIntegrity(t *testing.T) {
+	const count = 20
+
+	t.Run("consistent WAL", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			rootHashes := writeUpdates(t, dir, count)
+
+			report, err := CheckIntegrity(dir, true, zerolog.Nop())
+			require.NoError(t, err)
+			require.False(t, report.HasCorruptedSegments())
+			require.Equal(t, count, report.Records)
+			require.Equal(t, 0, report.FirstSegment)
+			require.Greater(t, report.LastSegment, 1, "updates span several segments")
+			require.NotNil(t, report.LastConsistent)
+			require.Equal(t, -1, report.CorruptedSegment)
+
+			// repairing a consistent WAL doesn't modify it
+			err = RepairTail(dir, report, RepairTruncate, "", zerolog.Nop())
+			require.NoError(t, err)
+
+			replayed, err := replayedRootHashes(t, dir, count, true)
+			require.NoError(t, err)
+			require.Equal(t, rootHashes, replayed)
+		})
+	})
+
+	t.Run("empty WAL", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			report, err := CheckIntegrity(dir, true, zerolog.Nop())
+			require.NoError(t, err)
+			require.False(t, report.HasCorruptedSegments())
+			require.Equal(t, -1, report.FirstSegment)
+			require.Equal(t, 0, report.Records)
+			require.Nil(t, report.LastConsistent)
+		})
+	})
+
+	t.Run("partially written last record", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			rootHashes := writeUpdates(t, dir, count)
+
+			report, err := CheckIntegrity(dir, false, zerolog.Nop())
+			require.NoError(t, err)
+			last := *report.LastConsistent
+
+			// cut the last record in the middle
+			err = os.Truncate(prometheusWAL.SegmentName(dir, last.Segment), last.Offset-100)
+			require.NoError(t, err)
+
+			report, err = CheckIntegrity(dir, false, zerolog.Nop())
+			require.NoError(t, err)
+			require.True(t, report.HasCorruptedSegments())
+			require.Equal(t, count-1, report.Records)
+			require.Equal(t, last.Segment, report.CorruptedSegment)
+			require.Equal(t, 0, report.RecordsAfterCorruption)
+			require.NoError(t, report.CanRepairTail())
+
+			// dry run doesn't modify the WAL
+			err = RepairTail(dir, report, RepairDryRun, "", zerolog.Nop())
+			require.NoError(t, err)
+			dryRunReport, err := CheckIntegrity(dir, false, zerolog.Nop())
+			require.NoError(t, err)
+			require.True(t, dryRunReport.HasCorruptedSegments())
+			require.Equal(t, report.LastSegment, dryRunReport.LastSegment)
+			require.Equal(t, report.LastConsistent, dryRunReport.LastConsistent)
+
+			err = RepairTail(dir, report, RepairTruncate, "", zerolog.Nop())
+			require.NoError(t, err)
+
+			repaired, err := CheckIntegrity(dir, false, zerolog.Nop())
+			require.NoError(t, err)
+			require.False(t, repaired.HasCorruptedSegments())
+			require.Equal(t, count-1, repaired.Records)
+
+			replayed, err := replayedRootHashes(t, dir, count, true)
+			require.NoError(t, err)
+			require.Equal(t, rootHashes[:count-1], replayed)
+		})
+	})
+
+	t.Run("torn record at the end", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			rootHashes := writeUpdates(t, dir, count)
+
+			report, err := CheckIntegrity(dir, false, zerolog.Nop())
+			require.NoError(t, err)
+			last := *report.LastConsistent
+
+			// write the first fragment of a record split across pages, with a valid checksum,
+			// which the WAL reader accepts as the page is padded with zeros
+			fragment := []byte("first fragment of a torn record")
+			header := make([]byte, 7)
+			header[0] = 2 // first fragment of a record
+			binary.BigEndian.PutUint16(header[1:], uint16(len(fragment)))
+			binary.BigEndian.PutUint32(header[3:], crc32.Checksum(fragment, crc32.MakeTable(crc32.Castagnoli)))
+
+			f, err := os.OpenFile(prometheusWAL.SegmentName(dir, last.Segment), os.O_WRONLY, 0)
+			require.NoError(t, err)
+			_, err = f.WriteAt(append(header, fragment...), last.Offset)
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+
+			report, err = CheckIntegrity(dir, false, zerolog.Nop())
+			require.NoError(t, err)
+			require.True(t, report.HasCorruptedSegments())
+			require.Equal(t, count, report.Records)
+			require.Equal(t, last, *report.LastConsistent)
+
+			err = RepairTail(dir, report, RepairTruncate, "", zerolog.Nop())
+			require.NoError(t, err)
+
+			repaired, err := CheckIntegrity(dir, false, zerolog.Nop())
+			require.NoError(t, err)
+			require.False(t, repaired.HasCorruptedSegments())
+
+			replayed, err := replayedRootHashes(t, dir, count, true)
+			require.NoError(t, err)
+			require.Equal(t, rootHashes, replayed)
+		})
+	})
+
+	t.Run("corruption followed by later segments", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			writeUpdates(t, dir, count)
+
+			// corrupt the first record
+			segment := prometheusWAL.SegmentName(dir, 0)
+			data, err := os.ReadFile(segment)
+			require.NoError(t, err)
+			data[100] ^= 0xff
+			err = os.WriteFile(segment, data, 0600)
+			require.NoError(t, err)
+
+			// a strict replay fails instead of silently skipping the records after the corruption
+			_, err = replayedRootHashes(t, dir, count, true)
+			require.Error(t, err)
+
+			// by default, the records after the corruption are skipped
+			replayed, err := replayedRootHashes(t, dir, count, false)
+			require.NoError(t, err)
+			require.Empty(t, replayed)
+
+			report, err := CheckIntegrity(dir, false, zerolog.Nop())
+			require.NoError(t, err)
+			require.True(t, report.HasCorruptedSegments())
+			require.Equal(t, 0, report.Records)
+			require.Nil(t, report.LastConsistent)
+			require.Equal(t, 0, report.CorruptedSegment)
+			require.True(t, report.DataAfterCorruptedSegment)
+			require.Greater(t, report.RecordsAfterCorruption, 0)
+			require.Error(t, report.CanRepairTail())
+
+			// the consistent records after the corruption would be lost, so the WAL is not modified
+			quarantineDir := path.Join(dir, "quarantine")
+			err = RepairTail(dir, report, RepairQuarantine, quarantineDir, zerolog.Nop())
+			require.Error(t, err)
+			err = RepairTail(dir, report, RepairTruncate, "", zerolog.Nop())
+			require.Error(t, err)
+			require.NoDirExists(t, quarantineDir)
+
+			requireUnmodified(t, dir, report)
+		})
+	})
+
+	t.Run("torn record followed by records written after a restart", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			writeUpdates(t, dir, count)
+
+			report, err := CheckIntegrity(dir, false, zerolog.Nop())
+			require.NoError(t, err)
+			last := *report.LastConsistent
+
+			// cut the last record in the middle, and record more updates in a new segment
+			err = os.Truncate(prometheusWAL.SegmentName(dir, last.Segment), last.Offset-100)
+			require.NoError(t, err)
+			writeUpdates(t, dir, 2)
+
+			report, err = CheckIntegrity(dir, false, zerolog.Nop())
+			require.NoError(t, err)
+			require.True(t, report.HasCorruptedSegments())
+			require.Equal(t, count-1, report.Records)
+			require.Equal(t, 2, report.RecordsAfterCorruption)
+
+			err = RepairTail(dir, report, RepairTruncate, "", zerolog.Nop())
+			require.Error(t, err)
+
+			requireUnmodified(t, dir, report)
+		})
+	})
+
+	t.Run("corrupted checkpoint", func(t *testing.T) {
+		unittest.RunWithTempDir(t, func(dir string) {
+			writeUpdates(t, dir, count)
+
+			wal, err := NewDiskWAL(zerolog.Nop(), nil, metrics.NewNoopCollector(), dir, count+1, pathByteSize, segmentSize)
+			require.NoError(t, err)
+			checkpointer, err := wal.NewCheckpointer()
+			require.NoError(t, err)
+			require.NoError(t, checkpointer.CheckpointV6(0))
+			require.NoError(t, checkpointer.CheckpointV6(1))
+			<-wal.Done()
+
+			// corrupt the checksum of checkpoint 1
+			filename := path.Join(dir, NumberToFilename(1))
+			data, err := os.ReadFile(filename)
+			require.NoError(t, err)
+			data[len(data)-1] ^= 0xff
+			err = os.WriteFile(filename, data, 0600)
+			require.NoError(t, err)
+
+			report, err := CheckIntegrity(dir, true, zerolog.Nop())
+			require.NoError(t, err)
+			require.False(t, report.HasCorruptedSegments())
+			require.Len(t, report.Checkpoints, 2)
+			require.Equal(t, []int{1}, report.CorruptedCheckpoints())
+		})
+	})
+}
+
+func Test_ParseRepairMode(t *testing.T) {
+	for _, mode := range []RepairMode{RepairDryRun, RepairTruncate, RepairQuarantine} {
+		parsed, err := ParseRepairMode(mode.String())
+		require.NoError(t, err)
+		require.Equal(t, mode, parsed)
+	}
+
+	_, err := ParseRepairMode("fix")
+	require.Error(t, err)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/node"
 	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
 	"github.com/onflow/flow-go/module"
 	"github.com/onflow/flow-go/utils/io"
@@ -28,6 +29,9 @@ type DiskWAL struct {
 	diskUpdateLimiter *time.Ticker
 	metrics           module.WALMetrics
 	dir               string
+	strictReplay      bool // whether replaying fails on a corrupted record instead of skipping the rest of the WAL
+	// payloadStorage stores the leaf payloads of loaded checkpoints and of the tries checkpoints are created from (nil to hold them in memory)
+	payloadStorage node.PayloadStorage
 }
 
 // TODO use real logger and metrics, but that would require passing them to Trie storage
@@ -48,6 +52,21 @@ func NewDiskWAL(logger zerolog.Logger, reg prometheus.Registerer, metrics module
 	}, nil
 }
 
+// SetStrictReplay sets whether replaying the WAL fails on a corrupted record. By default, the replay stops at the
+// first corrupted record and the rest of the WAL is skipped with a warning, which loses the updates recorded after
+// the corruption. With a strict replay, the corrupted WAL must be checked and repaired first (see CheckIntegrity).
+func (w *DiskWAL) SetStrictReplay(strict bool) {
+	w.strictReplay = strict
+}
+
+// SetPayloadStorage sets the storage of the leaf payloads of the tries loaded from checkpoints, both when
+// replaying the WAL and when creating checkpoints, so the payloads aren't held in memory. By default, the
+// payloads are held in memory. The storage should be the payload storage of the forest the WAL is replayed on,
+// otherwise the loaded tries are converted once more when they are added to the forest.
+func (w *DiskWAL) SetPayloadStorage(storage node.PayloadStorage) {
+	w.payloadStorage = storage
+}
+
 func (w *DiskWAL) PauseRecord() {
 	w.paused = true
 }
@@ -280,6 +299,15 @@ func (w *DiskWAL) replay(
 		}
 	}
 
+	// a corruption stops the reader before the end of the segments
+	err = reader.Err()
+	if err != nil {
+		if w.strictReplay {
+			return fmt.Errorf("cannot read LedgerWAL: %w", err)
+		}
+		w.log.Warn().Err(err).Msg("LedgerWAL is corrupted, skipping the rest of the WAL")
+	}
+
 	w.log.Info().Msgf("finished replaying WAL from %d to %d", from, to)
 
 	return nil
@@ -24,6 +24,9 @@ type Ledger interface {
 	// InitialState returns the initial state of the ledger
 	InitialState() State
 
+	// HasState returns true if the given state exists inside the ledger
+	HasState(state State) bool
+
 	// Get returns values for the given slice of keys at specific state
 	Get(query *Query) (values []Value, err error)
 
@@ -328,3 +331,12 @@ type Reporter interface {
 	// Report accepts slice ledger payloads and reports the state of the ledger
 	Report(payloads []Payload) error
 }
+
+// OwnerReporter is a Reporter which reports on the registers of each owner separately, so that it can be
+// served by an owner index instead of all payloads
+type OwnerReporter interface {
+	Reporter
+	// ReportOwners reports the state of the ledger from the payloads of the given owners, which are read
+	// with ownerPayloads
+	ReportOwners(owners [][]byte, ownerPayloads func(owner []byte) ([]Payload, error)) error
+}
@@ -22,19 +22,33 @@ type Ledger struct {
 }
 
 // NewLedger creates a new in-memory trie-backed ledger storage with persistence.
+// The proof can either be an encoded batch proof or an encoded compact batch proof.
 func NewLedger(proof ledger.Proof, s ledger.State, pathFinderVer uint8) (*Ledger, error) {
 
 	// Decode proof encodings
 	if len(proof) < 1 {
 		return nil, fmt.Errorf("at least a proof is needed to be able to contruct a partial trie")
 	}
+	var psmt *ptrie.PSMT
+	if encoding.IsCompactTrieBatchProof(proof) {
+		compactProof, err := encoding.DecodeCompactTrieBatchProof(proof)
+		if err != nil {
+			return nil, fmt.Errorf("decoding compact proof failed: %w", err)
+		}
+		psmt, err = ptrie.NewPSMTFromCompactProof(ledger.RootHash(s), compactProof)
+		if err != nil {
+			return nil, ledger.NewErrLedgerConstruction(err)
+		}
+		return &Ledger{ptrie: psmt, proof: proof, state: s, pathFinderVersion: pathFinderVer}, nil
+	}
+
 	batchProof, err := encoding.DecodeTrieBatchProof(proof)
 	if err != nil {
 		return nil, fmt.Errorf("decoding proof failed: %w", err)
 	}
 
 	// decode proof
-	psmt, err := ptrie.NewPSMT(ledger.RootHash(s), batchProof)
+	psmt, err = ptrie.NewPSMT(ledger.RootHash(s), batchProof)
 
 	if err != nil {
 		// TODO provide more details based on the error type
@@ -63,6 +77,11 @@ func (l *Ledger) InitialState() ledger.State {
 	return l.state
 }
 
+// HasState returns true if the given state is the state of the partial ledger
+func (l *Ledger) HasState(state ledger.State) bool {
+	return l.state == state
+}
+
 // Get read the values of the given keys at the given state
 // it returns the values in the same order as given registerIDs and errors (if any)
 func (l *Ledger) Get(query *ledger.Query) (values []ledger.Value, err error) {
@@ -116,3 +116,51 @@ func TestProofsForEmptyRegisters(t *testing.T) {
 	require.Empty(t, results[0])
 
 }
+
+func TestFunctionalityWithCompactProofs(t *testing.T) {
+
+	l, err := complete.NewLedger(&fixtures.NoopWAL{}, 100, &metrics.NoopCollector{}, zerolog.Logger{}, complete.DefaultPathFinderVersion)
+	require.NoError(t, err)
+
+	state := l.InitialState()
+	keys := utils.RandomUniqueKeys(20, 2, 2, 4)
+	values := utils.RandomValues(20, 1, 32)
+	update, err := ledger.NewUpdate(state, keys[0:15], values[0:15])
+	require.NoError(t, err)
+
+	newState, _, err := l.Set(update)
+	require.NoError(t, err)
+
+	// prove existing and non-existing keys
+	query, err := ledger.NewQuery(newState, keys[5:20])
+	require.NoError(t, err)
+
+	proof, err := l.Prove(query)
+	require.NoError(t, err)
+	compactProof, err := l.ProveCompact(query)
+	require.NoError(t, err)
+	assert.Less(t, len(compactProof), len(proof))
+
+	pled, err := partial.NewLedger(compactProof, newState, partial.DefaultPathFinderVersion)
+	require.NoError(t, err)
+	assert.Equal(t, pled.InitialState(), newState)
+
+	results, err := pled.Get(query)
+	require.NoError(t, err)
+	expected, err := l.Get(query)
+	require.NoError(t, err)
+	require.Equal(t, expected, results)
+
+	// updates of the partial ledger match updates of the complete ledger
+	update, err = ledger.NewUpdate(newState, keys[5:20], values[5:20])
+	require.NoError(t, err)
+	partialState, _, err := pled.Set(update)
+	require.NoError(t, err)
+	completeState, _, err := l.Set(update)
+	require.NoError(t, err)
+	require.Equal(t, completeState, partialState)
+
+	// proofs don't match a different state
+	_, err = partial.NewLedger(compactProof, state, partial.DefaultPathFinderVersion)
+	require.Error(t, err)
+}
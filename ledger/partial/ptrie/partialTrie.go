@@ -6,6 +6,7 @@ import (
 	"github.com/onflow/flow-go/ledger"
 	"github.com/onflow/flow-go/ledger/common/bitutils"
 	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/common/proof"
 )
 
 // PSMT (Partial Sparse Merkle Tree) holds a subset of an sparse merkle tree at specific
@@ -141,3 +142,15 @@ func NewPSMT(
 	}
 	return &psmt, nil
 }
+
+// NewPSMTFromCompactProof builds a Partial Sparse Merkle Tree (PSMT) given a compact batch proof
+func NewPSMTFromCompactProof(
+	rootValue ledger.RootHash,
+	compactProof *ledger.CompactTrieBatchProof,
+) (*PSMT, error) {
+	batchProof, err := proof.ExpandCompactTrieBatchProof(compactProof)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compact batch proof: %w", err)
+	}
+	return NewPSMT(rootValue, batchProof)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/proof"
 	"github.com/onflow/flow-go/ledger/common/utils"
 	"github.com/onflow/flow-go/ledger/complete/mtrie"
 	"github.com/onflow/flow-go/module/metrics"
@@ -297,6 +298,52 @@ func TestRandomProofs(t *testing.T) {
 	}
 }
 
+func TestCompactProof(t *testing.T) {
+	pathByteSize := 32
+	withForest(t, pathByteSize, 10, func(t *testing.T, f *mtrie.Forest) {
+
+		paths := utils.RandomPaths(100)
+		payloads := utils.RandomPayloads(len(paths), 2, 10)
+
+		rootHash, err := f.Update(&ledger.TrieUpdate{RootHash: f.GetEmptyRootHash(), Paths: paths[:80], Payloads: payloads[:80]})
+		require.NoError(t, err, "error updating trie")
+
+		// read a subset of the inserted paths and some paths which were not inserted
+		// reading proofs permutes the paths
+		readPaths := paths[60:]
+		bp, err := f.Proofs(&ledger.TrieRead{RootHash: rootHash, Paths: append([]ledger.Path{}, readPaths...)})
+		require.NoError(t, err, "error getting batch proof")
+
+		cp, err := proof.NewCompactTrieBatchProof(bp)
+		require.NoError(t, err, "error compacting batch proof")
+
+		psmt, err := NewPSMTFromCompactProof(rootHash, cp)
+		require.NoError(t, err, "error building partial trie")
+		ensureRootHash(t, rootHash, psmt)
+
+		readPayloads, err := psmt.Get(readPaths)
+		require.NoError(t, err)
+		for i, p := range readPayloads {
+			if i < 20 {
+				require.True(t, payloads[60+i].Equals(p))
+			} else {
+				require.True(t, p.IsEmpty())
+			}
+		}
+
+		rootHash2, err := f.Update(&ledger.TrieUpdate{RootHash: rootHash, Paths: readPaths, Payloads: payloads[:40]})
+		require.NoError(t, err, "error updating trie")
+
+		pRootHash2, err := psmt.Update(readPaths, payloads[:40])
+		require.NoError(t, err, "error updating partial trie")
+		assert.Equal(t, rootHash2, pRootHash2, "root2 hash doesn't match [%x] != [%x]", rootHash2, pRootHash2)
+
+		// proofs for a different state are rejected
+		_, err = NewPSMTFromCompactProof(rootHash2, cp)
+		require.Error(t, err)
+	})
+}
+
 // TODO add test for incompatible proofs [Byzantine milestone]
 // TODO add test key not exist [Byzantine milestone]
 
@@ -0,0 +1,202 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/encoding"
+	"github.com/onflow/flow-go/ledger/common/hash"
+	"github.com/onflow/flow-go/ledger/remote/ledgerpb"
+	"github.com/onflow/flow-go/utils/grpcutils"
+)
+
+// Client is a ledger which forwards all operations to a ledger served by a Service in another process.
+type Client struct {
+	conn         *grpc.ClientConn
+	client       ledgerpb.LedgerServiceClient
+	initialState ledger.State
+}
+
+var _ Ledger = (*Client)(nil)
+
+// NewClient connects to the ledger service at the given address, and fetches the initial state of the
+// ledger, which is returned by InitialState. It waits for the service to be reachable until the context
+// is done. The connection is insecure, unless a TLS config is given (see ClientTLSConfig).
+func NewClient(ctx context.Context, addr string, tlsConfig *tls.Config, opts ...grpc.DialOption) (*Client, error) {
+	creds := grpc.WithInsecure() //nolint:staticcheck
+	if tlsConfig != nil {
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+	}
+	opts = append([]grpc.DialOption{
+		creds,
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(grpcutils.DefaultMaxMsgSize),
+			grpc.MaxCallSendMsgSize(grpcutils.DefaultMaxMsgSize),
+		),
+	}, opts...)
+
+	conn, err := grpc.Dial(addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to ledger service at %s: %w", addr, err)
+	}
+	client := ledgerpb.NewLedgerServiceClient(conn)
+
+	response, err := client.InitialState(ctx, &ledgerpb.InitialStateRequest{}, grpc.WaitForReady(true))
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("could not get initial state from ledger service at %s: %w", addr, err)
+	}
+	initialState, err := ledger.ToState(response.GetState())
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("ledger service at %s returned an invalid initial state: %w", addr, err)
+	}
+
+	return &Client{
+		conn:         conn,
+		client:       client,
+		initialState: initialState,
+	}, nil
+}
+
+// ClientTLSConfig returns the TLS config of a ledger service client. The certificate of the service is
+// verified with the CAs of the given PEM file, or the system CAs if no CA file is given. If a certificate
+// and key are given, the client presents them to authenticate with the service.
+func ClientTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS13,
+	}
+	if caFile != "" {
+		rootCAs, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = rootCAs
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load TLS certificate from %s and %s: %w", certFile, keyFile, err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// Ready implements interface module.ReadyDoneAware
+func (c *Client) Ready() <-chan struct{} {
+	ready := make(chan struct{})
+	close(ready)
+	return ready
+}
+
+// Done implements interface module.ReadyDoneAware
+// it closes the connection to the ledger service, the ledger service keeps running.
+func (c *Client) Done() <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		_ = c.conn.Close()
+		close(done)
+	}()
+	return done
+}
+
+// InitialState returns the state of an empty ledger, as fetched when the client was created.
+func (c *Client) InitialState() ledger.State {
+	return c.initialState
+}
+
+// HasState returns whether the ledger service holds the given state.
+// It returns false if the ledger service can't be reached, as the interface doesn't allow errors;
+// reading the state from the service would fail as well.
+func (c *Client) HasState(state ledger.State) bool {
+	response, err := c.client.HasState(context.Background(), &ledgerpb.HasStateRequest{State: state[:]})
+	if err != nil {
+		return false
+	}
+	return response.GetHasState()
+}
+
+// Get returns the values of the given keys at the given state.
+func (c *Client) Get(query *ledger.Query) ([]ledger.Value, error) {
+	response, err := c.client.Get(context.Background(), queryToRequest(query))
+	if err != nil {
+		return nil, fmt.Errorf("could not get values from ledger service: %w", err)
+	}
+
+	values := messagesToValues(response.GetValues())
+	if len(values) != query.Size() {
+		return nil, fmt.Errorf("ledger service returned %d values for %d keys", len(values), query.Size())
+	}
+	return values, nil
+}
+
+// ValueSizes returns the sizes of the values of the given keys at the given state.
+func (c *Client) ValueSizes(query *ledger.Query) ([]int, error) {
+	response, err := c.client.ValueSizes(context.Background(), queryToRequest(query))
+	if err != nil {
+		return nil, fmt.Errorf("could not get value sizes from ledger service: %w", err)
+	}
+
+	if len(response.GetSizes()) != query.Size() {
+		return nil, fmt.Errorf("ledger service returned %d value sizes for %d keys", len(response.GetSizes()), query.Size())
+	}
+	sizes := make([]int, len(response.GetSizes()))
+	for i, size := range response.GetSizes() {
+		sizes[i] = int(size)
+	}
+	return sizes, nil
+}
+
+// Set updates the given keys at the given state, and returns the new state and the trie update.
+func (c *Client) Set(update *ledger.Update) (ledger.State, *ledger.TrieUpdate, error) {
+	state := update.State()
+	request := &ledgerpb.SetRequest{
+		State:  state[:],
+		Keys:   keysToMessages(update.Keys()),
+		Values: valuesToMessages(update.Values()),
+	}
+
+	response, err := c.client.Set(context.Background(), request)
+	if err != nil {
+		return ledger.State(hash.DummyHash), nil, fmt.Errorf("could not update ledger service: %w", err)
+	}
+
+	newState, err := ledger.ToState(response.GetNewState())
+	if err != nil {
+		return ledger.State(hash.DummyHash), nil, fmt.Errorf("ledger service returned an invalid state: %w", err)
+	}
+
+	// empty updates don't have a trie update
+	if len(response.GetTrieUpdate()) == 0 {
+		return newState, nil, nil
+	}
+	trieUpdate, err := encoding.DecodeTrieUpdate(response.GetTrieUpdate())
+	if err != nil {
+		return ledger.State(hash.DummyHash), nil, fmt.Errorf("ledger service returned an invalid trie update: %w", err)
+	}
+
+	return newState, trieUpdate, nil
+}
+
+// Prove returns the encoded batch proof of the given keys at the given state.
+func (c *Client) Prove(query *ledger.Query) (ledger.Proof, error) {
+	response, err := c.client.Prove(context.Background(), queryToRequest(query))
+	if err != nil {
+		return nil, fmt.Errorf("could not get proofs from ledger service: %w", err)
+	}
+	return response.GetProof(), nil
+}
+
+func queryToRequest(query *ledger.Query) *ledgerpb.GetRequest {
+	state := query.State()
+	return &ledgerpb.GetRequest{
+		State: state[:],
+		Keys:  keysToMessages(query.Keys()),
+	}
+}
@@ -0,0 +1,247 @@
+package remote_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/utils"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/wal/fixtures"
+	"github.com/onflow/flow-go/ledger/remote"
+	"github.com/onflow/flow-go/module/metrics"
+)
+
+// withClient serves a new ledger over an in-memory connection, and runs f with a client of this ledger.
+func withClient(t *testing.T, f func(client *remote.Client)) {
+	served, err := complete.NewLedger(&fixtures.NoopWAL{}, 100, &metrics.NoopCollector{}, zerolog.Nop(), complete.DefaultPathFinderVersion)
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := remote.NewServer(served, nil)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	client, err := remote.NewClient(context.Background(), "bufnet", nil, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	require.NoError(t, err)
+	defer func() {
+		<-client.Done()
+	}()
+
+	f(client)
+}
+
+// TestClient applies the same updates to a local ledger and a ledger served over gRPC,
+// and verifies that both ledgers return the same results.
+func TestClient(t *testing.T) {
+	withClient(t, func(client *remote.Client) {
+		local, err := complete.NewLedger(&fixtures.NoopWAL{}, 100, &metrics.NoopCollector{}, zerolog.Nop(), complete.DefaultPathFinderVersion)
+		require.NoError(t, err)
+
+		<-client.Ready()
+		require.Equal(t, local.InitialState(), client.InitialState())
+
+		state := local.InitialState()
+		var allKeys []ledger.Key
+		for i := 0; i < 5; i++ {
+			keys := utils.RandomUniqueKeys(10, 3, 1, 10)
+			values := utils.RandomValues(10, 1, 100)
+			allKeys = append(allKeys, keys...)
+
+			update, err := ledger.NewUpdate(state, keys, values)
+			require.NoError(t, err)
+
+			localState, localTrieUpdate, err := local.Set(update)
+			require.NoError(t, err)
+			remoteState, remoteTrieUpdate, err := client.Set(update)
+			require.NoError(t, err)
+			require.Equal(t, localState, remoteState)
+			require.True(t, localTrieUpdate.Equals(remoteTrieUpdate))
+			require.True(t, client.HasState(remoteState))
+			state = remoteState
+
+			// keys which don't exist are read as empty values
+			queryKeys := append(append([]ledger.Key{}, allKeys...), utils.RandomUniqueKeys(3, 3, 1, 10)...)
+			query, err := ledger.NewQuery(state, queryKeys)
+			require.NoError(t, err)
+
+			localValues, err := local.Get(query)
+			require.NoError(t, err)
+			remoteValues, err := client.Get(query)
+			require.NoError(t, err)
+			require.Len(t, remoteValues, len(localValues))
+			for j := range localValues {
+				require.True(t, localValues[j].Equals(remoteValues[j]))
+			}
+
+			localSizes, err := local.ValueSizes(query)
+			require.NoError(t, err)
+			remoteSizes, err := client.ValueSizes(query)
+			require.NoError(t, err)
+			require.Equal(t, localSizes, remoteSizes)
+
+			localProof, err := local.Prove(query)
+			require.NoError(t, err)
+			remoteProof, err := client.Prove(query)
+			require.NoError(t, err)
+			require.Equal(t, localProof, remoteProof)
+		}
+
+		// empty updates don't change the state
+		update, err := ledger.NewEmptyUpdate(state)
+		require.NoError(t, err)
+		newState, trieUpdate, err := client.Set(update)
+		require.NoError(t, err)
+		require.Equal(t, state, newState)
+		require.Nil(t, trieUpdate)
+	})
+}
+
+func TestClientErrors(t *testing.T) {
+	withClient(t, func(client *remote.Client) {
+		// unknown states can't be read or updated
+		unknownState := ledger.State(utils.RootHashFixture())
+		require.False(t, client.HasState(unknownState))
+
+		query, err := ledger.NewQuery(unknownState, utils.RandomUniqueKeys(1, 3, 1, 10))
+		require.NoError(t, err)
+		_, err = client.Get(query)
+		require.Error(t, err)
+		_, err = client.ValueSizes(query)
+		require.Error(t, err)
+		_, err = client.Prove(query)
+		require.Error(t, err)
+
+		update, err := ledger.NewUpdate(unknownState, utils.RandomUniqueKeys(1, 3, 1, 10), utils.RandomValues(1, 1, 10))
+		require.NoError(t, err)
+		_, _, err = client.Set(update)
+		require.Error(t, err)
+	})
+}
+
+func TestClientUnreachable(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	require.NoError(t, listener.Close())
+
+	// the initial state is fetched when the client is created, so an unreachable service is reported right away
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := remote.NewClient(ctx, "bufnet", nil, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	require.Error(t, err)
+}
+
+func TestClientTLS(t *testing.T) {
+	dir := t.TempDir()
+	caCert, caKey := writeCertificate(t, dir, "ca", nil, nil)
+	writeCertificate(t, dir, "server", caCert, caKey)
+	writeCertificate(t, dir, "client", caCert, caKey)
+	file := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	served, err := complete.NewLedger(&fixtures.NoopWAL{}, 100, &metrics.NoopCollector{}, zerolog.Nop(), complete.DefaultPathFinderVersion)
+	require.NoError(t, err)
+
+	serverConfig, err := remote.ServerTLSConfig(file("server.crt"), file("server.key"), file("ca.crt"))
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := remote.NewServer(served, serverConfig)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	addr := fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port)
+
+	t.Run("authenticated client", func(t *testing.T) {
+		clientConfig, err := remote.ClientTLSConfig(file("ca.crt"), file("client.crt"), file("client.key"))
+		require.NoError(t, err)
+
+		client, err := remote.NewClient(context.Background(), addr, clientConfig)
+		require.NoError(t, err)
+		defer func() {
+			<-client.Done()
+		}()
+		require.Equal(t, served.InitialState(), client.InitialState())
+	})
+
+	t.Run("client without certificate", func(t *testing.T) {
+		clientConfig, err := remote.ClientTLSConfig(file("ca.crt"), "", "")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err = remote.NewClient(ctx, addr, clientConfig)
+		require.Error(t, err)
+	})
+
+	t.Run("insecure client", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err = remote.NewClient(ctx, addr, nil)
+		require.Error(t, err)
+	})
+}
+
+// writeCertificate writes the PEM certificate and key `<name>.crt` and `<name>.key` for localhost to the
+// given directory. The certificate is signed by the given parent, or is a self-signed CA if parent is nil.
+func writeCertificate(t *testing.T, dir string, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent = template
+		parentKey = key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	require.NoError(t, err)
+
+	return cert, key
+}
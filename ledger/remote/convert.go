@@ -0,0 +1,52 @@
+package remote
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/remote/ledgerpb"
+)
+
+func keysToMessages(keys []ledger.Key) []*ledgerpb.Key {
+	messages := make([]*ledgerpb.Key, len(keys))
+	for i, key := range keys {
+		parts := make([]*ledgerpb.KeyPart, len(key.KeyParts))
+		for j, kp := range key.KeyParts {
+			parts[j] = &ledgerpb.KeyPart{Type: uint32(kp.Type), Value: kp.Value}
+		}
+		messages[i] = &ledgerpb.Key{Parts: parts}
+	}
+	return messages
+}
+
+func messagesToKeys(messages []*ledgerpb.Key) ([]ledger.Key, error) {
+	keys := make([]ledger.Key, len(messages))
+	for i, message := range messages {
+		parts := make([]ledger.KeyPart, len(message.GetParts()))
+		for j, part := range message.GetParts() {
+			if part.GetType() > math.MaxUint16 {
+				return nil, fmt.Errorf("invalid type %d of key part %d of key %d", part.GetType(), j, i)
+			}
+			parts[j] = ledger.NewKeyPart(uint16(part.GetType()), part.GetValue())
+		}
+		keys[i] = ledger.NewKey(parts)
+	}
+	return keys, nil
+}
+
+func valuesToMessages(values []ledger.Value) []*ledgerpb.Value {
+	messages := make([]*ledgerpb.Value, len(values))
+	for i, value := range values {
+		messages[i] = &ledgerpb.Value{Data: value}
+	}
+	return messages
+}
+
+func messagesToValues(messages []*ledgerpb.Value) []ledger.Value {
+	values := make([]ledger.Value, len(messages))
+	for i, message := range messages {
+		values[i] = message.GetData()
+	}
+	return values
+}
@@ -0,0 +1,293 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package ledgerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// LedgerServiceClient is the client API for LedgerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LedgerServiceClient interface {
+	// InitialState returns the state of an empty ledger.
+	InitialState(ctx context.Context, in *InitialStateRequest, opts ...grpc.CallOption) (*StateResponse, error)
+	// HasState returns whether the ledger holds the given state.
+	HasState(ctx context.Context, in *HasStateRequest, opts ...grpc.CallOption) (*HasStateResponse, error)
+	// Get returns the values of the given keys at the given state.
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	// ValueSizes returns the sizes of the values of the given keys at the given state.
+	ValueSizes(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*ValueSizesResponse, error)
+	// Set updates the given keys at the given state, and returns the new state.
+	Set(ctx context.Context, in *SetRequest, opts ...grpc.CallOption) (*SetResponse, error)
+	// Prove returns the encoded batch proof of the given keys at the given state.
+	Prove(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*ProofResponse, error)
+}
+
+type ledgerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLedgerServiceClient(cc grpc.ClientConnInterface) LedgerServiceClient {
+	return &ledgerServiceClient{cc}
+}
+
+func (c *ledgerServiceClient) InitialState(ctx context.Context, in *InitialStateRequest, opts ...grpc.CallOption) (*StateResponse, error) {
+	out := new(StateResponse)
+	err := c.cc.Invoke(ctx, "/ledger.LedgerService/InitialState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerServiceClient) HasState(ctx context.Context, in *HasStateRequest, opts ...grpc.CallOption) (*HasStateResponse, error) {
+	out := new(HasStateResponse)
+	err := c.cc.Invoke(ctx, "/ledger.LedgerService/HasState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerServiceClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, "/ledger.LedgerService/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerServiceClient) ValueSizes(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*ValueSizesResponse, error) {
+	out := new(ValueSizesResponse)
+	err := c.cc.Invoke(ctx, "/ledger.LedgerService/ValueSizes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerServiceClient) Set(ctx context.Context, in *SetRequest, opts ...grpc.CallOption) (*SetResponse, error) {
+	out := new(SetResponse)
+	err := c.cc.Invoke(ctx, "/ledger.LedgerService/Set", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerServiceClient) Prove(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*ProofResponse, error) {
+	out := new(ProofResponse)
+	err := c.cc.Invoke(ctx, "/ledger.LedgerService/Prove", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LedgerServiceServer is the server API for LedgerService service.
+// All implementations must embed UnimplementedLedgerServiceServer
+// for forward compatibility
+type LedgerServiceServer interface {
+	// InitialState returns the state of an empty ledger.
+	InitialState(context.Context, *InitialStateRequest) (*StateResponse, error)
+	// HasState returns whether the ledger holds the given state.
+	HasState(context.Context, *HasStateRequest) (*HasStateResponse, error)
+	// Get returns the values of the given keys at the given state.
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	// ValueSizes returns the sizes of the values of the given keys at the given state.
+	ValueSizes(context.Context, *GetRequest) (*ValueSizesResponse, error)
+	// Set updates the given keys at the given state, and returns the new state.
+	Set(context.Context, *SetRequest) (*SetResponse, error)
+	// Prove returns the encoded batch proof of the given keys at the given state.
+	Prove(context.Context, *GetRequest) (*ProofResponse, error)
+	mustEmbedUnimplementedLedgerServiceServer()
+}
+
+// UnimplementedLedgerServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedLedgerServiceServer struct {
+}
+
+func (UnimplementedLedgerServiceServer) InitialState(context.Context, *InitialStateRequest) (*StateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InitialState not implemented")
+}
+func (UnimplementedLedgerServiceServer) HasState(context.Context, *HasStateRequest) (*HasStateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HasState not implemented")
+}
+func (UnimplementedLedgerServiceServer) Get(context.Context, *GetRequest) (*GetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedLedgerServiceServer) ValueSizes(context.Context, *GetRequest) (*ValueSizesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValueSizes not implemented")
+}
+func (UnimplementedLedgerServiceServer) Set(context.Context, *SetRequest) (*SetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Set not implemented")
+}
+func (UnimplementedLedgerServiceServer) Prove(context.Context, *GetRequest) (*ProofResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Prove not implemented")
+}
+func (UnimplementedLedgerServiceServer) mustEmbedUnimplementedLedgerServiceServer() {}
+
+// UnsafeLedgerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LedgerServiceServer will
+// result in compilation errors.
+type UnsafeLedgerServiceServer interface {
+	mustEmbedUnimplementedLedgerServiceServer()
+}
+
+func RegisterLedgerServiceServer(s grpc.ServiceRegistrar, srv LedgerServiceServer) {
+	s.RegisterService(&LedgerService_ServiceDesc, srv)
+}
+
+func _LedgerService_InitialState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InitialStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServiceServer).InitialState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ledger.LedgerService/InitialState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServiceServer).InitialState(ctx, req.(*InitialStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LedgerService_HasState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HasStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServiceServer).HasState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ledger.LedgerService/HasState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServiceServer).HasState(ctx, req.(*HasStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LedgerService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ledger.LedgerService/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServiceServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LedgerService_ValueSizes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServiceServer).ValueSizes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ledger.LedgerService/ValueSizes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServiceServer).ValueSizes(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LedgerService_Set_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServiceServer).Set(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ledger.LedgerService/Set",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServiceServer).Set(ctx, req.(*SetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LedgerService_Prove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServiceServer).Prove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ledger.LedgerService/Prove",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServiceServer).Prove(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LedgerService_ServiceDesc is the grpc.ServiceDesc for LedgerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LedgerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ledger.LedgerService",
+	HandlerType: (*LedgerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "InitialState",
+			Handler:    _LedgerService_InitialState_Handler,
+		},
+		{
+			MethodName: "HasState",
+			Handler:    _LedgerService_HasState_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _LedgerService_Get_Handler,
+		},
+		{
+			MethodName: "ValueSizes",
+			Handler:    _LedgerService_ValueSizes_Handler,
+		},
+		{
+			MethodName: "Set",
+			Handler:    _LedgerService_Set_Handler,
+		},
+		{
+			MethodName: "Prove",
+			Handler:    _LedgerService_Prove_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ledgerpb/ledger.proto",
+}
@@ -0,0 +1,202 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/encoding"
+	"github.com/onflow/flow-go/ledger/remote/ledgerpb"
+	"github.com/onflow/flow-go/utils/grpcutils"
+)
+
+// Ledger is a ledger which can be served by the Service.
+type Ledger interface {
+	ledger.Ledger
+
+	// ValueSizes returns the sizes of the values of the given keys at the given state
+	ValueSizes(query *ledger.Query) (valueSizes []int, err error)
+}
+
+// Service serves a ledger over gRPC, so that it can run in another process than its users.
+// The served ledger owns its WAL and checkpoints.
+type Service struct {
+	ledgerpb.UnimplementedLedgerServiceServer
+	ledger Ledger
+}
+
+var _ ledgerpb.LedgerServiceServer = (*Service)(nil)
+
+// NewService returns a service serving the given ledger.
+func NewService(l Ledger) *Service {
+	return &Service{ledger: l}
+}
+
+// NewServer returns a gRPC server serving the given ledger. The server is insecure, unless a TLS
+// config is given (see ServerTLSConfig).
+func NewServer(l Ledger, tlsConfig *tls.Config) *grpc.Server {
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(grpcutils.DefaultMaxMsgSize),
+		grpc.MaxSendMsgSize(grpcutils.DefaultMaxMsgSize),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	server := grpc.NewServer(opts...)
+	ledgerpb.RegisterLedgerServiceServer(server, NewService(l))
+	return server
+}
+
+// ServerTLSConfig returns the TLS config of a ledger service presenting the certificate and key loaded
+// from the given PEM files. If a client CA file is given, clients are authenticated: only clients
+// presenting a certificate signed by one of the CAs of the PEM file are served.
+func ServerTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not load TLS certificate from %s and %s: %w", certFile, keyFile, err)
+	}
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS13,
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.NoClientCert,
+	}
+	if clientCAFile != "" {
+		clientCAs, err := loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = clientCAs
+	}
+	return config, nil
+}
+
+// loadCertPool returns a pool of the certificates of the given PEM file.
+func loadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read CA certificates from %s: %w", file, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no CA certificates found in %s", file)
+	}
+	return pool, nil
+}
+
+// InitialState returns the state of an empty ledger.
+func (s *Service) InitialState(_ context.Context, _ *ledgerpb.InitialStateRequest) (*ledgerpb.StateResponse, error) {
+	state := s.ledger.InitialState()
+	return &ledgerpb.StateResponse{State: state[:]}, nil
+}
+
+// HasState returns whether the ledger holds the given state.
+func (s *Service) HasState(_ context.Context, req *ledgerpb.HasStateRequest) (*ledgerpb.HasStateResponse, error) {
+	state, err := ledger.ToState(req.GetState())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid state: %v", err)
+	}
+	return &ledgerpb.HasStateResponse{HasState: s.ledger.HasState(state)}, nil
+}
+
+// Get returns the values of the given keys at the given state.
+func (s *Service) Get(_ context.Context, req *ledgerpb.GetRequest) (*ledgerpb.GetResponse, error) {
+	query, err := toQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := s.ledger.Get(query)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not get values: %v", err)
+	}
+
+	return &ledgerpb.GetResponse{Values: valuesToMessages(values)}, nil
+}
+
+// ValueSizes returns the sizes of the values of the given keys at the given state.
+func (s *Service) ValueSizes(_ context.Context, req *ledgerpb.GetRequest) (*ledgerpb.ValueSizesResponse, error) {
+	query, err := toQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes, err := s.ledger.ValueSizes(query)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not get value sizes: %v", err)
+	}
+
+	response := &ledgerpb.ValueSizesResponse{Sizes: make([]uint64, len(sizes))}
+	for i, size := range sizes {
+		response.Sizes[i] = uint64(size)
+	}
+	return response, nil
+}
+
+// Set updates the given keys at the given state, and returns the new state and the encoded trie update.
+func (s *Service) Set(_ context.Context, req *ledgerpb.SetRequest) (*ledgerpb.SetResponse, error) {
+	state, err := ledger.ToState(req.GetState())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid state: %v", err)
+	}
+	keys, err := messagesToKeys(req.GetKeys())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid keys: %v", err)
+	}
+	update, err := ledger.NewUpdate(state, keys, messagesToValues(req.GetValues()))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid update: %v", err)
+	}
+
+	newState, trieUpdate, err := s.ledger.Set(update)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not update ledger: %v", err)
+	}
+
+	response := &ledgerpb.SetResponse{NewState: newState[:]}
+	if trieUpdate != nil {
+		response.TrieUpdate = encoding.EncodeTrieUpdate(trieUpdate)
+	}
+	return response, nil
+}
+
+// Prove returns the encoded batch proof of the given keys at the given state.
+func (s *Service) Prove(_ context.Context, req *ledgerpb.GetRequest) (*ledgerpb.ProofResponse, error) {
+	query, err := toQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := s.ledger.Prove(query)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not get proofs: %v", err)
+	}
+
+	return &ledgerpb.ProofResponse{Proof: proof}, nil
+}
+
+func toQuery(req *ledgerpb.GetRequest) (*ledger.Query, error) {
+	state, err := ledger.ToState(req.GetState())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid state: %v", err)
+	}
+	keys, err := messagesToKeys(req.GetKeys())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid keys: %v", err)
+	}
+	query, err := ledger.NewQuery(state, keys)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid query: %v", err)
+	}
+	return query, nil
+}
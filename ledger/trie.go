@@ -422,3 +422,61 @@ func (bp *TrieBatchProof) Equals(o *TrieBatchProof) bool {
 	}
 	return true
 }
+
+// CompactTrieBatchProof is a space efficient representation of a batch of inclusion proofs.
+//
+// Proofs of registers sharing a part of their path to the root also share the siblings along
+// that part, and a sibling on the path of one register is often an ancestor of another register
+// in the batch. A compact batch proof stores every sibling hash only once, and drops the siblings
+// which can be computed from the registers in the batch.
+//
+// Siblings are listed in the order they are encountered by a depth-first traversal (left child first)
+// of the smallest subtrie containing all registers in the batch.
+type CompactTrieBatchProof struct {
+	Paths    []Path     // paths of the registers, in the order they were queried
+	Payloads []*Payload // payloads of the registers, in the order they were queried
+	Steps    []uint8    // number of steps from the root to the (compactified) leaf of each register
+
+	// SiblingFlags holds one bit for each sibling which can't be computed from the registers in the batch.
+	// The bit is set if the sibling is non-default, in which case its hash is included in Siblings.
+	SiblingFlags []byte
+	SiblingCount uint32      // number of bits used in SiblingFlags
+	Siblings     []hash.Hash // the non-default siblings
+}
+
+// Size returns the number of proofs
+func (p *CompactTrieBatchProof) Size() int {
+	return len(p.Paths)
+}
+
+// Equals compares this compact batch proof to another compact batch proof
+func (p *CompactTrieBatchProof) Equals(o *CompactTrieBatchProof) bool {
+	if o == nil {
+		return false
+	}
+	if len(p.Paths) != len(o.Paths) || len(p.Payloads) != len(o.Payloads) || len(p.Siblings) != len(o.Siblings) {
+		return false
+	}
+	for i, path := range p.Paths {
+		if !path.Equals(o.Paths[i]) {
+			return false
+		}
+	}
+	for i, payload := range p.Payloads {
+		if !payload.Equals(o.Payloads[i]) {
+			return false
+		}
+	}
+	if !bytes.Equal(p.Steps, o.Steps) {
+		return false
+	}
+	if p.SiblingCount != o.SiblingCount || !bytes.Equal(p.SiblingFlags, o.SiblingFlags) {
+		return false
+	}
+	for i, sibling := range p.Siblings {
+		if sibling != o.Siblings[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,14 @@
+package flow
+
+// ProgramUsage is how many times the checked program of a contract was used by the transactions executed by an
+// execution node, for a given code of the contract.
+type ProgramUsage struct {
+	// Address is the address of the account the contract is deployed to.
+	Address Address
+	// Name is the name of the contract.
+	Name string
+	// CodeHash is the hash of the code of the contract the program was checked from.
+	CodeHash Identifier
+	// Uses is the number of times the program was loaded or retrieved from the programs cache.
+	Uses uint64
+}
@@ -0,0 +1,41 @@
+package flow
+
+// TransactionProfile is a compact breakdown of the resources used to execute a transaction,
+// recorded by the execution node which executed it.
+type TransactionProfile struct {
+	// TransactionID is the ID of the profiled transaction.
+	TransactionID Identifier
+	// ComputationUsed is the computation the transaction was charged for.
+	ComputationUsed uint64
+	// MemoryUsed is the memory the transaction was charged for.
+	MemoryUsed uint64
+	// ComputationKinds are the computation kinds which contributed the most to the computation used,
+	// in descending order.
+	ComputationKinds []ProfiledKind
+	// MemoryKinds are the memory kinds which contributed the most to the memory used, in descending order.
+	MemoryKinds []ProfiledKind
+	// RegisterBytesRead is the number of bytes of the registers read by the transaction.
+	RegisterBytesRead uint64
+	// RegisterBytesWritten is the number of bytes of the registers written by the transaction.
+	RegisterBytesWritten uint64
+}
+
+// ID returns a canonical identifier that is guaranteed to be unique.
+func (p TransactionProfile) ID() Identifier {
+	return p.TransactionID
+}
+
+func (p TransactionProfile) Checksum() Identifier {
+	return p.ID()
+}
+
+// ProfiledKind is the usage of a single computation or memory kind by a transaction.
+type ProfiledKind struct {
+	// Kind is the Cadence or FVM computation or memory kind.
+	Kind uint32
+	// Intensity is the total intensity metered for the kind.
+	Intensity uint64
+	// Used is the computation or memory charged for the kind, which is the intensity multiplied by the
+	// weight of the kind. Kinds without a weight are not charged for.
+	Used uint64
+}
@@ -386,6 +386,12 @@ type ExecutionMetrics interface {
 	// ExecutionScriptExecuted reports the time spent on executing an script
 	ExecutionScriptExecuted(dur time.Duration, compUsed uint64)
 
+	// ExecutionScriptAborted reports a script which was timed out, cancelled or rejected before it completed
+	ExecutionScriptAborted(reason string)
+
+	// ExecutionScriptsQueued reports the number of scripts waiting for a script execution worker
+	ExecutionScriptsQueued(queued int)
+
 	// ExecutionCollectionRequestSent reports when a request for a collection is sent to a collection node
 	ExecutionCollectionRequestSent()
 
@@ -419,6 +425,31 @@ type TransactionMetrics interface {
 	TransactionSubmissionFailed()
 }
 
+type AccessMetrics interface {
+	// ConnectionFromPoolReused tracks the number of times a connection to a collection or execution node
+	// is reused from the connection pool
+	ConnectionFromPoolReused()
+
+	// NewConnectionEstablished tracks the number of times a new connection to a collection or execution node
+	// is established, because no connection to the node was available in the connection pool
+	NewConnectionEstablished()
+
+	// ConnectionFromPoolInvalidated tracks the number of times a cached connection is replaced because it
+	// became unhealthy
+	ConnectionFromPoolInvalidated()
+
+	// ConnectionFromPoolEvicted tracks the number of connections removed from the connection pool and closed,
+	// because the pool is full or they have been idle for too long
+	ConnectionFromPoolEvicted()
+
+	// TotalConnectionsInPool tracks the number of connections in the connection pool
+	TotalConnectionsInPool(connectionCount uint)
+
+	// RequestThrottled tracks the number of requests to an API method rejected because the client exceeded its
+	// rate limit for the method
+	RequestThrottled(method string)
+}
+
 type PingMetrics interface {
 	// NodeReachable tracks the round trip time in milliseconds taken to ping a node
 	// The nodeInfo provides additional information about the node such as the name of the node operator
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type AccessCollector struct {
+	connectionReused      prometheus.Counter
+	connectionsInPool     prometheus.Gauge
+	connectionEstablished prometheus.Counter
+	connectionInvalidated prometheus.Counter
+	connectionEvicted     prometheus.Counter
+	requestsThrottled     *prometheus.CounterVec
+}
+
+func NewAccessCollector() *AccessCollector {
+	ac := &AccessCollector{
+		connectionReused: promauto.NewCounter(prometheus.CounterOpts{
+			Name:      "connection_reused",
+			Namespace: namespaceAccess,
+			Subsystem: subsystemConnectionPool,
+			Help:      "counter for the number of times connections get reused",
+		}),
+		connectionsInPool: promauto.NewGauge(prometheus.GaugeOpts{
+			Name:      "connections_in_pool",
+			Namespace: namespaceAccess,
+			Subsystem: subsystemConnectionPool,
+			Help:      "number of connections in the pool",
+		}),
+		connectionEstablished: promauto.NewCounter(prometheus.CounterOpts{
+			Name:      "connection_established",
+			Namespace: namespaceAccess,
+			Subsystem: subsystemConnectionPool,
+			Help:      "counter for the number of times connections are established",
+		}),
+		connectionInvalidated: promauto.NewCounter(prometheus.CounterOpts{
+			Name:      "connection_invalidated",
+			Namespace: namespaceAccess,
+			Subsystem: subsystemConnectionPool,
+			Help:      "counter for the number of times connections are invalidated because they became unhealthy",
+		}),
+		connectionEvicted: promauto.NewCounter(prometheus.CounterOpts{
+			Name:      "connection_evicted",
+			Namespace: namespaceAccess,
+			Subsystem: subsystemConnectionPool,
+			Help:      "counter for the number of times connections are removed from the pool and closed",
+		}),
+		requestsThrottled: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name:      "requests_throttled",
+			Namespace: namespaceAccess,
+			Subsystem: subsystemRateLimit,
+			Help:      "counter for the number of requests rejected because the client exceeded its rate limit",
+		}, []string{LabelMethod}),
+	}
+
+	return ac
+}
+
+func (ac *AccessCollector) ConnectionFromPoolReused() {
+	ac.connectionReused.Inc()
+}
+
+func (ac *AccessCollector) NewConnectionEstablished() {
+	ac.connectionEstablished.Inc()
+}
+
+func (ac *AccessCollector) ConnectionFromPoolInvalidated() {
+	ac.connectionInvalidated.Inc()
+}
+
+func (ac *AccessCollector) ConnectionFromPoolEvicted() {
+	ac.connectionEvicted.Inc()
+}
+
+func (ac *AccessCollector) TotalConnectionsInPool(connectionCount uint) {
+	ac.connectionsInPool.Set(float64(connectionCount))
+}
+
+func (ac *AccessCollector) RequestThrottled(method string) {
+	ac.requestsThrottled.WithLabelValues(method).Inc()
+}
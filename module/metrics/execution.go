@@ -55,6 +55,8 @@ type ExecutionCollector struct {
 	transactionEmittedEvents         prometheus.Histogram
 	scriptExecutionTime              prometheus.Histogram
 	scriptComputationUsed            prometheus.Histogram
+	abortedScriptsCounter            *prometheus.CounterVec
+	queuedScriptsGauge               prometheus.Gauge
 	numberOfAccounts                 prometheus.Gauge
 	totalChunkDataPackRequests       prometheus.Counter
 	stateSyncActive                  prometheus.Gauge
@@ -421,6 +423,20 @@ func NewExecutionCollector(tracer module.Tracer) *ExecutionCollector {
 			Help:      "the total number of scripts that have been executed",
 		}),
 
+		abortedScriptsCounter: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespaceExecution,
+			Subsystem: subsystemRuntime,
+			Name:      "total_aborted_scripts",
+			Help:      "the total number of scripts that have been timed out, cancelled or rejected before completing",
+		}, []string{LabelReason}),
+
+		queuedScriptsGauge: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespaceExecution,
+			Subsystem: subsystemRuntime,
+			Name:      "queued_scripts",
+			Help:      "the number of scripts waiting for a script execution worker",
+		}),
+
 		lastExecutedBlockHeightGauge: promauto.NewGauge(prometheus.GaugeOpts{
 			Namespace: namespaceExecution,
 			Subsystem: subsystemRuntime,
@@ -512,6 +528,16 @@ func (ec *ExecutionCollector) ExecutionScriptExecuted(dur time.Duration, compUse
 	ec.scriptComputationUsed.Observe(float64(compUsed))
 }
 
+// ExecutionScriptAborted reports a script which was timed out, cancelled or rejected before it completed
+func (ec *ExecutionCollector) ExecutionScriptAborted(reason string) {
+	ec.abortedScriptsCounter.WithLabelValues(reason).Inc()
+}
+
+// ExecutionScriptsQueued reports the number of scripts waiting for a script execution worker
+func (ec *ExecutionCollector) ExecutionScriptsQueued(queued int) {
+	ec.queuedScriptsGauge.Set(float64(queued))
+}
+
 // ExecutionStateReadsPerBlock reports number of state access/read operations per block
 func (ec *ExecutionCollector) ExecutionStateReadsPerBlock(reads uint64) {
 	ec.stateReadsPerBlock.Observe(float64(reads))
@@ -13,6 +13,8 @@ const (
 	LabelNodeInfo    = "nodeinfo"
 	LabelNodeVersion = "nodeversion"
 	LabelPriority    = "priority"
+	LabelMethod      = "method"
+	LabelReason      = "reason"
 )
 
 const (
@@ -96,6 +98,7 @@ const (
 	ResourceTransactionResults        = "transaction_results"               // execution node
 	ResourceTransactionResultIndices  = "transaction_result_indices"        // execution node
 	ResourceTransactionResultByBlock  = "transaction_result_by_block"       // execution node
+	ResourceTransactionProfiles       = "transaction_profiles"              // execution node
 )
 
 const (
@@ -121,3 +124,9 @@ const (
 	MessageEntityRequest        = "entity_request"
 	MessageEntityResponse       = "entity_response"
 )
+
+const (
+	ScriptAbortedTimedOut  = "timed_out"
+	ScriptAbortedCancelled = "cancelled"
+	ScriptAbortedRejected  = "rejected"
+)
@@ -32,6 +32,8 @@ const (
 const (
 	subsystemTransactionTiming     = "transaction_timing"
 	subsystemTransactionSubmission = "transaction_submission"
+	subsystemConnectionPool        = "connection_pool"
+	subsystemRateLimit             = "rate_limit"
 )
 
 // Collection subsystem
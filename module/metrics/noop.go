@@ -119,6 +119,8 @@ func (nc *NoopCollector) ExecutionBlockExecuted(_ time.Duration, _ uint64, _ int
 func (nc *NoopCollector) ExecutionCollectionExecuted(_ time.Duration, _ uint64, _ int)          {}
 func (nc *NoopCollector) ExecutionTransactionExecuted(_ time.Duration, _ uint64, _ int, _ bool) {}
 func (nc *NoopCollector) ExecutionScriptExecuted(dur time.Duration, compUsed uint64)            {}
+func (nc *NoopCollector) ExecutionScriptAborted(reason string)                                  {}
+func (nc *NoopCollector) ExecutionScriptsQueued(queued int)                                     {}
 func (nc *NoopCollector) ForestApproxMemorySize(bytes uint64)                                   {}
 func (nc *NoopCollector) ForestNumberOfTrees(number uint64)                                     {}
 func (nc *NoopCollector) LatestTrieRegCount(number uint64)                                      {}
@@ -147,6 +149,12 @@ func (nc *NoopCollector) TransactionFinalized(txID flow.Identifier, when time.Ti
 func (nc *NoopCollector) TransactionExecuted(txID flow.Identifier, when time.Time)              {}
 func (nc *NoopCollector) TransactionExpired(txID flow.Identifier)                               {}
 func (nc *NoopCollector) TransactionSubmissionFailed()                                          {}
+func (nc *NoopCollector) ConnectionFromPoolReused()                                             {}
+func (nc *NoopCollector) NewConnectionEstablished()                                             {}
+func (nc *NoopCollector) ConnectionFromPoolInvalidated()                                        {}
+func (nc *NoopCollector) ConnectionFromPoolEvicted()                                            {}
+func (nc *NoopCollector) TotalConnectionsInPool(connectionCount uint)                           {}
+func (nc *NoopCollector) RequestThrottled(method string)                                        {}
 func (nc *NoopCollector) ChunkDataPackRequested()                                               {}
 func (nc *NoopCollector) ExecutionSync(syncing bool)                                            {}
 func (nc *NoopCollector) DiskSize(uint64)                                                       {}
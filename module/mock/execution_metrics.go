@@ -7,7 +7,6 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	testing "testing"
-
 	time "time"
 )
 
@@ -61,11 +60,21 @@ func (_m *ExecutionMetrics) ExecutionLastExecutedBlockHeight(height uint64) {
 	_m.Called(height)
 }
 
+// ExecutionScriptAborted provides a mock function with given fields: reason
+func (_m *ExecutionMetrics) ExecutionScriptAborted(reason string) {
+	_m.Called(reason)
+}
+
 // ExecutionScriptExecuted provides a mock function with given fields: dur, compUsed
 func (_m *ExecutionMetrics) ExecutionScriptExecuted(dur time.Duration, compUsed uint64) {
 	_m.Called(dur, compUsed)
 }
 
+// ExecutionScriptsQueued provides a mock function with given fields: queued
+func (_m *ExecutionMetrics) ExecutionScriptsQueued(queued int) {
+	_m.Called(queued)
+}
+
 // ExecutionStateReadsPerBlock provides a mock function with given fields: reads
 func (_m *ExecutionMetrics) ExecutionStateReadsPerBlock(reads uint64) {
 	_m.Called(reads)
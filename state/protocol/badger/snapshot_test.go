@@ -64,6 +64,8 @@ func TestSnapshot_Params(t *testing.T) {
 	require.NoError(t, err)
 	expectedSporkID, err := rootSnapshot.Params().SporkID()
 	require.NoError(t, err)
+	expectedSporkRootBlockHeight, err := rootSnapshot.Params().SporkRootBlockHeight()
+	require.NoError(t, err)
 	expectedProtocolVersion, err := rootSnapshot.Params().ProtocolVersion()
 	require.NoError(t, err)
 
@@ -100,6 +102,11 @@ func TestSnapshot_Params(t *testing.T) {
 				require.NoError(t, err)
 				assert.Equal(t, expectedSporkID, sporkID)
 			})
+			t.Run("should be able to get spork root block height from snapshot", func(t *testing.T) {
+				sporkRootBlockHeight, err := snapshot.Params().SporkRootBlockHeight()
+				require.NoError(t, err)
+				assert.Equal(t, expectedSporkRootBlockHeight, sporkRootBlockHeight)
+			})
 			t.Run("should be able to get protocol version from snapshot", func(t *testing.T) {
 				protocolVersion, err := snapshot.Params().ProtocolVersion()
 				require.NoError(t, err)
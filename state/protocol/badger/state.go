@@ -415,6 +415,15 @@ func (state *State) bootstrapSporkInfo(root protocol.Snapshot) func(*badger.Txn)
 			return fmt.Errorf("could not insert spork ID: %w", err)
 		}
 
+		sporkRootBlockHeight, err := params.SporkRootBlockHeight()
+		if err != nil {
+			return fmt.Errorf("could not get spork root block height: %w", err)
+		}
+		err = operation.InsertSporkRootBlockHeight(sporkRootBlockHeight)(tx)
+		if err != nil {
+			return fmt.Errorf("could not insert spork root block height: %w", err)
+		}
+
 		version, err := params.ProtocolVersion()
 		if err != nil {
 			return fmt.Errorf("could not get protocol version: %w", err)
@@ -428,6 +437,27 @@ func (state *State) bootstrapSporkInfo(root protocol.Snapshot) func(*badger.Txn)
 	}
 }
 
+// backfillSporkRootBlockHeight stores the root block height as the spork root block height of protocol
+// states which were bootstrapped before the spork root block height was stored. States bootstrapped from
+// the root snapshot of the spork get the exact height, other states the earliest height they know of.
+func backfillSporkRootBlockHeight(tx *badger.Txn) error {
+	var sporkRootBlockHeight uint64
+	err := operation.RetrieveSporkRootBlockHeight(&sporkRootBlockHeight)(tx)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("could not get spork root block height: %w", err)
+	}
+
+	var rootHeight uint64
+	err = operation.RetrieveRootHeight(&rootHeight)(tx)
+	if err != nil {
+		return fmt.Errorf("could not get root height: %w", err)
+	}
+	return operation.InsertSporkRootBlockHeight(rootHeight)(tx)
+}
+
 func OpenState(
 	metrics module.ComplianceMetrics,
 	db *badger.DB,
@@ -446,6 +476,11 @@ func OpenState(
 	if !isBootstrapped {
 		return nil, fmt.Errorf("expected database to contain bootstrapped state")
 	}
+	err = db.Update(backfillSporkRootBlockHeight)
+	if err != nil {
+		return nil, fmt.Errorf("could not backfill spork root block height: %w", err)
+	}
+
 	state := newState(metrics, db, headers, seals, results, blocks, setups, commits, statuses)
 
 	finalSnapshot := state.Final()
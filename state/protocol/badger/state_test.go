@@ -19,7 +19,9 @@ import (
 	"github.com/onflow/flow-go/state/protocol/inmem"
 	"github.com/onflow/flow-go/state/protocol/util"
 	protoutil "github.com/onflow/flow-go/state/protocol/util"
+	"github.com/onflow/flow-go/storage"
 	storagebadger "github.com/onflow/flow-go/storage/badger"
+	"github.com/onflow/flow-go/storage/badger/operation"
 	storutil "github.com/onflow/flow-go/storage/util"
 	"github.com/onflow/flow-go/utils/unittest"
 )
@@ -69,6 +71,35 @@ func TestBootstrapAndOpen(t *testing.T) {
 	})
 }
 
+// TestOpen_WithoutSporkRootBlockHeight verifies that opening a protocol state bootstrapped before the
+// spork root block height was stored backfills the spork root block height from the root block height.
+func TestOpen_WithoutSporkRootBlockHeight(t *testing.T) {
+	participants := unittest.CompleteIdentitySet()
+	rootSnapshot := unittest.RootSnapshotFixture(participants)
+	rootHeader, err := rootSnapshot.Head()
+	require.NoError(t, err)
+
+	protoutil.RunWithBootstrapState(t, rootSnapshot, func(db *badger.DB, _ *bprotocol.State) {
+		// remove the spork root block height (its key is the bare code 15), as states bootstrapped
+		// before it was stored don't hold it
+		err := db.Update(func(tx *badger.Txn) error {
+			return tx.Delete([]byte{15})
+		})
+		require.NoError(t, err)
+		var removed uint64
+		err = db.View(operation.RetrieveSporkRootBlockHeight(&removed))
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		all := storagebadger.InitAll(metrics.NewNoopCollector(), db)
+		state, err := bprotocol.OpenState(metrics.NewNoopCollector(), db, all.Headers, all.Seals, all.Results, all.Blocks, all.Setups, all.EpochCommits, all.Statuses)
+		require.NoError(t, err)
+
+		sporkRootBlockHeight, err := state.Params().SporkRootBlockHeight()
+		require.NoError(t, err)
+		assert.Equal(t, rootHeader.Height, sporkRootBlockHeight)
+	})
+}
+
 // TestBootstrapAndOpen_EpochCommitted verifies after bootstrapping with a
 // root snapshot from EpochCommitted phase  we should be able to open it and
 // got the same state.
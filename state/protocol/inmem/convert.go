@@ -101,6 +101,10 @@ func FromParams(from protocol.GlobalParams) (*Params, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not get spork id: %w", err)
 	}
+	params.SporkRootBlockHeight, err = from.SporkRootBlockHeight()
+	if err != nil {
+		return nil, fmt.Errorf("could not get spork root block height: %w", err)
+	}
 	params.ProtocolVersion, err = from.ProtocolVersion()
 	if err != nil {
 		return nil, fmt.Errorf("could not get protocol version: %w", err)
@@ -252,9 +256,10 @@ func SnapshotFromBootstrapStateWithProtocolVersion(
 	}
 
 	params := EncodableParams{
-		ChainID:         root.Header.ChainID, // chain ID must match the root block
-		SporkID:         root.ID(),           // use root block ID as the unique spork identifier
-		ProtocolVersion: version,             // major software version for this spork
+		ChainID:              root.Header.ChainID, // chain ID must match the root block
+		SporkID:              root.ID(),           // use root block ID as the unique spork identifier
+		SporkRootBlockHeight: root.Header.Height,  // use root block height as the spork root block height
+		ProtocolVersion:      version,             // major software version for this spork
 	}
 
 	snap := SnapshotFromEncodable(EncodableSnapshot{
@@ -1,6 +1,9 @@
 package inmem
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/onflow/flow-go/model/cluster"
 	"github.com/onflow/flow-go/model/encodable"
 	"github.com/onflow/flow-go/model/flow"
@@ -64,7 +67,30 @@ type EncodableCluster struct {
 
 // EncodableParams is the encoding format for protocol.GlobalParams
 type EncodableParams struct {
-	ChainID         flow.ChainID
-	SporkID         flow.Identifier
-	ProtocolVersion uint
+	ChainID              flow.ChainID
+	SporkID              flow.Identifier
+	SporkRootBlockHeight uint64
+	ProtocolVersion      uint
+}
+
+// UnmarshalJSON decodes the params, and returns an error if the spork root block height is missing, as
+// params encoded before it was added can't be told apart from params of a spork starting at height 0.
+func (p *EncodableParams) UnmarshalJSON(data []byte) error {
+	// params has the fields of EncodableParams, without its methods
+	type params EncodableParams
+	var decoded struct {
+		params
+		SporkRootBlockHeight *uint64
+	}
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err
+	}
+	if decoded.SporkRootBlockHeight == nil {
+		return fmt.Errorf("missing spork root block height")
+	}
+
+	*p = EncodableParams(decoded.params)
+	p.SporkRootBlockHeight = *decoded.SporkRootBlockHeight
+	return nil
 }
@@ -60,3 +60,24 @@ func TestStrippedEncodeDecode(t *testing.T) {
 		return id.Address == ""
 	}), len(participants))
 }
+
+// TestDecodeParams_MissingSporkRootBlockHeight tests that params encoded without the spork root block height
+// are rejected, rather than decoded with a spork root block height of 0.
+func TestDecodeParams_MissingSporkRootBlockHeight(t *testing.T) {
+	var params inmem.EncodableParams
+	err := json.Unmarshal([]byte(`{"ChainID":"flow-localnet","ProtocolVersion":2}`), &params)
+	require.Error(t, err)
+
+	err = json.Unmarshal([]byte(`{"ChainID":"flow-localnet","SporkRootBlockHeight":0,"ProtocolVersion":2}`), &params)
+	require.NoError(t, err)
+	assert.Equal(t, flow.Localnet, params.ChainID)
+	assert.Equal(t, uint64(0), params.SporkRootBlockHeight)
+	assert.Equal(t, uint(2), params.ProtocolVersion)
+
+	encoded, err := json.Marshal(inmem.EncodableParams{ChainID: flow.Mainnet, SporkRootBlockHeight: 100})
+	require.NoError(t, err)
+	var decoded inmem.EncodableParams
+	err = json.Unmarshal(encoded, &decoded)
+	require.NoError(t, err)
+	assert.Equal(t, inmem.EncodableParams{ChainID: flow.Mainnet, SporkRootBlockHeight: 100}, decoded)
+}
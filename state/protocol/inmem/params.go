@@ -16,6 +16,10 @@ func (p Params) SporkID() (flow.Identifier, error) {
 	return p.enc.SporkID, nil
 }
 
+func (p Params) SporkRootBlockHeight() (uint64, error) {
+	return p.enc.SporkRootBlockHeight, nil
+}
+
 func (p Params) ProtocolVersion() (uint, error) {
 	return p.enc.ProtocolVersion, nil
 }
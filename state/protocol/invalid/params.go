@@ -17,6 +17,10 @@ func (p *Params) SporkID() (flow.Identifier, error) {
 	return flow.ZeroID, p.err
 }
 
+func (p *Params) SporkRootBlockHeight() (uint64, error) {
+	return 0, p.err
+}
+
 func (p *Params) ProtocolVersion() (uint, error) {
 	return 0, p.err
 }
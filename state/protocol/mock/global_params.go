@@ -79,6 +79,27 @@ func (_m *GlobalParams) SporkID() (flow.Identifier, error) {
 	return r0, r1
 }
 
+// SporkRootBlockHeight provides a mock function with given fields:
+func (_m *GlobalParams) SporkRootBlockHeight() (uint64, error) {
+	ret := _m.Called()
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewGlobalParams creates a new instance of GlobalParams. It also registers the testing.TB interface on the mock and a cleanup function to assert the mocks expectations.
 func NewGlobalParams(t testing.TB) *GlobalParams {
 	mock := &GlobalParams{}
@@ -42,6 +42,12 @@ type GlobalParams interface {
 	// part of the root protocol state snapshot.
 	SporkID() (flow.Identifier, error)
 
+	// SporkRootBlockHeight returns the height of the spork's root block.
+	// This value is determined at the beginning of a spork during bootstrapping.
+	// If node uses a sealing segment for bootstrapping then this value will be carried over
+	// as part of snapshot.
+	SporkRootBlockHeight() (uint64, error)
+
 	// ProtocolVersion returns the protocol version, the major software version
 	// of the protocol software.
 	ProtocolVersion() (uint, error)
@@ -413,6 +413,33 @@ func traverse(prefix []byte, iteration iterationFunc) func(*badger.Txn) error {
 	}
 }
 
+// seek will decode the value of the first key which is greater than or equal to the given start key and shares
+// the given prefix into the given entity. It returns storage.ErrNotFound if there is no such key.
+func seek(prefix []byte, start []byte, entity interface{}) func(*badger.Txn) error {
+	return func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		opts.PrefetchValues = false
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		it.Seek(start)
+		if !it.ValidForPrefix(prefix) {
+			return storage.ErrNotFound
+		}
+
+		err := it.Item().Value(func(val []byte) error {
+			return msgpack.Unmarshal(val, entity)
+		})
+		if err != nil {
+			return fmt.Errorf("could not decode entity: %w", err)
+		}
+
+		return nil
+	}
+}
+
 // Fail returns a DB operation function that always fails with the given error.
 func Fail(err error) func(*badger.Txn) error {
 	return func(_ *badger.Txn) error {
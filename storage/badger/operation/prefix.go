@@ -23,6 +23,7 @@ const (
 	codeRootQuorumCertificate = 12
 	codeSporkID               = 13
 	codeProtocolVersion       = 14
+	codeSporkRootBlockHeight  = 15
 
 	// code for heights with special meaning
 	codeFinalizedHeight         = 20 // latest finalized block height
@@ -31,6 +32,8 @@ const (
 	codeExecutedBlock           = 23 // latest executed block with max height
 	codeRootHeight              = 24 // the height of the first loaded block
 	codeLastCompleteBlockHeight = 25 // the height of the last block for which all collections were received
+	codeRegistersFirstHeight    = 26 // the first height of the register index
+	codeRegistersLatestHeight   = 27 // the latest height of the register index
 
 	// codes for single entity storage
 	// 31 was used for identities before epochs
@@ -85,6 +88,10 @@ const (
 	codeFinalizedCluster             = 105
 	codeServiceEvent                 = 106
 	codeTransactionResultIndex       = 107
+	codeRegister                     = 108
+	codeBlockRegisters               = 109 // register entries updated by an executed block, until its height is indexed
+	codeTransactionProfile           = 110 // resources used by an executed transaction, keyed by block ID and transaction ID
+	codeProgramUsage                 = 111 // uses of the program of a contract, keyed by contract location and code hash
 	codeIndexCollection              = 200
 	codeIndexExecutionResultByBlock  = 202
 	codeIndexCollectionByTransaction = 203
@@ -0,0 +1,55 @@
+package operation
+
+import (
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// programLocation returns a fixed length ID for the location of a contract, so that the program usages of the
+// contract, whatever its code, share a key prefix.
+func programLocation(address flow.Address, name string) flow.Identifier {
+	return flow.MakeID(struct {
+		Address flow.Address
+		Name    string
+	}{
+		Address: address,
+		Name:    name,
+	})
+}
+
+func InsertProgramUsage(usage *flow.ProgramUsage) func(*badger.Txn) error {
+	return insert(makePrefix(codeProgramUsage, programLocation(usage.Address, usage.Name), usage.CodeHash), usage)
+}
+
+func UpdateProgramUsage(usage *flow.ProgramUsage) func(*badger.Txn) error {
+	return update(makePrefix(codeProgramUsage, programLocation(usage.Address, usage.Name), usage.CodeHash), usage)
+}
+
+func RetrieveProgramUsage(address flow.Address, name string, codeHash flow.Identifier, usage *flow.ProgramUsage) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeProgramUsage, programLocation(address, name), codeHash), usage)
+}
+
+// LookupProgramUsages retrieves the program usages of all contracts.
+func LookupProgramUsages(usages *[]flow.ProgramUsage) func(*badger.Txn) error {
+	iterationFunc := func() (checkFunc, createFunc, handleFunc) {
+		check := func(key []byte) bool {
+			return true
+		}
+		var val flow.ProgramUsage
+		create := func() interface{} {
+			return &val
+		}
+		handle := func() error {
+			*usages = append(*usages, val)
+			return nil
+		}
+		return check, create, handle
+	}
+	return traverse(makePrefix(codeProgramUsage), iterationFunc)
+}
+
+// RemoveProgramUsagesByLocation removes the program usages of the contract, for all of its codes.
+func RemoveProgramUsagesByLocation(address flow.Address, name string) func(*badger.Txn) error {
+	return removeByPrefix(makePrefix(codeProgramUsage, programLocation(address, name)))
+}
@@ -0,0 +1,48 @@
+package operation
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestProgramUsagesInsertUpdateRetrieveRemove(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		address := unittest.AddressFixture()
+		usage := flow.ProgramUsage{Address: address, Name: "Foo", CodeHash: unittest.IdentifierFixture(), Uses: 1}
+		previous := flow.ProgramUsage{Address: address, Name: "Foo", CodeHash: unittest.IdentifierFixture(), Uses: 2}
+		// a contract with a name starting with the name of the other contract
+		other := flow.ProgramUsage{Address: address, Name: "FooBar", CodeHash: usage.CodeHash, Uses: 3}
+
+		for _, u := range []flow.ProgramUsage{usage, previous, other} {
+			u := u
+			require.NoError(t, db.Update(InsertProgramUsage(&u)))
+		}
+
+		usage.Uses = 10
+		require.NoError(t, db.Update(UpdateProgramUsage(&usage)))
+
+		var actual flow.ProgramUsage
+		require.NoError(t, db.View(RetrieveProgramUsage(address, "Foo", usage.CodeHash, &actual)))
+		assert.Equal(t, usage, actual)
+
+		var usages []flow.ProgramUsage
+		require.NoError(t, db.View(LookupProgramUsages(&usages)))
+		assert.ElementsMatch(t, []flow.ProgramUsage{usage, previous, other}, usages)
+
+		require.NoError(t, db.Update(RemoveProgramUsagesByLocation(address, "Foo")))
+
+		err := db.View(RetrieveProgramUsage(address, "Foo", usage.CodeHash, &actual))
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+
+		usages = nil
+		require.NoError(t, db.View(LookupProgramUsages(&usages)))
+		assert.Equal(t, []flow.ProgramUsage{other}, usages)
+	})
+}
@@ -0,0 +1,55 @@
+package operation
+
+import (
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// BatchInsertRegister stores the value of the register at the given height. Registers are keyed by the hash of the
+// register ID followed by the inverted height, so that the value of a register at a height is the first value found
+// at or after the key of the register at that height.
+func BatchInsertRegister(height uint64, id flow.RegisterID, value flow.RegisterValue) func(batch *badger.WriteBatch) error {
+	return batchWrite(makePrefix(codeRegister, flow.MakeID(id), ^height), value)
+}
+
+// LookupRegister retrieves the latest value of the register stored at or below the given height.
+// Returns storage.ErrNotFound if no value was stored for the register at or below the height.
+func LookupRegister(id flow.RegisterID, height uint64, value *flow.RegisterValue) func(*badger.Txn) error {
+	registerID := flow.MakeID(id)
+	return seek(makePrefix(codeRegister, registerID), makePrefix(codeRegister, registerID, ^height), value)
+}
+
+func InsertRegistersFirstHeight(height uint64) func(*badger.Txn) error {
+	return insert(makePrefix(codeRegistersFirstHeight), height)
+}
+
+func RetrieveRegistersFirstHeight(height *uint64) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeRegistersFirstHeight), height)
+}
+
+func InsertRegistersLatestHeight(height uint64) func(*badger.Txn) error {
+	return insert(makePrefix(codeRegistersLatestHeight), height)
+}
+
+func UpdateRegistersLatestHeight(height uint64) func(*badger.Txn) error {
+	return update(makePrefix(codeRegistersLatestHeight), height)
+}
+
+func RetrieveRegistersLatestHeight(height *uint64) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeRegistersLatestHeight), height)
+}
+
+// BatchInsertBlockRegisters stores the register entries updated by the executed block with the given ID. As a block
+// may be executed again after a restart, existing entries are overwritten.
+func BatchInsertBlockRegisters(blockID flow.Identifier, entries flow.RegisterEntries) func(batch *badger.WriteBatch) error {
+	return batchWrite(makePrefix(codeBlockRegisters, blockID), entries)
+}
+
+func RetrieveBlockRegisters(blockID flow.Identifier, entries *flow.RegisterEntries) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeBlockRegisters, blockID), entries)
+}
+
+func RemoveBlockRegisters(blockID flow.Identifier) func(*badger.Txn) error {
+	return remove(makePrefix(codeBlockRegisters, blockID))
+}
@@ -0,0 +1,93 @@
+package operation
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/unittest"
+)
+
+func TestRegisterInsertLookup(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		id := flow.NewRegisterID("owner", "controller", "key")
+		other := flow.NewRegisterID("owner", "controller", "other")
+
+		batch := db.NewWriteBatch()
+		require.NoError(t, BatchInsertRegister(10, id, []byte("a"))(batch))
+		require.NoError(t, BatchInsertRegister(12, id, []byte("b"))(batch))
+		require.NoError(t, BatchInsertRegister(11, other, []byte("c"))(batch))
+		require.NoError(t, batch.Flush())
+
+		var value flow.RegisterValue
+
+		// no value was stored below the first height
+		err := db.View(LookupRegister(id, 9, &value))
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+
+		for height, expected := range map[uint64]string{10: "a", 11: "a", 12: "b", 100: "b"} {
+			err = db.View(LookupRegister(id, height, &value))
+			require.NoError(t, err)
+			assert.Equal(t, []byte(expected), value, "height %d", height)
+		}
+
+		err = db.View(LookupRegister(other, 10, &value))
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+		err = db.View(LookupRegister(other, 11, &value))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("c"), value)
+	})
+}
+
+func TestRegistersHeightsInsertUpdateRetrieve(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		err := db.Update(InsertRegistersFirstHeight(5))
+		require.NoError(t, err)
+		err = db.Update(InsertRegistersLatestHeight(5))
+		require.NoError(t, err)
+		err = db.Update(UpdateRegistersLatestHeight(6))
+		require.NoError(t, err)
+
+		var first, latest uint64
+		err = db.View(RetrieveRegistersFirstHeight(&first))
+		require.NoError(t, err)
+		err = db.View(RetrieveRegistersLatestHeight(&latest))
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(5), first)
+		assert.Equal(t, uint64(6), latest)
+	})
+}
+
+func TestBlockRegistersInsertRetrieveRemove(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		blockID := unittest.IdentifierFixture()
+		entries := flow.RegisterEntries{
+			{Key: flow.NewRegisterID("owner", "controller", "key"), Value: []byte("a")},
+			{Key: flow.NewRegisterID("owner", "controller", "other"), Value: []byte("b")},
+		}
+
+		batch := db.NewWriteBatch()
+		require.NoError(t, BatchInsertBlockRegisters(blockID, entries[:1])(batch))
+		require.NoError(t, batch.Flush())
+
+		// re-executed blocks overwrite their entries
+		batch = db.NewWriteBatch()
+		require.NoError(t, BatchInsertBlockRegisters(blockID, entries)(batch))
+		require.NoError(t, batch.Flush())
+
+		var retrieved flow.RegisterEntries
+		err := db.View(RetrieveBlockRegisters(blockID, &retrieved))
+		require.NoError(t, err)
+		assert.Equal(t, entries, retrieved)
+
+		err = db.Update(RemoveBlockRegisters(blockID))
+		require.NoError(t, err)
+		err = db.View(RetrieveBlockRegisters(blockID, &retrieved))
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
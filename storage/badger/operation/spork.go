@@ -18,6 +18,18 @@ func RetrieveSporkID(sporkID *flow.Identifier) func(*badger.Txn) error {
 	return retrieve(makePrefix(codeSporkID), sporkID)
 }
 
+// InsertSporkRootBlockHeight inserts the spork root block height for the present spork.
+// A single database and protocol state instance spans at most one spork, so this is inserted
+// exactly once, when bootstrapping the state.
+func InsertSporkRootBlockHeight(height uint64) func(*badger.Txn) error {
+	return insert(makePrefix(codeSporkRootBlockHeight), height)
+}
+
+// RetrieveSporkRootBlockHeight retrieves the spork root block height for the present spork.
+func RetrieveSporkRootBlockHeight(height *uint64) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeSporkRootBlockHeight), height)
+}
+
 // InsertProtocolVersion inserts the protocol version for the present spork.
 // A single database and protocol state instance spans at most one spork, and
 // a spork has exactly one protocol version for its duration, so this is
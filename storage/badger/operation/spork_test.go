@@ -27,6 +27,21 @@ func TestSporkID_InsertRetrieve(t *testing.T) {
 	})
 }
 
+func TestSporkRootBlockHeight_InsertRetrieve(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		height := rand.Uint64()
+
+		err := db.Update(InsertSporkRootBlockHeight(height))
+		require.NoError(t, err)
+
+		var actual uint64
+		err = db.View(RetrieveSporkRootBlockHeight(&actual))
+		require.NoError(t, err)
+
+		assert.Equal(t, height, actual)
+	})
+}
+
 func TestProtocolVersion_InsertRetrieve(t *testing.T) {
 	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
 		version := uint(rand.Uint32())
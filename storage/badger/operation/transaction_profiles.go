@@ -0,0 +1,31 @@
+package operation
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+)
+
+func BatchInsertTransactionProfile(blockID flow.Identifier, profile *flow.TransactionProfile) func(batch *badger.WriteBatch) error {
+	return batchWrite(makePrefix(codeTransactionProfile, blockID, profile.TransactionID), profile)
+}
+
+func RetrieveTransactionProfile(blockID flow.Identifier, transactionID flow.Identifier, profile *flow.TransactionProfile) func(*badger.Txn) error {
+	return retrieve(makePrefix(codeTransactionProfile, blockID, transactionID), profile)
+}
+
+// RemoveTransactionProfilesByBlockID removes the transaction profiles for the given blockID
+func RemoveTransactionProfilesByBlockID(blockID flow.Identifier) func(*badger.Txn) error {
+	return func(txn *badger.Txn) error {
+
+		prefix := makePrefix(codeTransactionProfile, blockID)
+		err := removeByPrefix(prefix)(txn)
+		if err != nil {
+			return fmt.Errorf("could not remove transaction profiles for block %v: %w", blockID, err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,74 @@
+package badger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+type ProgramUsages struct {
+	db *badger.DB
+}
+
+func NewProgramUsages(db *badger.DB) *ProgramUsages {
+	return &ProgramUsages{
+		db: db,
+	}
+}
+
+// Add adds the uses of the given program usages to the stored program usages with the same contract
+// location and code hash
+func (p *ProgramUsages) Add(usages []flow.ProgramUsage) error {
+	return operation.RetryOnConflict(p.db.Update, func(tx *badger.Txn) error {
+		for _, usage := range usages {
+			var stored flow.ProgramUsage
+			err := operation.RetrieveProgramUsage(usage.Address, usage.Name, usage.CodeHash, &stored)(tx)
+			if errors.Is(err, storage.ErrNotFound) {
+				usage := usage
+				err = operation.InsertProgramUsage(&usage)(tx)
+				if err != nil {
+					return fmt.Errorf("could not insert program usage of %s.%s: %w", usage.Address, usage.Name, err)
+				}
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("could not retrieve program usage of %s.%s: %w", usage.Address, usage.Name, err)
+			}
+
+			stored.Uses += usage.Uses
+			err = operation.UpdateProgramUsage(&stored)(tx)
+			if err != nil {
+				return fmt.Errorf("could not update program usage of %s.%s: %w", usage.Address, usage.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveByLocation removes the program usages of the contract, whatever its code hash
+func (p *ProgramUsages) RemoveByLocation(address flow.Address, name string) error {
+	return p.db.Update(operation.RemoveProgramUsagesByLocation(address, name))
+}
+
+// MostUsed returns up to limit program usages, the most used first
+func (p *ProgramUsages) MostUsed(limit uint) ([]flow.ProgramUsage, error) {
+	var usages []flow.ProgramUsage
+	err := p.db.View(operation.LookupProgramUsages(&usages))
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve program usages: %w", err)
+	}
+
+	sort.SliceStable(usages, func(i, j int) bool {
+		return usages[i].Uses > usages[j].Uses
+	})
+	if uint(len(usages)) > limit {
+		usages = usages[:limit]
+	}
+	return usages, nil
+}
@@ -0,0 +1,57 @@
+package badger_test
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/utils/unittest"
+
+	bstorage "github.com/onflow/flow-go/storage/badger"
+)
+
+func TestProgramUsages(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		store := bstorage.NewProgramUsages(db)
+
+		address := unittest.AddressFixture()
+		codeHash := unittest.IdentifierFixture()
+		updatedCodeHash := unittest.IdentifierFixture()
+
+		err := store.Add([]flow.ProgramUsage{
+			{Address: address, Name: "A", CodeHash: codeHash, Uses: 1},
+			{Address: address, Name: "B", CodeHash: codeHash, Uses: 5},
+			{Address: address, Name: "C", CodeHash: codeHash, Uses: 3},
+		})
+		require.NoError(t, err)
+
+		// uses are added to the program usages with the same location and code hash
+		err = store.Add([]flow.ProgramUsage{
+			{Address: address, Name: "A", CodeHash: codeHash, Uses: 6},
+			{Address: address, Name: "C", CodeHash: updatedCodeHash, Uses: 1},
+		})
+		require.NoError(t, err)
+
+		usages, err := store.MostUsed(3)
+		require.NoError(t, err)
+		assert.Equal(t, []flow.ProgramUsage{
+			{Address: address, Name: "A", CodeHash: codeHash, Uses: 7},
+			{Address: address, Name: "B", CodeHash: codeHash, Uses: 5},
+			{Address: address, Name: "C", CodeHash: codeHash, Uses: 3},
+		}, usages)
+
+		err = store.RemoveByLocation(address, "C")
+		require.NoError(t, err)
+
+		// the program usages are read back from the database
+		usages, err = bstorage.NewProgramUsages(db).MostUsed(10)
+		require.NoError(t, err)
+		assert.Equal(t, []flow.ProgramUsage{
+			{Address: address, Name: "A", CodeHash: codeHash, Uses: 7},
+			{Address: address, Name: "B", CodeHash: codeHash, Uses: 5},
+		}, usages)
+	})
+}
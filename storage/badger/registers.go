@@ -0,0 +1,163 @@
+package badger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+// Registers stores the register values of the execution state at each indexed height. The register entries of a
+// height are only visible once the latest height has been updated, so that the registers at any indexed height are
+// complete.
+type Registers struct {
+	db *badger.DB
+
+	mu           sync.RWMutex
+	firstHeight  uint64
+	latestHeight uint64
+	bootstrapped bool
+}
+
+var _ storage.Registers = (*Registers)(nil)
+
+func NewRegisters(db *badger.DB) (*Registers, error) {
+	r := &Registers{db: db}
+
+	err := db.View(func(tx *badger.Txn) error {
+		err := operation.RetrieveRegistersFirstHeight(&r.firstHeight)(tx)
+		if err != nil {
+			return err
+		}
+		return operation.RetrieveRegistersLatestHeight(&r.latestHeight)(tx)
+	})
+	if errors.Is(err, storage.ErrNotFound) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve register heights: %w", err)
+	}
+
+	r.bootstrapped = true
+	return r, nil
+}
+
+func (r *Registers) Bootstrap(height uint64, entries flow.RegisterEntries) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.bootstrapped {
+		return fmt.Errorf("registers are already bootstrapped at height %d", r.firstHeight)
+	}
+
+	err := r.storeEntries(height, entries)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.Update(func(tx *badger.Txn) error {
+		err := operation.InsertRegistersFirstHeight(height)(tx)
+		if err != nil {
+			return err
+		}
+		return operation.InsertRegistersLatestHeight(height)(tx)
+	})
+	if err != nil {
+		return fmt.Errorf("could not insert register heights: %w", err)
+	}
+
+	r.firstHeight = height
+	r.latestHeight = height
+	r.bootstrapped = true
+	return nil
+}
+
+func (r *Registers) Store(height uint64, entries flow.RegisterEntries) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.bootstrapped {
+		return fmt.Errorf("registers are not bootstrapped")
+	}
+	if height != r.latestHeight+1 {
+		return fmt.Errorf("height %d does not follow the latest indexed height %d", height, r.latestHeight)
+	}
+
+	err := r.storeEntries(height, entries)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.Update(operation.UpdateRegistersLatestHeight(height))
+	if err != nil {
+		return fmt.Errorf("could not update latest register height: %w", err)
+	}
+
+	r.latestHeight = height
+	return nil
+}
+
+// storeEntries writes the register entries in batches, as the full execution state does not fit into a single
+// transaction.
+func (r *Registers) storeEntries(height uint64, entries flow.RegisterEntries) error {
+	batch := NewBatch(r.db)
+	writer := batch.GetWriter()
+	for _, entry := range entries {
+		err := operation.BatchInsertRegister(height, entry.Key, entry.Value)(writer)
+		if err != nil {
+			return fmt.Errorf("could not insert register %s: %w", entry.Key.String(), err)
+		}
+	}
+
+	err := batch.Flush()
+	if err != nil {
+		return fmt.Errorf("could not flush registers at height %d: %w", height, err)
+	}
+	return nil
+}
+
+func (r *Registers) Get(id flow.RegisterID, height uint64) (flow.RegisterValue, error) {
+	r.mu.RLock()
+	indexed := r.bootstrapped && height >= r.firstHeight && height <= r.latestHeight
+	r.mu.RUnlock()
+
+	if !indexed {
+		return nil, storage.ErrNotFound
+	}
+
+	var value flow.RegisterValue
+	err := r.db.View(operation.LookupRegister(id, height, &value))
+	if errors.Is(err, storage.ErrNotFound) {
+		// the register has not been set at or below the height
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not lookup register %s: %w", id.String(), err)
+	}
+	return value, nil
+}
+
+func (r *Registers) FirstHeight() (uint64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.bootstrapped {
+		return 0, storage.ErrNotFound
+	}
+	return r.firstHeight, nil
+}
+
+func (r *Registers) LatestHeight() (uint64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.bootstrapped {
+		return 0, storage.ErrNotFound
+	}
+	return r.latestHeight, nil
+}
@@ -0,0 +1,78 @@
+package badger_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/unittest"
+
+	badgerstorage "github.com/onflow/flow-go/storage/badger"
+)
+
+// TestRegistersStoreAndGet tests that the registers are indexed by height, and only indexed heights can be read
+func TestRegistersStoreAndGet(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		registers, err := badgerstorage.NewRegisters(db)
+		require.NoError(t, err)
+
+		_, err = registers.LatestHeight()
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+		id := flow.NewRegisterID("owner", "", "key")
+		unset := flow.NewRegisterID("owner", "", "unset")
+
+		// registers can not be stored before the registers are bootstrapped
+		err = registers.Store(11, flow.RegisterEntries{{Key: id, Value: []byte("b")}})
+		require.Error(t, err)
+
+		err = registers.Bootstrap(10, flow.RegisterEntries{{Key: id, Value: []byte("a")}})
+		require.NoError(t, err)
+
+		// heights must be contiguous
+		err = registers.Store(12, flow.RegisterEntries{{Key: id, Value: []byte("b")}})
+		require.Error(t, err)
+
+		err = registers.Store(11, flow.RegisterEntries{{Key: id, Value: []byte("b")}})
+		require.NoError(t, err)
+		err = registers.Store(12, nil)
+		require.NoError(t, err)
+
+		value, err := registers.Get(id, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("a"), value)
+
+		value, err = registers.Get(id, 12)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("b"), value)
+
+		value, err = registers.Get(unset, 12)
+		require.NoError(t, err)
+		assert.Nil(t, value)
+
+		// heights outside of the indexed range are not found
+		_, err = registers.Get(id, 9)
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+		_, err = registers.Get(id, 13)
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+		// the indexed range is loaded from the database
+		registers, err = badgerstorage.NewRegisters(db)
+		require.NoError(t, err)
+
+		first, err := registers.FirstHeight()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(10), first)
+		latest, err := registers.LatestHeight()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(12), latest)
+
+		err = registers.Bootstrap(20, nil)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,87 @@
+package badger
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/storage/badger/operation"
+)
+
+type TransactionProfiles struct {
+	db    *badger.DB
+	cache *Cache
+}
+
+func NewTransactionProfiles(collector module.CacheMetrics, db *badger.DB, cacheSize uint) *TransactionProfiles {
+	retrieve := func(key interface{}) func(tx *badger.Txn) (interface{}, error) {
+		var profile flow.TransactionProfile
+		return func(tx *badger.Txn) (interface{}, error) {
+
+			blockID, txID, err := KeyToBlockIDTransactionID(key.(string))
+			if err != nil {
+				return nil, fmt.Errorf("could not convert key: %w", err)
+			}
+
+			err = operation.RetrieveTransactionProfile(blockID, txID, &profile)(tx)
+			if err != nil {
+				return nil, handleError(err, flow.TransactionProfile{})
+			}
+			return profile, nil
+		}
+	}
+
+	return &TransactionProfiles{
+		db: db,
+		cache: newCache(collector, metrics.ResourceTransactionProfiles,
+			withLimit(cacheSize),
+			withStore(noopStore),
+			withRetrieve(retrieve),
+		),
+	}
+}
+
+// BatchStore will store the transaction profiles for the given block ID in a batch
+func (tp *TransactionProfiles) BatchStore(blockID flow.Identifier, profiles []flow.TransactionProfile, batch storage.BatchStorage) error {
+	writeBatch := batch.GetWriter()
+
+	for i := range profiles {
+		err := operation.BatchInsertTransactionProfile(blockID, &profiles[i])(writeBatch)
+		if err != nil {
+			return fmt.Errorf("cannot batch insert tx profile: %w", err)
+		}
+	}
+
+	batch.OnSucceed(func() {
+		for _, profile := range profiles {
+			key := KeyFromBlockIDTransactionID(blockID, profile.TransactionID)
+			tp.cache.Insert(key, profile)
+		}
+	})
+	return nil
+}
+
+// ByBlockIDTransactionID returns the transaction profile for the given block ID and transaction ID
+func (tp *TransactionProfiles) ByBlockIDTransactionID(blockID flow.Identifier, txID flow.Identifier) (*flow.TransactionProfile, error) {
+	tx := tp.db.NewTransaction(false)
+	defer tx.Discard()
+	key := KeyFromBlockIDTransactionID(blockID, txID)
+	val, err := tp.cache.Get(key)(tx)
+	if err != nil {
+		return nil, err
+	}
+	profile, ok := val.(flow.TransactionProfile)
+	if !ok {
+		return nil, fmt.Errorf("could not convert transaction profile: %w", err)
+	}
+	return &profile, nil
+}
+
+// RemoveByBlockID removes transaction profiles by block ID
+func (tp *TransactionProfiles) RemoveByBlockID(blockID flow.Identifier) error {
+	return tp.db.Update(operation.RemoveTransactionProfilesByBlockID(blockID))
+}
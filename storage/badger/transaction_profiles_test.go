@@ -0,0 +1,78 @@
+package badger_test
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/onflow/flow-go/storage"
+	"github.com/onflow/flow-go/utils/unittest"
+
+	bstorage "github.com/onflow/flow-go/storage/badger"
+)
+
+func TestBatchStoringTransactionProfiles(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		metrics := metrics.NewNoopCollector()
+		store := bstorage.NewTransactionProfiles(metrics, db, 1000)
+
+		blockID := unittest.IdentifierFixture()
+		profiles := make([]flow.TransactionProfile, 0)
+		for i := 0; i < 10; i++ {
+			profiles = append(profiles, flow.TransactionProfile{
+				TransactionID:   unittest.IdentifierFixture(),
+				ComputationUsed: uint64(i),
+				MemoryUsed:      uint64(i * 1000),
+				ComputationKinds: []flow.ProfiledKind{
+					{Kind: 1001, Intensity: uint64(i), Used: uint64(i)},
+					{Kind: 1002, Intensity: 3},
+				},
+				MemoryKinds: []flow.ProfiledKind{
+					{Kind: 1, Intensity: 10, Used: 100},
+				},
+				RegisterBytesRead:    uint64(i * 10),
+				RegisterBytesWritten: uint64(i * 5),
+			})
+		}
+		writeBatch := bstorage.NewBatch(db)
+		err := store.BatchStore(blockID, profiles, writeBatch)
+		require.NoError(t, err)
+
+		err = writeBatch.Flush()
+		require.NoError(t, err)
+
+		for _, profile := range profiles {
+			actual, err := store.ByBlockIDTransactionID(blockID, profile.TransactionID)
+			require.NoError(t, err)
+			assert.Equal(t, profile, *actual)
+		}
+
+		// test loading from database
+		newStore := bstorage.NewTransactionProfiles(metrics, db, 1000)
+		for _, profile := range profiles {
+			actual, err := newStore.ByBlockIDTransactionID(blockID, profile.TransactionID)
+			require.NoError(t, err)
+			assert.Equal(t, profile, *actual)
+		}
+
+		err = newStore.RemoveByBlockID(blockID)
+		require.NoError(t, err)
+
+		_, err = bstorage.NewTransactionProfiles(metrics, db, 1000).ByBlockIDTransactionID(blockID, profiles[0].TransactionID)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
+
+func TestReadingNotStoredTransactionProfile(t *testing.T) {
+	unittest.RunWithBadgerDB(t, func(db *badger.DB) {
+		metrics := metrics.NewNoopCollector()
+		store := bstorage.NewTransactionProfiles(metrics, db, 1000)
+
+		_, err := store.ByBlockIDTransactionID(unittest.IdentifierFixture(), unittest.IdentifierFixture())
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
@@ -0,0 +1,20 @@
+package storage
+
+import "github.com/onflow/flow-go/model/flow"
+
+// ProgramUsages represents persistent storage for how many times the programs of contracts were used by executed
+// transactions, so that the most used programs can be loaded ahead of execution after a restart.
+// Usages are keyed by contract location and code hash. The checked programs themselves can't be encoded, so they
+// are not stored.
+type ProgramUsages interface {
+
+	// Add adds the uses of the given program usages to the stored program usages with the same contract
+	// location and code hash
+	Add(usages []flow.ProgramUsage) error
+
+	// RemoveByLocation removes the program usages of the contract, whatever its code hash
+	RemoveByLocation(address flow.Address, name string) error
+
+	// MostUsed returns up to limit program usages, the most used first
+	MostUsed(limit uint) ([]flow.ProgramUsage, error)
+}
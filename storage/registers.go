@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+)
+
+// Registers represents persistent storage for the register values of the execution state at each height of a
+// contiguous range of sealed heights.
+type Registers interface {
+
+	// Bootstrap stores the register entries of the full execution state at the given height, which becomes the first
+	// and latest indexed height. It must be called once, before any other register entries are stored.
+	Bootstrap(height uint64, entries flow.RegisterEntries) error
+
+	// Store stores the register entries updated at the given height, which must be the height following the latest
+	// indexed height.
+	Store(height uint64, entries flow.RegisterEntries) error
+
+	// Get returns the value of the register at the given height, which is nil if the register has not been set.
+	// Returns ErrNotFound if the height is not indexed.
+	Get(id flow.RegisterID, height uint64) (flow.RegisterValue, error)
+
+	// FirstHeight returns the first indexed height.
+	// Returns ErrNotFound if the registers have not been bootstrapped.
+	FirstHeight() (uint64, error)
+
+	// LatestHeight returns the latest indexed height.
+	// Returns ErrNotFound if the registers have not been bootstrapped.
+	LatestHeight() (uint64, error)
+}
@@ -0,0 +1,13 @@
+package storage
+
+import "github.com/onflow/flow-go/model/flow"
+
+// TransactionProfiles represents persistent storage for the profiles of executed transactions
+type TransactionProfiles interface {
+
+	// BatchStore inserts a batch of transaction profiles into a batch
+	BatchStore(blockID flow.Identifier, profiles []flow.TransactionProfile, batch BatchStorage) error
+
+	// ByBlockIDTransactionID returns the transaction profile for the given block ID and transaction ID
+	ByBlockIDTransactionID(blockID flow.Identifier, transactionID flow.Identifier) (*flow.TransactionProfile, error)
+}
@@ -1,6 +1,7 @@
 package grpcutils
 
 import (
+	"crypto/ecdsa"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/hex"
@@ -8,6 +9,7 @@ import (
 
 	lcrypto "github.com/libp2p/go-libp2p-core/crypto"
 	libp2ptls "github.com/libp2p/go-libp2p-tls"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/onflow/flow-go/crypto"
 	"github.com/onflow/flow-go/network/p2p/keyutils"
@@ -57,6 +59,25 @@ func DefaultServerTLSConfig(cert *tls.Certificate) *tls.Config {
 	return tlsConfig
 }
 
+// ServerTransportCredentials returns the TLS credentials of a secure GRPC server. The server presents the certificate
+// and key loaded from the given PEM files if set, or otherwise the self-signed certificate generated from the given
+// networking key (see X509Certificate).
+func ServerTransportCredentials(networkKey crypto.PrivateKey, certFile string, keyFile string) (credentials.TransportCredentials, error) {
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load TLS certificate from %s and %s: %w", certFile, keyFile, err)
+		}
+		return credentials.NewTLS(DefaultServerTLSConfig(&cert)), nil
+	}
+
+	cert, err := X509Certificate(networkKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate TLS certificate: %w", err)
+	}
+	return credentials.NewTLS(DefaultServerTLSConfig(cert)), nil
+}
+
 // ServerAuthError is an error returned when the server authentication fails
 type ServerAuthError struct {
 	message string
@@ -98,6 +119,45 @@ func DefaultClientTLSConfig(publicKey crypto.PublicKey) (*tls.Config, error) {
 	return config, nil
 }
 
+// CAClientTLSConfig returns the TLS client config for a secure GRPC client verifying the certificate of the server
+// against the given certificate authorities. The public key of the certificate is pinned to the given public key, so
+// the certificate authorities must issue the certificate of a node for its networking key.
+func CAClientTLSConfig(rootCAs *x509.CertPool, publicKey crypto.PublicKey) (*tls.Config, error) {
+
+	// convert the Flow.crypto key to LibP2P key for easy comparision using LibP2P TLS utils
+	remotePeerLibP2PID, err := keyutils.PeerIDFromFlowPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the libp2p Peer ID from the Flow key: %w", err)
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    rootCAs,
+		// the chain has been verified against the certificate authorities when this function is called
+		VerifyPeerCertificate: func(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+			for _, chain := range verifiedChains {
+				if len(chain) == 0 {
+					continue
+				}
+				certKey, ok := chain[0].PublicKey.(*ecdsa.PublicKey)
+				if !ok {
+					continue
+				}
+				actualLibP2PKey, err := lcrypto.ECDSAPublicKeyFromPubKey(*certKey)
+				if err != nil {
+					continue
+				}
+				if remotePeerLibP2PID.MatchesPublicKey(actualLibP2PKey) {
+					return nil
+				}
+			}
+			return newServerAuthError("certificate is not issued for the expected public key %s", publicKey.String())
+		},
+	}
+
+	return config, nil
+}
+
 func verifyPeerCertificateFunc(expectedPublicKey crypto.PublicKey) (func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error, error) {
 
 	// convert the Flow.crypto key to LibP2P key for easy comparision using LibP2P TLS utils
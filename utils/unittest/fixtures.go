@@ -1980,3 +1980,20 @@ func TransactionResultsFixture(n int) []flow.TransactionResult {
 	}
 	return results
 }
+
+func TransactionProfilesFixture(results []flow.TransactionResult) []flow.TransactionProfile {
+	profiles := make([]flow.TransactionProfile, 0, len(results))
+	for _, result := range results {
+		profiles = append(profiles, flow.TransactionProfile{
+			TransactionID:   result.TransactionID,
+			ComputationUsed: result.ComputationUsed,
+			MemoryUsed:      uint64(rand.Uint32()),
+			ComputationKinds: []flow.ProfiledKind{
+				{Kind: 1001, Intensity: result.ComputationUsed, Used: result.ComputationUsed},
+			},
+			RegisterBytesRead:    uint64(rand.Uint32()),
+			RegisterBytesWritten: uint64(rand.Uint32()),
+		})
+	}
+	return profiles
+}
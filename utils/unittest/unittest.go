@@ -313,6 +313,17 @@ func RunWithTempDir(t testing.TB, f func(string)) {
 	f(dbDir)
 }
 
+// ChdirTempDir changes the working directory to a temporary directory until the test completes,
+// for code which writes files to the working directory. Tests using it must not run in parallel.
+func ChdirTempDir(t testing.TB) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(wd))
+	})
+}
+
 func badgerDB(t testing.TB, dir string, create func(badger.Options) (*badger.DB, error)) *badger.DB {
 	opts := badger.
 		DefaultOptions(dir).